@@ -10,11 +10,15 @@ import (
 	"syscall"
 	"time"
 
+	"fitness-tracker/internal/adapters/cache"
+	"fitness-tracker/internal/adapters/external"
 	"fitness-tracker/internal/adapters/http/handlers"
 	httpAdapter "fitness-tracker/internal/adapters/http"
 	"fitness-tracker/internal/adapters/repositories/postgres"
 	"fitness-tracker/internal/config"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/auth"
 	"fitness-tracker/internal/services"
 
 	_ "github.com/joho/godotenv/autoload"
@@ -43,29 +47,46 @@ func main() {
 	)
 
 	// Initialize database
-	db, err := config.InitDB(&cfg.Database)
+	db, err := config.InitDB(&cfg.Database, logger)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
 
+	// Exercise duplicate detection compares names by trigram similarity
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		logger.Fatal("Failed to enable pg_trgm", zap.Error(err))
+	}
+
 	// Auto-migrate
 	if err := db.AutoMigrate(
 		&domain.User{},
+		&domain.UserToken{},
 		&domain.Food{},
 		&domain.ServingUnit{},
 		&domain.FoodServingConversion{},
+		&domain.FoodRevision{},
+		&domain.FoodStar{},
 		&domain.Meal{},
 		&domain.MealFoodItem{},
 		&domain.Activity{},
+		&domain.RoutePoint{},
 		&domain.Exercise{},
 		&domain.Workout{},
 		&domain.WorkoutExercise{},
 		&domain.WorkoutSet{},
+		&domain.PlannedSet{},
+		&domain.WorkoutPause{},
 		&domain.Metric{},
 		&domain.DailySummary{},
 		&domain.Goal{},
+		&domain.NutritionTarget{},
+		&domain.MealDistribution{},
+		&domain.Achievement{},
 		&domain.Conversation{},
 		&domain.Message{},
+		&domain.LLMAuditEntry{},
+		&domain.UserAction{},
+		&domain.JobRun{},
 	); err != nil {
 		logger.Fatal("Failed to run migrations", zap.Error(err))
 	}
@@ -74,20 +95,138 @@ func main() {
 
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(db)
+	userTokenRepo := postgres.NewUserTokenRepository(db)
+	accountRepo := postgres.NewAccountRepository(db)
+	// Food searches are cached in memory; writes through foodRepo drop the affected results
+	foodSearchCache := cache.NewFoodSearchCache(cfg.Server.FoodSearchCacheSize, cfg.Server.FoodSearchCacheTTL)
+	foodRepo := cache.NewFoodRepository(postgres.NewFoodRepository(db), foodSearchCache)
+	foodRevisionRepo := postgres.NewFoodRevisionRepository(db)
+	foodStarRepo := postgres.NewFoodStarRepository(db)
+	mealRepo := postgres.NewMealRepository(db)
+	activityRepo := postgres.NewActivityRepository(db)
+	workoutRepo := postgres.NewWorkoutRepository(db)
+	goalRepo := postgres.NewGoalRepository(db)
+	nutritionTargetRepo := postgres.NewNutritionTargetRepository(db)
+	mealDistributionRepo := postgres.NewMealDistributionRepository(db)
+	metricRepo := postgres.NewMetricRepository(db)
+	achievementRepo := postgres.NewAchievementRepository(db)
+	llmAuditRepo := postgres.NewLLMAuditRepository(db)
+	conversationRepo := postgres.NewConversationRepository(db)
+	userActionRepo := postgres.NewUserActionRepository(db)
+	demoRepo := postgres.NewDemoRepository(db)
+
+	// Initialize external clients
+	emailSender := external.NewLogEmailSender()
+	eventPublisher := external.NewLogEventPublisher()
+
+	var photoStorage ports.PhotoStorage
+	if cfg.Supabase.URL != "" {
+		photoStorage = external.NewSupabaseStorageClient(cfg.Supabase.URL, cfg.Supabase.AnonKey)
+	}
+
+	// Initialize JWT signing keys
+	jwtKeys, err := auth.NewKeySetFromConfig(&cfg.JWT)
+	if err != nil {
+		logger.Fatal("Failed to load JWT keys", zap.Error(err))
+	}
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, cfg.JWT.Secret, cfg.JWT.ExpirationTime)
+	authService := services.NewAuthService(userRepo, userTokenRepo, emailSender, jwtKeys, cfg.JWT.ExpirationTime)
+	accountService := services.NewAccountService(userRepo, accountRepo, photoStorage, eventPublisher)
+	profileService := services.NewProfileService(userRepo, goalRepo, nutritionTargetRepo, mealDistributionRepo, cfg.Nutrition.MacroPresets)
+	foodService := services.NewFoodService(foodRepo, mealRepo, foodRevisionRepo, foodStarRepo)
+	summaryService := services.NewSummaryService(mealRepo, activityRepo, workoutRepo, userRepo, goalRepo, nutritionTargetRepo, mealDistributionRepo, metricRepo, cfg.Server.CalorieSourcePriority)
+	workoutService := services.NewWorkoutService(workoutRepo, userRepo, userActionRepo, summaryService)
+	metricService := services.NewMetricService(metricRepo, userRepo, userActionRepo, summaryService)
+	goalService := services.NewGoalService(goalRepo, metricRepo, workoutRepo)
+	insightsService := services.NewInsightsService(userRepo, mealRepo, workoutRepo, metricRepo, achievementRepo)
+	undoService := services.NewUndoService(userActionRepo, mealRepo, activityRepo, metricRepo, workoutRepo, userRepo, summaryService)
+	llmAuditService := services.NewLLMAuditService(llmAuditRepo, cfg.OpenRouter.AuditEnabled, cfg.OpenRouter.AuditRedactPII)
+
+	// Coach output is screened before users see it; nil when moderation is off
+	moderator := external.NewKeywordModerator(cfg.OpenRouter.ModerationStrictness)
+
+	// Coach digest tips fall back to templates when AI is disabled
+	var coachClient *external.OpenRouterClient
+	if cfg.OpenRouter.APIKey != "" && cfg.OpenRouter.CoachDigestAITips {
+		coachClient = external.NewOpenRouterClientWithBaseURL(cfg.OpenRouter.APIKey, cfg.OpenRouter.BaseURL).
+			WithAuditor(llmAuditService).
+			WithFallbackModels(cfg.OpenRouter.FallbackModels...)
+	}
+	coachService := services.NewCoachService(userRepo, summaryService, goalService, coachClient, cfg.OpenRouter.Model, moderator)
+
+	// Weekly recap encouragement falls back to templates when AI is disabled
+	var recapClient *external.OpenRouterClient
+	if cfg.OpenRouter.APIKey != "" && cfg.OpenRouter.WeeklyRecapAI {
+		recapClient = external.NewOpenRouterClientWithBaseURL(cfg.OpenRouter.APIKey, cfg.OpenRouter.BaseURL).
+			WithAuditor(llmAuditService).
+			WithFallbackModels(cfg.OpenRouter.FallbackModels...)
+	}
+	weeklyRecapService := services.NewWeeklyRecapService(userRepo, workoutRepo, metricRepo, summaryService, recapClient, cfg.OpenRouter.Model, moderator)
+	conversationService := services.NewConversationService(conversationRepo)
+
+	// Food estimates use the built-in nutrition table when AI is disabled
+	foodEstimator := services.NewMealParserService(cfg.OpenRouter.APIKey, foodRepo, llmAuditService).
+		WithBaseURL(cfg.OpenRouter.BaseURL).
+		WithFallbackModels(cfg.OpenRouter.FallbackModels...).
+		WithTemperature(cfg.OpenRouter.ParseTemperature)
+	demoService := services.NewDemoService(demoRepo, userRepo, summaryService)
 
 	// Initialize handlers
+	pageLimits := handlers.PageLimits{DefaultSize: cfg.Server.DefaultPageSize, MaxSize: cfg.Server.MaxPageSize}
+	display := handlers.Display{NutritionPrecision: cfg.Server.NutritionPrecision}
 	authHandler := handlers.NewAuthHandler(authService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	profileHandler := handlers.NewProfileHandler(profileService)
+	foodHandler := handlers.NewFoodHandler(foodService, foodEstimator, pageLimits, display)
+	summaryHandler := handlers.NewSummaryHandler(summaryService, display)
+	workoutHandler := handlers.NewWorkoutHandler(workoutService, display)
+	metricHandler := handlers.NewMetricHandler(metricService, pageLimits)
+	goalHandler := handlers.NewGoalHandler(goalService)
+	insightsHandler := handlers.NewInsightsHandler(insightsService, weeklyRecapService)
+	undoHandler := handlers.NewUndoHandler(undoService, display)
+	coachHandler := handlers.NewCoachHandler(coachService, display)
+	conversationHandler := handlers.NewConversationHandler(conversationService, pageLimits)
+	llmAuditHandler := handlers.NewLLMAuditHandler(llmAuditService, pageLimits)
+	cacheHandler := handlers.NewCacheHandler(foodSearchCache)
+	demoHandler := handlers.NewDemoHandler(demoService)
+
+	// Register scheduled jobs. Every instance schedules them, and an advisory lock
+	// makes sure only one runs each job per interval.
+	scheduler := services.NewScheduler(postgres.NewJobRunRepository(db))
+	if photoStorage != nil && cfg.Supabase.PhotoRetentionDays > 0 {
+		retention := time.Duration(cfg.Supabase.PhotoRetentionDays) * 24 * time.Hour
+		photoRetentionService := services.NewPhotoRetentionService(userRepo, mealRepo, photoStorage, retention, cfg.Supabase.PhotoCleanupDryRun)
+		registerJob(scheduler, services.Job{
+			Name:     domain.JobPhotoCleanup,
+			Interval: cfg.Scheduler.Interval(domain.JobPhotoCleanup, cfg.Supabase.PhotoCleanupInterval),
+			Run: func(ctx context.Context) error {
+				return runPhotoCleanup(ctx, photoRetentionService, logger)
+			},
+		}, logger)
+	}
+
+	// Start background jobs; they stop when the server shuts down
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.Scheduler.Enabled {
+		scheduler.Start(jobCtx)
+	} else {
+		logger.Info("Scheduled jobs are disabled on this instance")
+	}
 
 	// Setup router
-	router := httpAdapter.SetupRouter(authHandler, authService, cfg)
+	router := httpAdapter.SetupRouter(authHandler, accountHandler, profileHandler, foodHandler, summaryHandler, workoutHandler, metricHandler, goalHandler, insightsHandler, undoHandler, coachHandler, conversationHandler, llmAuditHandler, cacheHandler, demoHandler, authService, jwtKeys, logger, cfg)
 
 	// Start server
+	// Streaming routes raise their own write deadline with middleware.WriteTimeout
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler: router,
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// Graceful shutdown
@@ -105,13 +244,37 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopJobs()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	scheduler.Wait()
 
 	logger.Info("Server exited")
 }
+
+// registerJob adds a job to the scheduler; a job that cannot be registered stops startup
+func registerJob(scheduler *services.Scheduler, job services.Job, logger *zap.Logger) {
+	if err := scheduler.Register(job); err != nil {
+		logger.Fatal("Failed to register scheduled job", zap.String("job", job.Name), zap.Error(err))
+	}
+}
+
+// runPhotoCleanup runs the photo retention cleanup once and logs its report
+func runPhotoCleanup(ctx context.Context, svc ports.PhotoRetentionService, logger *zap.Logger) error {
+	report, err := svc.Cleanup(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Info("Photo cleanup finished",
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("photos_scanned", report.PhotosScanned),
+		zap.Int("deleted", len(report.Deleted)),
+		zap.Int("failed", report.Failed),
+	)
+	return nil
+}
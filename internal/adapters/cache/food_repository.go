@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// foodRepository serves food searches from a FoodSearchCache and drops the affected
+// cached results whenever a food is written. Everything else goes to the wrapped
+// repository.
+type foodRepository struct {
+	ports.FoodRepository
+	cache ports.FoodSearchCache
+}
+
+// NewFoodRepository wraps repo so its searches are cached in searchCache
+func NewFoodRepository(repo ports.FoodRepository, searchCache ports.FoodSearchCache) ports.FoodRepository {
+	return &foodRepository{FoodRepository: repo, cache: searchCache}
+}
+
+func (r *foodRepository) Search(ctx context.Context, query string, filter domain.FoodSearchFilter, limit, offset int) ([]*domain.Food, error) {
+	key := domain.FoodSearchKey{Query: domain.NormalizeFoodQuery(query), Filter: filter, Limit: limit, Offset: offset}
+	if foods, ok := r.cache.Get(ctx, key); ok {
+		return foods, nil
+	}
+
+	foods, err := r.FoodRepository.Search(ctx, key.Query, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(ctx, key, foods)
+	return foods, nil
+}
+
+func (r *foodRepository) Create(ctx context.Context, food *domain.Food) error {
+	if err := r.FoodRepository.Create(ctx, food); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx, food)
+	return nil
+}
+
+func (r *foodRepository) Update(ctx context.Context, food *domain.Food) error {
+	if err := r.FoodRepository.Update(ctx, food); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx, food)
+	return nil
+}
+
+func (r *foodRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.FoodRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx, &domain.Food{ID: id})
+	return nil
+}
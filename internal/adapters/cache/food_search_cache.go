@@ -0,0 +1,172 @@
+// Package cache holds in-memory caches behind the ports cache interfaces
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// matchPrefixRunes is how much of a query word must appear in a food for an update of
+// the food to drop the query's cached results. Postgres full-text search also matches
+// stemmed forms, e.g. "eggs" finds "Egg", so only the start of the word is compared.
+const matchPrefixRunes = 3
+
+type foodSearchEntry struct {
+	key       domain.FoodSearchKey
+	foods     []domain.Food
+	expiresAt time.Time
+}
+
+// foodSearchCache is a least recently used cache of food search results whose entries
+// also expire after a TTL
+type foodSearchCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[domain.FoodSearchKey]*list.Element
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewFoodSearchCache returns an in-memory cache holding the results of up to size
+// searches for ttl each
+func NewFoodSearchCache(size int, ttl time.Duration) ports.FoodSearchCache {
+	return &foodSearchCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[domain.FoodSearchKey]*list.Element),
+	}
+}
+
+func (c *foodSearchCache) Get(ctx context.Context, key domain.FoodSearchKey) ([]*domain.Food, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if ok && time.Now().After(element.Value.(*foodSearchEntry).expiresAt) {
+		c.remove(element)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	c.order.MoveToFront(element)
+	stored := element.Value.(*foodSearchEntry).foods
+	foods := make([]*domain.Food, len(stored))
+	for i := range stored {
+		food := stored[i]
+		foods[i] = &food
+	}
+	return foods, true
+}
+
+func (c *foodSearchCache) Set(ctx context.Context, key domain.FoodSearchKey, foods []*domain.Food) {
+	if c.size <= 0 || c.ttl <= 0 {
+		return
+	}
+
+	// Stored by value so callers marking results, e.g. as starred, do not change the cache
+	stored := make([]domain.Food, len(foods))
+	for i, food := range foods {
+		stored[i] = *food
+	}
+	entry := &foodSearchEntry{key: key, foods: stored, expiresAt: time.Now().Add(c.ttl)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *foodSearchCache) Invalidate(ctx context.Context, food *domain.Food) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	text := foodSearchText(food)
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*foodSearchEntry)
+		if entry.lists(food) || mayMatch(entry.key.Query, text) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+func (c *foodSearchCache) Stats() domain.CacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	stats := domain.CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+func (c *foodSearchCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*foodSearchEntry).key)
+}
+
+func (e *foodSearchEntry) lists(food *domain.Food) bool {
+	for i := range e.foods {
+		if e.foods[i].ID == food.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// foodSearchText is the lower-cased text a search matches a food against
+func foodSearchText(food *domain.Food) string {
+	text := food.Name
+	if food.Brand != nil {
+		text += " " + *food.Brand
+	}
+	if food.Description != nil {
+		text += " " + *food.Description
+	}
+	return strings.ToLower(text)
+}
+
+// mayMatch reports whether a search for query could find a food with text. It errs
+// towards yes: every query word's start has to appear somewhere in the text.
+func mayMatch(query, text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, word := range strings.Fields(query) {
+		if utf8.RuneCountInString(word) > matchPrefixRunes {
+			word = string([]rune(word)[:matchPrefixRunes])
+		}
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DecodeJSONContent decodes a model reply into v.
+// Replies from JSON mode are decoded directly; for models that ignore response_format,
+// markdown fences and surrounding prose are stripped before retrying.
+func DecodeJSONContent(content string, v interface{}) error {
+	trimmed := strings.TrimSpace(content)
+	if err := json.Unmarshal([]byte(trimmed), v); err == nil {
+		return nil
+	}
+
+	cleaned := stripCodeFence(trimmed)
+	if err := json.Unmarshal([]byte(cleaned), v); err == nil {
+		return nil
+	}
+
+	extracted := extractJSONValue(cleaned)
+	if extracted == "" {
+		return fmt.Errorf("no JSON found in response: %s", content)
+	}
+	if err := json.Unmarshal([]byte(extracted), v); err != nil {
+		return fmt.Errorf("invalid JSON in response: %w", err)
+	}
+	return nil
+}
+
+// stripCodeFence removes a surrounding ```json ... ``` block
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	return strings.TrimSpace(text)
+}
+
+// extractJSONValue returns the outermost JSON object or array embedded in text
+func extractJSONValue(text string) string {
+	start := strings.IndexAny(text, "{[")
+	if start == -1 {
+		return ""
+	}
+
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+
+	end := strings.LastIndex(text, closing)
+	if end <= start {
+		return ""
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,63 @@
+package external
+
+import (
+	"context"
+	"regexp"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// moderationPattern flags text matching a word list under a category
+type moderationPattern struct {
+	category string
+	pattern  *regexp.Regexp
+}
+
+// Word lists are matched case-insensitively on word boundaries, so "assess" or
+// "hello" never match. They favour phrases that have no place in fitness coaching.
+var (
+	standardModerationPatterns = []moderationPattern{
+		{domain.ModerationCategoryHate, regexp.MustCompile(`(?i)\b(retard(ed|s)?|fag(got)?s?|tranny|trannies)\b`)},
+		{domain.ModerationCategorySexual, regexp.MustCompile(`(?i)\b(porn\w*|nudes?|blowjobs?|handjobs?|have sex|sexting)\b`)},
+		{domain.ModerationCategorySelfHarm, regexp.MustCompile(`(?i)\b(kill (yourself|urself)|kys|starve yourself|make yourself (throw up|vomit|purge)|cut yourself|hurt yourself)\b`)},
+		{domain.ModerationCategoryProfanity, regexp.MustCompile(`(?i)\b(fuck\w*|motherfuck\w*|cunts?)\b`)},
+	}
+	strictModerationPatterns = []moderationPattern{
+		{domain.ModerationCategoryProfanity, regexp.MustCompile(`(?i)\b(shit\w*|bullshit|bitch\w*|ass(hole)?s?|bastards?|damn\w*|dick(head)?s?|piss(ed)?|crap(py)?)\b`)},
+	}
+)
+
+// KeywordModerator is a lightweight moderator that flags AI output by word lists.
+// It makes no network calls, so it can screen every reply.
+type KeywordModerator struct {
+	patterns []moderationPattern
+}
+
+// NewKeywordModerator creates a moderator for a strictness level. It returns nil when
+// moderation is off; callers treat a nil moderator as a no-op.
+func NewKeywordModerator(strictness string) ports.ContentModerator {
+	switch strictness {
+	case domain.ModerationStandard:
+		return &KeywordModerator{patterns: standardModerationPatterns}
+	case domain.ModerationStrict:
+		patterns := append([]moderationPattern{}, standardModerationPatterns...)
+		return &KeywordModerator{patterns: append(patterns, strictModerationPatterns...)}
+	}
+	return nil
+}
+
+// Moderate flags text under every category one of its word lists matches
+func (m *KeywordModerator) Moderate(ctx context.Context, text string) (*domain.ModerationResult, error) {
+	result := &domain.ModerationResult{}
+	seen := make(map[string]bool)
+	for _, p := range m.patterns {
+		if seen[p.category] || !p.pattern.MatchString(text) {
+			continue
+		}
+		seen[p.category] = true
+		result.Categories = append(result.Categories, p.category)
+	}
+	result.Flagged = len(result.Categories) > 0
+	return result, nil
+}
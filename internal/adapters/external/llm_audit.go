@@ -0,0 +1,110 @@
+package external
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// CallAuditor receives a record of every chat completion call a client makes.
+// Implementations must not fail the call; errors are theirs to log.
+type CallAuditor interface {
+	Record(ctx context.Context, entry *domain.LLMAuditEntry)
+}
+
+// Audit operations label which feature made an LLM call
+const (
+	AuditOperationAgent        = "agent"
+	AuditOperationMealParse    = "meal_parse"
+	AuditOperationFoodEstimate = "food_estimate"
+	AuditOperationVision       = "vision"
+	AuditOperationCoachDigest  = "coach_digest"
+	AuditOperationWeeklyRecap  = "weekly_recap"
+	AuditOperationChatSummary  = "chat_summary"
+	auditOperationUnknown      = "unknown"
+)
+
+type auditContextKey struct{}
+
+type auditContext struct {
+	operation string
+	userID    *uuid.UUID
+}
+
+// WithAuditContext tags calls made with ctx with the operation and the user they serve
+func WithAuditContext(ctx context.Context, operation string, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, auditContext{operation: operation, userID: &userID})
+}
+
+// withAuditOperation replaces the operation on ctx, keeping any user already set
+func withAuditOperation(ctx context.Context, operation string) context.Context {
+	info, _ := ctx.Value(auditContextKey{}).(auditContext)
+	info.operation = operation
+	return context.WithValue(ctx, auditContextKey{}, info)
+}
+
+// newAuditEntry builds the audit record of a finished chat request.
+// Request and response are stored unredacted; the auditor decides what to scrub.
+func newAuditEntry(ctx context.Context, chatReq ChatRequest, resp *ChatResponse, callErr error, latency time.Duration) *domain.LLMAuditEntry {
+	info, _ := ctx.Value(auditContextKey{}).(auditContext)
+	if info.operation == "" {
+		info.operation = auditOperationUnknown
+	}
+
+	requestJSON, _ := json.Marshal(chatReq)
+	messagesJSON, _ := json.Marshal(chatReq.Messages)
+	hash := sha256.Sum256(messagesJSON)
+
+	entry := &domain.LLMAuditEntry{
+		UserID:     info.userID,
+		Operation:  info.operation,
+		Model:      chatReq.Model,
+		PromptHash: hex.EncodeToString(hash[:]),
+		Request:    string(requestJSON),
+		LatencyMs:  latency.Milliseconds(),
+		Status:     "success",
+	}
+
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+
+	if callErr != nil {
+		message := callErr.Error()
+		entry.Status = "error"
+		entry.Error = &message
+	}
+
+	if resp != nil {
+		if responseJSON, err := json.Marshal(resp); err == nil {
+			response := string(responseJSON)
+			entry.Response = &response
+		}
+
+		entry.PromptTokens = resp.Usage.PromptTokens
+		entry.CompletionTokens = resp.Usage.CompletionTokens
+		entry.TotalTokens = resp.Usage.TotalTokens
+		entry.CostUSD = resp.Usage.Cost
+
+		var tools []string
+		for _, choice := range resp.Choices {
+			for _, call := range choice.Message.ToolCalls {
+				tools = append(tools, call.Function.Name)
+			}
+		}
+		if len(tools) > 0 {
+			toolsJSON, _ := json.Marshal(tools)
+			toolsUsed := string(toolsJSON)
+			entry.ToolsUsed = &toolsUsed
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,23 @@
+package external
+
+import (
+	"context"
+
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// LogEmailSender writes emails to the application log instead of delivering them.
+// Useful for development until a real email provider is configured.
+type LogEmailSender struct{}
+
+// NewLogEmailSender creates a new log-only email sender
+func NewLogEmailSender() ports.EmailSender {
+	return &LogEmailSender{}
+}
+
+// SendEmail logs the email recipient, subject and body
+func (s *LogEmailSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	requestid.Logf(ctx, "[Email] To: %s, Subject: %s\n%s", to, subject, body)
+	return nil
+}
@@ -0,0 +1,28 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// LogEventPublisher writes events to the application log.
+// Replace with a queue-backed publisher when downstream consumers exist.
+type LogEventPublisher struct{}
+
+// NewLogEventPublisher creates a new log-only event publisher
+func NewLogEventPublisher() ports.EventPublisher {
+	return &LogEventPublisher{}
+}
+
+// Publish logs the event type and JSON payload
+func (p *LogEventPublisher) Publish(ctx context.Context, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	requestid.Logf(ctx, "[Event] %s %s", eventType, data)
+	return nil
+}
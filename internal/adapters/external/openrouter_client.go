@@ -4,11 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/requestid"
 )
 
 const (
@@ -16,6 +24,20 @@ const (
 	defaultModel      = "deepseek/deepseek-chat"
 	maxRetries        = 3
 	retryDelay        = time.Second * 2
+
+	// maxRetryAfterWait is the longest Retry-After the client waits out; a longer one
+	// fails the call right away so the caller can pass the hint on
+	maxRetryAfterWait = 30 * time.Second
+
+	defaultTemperature = 0.7
+	defaultMaxTokens   = 4096
+)
+
+// Response format types
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
 )
 
 // OpenRouterClient handles communication with OpenRouter API
@@ -23,12 +45,110 @@ type OpenRouterClient struct {
 	apiKey     string
 	httpClient *http.Client
 	baseURL    string
+	auditor    CallAuditor // optional
+
+	// fallbackModels are tried in order when the requested model fails with a retryable error
+	fallbackModels []string
+
+	// throttledUntil is when each model's last Retry-After expires; no request is sent to it before then
+	mu             sync.Mutex
+	throttledUntil map[string]time.Time
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+
+	// Parts holds multimodal content; when set it is sent as the content array instead of Content.
+	// A content array received from the API is decoded into Parts, with its text joined into Content.
+	Parts []ContentPart `json:"-"`
+}
+
+// NewTextMessage creates a message with plain string content
+func NewTextMessage(role, text string) Message {
+	return Message{Role: role, Content: text}
+}
+
+// NewMultimodalMessage creates a message whose content is an array of parts
+func NewMultimodalMessage(role string, parts ...ContentPart) Message {
+	return Message{Role: role, Parts: parts}
+}
+
+// ContentPart is one element of a multimodal message content array
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by http(s) URL or base64 data URL
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// TextPart creates a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: "text", Text: text}
+}
+
+// ImagePart creates an image content part from an http(s) or data URL
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url}}
+}
+
+// MarshalJSON sends Parts as the content array when present, otherwise the plain string content
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plainMessage Message
+	if len(m.Parts) == 0 {
+		return json.Marshal(plainMessage(m))
+	}
+
+	return json.Marshal(struct {
+		plainMessage
+		Content []ContentPart `json:"content"`
+	}{
+		plainMessage: plainMessage(m),
+		Content:      m.Parts,
+	})
+}
+
+// UnmarshalJSON accepts content as a string, an array of parts or null
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type plainMessage Message
+	var raw struct {
+		plainMessage
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = Message(raw.plainMessage)
+	m.Content = ""
+	m.Parts = nil
+
+	content := bytes.TrimSpace(raw.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '[':
+		if err := json.Unmarshal(content, &m.Parts); err != nil {
+			return fmt.Errorf("invalid message content parts: %w", err)
+		}
+		var texts []string
+		for _, part := range m.Parts {
+			if part.Type == "text" {
+				texts = append(texts, part.Text)
+			}
+		}
+		m.Content = strings.Join(texts, "\n")
+		return nil
+	default:
+		return json.Unmarshal(content, &m.Content)
+	}
 }
 
 // Tool represents a function tool definition
@@ -54,14 +174,86 @@ type ToolCall struct {
 	} `json:"function"`
 }
 
+// ResponseFormat constrains the format of the model output
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"` // only for json_schema
+}
+
+// JSONSchema describes the structure a json_schema response must follow
+type JSONSchema struct {
+	Name   string                 `json:"name"`
+	Strict bool                   `json:"strict"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
 // ChatRequest represents a chat completion request
 type ChatRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Tools       []Tool    `json:"tools,omitempty"`
-	Temperature float64   `json:"temperature,omitempty"`
-	MaxTokens   int       `json:"max_tokens,omitempty"`
-	Stream      bool      `json:"stream,omitempty"`
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Tools          []Tool          `json:"tools,omitempty"`
+	ToolChoice     interface{}     `json:"tool_choice,omitempty"` // "auto", "none" or a specific function
+	Temperature    *float64        `json:"temperature,omitempty"` // pointer so 0 is sent
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	Usage          *UsageOptions   `json:"usage,omitempty"`
+}
+
+// UsageOptions asks the API to report usage details such as cost
+type UsageOptions struct {
+	Include bool `json:"include"`
+}
+
+// ChatOptions overrides generation settings for a single request.
+// Zero values fall back to the client defaults.
+type ChatOptions struct {
+	Temperature    *float64
+	MaxTokens      int
+	ResponseFormat *ResponseFormat
+	ToolChoice     interface{} // "auto", "none" or ForceToolChoice; only sent with tools
+}
+
+// ForceToolChoice builds a tool_choice value that makes the model call the named function,
+// for extracting structured arguments instead of free text
+func ForceToolChoice(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "function",
+		"function": map[string]string{"name": name},
+	}
+}
+
+// ToolCallOptions returns options for structured extraction through a tool: temperature 0
+// and a forced call to the named tool, whose arguments are the extracted JSON
+func ToolCallOptions(name string) ChatOptions {
+	return ChatOptions{ToolChoice: ForceToolChoice(name)}.WithTemperature(0)
+}
+
+// JSONSchemaOptions returns structured-output options whose reply must match the given schema
+func JSONSchemaOptions(name string, schema map[string]interface{}) ChatOptions {
+	opts := StructuredOutputOptions()
+	opts.ResponseFormat = &ResponseFormat{
+		Type: ResponseFormatJSONSchema,
+		JSONSchema: &JSONSchema{
+			Name:   name,
+			Strict: true,
+			Schema: schema,
+		},
+	}
+	return opts
+}
+
+// StructuredOutputOptions returns options for parsing and estimation calls:
+// temperature 0 for reproducible results and JSON mode so the reply is a bare JSON object
+func StructuredOutputOptions() ChatOptions {
+	return ChatOptions{ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject}}.WithTemperature(0)
+}
+
+// WithTemperature returns the options with the sampling temperature set; 0 gives the most
+// repeatable replies
+func (o ChatOptions) WithTemperature(temperature float64) ChatOptions {
+	o.Temperature = &temperature
+	return o
 }
 
 // ChatResponse represents a chat completion response
@@ -71,19 +263,11 @@ type ChatResponse struct {
 	Created int64  `json:"created"`
 	Model   string `json:"model"`
 	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role      string     `json:"role"`
-			Content   string     `json:"content"`
-			ToolCalls []ToolCall `json:"tool_calls,omitempty"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
+		Index        int     `json:"index"`
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage Usage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -91,74 +275,225 @@ type ChatResponse struct {
 	} `json:"error,omitempty"`
 }
 
+// ToolArguments returns the arguments of the first choice's call to the named tool, and
+// false when the model answered without calling it
+func (r *ChatResponse) ToolArguments(name string) (string, bool) {
+	if len(r.Choices) == 0 {
+		return "", false
+	}
+	for _, call := range r.Choices[0].Message.ToolCalls {
+		if call.Function.Name == name {
+			return call.Function.Arguments, true
+		}
+	}
+	return "", false
+}
+
+// Usage reports the tokens, and when requested the cost, of a completion
+type Usage struct {
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	TotalTokens      int      `json:"total_tokens"`
+	Cost             *float64 `json:"cost,omitempty"` // USD; only reported when usage accounting is requested
+}
+
 // NewOpenRouterClient creates a new OpenRouter client
 func NewOpenRouterClient(apiKey string) *OpenRouterClient {
+	return NewOpenRouterClientWithBaseURL(apiKey, openRouterBaseURL)
+}
+
+// NewOpenRouterClientWithBaseURL creates a client against another OpenRouter-compatible endpoint
+func NewOpenRouterClientWithBaseURL(apiKey, baseURL string) *OpenRouterClient {
 	return &OpenRouterClient{
 		apiKey:  apiKey,
-		baseURL: openRouterBaseURL,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
 	}
 }
 
-// Chat sends a chat completion request
-func (c *OpenRouterClient) Chat(ctx context.Context, messages []Message, model string) (*ChatResponse, error) {
-	if model == "" {
-		model = defaultModel
-	}
+// WithAuditor records every chat request through the auditor, for an operational audit log
+func (c *OpenRouterClient) WithAuditor(auditor CallAuditor) *OpenRouterClient {
+	c.auditor = auditor
+	return c
+}
 
-	req := ChatRequest{
-		Model:       model,
-		Messages:    messages,
-		Temperature: 0.7,
-		MaxTokens:   4096,
+// WithBaseURL sends the client's requests to another OpenRouter-compatible endpoint.
+// An empty baseURL keeps the current one.
+func (c *OpenRouterClient) WithBaseURL(baseURL string) *OpenRouterClient {
+	if baseURL != "" {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
 	}
+	return c
+}
 
+// WithFallbackModels sets the models tried, in order, when the requested model is
+// rate limited or fails with a server or network error. The response's Model
+// reports which model served the request.
+func (c *OpenRouterClient) WithFallbackModels(models ...string) *OpenRouterClient {
+	c.fallbackModels = models
+	return c
+}
+
+// Chat sends a chat completion request
+func (c *OpenRouterClient) Chat(ctx context.Context, messages []Message, model string) (*ChatResponse, error) {
+	return c.ChatWithOptions(ctx, messages, model, ChatOptions{})
+}
+
+// ChatWithOptions sends a chat completion request with explicit generation settings
+func (c *OpenRouterClient) ChatWithOptions(ctx context.Context, messages []Message, model string, opts ChatOptions) (*ChatResponse, error) {
+	req := newChatRequest(messages, model, opts)
 	return c.sendChatRequest(ctx, req)
 }
 
 // ChatWithTools sends a chat completion request with tool support
 func (c *OpenRouterClient) ChatWithTools(ctx context.Context, messages []Message, tools []Tool, model string) (*ChatResponse, error) {
+	return c.ChatWithToolsAndOptions(ctx, messages, tools, model, ChatOptions{})
+}
+
+// ChatWithToolsAndOptions sends a chat completion request with tool support and the given
+// generation settings
+func (c *OpenRouterClient) ChatWithToolsAndOptions(ctx context.Context, messages []Message, tools []Tool, model string, opts ChatOptions) (*ChatResponse, error) {
+	req := newChatRequest(messages, model, opts)
+	req.Tools = tools
+
+	return c.sendChatRequest(ctx, req)
+}
+
+// newChatRequest builds a request, filling unset options with the client defaults
+func newChatRequest(messages []Message, model string, opts ChatOptions) ChatRequest {
 	if model == "" {
 		model = defaultModel
 	}
 
-	req := ChatRequest{
-		Model:       model,
-		Messages:    messages,
-		Tools:       tools,
-		Temperature: 0.7,
-		MaxTokens:   4096,
+	temperature := defaultTemperature
+	if opts.Temperature != nil {
+		temperature = *opts.Temperature
 	}
 
-	return c.sendChatRequest(ctx, req)
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	return ChatRequest{
+		Model:          model,
+		Messages:       messages,
+		Temperature:    &temperature,
+		MaxTokens:      maxTokens,
+		ResponseFormat: opts.ResponseFormat,
+		ToolChoice:     opts.ToolChoice,
+	}
 }
 
-// sendChatRequest sends a chat request with retry logic
+// sendChatRequest sends a chat request to the requested model and then, while the
+// failure is retryable, to each fallback model in turn
 func (c *OpenRouterClient) sendChatRequest(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	models := c.modelChain(chatReq.Model)
+
+	for i, model := range models {
+		chatReq.Model = model
+		hasFallback := i < len(models)-1
+
+		resp, err := c.sendToModel(ctx, chatReq, hasFallback)
+		if err == nil {
+			if resp.Model == "" {
+				resp.Model = model
+			}
+			return resp, nil
+		}
+		if !hasFallback || ctx.Err() != nil || !isRetryableError(err) {
+			return nil, err
+		}
+		requestid.Logf(ctx, "[OpenRouter] Model %s failed, falling back to %s: %v", model, models[i+1], err)
+	}
+
+	return nil, fmt.Errorf("no model to send the request to")
+}
+
+// modelChain returns the requested model followed by the fallback models, without repeats
+func (c *OpenRouterClient) modelChain(primary string) []string {
+	models := []string{primary}
+	for _, model := range c.fallbackModels {
+		if model != "" && !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// sendToModel sends a chat request to one model and reports the outcome to the auditor, if any
+func (c *OpenRouterClient) sendToModel(ctx context.Context, chatReq ChatRequest, hasFallback bool) (*ChatResponse, error) {
+	if c.auditor == nil {
+		resp, err := c.sendWithRetry(ctx, chatReq, hasFallback)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	// Ask for usage accounting so the audit entry carries the cost
+	chatReq.Usage = &UsageOptions{Include: true}
+
+	start := time.Now()
+	resp, err := c.sendWithRetry(ctx, chatReq, hasFallback)
+	c.auditor.Record(context.WithoutCancel(ctx), newAuditEntry(ctx, chatReq, resp, err, time.Since(start)))
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sendWithRetry sends a chat request with retry logic. An API error in the body is returned
+// together with the response so it can be audited. When a fallback model is available a
+// rate-limited model is not waited for, so the caller can move on right away.
+func (c *OpenRouterClient) sendWithRetry(ctx context.Context, chatReq ChatRequest, hasFallback bool) (*ChatResponse, error) {
 	var lastErr error
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		// Wait for the linear backoff or the provider's Retry-After, whichever is longer
+		throttled := c.throttleRemaining(chatReq.Model)
+		if throttled > 0 && hasFallback {
+			return nil, &domain.RateLimitError{RetryAfter: throttled, Message: "OpenRouter is throttling requests to " + chatReq.Model}
+		}
+		delay := throttled
 		if attempt > 0 {
-			log.Printf("[OpenRouter] Retry attempt %d/%d after error: %v", attempt+1, maxRetries, lastErr)
+			requestid.Logf(ctx, "[OpenRouter] Retry attempt %d/%d after error: %v", attempt+1, maxRetries, lastErr)
+			delay = max(delay, retryDelay*time.Duration(attempt))
+		}
+		if delay > maxRetryAfterWait {
+			return nil, &domain.RateLimitError{RetryAfter: delay, Message: "OpenRouter is throttling requests"}
+		}
+		if delay > 0 {
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
-			case <-time.After(retryDelay * time.Duration(attempt)):
+			case <-time.After(delay):
 			}
 		}
 
 		resp, err := c.doRequest(ctx, chatReq)
 		if err == nil {
 			if resp.Error != nil {
-				return nil, fmt.Errorf("OpenRouter API error: %s (type: %s, code: %s)", resp.Error.Message, resp.Error.Type, resp.Error.Code)
+				return resp, fmt.Errorf("OpenRouter API error: %s (type: %s, code: %s)", resp.Error.Message, resp.Error.Type, resp.Error.Code)
 			}
 			return resp, nil
 		}
 
 		lastErr = err
 
+		var rateLimited *domain.RateLimitError
+		if errors.As(err, &rateLimited) {
+			if rateLimited.RetryAfter > 0 {
+				requestid.Logf(ctx, "[OpenRouter] Rate limited, retry after %s", rateLimited.RetryAfter)
+				c.throttle(chatReq.Model, rateLimited.RetryAfter)
+			}
+			if hasFallback {
+				return nil, err
+			}
+		}
+
 		// Don't retry on context errors
 		if ctx.Err() != nil {
 			return nil, ctx.Err()
@@ -175,7 +510,11 @@ func (c *OpenRouterClient) doRequest(ctx context.Context, chatReq ChatRequest) (
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	log.Printf("[OpenRouter] Request: model=%s, messages=%d, tools=%d", chatReq.Model, len(chatReq.Messages), len(chatReq.Tools))
+	responseFormat := ResponseFormatText
+	if chatReq.ResponseFormat != nil {
+		responseFormat = chatReq.ResponseFormat.Type
+	}
+	requestid.Logf(ctx, "[OpenRouter] Request: model=%s, messages=%d, tools=%d, response_format=%s", chatReq.Model, len(chatReq.Messages), len(chatReq.Tools), responseFormat)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
 	if err != nil {
@@ -198,10 +537,17 @@ func (c *OpenRouterClient) doRequest(ctx context.Context, chatReq ChatRequest) (
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	log.Printf("[OpenRouter] Response: status=%d, body_length=%d", resp.StatusCode, len(respBody))
+	requestid.Logf(ctx, "[OpenRouter] Response: status=%d, body_length=%d", resp.StatusCode, len(respBody))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &domain.RateLimitError{
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Message:    string(respBody),
+		}
+	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(respBody))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var chatResp ChatResponse
@@ -210,7 +556,7 @@ func (c *OpenRouterClient) doRequest(ctx context.Context, chatReq ChatRequest) (
 	}
 
 	if len(chatResp.Choices) > 0 {
-		log.Printf("[OpenRouter] Success: content_length=%d, finish_reason=%s, tokens=%d",
+		requestid.Logf(ctx, "[OpenRouter] Success: content_length=%d, finish_reason=%s, tokens=%d",
 			len(chatResp.Choices[0].Message.Content),
 			chatResp.Choices[0].FinishReason,
 			chatResp.Usage.TotalTokens)
@@ -218,3 +564,72 @@ func (c *OpenRouterClient) doRequest(ctx context.Context, chatReq ChatRequest) (
 
 	return &chatResp, nil
 }
+
+// throttle holds back requests to model for d, unless an earlier Retry-After already lasts longer
+func (c *OpenRouterClient) throttle(model string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.throttledUntil == nil {
+		c.throttledUntil = make(map[string]time.Time)
+	}
+	if until := time.Now().Add(d); until.After(c.throttledUntil[model]) {
+		c.throttledUntil[model] = until
+	}
+}
+
+// throttleRemaining returns how long until requests may be sent to model again
+func (c *OpenRouterClient) throttleRemaining(model string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return max(0, time.Until(c.throttledUntil[model]))
+}
+
+// StatusError is a non-200 response from the API other than rate limiting
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// isRetryableError reports whether another model might succeed where err failed:
+// rate limiting, timeouts, server errors and failures to reach the API
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, domain.ErrRateLimited) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusRequestTimeout || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// parseRetryAfter reads a Retry-After header given as delay seconds or an HTTP date.
+// It returns 0 for a missing, malformed or past value.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(0, time.Duration(seconds)*time.Second)
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(0, at.Sub(now))
+	}
+
+	return 0
+}
@@ -3,12 +3,15 @@ package external
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"path/filepath"
 	"time"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/requestid"
 )
 
 const (
@@ -16,6 +19,7 @@ const (
 	bucketName          = "meal-photos"
 	maxUploadRetries    = 3
 	uploadRetryDelay    = time.Second * 2
+	listPageSize        = 100
 )
 
 // SupabaseStorageClient handles file storage operations with Supabase
@@ -49,7 +53,7 @@ func (c *SupabaseStorageClient) UploadImage(ctx context.Context, userID string,
 	var lastErr error
 	for attempt := 0; attempt < maxUploadRetries; attempt++ {
 		if attempt > 0 {
-			log.Printf("[Supabase] Upload retry attempt %d/%d after error: %v", attempt+1, maxUploadRetries, lastErr)
+			requestid.Logf(ctx, "[Supabase] Upload retry attempt %d/%d after error: %v", attempt+1, maxUploadRetries, lastErr)
 			select {
 			case <-ctx.Done():
 				return "", ctx.Err()
@@ -59,7 +63,7 @@ func (c *SupabaseStorageClient) UploadImage(ctx context.Context, userID string,
 
 		url, err := c.doUpload(ctx, objectPath, imageData)
 		if err == nil {
-			log.Printf("[Supabase] Successfully uploaded image: %s", objectPath)
+			requestid.Logf(ctx, "[Supabase] Successfully uploaded image: %s", objectPath)
 			return url, nil
 		}
 
@@ -78,7 +82,7 @@ func (c *SupabaseStorageClient) UploadImage(ctx context.Context, userID string,
 func (c *SupabaseStorageClient) doUpload(ctx context.Context, objectPath string, imageData []byte) (string, error) {
 	url := fmt.Sprintf("%s%s/%s/%s", c.projectURL, supabaseStoragePath, bucketName, objectPath)
 
-	log.Printf("[Supabase] Uploading to: %s (size: %d bytes)", objectPath, len(imageData))
+	requestid.Logf(ctx, "[Supabase] Uploading to: %s (size: %d bytes)", objectPath, len(imageData))
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(imageData))
 	if err != nil {
@@ -119,7 +123,7 @@ func (c *SupabaseStorageClient) GetPublicURL(objectPath string) string {
 func (c *SupabaseStorageClient) DeleteImage(ctx context.Context, objectPath string) error {
 	url := fmt.Sprintf("%s%s/%s/%s", c.projectURL, supabaseStoragePath, bucketName, objectPath)
 
-	log.Printf("[Supabase] Deleting: %s", objectPath)
+	requestid.Logf(ctx, "[Supabase] Deleting: %s", objectPath)
 
 	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
@@ -140,23 +144,66 @@ func (c *SupabaseStorageClient) DeleteImage(ctx context.Context, objectPath stri
 		return fmt.Errorf("delete failed with status %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	log.Printf("[Supabase] Successfully deleted: %s", objectPath)
+	requestid.Logf(ctx, "[Supabase] Successfully deleted: %s", objectPath)
 	return nil
 }
 
-// ListImages lists all images for a user
-func (c *SupabaseStorageClient) ListImages(ctx context.Context, userID string) ([]string, error) {
-	url := fmt.Sprintf("%s%s/list/%s?prefix=%s/", c.projectURL, supabaseStoragePath, bucketName, userID)
+// storageObject is an entry in a Supabase storage list response
+type storageObject struct {
+	ID        *string   `json:"id"` // nil for folders
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListImages lists all images for a user, following pagination
+func (c *SupabaseStorageClient) ListImages(ctx context.Context, userID string) ([]domain.StoredPhoto, error) {
+	requestid.Logf(ctx, "[Supabase] Listing images for user: %s", userID)
+
+	photos := []domain.StoredPhoto{}
+	for offset := 0; ; offset += listPageSize {
+		objects, err := c.listPage(ctx, userID, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			if object.ID == nil {
+				continue // folder placeholder
+			}
+			photos = append(photos, domain.StoredPhoto{
+				Path:      userID + "/" + object.Name,
+				CreatedAt: object.CreatedAt,
+			})
+		}
+
+		if len(objects) < listPageSize {
+			return photos, nil
+		}
+	}
+}
+
+// listPage fetches one page of objects under the user's folder
+func (c *SupabaseStorageClient) listPage(ctx context.Context, userID string, offset int) ([]storageObject, error) {
+	url := fmt.Sprintf("%s%s/list/%s", c.projectURL, supabaseStoragePath, bucketName)
 
-	log.Printf("[Supabase] Listing images for user: %s", userID)
+	body, err := json.Marshal(map[string]interface{}{
+		"prefix": userID,
+		"limit":  listPageSize,
+		"offset": offset,
+		"sortBy": map[string]string{"column": "name", "order": "asc"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.anonKey)
 	req.Header.Set("apikey", c.anonKey)
+	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -164,14 +211,19 @@ func (c *SupabaseStorageClient) ListImages(ctx context.Context, userID string) (
 	}
 	defer resp.Body.Close()
 
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("list failed with status %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	// Parse response to extract file paths
-	// This is simplified - actual implementation would parse JSON response
-	var files []string
-	// TODO: Parse actual JSON response from Supabase
-	return files, nil
+	var objects []storageObject
+	if err := json.Unmarshal(respBody, &objects); err != nil {
+		return nil, fmt.Errorf("failed to parse list response: %w", err)
+	}
+
+	return objects, nil
 }
@@ -2,21 +2,45 @@ package external
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/requestid"
 )
 
 const (
 	visionModel = "google/gemini-2.0-flash-exp:free"
+
+	// maxImageDataBytes caps the decoded size of a base64 image sent inline
+	maxImageDataBytes = 10 * 1024 * 1024
+
+	// maxVisionNotesLength caps the model's own words kept as notes when it answers
+	// without the expected JSON
+	maxVisionNotesLength = 300
+)
+
+// How usable the model found a photo. The model may report other values; these are the
+// ones the prompt asks for.
+const (
+	ImageQualityGood       = "good"
+	ImageQualityBlurry     = "blurry"
+	ImageQualityDark       = "dark"
+	ImageQualityObstructed = "obstructed"
+	ImageQualityUnknown    = "unknown" // the model did not say
 )
 
+// VisionNoteNoFood explains an analysis without items when the model gave no reason
+const VisionNoteNoFood = "no food detected"
+
 // VisionClient handles food photo analysis using vision models
 type VisionClient struct {
-	openRouter *OpenRouterClient
+	openRouter  *OpenRouterClient
+	temperature float64 // 0 unless set with WithTemperature
 }
 
 // FoodItem represents a detected food item from the image
@@ -24,7 +48,7 @@ type FoodItem struct {
 	Name        string  `json:"name"`
 	Quantity    float64 `json:"quantity"`
 	Unit        string  `json:"unit"`
-	Calories    int     `json:"calories,omitempty"`
+	Calories    float64 `json:"calories,omitempty"`
 	Protein     float64 `json:"protein,omitempty"`
 	Carbs       float64 `json:"carbs,omitempty"`
 	Fat         float64 `json:"fat,omitempty"`
@@ -32,7 +56,8 @@ type FoodItem struct {
 	Description string  `json:"description,omitempty"`
 }
 
-// FoodAnalysisResult contains all detected food items
+// FoodAnalysisResult contains all detected food items. When the model identifies no
+// food, Items is empty and ImageQuality and Notes say why, e.g. a blurry photo.
 type FoodAnalysisResult struct {
 	Items       []FoodItem `json:"items"`
 	TotalItems  int        `json:"total_items"`
@@ -47,9 +72,78 @@ func NewVisionClient(apiKey string) *VisionClient {
 	}
 }
 
-// AnalyzeFoodPhoto analyzes a food photo and returns structured food data
-func (c *VisionClient) AnalyzeFoodPhoto(ctx context.Context, imageURL string) (*FoodAnalysisResult, error) {
-	log.Printf("[Vision] Analyzing food photo: %s", imageURL)
+// NewVisionClientWithBaseURL creates a vision client against another OpenRouter-compatible endpoint
+func NewVisionClientWithBaseURL(apiKey, baseURL string) *VisionClient {
+	return &VisionClient{
+		openRouter: NewOpenRouterClientWithBaseURL(apiKey, baseURL),
+	}
+}
+
+// WithAuditor records the client's vision calls through the auditor
+func (c *VisionClient) WithAuditor(auditor CallAuditor) *VisionClient {
+	c.openRouter.WithAuditor(auditor)
+	return c
+}
+
+// WithBaseURL sends the client's requests to another OpenRouter-compatible endpoint
+func (c *VisionClient) WithBaseURL(baseURL string) *VisionClient {
+	c.openRouter.WithBaseURL(baseURL)
+	return c
+}
+
+// WithTemperature sets the sampling temperature of photo analysis
+func (c *VisionClient) WithTemperature(temperature float64) *VisionClient {
+	c.temperature = temperature
+	return c
+}
+
+// ImageDataURL encodes raw image bytes as a base64 data URL
+func ImageDataURL(mimeType string, data []byte) string {
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// ValidateImageURL checks that image is an http(s) URL or a base64 data URL of an image
+func ValidateImageURL(image string) error {
+	if strings.HasPrefix(image, "https://") || strings.HasPrefix(image, "http://") {
+		return nil
+	}
+	if !strings.HasPrefix(image, "data:") {
+		return fmt.Errorf("%w: image must be an http(s) URL or a base64 data URL", domain.ErrInvalidInput)
+	}
+
+	header, payload, ok := strings.Cut(strings.TrimPrefix(image, "data:"), ",")
+	if !ok || !strings.HasSuffix(header, ";base64") {
+		return fmt.Errorf("%w: data URL must be base64 encoded", domain.ErrInvalidInput)
+	}
+	if !strings.HasPrefix(strings.TrimSuffix(header, ";base64"), "image/") {
+		return fmt.Errorf("%w: data URL must contain an image", domain.ErrInvalidInput)
+	}
+	if base64.StdEncoding.DecodedLen(len(payload)) > maxImageDataBytes {
+		return fmt.Errorf("%w: image exceeds %d bytes", domain.ErrInvalidInput, maxImageDataBytes)
+	}
+	if _, err := base64.StdEncoding.DecodeString(payload); err != nil {
+		return fmt.Errorf("%w: invalid base64 image data", domain.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// describeImage returns a loggable form of an image reference without inline data
+func describeImage(image string) string {
+	if header, _, ok := strings.Cut(image, ","); ok && strings.HasPrefix(image, "data:") {
+		return fmt.Sprintf("%s (%d bytes)", header, len(image))
+	}
+	return image
+}
+
+// AnalyzeFoodPhoto analyzes a food photo and returns structured food data.
+// The image is an http(s) URL or a base64 data URL (see ImageDataURL).
+func (c *VisionClient) AnalyzeFoodPhoto(ctx context.Context, image string) (*FoodAnalysisResult, error) {
+	if err := ValidateImageURL(image); err != nil {
+		return nil, err
+	}
+
+	requestid.Logf(ctx, "[Vision] Analyzing food photo: %s", describeImage(image))
 
 	// Create vision prompt
 	prompt := `Analyze this food image and identify all food items visible. For each item, provide:
@@ -58,33 +152,37 @@ func (c *VisionClient) AnalyzeFoodPhoto(ctx context.Context, imageURL string) (*
 3. Unit of measurement (e.g., cup, piece, gram, oz)
 4. Brief description
 
-Format your response as a JSON array of food items like this:
-[
-  {
-    "name": "Grilled Chicken Breast",
-    "quantity": 6,
-    "unit": "oz",
-    "description": "Grilled boneless chicken breast"
-  },
-  {
-    "name": "Steamed Broccoli",
-    "quantity": 1,
-    "unit": "cup",
-    "description": "Fresh steamed broccoli florets"
-  }
-]
-
-Be specific about the food items and realistic about portion sizes. Only include items you can clearly identify.`
+Also rate the photo's image_quality as "good", "blurry", "dark" or "obstructed", and add short notes on anything that limited the analysis.
+
+Format your response as a JSON object with an "items" array like this:
+{
+  "image_quality": "good",
+  "notes": "",
+  "items": [
+    {
+      "name": "Grilled Chicken Breast",
+      "quantity": 6,
+      "unit": "oz",
+      "description": "Grilled boneless chicken breast"
+    },
+    {
+      "name": "Steamed Broccoli",
+      "quantity": 1,
+      "unit": "cup",
+      "description": "Fresh steamed broccoli florets"
+    }
+  ]
+}
+
+Be specific about the food items and realistic about portion sizes. Only include items you can clearly identify.
+If you cannot identify any food, return an empty "items" array and say why in notes, e.g. "no food detected".`
 
 	messages := []Message{
-		{
-			Role: "user",
-			Content: fmt.Sprintf(`[{"type": "image_url", "image_url": {"url": "%s"}}, {"type": "text", "text": "%s"}]`,
-				imageURL, prompt),
-		},
+		NewMultimodalMessage("user", ImagePart(image), TextPart(prompt)),
 	}
 
-	resp, err := c.openRouter.Chat(ctx, messages, visionModel)
+	ctx = withAuditOperation(ctx, AuditOperationVision)
+	resp, err := c.openRouter.ChatWithOptions(ctx, messages, visionModel, StructuredOutputOptions().WithTemperature(c.temperature))
 	if err != nil {
 		return nil, fmt.Errorf("vision API call failed: %w", err)
 	}
@@ -94,33 +192,46 @@ Be specific about the food items and realistic about portion sizes. Only include
 	}
 
 	content := resp.Choices[0].Message.Content
-	log.Printf("[Vision] Raw response: %s", content)
-
-	// Parse the response
-	result, err := c.parseVisionResponse(content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse vision response: %w", err)
+	requestid.Logf(ctx, "[Vision] Raw response: %s", content)
+
+	// A reply naming no food is a result, not an error: the caller asks the user to
+	// retake the photo or enter the meal by hand
+	result := c.parseVisionResponse(content)
+	if len(result.Items) == 0 {
+		requestid.Logf(ctx, "[Vision] No food detected (image quality: %s): %s", result.ImageQuality, result.Notes)
+		return result, nil
 	}
 
-	log.Printf("[Vision] Detected %d food items", len(result.Items))
+	requestid.Logf(ctx, "[Vision] Detected %d food items", len(result.Items))
 	return result, nil
 }
 
-// parseVisionResponse parses the vision model response into structured data
-func (c *VisionClient) parseVisionResponse(content string) (*FoodAnalysisResult, error) {
-	// Try to extract JSON array from response
-	jsonStr := extractJSON(content)
-	if jsonStr == "" {
-		return nil, fmt.Errorf("no valid JSON found in response")
-	}
-
+// parseVisionResponse parses the vision model response into structured data. A reply
+// that lists no usable items gives an empty result, with the model's prose as notes when
+// it did not answer in JSON.
+func (c *VisionClient) parseVisionResponse(content string) *FoodAnalysisResult {
 	var items []FoodItem
-	if err := json.Unmarshal([]byte(jsonStr), &items); err != nil {
+	result := &FoodAnalysisResult{ImageQuality: ImageQualityUnknown}
+
+	// JSON mode returns {"items": [...]}; models that ignore response_format
+	// may still return a bare or prose-wrapped array
+	var wrapped struct {
+		Items        []FoodItem `json:"items"`
+		ImageQuality string     `json:"image_quality"`
+		Notes        string     `json:"notes"`
+	}
+	if err := DecodeJSONContent(content, &wrapped); err == nil && (len(wrapped.Items) > 0 || wrapped.ImageQuality != "" || wrapped.Notes != "") {
+		items = wrapped.Items
+		if quality := strings.ToLower(strings.TrimSpace(wrapped.ImageQuality)); quality != "" {
+			result.ImageQuality = quality
+		}
+		result.Notes = strings.TrimSpace(wrapped.Notes)
+	} else if jsonStr := extractJSON(content); jsonStr == "" {
+		// Prose without any JSON explains why nothing was found
+		result.Notes = truncateNotes(content)
+	} else if err := json.Unmarshal([]byte(jsonStr), &items); err != nil {
 		// If JSON parsing fails, try to parse manually
 		items = c.parseManually(content)
-		if len(items) == 0 {
-			return nil, fmt.Errorf("failed to parse response: %w", err)
-		}
 	}
 
 	// Validate and clean items
@@ -133,10 +244,21 @@ func (c *VisionClient) parseVisionResponse(content string) (*FoodAnalysisResult,
 		}
 	}
 
-	return &FoodAnalysisResult{
-		Items:      validItems,
-		TotalItems: len(validItems),
-	}, nil
+	result.Items = validItems
+	result.TotalItems = len(validItems)
+	if len(validItems) == 0 && result.Notes == "" {
+		result.Notes = VisionNoteNoFood
+	}
+	return result
+}
+
+// truncateNotes trims the model's prose to maxVisionNotesLength runes
+func truncateNotes(content string) string {
+	notes := strings.TrimSpace(content)
+	if runes := []rune(notes); len(runes) > maxVisionNotesLength {
+		notes = strings.TrimSpace(string(runes[:maxVisionNotesLength])) + "…"
+	}
+	return notes
 }
 
 // parseManually attempts to parse the response manually if JSON parsing fails
@@ -226,8 +348,8 @@ func normalizeUnit(unit string) string {
 }
 
 // AnalyzeFoodPhotoWithNutrition analyzes food and enriches with nutrition data
-func (c *VisionClient) AnalyzeFoodPhotoWithNutrition(ctx context.Context, imageURL string) (*FoodAnalysisResult, error) {
-	result, err := c.AnalyzeFoodPhoto(ctx, imageURL)
+func (c *VisionClient) AnalyzeFoodPhotoWithNutrition(ctx context.Context, image string) (*FoodAnalysisResult, error) {
+	result, err := c.AnalyzeFoodPhoto(ctx, image)
 	if err != nil {
 		return nil, err
 	}
@@ -250,23 +372,23 @@ func (c *VisionClient) estimateNutrition(item *FoodItem) {
 	// Very basic estimation based on common foods
 	switch {
 	case strings.Contains(name, "chicken"):
-		item.Calories = int(float64(165) * (item.Quantity / 3.5)) // per 3.5oz
+		item.Calories = float64(165) * (item.Quantity / 3.5) // per 3.5oz
 		item.Protein = float64(31) * (item.Quantity / 3.5)
 		item.Carbs = 0
 		item.Fat = float64(3.6) * (item.Quantity / 3.5)
 	case strings.Contains(name, "broccoli"):
-		item.Calories = int(float64(55) * item.Quantity) // per cup
+		item.Calories = float64(55) * item.Quantity // per cup
 		item.Protein = float64(3.7) * item.Quantity
 		item.Carbs = float64(11) * item.Quantity
 		item.Fat = float64(0.6) * item.Quantity
 	case strings.Contains(name, "rice"):
-		item.Calories = int(float64(205) * item.Quantity) // per cup
+		item.Calories = float64(205) * item.Quantity // per cup
 		item.Protein = float64(4.3) * item.Quantity
 		item.Carbs = float64(45) * item.Quantity
 		item.Fat = float64(0.4) * item.Quantity
 	default:
 		// Default moderate estimate
-		item.Calories = int(float64(150) * item.Quantity)
+		item.Calories = float64(150) * item.Quantity
 		item.Protein = float64(10) * item.Quantity
 		item.Carbs = float64(15) * item.Quantity
 		item.Fat = float64(5) * item.Quantity
@@ -15,13 +15,64 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// ForgotPasswordRequest requests a password reset token
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password using a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// DeleteAccountRequest confirms account deletion
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+	Export   bool   `json:"export"` // return a copy of all data before deleting
+}
+
+// UpdateProfileRequest updates profile fields; omitted fields are unchanged
+type UpdateProfileRequest struct {
+	Name               *string                `json:"name,omitempty" validate:"omitempty,min=2,max=200"`
+	HeightCm           *float64               `json:"height_cm,omitempty" validate:"omitempty,gt=0"`
+	WeightKg           *float64               `json:"weight_kg,omitempty" validate:"omitempty,gt=0"`
+	ActivityLevel      *string                `json:"activity_level,omitempty"`
+	Timezone           *string                `json:"timezone,omitempty"`
+	UnitSystem         *string                `json:"unit_system,omitempty" validate:"omitempty,oneof=metric imperial"`
+	DietaryPreferences map[string]interface{} `json:"dietary_preferences,omitempty"`
+	CalorieTargetMode  *string                `json:"calorie_target_mode,omitempty" validate:"omitempty,oneof=static dynamic"` // dynamic adds a share of burned calories to the target
+	ExerciseCalorieFraction *float64          `json:"exercise_calorie_fraction,omitempty" validate:"omitempty,gte=0,lte=1"`
+}
+
+// UpdateNutritionTargetsRequest sets the daily nutrition target, either manually or, with
+// derive_from_profile, from the profile's height, weight, age and activity level
+type UpdateNutritionTargetsRequest struct {
+	DeriveFromProfile bool     `json:"derive_from_profile"`
+	GoalType          string   `json:"goal_type,omitempty" validate:"omitempty,max=50"` // with derive_from_profile: cut, maintain, bulk or a configured preset
+	Calories          *float64 `json:"calories,omitempty" validate:"omitempty,gt=0"`
+	Protein           *float64 `json:"protein,omitempty" validate:"omitempty,gte=0"`
+	Carbohydrates     *float64 `json:"carbohydrates,omitempty" validate:"omitempty,gte=0"`
+	Fat               *float64 `json:"fat,omitempty" validate:"omitempty,gte=0"`
+	WaterMl           *float64 `json:"water_ml,omitempty" validate:"omitempty,gte=0"`
+}
+
+// UpdateMealDistributionRequest sets the percent of the day's target planned for each
+// meal type; the four must add up to 100
+type UpdateMealDistributionRequest struct {
+	Breakfast *float64 `json:"breakfast" validate:"required,gte=0,lte=100"`
+	Lunch     *float64 `json:"lunch" validate:"required,gte=0,lte=100"`
+	Dinner    *float64 `json:"dinner" validate:"required,gte=0,lte=100"`
+	Snack     *float64 `json:"snack" validate:"required,gte=0,lte=100"`
+}
+
 // CreateMealRequest represents a new meal entry
 type CreateMealRequest struct {
 	Name        string    `json:"name" validate:"required"`
-	MealType    string    `json:"meal_type" validate:"required,oneof=breakfast lunch dinner snack"`
+	MealType    string    `json:"meal_type" validate:"required,max=50"`
 	ConsumedAt  time.Time `json:"consumed_at" validate:"required"`
 	Foods       []FoodItem `json:"foods" validate:"required,dive"`
-	TotalCalories int     `json:"total_calories,omitempty"`
+	TotalCalories float64 `json:"total_calories,omitempty"`
 	TotalProtein  float64 `json:"total_protein,omitempty"`
 	TotalCarbs    float64 `json:"total_carbs,omitempty"`
 	TotalFat      float64 `json:"total_fat,omitempty"`
@@ -38,7 +89,7 @@ type FoodItem struct {
 // ParseMealRequest represents natural language meal input
 type ParseMealRequest struct {
 	Description string    `json:"description" validate:"required"`
-	MealType    string    `json:"meal_type" validate:"required,oneof=breakfast lunch dinner snack"`
+	MealType    string    `json:"meal_type" validate:"required,max=50"`
 	ConsumedAt  time.Time `json:"consumed_at,omitempty"`
 }
 
@@ -46,13 +97,23 @@ type ParseMealRequest struct {
 type ConfirmMealRequest struct {
 	ParsedMealID string `json:"parsed_meal_id" validate:"required"`
 	Adjustments  *CreateMealRequest `json:"adjustments,omitempty"`
+	// Items tweaks the parsed food items before saving. Items not listed are saved as parsed.
+	Items []ConfirmMealItem `json:"items,omitempty" validate:"omitempty,dive"`
+}
+
+// ConfirmMealItem excludes one parsed food item, by its position in the parsed meal, or
+// overrides its quantity
+type ConfirmMealItem struct {
+	Index    int      `json:"index" validate:"gte=0"`
+	Exclude  bool     `json:"exclude,omitempty"`
+	Quantity *float64 `json:"quantity,omitempty" validate:"omitempty,gt=0"`
 }
 
 // CreateFoodRequest represents a new custom food entry
 type CreateFoodRequest struct {
 	Name        string  `json:"name" validate:"required"`
 	Brand       string  `json:"brand,omitempty"`
-	Calories    int     `json:"calories" validate:"required,gte=0"`
+	Calories    float64 `json:"calories" validate:"required,gte=0"`
 	Protein     float64 `json:"protein" validate:"required,gte=0"`
 	Carbs       float64 `json:"carbs" validate:"required,gte=0"`
 	Fat         float64 `json:"fat" validate:"required,gte=0"`
@@ -63,16 +124,35 @@ type CreateFoodRequest struct {
 	Barcode     string  `json:"barcode,omitempty"`
 }
 
+// EstimateFoodRequest asks for the nutrition of a freeform food without saving it
+type EstimateFoodRequest struct {
+	Name     string  `json:"name" validate:"required,max=200"`
+	Quantity float64 `json:"quantity,omitempty" validate:"gte=0"` // default 1
+	Unit     string  `json:"unit,omitempty" validate:"max=50"`    // default "serving"
+}
+
 // CreateActivityRequest represents a new activity entry
 type CreateActivityRequest struct {
 	ActivityType string    `json:"activity_type" validate:"required"`
 	StartTime    time.Time `json:"start_time" validate:"required"`
 	EndTime      time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
 	Duration     int       `json:"duration,omitempty"` // in minutes
-	Calories     int       `json:"calories,omitempty"`
+	Calories     float64   `json:"calories,omitempty"`
+	CalorieSource string   `json:"calorie_source,omitempty" validate:"omitempty,oneof=device manual estimate"`
 	Distance     float64   `json:"distance,omitempty"` // in km
 	HeartRate    int       `json:"heart_rate,omitempty"`
 	Notes        string    `json:"notes,omitempty"`
+
+	// Route is the GPS track in recording order. Without a distance, the distance is
+	// measured along it.
+	Route []RoutePoint `json:"route,omitempty" validate:"omitempty,min=2,max=50000,dive"`
+}
+
+// RoutePoint is one GPS fix of an activity's route
+type RoutePoint struct {
+	Lat  float64   `json:"lat" validate:"gte=-90,lte=90"`
+	Lng  float64   `json:"lng" validate:"gte=-180,lte=180"`
+	Time time.Time `json:"time" validate:"required"`
 }
 
 // StartWorkoutRequest represents starting a new workout
@@ -83,6 +163,11 @@ type StartWorkoutRequest struct {
 	Notes     string    `json:"notes,omitempty"`
 }
 
+// ReorderWorkoutExercisesRequest lists workout exercise IDs in their new order
+type ReorderWorkoutExercisesRequest struct {
+	WorkoutExerciseIDs []string `json:"workout_exercise_ids" validate:"required,min=1"`
+}
+
 // LogSetRequest represents logging a set during a workout
 type LogSetRequest struct {
 	WorkoutID  string  `json:"workout_id" validate:"required"`
@@ -92,6 +177,45 @@ type LogSetRequest struct {
 	Weight     float64 `json:"weight,omitempty"`
 	Duration   int     `json:"duration,omitempty"` // in seconds
 	Notes      string  `json:"notes,omitempty"`
+
+	// RPE rates how hard the set felt, 0-10; 10 means taken to failure
+	RPE *float64 `json:"rpe,omitempty" validate:"omitempty,gte=0,lte=10"`
+
+	// SetType defaults to normal. Sets sharing a client-generated GroupID form a superset
+	// or a drop set.
+	SetType string `json:"set_type,omitempty" validate:"omitempty,oneof=normal warmup drop superset"`
+	GroupID string `json:"group_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// LogSetBatchRequest logs several sets of one workout exercise. Set numbers are assigned
+// in order after the exercise's last set. Sets are validated one by one by the service so
+// a bad set does not reject the others.
+type LogSetBatchRequest struct {
+	Sets []BatchSetRequest `json:"sets" validate:"required,min=1,dive"`
+}
+
+// BatchSetRequest is one set of a LogSetBatchRequest
+type BatchSetRequest struct {
+	Reps            *int     `json:"reps,omitempty"`
+	Weight          *float64 `json:"weight,omitempty"`
+	DurationSeconds *int     `json:"duration_seconds,omitempty"`
+	Distance        *float64 `json:"distance,omitempty"`
+	RestSeconds     *int     `json:"rest_seconds,omitempty"`
+	RPE             *float64 `json:"rpe,omitempty"`
+	SetType         string   `json:"set_type,omitempty"`
+	GroupID         string   `json:"group_id,omitempty" validate:"omitempty,uuid"`
+	Notes           string   `json:"notes,omitempty"`
+}
+
+// CreateExerciseRequest represents adding an exercise to the library. CanonicalID makes
+// the new exercise an alias whose sets count toward the canonical exercise.
+type CreateExerciseRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Category    string `json:"category" validate:"required,max=100"`
+	MuscleGroup string `json:"muscle_group,omitempty" validate:"omitempty,max=100"`
+	Equipment   string `json:"equipment,omitempty" validate:"omitempty,max=100"`
+	Description string `json:"description,omitempty"`
+	CanonicalID string `json:"canonical_id,omitempty" validate:"omitempty,uuid"`
 }
 
 // LogMetricRequest represents logging a body metric
@@ -103,9 +227,23 @@ type LogMetricRequest struct {
 	Notes      string    `json:"notes,omitempty"`
 }
 
+// LogMetricBatchRequest logs many body metrics at once, e.g. a wearable sync. Rows are
+// validated one by one by the service so a bad row does not reject the whole batch.
+type LogMetricBatchRequest struct {
+	Metrics []LogMetricRequest `json:"metrics" validate:"required,min=1"`
+}
+
+// UpdateMetricRequest corrects a logged metric; omitted fields are unchanged
+type UpdateMetricRequest struct {
+	Value      *float64   `json:"value,omitempty" validate:"omitempty,gt=0"`
+	Unit       *string    `json:"unit,omitempty" validate:"omitempty,min=1"`
+	MeasuredAt *time.Time `json:"measured_at,omitempty"`
+	Notes      *string    `json:"notes,omitempty"`
+}
+
 // CreateGoalRequest represents a new fitness goal
 type CreateGoalRequest struct {
-	GoalType    string    `json:"goal_type" validate:"required,oneof=weight calorie_intake protein_intake workout_frequency custom"`
+	GoalType    string    `json:"goal_type" validate:"required,oneof=weight calorie_intake protein_intake calories protein carbohydrates fat workout_frequency custom"`
 	TargetValue float64   `json:"target_value" validate:"required"`
 	CurrentValue float64  `json:"current_value,omitempty"`
 	Unit        string    `json:"unit" validate:"required"`
@@ -10,10 +10,11 @@ type AuthResponse struct {
 
 // UserData represents user information
 type UserData struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // MealResponse represents a meal entry
@@ -24,7 +25,7 @@ type MealResponse struct {
 	MealType      string         `json:"meal_type"`
 	ConsumedAt    time.Time      `json:"consumed_at"`
 	Foods         []FoodItemResponse `json:"foods"`
-	TotalCalories int            `json:"total_calories"`
+	TotalCalories float64        `json:"total_calories"`
 	TotalProtein  float64        `json:"total_protein"`
 	TotalCarbs    float64        `json:"total_carbs"`
 	TotalFat      float64        `json:"total_fat"`
@@ -39,7 +40,7 @@ type FoodItemResponse struct {
 	Name     string  `json:"name"`
 	Quantity float64 `json:"quantity"`
 	Unit     string  `json:"unit"`
-	Calories int     `json:"calories"`
+	Calories float64 `json:"calories"`
 	Protein  float64 `json:"protein"`
 	Carbs    float64 `json:"carbs"`
 	Fat      float64 `json:"fat"`
@@ -51,7 +52,7 @@ type ParsedMealResponse struct {
 	OriginalText  string         `json:"original_text"`
 	MealType      string         `json:"meal_type"`
 	DetectedFoods []DetectedFood `json:"detected_foods"`
-	TotalCalories int            `json:"total_calories"`
+	TotalCalories float64        `json:"total_calories"`
 	TotalProtein  float64        `json:"total_protein"`
 	TotalCarbs    float64        `json:"total_carbs"`
 	TotalFat      float64        `json:"total_fat"`
@@ -63,7 +64,7 @@ type DetectedFood struct {
 	Name       string  `json:"name"`
 	Quantity   float64 `json:"quantity"`
 	Unit       string  `json:"unit"`
-	Calories   int     `json:"calories"`
+	Calories   float64 `json:"calories"`
 	Protein    float64 `json:"protein"`
 	Carbs      float64 `json:"carbs"`
 	Fat        float64 `json:"fat"`
@@ -75,7 +76,7 @@ type FoodResponse struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Brand       string    `json:"brand,omitempty"`
-	Calories    int       `json:"calories"`
+	Calories    float64   `json:"calories"`
 	Protein     float64   `json:"protein"`
 	Carbs       float64   `json:"carbs"`
 	Fat         float64   `json:"fat"`
@@ -85,6 +86,9 @@ type FoodResponse struct {
 	ServingUnit string    `json:"serving_unit"`
 	Barcode     string    `json:"barcode,omitempty"`
 	IsCustom    bool      `json:"is_custom"`
+	IsStarred   bool      `json:"is_starred"`
+	// Grams of protein per 100 kcal, on search results
+	ProteinDensity *float64 `json:"protein_density,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -96,11 +100,12 @@ type ActivityResponse struct {
 	StartTime    time.Time `json:"start_time"`
 	EndTime      time.Time `json:"end_time"`
 	Duration     int       `json:"duration"` // in minutes
-	Calories     int       `json:"calories"`
+	Calories     float64   `json:"calories"`
 	Distance     float64   `json:"distance,omitempty"` // in km
 	HeartRate    int       `json:"heart_rate,omitempty"`
 	Notes        string    `json:"notes,omitempty"`
 	Source       string    `json:"source"` // manual, garmin, etc.
+	Route        []RoutePoint `json:"route,omitempty"` // only on single activity lookups
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -114,6 +119,7 @@ type WorkoutResponse struct {
 	StartTime time.Time         `json:"start_time"`
 	EndTime   *time.Time        `json:"end_time,omitempty"`
 	Duration  int               `json:"duration,omitempty"` // in minutes
+	CaloriesBurned *float64     `json:"calories_burned,omitempty"` // estimated when not provided
 	Exercises []ExerciseSet     `json:"exercises"`
 	Notes     string            `json:"notes,omitempty"`
 	Status    string            `json:"status"` // in_progress, completed, cancelled
@@ -129,7 +135,10 @@ type ExerciseSet struct {
 	Reps         int     `json:"reps"`
 	Weight       float64 `json:"weight,omitempty"`
 	Duration     int     `json:"duration,omitempty"` // in seconds
+	RPE          *float64 `json:"rpe,omitempty"`     // rate of perceived exertion, 0-10
 	Notes        string  `json:"notes,omitempty"`
+	SetType      string  `json:"set_type"`           // normal, warmup, drop or superset
+	GroupID      string  `json:"group_id,omitempty"` // shared by the sets of a superset or drop set
 }
 
 // MetricResponse represents a body metric entry
@@ -162,22 +171,38 @@ type GoalResponse struct {
 
 // DailySummaryResponse represents a daily fitness summary
 type DailySummaryResponse struct {
-	Date             string  `json:"date"`
-	TotalCalories    int     `json:"total_calories"`
-	CalorieGoal      int     `json:"calorie_goal"`
-	TotalProtein     float64 `json:"total_protein"`
-	ProteinGoal      float64 `json:"protein_goal"`
-	TotalCarbs       float64 `json:"total_carbs"`
-	CarbsGoal        float64 `json:"carbs_goal"`
-	TotalFat         float64 `json:"total_fat"`
-	FatGoal          float64 `json:"fat_goal"`
-	CaloriesBurned   int     `json:"calories_burned"`
-	Activities       int     `json:"activities_count"`
-	Workouts         int     `json:"workouts_count"`
-	MealsLogged      int     `json:"meals_logged"`
-	WaterIntake      float64 `json:"water_intake,omitempty"`
-	StepsCount       int     `json:"steps_count,omitempty"`
-	ActiveMinutes    int     `json:"active_minutes,omitempty"`
+	Date           string   `json:"date"`
+	TotalCalories  float64  `json:"total_calories"`
+	CalorieGoal    float64  `json:"calorie_goal"`
+	TotalProtein   float64  `json:"total_protein"`
+	ProteinGoal    float64  `json:"protein_goal"`
+	TotalCarbs     float64  `json:"total_carbs"`
+	CarbsGoal      float64  `json:"carbs_goal"`
+	TotalFat       float64  `json:"total_fat"`
+	FatGoal        float64  `json:"fat_goal"`
+	TotalSaturatedFat float64 `json:"total_saturated_fat"` // g
+	TotalSodium       float64 `json:"total_sodium"`        // mg
+	NutrientLimits    []NutrientLimitResponse `json:"nutrient_limits,omitempty"` // intake against sodium and saturated fat limit goals
+	CaloriesBurned float64  `json:"calories_burned"`
+	NetCalories    float64  `json:"net_calories"`             // consumed - burned
+	TDEE           *float64 `json:"tdee,omitempty"`           // from profile; omitted when incomplete
+	EnergyBalance  *float64 `json:"energy_balance,omitempty"` // consumed - TDEE
+	Activities     int      `json:"activities_count"`
+	Workouts       int      `json:"workouts_count"`
+	MealsLogged    int      `json:"meals_logged"`
+	WaterIntake    float64  `json:"water_intake,omitempty"`
+	StepsCount     int      `json:"steps_count,omitempty"`
+	ActiveMinutes  int      `json:"active_minutes,omitempty"`
+}
+
+// NutrientLimitResponse is a day's intake of a nutrient against the user's limit goal for it
+type NutrientLimitResponse struct {
+	Nutrient  string  `json:"nutrient"` // sodium or saturated_fat
+	Limit     float64 `json:"limit"`
+	Unit      string  `json:"unit"` // mg for sodium, g for saturated fat
+	Consumed  float64 `json:"consumed"`
+	Remaining float64 `json:"remaining"` // negative when the limit is exceeded
+	Exceeded  bool    `json:"exceeded"`
 }
 
 // ChatResponse represents AI coach response
@@ -194,12 +219,37 @@ type CompleteOnboardingResponse struct {
     Goal *GoalResponse `json:"goal,omitempty"`
 }
 
-// ErrorResponse represents an error response
+// ProfileResponse represents the user's profile
+type ProfileResponse struct {
+	ID                 string                 `json:"id"`
+	Email              string                 `json:"email"`
+	Name               string                 `json:"name"`
+	EmailVerified      bool                   `json:"email_verified"`
+	HeightCm           *float64               `json:"height_cm,omitempty"`
+	WeightKg           *float64               `json:"weight_kg,omitempty"`
+	ActivityLevel      *string                `json:"activity_level,omitempty"`
+	Timezone           string                 `json:"timezone"`
+	UnitSystem         string                 `json:"unit_system"`
+	DietaryPreferences map[string]interface{} `json:"dietary_preferences,omitempty"`
+	CalorieTargetMode  string                 `json:"calorie_target_mode"`       // static or dynamic
+	ExerciseCalorieFraction float64           `json:"exercise_calorie_fraction"` // share of burned calories a dynamic target grows by
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+}
+
+// ErrorResponse represents an error response. RequestID is filled in by the
+// request ID middleware when a handler leaves it empty.
 type ErrorResponse struct {
-	Error   string            `json:"error"`
-	Message string            `json:"message"`
-	Code    string            `json:"code,omitempty"`
-	Details map[string]string `json:"details,omitempty"`
+	Error     string            `json:"error"`
+	Message   string            `json:"message"`
+	Code      string            `json:"code,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// MessageResponse represents a simple acknowledgement
+type MessageResponse struct {
+	Message string `json:"message"`
 }
 
 // PaginatedResponse wraps paginated data
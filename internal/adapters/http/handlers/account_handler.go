@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// AccountHandler handles account export and deletion requests
+type AccountHandler struct {
+	accountService ports.AccountService
+	validator      *validator.Validate
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService ports.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		validator:      middleware.NewValidator(),
+	}
+}
+
+// ExportAccount returns a copy of all the user's data
+// @Summary Export account data
+// @Description Download a full copy of the authenticated user's data
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.UserDataExport
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/account/export [get]
+func (h *AccountHandler) ExportAccount(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	export, err := h.accountService.ExportAccount(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to export account",
+			Message: err.Error(),
+			Code:    "EXPORT_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// DeleteAccount permanently deletes the user's account and data
+// @Summary Delete account
+// @Description Permanently delete the authenticated user's account and all their data. Requires the current password. Set export=true to receive a copy of the data in the response.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeleteAccountRequest true "Password confirmation"
+// @Success 200 {object} domain.UserDataExport
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/account [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.DeleteAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// Take the export before anything is deleted
+	var export *domain.UserDataExport
+	if req.Export {
+		var err error
+		export, err = h.accountService.ExportAccount(c.Request.Context(), userID.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to export account",
+				Message: err.Error(),
+				Code:    "EXPORT_FAILED",
+			})
+			return
+		}
+	}
+
+	if err := h.accountService.DeleteAccount(c.Request.Context(), userID.(string), req.Password); err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "DELETE_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			statusCode = http.StatusUnauthorized
+			errorCode = "INVALID_CREDENTIALS"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to delete account",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	if export != nil {
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
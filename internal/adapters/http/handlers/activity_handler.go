@@ -1,13 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
@@ -15,13 +17,15 @@ import (
 type ActivityHandler struct {
 	activityService ports.ActivityService
 	validator       *validator.Validate
+	display         Display
 }
 
 // NewActivityHandler creates a new activity handler
-func NewActivityHandler(activityService ports.ActivityService) *ActivityHandler {
+func NewActivityHandler(activityService ports.ActivityService, display Display) *ActivityHandler {
 	return &ActivityHandler{
 		activityService: activityService,
-		validator:       validator.New(),
+		validator:       middleware.NewValidator(),
+		display:         display,
 	}
 }
 
@@ -32,8 +36,8 @@ func NewActivityHandler(activityService ports.ActivityService) *ActivityHandler
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param start_date query string false "Start date (YYYY-MM-DD)"
-// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 29 days before end_date when only end_date is given"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today; the range may span at most 366 days"
 // @Param type query string false "Filter by activity type"
 // @Success 200 {array} dto.ActivityResponse
 // @Failure 400 {object} dto.ErrorResponse
@@ -44,36 +48,11 @@ func (h *ActivityHandler) GetActivities(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
 	// Parse query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
 	activityType := c.Query("type")
 
-	var startDate, endDate *time.Time
-
-	if startDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid start_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
-			return
-		}
-		startDate = &parsed
-	}
-
-	if endDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid end_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
-			return
-		}
-		endDate = &parsed
+	startDate, endDate, ok := bindDateRange(c, "start_date", "end_date", defaultRangeDays)
+	if !ok {
+		return
 	}
 
 	activities, err := h.activityService.GetActivities(c.Request.Context(), userID.(string), startDate, endDate, activityType)
@@ -86,7 +65,7 @@ func (h *ActivityHandler) GetActivities(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, activities)
+	h.display.respondNutrition(c, http.StatusOK, activities)
 }
 
 // GetActivity retrieves a specific activity by ID
@@ -111,7 +90,7 @@ func (h *ActivityHandler) GetActivity(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "RETRIEVAL_FAILED"
 
-		if err.Error() == "activity not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -124,7 +103,7 @@ func (h *ActivityHandler) GetActivity(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, activity)
+	h.display.respondNutrition(c, http.StatusOK, activity)
 }
 
 // CreateActivity creates a new activity entry
@@ -145,6 +124,9 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 	var req dto.CreateActivityRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -154,11 +136,7 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -172,7 +150,7 @@ func (h *ActivityHandler) CreateActivity(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, activity)
+	h.display.respondNutrition(c, http.StatusCreated, activity)
 }
 
 // UpdateActivity updates an existing activity
@@ -196,6 +174,9 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 	var req dto.CreateActivityRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -205,11 +186,7 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -218,7 +195,7 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "UPDATE_FAILED"
 
-		if err.Error() == "activity not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -231,7 +208,7 @@ func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, activity)
+	h.display.respondNutrition(c, http.StatusOK, activity)
 }
 
 // DeleteActivity deletes an activity
@@ -256,7 +233,7 @@ func (h *ActivityHandler) DeleteActivity(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "DELETE_FAILED"
 
-		if err.Error() == "activity not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
@@ -20,7 +23,7 @@ type AuthHandler struct {
 func NewAuthHandler(authService ports.AuthService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		validator:   validator.New(),
+		validator:   middleware.NewValidator(),
 	}
 }
 
@@ -40,6 +43,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -49,16 +55,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		validationErrors := make(map[string]string)
-		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors[err.Field()] = err.Tag()
-		}
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: "Invalid input data",
-			Code:    "VALIDATION_ERROR",
-			Details: validationErrors,
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -68,7 +65,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		errorCode := "REGISTRATION_FAILED"
 
 		// Check for specific errors
-		if err.Error() == "user already exists" {
+		if errors.Is(err, domain.ErrEmailAlreadyExists) {
 			statusCode = http.StatusConflict
 			errorCode = "USER_EXISTS"
 		}
@@ -89,10 +86,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, dto.AuthResponse{
 		User: dto.UserData{
-			ID:        user.ID.String(),
-			Email:     user.Email,
-			Name:      fullName,
-			CreatedAt: user.CreatedAt,
+			ID:            user.ID.String(),
+			Email:         user.Email,
+			Name:          fullName,
+			EmailVerified: user.EmailVerified,
+			CreatedAt:     user.CreatedAt,
 		},
 		Token: token,
 	})
@@ -114,6 +112,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -123,16 +124,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		validationErrors := make(map[string]string)
-		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors[err.Field()] = err.Tag()
-		}
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: "Invalid input data",
-			Code:    "VALIDATION_ERROR",
-			Details: validationErrors,
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -142,7 +134,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		errorCode := "LOGIN_FAILED"
 
 		// Check for authentication errors
-		if err.Error() == "invalid credentials" {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
 			statusCode = http.StatusUnauthorized
 			errorCode = "INVALID_CREDENTIALS"
 		}
@@ -163,10 +155,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.AuthResponse{
 		User: dto.UserData{
-			ID:        user.ID.String(),
-			Email:     user.Email,
-			Name:      fullName,
-			CreatedAt: user.CreatedAt,
+			ID:            user.ID.String(),
+			Email:         user.Email,
+			Name:          fullName,
+			EmailVerified: user.EmailVerified,
+			CreatedAt:     user.CreatedAt,
 		},
 		Token: token,
 	})
@@ -191,11 +184,191 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// ForgotPassword starts the password reset flow
+// @Summary Request password reset
+// @Description Send a single-use password reset token to the email if an account exists. Always returns 200.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	// Failures are logged rather than returned so the response never reveals whether the email exists
+	if err := h.authService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Message: "If an account exists for that email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword completes the password reset flow
+// @Summary Reset password
+// @Description Set a new password using a password reset token. The token can only be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RESET_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidToken):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_TOKEN"
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "WEAK_PASSWORD"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Password reset failed",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Message: "Password has been reset",
+	})
+}
+
+// SendVerification sends a new email verification link
+// @Summary Send email verification
+// @Description Send (or re-send) a verification link to the authenticated user's email. Earlier links stop working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/send-verification [post]
+func (h *AuthHandler) SendVerification(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.authService.SendVerificationEmail(c.Request.Context(), userID.(string)); err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "SEND_FAILED"
+
+		if errors.Is(err, domain.ErrEmailAlreadyVerified) {
+			statusCode = http.StatusConflict
+			errorCode = "ALREADY_VERIFIED"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to send verification email",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// VerifyEmail confirms an email address using a verification token
+// @Summary Verify email
+// @Description Mark the user's email as verified using the token from the verification email
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/verify [get]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Missing token",
+			Message: "token query parameter is required",
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c.Request.Context(), token); err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "VERIFICATION_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidToken) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_TOKEN"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Email verification failed",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Message: "Email verified",
+	})
+}
+
 // CompleteOnboarding handles completion of onboarding profile
 // Note: Service implementation to persist fields may be pending in current codebase.
 func (h *AuthHandler) CompleteOnboarding(c *gin.Context) {
     var req dto.CompleteOnboardingRequest
     if err := c.ShouldBindJSON(&req); err != nil {
+        if respondBodyTooLarge(c, err) {
+            return
+        }
         c.JSON(http.StatusBadRequest, dto.ErrorResponse{
             Error:   "Invalid request body",
             Message: err.Error(),
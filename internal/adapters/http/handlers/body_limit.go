@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+)
+
+// respondBodyTooLarge writes a 413 when binding failed because the request body went over
+// the route's middleware.BodyLimit. It returns false for other binding errors.
+func respondBodyTooLarge(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+
+	c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+		Error:   "Request body too large",
+		Message: middleware.BodyTooLargeMessage(tooLarge.Limit),
+		Code:    "BODY_TOO_LARGE",
+	})
+	return true
+}
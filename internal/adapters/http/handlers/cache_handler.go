@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// CacheHandler reports how well the server's caches are working to admins
+type CacheHandler struct {
+	foodSearchCache ports.FoodSearchCache
+}
+
+// cacheStatsResponse holds the statistics of each cache
+type cacheStatsResponse struct {
+	FoodSearch domain.CacheStats `json:"food_search"`
+}
+
+// NewCacheHandler creates a new cache handler
+func NewCacheHandler(foodSearchCache ports.FoodSearchCache) *CacheHandler {
+	return &CacheHandler{foodSearchCache: foodSearchCache}
+}
+
+// GetStats returns the hit and miss counts of the caches
+// @Summary Get cache statistics
+// @Description Hits, misses, hit rate and entry count of the food search cache since the server started. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} cacheStatsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/cache-stats [get]
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, cacheStatsResponse{FoodSearch: h.foodSearchCache.Stats()})
+}
@@ -1,27 +1,32 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
 	"fitness-tracker/internal/core/ports"
 )
 
+// chatHistoryDefaultLimit is how many messages the history returns without a limit
+const chatHistoryDefaultLimit = 50
+
 // ChatHandler handles AI coach chat requests
 type ChatHandler struct {
 	chatService ports.ChatService
 	validator   *validator.Validate
+	pages       PageLimits
 }
 
 // NewChatHandler creates a new chat handler
-func NewChatHandler(chatService ports.ChatService) *ChatHandler {
+func NewChatHandler(chatService ports.ChatService, pages PageLimits) *ChatHandler {
 	return &ChatHandler{
 		chatService: chatService,
-		validator:   validator.New(),
+		validator:   middleware.NewValidator(),
+		pages:       pages,
 	}
 }
 
@@ -36,6 +41,7 @@ func NewChatHandler(chatService ports.ChatService) *ChatHandler {
 // @Success 200 {object} dto.ChatResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "AI provider rate limited; see the Retry-After header"
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /chat [post]
 func (h *ChatHandler) SendMessage(c *gin.Context) {
@@ -43,6 +49,9 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	var req dto.ChatRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -52,16 +61,15 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
 	response, err := h.chatService.SendMessage(c.Request.Context(), userID.(string), req.Message, req.Context)
 	if err != nil {
+		if respondRateLimited(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to process message",
 			Message: err.Error(),
@@ -90,29 +98,13 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 func (h *ChatHandler) GetHistory(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
-	limit := 50
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid limit parameter",
-				Message: "Limit must be a valid integer",
-				Code:    "INVALID_LIMIT",
-			})
-			return
-		}
+	limit, ok := h.pages.bindLimit(c, chatHistoryDefaultLimit)
+	if !ok {
+		return
 	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if _, err := fmt.Sscanf(offsetStr, "%d", &offset); err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid offset parameter",
-				Message: "Offset must be a valid integer",
-				Code:    "INVALID_OFFSET",
-			})
-			return
-		}
+	offset, ok := bindOffset(c)
+	if !ok {
+		return
 	}
 
 	history, err := h.chatService.GetHistory(c.Request.Context(), userID.(string), limit, offset)
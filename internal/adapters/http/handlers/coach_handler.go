@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// CoachHandler handles the proactive coach digest
+type CoachHandler struct {
+	coachService ports.CoachService
+	display      Display
+}
+
+// NewCoachHandler creates a new coach handler
+func NewCoachHandler(coachService ports.CoachService, display Display) *CoachHandler {
+	return &CoachHandler{
+		coachService: coachService,
+		display:      display,
+	}
+}
+
+// GetDigest returns today's coach digest for the home screen
+// @Summary Get coach digest
+// @Description Get a short proactive summary of today without a chat turn: remaining calories and macros, whether the user worked out, progress on active goals and one actionable tip. The tip is AI-written when AI is enabled and templated otherwise, and is regenerated only when the day's data changes materially.
+// @Tags coach
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.CoachDigest
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /coach/digest [get]
+func (h *CoachHandler) GetDigest(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	digest, err := h.coachService.GetDigest(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "USER_NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve coach digest",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, digest)
+}
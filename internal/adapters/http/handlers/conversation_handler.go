@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// conversationListDefaultLimit is how many conversations the list returns without a limit
+const conversationListDefaultLimit = 20
+
+// ConversationHandler handles the chat conversation list and message history
+type ConversationHandler struct {
+	conversationService ports.ConversationService
+	pages               PageLimits
+}
+
+// NewConversationHandler creates a new conversation handler
+func NewConversationHandler(conversationService ports.ConversationService, pages PageLimits) *ConversationHandler {
+	return &ConversationHandler{
+		conversationService: conversationService,
+		pages:               pages,
+	}
+}
+
+// ListConversations lists the user's conversations with previews
+// @Summary List conversations
+// @Description List the user's chat conversations, most recently active first, with the start of each one's latest message and its message count
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Results limit" default(20)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {array} domain.ConversationPreview
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /chat/conversations [get]
+func (h *ConversationHandler) ListConversations(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	limit, ok := h.pages.bindLimit(c, conversationListDefaultLimit)
+	if !ok {
+		return
+	}
+	offset, ok := bindOffset(c)
+	if !ok {
+		return
+	}
+
+	conversations, err := h.conversationService.ListConversations(c.Request.Context(), userID.(string), limit, offset)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve conversations",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+// ListMessages pages through a conversation's messages
+// @Summary List conversation messages
+// @Description List a conversation's messages, oldest first. Pass the response's next_cursor as cursor to get the following page; it is absent on the last page. Messages added while paging appear on later pages without shifting earlier ones.
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Conversation ID"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param limit query int false "Results limit" default(20)
+// @Success 200 {object} domain.MessagePage
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /chat/conversations/{id}/messages [get]
+func (h *ConversationHandler) ListMessages(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	limit, ok := h.pages.bindLimit(c, 0)
+	if !ok {
+		return
+	}
+
+	page, err := h.conversationService.ListMessages(c.Request.Context(), userID.(string), c.Param("id"), c.Query("cursor"), limit)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve messages",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	apperrors "fitness-tracker/internal/pkg/errors"
+	"fitness-tracker/internal/pkg/utils"
+)
+
+// defaultRangeDays is the span filled in when a list query gives only one end of its date range
+const defaultRangeDays = 30
+
+// bindDateRange parses the startParam/endParam query parameters with utils.ParseDateRange.
+// On an invalid range it writes a 400 response and returns ok=false.
+func bindDateRange(c *gin.Context, startParam, endParam string, defaultDays int) (start, end *time.Time, ok bool) {
+	start, end, err := utils.ParseDateRange(c.Query(startParam), c.Query(endParam), defaultDays)
+	if err == nil {
+		return start, end, true
+	}
+
+	appErr, _ := apperrors.GetAppError(err)
+	if apperrors.IsValidation(err) {
+		param := startParam
+		if appErr.Details["field"] == "end" {
+			param = endParam
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid " + param + " format",
+			Message: "Use YYYY-MM-DD format",
+			Code:    "INVALID_DATE",
+		})
+		return nil, nil, false
+	}
+
+	c.JSON(appErr.GetHTTPStatus(), dto.ErrorResponse{
+		Error:   "Invalid date range",
+		Message: appErr.Message,
+		Code:    "INVALID_DATE_RANGE",
+	})
+	return nil, nil, false
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// DemoHandler handles demo seed requests. Its routes are only registered when the demo
+// seed is enabled in the config.
+type DemoHandler struct {
+	demoService ports.DemoService
+}
+
+// NewDemoHandler creates a new demo handler
+func NewDemoHandler(demoService ports.DemoService) *DemoHandler {
+	return &DemoHandler{
+		demoService: demoService,
+	}
+}
+
+// SeedDemoData fills the account with sample data
+// @Summary Seed demo data
+// @Description Add five days of sample meals, activities, workouts and weigh-ins ending today, so a new account's dashboards have something to show. Every record is flagged is_demo. Only available when the demo seed is enabled.
+// @Tags demo
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} domain.DemoSeedResult
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /demo/seed [post]
+func (h *DemoHandler) SeedDemoData(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	result, err := h.demoService.SeedDemoData(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "DEMO_SEED_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrConflict):
+			statusCode = http.StatusConflict
+			errorCode = "ALREADY_SEEDED"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to seed demo data",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ClearDemoData removes the sample data
+// @Summary Clear demo data
+// @Description Permanently delete every record flagged is_demo, with anything logged under a sample meal or workout. The user's own data is kept. Only available when the demo seed is enabled.
+// @Tags demo
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.DemoSeedResult
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /demo/seed [delete]
+func (h *DemoHandler) ClearDemoData(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	result, err := h.demoService.ClearDemoData(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to clear demo data",
+			Message: err.Error(),
+			Code:    "DEMO_CLEAR_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/pkg/utils"
+)
+
+// Display controls how values are formatted in responses
+type Display struct {
+	NutritionPrecision int // decimal places of calories and nutrients
+}
+
+// respondNutrition writes v as JSON with calories and nutrients rounded to
+// NutritionPrecision decimal places. Services keep full precision; rounding
+// happens only here.
+func (d Display) respondNutrition(c *gin.Context, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		data, err = utils.RoundNutritionJSON(data, d.NutritionPrecision)
+	}
+	if err != nil {
+		c.JSON(status, v)
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", data)
+}
@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
 	"fitness-tracker/internal/core/ports"
 )
 
@@ -15,13 +15,15 @@ import (
 type ExerciseHandler struct {
 	exerciseService ports.ExerciseService
 	validator       *validator.Validate
+	pages           PageLimits
 }
 
 // NewExerciseHandler creates a new exercise handler
-func NewExerciseHandler(exerciseService ports.ExerciseService) *ExerciseHandler {
+func NewExerciseHandler(exerciseService ports.ExerciseService, pages PageLimits) *ExerciseHandler {
 	return &ExerciseHandler{
 		exerciseService: exerciseService,
-		validator:       validator.New(),
+		validator:       middleware.NewValidator(),
+		pages:           pages,
 	}
 }
 
@@ -45,17 +47,10 @@ func (h *ExerciseHandler) SearchExercises(c *gin.Context) {
 	query := c.Query("query")
 	category := c.Query("category")
 	muscleGroup := c.Query("muscle_group")
-	limit := 20
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid limit parameter",
-				Message: "Limit must be a valid integer",
-				Code:    "INVALID_LIMIT",
-			})
-			return
-		}
+	limit, ok := h.pages.bindLimit(c, 0)
+	if !ok {
+		return
 	}
 
 	exercises, err := h.exerciseService.SearchExercises(c.Request.Context(), query, category, muscleGroup, limit)
@@ -89,6 +84,9 @@ func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
 	var req CreateExerciseRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -98,11 +96,7 @@ func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
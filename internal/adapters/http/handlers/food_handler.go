@@ -1,38 +1,50 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
 // FoodHandler handles food-related requests
 type FoodHandler struct {
 	foodService ports.FoodService
+	estimator   ports.FoodEstimator
 	validator   *validator.Validate
+	pages       PageLimits
+	display     Display
 }
 
 // NewFoodHandler creates a new food handler
-func NewFoodHandler(foodService ports.FoodService) *FoodHandler {
+func NewFoodHandler(foodService ports.FoodService, estimator ports.FoodEstimator, pages PageLimits, display Display) *FoodHandler {
 	return &FoodHandler{
 		foodService: foodService,
-		validator:   validator.New(),
+		estimator:   estimator,
+		validator:   middleware.NewValidator(),
+		pages:       pages,
+		display:     display,
 	}
 }
 
-// SearchFoods searches for foods by name or barcode
+// SearchFoods searches for foods by name, brand or description
 // @Summary Search foods
-// @Description Search for foods by name or barcode in the database
+// @Description Search for foods whose name, brand or description contain every word of the query. Name matches rank above brand matches, which rank above description matches.
 // @Tags foods
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param query query string false "Search query (name or barcode)"
+// @Param query query string false "Search query (name, brand or description)"
+// @Param verified_only query bool false "Only return foods with verified nutrition data" default(false)
+// @Param sort query string false "Order of the results: relevance, protein_density (most protein per calorie first) or calories (fewest first)" default(relevance)
 // @Param limit query int false "Results limit" default(20)
 // @Success 200 {array} dto.FoodResponse
 // @Failure 400 {object} dto.ErrorResponse
@@ -50,20 +62,36 @@ func (h *FoodHandler) SearchFoods(c *gin.Context) {
 		return
 	}
 
-	limit := 20
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil {
+	var filter domain.FoodSearchFilter
+	if verifiedOnly := c.Query("verified_only"); verifiedOnly != "" {
+		parsed, err := strconv.ParseBool(verifiedOnly)
+		if err != nil {
 			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid limit parameter",
-				Message: "Limit must be a valid integer",
-				Code:    "INVALID_LIMIT",
+				Error:   "Invalid verified_only",
+				Message: "verified_only must be true or false",
+				Code:    "INVALID_INPUT",
 			})
 			return
 		}
+		filter.VerifiedOnly = parsed
 	}
+	filter.Sort = c.Query("sort")
 
-	foods, err := h.foodService.SearchFoods(c.Request.Context(), query, limit)
+	limit, ok := h.pages.bindLimit(c, 0)
+	if !ok {
+		return
+	}
+
+	foods, err := h.foodService.SearchFoods(c.Request.Context(), query, filter, limit)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid sort",
+				Message: err.Error(),
+				Code:    "INVALID_INPUT",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Search failed",
 			Message: err.Error(),
@@ -72,18 +100,207 @@ func (h *FoodHandler) SearchFoods(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, foods)
+	userID, _ := c.Get("userID")
+	h.foodService.MarkStarred(c.Request.Context(), userID.(string), foods)
+
+	h.display.respondNutrition(c, http.StatusOK, foods)
+}
+
+// GetRecentFoods returns the foods the user logged most recently
+// @Summary Get recent foods
+// @Description Get the user's recently and frequently logged foods with their typical quantity and unit, for quick re-logging
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Results limit (max 50)" default(20)
+// @Success 200 {array} domain.RecentFood
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/recent [get]
+func (h *FoodHandler) GetRecentFoods(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	limit, ok := h.pages.bindLimit(c, 0)
+	if !ok {
+		return
+	}
+
+	foods, err := h.foodService.GetRecentFoods(c.Request.Context(), userID.(string), limit)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve recent foods",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, foods)
+}
+
+// GetStarredFoods returns the foods the user starred
+// @Summary Get starred foods
+// @Description Get the foods the user starred for quick adding, most recently starred first. Any food can be starred, including verified foods and the user's own.
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Results limit (max 100)" default(50)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {array} dto.FoodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/starred [get]
+func (h *FoodHandler) GetStarredFoods(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	limit, ok := h.pages.bindLimit(c, 0)
+	if !ok {
+		return
+	}
+	offset, ok := bindOffset(c)
+	if !ok {
+		return
+	}
+
+	foods, err := h.foodService.GetStarredFoods(c.Request.Context(), userID.(string), limit, offset)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve starred foods",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, foods)
+}
+
+// StarFood stars a food for the user
+// @Summary Star food
+// @Description Star a food for quick adding. Starring an already starred food succeeds without change.
+// @Tags foods
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/{id}/star [post]
+func (h *FoodHandler) StarFood(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.foodService.StarFood(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "STAR_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to star food",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnstarFood removes the user's star from a food
+// @Summary Unstar food
+// @Description Remove the star from a food. Unstarring a food that is not starred succeeds without change.
+// @Tags foods
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/{id}/star [delete]
+func (h *FoodHandler) UnstarFood(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.foodService.UnstarFood(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "UNSTAR_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to unstar food",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListServingUnits returns the serving units clients can offer when logging food
+// @Summary List serving units
+// @Description Get all serving units grouped by category (weight, volume, count)
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.ServingUnitGroup
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/serving-units [get]
+func (h *FoodHandler) ListServingUnits(c *gin.Context) {
+	groups, err := h.foodService.ListServingUnits(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to retrieve serving units",
+			Message: err.Error(),
+			Code:    "RETRIEVAL_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
 }
 
 // GetFood retrieves a specific food by ID
 // @Summary Get food by ID
-// @Description Retrieve detailed information about a specific food item
+// @Description Retrieve detailed information about a specific food item. The response carries an ETag; send it back in If-None-Match to get 304 with no body while the food is unchanged.
 // @Tags foods
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Food ID"
+// @Param If-None-Match header string false "ETag of a previous response"
 // @Success 200 {object} dto.FoodResponse
+// @Success 304 "Not modified"
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -96,7 +313,7 @@ func (h *FoodHandler) GetFood(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "RETRIEVAL_FAILED"
 
-		if err.Error() == "food not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -109,7 +326,10 @@ func (h *FoodHandler) GetFood(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, food)
+	userID, _ := c.Get("userID")
+	h.foodService.MarkStarred(c.Request.Context(), userID.(string), []*domain.Food{food})
+
+	h.display.respondNutrition(c, http.StatusOK, food)
 }
 
 // CreateFood creates a custom food entry
@@ -130,6 +350,9 @@ func (h *FoodHandler) CreateFood(c *gin.Context) {
 	var req dto.CreateFoodRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -139,11 +362,7 @@ func (h *FoodHandler) CreateFood(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -157,7 +376,82 @@ func (h *FoodHandler) CreateFood(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, food)
+	h.display.respondNutrition(c, http.StatusCreated, food)
+}
+
+// EstimateFood estimates the nutrition of a freeform food without saving it
+// @Summary Estimate food nutrition
+// @Description Estimate calories and macros for an amount of a food described in free text, like "Big Mac". Nothing is saved and the result is flagged as an estimate. Estimates for the same food are cached for 15 minutes.
+// @Tags foods
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.EstimateFoodRequest true "Food and amount"
+// @Success 200 {object} domain.FoodEstimate
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/estimate [post]
+func (h *FoodHandler) EstimateFood(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.EstimateFoodRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Invalid user",
+			Message: "user ID in token is not valid",
+			Code:    "UNAUTHORIZED",
+		})
+		return
+	}
+
+	estimate, err := h.estimator.EstimateFood(c.Request.Context(), userUUID, req.Name, req.Quantity, req.Unit)
+	if err != nil {
+		if respondRateLimited(c, err) {
+			return
+		}
+
+		statusCode := http.StatusInternalServerError
+		errorCode := "ESTIMATE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to estimate food",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, estimate)
 }
 
 // UpdateFood updates a custom food entry
@@ -182,6 +476,9 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 	var req dto.CreateFoodRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -191,11 +488,7 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -204,11 +497,14 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "UPDATE_FAILED"
 
-		switch err.Error() {
-		case "food not found":
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
-		case "cannot update non-custom food":
+		case errors.Is(err, domain.ErrForbidden):
 			statusCode = http.StatusForbidden
 			errorCode = "FORBIDDEN"
 		}
@@ -221,5 +517,104 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, food)
+	h.display.respondNutrition(c, http.StatusOK, food)
+}
+
+// GetFoodHistory returns the change history of a food
+// @Summary Get food history
+// @Description Revisions of a food, newest first, each with the changed fields and their values before and after
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Param limit query int false "Results limit (max 100)" default(20)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {array} domain.FoodRevision
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/{id}/history [get]
+func (h *FoodHandler) GetFoodHistory(c *gin.Context) {
+	foodID := c.Param("id")
+
+	limit, ok := h.pages.bindLimit(c, 0)
+	if !ok {
+		return
+	}
+	offset, ok := bindOffset(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.foodService.GetFoodHistory(c.Request.Context(), foodID, limit, offset)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve food history",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, history)
+}
+
+// GetFoodAlternatives suggests healthier swaps for a food
+// @Summary Get food alternatives
+// @Description Foods in the same category with a better nutrient-density score (protein and fiber per calorie up, sugar and saturated fat down), best first. Empty with a note when the food has no category or no calories.
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Food ID"
+// @Param limit query int false "Results limit (max 20)" default(5)
+// @Success 200 {object} domain.FoodAlternatives
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /foods/{id}/alternatives [get]
+func (h *FoodHandler) GetFoodAlternatives(c *gin.Context) {
+	foodID := c.Param("id")
+
+	limit, ok := h.pages.bindLimit(c, 5)
+	if !ok {
+		return
+	}
+
+	alternatives, err := h.foodService.GetFoodAlternatives(c.Request.Context(), foodID, limit)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve food alternatives",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, alternatives)
 }
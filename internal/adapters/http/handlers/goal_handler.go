@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
@@ -20,7 +23,7 @@ type GoalHandler struct {
 func NewGoalHandler(goalService ports.GoalService) *GoalHandler {
 	return &GoalHandler{
 		goalService: goalService,
-		validator:   validator.New(),
+		validator:   middleware.NewValidator(),
 	}
 }
 
@@ -42,6 +45,9 @@ func (h *GoalHandler) CreateGoal(c *gin.Context) {
 	var req dto.CreateGoalRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -51,11 +57,7 @@ func (h *GoalHandler) CreateGoal(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -74,25 +76,35 @@ func (h *GoalHandler) CreateGoal(c *gin.Context) {
 
 // GetGoals retrieves goals for a user
 // @Summary Get user goals
-// @Description Retrieve all goals for the authenticated user
+// @Description Retrieve all goals for the authenticated user. Each goal includes a computed summary: remaining amount and on-track status for weight goals, this week's workout count for workout_frequency goals.
 // @Tags goals
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param status query string false "Filter by status (active, completed, failed, cancelled)"
-// @Param type query string false "Filter by goal type"
-// @Success 200 {array} dto.GoalResponse
+// @Param status query string false "Filter by status (active, completed, abandoned)"
+// @Success 200 {array} domain.Goal
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /goals [get]
 func (h *GoalHandler) GetGoals(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
-	status := c.Query("status")
-	goalType := c.Query("type")
+	var status *string
+	if s := c.Query("status"); s != "" {
+		status = &s
+	}
 
-	goals, err := h.goalService.GetGoals(c.Request.Context(), userID.(string), status, goalType)
+	goals, err := h.goalService.GetGoals(c.Request.Context(), userID.(string), status)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: "status must be one of active, completed, abandoned",
+				Code:    "INVALID_REQUEST",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to retrieve goals",
 			Message: err.Error(),
@@ -125,6 +137,9 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 	var req dto.CreateGoalRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -134,11 +149,7 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -147,7 +158,7 @@ func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "UPDATE_FAILED"
 
-		if err.Error() == "goal not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -185,7 +196,7 @@ func (h *GoalHandler) DeleteGoal(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "DELETE_FAILED"
 
-		if err.Error() == "goal not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
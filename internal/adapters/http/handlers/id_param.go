@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	apperrors "fitness-tracker/internal/pkg/errors"
+	"fitness-tracker/internal/pkg/utils"
+)
+
+// currentUserID returns the authenticated user's ID set by the auth middleware. A token
+// carrying a malformed user ID gets a 401 response and ok=false.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Invalid token",
+			Message: "The token does not identify a user",
+			Code:    "UNAUTHORIZED",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// bindIDParam parses the named path parameter with utils.ParseID. On a malformed ID it
+// writes a 400 response and returns ok=false.
+func bindIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	return bindID(c, c.Param(name), name)
+}
+
+// bindID parses an ID the client sent in field, answering a malformed one with 400
+func bindID(c *gin.Context, value, field string) (uuid.UUID, bool) {
+	id, err := utils.ParseID(value, field)
+	if err != nil {
+		respondInvalidID(c, err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// bindIDs parses a list of IDs the client sent in field, answering a malformed one with 400
+func bindIDs(c *gin.Context, values []string, field string) ([]uuid.UUID, bool) {
+	ids, err := utils.ParseIDs(values, field)
+	if err != nil {
+		respondInvalidID(c, err)
+		return nil, false
+	}
+	return ids, true
+}
+
+func respondInvalidID(c *gin.Context, err error) {
+	appErr, _ := apperrors.GetAppError(err)
+	c.JSON(appErr.GetHTTPStatus(), dto.ErrorResponse{
+		Error:   "Invalid ID",
+		Message: appErr.Message,
+		Code:    "INVALID_ID",
+	})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// InsightsHandler handles streak, achievement, activity calendar and weekly recap requests
+type InsightsHandler struct {
+	insightsService    ports.InsightsService
+	weeklyRecapService ports.WeeklyRecapService
+}
+
+// NewInsightsHandler creates a new insights handler
+func NewInsightsHandler(insightsService ports.InsightsService, weeklyRecapService ports.WeeklyRecapService) *InsightsHandler {
+	return &InsightsHandler{
+		insightsService:    insightsService,
+		weeklyRecapService: weeklyRecapService,
+	}
+}
+
+// GetStreaks returns the user's logging streaks and achievements
+// @Summary Get streaks
+// @Description Get the current and longest runs of consecutive days with a logged meal and with a completed workout, in the user's timezone, plus earned achievements
+// @Tags insights
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.StreakSummary
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /insights/streaks [get]
+func (h *InsightsHandler) GetStreaks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	streaks, err := h.insightsService.GetStreaks(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "USER_NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve streaks",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, streaks)
+}
+
+// GetWeeklyRecap returns a shareable recap of a finished week
+// @Summary Get weekly recap
+// @Description Get a recap of a finished ISO week in the user's timezone: finished workouts, total volume, weight change, calorie adherence, best lift and a one-line encouragement. Recaps are cached per week.
+// @Tags insights
+// @Produce json
+// @Security BearerAuth
+// @Param week query string false "ISO week, e.g. 2025-W47 (default: last week)"
+// @Success 200 {object} domain.WeeklyRecap
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /insights/weekly-recap [get]
+func (h *InsightsHandler) GetWeeklyRecap(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	recap, err := h.weeklyRecapService.GetWeeklyRecap(c.Request.Context(), userID.(string), c.Query("week"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "USER_NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve weekly recap",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, recap)
+}
+
+// GetActivityCalendar returns a year of the user's logging for a heatmap
+// @Summary Get activity calendar
+// @Description Get one value per day of a year, in the user's timezone, flagging a logged meal (1), a finished workout (2) and a logged weight (4). The current year ends today; a future year has no days.
+// @Tags insights
+// @Produce json
+// @Security BearerAuth
+// @Param year query int false "Year, e.g. 2025 (default: this year)"
+// @Success 200 {object} domain.ActivityCalendar
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /insights/activity-calendar [get]
+func (h *InsightsHandler) GetActivityCalendar(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	calendar, err := h.insightsService.GetActivityCalendar(c.Request.Context(), userID.(string), c.Query("year"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "USER_NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve activity calendar",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
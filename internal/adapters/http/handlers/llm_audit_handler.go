@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// llmAuditDefaultLimit is how many audit entries are returned without a limit
+const llmAuditDefaultLimit = 50
+
+// LLMAuditHandler serves the LLM call audit log to admins
+type LLMAuditHandler struct {
+	auditService ports.LLMAuditService
+	pages        PageLimits
+}
+
+// NewLLMAuditHandler creates a new LLM audit handler
+func NewLLMAuditHandler(auditService ports.LLMAuditService, pages PageLimits) *LLMAuditHandler {
+	return &LLMAuditHandler{
+		auditService: auditService,
+		pages:        pages,
+	}
+}
+
+// ListEntries queries the LLM audit log across all users
+// @Summary List LLM audit entries
+// @Description Query recorded LLM calls (agent, meal parsing, food estimates, vision), newest first. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Only calls made for this user"
+// @Param request_id query string false "Only calls made while serving this X-Request-ID"
+// @Param operation query string false "Operation (agent, meal_parse, food_estimate, vision, coach_digest, weekly_recap)"
+// @Param model query string false "Model name"
+// @Param status query string false "Call status (success, error)"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 29 days before end_date when only end_date is given"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today; the range may span at most 366 days"
+// @Param limit query int false "Results limit (max 200)" default(50)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Success 200 {array} domain.LLMAuditEntry
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/llm-audit [get]
+func (h *LLMAuditHandler) ListEntries(c *gin.Context) {
+	filter := domain.LLMAuditFilter{
+		RequestID: c.Query("request_id"),
+		Operation: c.Query("operation"),
+		Model:     c.Query("model"),
+		Status:    c.Query("status"),
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid user_id parameter",
+				Message: "user_id must be a valid UUID",
+				Code:    "INVALID_USER_ID",
+			})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	startDate, endDate, ok := bindDateRange(c, "start_date", "end_date", defaultRangeDays)
+	if !ok {
+		return
+	}
+	if startDate != nil {
+		// Dates are whole days, so the upper bound is the start of the day after end_date
+		to := endDate.AddDate(0, 0, 1)
+		filter.From = startDate
+		filter.To = &to
+	}
+
+	limit, ok := h.pages.bindLimit(c, llmAuditDefaultLimit)
+	if !ok {
+		return
+	}
+	offset, ok := bindOffset(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.auditService.ListEntries(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve LLM audit entries",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
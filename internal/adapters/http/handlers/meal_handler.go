@@ -1,27 +1,42 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
 // MealHandler handles meal-related requests
 type MealHandler struct {
-	mealService ports.MealService
-	validator   *validator.Validate
+	mealService           ports.MealService
+	mealComparisonService ports.MealComparisonService
+	parsedMeals           ports.ParsedMealStore
+	validator             *validator.Validate
+	display               Display
+}
+
+// confirmedMealResponse is a confirmed meal with how it compares to the user's
+// typical meal of its type
+type confirmedMealResponse struct {
+	*domain.Meal
+	Comparison *domain.MealComparison `json:"comparison,omitempty"`
 }
 
 // NewMealHandler creates a new meal handler
-func NewMealHandler(mealService ports.MealService) *MealHandler {
+func NewMealHandler(mealService ports.MealService, mealComparisonService ports.MealComparisonService, parsedMeals ports.ParsedMealStore, display Display) *MealHandler {
 	return &MealHandler{
-		mealService: mealService,
-		validator:   validator.New(),
+		mealService:           mealService,
+		mealComparisonService: mealComparisonService,
+		parsedMeals:           parsedMeals,
+		validator:             middleware.NewValidator(),
+		display:               display,
 	}
 }
 
@@ -43,6 +58,9 @@ func (h *MealHandler) ParseMeal(c *gin.Context) {
 	var req dto.ParseMealRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -52,11 +70,7 @@ func (h *MealHandler) ParseMeal(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -70,12 +84,15 @@ func (h *MealHandler) ParseMeal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, parsedMeal)
+	h.display.respondNutrition(c, http.StatusOK, parsedMeal)
 }
 
 // ConfirmMeal confirms and saves a parsed meal
 // @Summary Confirm parsed meal
-// @Description Confirm and save a previously parsed meal to the database
+// @Description Confirm and save a previously parsed meal to the database. The response
+// @Description compares it with the user's typical meal of the same type. Without adjustments,
+// @Description items may exclude parsed food items or override their quantities; totals are
+// @Description recomputed from the items that remain.
 // @Tags meals
 // @Accept json
 // @Produce json
@@ -84,6 +101,7 @@ func (h *MealHandler) ParseMeal(c *gin.Context) {
 // @Success 201 {object} dto.MealResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /meals/confirm [post]
 func (h *MealHandler) ConfirmMeal(c *gin.Context) {
@@ -91,6 +109,9 @@ func (h *MealHandler) ConfirmMeal(c *gin.Context) {
 	var req dto.ConfirmMealRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -100,15 +121,20 @@ func (h *MealHandler) ConfirmMeal(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	meal, err := h.mealService.ConfirmMeal(c.Request.Context(), userID.(string), req.ParsedMealID, req.Adjustments)
+	var meal *domain.Meal
+	var err error
+	if req.Adjustments != nil {
+		meal, err = h.mealService.ConfirmMeal(c.Request.Context(), userID.(string), req.ParsedMealID, req.Adjustments)
+	} else {
+		var ok bool
+		if meal, ok = h.confirmParsedMeal(c, req); !ok {
+			return
+		}
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to confirm meal",
@@ -118,7 +144,13 @@ func (h *MealHandler) ConfirmMeal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, meal)
+	// The meal is saved either way; a failed comparison is only left out
+	response := confirmedMealResponse{Meal: meal}
+	if comparison, err := h.mealComparisonService.CompareLoggedMeal(c.Request.Context(), userID.(string), meal.ID.String()); err == nil {
+		response.Comparison = comparison
+	}
+
+	h.display.respondNutrition(c, http.StatusCreated, response)
 }
 
 // CreateMeal handles manual meal creation
@@ -139,6 +171,9 @@ func (h *MealHandler) CreateMeal(c *gin.Context) {
 	var req dto.CreateMealRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -148,11 +183,7 @@ func (h *MealHandler) CreateMeal(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -166,7 +197,7 @@ func (h *MealHandler) CreateMeal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, meal)
+	h.display.respondNutrition(c, http.StatusCreated, meal)
 }
 
 // GetMeals retrieves meals for a user
@@ -176,8 +207,8 @@ func (h *MealHandler) CreateMeal(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param start_date query string false "Start date (YYYY-MM-DD)"
-// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 29 days before end_date when only end_date is given"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today; the range may span at most 366 days"
 // @Param meal_type query string false "Filter by meal type"
 // @Success 200 {array} dto.MealResponse
 // @Failure 400 {object} dto.ErrorResponse
@@ -188,36 +219,11 @@ func (h *MealHandler) GetMeals(c *gin.Context) {
 	userID, _ := c.Get("userID")
 
 	// Parse query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
 	mealType := c.Query("meal_type")
 
-	var startDate, endDate *time.Time
-
-	if startDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid start_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
-			return
-		}
-		startDate = &parsed
-	}
-
-	if endDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid end_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
-			return
-		}
-		endDate = &parsed
+	startDate, endDate, ok := bindDateRange(c, "start_date", "end_date", defaultRangeDays)
+	if !ok {
+		return
 	}
 
 	meals, err := h.mealService.GetMeals(c.Request.Context(), userID.(string), startDate, endDate, mealType)
@@ -230,7 +236,7 @@ func (h *MealHandler) GetMeals(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, meals)
+	h.display.respondNutrition(c, http.StatusOK, meals)
 }
 
 // GetMeal retrieves a specific meal by ID
@@ -255,7 +261,7 @@ func (h *MealHandler) GetMeal(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "RETRIEVAL_FAILED"
 
-		if err.Error() == "meal not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -268,7 +274,7 @@ func (h *MealHandler) GetMeal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, meal)
+	h.display.respondNutrition(c, http.StatusOK, meal)
 }
 
 // UpdateMeal updates an existing meal
@@ -292,6 +298,9 @@ func (h *MealHandler) UpdateMeal(c *gin.Context) {
 	var req dto.CreateMealRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -301,11 +310,7 @@ func (h *MealHandler) UpdateMeal(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -314,7 +319,7 @@ func (h *MealHandler) UpdateMeal(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "UPDATE_FAILED"
 
-		if err.Error() == "meal not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -327,7 +332,7 @@ func (h *MealHandler) UpdateMeal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, meal)
+	h.display.respondNutrition(c, http.StatusOK, meal)
 }
 
 // DeleteMeal deletes a meal
@@ -352,7 +357,7 @@ func (h *MealHandler) DeleteMeal(c *gin.Context) {
 		statusCode := http.StatusInternalServerError
 		errorCode := "DELETE_FAILED"
 
-		if err.Error() == "meal not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -367,3 +372,61 @@ func (h *MealHandler) DeleteMeal(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// confirmParsedMeal saves the stored parsed meal with the request's item changes applied.
+// It writes the error response itself and returns ok=false when the meal cannot be saved.
+func (h *MealHandler) confirmParsedMeal(c *gin.Context, req dto.ConfirmMealRequest) (*domain.Meal, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	parsedMealID, ok := bindID(c, req.ParsedMealID, "parsed_meal_id")
+	if !ok {
+		return nil, false
+	}
+
+	// Taken rather than read, so a second confirmation arriving meanwhile gets a 404
+	// instead of logging the meal again
+	parsed, err := h.parsedMeals.TakeParsedMeal(userID, parsedMealID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Parsed meal not found",
+			Message: err.Error(),
+			Code:    "NOT_FOUND",
+		})
+		return nil, false
+	}
+
+	adjustments := make([]domain.ParsedItemAdjustment, len(req.Items))
+	for i, item := range req.Items {
+		adjustments[i] = domain.ParsedItemAdjustment{Index: item.Index, Exclude: item.Exclude, Quantity: item.Quantity}
+	}
+	if err := parsed.ApplyAdjustments(adjustments); err != nil {
+		h.parsedMeals.ReturnParsedMeal(parsedMealID)
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid item changes",
+			Message: err.Error(),
+			Code:    "VALIDATION_ERROR",
+		})
+		return nil, false
+	}
+
+	meal, err := h.mealService.ConfirmParsedMeal(c.Request.Context(), userID.String(), parsed)
+	if err != nil {
+		h.parsedMeals.ReturnParsedMeal(parsedMealID)
+		status, code := http.StatusInternalServerError, "CONFIRM_FAILED"
+		if errors.Is(err, domain.ErrInvalidInput) {
+			status, code = http.StatusBadRequest, "VALIDATION_ERROR"
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   "Failed to confirm meal",
+			Message: err.Error(),
+			Code:    code,
+		})
+		return nil, false
+	}
+
+	// A parsed meal is logged once; confirming it again would double the entry
+	h.parsedMeals.ForgetParsedMeal(parsedMealID)
+	return meal, true
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"time"
 
@@ -9,20 +9,27 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
+// metricTrendDefaultLimit is how many trend entries are returned without a limit
+const metricTrendDefaultLimit = 30
+
 // MetricHandler handles body metric-related requests
 type MetricHandler struct {
 	metricService ports.MetricService
 	validator     *validator.Validate
+	pages         PageLimits
 }
 
 // NewMetricHandler creates a new metric handler
-func NewMetricHandler(metricService ports.MetricService) *MetricHandler {
+func NewMetricHandler(metricService ports.MetricService, pages PageLimits) *MetricHandler {
 	return &MetricHandler{
 		metricService: metricService,
-		validator:     validator.New(),
+		validator:     middleware.NewValidator(),
+		pages:         pages,
 	}
 }
 
@@ -44,6 +51,9 @@ func (h *MetricHandler) LogMetric(c *gin.Context) {
 	var req dto.LogMetricRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -53,11 +63,7 @@ func (h *MetricHandler) LogMetric(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
@@ -79,6 +85,76 @@ func (h *MetricHandler) LogMetric(c *gin.Context) {
 	c.JSON(http.StatusCreated, metric)
 }
 
+// LogMetricBatch logs many body metrics at once
+// @Summary Log body metrics in bulk
+// @Description Log up to 500 measurements at once, e.g. a wearable sync. All accepted rows are saved in one transaction. Rows with an unknown type, a missing unit or recorded_at, or an implausible value are reported in errors by position without failing the rest. Rows with the same metric_type and recorded_at as an already logged measurement are skipped as duplicates, so a sync can be retried safely.
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.LogMetricBatchRequest true "Metrics to log"
+// @Success 200 {object} domain.MetricBatchResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /metrics/batch [post]
+func (h *MetricHandler) LogMetricBatch(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.LogMetricBatchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	entries := make([]domain.MetricEntry, 0, len(req.Metrics))
+	for _, row := range req.Metrics {
+		entry := domain.MetricEntry{
+			MetricType: row.MetricType,
+			Value:      row.Value,
+			Unit:       row.Unit,
+			MeasuredAt: row.RecordedAt,
+		}
+		if row.Notes != "" {
+			notes := row.Notes
+			entry.Notes = &notes
+		}
+		entries = append(entries, entry)
+	}
+
+	result, err := h.metricService.LogMetricBatch(c.Request.Context(), userID.(string), entries)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "LOG_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "VALIDATION_ERROR"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to log metrics",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // GetMetricTrend retrieves metric trend data
 // @Summary Get metric trend
 // @Description Retrieve trend data for a specific metric type over a time period
@@ -87,8 +163,8 @@ func (h *MetricHandler) LogMetric(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param type path string true "Metric type (weight, body_fat, muscle_mass, bmi, waist_circumference)"
-// @Param start_date query string false "Start date (YYYY-MM-DD)"
-// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 29 days before end_date when only end_date is given"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today; the range may span at most 366 days"
 // @Param limit query int false "Maximum number of results" default(30)
 // @Success 200 {array} dto.MetricResponse
 // @Failure 400 {object} dto.ErrorResponse
@@ -118,58 +194,135 @@ func (h *MetricHandler) GetMetricTrend(c *gin.Context) {
 	}
 
 	// Parse query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-	limit := 30
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid limit parameter",
-				Message: "Limit must be a valid integer",
-				Code:    "INVALID_LIMIT",
-			})
-			return
-		}
+	limit, ok := h.pages.bindLimit(c, metricTrendDefaultLimit)
+	if !ok {
+		return
 	}
 
-	var startDate, endDate *time.Time
+	startDate, endDate, ok := bindDateRange(c, "start_date", "end_date", defaultRangeDays)
+	if !ok {
+		return
+	}
 
-	if startDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid start_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
+	metrics, err := h.metricService.GetMetricTrend(c.Request.Context(), userID.(string), metricType, startDate, endDate, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to retrieve metric trend",
+			Message: err.Error(),
+			Code:    "RETRIEVAL_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+// UpdateMetric corrects a logged metric
+// @Summary Update metric
+// @Description Correct a mis-logged measurement. Omitted fields are left unchanged. Values derived from a weight entry (paired BMI, profile weight, daily summary) are recomputed.
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Metric ID"
+// @Param request body dto.UpdateMetricRequest true "Metric fields"
+// @Success 200 {object} dto.MetricResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /metrics/{id} [put]
+func (h *MetricHandler) UpdateMetric(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	metricID := c.Param("id")
+	var req dto.UpdateMetricRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
 			return
 		}
-		startDate = &parsed
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
 	}
 
-	if endDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid end_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
-			return
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	metric, err := h.metricService.UpdateMetric(c.Request.Context(), userID.(string), metricID, &domain.MetricUpdate{
+		Value:      req.Value,
+		Unit:       req.Unit,
+		MeasuredAt: req.MeasuredAt,
+		Notes:      req.Notes,
+	})
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "UPDATE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "VALIDATION_ERROR"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
 		}
-		endDate = &parsed
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to update metric",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
 	}
 
-	metrics, err := h.metricService.GetMetricTrend(c.Request.Context(), userID.(string), metricType, startDate, endDate, limit)
+	c.JSON(http.StatusOK, metric)
+}
+
+// DeleteMetric deletes a logged metric
+// @Summary Delete metric
+// @Description Delete a mis-logged measurement. Values derived from a weight entry are recomputed.
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Metric ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /metrics/{id} [delete]
+func (h *MetricHandler) DeleteMetric(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	metricID := c.Param("id")
+
+	err := h.metricService.DeleteMetric(c.Request.Context(), userID.(string), metricID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to retrieve metric trend",
+		statusCode := http.StatusInternalServerError
+		errorCode := "DELETE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to delete metric",
 			Message: err.Error(),
-			Code:    "RETRIEVAL_FAILED",
+			Code:    errorCode,
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, metrics)
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/pkg/utils"
+)
+
+// PageLimits bounds the limit query parameter of list and search endpoints
+type PageLimits struct {
+	DefaultSize int // limit used when an endpoint has no default of its own
+	MaxSize     int // larger limits are capped to this
+}
+
+// bindLimit parses the limit query parameter with utils.ParseLimit. Without one it
+// returns defaultLimit, or DefaultSize when defaultLimit is 0. On an invalid limit
+// it writes a 400 response and returns ok=false.
+func (p PageLimits) bindLimit(c *gin.Context, defaultLimit int) (limit int, ok bool) {
+	if defaultLimit == 0 {
+		defaultLimit = p.DefaultSize
+	}
+
+	limit, err := utils.ParseLimit(c.Query("limit"), defaultLimit, p.MaxSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid limit parameter",
+			Message: "Limit must be a positive integer",
+			Code:    "INVALID_LIMIT",
+		})
+		return 0, false
+	}
+	return limit, true
+}
+
+// bindOffset parses the offset query parameter with utils.ParseOffset. On an invalid
+// offset it writes a 400 response and returns ok=false.
+func bindOffset(c *gin.Context) (offset int, ok bool) {
+	offset, err := utils.ParseOffset(c.Query("offset"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid offset parameter",
+			Message: "Offset must be a non-negative integer",
+			Code:    "INVALID_OFFSET",
+		})
+		return 0, false
+	}
+	return offset, true
+}
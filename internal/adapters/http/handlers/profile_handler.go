@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// ProfileHandler handles user profile requests
+type ProfileHandler struct {
+	profileService ports.ProfileService
+	validator      *validator.Validate
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(profileService ports.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		validator:      middleware.NewValidator(),
+	}
+}
+
+// GetProfile retrieves the authenticated user's profile
+// @Summary Get profile
+// @Description Retrieve the authenticated user's profile and preferences
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /profile [get]
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	user, err := h.profileService.GetProfile(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve profile",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toProfileResponse(user))
+}
+
+// UpdateProfile updates the authenticated user's profile
+// @Summary Update profile
+// @Description Update profile fields. Omitted fields are left unchanged.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateProfileRequest true "Profile fields"
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /profile [put]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.UpdateProfileRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(c.Request.Context(), userID.(string), &domain.ProfileUpdate{
+		Name:                    req.Name,
+		HeightCm:                req.HeightCm,
+		WeightKg:                req.WeightKg,
+		ActivityLevel:           req.ActivityLevel,
+		Timezone:                req.Timezone,
+		UnitSystem:              req.UnitSystem,
+		DietaryPreferences:      req.DietaryPreferences,
+		CalorieTargetMode:       req.CalorieTargetMode,
+		ExerciseCalorieFraction: req.ExerciseCalorieFraction,
+	})
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "UPDATE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "VALIDATION_ERROR"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to update profile",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, toProfileResponse(user))
+}
+
+// GetNutritionTargets retrieves the authenticated user's daily nutrition target
+// @Summary Get nutrition targets
+// @Description Retrieve the daily calorie, macro and water target that summaries and the coach measure against. Without a stored target, source is "default" and active nutrition goals override the defaults.
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.NutritionTarget
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /profile/nutrition-targets [get]
+func (h *ProfileHandler) GetNutritionTargets(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	target, err := h.profileService.GetNutritionTargets(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve nutrition targets",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+// UpdateNutritionTargets sets the authenticated user's daily nutrition target
+// @Summary Set nutrition targets
+// @Description Replace the daily nutrition target with the given values, or derive it from the profile with derive_from_profile, optionally splitting the calories by the macro preset of goal_type. Macros that do not add up to the calories are saved with warnings.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateNutritionTargetsRequest true "Nutrition targets"
+// @Success 200 {object} domain.NutritionTarget
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /profile/nutrition-targets [put]
+func (h *ProfileHandler) UpdateNutritionTargets(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.UpdateNutritionTargetsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var target *domain.NutritionTarget
+	var err error
+	if req.DeriveFromProfile {
+		target, err = h.profileService.DeriveNutritionTargets(c.Request.Context(), userID.(string), req.GoalType)
+	} else {
+		target, err = h.profileService.SetNutritionTargets(c.Request.Context(), userID.(string), &domain.NutritionTarget{
+			Calories:      valueOrZero(req.Calories),
+			Protein:       valueOrZero(req.Protein),
+			Carbohydrates: valueOrZero(req.Carbohydrates),
+			Fat:           valueOrZero(req.Fat),
+			WaterMl:       valueOrZero(req.WaterMl),
+		})
+	}
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "UPDATE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "VALIDATION_ERROR"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to update nutrition targets",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+// toProfileResponse converts a user to the profile response shape
+func toProfileResponse(user *domain.User) dto.ProfileResponse {
+	fullName := user.FirstName
+	if user.LastName != "" {
+		fullName += " " + user.LastName
+	}
+
+	var preferences map[string]interface{}
+	if user.DietaryPreferences != nil {
+		_ = json.Unmarshal([]byte(*user.DietaryPreferences), &preferences)
+	}
+
+	calorieTargetMode := user.CalorieTargetMode
+	if calorieTargetMode == "" {
+		calorieTargetMode = domain.CalorieTargetStatic
+	}
+	exerciseCalorieFraction := domain.DefaultExerciseCalorieFraction
+	if user.ExerciseCalorieFraction != nil {
+		exerciseCalorieFraction = *user.ExerciseCalorieFraction
+	}
+
+	return dto.ProfileResponse{
+		ID:                      user.ID.String(),
+		Email:                   user.Email,
+		Name:                    fullName,
+		EmailVerified:           user.EmailVerified,
+		HeightCm:                user.HeightCm,
+		WeightKg:                user.WeightKg,
+		ActivityLevel:           user.ActivityLevel,
+		Timezone:                user.Timezone,
+		UnitSystem:              user.UnitSystem,
+		DietaryPreferences:      preferences,
+		CalorieTargetMode:       calorieTargetMode,
+		ExerciseCalorieFraction: exerciseCalorieFraction,
+		CreatedAt:               user.CreatedAt,
+		UpdatedAt:               user.UpdatedAt,
+	}
+}
+
+// valueOrZero returns *v, or 0 when v is nil
+func valueOrZero(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// GetMealDistribution retrieves how the authenticated user splits their daily target between meal types
+// @Summary Get meal distribution
+// @Description Retrieve the percent of the daily target planned for breakfast, lunch, dinner and snacks. The daily summary's meal_targets are split by it. Without a stored distribution, source is "default" and the split is 25/35/30/10.
+// @Tags profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.MealDistribution
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /profile/meal-distribution [get]
+func (h *ProfileHandler) GetMealDistribution(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	distribution, err := h.profileService.GetMealDistribution(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve meal distribution",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, distribution)
+}
+
+// UpdateMealDistribution sets how the authenticated user splits their daily target between meal types
+// @Summary Set meal distribution
+// @Description Replace the percent of the daily target planned for each meal type. The four shares must add up to 100.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateMealDistributionRequest true "Meal distribution"
+// @Success 200 {object} domain.MealDistribution
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /profile/meal-distribution [put]
+func (h *ProfileHandler) UpdateMealDistribution(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var req dto.UpdateMealDistributionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	distribution, err := h.profileService.SetMealDistribution(c.Request.Context(), userID.(string), &domain.MealDistribution{
+		Breakfast: *req.Breakfast,
+		Lunch:     *req.Lunch,
+		Dinner:    *req.Dinner,
+		Snack:     *req.Snack,
+	})
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "UPDATE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "VALIDATION_ERROR"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to update meal distribution",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, distribution)
+}
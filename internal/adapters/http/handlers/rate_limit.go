@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+)
+
+// respondRateLimited writes a 429 when err comes from a rate-limited upstream provider,
+// passing the provider's Retry-After on to the client. It returns false for other errors.
+func respondRateLimited(c *gin.Context, err error) bool {
+	var rateLimited *domain.RateLimitError
+	if !errors.As(err, &rateLimited) {
+		return false
+	}
+
+	if rateLimited.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+	}
+	c.JSON(http.StatusTooManyRequests, dto.ErrorResponse{
+		Error:   "AI service is busy",
+		Message: "The AI provider is rate limiting requests, try again after the Retry-After delay",
+		Code:    "AI_RATE_LIMITED",
+	})
+	return true
+}
@@ -1,24 +1,28 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
 // SummaryHandler handles daily summary requests
 type SummaryHandler struct {
 	summaryService ports.SummaryService
+	display        Display
 }
 
 // NewSummaryHandler creates a new summary handler
-func NewSummaryHandler(summaryService ports.SummaryService) *SummaryHandler {
+func NewSummaryHandler(summaryService ports.SummaryService, display Display) *SummaryHandler {
 	return &SummaryHandler{
 		summaryService: summaryService,
+		display:        display,
 	}
 }
 
@@ -68,5 +72,110 @@ func (h *SummaryHandler) GetDailySummary(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, summary)
+	h.display.respondNutrition(c, http.StatusOK, summary)
+}
+
+// GetSummaryRange lists daily summaries over a date range for trend charts
+// @Summary Get daily summaries for a range
+// @Description One daily summary per day in the user's timezone, oldest first, for charting calories, activity and weight without a call per day. Days without a stored summary are computed on request; the range is capped at 92 days.
+// @Tags summary
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 29 days before to"
+// @Param to query string false "End date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} domain.DailySummaryRange
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /summary/range [get]
+func (h *SummaryHandler) GetSummaryRange(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	start, end, ok := bindDateRange(c, "from", "to", domain.DefaultSummaryRangeDays)
+	if !ok {
+		return
+	}
+
+	// Missing bounds are left to the service, which defaults them in the user's timezone
+	var from, to time.Time
+	if c.Query("from") != "" {
+		from = *start
+	}
+	if c.Query("to") != "" {
+		to = *end
+	}
+
+	summaries, err := h.summaryService.GetSummaryRange(c.Request.Context(), userID.(string), from, to)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_RANGE"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve daily summaries",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, summaries)
+}
+
+// adherenceDefaultDays is the span of an adherence range given only its end, matching the service default
+const adherenceDefaultDays = 7
+
+// GetAdherence reports how consistently the user met their nutrition targets
+// @Summary Get target adherence
+// @Description Per day in the user's timezone, whether each calorie/macro target was met, plus adherence percentages for the range
+// @Tags summary
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 6 days before to"
+// @Param to query string false "End date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} domain.AdherenceSummary
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /summary/adherence [get]
+func (h *SummaryHandler) GetAdherence(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	start, end, ok := bindDateRange(c, "from", "to", adherenceDefaultDays)
+	if !ok {
+		return
+	}
+
+	// Missing bounds are left to the service, which defaults them in the user's timezone
+	var from, to time.Time
+	if c.Query("from") != "" {
+		from = *start
+	}
+	if c.Query("to") != "" {
+		to = *end
+	}
+
+	summary, err := h.summaryService.GetAdherence(c.Request.Context(), userID.(string), from, to)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_RANGE"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve adherence",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, summary)
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// UndoHandler handles undo requests
+type UndoHandler struct {
+	undoService ports.UndoService
+	display     Display
+}
+
+// NewUndoHandler creates a new undo handler
+func NewUndoHandler(undoService ports.UndoService, display Display) *UndoHandler {
+	return &UndoHandler{
+		undoService: undoService,
+		display:     display,
+	}
+}
+
+// UndoLastAction reverses the user's most recent create or delete
+// @Summary Undo last action
+// @Description Reverse the user's most recent meal, activity, metric or workout create or delete made in the last 5 minutes. A created record is deleted and a deleted record is restored. Only the latest action can be undone, once.
+// @Tags actions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.UndoResult
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /actions/undo [post]
+func (h *UndoHandler) UndoLastAction(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	result, err := h.undoService.UndoLastAction(c.Request.Context(), userID.(string))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "UNDO_FAILED"
+		message := err.Error()
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOTHING_TO_UNDO"
+			message = "No recent action can be undone"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to undo last action",
+			Message: message,
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, result)
+}
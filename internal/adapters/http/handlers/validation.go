@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+)
+
+// respondValidationError writes a 400 VALIDATION_ERROR response for an error from
+// validator.Struct, with Details mapping each invalid field to a readable message
+func respondValidationError(c *gin.Context, err error) {
+	details := middleware.ValidationDetails(err)
+	message := "Invalid input data"
+	if details == nil {
+		message = err.Error()
+	}
+
+	c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		Error:   "Validation failed",
+		Message: message,
+		Code:    "VALIDATION_ERROR",
+		Details: details,
+	})
+}
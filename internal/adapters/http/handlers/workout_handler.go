@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 
 	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
 )
 
@@ -15,13 +19,15 @@ import (
 type WorkoutHandler struct {
 	workoutService ports.WorkoutService
 	validator      *validator.Validate
+	display        Display
 }
 
 // NewWorkoutHandler creates a new workout handler
-func NewWorkoutHandler(workoutService ports.WorkoutService) *WorkoutHandler {
+func NewWorkoutHandler(workoutService ports.WorkoutService, display Display) *WorkoutHandler {
 	return &WorkoutHandler{
 		workoutService: workoutService,
-		validator:      validator.New(),
+		validator:      middleware.NewValidator(),
+		display:        display,
 	}
 }
 
@@ -39,10 +45,16 @@ func NewWorkoutHandler(workoutService ports.WorkoutService) *WorkoutHandler {
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts/start [post]
 func (h *WorkoutHandler) StartWorkout(c *gin.Context) {
-	userID, _ := c.Get("userID")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 	var req dto.StartWorkoutRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -52,15 +64,11 @@ func (h *WorkoutHandler) StartWorkout(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
-			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
-		})
+		respondValidationError(c, err)
 		return
 	}
 
-	workout, err := h.workoutService.StartWorkout(c.Request.Context(), userID.(string), &req)
+	workout, err := h.workoutService.StartWorkout(c.Request.Context(), userID, req.Name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to start workout",
@@ -70,7 +78,51 @@ func (h *WorkoutHandler) StartWorkout(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, workout)
+	h.display.respondNutrition(c, http.StatusCreated, workout)
+}
+
+// CloneWorkout starts a new workout repeating a past one
+// @Summary Clone workout
+// @Description Start a new in-progress workout with the same name and exercises, in order, as one of the user's workouts. The source's sets become planned_sets on each exercise, their weights suggestions only; the clone has no logged sets.
+// @Tags workouts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID of the workout to clone"
+// @Success 201 {object} domain.Workout
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workouts/{id}/clone [post]
+func (h *WorkoutHandler) CloneWorkout(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	workout, err := h.workoutService.CloneWorkout(c.Request.Context(), userID, workoutID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "CLONE_FAILED"
+
+		if errors.Is(err, domain.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to clone workout",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusCreated, workout)
 }
 
 // GetWorkouts retrieves workouts for a user
@@ -80,61 +132,60 @@ func (h *WorkoutHandler) StartWorkout(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param start_date query string false "Start date (YYYY-MM-DD)"
-// @Param end_date query string false "End date (YYYY-MM-DD)"
-// @Param status query string false "Filter by status (in_progress, completed, cancelled)"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 29 days before end_date when only end_date is given"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today; the range may span at most 366 days"
+// @Param status query string false "Filter by status (in_progress, completed)"
+// @Param exercise_id query string false "Only workouts that include this exercise or one of its aliases"
 // @Success 200 {array} dto.WorkoutResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts [get]
 func (h *WorkoutHandler) GetWorkouts(c *gin.Context) {
-	userID, _ := c.Get("userID")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 
 	// Parse query parameters
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-	status := c.Query("status")
-
-	var startDate, endDate *time.Time
-
-	if startDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid start_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
+	filter := domain.WorkoutFilter{Status: c.Query("status")}
+	if exerciseID := c.Query("exercise_id"); exerciseID != "" {
+		parsed, ok := bindID(c, exerciseID, "exercise_id")
+		if !ok {
 			return
 		}
-		startDate = &parsed
+		filter.ExerciseID = &parsed
 	}
 
-	if endDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid end_date format",
-				Message: "Use YYYY-MM-DD format",
-				Code:    "INVALID_DATE",
-			})
-			return
-		}
-		endDate = &parsed
+	startDate, endDate, ok := bindDateRange(c, "start_date", "end_date", defaultRangeDays)
+	if !ok {
+		return
 	}
 
-	workouts, err := h.workoutService.GetWorkouts(c.Request.Context(), userID.(string), startDate, endDate, status)
+	workouts, err := h.workoutService.GetWorkouts(c.Request.Context(), userID, startDate, endDate, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
 			Error:   "Failed to retrieve workouts",
 			Message: err.Error(),
-			Code:    "RETRIEVAL_FAILED",
+			Code:    errorCode,
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, workouts)
+	h.display.respondNutrition(c, http.StatusOK, workouts)
 }
 
 // GetWorkout retrieves a specific workout by ID
@@ -146,20 +197,27 @@ func (h *WorkoutHandler) GetWorkouts(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Workout ID"
 // @Success 200 {object} dto.WorkoutResponse
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts/{id} [get]
 func (h *WorkoutHandler) GetWorkout(c *gin.Context) {
-	userID, _ := c.Get("userID")
-	workoutID := c.Param("id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
 
-	workout, err := h.workoutService.GetWorkout(c.Request.Context(), userID.(string), workoutID)
+	workout, err := h.workoutService.GetWorkout(c.Request.Context(), userID, workoutID)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errorCode := "RETRIEVAL_FAILED"
 
-		if err.Error() == "workout not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -172,32 +230,43 @@ func (h *WorkoutHandler) GetWorkout(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, workout)
+	h.display.respondNutrition(c, http.StatusOK, workout)
 }
 
 // FinishWorkout finishes an active workout
 // @Summary Finish workout
-// @Description Mark an active workout as completed
+// @Description Mark an active workout as completed. The response carries a summary for the post-workout screen: duration without breaks, total sets, reps and volume, estimated calories and the personal records set.
 // @Tags workouts
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Workout ID"
-// @Success 200 {object} dto.WorkoutResponse
+// @Success 200 {object} domain.Workout
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts/{id}/finish [post]
 func (h *WorkoutHandler) FinishWorkout(c *gin.Context) {
-	userID, _ := c.Get("userID")
-	workoutID := c.Param("id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
 
-	workout, err := h.workoutService.FinishWorkout(c.Request.Context(), userID.(string), workoutID)
+	workout, err := h.workoutService.FinishWorkout(c.Request.Context(), userID, workoutID)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errorCode := "FINISH_FAILED"
 
-		if err.Error() == "workout not found" {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -210,7 +279,188 @@ func (h *WorkoutHandler) FinishWorkout(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, workout)
+	h.display.respondNutrition(c, http.StatusOK, workout)
+}
+
+// PauseWorkout pauses an unfinished workout
+// @Summary Pause workout
+// @Description Start a break; time paused does not count toward the workout's duration
+// @Tags workouts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workout ID"
+// @Success 200 {object} domain.Workout
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workouts/{id}/pause [post]
+func (h *WorkoutHandler) PauseWorkout(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	workout, err := h.workoutService.PauseWorkout(c.Request.Context(), userID, workoutID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "PAUSE_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		case errors.Is(err, domain.ErrConflict):
+			statusCode = http.StatusConflict
+			errorCode = "ALREADY_PAUSED"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to pause workout",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, workout)
+}
+
+// ResumeWorkout resumes a paused workout
+// @Summary Resume workout
+// @Description End the current break of a paused workout
+// @Tags workouts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workout ID"
+// @Success 200 {object} domain.Workout
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workouts/{id}/resume [post]
+func (h *WorkoutHandler) ResumeWorkout(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	workout, err := h.workoutService.ResumeWorkout(c.Request.Context(), userID, workoutID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RESUME_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		case errors.Is(err, domain.ErrConflict):
+			statusCode = http.StatusConflict
+			errorCode = "NOT_PAUSED"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to resume workout",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, workout)
+}
+
+// ReorderExercises changes the order of a workout's exercises
+// @Summary Reorder workout exercises
+// @Description Move the listed workout exercises into the given order; exercises left out keep their positions
+// @Tags workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workout ID"
+// @Param request body dto.ReorderWorkoutExercisesRequest true "Workout exercise IDs in their new order"
+// @Success 200 {array} domain.WorkoutExercise
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workouts/{id}/exercises/reorder [put]
+func (h *WorkoutHandler) ReorderExercises(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReorderWorkoutExercisesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	workoutExerciseIDs, ok := bindIDs(c, req.WorkoutExerciseIDs, "workout_exercise_ids")
+	if !ok {
+		return
+	}
+
+	exercises, err := h.workoutService.ReorderExercises(c.Request.Context(), userID, workoutID, workoutExerciseIDs)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "REORDER_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		case errors.Is(err, domain.ErrConflict):
+			statusCode = http.StatusConflict
+			errorCode = "CONFLICT"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to reorder exercises",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, exercises)
 }
 
 // AddExercise adds an exercise to a workout
@@ -229,11 +479,15 @@ func (h *WorkoutHandler) FinishWorkout(c *gin.Context) {
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts/{id}/exercises [post]
 func (h *WorkoutHandler) AddExercise(c *gin.Context) {
-	userID, _ := c.Get("userID")
-	workoutID := c.Param("id")
-	exerciseID := c.Query("exercise_id")
-
-	if exerciseID == "" {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if c.Query("exercise_id") == "" {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Missing exercise_id",
 			Message: "exercise_id query parameter is required",
@@ -241,13 +495,17 @@ func (h *WorkoutHandler) AddExercise(c *gin.Context) {
 		})
 		return
 	}
+	exerciseID, ok := bindID(c, c.Query("exercise_id"), "exercise_id")
+	if !ok {
+		return
+	}
 
-	workout, err := h.workoutService.AddExercise(c.Request.Context(), userID.(string), workoutID, exerciseID)
+	workout, err := h.workoutService.AddExercise(c.Request.Context(), userID, workoutID, exerciseID)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errorCode := "ADD_EXERCISE_FAILED"
 
-		if err.Error() == "workout not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -260,7 +518,7 @@ func (h *WorkoutHandler) AddExercise(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, workout)
+	h.display.respondNutrition(c, http.StatusOK, workout)
 }
 
 // LogSet logs a set for an exercise in a workout
@@ -278,10 +536,16 @@ func (h *WorkoutHandler) AddExercise(c *gin.Context) {
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts/sets [post]
 func (h *WorkoutHandler) LogSet(c *gin.Context) {
-	userID, _ := c.Get("userID")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 	var req dto.LogSetRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -291,33 +555,123 @@ func (h *WorkoutHandler) LogSet(c *gin.Context) {
 	}
 
 	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	workout, err := h.workoutService.LogSet(c.Request.Context(), userID, &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "LOG_SET_FAILED"
+
+		if errors.Is(err, domain.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to log set",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, workout)
+}
+
+// LogSets logs several sets of a workout exercise at once
+// @Summary Log exercise sets in bulk
+// @Description Log up to 50 sets of one exercise of an unfinished workout in one request. Set numbers continue from the exercise's last set. Sets with negative values, an RPE off the 0-10 scale or an unknown set_type are reported in errors by position; the others are saved together.
+// @Tags workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workout ID"
+// @Param exerciseId path string true "Workout exercise ID"
+// @Param request body dto.LogSetBatchRequest true "Sets to log"
+// @Success 201 {object} domain.SetBatchResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workouts/{id}/exercises/{exerciseId}/sets/bulk [post]
+func (h *WorkoutHandler) LogSets(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+	workoutExerciseID, ok := bindIDParam(c, "exerciseId")
+	if !ok {
+		return
+	}
+	var req dto.LogSetBatchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation failed",
+			Error:   "Invalid request body",
 			Message: err.Error(),
-			Code:    "VALIDATION_ERROR",
+			Code:    "INVALID_REQUEST",
 		})
 		return
 	}
 
-	workout, err := h.workoutService.LogSet(c.Request.Context(), userID.(string), &req)
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	sets := make([]*domain.WorkoutSet, len(req.Sets))
+	for i, row := range req.Sets {
+		set := &domain.WorkoutSet{
+			Reps:            row.Reps,
+			Weight:          row.Weight,
+			DurationSeconds: row.DurationSeconds,
+			Distance:        row.Distance,
+			RestSeconds:     row.RestSeconds,
+			RPE:             row.RPE,
+			SetType:         row.SetType,
+		}
+		if row.GroupID != "" {
+			groupID := uuid.MustParse(row.GroupID) // checked by the validator
+			set.GroupID = &groupID
+		}
+		if row.Notes != "" {
+			notes := row.Notes
+			set.Notes = &notes
+		}
+		sets[i] = set
+	}
+
+	result, err := h.workoutService.LogSets(c.Request.Context(), userID, workoutID, workoutExerciseID, sets)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errorCode := "LOG_SET_FAILED"
 
-		if err.Error() == "workout not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
+		} else if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "VALIDATION_ERROR"
 		}
 
 		c.JSON(statusCode, dto.ErrorResponse{
-			Error:   "Failed to log set",
+			Error:   "Failed to log sets",
 			Message: err.Error(),
 			Code:    errorCode,
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, workout)
+	c.JSON(http.StatusCreated, result)
 }
 
 // DeleteWorkout deletes a workout
@@ -329,20 +683,27 @@ func (h *WorkoutHandler) LogSet(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Workout ID"
 // @Success 204
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /workouts/{id} [delete]
 func (h *WorkoutHandler) DeleteWorkout(c *gin.Context) {
-	userID, _ := c.Get("userID")
-	workoutID := c.Param("id")
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	workoutID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
 
-	err := h.workoutService.DeleteWorkout(c.Request.Context(), userID.(string), workoutID)
+	err := h.workoutService.DeleteWorkout(c.Request.Context(), userID, workoutID)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		errorCode := "DELETE_FAILED"
 
-		if err.Error() == "workout not found" {
+		if errors.Is(err, domain.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			errorCode = "NOT_FOUND"
 		}
@@ -357,3 +718,237 @@ func (h *WorkoutHandler) DeleteWorkout(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// exerciseDetailResponse is an exercise with the user's performance of it
+type exerciseDetailResponse struct {
+	*domain.Exercise
+	Performance *domain.ExercisePerformance `json:"performance"`
+}
+
+// GetExercise returns an exercise, optionally with the user's recent performance
+// @Summary Get exercise
+// @Description Exercise library details. With include=performance the response also carries the user's recent sets, current estimated 1RM and personal records for the exercise. A matching If-None-Match gets 304 with no body.
+// @Tags exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ID"
+// @Param include query string false "Set to performance to add the user's performance" Enums(performance)
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Success 200 {object} domain.Exercise
+// @Success 304 "Not modified"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /exercises/{id} [get]
+func (h *WorkoutHandler) GetExercise(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	exerciseID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	include := c.Query("include")
+	if include != "" && include != "performance" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid include",
+			Message: "include must be performance",
+			Code:    "INVALID_INPUT",
+		})
+		return
+	}
+
+	exercise, err := h.workoutService.GetExercise(c.Request.Context(), exerciseID)
+	if err != nil {
+		respondExerciseError(c, err)
+		return
+	}
+
+	if include == "" {
+		c.JSON(http.StatusOK, exercise)
+		return
+	}
+
+	performance, err := h.workoutService.GetExercisePerformance(c.Request.Context(), userID, exerciseID)
+	if err != nil {
+		respondExerciseError(c, err)
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, exerciseDetailResponse{Exercise: exercise, Performance: performance})
+}
+
+// CreateExercise adds an exercise to the library
+// @Summary Create exercise
+// @Description Add an exercise to the library. The name is trimmed; if an exercise of the same name exists regardless of case it is returned with 200 instead. Otherwise the new exercise lists likely duplicates in warnings. Set canonical_id to make it an alias that shares the canonical exercise's history and personal records.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateExerciseRequest true "Exercise data"
+// @Success 200 {object} domain.Exercise
+// @Success 201 {object} domain.Exercise
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /exercises [post]
+func (h *WorkoutHandler) CreateExercise(c *gin.Context) {
+	var req dto.CreateExerciseRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if respondBodyTooLarge(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	exercise := &domain.Exercise{
+		Name:     req.Name,
+		Category: req.Category,
+	}
+	if req.MuscleGroup != "" {
+		exercise.MuscleGroup = &req.MuscleGroup
+	}
+	if req.Equipment != "" {
+		exercise.Equipment = &req.Equipment
+	}
+	if req.Description != "" {
+		exercise.Description = &req.Description
+	}
+	if canonicalID, err := uuid.Parse(req.CanonicalID); err == nil {
+		exercise.CanonicalID = &canonicalID
+	}
+
+	exercise, created, err := h.workoutService.CreateExercise(c.Request.Context(), exercise)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "CREATE_FAILED"
+		if errors.Is(err, domain.ErrInvalidInput) {
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		}
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to create exercise",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, exercise)
+		return
+	}
+	c.JSON(http.StatusCreated, exercise)
+}
+
+// respondExerciseError writes the error response for a failed exercise lookup
+func respondExerciseError(c *gin.Context, err error) {
+	statusCode := http.StatusInternalServerError
+	errorCode := "RETRIEVAL_FAILED"
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_INPUT"
+	case errors.Is(err, domain.ErrNotFound):
+		statusCode = http.StatusNotFound
+		errorCode = "NOT_FOUND"
+	}
+
+	c.JSON(statusCode, dto.ErrorResponse{
+		Error:   "Failed to retrieve exercise",
+		Message: err.Error(),
+		Code:    errorCode,
+	})
+}
+
+// GetExerciseHistory returns every set the user performed for an exercise
+// @Summary Get exercise history
+// @Description Every set the user logged for an exercise across workouts, oldest first, with estimated 1RM
+// @Tags exercises
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Exercise ID"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {array} domain.ExerciseSetRecord
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /exercises/{id}/history [get]
+func (h *WorkoutHandler) GetExerciseHistory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	exerciseID, ok := bindIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid from format",
+				Message: "Use YYYY-MM-DD format",
+				Code:    "INVALID_DATE",
+			})
+			return
+		}
+		from = &parsed
+	}
+
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid to format",
+				Message: "Use YYYY-MM-DD format",
+				Code:    "INVALID_DATE",
+			})
+			return
+		}
+		to = &parsed
+	}
+
+	history, err := h.workoutService.GetExerciseHistory(c.Request.Context(), userID, exerciseID, from, to)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "RETRIEVAL_FAILED"
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			statusCode = http.StatusBadRequest
+			errorCode = "INVALID_INPUT"
+		case errors.Is(err, domain.ErrNotFound):
+			statusCode = http.StatusNotFound
+			errorCode = "NOT_FOUND"
+		}
+
+		c.JSON(statusCode, dto.ErrorResponse{
+			Error:   "Failed to retrieve exercise history",
+			Message: err.Error(),
+			Code:    errorCode,
+		})
+		return
+	}
+
+	h.display.respondNutrition(c, http.StatusOK, history)
+}
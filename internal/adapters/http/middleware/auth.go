@@ -6,10 +6,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"fitness-tracker/internal/pkg/auth"
 )
 
-// AuthJWT validates JWT token from Authorization header and adds userID to context
-func AuthJWT(jwtSecret string) gin.HandlerFunc {
+// AuthJWT validates JWT token from Authorization header and adds userID to context.
+// The token may be signed by any key in the key set, which allows secrets to be rotated.
+func AuthJWT(keys *auth.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -33,13 +36,7 @@ func AuthJWT(jwtSecret string) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := keys.Parse(tokenString)
 
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -83,3 +80,28 @@ func AuthJWT(jwtSecret string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireAdmin allows only the listed user IDs through. It must run after AuthJWT.
+// With no admins configured every request is rejected.
+func RequireAdmin(adminUserIDs []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[strings.ToLower(id)] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		id, _ := userID.(string)
+		if !admins[strings.ToLower(id)] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin access required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// originalBodyKey keeps the unlimited request body, so a route's own limit replaces the
+// global one instead of being capped by it
+const originalBodyKey = "bodyLimitOriginalBody"
+
+// BodyLimit caps request bodies at maxBytes: reading past the limit fails with
+// *http.MaxBytesError, which handlers answer with 413, and the connection is closed
+// so the rest of the body is never read. The last BodyLimit applied to a route wins,
+// so routes can raise or lower the global limit. 0 removes the limit.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, ok := c.Get(originalBodyKey)
+		if !ok {
+			body = c.Request.Body
+			c.Set(originalBodyKey, body)
+		}
+		original, _ := body.(io.ReadCloser)
+		if original == nil {
+			c.Next()
+			return
+		}
+
+		if maxBytes <= 0 {
+			c.Request.Body = original
+			c.Next()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, original, maxBytes)
+
+		c.Next()
+	}
+}
+
+// BodyTooLargeMessage tells the client the limit its request body went over
+func BodyTooLargeMessage(maxBytes int64) string {
+	switch {
+	case maxBytes >= 1<<20 && maxBytes%(1<<20) == 0:
+		return "The request body must not exceed " + strconv.FormatInt(maxBytes>>20, 10) + " MB"
+	case maxBytes >= 1<<10 && maxBytes%(1<<10) == 0:
+		return "The request body must not exceed " + strconv.FormatInt(maxBytes>>10, 10) + " KB"
+	}
+	return "The request body must not exceed " + strconv.FormatInt(maxBytes, 10) + " bytes"
+}
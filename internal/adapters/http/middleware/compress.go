@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Compress gzips or deflates responses of clients that accept it, preferring gzip.
+// Responses shorter than minSize bytes are sent as they are, since compressing them
+// saves little. Streams are never compressed: requests accepting text/event-stream,
+// responses of that type, and responses flushed before reaching minSize.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = writer
+		defer writer.finish()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header, or "" when
+// the client accepts neither. Encodings given a quality of 0 are refused.
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if quality, err := strconv.ParseFloat(q, 64); err == nil && quality <= 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+
+	switch {
+	case accepted["gzip"] || accepted["*"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter holds back the start of a response until it reaches minSize, then
+// either compresses it or, for streams and encoded bodies, passes it through
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf         []byte
+	encoder     io.WriteCloser // set once compression starts
+	passthrough bool
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	switch {
+	case w.passthrough:
+		return w.ResponseWriter.Write(data)
+	case w.encoder != nil:
+		return w.encoder.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.minSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends what has been written so far. A response flushed before it is known to
+// be large enough is treated as a stream and is not compressed.
+func (w *compressWriter) Flush() {
+	if w.encoder == nil && !w.passthrough {
+		w.passthrough = true
+		w.writeBuffered()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start decides how the response is sent once it has reached minSize
+func (w *compressWriter) start() error {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		w.passthrough = true
+		return w.writeBuffered()
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if w.encoding == "gzip" {
+		w.encoder = gzip.NewWriter(w.ResponseWriter)
+	} else {
+		w.encoder = zlib.NewWriter(w.ResponseWriter) // HTTP's deflate is the zlib format
+	}
+
+	buf := w.buf
+	w.buf = nil
+	_, err := w.encoder.Write(buf)
+	return err
+}
+
+// writeBuffered sends the held back bytes uncompressed
+func (w *compressWriter) writeBuffered() error {
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish ends the response: a small response goes out as it is, a compressed one is closed
+func (w *compressWriter) finish() {
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		return
+	}
+	_ = w.writeBuffered()
+}
@@ -2,18 +2,20 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // CORSConfig holds CORS configuration
 type CORSConfig struct {
-	AllowedOrigins   []string
+	AllowedOrigins   []string // "*" allows any origin
 	AllowedMethods   []string
-	AllowedHeaders   []string
-	ExposedHeaders   []string
+	AllowedHeaders   []string // empty allows whatever headers a preflight asks for
+	ExposedHeaders   []string // X-Request-ID is always exposed
 	AllowCredentials bool
-	MaxAge           int
+	MaxAge           int // seconds a preflight may be cached
 }
 
 // DefaultCORSConfig returns default CORS configuration
@@ -43,60 +45,96 @@ func DefaultCORSConfig() CORSConfig {
 	}
 }
 
-// CORS creates a middleware that handles Cross-Origin Resource Sharing
+// CORS creates a middleware that handles Cross-Origin Resource Sharing.
+//
+// Origins listed explicitly are echoed back, with credentials when AllowCredentials
+// is set. An origin allowed only by "*" gets a literal "*" and never credentials,
+// since browsers reject credentials with a wildcard origin. Preflight requests are
+// answered here for every route, with 204 or 403 for an origin that is not allowed.
 func CORS(config CORSConfig) gin.HandlerFunc {
+	wildcard := false
+	origins := make(map[string]bool, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			wildcard = true
+			continue
+		}
+		origins[normalizeOrigin(origin)] = true
+	}
+
+	allowMethods := strings.Join(config.AllowedMethods, ", ")
+	allowHeaders := strings.Join(config.AllowedHeaders, ", ")
+	exposed := config.ExposedHeaders
+	if !containsHeader(exposed, RequestIDHeader) {
+		exposed = append(append([]string{}, exposed...), RequestIDHeader)
+	}
+	exposeHeaders := strings.Join(exposed, ", ")
+
 	return func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-		// Check if origin is allowed
-		allowed := false
-		for _, allowedOrigin := range config.AllowedOrigins {
-			if allowedOrigin == "*" || allowedOrigin == origin {
-				allowed = true
-				break
-			}
+		// Same-origin and non-browser requests carry no Origin
+		if origin == "" {
+			c.Next()
+			return
 		}
 
-		if allowed {
-			// Set CORS headers
-			if len(config.AllowedOrigins) == 1 && config.AllowedOrigins[0] == "*" {
-				c.Header("Access-Control-Allow-Origin", "*")
-			} else {
-				c.Header("Access-Control-Allow-Origin", origin)
-			}
+		// The response depends on the origin, so caches must key on it
+		c.Writer.Header().Add("Vary", "Origin")
 
-			// Set other CORS headers
-			c.Header("Access-Control-Allow-Methods", joinStrings(config.AllowedMethods, ", "))
-			c.Header("Access-Control-Allow-Headers", joinStrings(config.AllowedHeaders, ", "))
-			c.Header("Access-Control-Expose-Headers", joinStrings(config.ExposedHeaders, ", "))
+		listed := origins[normalizeOrigin(origin)]
+		if !listed && !wildcard {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// Without CORS headers the browser keeps the response from the page
+			c.Next()
+			return
+		}
 
+		if listed {
+			c.Header("Access-Control-Allow-Origin", origin)
 			if config.AllowCredentials {
 				c.Header("Access-Control-Allow-Credentials", "true")
 			}
-
-			if config.MaxAge > 0 {
-				c.Header("Access-Control-Max-Age", string(rune(config.MaxAge)))
-			}
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
 		}
 
-		// Handle preflight requests
-		if c.Request.Method == http.MethodOptions {
-			c.AbortWithStatus(http.StatusNoContent)
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			c.Next()
 			return
 		}
 
-		c.Next()
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			c.Header("Access-Control-Allow-Headers", requested)
+		}
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
 	}
 }
 
-// joinStrings joins string slice with separator
-func joinStrings(strs []string, sep string) string {
-	if len(strs) == 0 {
-		return ""
-	}
-	result := strs[0]
-	for i := 1; i < len(strs); i++ {
-		result += sep + strs[i]
+// normalizeOrigin compares origins ignoring case and a trailing slash
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
+
+// containsHeader reports whether headers contains name, ignoring case
+func containsHeader(headers []string, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header, name) {
+			return true
+		}
 	}
-	return result
+	return false
 }
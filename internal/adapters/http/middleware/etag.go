@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag tags successful GET responses with a hash of their body and answers a request
+// whose If-None-Match holds the current tag with 304 Not Modified and no body. Meant
+// for lookups of records that rarely change: the handler still runs, but an unchanged
+// record costs the client a few bytes instead of the whole payload.
+//
+// Tags are weak (W/"...") because compression may change the bytes on the wire while
+// the content stays the same.
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &etagWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() != http.StatusOK {
+			_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(writer.body.Bytes())
+		tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		header := writer.Header()
+		header.Set("ETag", tag)
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", "private, no-cache") // per user, and always revalidated
+		}
+
+		if etagMatches(c.GetHeader("If-None-Match"), tag) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+			writer.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists tag, comparing weakly as
+// RFC 9110 requires for If-None-Match
+func etagMatches(ifNoneMatch, tag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter holds back the response body so it can be hashed before anything is sent
+type etagWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
@@ -7,8 +7,10 @@ import (
 	"go.uber.org/zap"
 )
 
-// Logger creates a middleware that logs HTTP requests using zap structured logging
-func Logger(logger *zap.Logger) gin.HandlerFunc {
+// Logger creates a middleware that logs HTTP requests using zap structured logging.
+// Requests taking longer than slowThreshold are tagged slow and logged at WARN at
+// least; a slowThreshold of 0 turns this off.
+func Logger(logger *zap.Logger, slowThreshold time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
@@ -16,7 +18,7 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		query := c.Request.URL.RawQuery
 
 		// Get request ID from context (set by RequestID middleware)
-		requestID, _ := c.Get("requestID")
+		requestID := GetRequestID(c)
 
 		// Process request
 		c.Next()
@@ -39,9 +41,14 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 			zap.String("user_agent", userAgent),
 		}
 
+		slow := slowThreshold > 0 && latency > slowThreshold
+		if slow {
+			fields = append(fields, zap.Bool("slow", true), zap.Duration("slow_threshold", slowThreshold))
+		}
+
 		// Add request ID if available
-		if requestID != nil {
-			fields = append(fields, zap.String("request_id", requestID.(string)))
+		if requestID != "" {
+			fields = append(fields, zap.String("request_id", requestID))
 		}
 
 		// Add user ID if authenticated
@@ -63,6 +70,8 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		} else if status >= 400 {
 			// Client errors
 			logger.Warn("HTTP request completed with client error", fields...)
+		} else if slow {
+			logger.Warn("HTTP request completed slowly", fields...)
 		} else {
 			// Success
 			logger.Info("HTTP request completed", fields...)
@@ -1,8 +1,15 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"fitness-tracker/internal/pkg/requestid"
 )
 
 const (
@@ -13,7 +20,9 @@ const (
 )
 
 // RequestID creates a middleware that generates a unique UUID for each request
-// and adds it to both the context and response headers
+// and adds it to the gin context, the request's context.Context (for services
+// and outgoing AI calls) and the response headers. JSON error responses also
+// get it as "request_id" so users can quote it to support.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check if request ID already exists in header
@@ -24,13 +33,19 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 
-		// Add request ID to context for downstream handlers
+		// Add request ID to context for downstream handlers and services
 		c.Set(RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
 
 		// Add request ID to response headers
 		c.Header(RequestIDHeader, requestID)
 
+		writer := &errorBodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
 		c.Next()
+
+		writer.flush(requestID)
 	}
 }
 
@@ -41,3 +56,49 @@ func GetRequestID(c *gin.Context) string {
 	}
 	return ""
 }
+
+// errorBodyWriter holds back JSON error bodies (status 400 and above) so the
+// request ID can be added before they are sent. Other responses, including
+// streams, pass straight through.
+type errorBodyWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	buffered bool
+}
+
+func (w *errorBodyWriter) holdsBack() bool {
+	return w.Status() >= http.StatusBadRequest &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (w *errorBodyWriter) Write(data []byte) (int, error) {
+	if w.holdsBack() {
+		w.buffered = true
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *errorBodyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// flush sends a held-back error body with request_id set. Bodies that are not
+// JSON objects, or already carry a request_id, are sent unchanged.
+func (w *errorBodyWriter) flush(requestID string) {
+	if !w.buffered {
+		return
+	}
+
+	body := w.body.Bytes()
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err == nil {
+		if _, ok := fields["request_id"]; !ok {
+			fields["request_id"] = requestID
+			if withID, err := json.Marshal(fields); err == nil {
+				body = withID
+			}
+		}
+	}
+	_, _ = w.ResponseWriter.Write(body)
+}
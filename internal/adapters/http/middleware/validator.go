@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -10,7 +14,7 @@ import (
 var validate *validator.Validate
 
 func init() {
-	validate = validator.New()
+	validate = NewValidator()
 }
 
 // ValidationError represents a validation error response
@@ -40,7 +44,7 @@ func ValidateRequest(target interface{}) gin.HandlerFunc {
 			if validatorErrs, ok := err.(validator.ValidationErrors); ok {
 				for _, e := range validatorErrs {
 					validationErrors = append(validationErrors, ValidationError{
-						Field:   e.Field(),
+						Field:   fieldPath(e),
 						Message: getValidationMessage(e),
 					})
 				}
@@ -60,38 +64,6 @@ func ValidateRequest(target interface{}) gin.HandlerFunc {
 	}
 }
 
-// getValidationMessage returns a human-readable validation error message
-func getValidationMessage(err validator.FieldError) string {
-	switch err.Tag() {
-	case "required":
-		return "This field is required"
-	case "email":
-		return "Invalid email format"
-	case "min":
-		return "Value is too short (minimum: " + err.Param() + ")"
-	case "max":
-		return "Value is too long (maximum: " + err.Param() + ")"
-	case "gte":
-		return "Value must be greater than or equal to " + err.Param()
-	case "lte":
-		return "Value must be less than or equal to " + err.Param()
-	case "gt":
-		return "Value must be greater than " + err.Param()
-	case "lt":
-		return "Value must be less than " + err.Param()
-	case "len":
-		return "Length must be " + err.Param()
-	case "oneof":
-		return "Value must be one of: " + err.Param()
-	case "url":
-		return "Invalid URL format"
-	case "uuid":
-		return "Invalid UUID format"
-	default:
-		return "Validation failed: " + err.Tag()
-	}
-}
-
 // ValidateStruct validates a struct and returns validation errors
 // This can be used in handlers for manual validation
 func ValidateStruct(s interface{}) []ValidationError {
@@ -104,7 +76,7 @@ func ValidateStruct(s interface{}) []ValidationError {
 	if validatorErrs, ok := err.(validator.ValidationErrors); ok {
 		for _, e := range validatorErrs {
 			validationErrors = append(validationErrors, ValidationError{
-				Field:   e.Field(),
+				Field:   fieldPath(e),
 				Message: getValidationMessage(e),
 			})
 		}
@@ -112,3 +84,107 @@ func ValidateStruct(s interface{}) []ValidationError {
 
 	return validationErrors
 }
+
+// NewValidator returns a validator that reports fields by their JSON names,
+// so validation details use the same keys the client sent
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// ValidationDetails maps each failed field to a message. Nested fields are keyed by
+// their path below the request, e.g. "items[0].quantity". It returns nil when err
+// is not a validator.ValidationErrors.
+func ValidationDetails(err error) map[string]string {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil
+	}
+
+	details := make(map[string]string, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		details[fieldPath(fieldErr)] = getValidationMessage(fieldErr)
+	}
+	return details
+}
+
+// fieldPath strips the top-level struct name from the error's namespace
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// getValidationMessage describes a failed validation tag in plain words
+func getValidationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "min", "gte":
+		return "must be at least " + param + lengthUnit(fieldErr)
+	case "max", "lte":
+		return "must be at most " + param + lengthUnit(fieldErr)
+	case "gt":
+		return "must be greater than " + param + lengthUnit(fieldErr)
+	case "lt":
+		return "must be less than " + param + lengthUnit(fieldErr)
+	case "len":
+		return "must be exactly " + param + lengthUnit(fieldErr)
+	case "gtfield":
+		return "must be after " + snakeCase(param)
+	case "ltfield":
+		return "must be before " + snakeCase(param)
+	default:
+		return "failed the " + fieldErr.Tag() + " check"
+	}
+}
+
+// lengthUnit names what a size bound counts for strings and collections;
+// numeric bounds need no unit
+func lengthUnit(fieldErr validator.FieldError) string {
+	switch fieldErr.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+// snakeCase converts a Go field name such as "StartTime" to its JSON form "start_time"
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
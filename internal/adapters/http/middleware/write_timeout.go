@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WriteTimeout overrides the server's write timeout for the routes it wraps, so long-lived
+// responses such as streamed chat (server-sent events) are not cut off mid-stream.
+// A timeout of 0 removes the deadline. Writers that cannot change their deadline,
+// such as test recorders, keep the server default.
+func WriteTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var deadline time.Time
+		if timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(deadline)
+
+		c.Next()
+	}
+}
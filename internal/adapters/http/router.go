@@ -3,19 +3,38 @@ package http
 import (
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"fitness-tracker/internal/adapters/http/handlers"
+	"fitness-tracker/internal/adapters/http/middleware"
 	"fitness-tracker/internal/core/ports"
 	"fitness-tracker/internal/config"
+	"fitness-tracker/internal/pkg/auth"
 )
 
-// SetupRouter initializes the HTTP router with all routes and middleware
+// SetupRouter initializes the HTTP router with all routes and middleware.
+// Streaming (SSE) routes must add middleware.WriteTimeout(cfg.Server.StreamWriteTimeout)
+// so the server's shorter write timeout does not end the stream.
 func SetupRouter(
 	authHandler *handlers.AuthHandler,
+	accountHandler *handlers.AccountHandler,
+	profileHandler *handlers.ProfileHandler,
+	foodHandler *handlers.FoodHandler,
+	summaryHandler *handlers.SummaryHandler,
+	workoutHandler *handlers.WorkoutHandler,
+	metricHandler *handlers.MetricHandler,
+	goalHandler *handlers.GoalHandler,
+	insightsHandler *handlers.InsightsHandler,
+	undoHandler *handlers.UndoHandler,
+	coachHandler *handlers.CoachHandler,
+	conversationHandler *handlers.ConversationHandler,
+	llmAuditHandler *handlers.LLMAuditHandler,
+	cacheHandler *handlers.CacheHandler,
+	demoHandler *handlers.DemoHandler,
 	authService ports.AuthService,
+	jwtKeys *auth.KeySet,
+	logger *zap.Logger,
 	cfg *config.Config,
 ) *gin.Engine {
 	// Set Gin mode based on environment
@@ -23,12 +42,15 @@ func SetupRouter(
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
+	router := gin.New()
 
 	// Global middleware
 	router.Use(corsMiddleware(cfg))
 	router.Use(gin.Recovery())
-	router.Use(requestIDMiddleware())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Logger(logger, cfg.Server.SlowRequestThreshold))
+	router.Use(middleware.BodyLimit(cfg.Server.MaxBodyBytes))
+	router.Use(middleware.Compress(cfg.Server.CompressionMinSize))
 
 	// Health check endpoint
 	router.GET("/health", healthCheckHandler)
@@ -38,45 +60,106 @@ func SetupRouter(
 	{
 		// Auth routes (no authentication required)
 		auth := v1.Group("/auth")
+		auth.Use(middleware.BodyLimit(cfg.Server.AuthMaxBodyBytes))
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.GET("/verify", authHandler.VerifyEmail)
+		}
+
+		// Protected routes (JWT required)
+		protected := v1.Group("")
+		protected.Use(middleware.AuthJWT(jwtKeys))
+		{
+			protected.POST("/auth/send-verification", authHandler.SendVerification)
+			protected.GET("/auth/account/export", accountHandler.ExportAccount)
+			protected.DELETE("/auth/account", accountHandler.DeleteAccount)
+
+			protected.GET("/profile", profileHandler.GetProfile)
+			protected.PUT("/profile", profileHandler.UpdateProfile)
+			protected.GET("/profile/nutrition-targets", profileHandler.GetNutritionTargets)
+			protected.PUT("/profile/nutrition-targets", profileHandler.UpdateNutritionTargets)
+			protected.GET("/profile/meal-distribution", profileHandler.GetMealDistribution)
+			protected.PUT("/profile/meal-distribution", profileHandler.UpdateMealDistribution)
+
+			protected.GET("/foods/search", foodHandler.SearchFoods)
+			protected.GET("/foods/recent", foodHandler.GetRecentFoods)
+			protected.GET("/foods/starred", foodHandler.GetStarredFoods)
+			protected.GET("/foods/serving-units", foodHandler.ListServingUnits)
+			protected.POST("/foods/estimate", foodHandler.EstimateFood)
+			protected.GET("/foods/:id", middleware.ETag(), foodHandler.GetFood)
+			protected.POST("/foods/:id/star", foodHandler.StarFood)
+			protected.DELETE("/foods/:id/star", foodHandler.UnstarFood)
+			protected.GET("/foods/:id/history", foodHandler.GetFoodHistory)
+			protected.GET("/foods/:id/alternatives", foodHandler.GetFoodAlternatives)
+
+			protected.GET("/summary/adherence", summaryHandler.GetAdherence)
+			protected.GET("/summary/range", summaryHandler.GetSummaryRange)
+
+			protected.GET("/workouts", workoutHandler.GetWorkouts)
+			protected.POST("/workouts/:id/clone", workoutHandler.CloneWorkout)
+			protected.POST("/workouts/:id/finish", workoutHandler.FinishWorkout)
+			protected.POST("/workouts/:id/pause", workoutHandler.PauseWorkout)
+			protected.POST("/workouts/:id/resume", workoutHandler.ResumeWorkout)
+			protected.PUT("/workouts/:id/exercises/reorder", workoutHandler.ReorderExercises)
+			protected.POST("/workouts/:id/exercises/:exerciseId/sets/bulk", workoutHandler.LogSets)
+			protected.POST("/exercises", workoutHandler.CreateExercise)
+			protected.GET("/exercises/:id", middleware.ETag(), workoutHandler.GetExercise)
+			protected.GET("/exercises/:id/history", workoutHandler.GetExerciseHistory)
+
+			protected.POST("/metrics/batch", middleware.BodyLimit(cfg.Server.BulkMaxBodyBytes), metricHandler.LogMetricBatch)
+			protected.PUT("/metrics/:id", metricHandler.UpdateMetric)
+			protected.DELETE("/metrics/:id", metricHandler.DeleteMetric)
+
+			protected.GET("/goals", goalHandler.GetGoals)
+
+			protected.GET("/insights/streaks", insightsHandler.GetStreaks)
+			protected.GET("/insights/weekly-recap", insightsHandler.GetWeeklyRecap)
+			protected.GET("/insights/activity-calendar", insightsHandler.GetActivityCalendar)
+
+			protected.POST("/actions/undo", undoHandler.UndoLastAction)
+
+			protected.GET("/coach/digest", coachHandler.GetDigest)
+
+			protected.GET("/chat/conversations", conversationHandler.ListConversations)
+			protected.GET("/chat/conversations/:id/messages", conversationHandler.ListMessages)
+
+			// Sample data for demos and QA; never registered unless enabled
+			if cfg.Server.DemoSeedEnabled {
+				protected.POST("/demo/seed", demoHandler.SeedDemoData)
+				protected.DELETE("/demo/seed", demoHandler.ClearDemoData)
+			}
+		}
+
+		// Admin routes (JWT of a configured admin user required)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthJWT(jwtKeys), middleware.RequireAdmin(cfg.Server.AdminUserIDs))
+		{
+			admin.GET("/llm-audit", llmAuditHandler.ListEntries)
+			admin.GET("/cache-stats", cacheHandler.GetStats)
 		}
 
 		// TODO: Add other protected routes here
-		// For now, only auth endpoints are enabled
 	}
 
 	return router
 }
 
-// corsMiddleware configures CORS settings
+// corsMiddleware configures CORS from the cors config section
 func corsMiddleware(cfg *config.Config) gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173", "*"}, // Add your frontend URLs
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	return middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
 	})
 }
 
-// requestIDMiddleware adds a unique request ID to each request
-func requestIDMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.New().String()
-		}
-
-		c.Set("requestID", requestID)
-		c.Writer.Header().Set("X-Request-ID", requestID)
-		c.Next()
-	}
-}
-
 // healthCheckHandler returns the health status of the API
 func healthCheckHandler(c *gin.Context) {
 	c.JSON(200, gin.H{
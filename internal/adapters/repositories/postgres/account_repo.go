@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates a new account repository
+func NewAccountRepository(db *gorm.DB) ports.AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) ExportUserData(ctx context.Context, userID uuid.UUID) (*domain.UserDataExport, error) {
+	db := dbFrom(ctx, r.db)
+	export := &domain.UserDataExport{ExportedAt: time.Now()}
+
+	if err := db.Where("id = ?", userID).First(&export.User).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := db.Preload("FoodItems").Where("user_id = ?", userID).Order("consumed_at ASC").Find(&export.Meals).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Route", orderRoutePoints).Where("user_id = ?", userID).Order("start_time ASC").Find(&export.Activities).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Exercises.Exercise").Preload("Exercises.Sets").Preload("Exercises.PlannedSets").Preload("Pauses").Where("user_id = ?", userID).Order("start_time ASC").Find(&export.Workouts).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("measured_at ASC").Find(&export.Metrics).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("date ASC").Find(&export.DailySummaries).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&export.Goals).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("earned_at ASC").Find(&export.Achievements).Error; err != nil {
+		return nil, err
+	}
+	var target domain.NutritionTarget
+	if err := db.Where("user_id = ?", userID).First(&target).Error; err == nil {
+		export.NutritionTarget = &target
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var distribution domain.MealDistribution
+	if err := db.Where("user_id = ?", userID).First(&distribution).Error; err == nil {
+		export.MealDistribution = &distribution
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := db.Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("user_id = ?", userID).Order("created_at ASC").Find(&export.Conversations).Error; err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// PurgeUser hard-deletes the user and every row they own in a single transaction.
+// Children are deleted before parents so no orphaned rows remain even without FK cascades.
+func (r *accountRepository) PurgeUser(ctx context.Context, userID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Unscoped so soft-deleted rows are purged too
+		conversationIDs := tx.Model(&domain.Conversation{}).Select("id").Where("user_id = ?", userID)
+		workoutIDs := tx.Unscoped().Model(&domain.Workout{}).Select("id").Where("user_id = ?", userID)
+		workoutExerciseIDs := tx.Model(&domain.WorkoutExercise{}).Select("id").Where("workout_id IN (?)", workoutIDs)
+		activityIDs := tx.Unscoped().Model(&domain.Activity{}).Select("id").Where("user_id = ?", userID)
+		mealIDs := tx.Unscoped().Model(&domain.Meal{}).Select("id").Where("user_id = ?", userID)
+
+		steps := []struct {
+			model interface{}
+			query string
+			arg   interface{}
+		}{
+			{&domain.Message{}, "conversation_id IN (?)", conversationIDs},
+			{&domain.Conversation{}, "user_id = ?", userID},
+			{&domain.WorkoutSet{}, "workout_exercise_id IN (?)", workoutExerciseIDs},
+			{&domain.PlannedSet{}, "workout_exercise_id IN (?)", workoutExerciseIDs},
+			{&domain.WorkoutExercise{}, "workout_id IN (?)", workoutIDs},
+			{&domain.WorkoutPause{}, "workout_id IN (?)", workoutIDs},
+			{&domain.Workout{}, "user_id = ?", userID},
+			{&domain.MealFoodItem{}, "meal_id IN (?)", mealIDs},
+			{&domain.Meal{}, "user_id = ?", userID},
+			{&domain.FoodStar{}, "user_id = ?", userID},
+			{&domain.RoutePoint{}, "activity_id IN (?)", activityIDs},
+			{&domain.Activity{}, "user_id = ?", userID},
+			{&domain.Metric{}, "user_id = ?", userID},
+			{&domain.DailySummary{}, "user_id = ?", userID},
+			{&domain.Goal{}, "user_id = ?", userID},
+			{&domain.Achievement{}, "user_id = ?", userID},
+			{&domain.NutritionTarget{}, "user_id = ?", userID},
+			{&domain.MealDistribution{}, "user_id = ?", userID},
+			{&domain.UserToken{}, "user_id = ?", userID},
+			{&domain.UserAction{}, "user_id = ?", userID},
+		}
+
+		for _, step := range steps {
+			if err := tx.Unscoped().Where(step.query, step.arg).Delete(step.model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Food history outlives the user; only the editor reference is cleared
+		if err := tx.Model(&domain.FoodRevision{}).Where("edited_by = ?", userID).Update("edited_by", nil).Error; err != nil {
+			return err
+		}
+
+		// LLM audit entries are kept for billing, without the user or what they said
+		if err := tx.Model(&domain.LLMAuditEntry{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"user_id":    nil,
+			"request":    gorm.Expr("'{}'::jsonb"),
+			"response":   nil,
+			"tools_used": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", userID).Delete(&domain.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		return nil
+	})
+}
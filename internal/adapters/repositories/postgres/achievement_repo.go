@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type achievementRepository struct {
+	db *gorm.DB
+}
+
+// NewAchievementRepository creates a new achievement repository
+func NewAchievementRepository(db *gorm.DB) ports.AchievementRepository {
+	return &achievementRepository{db: db}
+}
+
+// CreateIfMissing records the achievement unless the user already earned that code
+func (r *achievementRepository) CreateIfMissing(ctx context.Context, achievement *domain.Achievement) error {
+	return dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "code"}},
+			DoNothing: true,
+		}).
+		Create(achievement).Error
+}
+
+// ListByUser returns the user's achievements in the order they were earned
+func (r *achievementRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Achievement, error) {
+	var achievements []*domain.Achievement
+	err := dbFrom(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("earned_at ASC").
+		Find(&achievements).Error
+	if err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
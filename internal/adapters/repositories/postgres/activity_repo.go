@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,30 +21,41 @@ func NewActivityRepository(db *gorm.DB) ports.ActivityRepository {
 	return &activityRepository{db: db}
 }
 
+// Create inserts the activity with its route. Route points go in batches to stay under
+// Postgres' limit on parameters per statement.
 func (r *activityRepository) Create(ctx context.Context, activity *domain.Activity) error {
-	return r.db.WithContext(ctx).Create(activity).Error
+	return dbFrom(ctx, r.db).Session(&gorm.Session{CreateBatchSize: routePointBatchSize}).Create(activity).Error
 }
 
 func (r *activityRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Activity, error) {
 	var activity domain.Activity
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&activity).Error
+	err := dbFrom(ctx, r.db).Preload("Route", orderRoutePoints).Where("id = ?", id).First(&activity).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &activity, nil
 }
 
+// Update saves the activity's own fields; its route is written once, on Create
 func (r *activityRepository) Update(ctx context.Context, activity *domain.Activity) error {
-	return r.db.WithContext(ctx).Save(activity).Error
+	return dbFrom(ctx, r.db).Omit("Route").Save(activity).Error
 }
 
+// Delete soft-deletes the activity so the delete can be undone with Restore
 func (r *activityRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Activity{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Activity{}, "id = ?", id).Error
+}
+
+func (r *activityRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return restore(dbFrom(ctx, r.db), &domain.Activity{}, id)
 }
 
 func (r *activityRepository) ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, limit, offset int) ([]*domain.Activity, error) {
 	var activities []*domain.Activity
-	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	query := dbFrom(ctx, r.db).Where("user_id = ?", userID)
 
 	if !startDate.IsZero() && !endDate.IsZero() {
 		query = query.Where("start_time BETWEEN ? AND ?", startDate, endDate)
@@ -61,6 +73,23 @@ func (r *activityRepository) ListByUser(ctx context.Context, userID uuid.UUID, s
 	return activities, nil
 }
 
+func (r *activityRepository) ListByUserAfter(ctx context.Context, userID uuid.UUID, after *domain.PageCursor, limit int) ([]*domain.Activity, error) {
+	var activities []*domain.Activity
+	query := dbFrom(ctx, r.db).Where("user_id = ?", userID)
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&activities).Error
+	if err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
+
 func (r *activityRepository) GetTotalsByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) (map[string]interface{}, error) {
 	type Result struct {
 		TotalCaloriesBurned float64
@@ -70,7 +99,7 @@ func (r *activityRepository) GetTotalsByUser(ctx context.Context, userID uuid.UU
 	}
 
 	var result Result
-	query := r.db.WithContext(ctx).
+	query := dbFrom(ctx, r.db).
 		Model(&domain.Activity{}).
 		Select(`
 			COALESCE(SUM(calories_burned), 0) as total_calories_burned,
@@ -96,3 +125,11 @@ func (r *activityRepository) GetTotalsByUser(ctx context.Context, userID uuid.UU
 		"total_steps":           result.TotalSteps,
 	}, nil
 }
+
+// routePointBatchSize is how many route points one INSERT writes
+const routePointBatchSize = 1000
+
+// orderRoutePoints preloads an activity's route in the order it was recorded
+func orderRoutePoints(db *gorm.DB) *gorm.DB {
+	return db.Order("sequence")
+}
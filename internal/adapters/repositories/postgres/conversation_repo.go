@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -20,39 +22,43 @@ func NewConversationRepository(db *gorm.DB) ports.ConversationRepository {
 }
 
 func (r *conversationRepository) Create(ctx context.Context, conversation *domain.Conversation) error {
-	return r.db.WithContext(ctx).Create(conversation).Error
+	return dbFrom(ctx, r.db).Create(conversation).Error
 }
 
 func (r *conversationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Conversation, error) {
 	var conversation domain.Conversation
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Messages", func(db *gorm.DB) *gorm.DB {
 			return db.Order("created_at ASC")
 		}).
 		Where("id = ?", id).
 		First(&conversation).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &conversation, nil
 }
 
 func (r *conversationRepository) Update(ctx context.Context, conversation *domain.Conversation) error {
-	return r.db.WithContext(ctx).Save(conversation).Error
+	return dbFrom(ctx, r.db).Save(conversation).Error
 }
 
 func (r *conversationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Cascade delete is handled by the database constraint
-	return r.db.WithContext(ctx).Delete(&domain.Conversation{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Conversation{}, "id = ?", id).Error
 }
 
+// ListByUser orders by updated_at, which AddMessage moves to each new message
 func (r *conversationRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Conversation, error) {
 	var conversations []*domain.Conversation
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Where("user_id = ?", userID).
 		Limit(limit).
 		Offset(offset).
-		Order("updated_at DESC").
+		Order("updated_at DESC, id").
 		Find(&conversations).Error
 	if err != nil {
 		return nil, err
@@ -60,15 +66,66 @@ func (r *conversationRepository) ListByUser(ctx context.Context, userID uuid.UUI
 	return conversations, nil
 }
 
+func (r *conversationRepository) GetActivity(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]domain.ConversationActivity, error) {
+	activity := make(map[uuid.UUID]domain.ConversationActivity, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return activity, nil
+	}
+
+	var rows []struct {
+		domain.Message
+		MessageCount int
+	}
+	// The window count is taken before DISTINCT ON keeps each conversation's latest message
+	err := dbFrom(ctx, r.db).
+		Table("messages").
+		Select("DISTINCT ON (conversation_id) *, COUNT(*) OVER (PARTITION BY conversation_id) AS message_count").
+		Where("conversation_id IN ?", conversationIDs).
+		Order("conversation_id, created_at DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		activity[rows[i].ConversationID] = domain.ConversationActivity{
+			MessageCount: rows[i].MessageCount,
+			LastMessage:  &rows[i].Message,
+		}
+	}
+	return activity, nil
+}
+
+// SaveSummary replaces the conversation's summary. updated_at is left alone: summarizing
+// is not activity, and conversations are listed by their latest message.
+func (r *conversationRepository) SaveSummary(ctx context.Context, conversationID uuid.UUID, summary string, through time.Time) error {
+	return dbFrom(ctx, r.db).
+		Model(&domain.Conversation{}).
+		Where("id = ?", conversationID).
+		UpdateColumns(map[string]interface{}{
+			"summary":            summary,
+			"summarized_through": through,
+		}).Error
+}
+
 // Message operations
 
+// AddMessage saves the message and moves the conversation's updated_at to it, so
+// conversations list in order of their latest activity
 func (r *conversationRepository) AddMessage(ctx context.Context, message *domain.Message) error {
-	return r.db.WithContext(ctx).Create(message).Error
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Conversation{}).
+			Where("id = ? AND updated_at < ?", message.ConversationID, message.CreatedAt).
+			UpdateColumn("updated_at", message.CreatedAt).Error
+	})
 }
 
 func (r *conversationRepository) GetMessages(ctx context.Context, conversationID uuid.UUID, limit, offset int) ([]*domain.Message, error) {
 	var messages []*domain.Message
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Where("conversation_id = ?", conversationID).
 		Limit(limit).
 		Offset(offset).
@@ -80,10 +137,41 @@ func (r *conversationRepository) GetMessages(ctx context.Context, conversationID
 	return messages, nil
 }
 
+// GetLatestMessages returns the newest messages of a conversation, oldest first
 func (r *conversationRepository) GetLatestMessages(ctx context.Context, conversationID uuid.UUID, limit int) ([]*domain.Message, error) {
+	return r.latestMessages(dbFrom(ctx, r.db).Where("conversation_id = ?", conversationID), limit)
+}
+
+// GetMessagesBefore returns the newest messages created before the cursor, oldest first.
+// Passing the CreatedAt of the first message of a page loads the page before it.
+func (r *conversationRepository) GetMessagesBefore(ctx context.Context, conversationID uuid.UUID, before time.Time, limit int) ([]*domain.Message, error) {
+	return r.latestMessages(dbFrom(ctx, r.db).Where("conversation_id = ? AND created_at < ?", conversationID, before), limit)
+}
+
+// GetMessagesAfter pages through a conversation in the order it was written. Messages
+// sharing a timestamp are ordered by ID, so none is skipped at a page boundary.
+func (r *conversationRepository) GetMessagesAfter(ctx context.Context, conversationID uuid.UUID, after *domain.PageCursor, limit int) ([]*domain.Message, error) {
 	var messages []*domain.Message
-	err := r.db.WithContext(ctx).
-		Where("conversation_id = ?", conversationID).
+	query := dbFrom(ctx, r.db).Where("conversation_id = ?", conversationID)
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	err := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// latestMessages loads the newest messages matching query and returns them in
+// chronological order, the order the model expects its history in
+func (r *conversationRepository) latestMessages(query *gorm.DB, limit int) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	err := query.
 		Limit(limit).
 		Order("created_at DESC").
 		Find(&messages).Error
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type demoRepository struct {
+	db *gorm.DB
+}
+
+// NewDemoRepository creates a new demo repository
+func NewDemoRepository(db *gorm.DB) ports.DemoRepository {
+	return &demoRepository{db: db}
+}
+
+func (r *demoRepository) HasDemoData(ctx context.Context, userID uuid.UUID) (bool, error) {
+	db := dbFrom(ctx, r.db).Unscoped()
+	for _, model := range []interface{}{&domain.Meal{}, &domain.Activity{}, &domain.Workout{}, &domain.Metric{}} {
+		var count int64
+		if err := db.Model(model).Where("user_id = ? AND is_demo", userID).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *demoRepository) CreateDemoData(ctx context.Context, data *domain.DemoData) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if len(data.Meals) > 0 {
+			if err := tx.Create(&data.Meals).Error; err != nil {
+				return err
+			}
+		}
+		if len(data.Activities) > 0 {
+			if err := tx.Create(&data.Activities).Error; err != nil {
+				return err
+			}
+		}
+		if len(data.Workouts) > 0 {
+			if err := tx.Create(&data.Workouts).Error; err != nil {
+				return err
+			}
+		}
+		if len(data.Metrics) > 0 {
+			if err := tx.Create(&data.Metrics).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteDemoData deletes children before parents, like PurgeUser, so foods or sets the user
+// logged under a sample meal or workout go with it
+func (r *demoRepository) DeleteDemoData(ctx context.Context, userID uuid.UUID) (*domain.DemoData, error) {
+	deleted := &domain.DemoData{}
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Unscoped so soft-deleted sample rows are removed too
+		workoutIDs := tx.Unscoped().Model(&domain.Workout{}).Select("id").Where("user_id = ? AND is_demo", userID)
+		workoutExerciseIDs := tx.Model(&domain.WorkoutExercise{}).Select("id").Where("workout_id IN (?)", workoutIDs)
+		mealIDs := tx.Unscoped().Model(&domain.Meal{}).Select("id").Where("user_id = ? AND is_demo", userID)
+
+		children := []struct {
+			model interface{}
+			query string
+			arg   interface{}
+		}{
+			{&domain.WorkoutSet{}, "workout_exercise_id IN (?)", workoutExerciseIDs},
+			{&domain.WorkoutExercise{}, "workout_id IN (?)", workoutIDs},
+			{&domain.MealFoodItem{}, "meal_id IN (?)", mealIDs},
+		}
+		for _, child := range children {
+			if err := tx.Unscoped().Where(child.query, child.arg).Delete(child.model).Error; err != nil {
+				return err
+			}
+		}
+
+		// RETURNING fills each slice with the rows deleted
+		for _, records := range []interface{}{&deleted.Workouts, &deleted.Meals, &deleted.Activities, &deleted.Metrics} {
+			if err := tx.Unscoped().Clauses(clause.Returning{}).Where("user_id = ? AND is_demo", userID).Delete(records).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
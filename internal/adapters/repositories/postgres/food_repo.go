@@ -2,9 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
@@ -20,17 +24,20 @@ func NewFoodRepository(db *gorm.DB) ports.FoodRepository {
 }
 
 func (r *foodRepository) Create(ctx context.Context, food *domain.Food) error {
-	return r.db.WithContext(ctx).Create(food).Error
+	return dbFrom(ctx, r.db).Create(food).Error
 }
 
 func (r *foodRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Food, error) {
 	var food domain.Food
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Ingredients.Ingredient").
 		Preload("ServingConversions.ServingUnit").
 		Where("id = ?", id).
 		First(&food).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &food, nil
@@ -38,28 +45,31 @@ func (r *foodRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Foo
 
 func (r *foodRepository) GetByFdcID(ctx context.Context, fdcID int) (*domain.Food, error) {
 	var food domain.Food
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Ingredients.Ingredient").
 		Preload("ServingConversions.ServingUnit").
 		Where("fdc_id = ?", fdcID).
 		First(&food).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &food, nil
 }
 
 func (r *foodRepository) Update(ctx context.Context, food *domain.Food) error {
-	return r.db.WithContext(ctx).Save(food).Error
+	return dbFrom(ctx, r.db).Save(food).Error
 }
 
 func (r *foodRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Food{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Food{}, "id = ?", id).Error
 }
 
 func (r *foodRepository) List(ctx context.Context, limit, offset int) ([]*domain.Food, error) {
 	var foods []*domain.Food
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Limit(limit).
 		Offset(offset).
 		Order("name ASC").
@@ -70,33 +80,113 @@ func (r *foodRepository) List(ctx context.Context, limit, offset int) ([]*domain
 	return foods, nil
 }
 
-func (r *foodRepository) Search(ctx context.Context, query string, limit, offset int) ([]*domain.Food, error) {
+// Search weights per query word; a word counts once, in the best field it appears in
+const (
+	foodSearchNameWeight        = 3
+	foodSearchBrandWeight       = 2
+	foodSearchDescriptionWeight = 1
+)
+
+func (r *foodRepository) Search(ctx context.Context, query string, filter domain.FoodSearchFilter, limit, offset int) ([]*domain.Food, error) {
 	var foods []*domain.Food
 
-	// Use PostgreSQL full-text search with to_tsquery
-	err := r.db.WithContext(ctx).
-		Where("to_tsvector('english', name) @@ plainto_tsquery('english', ?)", query).
-		Or("name ILIKE ?", "%"+query+"%").
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return foods, nil
+	}
+
+	// A food matches when every word appears in its name, brand or description (served by
+	// the trigram indexes), or when full-text search on the name matches, which catches
+	// plurals and other stemmed forms
+	allWords := r.db
+	scores := make([]string, 0, len(words))
+	args := make([]interface{}, 0, 3*len(words)+1)
+	for _, word := range words {
+		pattern := "%" + escapeLike(word) + "%"
+		allWords = allWords.Where("(name ILIKE ? OR brand ILIKE ? OR description ILIKE ?)", pattern, pattern, pattern)
+		scores = append(scores, fmt.Sprintf("CASE WHEN name ILIKE ? THEN %d WHEN brand ILIKE ? THEN %d WHEN description ILIKE ? THEN %d ELSE 0 END",
+			foodSearchNameWeight, foodSearchBrandWeight, foodSearchDescriptionWeight))
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	// Among equal scores, names that start with the query come first. A sort other than
+	// relevance orders by its own measure first and falls back to relevance for ties.
+	var sortBy string
+	switch filter.Sort {
+	case domain.FoodSortProteinDensity:
+		sortBy = "protein / NULLIF(calories, 0) DESC NULLS LAST, "
+	case domain.FoodSortCalories:
+		sortBy = "calories ASC, "
+	}
+	args = append(args, escapeLike(query)+"%")
+	rank := clause.Expr{
+		SQL:  sortBy + "(" + strings.Join(scores, " + ") + ") DESC, name ILIKE ? DESC, is_verified DESC, name ASC",
+		Vars: args,
+	}
+
+	db := dbFrom(ctx, r.db).
+		Where(r.db.Where("to_tsvector('english', name) @@ plainto_tsquery('english', ?)", query).Or(allWords))
+	if filter.VerifiedOnly {
+		db = db.Where("is_verified = ?", true)
+	}
+
+	err := db.
+		Clauses(clause.OrderBy{Expression: rank}).
 		Limit(limit).
 		Offset(offset).
+		Find(&foods).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
+
+func (r *foodRepository) ListByCategory(ctx context.Context, category string, limit int) ([]*domain.Food, error) {
+	var foods []*domain.Food
+	err := dbFrom(ctx, r.db).
+		Where("LOWER(category) = LOWER(?) AND deleted_at IS NULL", category).
 		Order("is_verified DESC, name ASC").
+		Limit(limit).
 		Find(&foods).Error
+	if err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
 
+// ListVerified orders foods nobody has logged by a hash of their ID, which is stable
+// between calls but spread across the alphabet, so the limit does not keep only the foods
+// whose names come first
+func (r *foodRepository) ListVerified(ctx context.Context, limit int) ([]*domain.Food, error) {
+	var foods []*domain.Food
+	err := dbFrom(ctx, r.db).
+		Select("foods.*").
+		Joins("LEFT JOIN (SELECT food_id, COUNT(*) AS logged FROM meal_food_items GROUP BY food_id) usage ON usage.food_id = foods.id").
+		Where("foods.is_verified AND foods.deleted_at IS NULL AND foods.calories > 0 AND foods.serving_size > 0").
+		Order("COALESCE(usage.logged, 0) DESC, md5(foods.id::text)").
+		Limit(limit).
+		Find(&foods).Error
 	if err != nil {
 		return nil, err
 	}
 	return foods, nil
 }
 
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // Ingredient operations
 
 func (r *foodRepository) AddIngredient(ctx context.Context, ingredient *domain.FoodIngredient) error {
-	return r.db.WithContext(ctx).Create(ingredient).Error
+	return dbFrom(ctx, r.db).Create(ingredient).Error
 }
 
 func (r *foodRepository) GetIngredients(ctx context.Context, foodID uuid.UUID) ([]*domain.FoodIngredient, error) {
 	var ingredients []*domain.FoodIngredient
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Ingredient").
 		Where("food_id = ?", foodID).
 		Find(&ingredients).Error
@@ -109,12 +199,12 @@ func (r *foodRepository) GetIngredients(ctx context.Context, foodID uuid.UUID) (
 // Serving conversion operations
 
 func (r *foodRepository) AddServingConversion(ctx context.Context, conversion *domain.FoodServingConversion) error {
-	return r.db.WithContext(ctx).Create(conversion).Error
+	return dbFrom(ctx, r.db).Create(conversion).Error
 }
 
 func (r *foodRepository) GetServingConversions(ctx context.Context, foodID uuid.UUID) ([]*domain.FoodServingConversion, error) {
 	var conversions []*domain.FoodServingConversion
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("ServingUnit").
 		Where("food_id = ?", foodID).
 		Find(&conversions).Error
@@ -127,13 +217,16 @@ func (r *foodRepository) GetServingConversions(ctx context.Context, foodID uuid.
 // Serving unit operations
 
 func (r *foodRepository) CreateServingUnit(ctx context.Context, unit *domain.ServingUnit) error {
-	return r.db.WithContext(ctx).Create(unit).Error
+	return dbFrom(ctx, r.db).Create(unit).Error
 }
 
 func (r *foodRepository) GetServingUnit(ctx context.Context, id uuid.UUID) (*domain.ServingUnit, error) {
 	var unit domain.ServingUnit
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&unit).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&unit).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &unit, nil
@@ -141,7 +234,7 @@ func (r *foodRepository) GetServingUnit(ctx context.Context, id uuid.UUID) (*dom
 
 func (r *foodRepository) ListServingUnits(ctx context.Context) ([]*domain.ServingUnit, error) {
 	var units []*domain.ServingUnit
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Order("category ASC, name ASC").
 		Find(&units).Error
 	if err != nil {
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type foodRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewFoodRevisionRepository creates a new food revision repository
+func NewFoodRevisionRepository(db *gorm.DB) ports.FoodRevisionRepository {
+	return &foodRevisionRepository{db: db}
+}
+
+func (r *foodRevisionRepository) Create(ctx context.Context, revision *domain.FoodRevision) error {
+	return dbFrom(ctx, r.db).Create(revision).Error
+}
+
+// ListByFood returns the food's revisions, newest first
+func (r *foodRevisionRepository) ListByFood(ctx context.Context, foodID uuid.UUID, limit, offset int) ([]*domain.FoodRevision, error) {
+	var revisions []*domain.FoodRevision
+	err := dbFrom(ctx, r.db).
+		Where("food_id = ?", foodID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type foodStarRepository struct {
+	db *gorm.DB
+}
+
+// NewFoodStarRepository creates a new food star repository
+func NewFoodStarRepository(db *gorm.DB) ports.FoodStarRepository {
+	return &foodStarRepository{db: db}
+}
+
+// Star records the star unless the user already starred the food
+func (r *foodStarRepository) Star(ctx context.Context, star *domain.FoodStar) error {
+	return dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "food_id"}},
+			DoNothing: true,
+		}).
+		Create(star).Error
+}
+
+func (r *foodStarRepository) Unstar(ctx context.Context, userID, foodID uuid.UUID) error {
+	return dbFrom(ctx, r.db).
+		Where("user_id = ? AND food_id = ?", userID, foodID).
+		Delete(&domain.FoodStar{}).Error
+}
+
+// ListFoods returns the user's starred foods, most recently starred first
+func (r *foodStarRepository) ListFoods(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Food, error) {
+	var foods []*domain.Food
+	err := dbFrom(ctx, r.db).
+		Joins("JOIN food_stars ON food_stars.food_id = foods.id").
+		Where("food_stars.user_id = ?", userID).
+		Order("food_stars.created_at DESC, foods.name ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&foods).Error
+	if err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
+
+// StarredIDs returns the subset of foodIDs the user starred
+func (r *foodStarRepository) StarredIDs(ctx context.Context, userID uuid.UUID, foodIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	starred := make(map[uuid.UUID]bool)
+	if len(foodIDs) == 0 {
+		return starred, nil
+	}
+
+	var ids []uuid.UUID
+	err := dbFrom(ctx, r.db).
+		Model(&domain.FoodStar{}).
+		Where("user_id = ? AND food_id IN ?", userID, foodIDs).
+		Pluck("food_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		starred[id] = true
+	}
+	return starred, nil
+}
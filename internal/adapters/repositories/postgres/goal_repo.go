@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -20,29 +21,32 @@ func NewGoalRepository(db *gorm.DB) ports.GoalRepository {
 }
 
 func (r *goalRepository) Create(ctx context.Context, goal *domain.Goal) error {
-	return r.db.WithContext(ctx).Create(goal).Error
+	return dbFrom(ctx, r.db).Create(goal).Error
 }
 
 func (r *goalRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Goal, error) {
 	var goal domain.Goal
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&goal).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&goal).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &goal, nil
 }
 
 func (r *goalRepository) Update(ctx context.Context, goal *domain.Goal) error {
-	return r.db.WithContext(ctx).Save(goal).Error
+	return dbFrom(ctx, r.db).Save(goal).Error
 }
 
 func (r *goalRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Goal{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Goal{}, "id = ?", id).Error
 }
 
 func (r *goalRepository) ListByUser(ctx context.Context, userID uuid.UUID, status string, limit, offset int) ([]*domain.Goal, error) {
 	var goals []*domain.Goal
-	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	query := dbFrom(ctx, r.db).Where("user_id = ?", userID)
 
 	if status != "" {
 		query = query.Where("status = ?", status)
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// jobLockNamespace keeps job advisory lock keys apart from other users of advisory locks
+const jobLockNamespace = "fitness-tracker/job/"
+
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRunRepository creates a new job run repository
+func NewJobRunRepository(db *gorm.DB) ports.JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+// TryLock takes a session-level advisory lock on a connection of its own, so the lock
+// lasts until release returns the connection to the pool. A crashed instance's lock
+// goes away with its connection.
+func (r *jobRunRepository) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := jobLockKey(name)
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release := func() {
+		// Unlock even when the run's context was cancelled
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+func (r *jobRunRepository) GetLastRun(ctx context.Context, name string) (*domain.JobRun, error) {
+	var run domain.JobRun
+	err := dbFrom(ctx, r.db).Where("name = ?", name).First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *jobRunRepository) SaveRun(ctx context.Context, run *domain.JobRun) error {
+	run.UpdatedAt = time.Now()
+	return dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"started_at", "finished_at", "error", "updated_at"}),
+		}).
+		Create(run).Error
+}
+
+// jobLockKey hashes a job name to an advisory lock key
+func jobLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(jobLockNamespace + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type llmAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewLLMAuditRepository creates a new LLM audit repository
+func NewLLMAuditRepository(db *gorm.DB) ports.LLMAuditRepository {
+	return &llmAuditRepository{db: db}
+}
+
+func (r *llmAuditRepository) Create(ctx context.Context, entry *domain.LLMAuditEntry) error {
+	return dbFrom(ctx, r.db).Create(entry).Error
+}
+
+// List returns matching entries, newest first
+func (r *llmAuditRepository) List(ctx context.Context, filter domain.LLMAuditFilter, limit, offset int) ([]*domain.LLMAuditEntry, error) {
+	var entries []*domain.LLMAuditEntry
+	query := dbFrom(ctx, r.db)
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Operation != "" {
+		query = query.Where("operation = ?", filter.Operation)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
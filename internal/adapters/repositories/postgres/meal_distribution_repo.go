@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type mealDistributionRepository struct {
+	db *gorm.DB
+}
+
+// NewMealDistributionRepository creates a new meal distribution repository
+func NewMealDistributionRepository(db *gorm.DB) ports.MealDistributionRepository {
+	return &mealDistributionRepository{db: db}
+}
+
+func (r *mealDistributionRepository) Get(ctx context.Context, userID uuid.UUID) (*domain.MealDistribution, error) {
+	var distribution domain.MealDistribution
+	err := dbFrom(ctx, r.db).Where("user_id = ?", userID).First(&distribution).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &distribution, nil
+}
+
+func (r *mealDistributionRepository) Upsert(ctx context.Context, distribution *domain.MealDistribution) error {
+	distribution.UpdatedAt = time.Now()
+	return dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"breakfast",
+				"lunch",
+				"dinner",
+				"snack",
+				"source",
+				"updated_at",
+			}),
+		}).
+		Create(distribution).Error
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,32 +22,63 @@ func NewMealRepository(db *gorm.DB) ports.MealRepository {
 }
 
 func (r *mealRepository) Create(ctx context.Context, meal *domain.Meal) error {
-	return r.db.WithContext(ctx).Create(meal).Error
+	db := dbFrom(ctx, r.db)
+	if len(meal.FoodItems) > 0 {
+		for i := range meal.FoodItems {
+			if err := snapshotFoodItem(db, &meal.FoodItems[i]); err != nil {
+				return err
+			}
+		}
+		meal.RecalculateTotals()
+	}
+	return db.Create(meal).Error
 }
 
 func (r *mealRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Meal, error) {
 	var meal domain.Meal
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("FoodItems.Food").
 		Where("id = ?", id).
 		First(&meal).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &meal, nil
 }
 
 func (r *mealRepository) Update(ctx context.Context, meal *domain.Meal) error {
-	return r.db.WithContext(ctx).Save(meal).Error
+	return dbFrom(ctx, r.db).Save(meal).Error
+}
+
+func (r *mealRepository) UpdateTotals(ctx context.Context, meal *domain.Meal) error {
+	return dbFrom(ctx, r.db).
+		Model(&domain.Meal{}).
+		Where("id = ?", meal.ID).
+		Updates(map[string]interface{}{
+			"total_calories":      meal.TotalCalories,
+			"total_protein":       meal.TotalProtein,
+			"total_carbohydrates": meal.TotalCarbohydrates,
+			"total_fat":           meal.TotalFat,
+			"total_saturated_fat": meal.TotalSaturatedFat,
+			"total_sodium":        meal.TotalSodium,
+		}).Error
 }
 
+// Delete soft-deletes the meal so the delete can be undone with Restore
 func (r *mealRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Meal{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Meal{}, "id = ?", id).Error
+}
+
+func (r *mealRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return restore(dbFrom(ctx, r.db), &domain.Meal{}, id)
 }
 
 func (r *mealRepository) ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, limit, offset int) ([]*domain.Meal, error) {
 	var meals []*domain.Meal
-	query := r.db.WithContext(ctx).
+	query := dbFrom(ctx, r.db).
 		Preload("FoodItems.Food").
 		Where("user_id = ?", userID)
 
@@ -66,23 +98,188 @@ func (r *mealRepository) ListByUser(ctx context.Context, userID uuid.UUID, start
 	return meals, nil
 }
 
+func (r *mealRepository) ListByUserAfter(ctx context.Context, userID uuid.UUID, after *domain.PageCursor, limit int) ([]*domain.Meal, error) {
+	var meals []*domain.Meal
+	query := dbFrom(ctx, r.db).
+		Preload("FoodItems.Food").
+		Where("user_id = ?", userID)
+
+	// The ID breaks ties between meals logged in the same microsecond
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&meals).Error
+	if err != nil {
+		return nil, err
+	}
+	return meals, nil
+}
+
+// recentFoodRow is the aggregate row for ListRecentFoods
+type recentFoodRow struct {
+	FoodID          uuid.UUID
+	TimesLogged     int
+	LastLoggedAt    time.Time
+	TypicalQuantity float64
+	TypicalUnit     string
+}
+
+// ListDeletedMealPhotoPaths returns which of the given photo paths belong only to meals the
+// user deleted. Paths no meal records, or that a live meal still uses, are left out.
+func (r *mealRepository) ListDeletedMealPhotoPaths(ctx context.Context, userID uuid.UUID, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{}, nil
+	}
+
+	var deleted []string
+	err := dbFrom(ctx, r.db).
+		Unscoped().
+		Model(&domain.Meal{}).
+		Where("user_id = ? AND photo_path IN ?", userID, paths).
+		Group("photo_path").
+		Having("bool_and(deleted_at IS NOT NULL)").
+		Pluck("photo_path", &deleted).Error
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ListRecentFoods groups the user's meal food items by food, most recently logged first.
+// Ties are broken by how often the food was logged. The typical quantity and unit are
+// the pair logged most often, so a quantity is never reported against another unit.
+func (r *mealRepository) ListRecentFoods(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*domain.RecentFood, error) {
+	var rows []recentFoodRow
+	err := dbFrom(ctx, r.db).
+		Table("meal_food_items AS mfi").
+		Select(`mfi.food_id,
+			COUNT(*) AS times_logged,
+			MAX(m.consumed_at) AS last_logged_at,
+			typical.quantity AS typical_quantity,
+			typical.unit AS typical_unit`).
+		Joins("JOIN meals m ON m.id = mfi.meal_id").
+		Joins(`JOIN (
+			SELECT DISTINCT ON (pmfi.food_id) pmfi.food_id, pmfi.quantity, pmfi.unit
+			FROM meal_food_items pmfi
+			JOIN meals pm ON pm.id = pmfi.meal_id
+			WHERE pm.user_id = ? AND pm.deleted_at IS NULL AND pm.consumed_at >= ?
+			GROUP BY pmfi.food_id, pmfi.quantity, pmfi.unit
+			ORDER BY pmfi.food_id, COUNT(*) DESC, MAX(pm.consumed_at) DESC
+		) typical ON typical.food_id = mfi.food_id`, userID, since).
+		Where("m.user_id = ? AND m.deleted_at IS NULL AND m.consumed_at >= ?", userID, since).
+		Group("mfi.food_id, typical.quantity, typical.unit").
+		Order("last_logged_at DESC, times_logged DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return []*domain.RecentFood{}, nil
+	}
+
+	foodIDs := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		foodIDs[i] = row.FoodID
+	}
+
+	var foods []domain.Food
+	if err := dbFrom(ctx, r.db).Where("id IN ? AND deleted_at IS NULL", foodIDs).Find(&foods).Error; err != nil {
+		return nil, err
+	}
+
+	foodsByID := make(map[uuid.UUID]domain.Food, len(foods))
+	for _, food := range foods {
+		foodsByID[food.ID] = food
+	}
+
+	recent := make([]*domain.RecentFood, 0, len(rows))
+	for _, row := range rows {
+		food, ok := foodsByID[row.FoodID]
+		if !ok {
+			continue // food was deleted from the catalog
+		}
+		recent = append(recent, &domain.RecentFood{
+			Food:            food,
+			TimesLogged:     row.TimesLogged,
+			LastLoggedAt:    row.LastLoggedAt,
+			TypicalQuantity: row.TypicalQuantity,
+			TypicalUnit:     row.TypicalUnit,
+		})
+	}
+
+	return recent, nil
+}
+
+// SumNutritionByDay totals meal nutrition per calendar day in the given timezone.
+// Only days with at least one meal in [start, end) are returned, oldest first.
+func (r *mealRepository) SumNutritionByDay(ctx context.Context, userID uuid.UUID, start, end time.Time, timezone string) ([]*domain.DailyNutrition, error) {
+	var days []*domain.DailyNutrition
+	err := dbFrom(ctx, r.db).
+		Model(&domain.Meal{}).
+		Select(`TO_CHAR((consumed_at AT TIME ZONE ?)::date, 'YYYY-MM-DD') AS date,
+			COALESCE(SUM(total_calories), 0) AS calories,
+			COALESCE(SUM(total_protein), 0) AS protein,
+			COALESCE(SUM(total_carbohydrates), 0) AS carbohydrates,
+			COALESCE(SUM(total_fat), 0) AS fat,
+			COUNT(*) AS meals_logged`, timezone).
+		Where("user_id = ? AND deleted_at IS NULL AND consumed_at >= ? AND consumed_at < ?", userID, start, end).
+		Group("1").
+		Order("1").
+		Scan(&days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// ListLoggedDays returns the distinct calendar days, in the given timezone, with at least one meal, oldest first
+func (r *mealRepository) ListLoggedDays(ctx context.Context, userID uuid.UUID, timezone string) ([]string, error) {
+	var days []string
+	err := dbFrom(ctx, r.db).
+		Model(&domain.Meal{}).
+		Select("DISTINCT TO_CHAR((consumed_at AT TIME ZONE ?)::date, 'YYYY-MM-DD') AS day", timezone).
+		Where("user_id = ? AND deleted_at IS NULL", userID).
+		Order("day").
+		Pluck("day", &days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
 // Food item operations
 
 func (r *mealRepository) AddFoodItem(ctx context.Context, item *domain.MealFoodItem) error {
-	return r.db.WithContext(ctx).Create(item).Error
+	db := dbFrom(ctx, r.db)
+	if err := snapshotFoodItem(db, item); err != nil {
+		return err
+	}
+	return db.Create(item).Error
 }
 
+// UpdateFoodItem recalculates the item's nutrition from its snapshot, so a new
+// quantity is priced as the food was when logged rather than as it is now
 func (r *mealRepository) UpdateFoodItem(ctx context.Context, item *domain.MealFoodItem) error {
-	return r.db.WithContext(ctx).Save(item).Error
+	db := dbFrom(ctx, r.db)
+	if err := snapshotFoodItem(db, item); err != nil {
+		return err
+	}
+	return db.Save(item).Error
 }
 
 func (r *mealRepository) RemoveFoodItem(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.MealFoodItem{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.MealFoodItem{}, "id = ?", id).Error
 }
 
 func (r *mealRepository) GetFoodItems(ctx context.Context, mealID uuid.UUID) ([]*domain.MealFoodItem, error) {
 	var items []*domain.MealFoodItem
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Food").
 		Where("meal_id = ?", mealID).
 		Find(&items).Error
@@ -91,3 +288,19 @@ func (r *mealRepository) GetFoodItems(ctx context.Context, mealID uuid.UUID) ([]
 	}
 	return items, nil
 }
+
+// snapshotFoodItem copies the item's food onto it if it has no snapshot yet and
+// recalculates its nutrition from the snapshot
+func snapshotFoodItem(db *gorm.DB, item *domain.MealFoodItem) error {
+	if !item.Snapshot.IsZero() {
+		item.RecalculateNutrition()
+		return nil
+	}
+
+	var food domain.Food
+	if err := db.Where("id = ?", item.FoodID).First(&food).Error; err != nil {
+		return err
+	}
+	item.ApplySnapshot(&food)
+	return nil
+}
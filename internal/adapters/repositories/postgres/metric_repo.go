@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,29 +23,37 @@ func NewMetricRepository(db *gorm.DB) ports.MetricRepository {
 }
 
 func (r *metricRepository) Create(ctx context.Context, metric *domain.Metric) error {
-	return r.db.WithContext(ctx).Create(metric).Error
+	return dbFrom(ctx, r.db).Create(metric).Error
 }
 
 func (r *metricRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Metric, error) {
 	var metric domain.Metric
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&metric).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&metric).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &metric, nil
 }
 
 func (r *metricRepository) Update(ctx context.Context, metric *domain.Metric) error {
-	return r.db.WithContext(ctx).Save(metric).Error
+	return dbFrom(ctx, r.db).Save(metric).Error
 }
 
+// Delete soft-deletes the metric so the delete can be undone with Restore
 func (r *metricRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Metric{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Metric{}, "id = ?", id).Error
+}
+
+func (r *metricRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return restore(dbFrom(ctx, r.db), &domain.Metric{}, id)
 }
 
 func (r *metricRepository) ListByUser(ctx context.Context, userID uuid.UUID, metricType string, startDate, endDate time.Time, limit, offset int) ([]*domain.Metric, error) {
 	var metrics []*domain.Metric
-	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	query := dbFrom(ctx, r.db).Where("user_id = ?", userID)
 
 	if metricType != "" {
 		query = query.Where("metric_type = ?", metricType)
@@ -66,11 +75,92 @@ func (r *metricRepository) ListByUser(ctx context.Context, userID uuid.UUID, met
 	return metrics, nil
 }
 
+// ListMeasuredDays returns the distinct calendar days, in the given timezone, with at least
+// one metric of the type, oldest first
+func (r *metricRepository) ListMeasuredDays(ctx context.Context, userID uuid.UUID, metricType, timezone string) ([]string, error) {
+	var days []string
+	err := dbFrom(ctx, r.db).
+		Model(&domain.Metric{}).
+		Select("DISTINCT TO_CHAR((measured_at AT TIME ZONE ?)::date, 'YYYY-MM-DD') AS day", timezone).
+		Where("user_id = ? AND metric_type = ?", userID, metricType).
+		Order("day").
+		Pluck("day", &days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// metricKey identifies a reading for de-duplication
+type metricKey struct {
+	metricType string
+	measuredAt int64 // microseconds, the precision Postgres stores
+}
+
+func (r *metricRepository) CreateBatch(ctx context.Context, metrics []*domain.Metric) ([]*domain.Metric, error) {
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	var created []*domain.Metric
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		types := make([]string, 0, len(metrics))
+		from, to := metrics[0].MeasuredAt, metrics[0].MeasuredAt
+		for _, metric := range metrics {
+			types = append(types, metric.MetricType)
+			if metric.MeasuredAt.Before(from) {
+				from = metric.MeasuredAt
+			}
+			if metric.MeasuredAt.After(to) {
+				to = metric.MeasuredAt
+			}
+		}
+
+		// Deleted readings count too, so a sync does not bring back what the user removed
+		var existing []struct {
+			MetricType string
+			MeasuredAt time.Time
+		}
+		err := tx.Unscoped().
+			Model(&domain.Metric{}).
+			Select("metric_type, measured_at").
+			Where("user_id = ? AND metric_type IN ? AND measured_at BETWEEN ? AND ?", metrics[0].UserID, types, from, to).
+			Scan(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[metricKey]bool, len(existing)+len(metrics))
+		for _, row := range existing {
+			seen[metricKey{row.MetricType, row.MeasuredAt.UnixMicro()}] = true
+		}
+		for _, metric := range metrics {
+			key := metricKey{metric.MetricType, metric.MeasuredAt.UnixMicro()}
+			if !seen[key] {
+				seen[key] = true
+				created = append(created, metric)
+			}
+		}
+
+		if len(created) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(created, 100).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
 // Daily summary operations
 
+// CreateOrUpdateDailySummary upserts the summary on the unique (user_id, date) index, so
+// concurrent recomputes of a day update one row instead of racing to insert two. The date
+// column has no timezone, so the summary is stored under its own calendar day.
 func (r *metricRepository) CreateOrUpdateDailySummary(ctx context.Context, summary *domain.DailySummary) error {
-	// Use GORM's upsert functionality (INSERT ... ON CONFLICT)
-	return r.db.WithContext(ctx).
+	summary.Date = time.Date(summary.Date.Year(), summary.Date.Month(), summary.Date.Day(), 0, 0, 0, 0, time.UTC)
+	return dbFrom(ctx, r.db).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "user_id"}, {Name: "date"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -78,6 +168,8 @@ func (r *metricRepository) CreateOrUpdateDailySummary(ctx context.Context, summa
 				"total_protein",
 				"total_carbohydrates",
 				"total_fat",
+				"total_saturated_fat",
+				"total_sodium",
 				"total_calories_burned",
 				"total_exercise_minutes",
 				"total_steps",
@@ -92,10 +184,13 @@ func (r *metricRepository) CreateOrUpdateDailySummary(ctx context.Context, summa
 
 func (r *metricRepository) GetDailySummary(ctx context.Context, userID uuid.UUID, date time.Time) (*domain.DailySummary, error) {
 	var summary domain.DailySummary
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Where("user_id = ? AND date = ?", userID, date.Format("2006-01-02")).
 		First(&summary).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &summary, nil
@@ -103,7 +198,7 @@ func (r *metricRepository) GetDailySummary(ctx context.Context, userID uuid.UUID
 
 func (r *metricRepository) ListDailySummaries(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*domain.DailySummary, error) {
 	var summaries []*domain.DailySummary
-	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	query := dbFrom(ctx, r.db).Where("user_id = ?", userID)
 
 	if !startDate.IsZero() && !endDate.IsZero() {
 		query = query.Where("date BETWEEN ? AND ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
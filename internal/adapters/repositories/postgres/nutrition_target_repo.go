@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type nutritionTargetRepository struct {
+	db *gorm.DB
+}
+
+// NewNutritionTargetRepository creates a new nutrition target repository
+func NewNutritionTargetRepository(db *gorm.DB) ports.NutritionTargetRepository {
+	return &nutritionTargetRepository{db: db}
+}
+
+func (r *nutritionTargetRepository) Get(ctx context.Context, userID uuid.UUID) (*domain.NutritionTarget, error) {
+	var target domain.NutritionTarget
+	err := dbFrom(ctx, r.db).Where("user_id = ?", userID).First(&target).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *nutritionTargetRepository) Upsert(ctx context.Context, target *domain.NutritionTarget) error {
+	target.UpdatedAt = time.Now()
+	return dbFrom(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"calories",
+				"protein",
+				"carbohydrates",
+				"fat",
+				"water_ml",
+				"source",
+				"updated_at",
+			}),
+		}).
+		Create(target).Error
+}
@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/domain"
+)
+
+// restore clears deleted_at on a soft-deleted row. It returns domain.ErrNotFound
+// when no row with the ID exists, deleted or not.
+func restore(db *gorm.DB, model interface{}, id uuid.UUID) error {
+	result := db.Unscoped().Model(model).Where("id = ?", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
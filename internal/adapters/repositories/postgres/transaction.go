@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/ports"
+)
+
+type txKey struct{}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a transactor whose transactions every repository of this package joins
+func NewTransactor(db *gorm.DB) ports.Transactor {
+	return &transactor{db: db}
+}
+
+// WithTransaction runs fn in a transaction carried by the context it is given. It commits
+// when fn returns nil and rolls back otherwise. Called inside another transaction, fn joins
+// the outer one, so nothing is committed until the outermost call returns.
+func (t *transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// dbFrom returns the transaction ctx carries, or db when there is none, bound to ctx.
+// Repositories start every query from it so their writes join a WithTransaction call.
+func dbFrom(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
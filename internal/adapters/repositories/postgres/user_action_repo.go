@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type userActionRepository struct {
+	db *gorm.DB
+}
+
+// NewUserActionRepository creates a new user action log repository
+func NewUserActionRepository(db *gorm.DB) ports.UserActionRepository {
+	return &userActionRepository{db: db}
+}
+
+func (r *userActionRepository) Create(ctx context.Context, action *domain.UserAction) error {
+	return dbFrom(ctx, r.db).Create(action).Error
+}
+
+// GetLatest returns the user's most recent action, including undo entries
+func (r *userActionRepository) GetLatest(ctx context.Context, userID uuid.UUID) (*domain.UserAction, error) {
+	var action domain.UserAction
+	err := dbFrom(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&action).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &action, nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(db *gorm.DB) ports.UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	return dbFrom(ctx, r.db).Create(token).Error
+}
+
+func (r *userTokenRepository) GetByHash(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := dbFrom(ctx, r.db).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *userTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	result := dbFrom(ctx, r.db).
+		Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string) error {
+	return dbFrom(ctx, r.db).
+		Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
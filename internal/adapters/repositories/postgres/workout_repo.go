@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
@@ -20,42 +22,70 @@ func NewWorkoutRepository(db *gorm.DB) ports.WorkoutRepository {
 	return &workoutRepository{db: db}
 }
 
+// Create saves the workout together with any exercises and planned sets it holds
 func (r *workoutRepository) Create(ctx context.Context, workout *domain.Workout) error {
-	return r.db.WithContext(ctx).Create(workout).Error
+	return dbFrom(ctx, r.db).Create(workout).Error
 }
 
 func (r *workoutRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Workout, error) {
 	var workout domain.Workout
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Exercises.Exercise").
 		Preload("Exercises.Sets").
+		Preload("Exercises.PlannedSets", orderPlannedSets).
+		Preload("Pauses", orderPauses).
 		Where("id = ?", id).
 		First(&workout).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &workout, nil
 }
 
+// Update saves the workout's own columns; exercises, sets and pauses are saved through
+// their own methods
 func (r *workoutRepository) Update(ctx context.Context, workout *domain.Workout) error {
-	return r.db.WithContext(ctx).Save(workout).Error
+	return dbFrom(ctx, r.db).Omit(clause.Associations).Save(workout).Error
 }
 
+// Delete soft-deletes the workout so the delete can be undone with Restore
 func (r *workoutRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Workout{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.Workout{}, "id = ?", id).Error
 }
 
-func (r *workoutRepository) ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, limit, offset int) ([]*domain.Workout, error) {
+func (r *workoutRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return restore(dbFrom(ctx, r.db), &domain.Workout{}, id)
+}
+
+func (r *workoutRepository) ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, filter domain.WorkoutFilter, limit, offset int) ([]*domain.Workout, error) {
 	var workouts []*domain.Workout
-	query := r.db.WithContext(ctx).
+	query := dbFrom(ctx, r.db).
 		Preload("Exercises.Exercise").
 		Preload("Exercises.Sets").
+		Preload("Exercises.PlannedSets", orderPlannedSets).
+		Preload("Pauses", orderPauses).
 		Where("user_id = ?", userID)
 
 	if !startDate.IsZero() && !endDate.IsZero() {
 		query = query.Where("start_time BETWEEN ? AND ?", startDate, endDate)
 	}
 
+	if filter.ExerciseID != nil {
+		query = query.Where(`id IN (SELECT we.workout_id FROM workout_exercises we
+			JOIN exercises e ON e.id = we.exercise_id
+			WHERE e.id = ? OR e.canonical_id = ?)`, *filter.ExerciseID, *filter.ExerciseID)
+	}
+
+	switch filter.Status {
+	case domain.WorkoutStatusInProgress:
+		query = query.Where("end_time IS NULL")
+	case domain.WorkoutStatusCompleted:
+		query = query.Where("end_time IS NOT NULL")
+	}
+
 	err := query.
 		Limit(limit).
 		Offset(offset).
@@ -68,24 +98,144 @@ func (r *workoutRepository) ListByUser(ctx context.Context, userID uuid.UUID, st
 	return workouts, nil
 }
 
+// ListCompletedDays returns the distinct calendar days, in the given timezone, on which the
+// user started a workout that was finished, oldest first
+func (r *workoutRepository) ListCompletedDays(ctx context.Context, userID uuid.UUID, timezone string) ([]string, error) {
+	var days []string
+	err := dbFrom(ctx, r.db).
+		Model(&domain.Workout{}).
+		Select("DISTINCT TO_CHAR((start_time AT TIME ZONE ?)::date, 'YYYY-MM-DD') AS day", timezone).
+		Where("user_id = ? AND deleted_at IS NULL AND end_time IS NOT NULL", userID).
+		Order("day").
+		Pluck("day", &days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// FirstCompletedAt returns when the user's first finished workout ended, or nil if there is none
+func (r *workoutRepository) FirstCompletedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var result struct {
+		At *time.Time
+	}
+	err := dbFrom(ctx, r.db).
+		Model(&domain.Workout{}).
+		Select("MIN(end_time) AS at").
+		Where("user_id = ? AND deleted_at IS NULL AND end_time IS NOT NULL", userID).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result.At, nil
+}
+
+// FirstPersonalRecordAt returns the start of the first workout in which the user lifted more
+// on an exercise than in any earlier workout, or nil if they never have. A first session of an
+// exercise sets a baseline and is not itself a record, and warmup sets do not count. Aliases
+// are compared with their canonical exercise.
+func (r *workoutRepository) FirstPersonalRecordAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var result struct {
+		At *time.Time
+	}
+	err := dbFrom(ctx, r.db).Raw(`
+		WITH bests AS (
+			SELECT COALESCE(e.canonical_id, e.id) AS exercise_id, w.id AS workout_id, w.start_time, MAX(ws.weight) AS best
+			FROM workout_sets ws
+			JOIN workout_exercises we ON we.id = ws.workout_exercise_id
+			JOIN exercises e ON e.id = we.exercise_id
+			JOIN workouts w ON w.id = we.workout_id
+			WHERE w.user_id = ? AND w.deleted_at IS NULL AND ws.weight > 0 AND ws.set_type <> 'warmup'
+			GROUP BY COALESCE(e.canonical_id, e.id), w.id, w.start_time
+		), ranked AS (
+			SELECT start_time, best,
+				MAX(best) OVER (
+					PARTITION BY exercise_id ORDER BY start_time, workout_id
+					ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+				) AS previous_best
+			FROM bests
+		)
+		SELECT MIN(start_time) AS at FROM ranked WHERE best > previous_best`, userID).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result.At, nil
+}
+
+// Pause operations
+
+func (r *workoutRepository) AddPause(ctx context.Context, pause *domain.WorkoutPause) error {
+	return dbFrom(ctx, r.db).Create(pause).Error
+}
+
+func (r *workoutRepository) UpdatePause(ctx context.Context, pause *domain.WorkoutPause) error {
+	return dbFrom(ctx, r.db).Save(pause).Error
+}
+
+// orderPauses preloads a workout's pauses in the order they were taken
+func orderPauses(db *gorm.DB) *gorm.DB {
+	return db.Order("paused_at")
+}
+
 // Exercise operations
 
 func (r *workoutRepository) CreateExercise(ctx context.Context, exercise *domain.Exercise) error {
-	return r.db.WithContext(ctx).Create(exercise).Error
+	return dbFrom(ctx, r.db).Create(exercise).Error
 }
 
 func (r *workoutRepository) GetExercise(ctx context.Context, id uuid.UUID) (*domain.Exercise, error) {
 	var exercise domain.Exercise
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&exercise).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&exercise).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+func (r *workoutRepository) FindExerciseByName(ctx context.Context, name string) (*domain.Exercise, error) {
+	var exercise domain.Exercise
+	err := dbFrom(ctx, r.db).
+		Where("lower(name) = lower(?)", name).
+		Order("created_at ASC").
+		First(&exercise).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return &exercise, nil
 }
 
+// exerciseSimilarityThreshold is the trigram word similarity above which two exercise
+// names are taken to be likely the same exercise, e.g. "bench press" and "barbell bench press"
+const exerciseSimilarityThreshold = 0.6
+
+func (r *workoutRepository) ListSimilarExercises(ctx context.Context, name string, limit int) ([]*domain.Exercise, error) {
+	var exercises []*domain.Exercise
+	err := dbFrom(ctx, r.db).
+		Where("lower(name) <> lower(?)", name).
+		Where("greatest(word_similarity(lower(?), lower(name)), word_similarity(lower(name), lower(?))) >= ?",
+			name, name, exerciseSimilarityThreshold).
+		Order(clause.Expr{
+			SQL:  "greatest(word_similarity(lower(?), lower(name)), word_similarity(lower(name), lower(?))) DESC, name ASC",
+			Vars: []interface{}{name, name},
+		}).
+		Limit(limit).
+		Find(&exercises).Error
+	if err != nil {
+		return nil, err
+	}
+	return exercises, nil
+}
+
 func (r *workoutRepository) ListExercises(ctx context.Context, category string, limit, offset int) ([]*domain.Exercise, error) {
 	var exercises []*domain.Exercise
-	query := r.db.WithContext(ctx)
+	query := dbFrom(ctx, r.db)
 
 	if category != "" {
 		query = query.Where("category = ?", category)
@@ -106,16 +256,17 @@ func (r *workoutRepository) ListExercises(ctx context.Context, category string,
 // Workout exercise operations
 
 func (r *workoutRepository) AddWorkoutExercise(ctx context.Context, workoutExercise *domain.WorkoutExercise) error {
-	return r.db.WithContext(ctx).Create(workoutExercise).Error
+	return dbFrom(ctx, r.db).Create(workoutExercise).Error
 }
 
 func (r *workoutRepository) GetWorkoutExercises(ctx context.Context, workoutID uuid.UUID) ([]*domain.WorkoutExercise, error) {
 	var workoutExercises []*domain.WorkoutExercise
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Preload("Exercise").
 		Preload("Sets").
+		Preload("PlannedSets", orderPlannedSets).
 		Where("workout_id = ?", workoutID).
-		Order("order_index ASC").
+		Order("order_index ASC, created_at ASC").
 		Find(&workoutExercises).Error
 	if err != nil {
 		return nil, err
@@ -123,23 +274,52 @@ func (r *workoutRepository) GetWorkoutExercises(ctx context.Context, workoutID u
 	return workoutExercises, nil
 }
 
+func (r *workoutRepository) UpdateWorkoutExerciseOrder(ctx context.Context, workoutID uuid.UUID, exercises []*domain.WorkoutExercise) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, we := range exercises {
+			result := tx.Model(&domain.WorkoutExercise{}).
+				Where("id = ? AND workout_id = ?", we.ID, workoutID).
+				Update("order_index", we.OrderIndex)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrNotFound
+			}
+		}
+		return nil
+	})
+}
+
+// orderPlannedSets preloads a workout exercise's planned sets by set number
+func orderPlannedSets(db *gorm.DB) *gorm.DB {
+	return db.Order("set_number")
+}
+
 // Set operations
 
 func (r *workoutRepository) AddSet(ctx context.Context, set *domain.WorkoutSet) error {
-	return r.db.WithContext(ctx).Create(set).Error
+	return dbFrom(ctx, r.db).Create(set).Error
+}
+
+func (r *workoutRepository) AddSets(ctx context.Context, sets []*domain.WorkoutSet) error {
+	if len(sets) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Create(&sets).Error
 }
 
 func (r *workoutRepository) UpdateSet(ctx context.Context, set *domain.WorkoutSet) error {
-	return r.db.WithContext(ctx).Save(set).Error
+	return dbFrom(ctx, r.db).Save(set).Error
 }
 
 func (r *workoutRepository) DeleteSet(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.WorkoutSet{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&domain.WorkoutSet{}, "id = ?", id).Error
 }
 
 func (r *workoutRepository) GetSets(ctx context.Context, workoutExerciseID uuid.UUID) ([]*domain.WorkoutSet, error) {
 	var sets []*domain.WorkoutSet
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Where("workout_exercise_id = ?", workoutExerciseID).
 		Order("set_number ASC").
 		Find(&sets).Error
@@ -148,3 +328,40 @@ func (r *workoutRepository) GetSets(ctx context.Context, workoutExerciseID uuid.
 	}
 	return sets, nil
 }
+
+// ListExerciseSets returns every set the user performed for an exercise or one of its
+// aliases, oldest first. Zero start/end dates leave that side of the range open.
+func (r *workoutRepository) ListExerciseSets(ctx context.Context, userID, exerciseID uuid.UUID, startDate, endDate time.Time) ([]*domain.ExerciseSetRecord, error) {
+	var records []*domain.ExerciseSetRecord
+	query := dbFrom(ctx, r.db).
+		Table("workout_sets AS ws").
+		Select(`ws.id AS set_id,
+			w.id AS workout_id,
+			w.name AS workout_name,
+			w.start_time AS performed_at,
+			ws.set_number,
+			ws.set_type,
+			ws.group_id,
+			ws.reps,
+			ws.weight,
+			ws.rpe`).
+		Joins("JOIN workout_exercises we ON we.id = ws.workout_exercise_id").
+		Joins("JOIN workouts w ON w.id = we.workout_id").
+		Where("w.user_id = ? AND w.deleted_at IS NULL", userID).
+		Where("we.exercise_id IN (SELECT id FROM exercises WHERE id = ? OR canonical_id = ?)", exerciseID, exerciseID)
+
+	if !startDate.IsZero() {
+		query = query.Where("w.start_time >= ?", startDate)
+	}
+	if !endDate.IsZero() {
+		query = query.Where("w.start_time < ?", endDate)
+	}
+
+	err := query.
+		Order("w.start_time ASC, we.order_index ASC, ws.set_number ASC").
+		Scan(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
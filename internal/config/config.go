@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+
+	"fitness-tracker/internal/core/domain"
 )
 
 // Config holds all configuration for the application
@@ -16,6 +18,8 @@ type Config struct {
 	Supabase   SupabaseConfig
 	Server     ServerConfig
 	CORS       CORSConfig
+	Scheduler  SchedulerConfig
+	Nutrition  NutritionConfig
 }
 
 // DatabaseConfig holds database connection settings
@@ -29,6 +33,11 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	PrepareStmt     bool // cache prepared statements per connection
+
+	// Queries taking longer are logged at WARN with their SQL and duration; 0 disables it
+	SlowQueryThreshold time.Duration
 }
 
 // JWTConfig holds JWT authentication settings
@@ -36,6 +45,10 @@ type JWTConfig struct {
 	Secret         string
 	ExpirationTime time.Duration
 	RefreshTime    time.Duration
+	Algorithm      string   // HS256 (default) or RS256
+	KeyID          string   // kid header for newly signed tokens
+	PrivateKeyPath string   // PEM private key, required for RS256
+	PreviousKeys   []string // "kid=secret" (HS256) or "kid=public_key_path" (RS256) still accepted during rotation
 }
 
 // OpenRouterConfig holds OpenRouter API settings
@@ -44,6 +57,36 @@ type OpenRouterConfig struct {
 	BaseURL string
 	Model   string
 	Timeout time.Duration
+
+	// Models tried in order when a model is rate limited or fails with a server or network error
+	FallbackModels []string
+
+	// Sampling temperature (0-2) per task: meal parsing, food estimates and photo analysis
+	// want repeatable answers, chat replies can be warmer
+	ParseTemperature float64
+	ChatTemperature  float64
+
+	// Audit log of every LLM call, for debugging and billing disputes
+	AuditEnabled   bool
+	AuditRedactPII bool // scrub emails, phone numbers and inline images before storing
+
+	// Coach digest tips are written by Model when enabled and an API key is set, templated otherwise
+	CoachDigestAITips bool
+
+	// Weekly recap encouragement is written by Model when enabled and an API key is set, templated otherwise
+	WeeklyRecapAI bool
+
+	// Screening of coach output shown to users: off, standard or strict
+	ModerationStrictness string
+
+	// Older chat messages are summarized once the history sent with a turn holds more than
+	// this many messages or estimated tokens; 0 turns that trigger off
+	SummarizeAfterMessages int
+	SummarizeAfterTokens   int
+
+	// Meals the chat agent logs with less confidence than this (0-1) wait for the user to
+	// confirm them; 0 logs every meal the agent gives quantities for straight away
+	AgentAutoLogConfidence float64
 }
 
 // SupabaseConfig holds Supabase settings
@@ -51,21 +94,80 @@ type SupabaseConfig struct {
 	URL       string
 	AnonKey   string
 	JWTSecret string
+
+	// Photo retention; a retention of 0 days disables cleanup
+	PhotoRetentionDays   int
+	PhotoCleanupInterval time.Duration
+	PhotoCleanupDryRun   bool
 }
 
 // ServerConfig holds server settings
 type ServerConfig struct {
-	Port            int
-	Host            string
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
-	ShutdownTimeout time.Duration
-	Environment     string
+	Port               int
+	Host               string
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	IdleTimeout        time.Duration
+	StreamWriteTimeout time.Duration // write timeout of streaming (SSE) routes; 0 disables it
+	ShutdownTimeout    time.Duration
+	Environment        string
+	AdminUserIDs       []string // users allowed to call the /admin endpoints
+
+	// Requests taking longer are logged at WARN and tagged slow; 0 disables it
+	SlowRequestThreshold time.Duration
+
+	// Page size of list and search endpoints
+	DefaultPageSize int // used when a request gives no limit
+	MaxPageSize     int // larger limits are capped to this
+
+	// Decimal places of calories and nutrients in responses; values are stored unrounded
+	NutritionPrecision int
+
+	// Responses of at least this many bytes are gzip or deflate compressed for clients that accept it
+	CompressionMinSize int
+
+	// Request body limits in bytes: the global default, a lower one for the unauthenticated
+	// auth routes and a higher one for bulk imports such as POST /metrics/batch
+	MaxBodyBytes     int64
+	AuthMaxBodyBytes int64
+	BulkMaxBodyBytes int64
+
+	// Calorie burn sources (device, manual, estimate) from most to least trusted; when
+	// sessions overlap, the shared time counts once, from the most trusted one
+	CalorieSourcePriority []string
+
+	// Food search results are cached for FoodSearchCacheTTL, for up to FoodSearchCacheSize
+	// searches; a TTL or size of 0 disables the cache
+	FoodSearchCacheSize int
+	FoodSearchCacheTTL  time.Duration
+
+	// Exposes POST/DELETE /demo/seed to fill accounts with sample data; not allowed in production
+	DemoSeedEnabled bool
+}
+
+// SchedulerConfig holds settings of the scheduled job runner
+type SchedulerConfig struct {
+	Enabled   bool                     // false keeps this instance from running any job
+	Intervals map[string]time.Duration // per-job intervals keyed by job name, overriding each job's default
+}
+
+// Interval returns the configured interval of a job, or fallback when none is set
+func (c SchedulerConfig) Interval(job string, fallback time.Duration) time.Duration {
+	if interval, ok := c.Intervals[job]; ok {
+		return interval
+	}
+	return fallback
+}
+
+// NutritionConfig holds settings of the nutrition targets suggested to users
+type NutritionConfig struct {
+	// Macro split per goal type for derived targets: the defaults with any configured overrides
+	MacroPresets domain.MacroPresets
 }
 
 // CORSConfig holds CORS settings
 type CORSConfig struct {
-	AllowedOrigins   []string
+	AllowedOrigins   []string // "*" allows any origin, but without credentials
 	AllowedMethods   []string
 	AllowedHeaders   []string
 	ExposedHeaders   []string
@@ -110,23 +212,32 @@ func LoadConfig(configPath string) (*Config, error) {
 	} else {
 		// Use individual environment variables
 		config.Database = DatabaseConfig{
-			Host:            viper.GetString("database.host"),
-			Port:            viper.GetInt("database.port"),
-			User:            viper.GetString("database.user"),
-			Password:        viper.GetString("database.password"),
-			DBName:          viper.GetString("database.dbname"),
-			SSLMode:         viper.GetString("database.sslmode"),
-			MaxOpenConns:    viper.GetInt("database.max_open_conns"),
-			MaxIdleConns:    viper.GetInt("database.max_idle_conns"),
-			ConnMaxLifetime: viper.GetDuration("database.conn_max_lifetime"),
+			Host:     viper.GetString("database.host"),
+			Port:     viper.GetInt("database.port"),
+			User:     viper.GetString("database.user"),
+			Password: viper.GetString("database.password"),
+			DBName:   viper.GetString("database.dbname"),
+			SSLMode:  viper.GetString("database.sslmode"),
 		}
 	}
 
+	// Pool and statement cache settings apply to both connection styles
+	config.Database.MaxOpenConns = viper.GetInt("database.max_open_conns")
+	config.Database.MaxIdleConns = viper.GetInt("database.max_idle_conns")
+	config.Database.ConnMaxLifetime = viper.GetDuration("database.conn_max_lifetime")
+	config.Database.ConnMaxIdleTime = viper.GetDuration("database.conn_max_idle_time")
+	config.Database.PrepareStmt = viper.GetBool("database.prepare_stmt")
+	config.Database.SlowQueryThreshold = viper.GetDuration("database.slow_query_threshold")
+
 	// JWT Config
 	config.JWT = JWTConfig{
 		Secret:         viper.GetString("jwt.secret"),
 		ExpirationTime: viper.GetDuration("jwt.expiration_time"),
 		RefreshTime:    viper.GetDuration("jwt.refresh_time"),
+		Algorithm:      strings.ToUpper(viper.GetString("jwt.algorithm")),
+		KeyID:          viper.GetString("jwt.key_id"),
+		PrivateKeyPath: viper.GetString("jwt.private_key_path"),
+		PreviousKeys:   viper.GetStringSlice("jwt.previous_keys"),
 	}
 
 	// OpenRouter Config
@@ -135,6 +246,24 @@ func LoadConfig(configPath string) (*Config, error) {
 		BaseURL: viper.GetString("openrouter.base_url"),
 		Model:   viper.GetString("openrouter.model"),
 		Timeout: viper.GetDuration("openrouter.timeout"),
+
+		FallbackModels: viper.GetStringSlice("openrouter.fallback_models"),
+
+		ParseTemperature: viper.GetFloat64("openrouter.parse_temperature"),
+		ChatTemperature:  viper.GetFloat64("openrouter.chat_temperature"),
+
+		AuditEnabled:   viper.GetBool("openrouter.audit_enabled"),
+		AuditRedactPII: viper.GetBool("openrouter.audit_redact_pii"),
+
+		CoachDigestAITips: viper.GetBool("openrouter.coach_digest_ai_tips"),
+		WeeklyRecapAI:     viper.GetBool("openrouter.weekly_recap_ai"),
+
+		ModerationStrictness: viper.GetString("openrouter.moderation_strictness"),
+
+		SummarizeAfterMessages: viper.GetInt("openrouter.summarize_after_messages"),
+		SummarizeAfterTokens:   viper.GetInt("openrouter.summarize_after_tokens"),
+
+		AgentAutoLogConfidence: viper.GetFloat64("openrouter.agent_auto_log_confidence"),
 	}
 
 	// Supabase Config
@@ -142,28 +271,73 @@ func LoadConfig(configPath string) (*Config, error) {
 		URL:       viper.GetString("supabase.url"),
 		AnonKey:   viper.GetString("supabase.anon_key"),
 		JWTSecret: viper.GetString("supabase.jwt_secret"),
+
+		PhotoRetentionDays:   viper.GetInt("supabase.photo_retention_days"),
+		PhotoCleanupInterval: viper.GetDuration("supabase.photo_cleanup_interval"),
+		PhotoCleanupDryRun:   viper.GetBool("supabase.photo_cleanup_dry_run"),
 	}
 
 	// Server Config
 	config.Server = ServerConfig{
-		Port:            viper.GetInt("server.port"),
-		Host:            viper.GetString("server.host"),
-		ReadTimeout:     viper.GetDuration("server.read_timeout"),
-		WriteTimeout:    viper.GetDuration("server.write_timeout"),
-		ShutdownTimeout: viper.GetDuration("server.shutdown_timeout"),
-		Environment:     viper.GetString("server.environment"),
+		Port:               viper.GetInt("server.port"),
+		Host:               viper.GetString("server.host"),
+		ReadTimeout:        viper.GetDuration("server.read_timeout"),
+		WriteTimeout:       viper.GetDuration("server.write_timeout"),
+		IdleTimeout:        viper.GetDuration("server.idle_timeout"),
+		StreamWriteTimeout: viper.GetDuration("server.stream_write_timeout"),
+		ShutdownTimeout:    viper.GetDuration("server.shutdown_timeout"),
+		Environment:        viper.GetString("server.environment"),
+		AdminUserIDs:       viper.GetStringSlice("server.admin_user_ids"),
+
+		SlowRequestThreshold: viper.GetDuration("server.slow_request_threshold"),
+
+		DefaultPageSize: viper.GetInt("server.default_page_size"),
+		MaxPageSize:     viper.GetInt("server.max_page_size"),
+
+		NutritionPrecision: viper.GetInt("server.nutrition_precision"),
+		CompressionMinSize: viper.GetInt("server.compression_min_size"),
+
+		MaxBodyBytes:     viper.GetInt64("server.max_body_bytes"),
+		AuthMaxBodyBytes: viper.GetInt64("server.auth_max_body_bytes"),
+		BulkMaxBodyBytes: viper.GetInt64("server.bulk_max_body_bytes"),
+
+		CalorieSourcePriority: listSetting("server.calorie_source_priority"),
+
+		FoodSearchCacheSize: viper.GetInt("server.food_search_cache_size"),
+		FoodSearchCacheTTL:  viper.GetDuration("server.food_search_cache_ttl"),
+
+		DemoSeedEnabled: viper.GetBool("server.demo_seed_enabled"),
 	}
 
 	// CORS Config
 	config.CORS = CORSConfig{
-		AllowedOrigins:   viper.GetStringSlice("cors.allowed_origins"),
-		AllowedMethods:   viper.GetStringSlice("cors.allowed_methods"),
-		AllowedHeaders:   viper.GetStringSlice("cors.allowed_headers"),
-		ExposedHeaders:   viper.GetStringSlice("cors.exposed_headers"),
+		AllowedOrigins:   listSetting("cors.allowed_origins"),
+		AllowedMethods:   listSetting("cors.allowed_methods"),
+		AllowedHeaders:   listSetting("cors.allowed_headers"),
+		ExposedHeaders:   listSetting("cors.exposed_headers"),
 		AllowCredentials: viper.GetBool("cors.allow_credentials"),
 		MaxAge:           viper.GetInt("cors.max_age"),
 	}
 
+	// Scheduler Config
+	intervals, err := parseIntervals(listSetting("scheduler.intervals"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduler intervals: %w", err)
+	}
+	config.Scheduler = SchedulerConfig{
+		Enabled:   viper.GetBool("scheduler.enabled"),
+		Intervals: intervals,
+	}
+
+	// Nutrition Config
+	presets, err := parseMacroPresets(listSetting("nutrition.macro_presets"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid macro presets: %w", err)
+	}
+	config.Nutrition = NutritionConfig{
+		MacroPresets: domain.DefaultMacroPresets.WithOverrides(presets),
+	}
+
 	// Validate configuration
 	if err := validateConfig(&config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -181,31 +355,122 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 25)
 	viper.SetDefault("database.max_idle_conns", 5)
 	viper.SetDefault("database.conn_max_lifetime", 5*time.Minute)
+	viper.SetDefault("database.conn_max_idle_time", 2*time.Minute)
+	viper.SetDefault("database.prepare_stmt", true)
+	viper.SetDefault("database.slow_query_threshold", 200*time.Millisecond)
 
 	// JWT defaults
 	viper.SetDefault("jwt.expiration_time", 24*time.Hour)
 	viper.SetDefault("jwt.refresh_time", 7*24*time.Hour)
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.key_id", "default")
 
 	// OpenRouter defaults
 	viper.SetDefault("openrouter.base_url", "https://openrouter.ai/api/v1")
 	viper.SetDefault("openrouter.model", "openai/gpt-4-turbo-preview")
 	viper.SetDefault("openrouter.timeout", 30*time.Second)
+	viper.SetDefault("openrouter.parse_temperature", 0.0)
+	viper.SetDefault("openrouter.chat_temperature", 0.7)
+	viper.SetDefault("openrouter.audit_enabled", false)
+	viper.SetDefault("openrouter.audit_redact_pii", true)
+	viper.SetDefault("openrouter.coach_digest_ai_tips", true)
+	viper.SetDefault("openrouter.weekly_recap_ai", true)
+	viper.SetDefault("openrouter.moderation_strictness", "standard")
+	viper.SetDefault("openrouter.summarize_after_messages", 16)
+	viper.SetDefault("openrouter.summarize_after_tokens", 6000)
+	viper.SetDefault("openrouter.agent_auto_log_confidence", 0.8)
+
+	// Supabase defaults
+	viper.SetDefault("supabase.photo_retention_days", 0)
+	viper.SetDefault("supabase.photo_cleanup_interval", 24*time.Hour)
+	viper.SetDefault("supabase.photo_cleanup_dry_run", false)
 
 	// Server defaults
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.read_timeout", 15*time.Second)
 	viper.SetDefault("server.write_timeout", 15*time.Second)
+	viper.SetDefault("server.idle_timeout", 60*time.Second)
+	viper.SetDefault("server.stream_write_timeout", 5*time.Minute)
 	viper.SetDefault("server.shutdown_timeout", 10*time.Second)
+	viper.SetDefault("server.slow_request_threshold", time.Second)
 	viper.SetDefault("server.environment", "development")
+	viper.SetDefault("server.default_page_size", 20)
+	viper.SetDefault("server.max_page_size", 100)
+	viper.SetDefault("server.nutrition_precision", 1)
+	viper.SetDefault("server.compression_min_size", 1024)
+	viper.SetDefault("server.max_body_bytes", 1<<20)       // 1 MB
+	viper.SetDefault("server.auth_max_body_bytes", 64<<10) // 64 KB
+	viper.SetDefault("server.bulk_max_body_bytes", 10<<20) // 10 MB
+	viper.SetDefault("server.calorie_source_priority", []string{"device", "manual", "estimate"})
+	viper.SetDefault("server.food_search_cache_size", 1000)
+	viper.SetDefault("server.food_search_cache_ttl", time.Minute)
+	viper.SetDefault("server.demo_seed_enabled", false)
 
 	// CORS defaults
-	viper.SetDefault("cors.allowed_origins", []string{"*"})
-	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
-	viper.SetDefault("cors.allowed_headers", []string{"Content-Type", "Authorization"})
-	viper.SetDefault("cors.exposed_headers", []string{"Content-Length"})
+	viper.SetDefault("cors.allowed_origins", []string{"http://localhost:3000", "http://localhost:5173", "*"})
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	viper.SetDefault("cors.allowed_headers", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"})
+	viper.SetDefault("cors.exposed_headers", []string{"Content-Length", "X-Request-ID"})
 	viper.SetDefault("cors.allow_credentials", true)
-	viper.SetDefault("cors.max_age", 3600)
+	viper.SetDefault("cors.max_age", 12*60*60)
+
+	// Scheduler defaults
+	viper.SetDefault("scheduler.enabled", true)
+	viper.SetDefault("scheduler.intervals", []string{})
+
+	// Nutrition defaults; presets not overridden keep domain.DefaultMacroPresets
+	viper.SetDefault("nutrition.macro_presets", []string{})
+}
+
+// listSetting reads a list that may also be given as one comma-separated value,
+// as environment variables like CORS_ALLOWED_ORIGINS are
+func listSetting(key string) []string {
+	var list []string
+	for _, value := range viper.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// parseIntervals parses "name=duration" entries such as "photo_cleanup=12h"
+func parseIntervals(entries []string) (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not name=duration", entry)
+		}
+		interval, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		intervals[name] = interval
+	}
+	return intervals, nil
+}
+
+// parseMacroPresets parses "goal=protein/carbohydrates/fat" entries such as "cut=40/30/30"
+func parseMacroPresets(entries []string) (domain.MacroPresets, error) {
+	presets := make(domain.MacroPresets, len(entries))
+	for _, entry := range entries {
+		goalType, value, ok := strings.Cut(entry, "=")
+		goalType = strings.ToLower(strings.TrimSpace(goalType))
+		if !ok || goalType == "" {
+			return nil, fmt.Errorf("%q is not goal=protein/carbohydrates/fat", entry)
+		}
+		split, err := domain.ParseMacroSplit(value)
+		if err != nil {
+			return nil, err
+		}
+		presets[goalType] = split
+	}
+	return presets, nil
 }
 
 // parseDatabaseURL parses a DATABASE_URL connection string
@@ -265,15 +530,12 @@ func parseDatabaseURL(dbURL string) (*DatabaseConfig, error) {
 	}
 
 	return &DatabaseConfig{
-		Host:            host,
-		Port:            port,
-		User:            user,
-		Password:        password,
-		DBName:          dbname,
-		SSLMode:         sslmode,
-		MaxOpenConns:    25,
-		MaxIdleConns:    5,
-		ConnMaxLifetime: 5 * time.Minute,
+		Host:     host,
+		Port:     port,
+		User:     user,
+		Password: password,
+		DBName:   dbname,
+		SSLMode:  sslmode,
 	}, nil
 }
 
@@ -289,22 +551,104 @@ func validateConfig(config *Config) error {
 	if config.Database.DBName == "" {
 		return fmt.Errorf("database name is required")
 	}
+	if config.Database.MaxOpenConns < 1 {
+		return fmt.Errorf("database max open connections must be at least 1")
+	}
+	if config.Database.MaxIdleConns < 0 || config.Database.MaxIdleConns > config.Database.MaxOpenConns {
+		return fmt.Errorf("database max idle connections must be between 0 and max open connections")
+	}
 
 	// Validate JWT
-	if config.JWT.Secret == "" {
-		return fmt.Errorf("JWT secret is required")
-	}
-	if len(config.JWT.Secret) < 32 {
-		return fmt.Errorf("JWT secret must be at least 32 characters long")
+	switch config.JWT.Algorithm {
+	case "HS256":
+		if config.JWT.Secret == "" {
+			return fmt.Errorf("JWT secret is required")
+		}
+		if len(config.JWT.Secret) < 32 {
+			return fmt.Errorf("JWT secret must be at least 32 characters long")
+		}
+	case "RS256":
+		if config.JWT.PrivateKeyPath == "" {
+			return fmt.Errorf("JWT private key path is required for RS256")
+		}
+	default:
+		return fmt.Errorf("unsupported JWT algorithm: %s", config.JWT.Algorithm)
 	}
 
 	// OpenRouter and Supabase are optional - only validate if provided
 	// This allows for basic deployment without these services
+	switch config.OpenRouter.ModerationStrictness {
+	case "off", "standard", "strict":
+	default:
+		return fmt.Errorf("openrouter moderation strictness must be off, standard or strict")
+	}
+	if config.OpenRouter.SummarizeAfterMessages < 0 || config.OpenRouter.SummarizeAfterTokens < 0 {
+		return fmt.Errorf("openrouter summarize thresholds must not be negative")
+	}
+	for _, temperature := range []float64{config.OpenRouter.ParseTemperature, config.OpenRouter.ChatTemperature} {
+		if temperature < 0 || temperature > 2 {
+			return fmt.Errorf("openrouter temperatures must be between 0 and 2")
+		}
+	}
+	if config.OpenRouter.AgentAutoLogConfidence < 0 || config.OpenRouter.AgentAutoLogConfidence > 1 {
+		return fmt.Errorf("openrouter agent auto-log confidence must be between 0 and 1")
+	}
+	if config.Supabase.PhotoRetentionDays < 0 {
+		return fmt.Errorf("supabase photo retention days must not be negative")
+	}
+	if config.Supabase.PhotoRetentionDays > 0 && config.Supabase.PhotoCleanupInterval <= 0 {
+		return fmt.Errorf("supabase photo cleanup interval must be positive when retention is enabled")
+	}
 
 	// Validate Server
 	if config.Server.Port < 1 || config.Server.Port > 65535 {
 		return fmt.Errorf("server port must be between 1 and 65535")
 	}
+	if config.Server.ReadTimeout < 0 || config.Server.WriteTimeout < 0 || config.Server.IdleTimeout < 0 || config.Server.StreamWriteTimeout < 0 {
+		return fmt.Errorf("server timeouts must not be negative")
+	}
+	if config.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("server shutdown timeout must be positive")
+	}
+	if config.Server.DefaultPageSize < 1 || config.Server.MaxPageSize < config.Server.DefaultPageSize {
+		return fmt.Errorf("server default page size must be at least 1 and not above the max page size")
+	}
+	if config.Server.NutritionPrecision < 0 || config.Server.NutritionPrecision > 4 {
+		return fmt.Errorf("server nutrition precision must be between 0 and 4 decimal places")
+	}
+	if config.Server.CompressionMinSize < 0 {
+		return fmt.Errorf("server compression min size must not be negative")
+	}
+	if config.Server.MaxBodyBytes < 0 || config.Server.AuthMaxBodyBytes < 0 || config.Server.BulkMaxBodyBytes < 0 {
+		return fmt.Errorf("server body size limits must not be negative")
+	}
+	seenSources := make(map[string]bool, len(config.Server.CalorieSourcePriority))
+	for _, source := range config.Server.CalorieSourcePriority {
+		switch source {
+		case "device", "manual", "estimate":
+		default:
+			return fmt.Errorf("server calorie source priority has unknown source %q; use device, manual or estimate", source)
+		}
+		if seenSources[source] {
+			return fmt.Errorf("server calorie source priority lists %s twice", source)
+		}
+		seenSources[source] = true
+	}
+	if config.Server.DemoSeedEnabled && config.Server.Environment == "production" {
+		return fmt.Errorf("server demo seed must not be enabled in production")
+	}
+
+	// Validate CORS
+	if config.CORS.MaxAge < 0 {
+		return fmt.Errorf("cors max age must not be negative")
+	}
+
+	// Validate Scheduler
+	for name, interval := range config.Scheduler.Intervals {
+		if interval <= 0 {
+			return fmt.Errorf("scheduler interval of %s must be positive", name)
+		}
+	}
 
 	return nil
 }
@@ -5,17 +5,41 @@ import (
 	"log"
 	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 // InitDB initializes the database connection and runs migrations
-func InitDB(config *DatabaseConfig) (*gorm.DB, error) {
+func InitDB(config *DatabaseConfig, queryLog *zap.Logger) (*gorm.DB, error) {
+	db, err := OpenDB(config, queryLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run migrations
+	if err := runMigrations(db); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	log.Println("Database migrations completed successfully")
+
+	return db, nil
+}
+
+// OpenDB opens a connection with the configured pool and statement cache settings.
+// It is the single place GORM connections are created. Queries are logged to queryLog, or
+// to GORM's default logger when queryLog is nil.
+func OpenDB(config *DatabaseConfig, queryLog *zap.Logger) (*gorm.DB, error) {
 	// Configure GORM logger
-	gormLogger := logger.Default
+	level := logger.Warn
 	if config.SSLMode == "disable" {
-		gormLogger = logger.Default.LogMode(logger.Info)
+		level = logger.Info
+	}
+	gormLogger := logger.Default.LogMode(level)
+	if queryLog != nil {
+		gormLogger = NewQueryLogger(queryLog, level, config.SlowQueryThreshold)
 	}
 
 	// Open database connection
@@ -24,7 +48,7 @@ func InitDB(config *DatabaseConfig) (*gorm.DB, error) {
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
-		PrepareStmt: true,
+		PrepareStmt: config.PrepareStmt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -40,20 +64,15 @@ func InitDB(config *DatabaseConfig) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
 	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Printf("Successfully connected to database at %s:%d", config.Host, config.Port)
-
-	// Run migrations
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	log.Println("Database migrations completed successfully")
+	log.Printf("Successfully connected to database at %s:%d (max_open=%d, max_idle=%d, prepare_stmt=%t)",
+		config.Host, config.Port, config.MaxOpenConns, config.MaxIdleConns, config.PrepareStmt)
 
 	return db, nil
 }
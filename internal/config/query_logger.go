@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// queryLogger writes GORM's logs through zap. Queries slower than slowThreshold are
+// logged at WARN with their SQL and duration; at the Info level every query is
+// logged at DEBUG.
+type queryLogger struct {
+	logger        *zap.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration // 0 turns slow query logging off
+}
+
+// NewQueryLogger returns a GORM logger writing to logger at the given level
+func NewQueryLogger(logger *zap.Logger, level gormlogger.LogLevel, slowThreshold time.Duration) gormlogger.Interface {
+	return &queryLogger{logger: logger, level: level, slowThreshold: slowThreshold}
+}
+
+// LogMode returns a copy of the logger at level
+func (l *queryLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.Info(fmt.Sprintf(msg, args...), l.contextFields(ctx)...)
+	}
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.Warn(fmt.Sprintf(msg, args...), l.contextFields(ctx)...)
+	}
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.Error(fmt.Sprintf(msg, args...), l.contextFields(ctx)...)
+	}
+}
+
+// Trace logs a finished query. A record not being found is an expected outcome, not
+// a failed query.
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	switch {
+	case failed && l.level >= gormlogger.Error:
+		l.logger.Error("Database query failed", append(l.queryFields(ctx, elapsed, fc), zap.Error(err))...)
+	case slow && l.level >= gormlogger.Warn:
+		l.logger.Warn("Slow database query", append(l.queryFields(ctx, elapsed, fc),
+			zap.Bool("slow", true),
+			zap.Duration("threshold", l.slowThreshold),
+		)...)
+	case l.level >= gormlogger.Info:
+		l.logger.Debug("Database query", l.queryFields(ctx, elapsed, fc)...)
+	}
+}
+
+// ParamsFilter keeps query parameters, which hold user data such as emails and
+// meal descriptions, out of the logged SQL
+func (l *queryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (l *queryLogger) queryFields(ctx context.Context, elapsed time.Duration, fc func() (string, int64)) []zap.Field {
+	sql, rows := fc()
+	return append(l.contextFields(ctx),
+		zap.String("sql", sql),
+		zap.Duration("duration", elapsed),
+		zap.Int64("rows", rows),
+	)
+}
+
+func (l *queryLogger) contextFields(ctx context.Context) []zap.Field {
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		return []zap.Field{zap.String("request_id", requestID)}
+	}
+	return nil
+}
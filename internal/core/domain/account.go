@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types published for downstream consumers
+const (
+	EventAccountDeleted = "account.deleted"
+)
+
+// UserDataExport is a full copy of a user's data, returned before account deletion
+type UserDataExport struct {
+	User           User           `json:"user"`
+	Meals          []Meal         `json:"meals"`
+	Activities     []Activity     `json:"activities"`
+	Workouts       []Workout      `json:"workouts"`
+	Metrics        []Metric       `json:"metrics"`
+	DailySummaries []DailySummary `json:"daily_summaries"`
+	Goals          []Goal         `json:"goals"`
+	Achievements   []Achievement  `json:"achievements"`
+	Conversations  []Conversation `json:"conversations"`
+	// Settings stored once per user; nil when the user never saved them
+	NutritionTarget  *NutritionTarget  `json:"nutrition_target,omitempty"`
+	MealDistribution *MealDistribution `json:"meal_distribution,omitempty"`
+	ExportedAt       time.Time         `json:"exported_at"`
+}
+
+// AccountDeletedEvent is published after a user's data has been purged
+type AccountDeletedEvent struct {
+	UserID    uuid.UUID `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -1,9 +1,12 @@
 package domain
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Activity represents a physical activity logged by a user
@@ -18,15 +21,22 @@ type Activity struct {
 	// Activity metrics
 	Distance       *float64 `gorm:"type:decimal(10,2)" json:"distance,omitempty"`        // Stored as float64, precision 10,2, in km
 	CaloriesBurned *float64 `gorm:"type:decimal(10,2)" json:"calories_burned,omitempty"` // Stored as float64, precision 10,2
+	CalorieSource  *string  `gorm:"type:varchar(20)" json:"calorie_source,omitempty"` // device, manual or estimate; manual when unset
 	AverageHeartRate *int   `gorm:"type:integer" json:"average_heart_rate,omitempty"`
 	MaxHeartRate   *int     `gorm:"type:integer" json:"max_heart_rate,omitempty"`
 	Steps          *int     `gorm:"type:integer" json:"steps,omitempty"`
 
 	Notes *string `gorm:"type:text" json:"notes,omitempty"`
 
-	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	// Route is the GPS track, when one was recorded. Only single activity lookups load it.
+	Route []RoutePoint `gorm:"foreignKey:ActivityID" json:"route,omitempty"`
+
+	// Set on sample activities added by the demo seed
+	IsDemo bool `gorm:"not null;default:false" json:"is_demo,omitempty"`
+
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // soft-deleted so a delete can be undone
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"-"`
@@ -36,3 +46,30 @@ type Activity struct {
 func (Activity) TableName() string {
 	return "activities"
 }
+
+// ActivityDurationTolerance is how many minutes a logged duration may differ from the time
+// between an activity's start and end, allowing for rounding to whole minutes
+const ActivityDurationTolerance = 1
+
+// ReconcileDuration checks an activity with an end time ends after it starts and that its
+// duration matches that interval, deriving the duration from the interval when it is not set.
+// Activities without an end time are left as they are.
+func (a *Activity) ReconcileDuration() error {
+	if a.EndTime == nil {
+		return nil
+	}
+	if !a.EndTime.After(a.StartTime) {
+		return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidInput)
+	}
+
+	interval := int(math.Round(a.EndTime.Sub(a.StartTime).Minutes()))
+	if a.DurationMinutes == nil {
+		a.DurationMinutes = &interval
+		return nil
+	}
+	if diff := *a.DurationMinutes - interval; diff > ActivityDurationTolerance || diff < -ActivityDurationTolerance {
+		return fmt.Errorf("%w: duration of %d minutes does not match the %d minutes between start_time and end_time",
+			ErrInvalidInput, *a.DurationMinutes, interval)
+	}
+	return nil
+}
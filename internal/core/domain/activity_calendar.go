@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// What was logged on a day of an ActivityCalendar. A day's value adds up the flags that
+// apply, so 0 is a day with nothing logged and 7 one with all three.
+const (
+	CalendarMealLogged   = 1 << iota // at least one meal
+	CalendarWorkoutDone              // at least one finished workout
+	CalendarWeightLogged             // at least one weight measurement
+)
+
+// Years an ActivityCalendar can be built for
+const (
+	MinCalendarYear = 1970
+	MaxCalendarYear = 9999
+)
+
+// ActivityCalendar is a year of a user's logging, one value per local calendar day, for
+// drawing a contribution-style heatmap
+type ActivityCalendar struct {
+	Year     int    `json:"year"`
+	Timezone string `json:"timezone"`
+	Today    string `json:"today"` // YYYY-MM-DD in Timezone
+
+	// Weekday of January 1, 0 for Sunday, so a grid of weeks knows where the year starts
+	StartWeekday int `json:"start_weekday"`
+	DaysInYear   int `json:"days_in_year"`
+
+	// Days holds a value for each day from January 1 up to today: all of a past year,
+	// part of the current one and nothing of a future one
+	Days   []int                  `json:"days"`
+	Flags  ActivityCalendarFlags  `json:"flags"`
+	Totals ActivityCalendarTotals `json:"totals"`
+}
+
+// ActivityCalendarFlags spells out the bit of a day's value each kind of logging sets
+type ActivityCalendarFlags struct {
+	Meal    int `json:"meal"`
+	Workout int `json:"workout"`
+	Weight  int `json:"weight"`
+}
+
+// ActivityCalendarTotals counts the days of the calendar with each kind of logging
+type ActivityCalendarTotals struct {
+	MealDays    int `json:"meal_days"`
+	WorkoutDays int `json:"workout_days"`
+	WeightDays  int `json:"weight_days"`
+	ActiveDays  int `json:"active_days"` // days with any logging
+}
+
+// ParseCalendarYear reads the year of an activity calendar. An empty year is the year of
+// today.
+func ParseCalendarYear(year string, today time.Time) (int, error) {
+	if year == "" {
+		return today.Year(), nil
+	}
+	parsed, err := strconv.Atoi(year)
+	if err != nil || parsed < MinCalendarYear || parsed > MaxCalendarYear {
+		return 0, fmt.Errorf("%w: year must be a number from %d to %d", ErrInvalidInput, MinCalendarYear, MaxCalendarYear)
+	}
+	return parsed, nil
+}
+
+// NewActivityCalendar builds the calendar of a year from the days, as distinct
+// YYYY-MM-DD strings, on which meals, workouts and weights were logged. Days outside the
+// year or after today are left out; today is the user's local date.
+func NewActivityCalendar(year int, timezone string, today time.Time, mealDays, workoutDays, weightDays []string) *ActivityCalendar {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	calendar := &ActivityCalendar{
+		Year:         year,
+		Timezone:     timezone,
+		Today:        todayDate.Format("2006-01-02"),
+		StartWeekday: int(start.Weekday()),
+		DaysInYear:   int(end.Sub(start).Hours() / 24),
+		Days:         []int{},
+		Flags: ActivityCalendarFlags{
+			Meal:    CalendarMealLogged,
+			Workout: CalendarWorkoutDone,
+			Weight:  CalendarWeightLogged,
+		},
+	}
+
+	// Days up to and including today
+	shown := calendar.DaysInYear
+	if todayDate.Before(end) {
+		shown = max(int(todayDate.Sub(start).Hours()/24)+1, 0)
+	}
+	calendar.Days = make([]int, shown)
+
+	mark := func(days []string, flag int) {
+		for _, date := range days {
+			day, err := time.Parse("2006-01-02", date)
+			if err != nil {
+				continue
+			}
+			if index := int(day.Sub(start).Hours() / 24); !day.Before(start) && index < shown {
+				calendar.Days[index] |= flag
+			}
+		}
+	}
+	mark(mealDays, CalendarMealLogged)
+	mark(workoutDays, CalendarWorkoutDone)
+	mark(weightDays, CalendarWeightLogged)
+
+	for _, value := range calendar.Days {
+		if value&CalendarMealLogged != 0 {
+			calendar.Totals.MealDays++
+		}
+		if value&CalendarWorkoutDone != 0 {
+			calendar.Totals.WorkoutDays++
+		}
+		if value&CalendarWeightLogged != 0 {
+			calendar.Totals.WeightDays++
+		}
+		if value != 0 {
+			calendar.Totals.ActiveDays++
+		}
+	}
+	return calendar
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoutePoint is one GPS fix of an activity's route
+type RoutePoint struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"-"`
+	ActivityID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	Sequence   int       `gorm:"type:integer;not null" json:"-"` // order along the route
+	Latitude   float64   `gorm:"type:decimal(9,6);not null" json:"lat"`
+	Longitude  float64   `gorm:"type:decimal(9,6);not null" json:"lng"`
+	RecordedAt time.Time `gorm:"not null" json:"time"`
+}
+
+// TableName specifies the table name for GORM
+func (RoutePoint) TableName() string {
+	return "activity_route_points"
+}
+
+// MaxRoutePoints caps how many points one activity's route may hold, about 14 hours
+// recorded once a second
+const MaxRoutePoints = 50000
+
+// earthRadiusKm is the mean radius of the Earth used for route distances
+const earthRadiusKm = 6371.0
+
+// maxSpeedKmh is the fastest average speed still plausible for an activity type. Anything
+// faster over a whole activity is a wrong distance or duration, or a GPS track recorded
+// in a car. Types not listed, such as yoga, are held to defaultMaxSpeedKmh.
+var maxSpeedKmh = map[string]float64{
+	"walking":  12,
+	"hiking":   12,
+	"swimming": 10,
+	"running":  30,
+	"cycling":  80,
+}
+
+const defaultMaxSpeedKmh = 80
+
+// MaxSpeedKmh returns the fastest plausible average speed for an activity type
+func MaxSpeedKmh(activityType string) float64 {
+	if speed, ok := maxSpeedKmh[activityType]; ok {
+		return speed
+	}
+	return defaultMaxSpeedKmh
+}
+
+// ValidateRoute checks a route has at least two points, that every point is a real
+// coordinate, and that the points are in time order
+func ValidateRoute(points []RoutePoint) error {
+	if len(points) < 2 {
+		return fmt.Errorf("%w: a route needs at least 2 points", ErrInvalidInput)
+	}
+	if len(points) > MaxRoutePoints {
+		return fmt.Errorf("%w: a route may have at most %d points", ErrInvalidInput, MaxRoutePoints)
+	}
+	for i, point := range points {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return fmt.Errorf("%w: route point %d is not a valid coordinate", ErrInvalidInput, i)
+		}
+		if point.RecordedAt.IsZero() {
+			return fmt.Errorf("%w: route point %d has no time", ErrInvalidInput, i)
+		}
+		if i > 0 && point.RecordedAt.Before(points[i-1].RecordedAt) {
+			return fmt.Errorf("%w: route point %d is earlier than the point before it", ErrInvalidInput, i)
+		}
+	}
+	return nil
+}
+
+// RouteDistanceKm is the length of a route in kilometers, summing the great-circle
+// distance between consecutive points
+func RouteDistanceKm(points []RoutePoint) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += haversineKm(points[i-1], points[i])
+	}
+	return total
+}
+
+func haversineKm(from, to RoutePoint) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ApplyRoute numbers the route's points and, when the activity has no distance, sets it
+// to the route's length. A distance given with the route, e.g. from a wheel sensor, is kept.
+// An activity with neither end time nor duration takes its duration from the route too.
+func (a *Activity) ApplyRoute() error {
+	if len(a.Route) == 0 {
+		return nil
+	}
+	if err := ValidateRoute(a.Route); err != nil {
+		return err
+	}
+
+	for i := range a.Route {
+		a.Route[i].Sequence = i
+	}
+	if a.Distance == nil {
+		distance := math.Round(RouteDistanceKm(a.Route)*100) / 100
+		a.Distance = &distance
+	}
+	if a.EndTime == nil && a.DurationMinutes == nil {
+		// Without either, the route's own time span is the best duration there is
+		duration := int(math.Round(a.Route[len(a.Route)-1].RecordedAt.Sub(a.Route[0].RecordedAt).Minutes()))
+		a.DurationMinutes = &duration
+	}
+	return nil
+}
+
+// CheckPace rejects an activity whose distance could not have been covered in its
+// duration, such as a 5 km run logged as 2 minutes. Activities missing either are
+// not checked.
+func (a *Activity) CheckPace() error {
+	if a.Distance == nil || *a.Distance <= 0 || a.DurationMinutes == nil || *a.DurationMinutes <= 0 {
+		return nil
+	}
+
+	limit := MaxSpeedKmh(a.ActivityType)
+	speed := *a.Distance / (float64(*a.DurationMinutes) / 60)
+	if speed > limit {
+		return fmt.Errorf("%w: %g km in %d minutes is %.1f km/h, faster than the %g km/h possible for %s",
+			ErrInvalidInput, *a.Distance, *a.DurationMinutes, speed, limit, a.ActivityType)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+// Adherence tolerances, as a fraction of the target
+const (
+	// AdherenceTolerance is how far calories, carbohydrates and fat may be from target and still count as met
+	AdherenceTolerance = 0.10
+
+	// ProteinAdherenceFloor is the share of the protein target that counts as met; going over is fine
+	ProteinAdherenceFloor = 0.90
+)
+
+// MaxAdherenceRangeDays caps the range of an adherence summary
+const MaxAdherenceRangeDays = 366
+
+// DailyNutrition is the nutrition logged on one local calendar day
+type DailyNutrition struct {
+	Date          string  `json:"date"` // YYYY-MM-DD in the user's timezone
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+	MealsLogged   int     `json:"meals_logged"`
+}
+
+// DayAdherence reports which targets were met on a day
+type DayAdherence struct {
+	Date             string       `json:"date"`
+	Logged           bool         `json:"logged"`
+	Totals           MacroTargets `json:"totals"`
+	CaloriesMet      bool         `json:"calories_met"`
+	ProteinMet       bool         `json:"protein_met"`
+	CarbohydratesMet bool         `json:"carbohydrates_met"`
+	FatMet           bool         `json:"fat_met"`
+	AllMet           bool         `json:"all_met"`
+}
+
+// AdherencePercentages is the share of days in a range each target was met, 0-100
+type AdherencePercentages struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+	Overall       float64 `json:"overall"` // days on which every target was met
+}
+
+// AdherenceSummary reports how consistently a user met their targets over a date range
+type AdherenceSummary struct {
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	Timezone   string               `json:"timezone"`
+	Targets    MacroTargets         `json:"targets"`
+	Days       []DayAdherence       `json:"days"`
+	DaysLogged int                  `json:"days_logged"`
+	Adherence  AdherencePercentages `json:"adherence"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+// CacheStats counts how often a cache answered a lookup. HitRate is hits over all
+// lookups, 0 before the first one.
+type CacheStats struct {
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+	Entries int     `json:"entries"`
+}
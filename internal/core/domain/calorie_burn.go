@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a session's calories burned came from
+const (
+	CalorieSourceDevice   = "device"   // reported by a wearable or fitness app
+	CalorieSourceManual   = "manual"   // entered by the user
+	CalorieSourceEstimate = "estimate" // estimated from the duration and body weight
+)
+
+// CalorieSources lists the calorie burn sources
+var CalorieSources = []string{CalorieSourceDevice, CalorieSourceManual, CalorieSourceEstimate}
+
+// DefaultCalorieSourcePriority is the order in which overlapping burns are trusted when
+// none is configured: a device measurement over a manual entry over an estimate
+var DefaultCalorieSourcePriority = []string{CalorieSourceDevice, CalorieSourceManual, CalorieSourceEstimate}
+
+// Kinds of session that burn calories
+const (
+	BurnKindActivity = "activity"
+	BurnKindWorkout  = "workout"
+)
+
+// BurnEntry is one session's calories burned over the time it covered. End equals Start
+// when the session's duration is unknown.
+type BurnEntry struct {
+	Kind     string
+	ID       uuid.UUID
+	Start    time.Time
+	End      time.Time
+	Calories float64
+	Source   string
+}
+
+// BurnDecision records how much of a session's burn counted towards the day, and which
+// sessions it overlapped with
+type BurnDecision struct {
+	Kind            string      `json:"kind"`
+	ID              uuid.UUID   `json:"id"`
+	Source          string      `json:"source"`
+	Calories        float64     `json:"calories"`
+	CountedCalories float64     `json:"counted_calories"`
+	OverlapMinutes  float64     `json:"overlap_minutes,omitempty"`
+	OverlapsWith    []uuid.UUID `json:"overlaps_with,omitempty"`
+}
+
+// DeduplicateBurn totals the entries' calories without counting the same time twice.
+// Entries are taken in source priority order, then by most calories; an entry sharing
+// time with entries already counted only counts the share of its calories outside that
+// time. An entry without a duration counts in full unless it starts during one already
+// counted. Sources missing from priority rank last. Decisions are returned in entry order.
+func DeduplicateBurn(entries []BurnEntry, priority []string) (float64, []BurnDecision) {
+	rank := make(map[string]int, len(priority))
+	for i, source := range priority {
+		rank[source] = i
+	}
+	rankOf := func(source string) int {
+		if r, ok := rank[source]; ok {
+			return r
+		}
+		return len(priority)
+	}
+
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := entries[order[a]], entries[order[b]]
+		if ra, rb := rankOf(ea.Source), rankOf(eb.Source); ra != rb {
+			return ra < rb
+		}
+		if ea.Calories != eb.Calories {
+			return ea.Calories > eb.Calories
+		}
+		return ea.Start.Before(eb.Start)
+	})
+
+	total := 0.0
+	decisions := make([]BurnDecision, len(entries))
+	var counted []BurnEntry
+	for _, i := range order {
+		entry := entries[i]
+		decision := BurnDecision{
+			Kind:            entry.Kind,
+			ID:              entry.ID,
+			Source:          entry.Source,
+			Calories:        entry.Calories,
+			CountedCalories: entry.Calories,
+		}
+
+		duration := entry.End.Sub(entry.Start)
+		var overlaps []BurnEntry
+		for _, other := range counted {
+			if duration > 0 && entry.Start.Before(other.End) && other.Start.Before(entry.End) {
+				overlaps = append(overlaps, other)
+			} else if duration <= 0 && !entry.Start.Before(other.Start) && entry.Start.Before(other.End) {
+				overlaps = append(overlaps, other)
+			}
+		}
+
+		if len(overlaps) > 0 {
+			share := 1.0
+			if duration > 0 {
+				overlap := coveredDuration(entry.Start, entry.End, overlaps)
+				share = float64(overlap) / float64(duration)
+				decision.OverlapMinutes = math.Round(overlap.Minutes()*10) / 10
+			}
+			decision.CountedCalories = entry.Calories * (1 - share)
+			for _, other := range overlaps {
+				decision.OverlapsWith = append(decision.OverlapsWith, other.ID)
+			}
+		}
+
+		total += decision.CountedCalories
+		decisions[i] = decision
+		counted = append(counted, entry)
+	}
+	return total, decisions
+}
+
+// coveredDuration returns how much of [start, end) the entries cover, counting time
+// covered by several entries once
+func coveredDuration(start, end time.Time, entries []BurnEntry) time.Duration {
+	type span struct{ from, to time.Time }
+	spans := make([]span, 0, len(entries))
+	for _, entry := range entries {
+		from, to := entry.Start, entry.End
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if from.Before(to) {
+			spans = append(spans, span{from, to})
+		}
+	}
+	sort.Slice(spans, func(a, b int) bool { return spans[a].from.Before(spans[b].from) })
+
+	var covered time.Duration
+	var reach time.Time
+	for _, s := range spans {
+		if s.from.Before(reach) {
+			s.from = reach
+		}
+		if s.from.Before(s.to) {
+			covered += s.to.Sub(s.from)
+			reach = s.to
+		}
+	}
+	return covered
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "math"
+
+// Calorie target modes. A static target is the same every day; a dynamic one grows by a
+// share of the calories burned that day ("TDEE + exercise"), so active days allow more food.
+const (
+	CalorieTargetStatic  = "static"
+	CalorieTargetDynamic = "dynamic"
+)
+
+// CalorieTargetModes lists the supported calorie target modes
+var CalorieTargetModes = []string{CalorieTargetStatic, CalorieTargetDynamic}
+
+// DefaultExerciseCalorieFraction is the share of burned calories added to a dynamic target
+// when the user has not chosen one. Burn estimates run high, so only half is eaten back.
+const DefaultExerciseCalorieFraction = 0.5
+
+// ExerciseCalorieFractionFor returns the share of burned calories the user's target grows
+// by: 0 in static mode, their chosen fraction or the default in dynamic mode
+func ExerciseCalorieFractionFor(user *User) float64 {
+	if user.CalorieTargetMode != CalorieTargetDynamic {
+		return 0
+	}
+	if user.ExerciseCalorieFraction != nil {
+		return *user.ExerciseCalorieFraction
+	}
+	return DefaultExerciseCalorieFraction
+}
+
+// ApplyCalorieTarget sets the day's calorie target from the user's base target and, in
+// dynamic mode, the calories burned, and how many calories remain against it. Remaining
+// is negative once the target is exceeded.
+func (s *DailySummary) ApplyCalorieTarget(base float64, user *User) {
+	added := math.Round(s.TotalCaloriesBurned*ExerciseCalorieFractionFor(user)*10) / 10
+	target := base + added
+	remaining := target - s.TotalCalories
+
+	s.ExerciseCaloriesAdded = added
+	s.CalorieTarget = &target
+	s.CaloriesRemaining = &remaining
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a coach digest's tip came from
+const (
+	DigestTipSourceAI       = "ai"
+	DigestTipSourceTemplate = "template"
+)
+
+// Buckets a coach digest's data is rounded to before deciding whether it changed materially
+const (
+	digestCalorieBucket  = 100.0 // kcal
+	digestMacroBucket    = 10.0  // grams
+	digestProgressBucket = 10.0  // percentage points
+)
+
+// DigestGoal is an active goal's progress as shown on the coach digest
+type DigestGoal struct {
+	ID              uuid.UUID `json:"id"`
+	GoalType        string    `json:"goal_type"`
+	Description     string    `json:"description"`
+	ProgressPercent float64   `json:"progress_percent"`
+	OnTrack         *bool     `json:"on_track,omitempty"`
+}
+
+// CoachDigest is a short proactive status of the user's day for a home-screen
+// coach card: what is left of today's targets, whether they trained and how
+// their goals are going, with one actionable tip
+type CoachDigest struct {
+	Date            string       `json:"date"` // YYYY-MM-DD in the user's timezone
+	Targets         MacroTargets `json:"targets"`
+	Consumed        MacroTargets `json:"consumed"`
+	Remaining       MacroTargets `json:"remaining"` // never below zero
+	WorkedOut       bool         `json:"worked_out"`
+	ExerciseMinutes int          `json:"exercise_minutes"`
+	Goals           []DigestGoal `json:"goals"`
+
+	Tip            string    `json:"tip"`
+	TipSource      string    `json:"tip_source"`       // ai or template
+	TipGeneratedAt time.Time `json:"tip_generated_at"` // the tip is kept until the day's data changes materially
+}
+
+// Fingerprint summarizes the digest's data coarsely enough that logging a
+// small snack or a goal moving a point does not change it. A tip written for
+// one fingerprint still fits any digest with the same fingerprint.
+func (d *CoachDigest) Fingerprint() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%.0f|%d|%d|%d|%d|%t",
+		d.Date,
+		d.Targets.Calories,
+		bucket(d.Consumed.Calories, digestCalorieBucket),
+		bucket(d.Consumed.Protein, digestMacroBucket),
+		bucket(d.Consumed.Carbohydrates, digestMacroBucket),
+		bucket(d.Consumed.Fat, digestMacroBucket),
+		d.WorkedOut,
+	)
+	for _, goal := range d.Goals {
+		onTrack := "-"
+		if goal.OnTrack != nil {
+			onTrack = fmt.Sprint(*goal.OnTrack)
+		}
+		fmt.Fprintf(&b, "|%s:%d:%s", goal.ID, bucket(goal.ProgressPercent, digestProgressBucket), onTrack)
+	}
+	return b.String()
+}
+
+// TemplateTip picks a tip from the digest's data without an LLM: going over
+// calories first, then missing protein, then not having trained, then a goal
+// falling behind
+func (d *CoachDigest) TemplateTip() string {
+	if d.Targets.Calories > 0 && d.Consumed.Calories > d.Targets.Calories*(1+AdherenceTolerance) {
+		return "You're over today's calorie target. Keep the rest of the day light with vegetables and lean protein, or add a walk."
+	}
+	if d.Remaining.Protein >= 30 {
+		return fmt.Sprintf("You still have %.0fg of protein to go today. A protein-rich meal like chicken, fish, tofu or Greek yogurt would close the gap.", d.Remaining.Protein)
+	}
+	if !d.WorkedOut {
+		return "You haven't trained today yet. Even a 20-minute session or a brisk walk counts toward your goals."
+	}
+	for _, goal := range d.Goals {
+		if goal.OnTrack != nil && !*goal.OnTrack {
+			return fmt.Sprintf("Your goal \"%s\" is behind schedule. Pick one small step toward it today.", goal.Description)
+		}
+	}
+	return "Nice work, you're on track today. Keep it up."
+}
+
+// bucket returns which bucket of the given size value falls in
+func bucket(value, size float64) int {
+	return int(math.Floor(value / size))
+}
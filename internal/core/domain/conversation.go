@@ -1,7 +1,11 @@
 package domain
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -13,6 +17,11 @@ type Conversation struct {
 	Title     *string    `gorm:"type:varchar(255)" json:"title,omitempty"` // Auto-generated or user-set
 	Context   *string    `gorm:"type:jsonb" json:"context,omitempty"` // JSON context for the conversation
 
+	// Summary condenses the messages up to SummarizedThrough for the model's prompt. The
+	// messages themselves are kept and still listed; only the prompt uses the summary.
+	Summary           *string    `gorm:"type:text" json:"-"`
+	SummarizedThrough *time.Time `json:"-"` // CreatedAt of the last message the summary covers
+
 	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_user_conversations" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
@@ -33,7 +42,7 @@ type Message struct {
 	ConversationID uuid.UUID `gorm:"type:uuid;not null;index:idx_conversation_messages" json:"conversation_id"`
 	Role           string    `gorm:"type:varchar(50);not null" json:"role"` // user, assistant, system
 	Content        string    `gorm:"type:text;not null" json:"content"`
-	Metadata       *string   `gorm:"type:jsonb" json:"metadata,omitempty"` // Additional metadata (e.g., tokens, model)
+	Metadata       *string   `gorm:"type:jsonb" json:"metadata,omitempty"` // MessageMetadata as JSON; read and write it with GetMetadata and SetMetadata
 
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_conversation_messages" json:"created_at"`
 
@@ -45,3 +54,107 @@ type Message struct {
 func (Message) TableName() string {
 	return "messages"
 }
+
+// MessageMetadata records how an assistant message was produced: the model that wrote it,
+// the tools it called and what the turn cost. Token counts and cost add up every
+// completion of the turn, tool rounds included.
+type MessageMetadata struct {
+	Model            string   `json:"model,omitempty"`
+	ToolsUsed        []string `json:"tools_used,omitempty"`
+	Moderated        bool     `json:"moderated,omitempty"` // the reply was redacted by the content moderator
+	PromptTokens     int      `json:"prompt_tokens,omitempty"`
+	CompletionTokens int      `json:"completion_tokens,omitempty"`
+	TotalTokens      int      `json:"total_tokens,omitempty"`
+	CostUSD          *float64 `json:"cost_usd,omitempty"` // nil when the provider did not report a cost
+
+	// The meal the agent held for the user to confirm in this turn; nil when none
+	PendingMealID *uuid.UUID `json:"pending_meal_id,omitempty"`
+}
+
+// IsZero reports whether nothing is recorded in the metadata
+func (m *MessageMetadata) IsZero() bool {
+	return m.Model == "" && len(m.ToolsUsed) == 0 && !m.Moderated &&
+		m.PromptTokens == 0 && m.CompletionTokens == 0 && m.TotalTokens == 0 && m.CostUSD == nil && m.PendingMealID == nil
+}
+
+// GetMetadata decodes the message's metadata. A message without metadata has an empty one.
+func (m *Message) GetMetadata() (*MessageMetadata, error) {
+	metadata := &MessageMetadata{}
+	if m.Metadata == nil || *m.Metadata == "" {
+		return metadata, nil
+	}
+	if err := json.Unmarshal([]byte(*m.Metadata), metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode message metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// SetMetadata encodes metadata onto the message. Empty metadata clears it.
+func (m *Message) SetMetadata(metadata *MessageMetadata) error {
+	if metadata == nil || metadata.IsZero() {
+		m.Metadata = nil
+		return nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode message metadata: %w", err)
+	}
+	value := string(encoded)
+	m.Metadata = &value
+	return nil
+}
+
+// EstimateTokens roughly counts the tokens text costs in a prompt, at about four characters
+// a token. It only has to be good enough to tell when a history is getting long.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// ConversationPreviewLength is how many characters of the last message a conversation preview shows
+const ConversationPreviewLength = 120
+
+// ConversationPreview is a conversation as listed in the chat list
+type ConversationPreview struct {
+	ID              uuid.UUID `json:"id"`
+	Title           *string   `json:"title,omitempty"`
+	LastMessage     *string   `json:"last_message,omitempty"`      // start of the latest message; nil without messages
+	LastMessageRole *string   `json:"last_message_role,omitempty"` // user or assistant
+	MessageCount    int       `json:"message_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"` // time of the latest message, or of creation
+}
+
+// ConversationActivity is the message count and latest message of a conversation
+type ConversationActivity struct {
+	MessageCount int
+	LastMessage  *Message
+}
+
+// NewConversationPreview builds the preview of a conversation from its activity
+func NewConversationPreview(conversation *Conversation, activity ConversationActivity) ConversationPreview {
+	preview := ConversationPreview{
+		ID:           conversation.ID,
+		Title:        conversation.Title,
+		MessageCount: activity.MessageCount,
+		CreatedAt:    conversation.CreatedAt,
+		UpdatedAt:    conversation.UpdatedAt,
+	}
+	if message := activity.LastMessage; message != nil {
+		text := PreviewText(message.Content, ConversationPreviewLength)
+		role := message.Role
+		preview.LastMessage = &text
+		preview.LastMessageRole = &role
+	}
+	return preview
+}
+
+// PreviewText collapses whitespace in text and cuts it to at most length characters,
+// ending a cut preview with an ellipsis
+func PreviewText(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return strings.TrimRight(string(runes[:length-1]), " ") + "…"
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DemoSeedDays is how many days of sample data the demo seed adds, ending today
+const DemoSeedDays = 5
+
+// DemoData is the sample data the demo seed adds to an account. Every record is flagged IsDemo.
+type DemoData struct {
+	Meals      []*Meal
+	Activities []*Activity
+	Workouts   []*Workout
+	Metrics    []*Metric
+}
+
+// DemoSeedResult counts the sample records added or removed by the demo seed
+type DemoSeedResult struct {
+	Meals      int `json:"meals"`
+	Activities int `json:"activities"`
+	Workouts   int `json:"workouts"`
+	Metrics    int `json:"metrics"`
+}
+
+// Counts returns how many records of each kind the data holds
+func (d *DemoData) Counts() *DemoSeedResult {
+	return &DemoSeedResult{
+		Meals:      len(d.Meals),
+		Activities: len(d.Activities),
+		Workouts:   len(d.Workouts),
+		Metrics:    len(d.Metrics),
+	}
+}
+
+// demoMeal is a sample meal: its type, the hour it is eaten and its nutrition
+type demoMeal struct {
+	mealType, name                string
+	hour                          int
+	calories, protein, carbs, fat float64
+	saturatedFat, sodium          float64
+}
+
+// demoMenus alternate from day to day so the dashboards do not repeat a single day
+var demoMenus = [][]demoMeal{
+	{
+		{"breakfast", "Oatmeal with berries", 8, 380, 14, 62, 8, 1.5, 150},
+		{"lunch", "Grilled chicken salad", 13, 520, 42, 28, 24, 4.5, 780},
+		{"snack", "Greek yogurt with honey", 16, 210, 17, 24, 5, 3.0, 70},
+		{"dinner", "Salmon with rice and broccoli", 19, 640, 40, 62, 22, 4.0, 520},
+	},
+	{
+		{"breakfast", "Scrambled eggs on toast", 8, 420, 24, 32, 21, 6.5, 610},
+		{"lunch", "Turkey and avocado wrap", 13, 560, 35, 48, 24, 5.0, 1050},
+		{"snack", "Apple with peanut butter", 16, 260, 7, 30, 14, 2.5, 140},
+		{"dinner", "Beef stir-fry with noodles", 19, 690, 38, 74, 24, 7.0, 1180},
+	},
+	{
+		{"breakfast", "Protein smoothie", 8, 340, 30, 42, 6, 1.0, 180},
+		{"lunch", "Lentil soup with bread", 13, 480, 24, 70, 10, 1.5, 950},
+		{"snack", "Trail mix", 16, 230, 6, 20, 15, 2.0, 90},
+		{"dinner", "Chicken pasta with tomato sauce", 19, 710, 45, 86, 18, 5.5, 890},
+	},
+}
+
+// BuildDemoData builds DemoSeedDays days of sample meals, activities, workouts and weigh-ins
+// for the user, ending on today's date in loc. Nothing is placed after now, so today only
+// holds what would already have happened. Weigh-ins trend down from weightKg, or from
+// DefaultBodyWeightKg when it is not positive.
+func BuildDemoData(userID uuid.UUID, now time.Time, loc *time.Location, weightKg float64) *DemoData {
+	if weightKg <= 0 {
+		weightKg = DefaultBodyWeightKg
+	}
+
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	data := &DemoData{}
+
+	for i := 0; i < DemoSeedDays; i++ {
+		daysAgo := DemoSeedDays - 1 - i
+		day := today.AddDate(0, 0, -daysAgo)
+		at := func(hour, minute int) time.Time {
+			return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		}
+
+		// The weigh-in before breakfast; the user is slightly lighter each day
+		if weighIn := at(7, 0); !weighIn.After(now) {
+			data.Metrics = append(data.Metrics, &Metric{
+				UserID:     userID,
+				MetricType: "weight",
+				Value:      weightKg + 0.2*float64(daysAgo),
+				Unit:       "kg",
+				MeasuredAt: weighIn,
+				IsDemo:     true,
+			})
+		}
+
+		for _, sample := range demoMenus[i%len(demoMenus)] {
+			consumedAt := at(sample.hour, 0)
+			if consumedAt.After(now) {
+				continue
+			}
+			data.Meals = append(data.Meals, &Meal{
+				UserID:             userID,
+				Name:               sample.name,
+				MealType:           sample.mealType,
+				ConsumedAt:         consumedAt,
+				TotalCalories:      sample.calories,
+				TotalProtein:       sample.protein,
+				TotalCarbohydrates: sample.carbs,
+				TotalFat:           sample.fat,
+				TotalSaturatedFat:  sample.saturatedFat,
+				TotalSodium:        sample.sodium,
+				IsDemo:             true,
+			})
+		}
+
+		// A lunchtime walk every day
+		walkStart, walkMinutes := at(12, 15), 30+5*(i%3)
+		if walkEnd := walkStart.Add(time.Duration(walkMinutes) * time.Minute); !walkEnd.After(now) {
+			distance := float64(walkMinutes) * 0.09
+			calories := float64(walkMinutes) * 4.5
+			steps := walkMinutes * 110
+			data.Activities = append(data.Activities, &Activity{
+				UserID:          userID,
+				ActivityType:    "walking",
+				StartTime:       walkStart,
+				EndTime:         &walkEnd,
+				DurationMinutes: &walkMinutes,
+				Distance:        &distance,
+				CaloriesBurned:  &calories,
+				Steps:           &steps,
+				IsDemo:          true,
+			})
+		}
+
+		// Gym sessions on alternate evenings
+		start, minutes := at(17, 30), 50
+		if end := start.Add(time.Duration(minutes) * time.Minute); i%2 == 1 && !end.After(now) {
+			name := "Upper body"
+			if i%4 == 3 {
+				name = "Lower body"
+			}
+			workout := &Workout{
+				UserID:          userID,
+				Name:            name,
+				StartTime:       start,
+				EndTime:         &end,
+				DurationMinutes: &minutes,
+				IsDemo:          true,
+			}
+			calories := EstimateWorkoutCalories(workout, weightKg)
+			workout.CaloriesBurned = &calories
+			data.Workouts = append(data.Workouts, workout)
+		}
+	}
+
+	return data
+}
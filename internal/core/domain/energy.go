@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Values used to estimate the burn of workouts without a logged one
+const (
+	// DefaultBodyWeightKg is used to estimate workout burn when the profile has no weight
+	DefaultBodyWeightKg = 70.0
+
+	// StrengthTrainingMET is general resistance training at moderate effort
+	StrengthTrainingMET = 5.0
+
+	// CardioMET is running, cycling or rowing at a moderate pace
+	CardioMET = 8.0
+
+	// kcalPerKgPerKm is the approximate cost of covering distance on foot
+	kcalPerKgPerKm = 1.0
+)
+
+// ActivityMultipliers scale BMR to total daily energy expenditure per activity level
+var ActivityMultipliers = map[string]float64{
+	"sedentary":         1.2,
+	"lightly_active":    1.375,
+	"moderately_active": 1.55,
+	"very_active":       1.725,
+	"extremely_active":  1.9,
+}
+
+// CalculateBMR estimates basal metabolic rate with the Mifflin-St Jeor equation.
+// It returns nil when the profile lacks weight, height or date of birth.
+// Without a male/female gender the midpoint of the two sex constants is used.
+func CalculateBMR(user *User, at time.Time) *float64 {
+	if user.WeightKg == nil || user.HeightCm == nil || user.DateOfBirth == nil {
+		return nil
+	}
+	if *user.WeightKg <= 0 || *user.HeightCm <= 0 {
+		return nil
+	}
+
+	age := ageAt(*user.DateOfBirth, at)
+	if age <= 0 {
+		return nil
+	}
+
+	bmr := 10**user.WeightKg + 6.25**user.HeightCm - 5*float64(age)
+	gender := ""
+	if user.Gender != nil {
+		gender = strings.ToLower(*user.Gender)
+	}
+	switch gender {
+	case "male", "m":
+		bmr += 5
+	case "female", "f":
+		bmr -= 161
+	default:
+		bmr -= 78
+	}
+
+	return &bmr
+}
+
+// CalculateTDEE estimates total daily energy expenditure from BMR and activity level.
+// A missing or unknown activity level is treated as sedentary.
+func CalculateTDEE(user *User, at time.Time) *float64 {
+	bmr := CalculateBMR(user, at)
+	if bmr == nil {
+		return nil
+	}
+
+	multiplier := ActivityMultipliers["sedentary"]
+	if user.ActivityLevel != nil {
+		if m, ok := ActivityMultipliers[*user.ActivityLevel]; ok {
+			multiplier = m
+		}
+	}
+
+	tdee := *bmr * multiplier
+	return &tdee
+}
+
+// EstimateWorkoutCalories estimates the burn of a workout that has no logged calories.
+// Cardio sets are estimated from their own duration or distance; the remaining
+// session time is treated as resistance training.
+// Formula: kcal = MET x body weight (kg) x hours
+func EstimateWorkoutCalories(workout *Workout, weightKg float64) float64 {
+	if workout == nil {
+		return 0
+	}
+	if weightKg <= 0 {
+		weightKg = DefaultBodyWeightKg
+	}
+
+	totalMinutes := 0.0
+	if workout.DurationMinutes != nil {
+		totalMinutes = float64(*workout.DurationMinutes)
+	} else if workout.EndTime != nil {
+		totalMinutes = workout.EndTime.Sub(workout.StartTime).Minutes()
+	}
+
+	cardioCalories := 0.0
+	cardioMinutes := 0.0
+	for _, exercise := range workout.Exercises {
+		if exercise.Exercise.Category != "cardio" {
+			continue
+		}
+		for _, set := range exercise.Sets {
+			switch {
+			case set.DurationSeconds != nil && *set.DurationSeconds > 0:
+				minutes := float64(*set.DurationSeconds) / 60.0
+				cardioMinutes += minutes
+				cardioCalories += CardioMET * weightKg * (minutes / 60.0)
+			case set.Distance != nil && *set.Distance > 0:
+				// Distance is stored in meters
+				cardioCalories += kcalPerKgPerKm * weightKg * (*set.Distance / 1000.0)
+			}
+		}
+	}
+
+	strengthMinutes := math.Max(totalMinutes-cardioMinutes, 0)
+	strengthCalories := StrengthTrainingMET * weightKg * (strengthMinutes / 60.0)
+
+	return math.Round((cardioCalories+strengthCalories)*100) / 100
+}
+
+// ageAt returns the age in whole years on the given date
+func ageAt(dateOfBirth, at time.Time) int {
+	age := at.Year() - dateOfBirth.Year()
+	if at.Month() < dateOfBirth.Month() || (at.Month() == dateOfBirth.Month() && at.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
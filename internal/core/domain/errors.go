@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Common domain errors
 var (
@@ -27,4 +31,42 @@ var (
 
 	// ErrInvalidCredentials indicates invalid login credentials
 	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrInvalidToken indicates a reset/verification token is unknown, expired or already used
+	ErrInvalidToken = errors.New("invalid or expired token")
+
+	// ErrEmailAlreadyVerified indicates the user's email has already been verified
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+
+	// ErrRateLimited indicates an upstream provider is throttling requests
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrAIUnavailable indicates a feature needs AI but no AI provider is configured
+	ErrAIUnavailable = errors.New("AI is not configured")
+
+	// ErrLowConfidence indicates a meal was parsed with too little confidence to offer for confirmation
+	ErrLowConfidence = errors.New("couldn't confidently parse the meal, please enter it manually")
 )
+
+// RateLimitError reports that an upstream provider rejected a request for rate limiting.
+// RetryAfter is how long the provider asked callers to wait, or 0 if it gave no hint.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *RateLimitError) Error() string {
+	msg := ErrRateLimited.Error()
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+	return msg
+}
+
+// Unwrap lets errors.Is match ErrRateLimited
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,12 +17,39 @@ type Exercise struct {
 	Equipment   *string   `gorm:"type:varchar(100)" json:"equipment,omitempty"` // barbell, dumbbell, bodyweight, etc.
 	Difficulty  *string   `gorm:"type:varchar(50)" json:"difficulty,omitempty"` // beginner, intermediate, advanced
 
+	// CanonicalID marks the exercise as an alias (e.g. "Barbell Bench Press" of "Bench Press");
+	// its sets count toward the canonical exercise's history and personal records
+	CanonicalID *uuid.UUID `gorm:"type:uuid;index" json:"canonical_id,omitempty"`
+
 	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Warnings name likely duplicates found when the exercise was created; they are not stored
+	Warnings []string `gorm:"-" json:"warnings,omitempty"`
 }
 
 // TableName specifies the table name for GORM
 func (Exercise) TableName() string {
 	return "exercises"
 }
+
+// CanonicalExerciseID returns the exercise the sets of this one are tracked under:
+// the canonical exercise for an alias, the exercise itself otherwise
+func (e *Exercise) CanonicalExerciseID() uuid.UUID {
+	if e.CanonicalID != nil {
+		return *e.CanonicalID
+	}
+	return e.ID
+}
+
+// NormalizeExerciseName trims an exercise name and collapses runs of whitespace,
+// keeping the user's casing
+func NormalizeExerciseName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// SameExerciseName reports whether two names are the same once normalized, ignoring case
+func SameExerciseName(a, b string) bool {
+	return strings.EqualFold(NormalizeExerciseName(a), NormalizeExerciseName(b))
+}
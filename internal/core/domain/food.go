@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,6 +38,10 @@ type Food struct {
 	// Metadata
 	IsVerified bool       `gorm:"not null;default:false" json:"is_verified"`
 	Source     *string    `gorm:"type:varchar(100)" json:"source,omitempty"` // e.g., "usda", "user", "manual"
+	IsStarred  bool       `gorm:"-" json:"is_starred"` // set per requesting user, see FoodStar
+
+	// Grams of protein per 100 kcal, set on search results; absent for foods without calories
+	ProteinDensity *float64 `gorm:"-" json:"protein_density,omitempty"`
 
 	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
@@ -50,6 +57,44 @@ func (Food) TableName() string {
 	return "foods"
 }
 
+// Orders food search results can be sorted in
+const (
+	FoodSortRelevance      = "relevance"       // best match first
+	FoodSortProteinDensity = "protein_density" // most protein per calorie first
+	FoodSortCalories       = "calories"        // fewest calories per serving first
+)
+
+// FoodSearchFilter narrows a food search
+type FoodSearchFilter struct {
+	VerifiedOnly bool   // only foods with verified nutrition data
+	Sort         string // one of the FoodSort orders; empty is relevance
+}
+
+// CalculateProteinDensity returns the food's grams of protein per 100 kcal, or nil when
+// it has no calories to compare against
+func (f *Food) CalculateProteinDensity() *float64 {
+	if f.Calories <= 0 {
+		return nil
+	}
+	density := f.Protein / f.Calories * 100
+	return &density
+}
+
+// FoodSearchKey identifies the results of one food search page for caching. Query is
+// normalized: lower case with single spaces between words.
+type FoodSearchKey struct {
+	Query  string
+	Filter FoodSearchFilter
+	Limit  int
+	Offset int
+}
+
+// NormalizeFoodQuery lower-cases a search query and collapses its whitespace, so queries
+// differing only in case or spacing share cached results
+func NormalizeFoodQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
 // FoodIngredient represents an ingredient in a composite food
 type FoodIngredient struct {
 	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -87,6 +132,44 @@ func (ServingUnit) TableName() string {
 	return "serving_units"
 }
 
+// Serving unit categories
+const (
+	ServingUnitCategoryWeight = "weight"
+	ServingUnitCategoryVolume = "volume"
+	ServingUnitCategoryCount  = "count"
+)
+
+// ServingUnitCategories lists the serving unit categories in display order
+var ServingUnitCategories = []string{ServingUnitCategoryWeight, ServingUnitCategoryVolume, ServingUnitCategoryCount}
+
+// ServingUnitGroup is the serving units of one category
+type ServingUnitGroup struct {
+	Category string         `json:"category"`
+	Units    []*ServingUnit `json:"units"`
+}
+
+// GroupServingUnits groups units by category, keeping their order within each group.
+// Known categories come first in ServingUnitCategories order, then any others by name.
+func GroupServingUnits(units []*ServingUnit) []ServingUnitGroup {
+	byCategory := make(map[string][]*ServingUnit)
+	var others []string
+	for _, unit := range units {
+		if _, ok := byCategory[unit.Category]; !ok && !slices.Contains(ServingUnitCategories, unit.Category) {
+			others = append(others, unit.Category)
+		}
+		byCategory[unit.Category] = append(byCategory[unit.Category], unit)
+	}
+	sort.Strings(others)
+
+	groups := make([]ServingUnitGroup, 0, len(byCategory))
+	for _, category := range append(slices.Clone(ServingUnitCategories), others...) {
+		if units, ok := byCategory[category]; ok {
+			groups = append(groups, ServingUnitGroup{Category: category, Units: units})
+		}
+	}
+	return groups
+}
+
 // FoodServingConversion represents conversion factors for different serving sizes
 type FoodServingConversion struct {
 	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -105,3 +188,12 @@ type FoodServingConversion struct {
 func (FoodServingConversion) TableName() string {
 	return "food_serving_conversions"
 }
+
+// RecentFood is a food from the user's meal history with how they usually log it
+type RecentFood struct {
+	Food            Food      `json:"food"`
+	TimesLogged     int       `json:"times_logged"`
+	LastLoggedAt    time.Time `json:"last_logged_at"`
+	TypicalQuantity float64   `json:"typical_quantity"` // most common quantity used
+	TypicalUnit     string    `json:"typical_unit"`     // most common unit used
+}
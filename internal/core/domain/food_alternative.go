@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Weights of the nutrient-density score, per gram per 100 kcal. Fiber and protein raise
+// the score; sugar and saturated fat lower it.
+const (
+	nutrientDensityProteinWeight      = 1.0
+	nutrientDensityFiberWeight        = 2.0
+	nutrientDensitySugarWeight        = -1.0
+	nutrientDensitySaturatedFatWeight = -1.0
+)
+
+// Smallest difference per 100 kcal, in grams, reported as a reason for a swap
+const (
+	swapReasonMinProtein      = 1.0
+	swapReasonMinFiber        = 0.5
+	swapReasonMinSugar        = 1.0
+	swapReasonMinSaturatedFat = 0.5
+)
+
+// NutrientDensity is a food's nutrients per 100 kcal, which compares foods regardless
+// of their serving sizes
+type NutrientDensity struct {
+	Protein      float64 `json:"protein"`
+	Fiber        float64 `json:"fiber"`
+	Sugar        float64 `json:"sugar"`
+	SaturatedFat float64 `json:"saturated_fat"`
+	Score        float64 `json:"score"`
+}
+
+// FoodAlternative is a food suggested as a healthier swap
+type FoodAlternative struct {
+	Food    *Food           `json:"food"`
+	Density NutrientDensity `json:"density"`
+	Reasons []string        `json:"reasons"` // e.g. "higher protein per calorie"
+}
+
+// FoodAlternatives are the swaps suggested for a food, best first. Alternatives is
+// empty with a Note when the food cannot be compared, e.g. it has no category.
+type FoodAlternatives struct {
+	Food         *Food             `json:"food"`
+	Density      *NutrientDensity  `json:"density,omitempty"`
+	Alternatives []FoodAlternative `json:"alternatives"`
+	Note         string            `json:"note,omitempty"`
+}
+
+// FoodNutrientDensity scores a food by its nutrients per 100 kcal. It reports false for
+// foods without calories, which have no meaningful density. Missing nutrients count as 0.
+func FoodNutrientDensity(food *Food) (NutrientDensity, bool) {
+	if food.Calories <= 0 {
+		return NutrientDensity{}, false
+	}
+
+	per100 := func(grams float64) float64 {
+		return math.Round(grams/food.Calories*100*10) / 10
+	}
+	optional := func(grams *float64) float64 {
+		if grams == nil {
+			return 0
+		}
+		return per100(*grams)
+	}
+
+	density := NutrientDensity{
+		Protein:      per100(food.Protein),
+		Fiber:        optional(food.Fiber),
+		Sugar:        optional(food.Sugar),
+		SaturatedFat: optional(food.SaturatedFat),
+	}
+	score := density.Protein*nutrientDensityProteinWeight +
+		density.Fiber*nutrientDensityFiberWeight +
+		density.Sugar*nutrientDensitySugarWeight +
+		density.SaturatedFat*nutrientDensitySaturatedFatWeight
+	density.Score = math.Round(score*10) / 10
+	return density, true
+}
+
+// RankFoodAlternatives returns up to limit candidates that score higher than source,
+// best first. The source itself and foods without calories are skipped.
+func RankFoodAlternatives(source *Food, candidates []*Food, limit int) []FoodAlternative {
+	alternatives := []FoodAlternative{}
+	base, ok := FoodNutrientDensity(source)
+	if !ok {
+		return alternatives
+	}
+
+	for _, candidate := range candidates {
+		if candidate.ID == source.ID {
+			continue
+		}
+		density, ok := FoodNutrientDensity(candidate)
+		if !ok || density.Score <= base.Score {
+			continue
+		}
+		alternatives = append(alternatives, FoodAlternative{
+			Food:    candidate,
+			Density: density,
+			Reasons: swapReasons(base, density),
+		})
+	}
+
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		if alternatives[i].Density.Score != alternatives[j].Density.Score {
+			return alternatives[i].Density.Score > alternatives[j].Density.Score
+		}
+		return strings.ToLower(alternatives[i].Food.Name) < strings.ToLower(alternatives[j].Food.Name)
+	})
+	if limit > 0 && len(alternatives) > limit {
+		alternatives = alternatives[:limit]
+	}
+	return alternatives
+}
+
+// swapReasons names what makes the alternative better than the source
+func swapReasons(source, alternative NutrientDensity) []string {
+	reasons := []string{}
+	if alternative.Protein-source.Protein >= swapReasonMinProtein {
+		reasons = append(reasons, "higher protein per calorie")
+	}
+	if alternative.Fiber-source.Fiber >= swapReasonMinFiber {
+		reasons = append(reasons, "more fiber per calorie")
+	}
+	if source.Sugar-alternative.Sugar >= swapReasonMinSugar {
+		reasons = append(reasons, "less sugar per calorie")
+	}
+	if source.SaturatedFat-alternative.SaturatedFat >= swapReasonMinSaturatedFat {
+		reasons = append(reasons, "less saturated fat per calorie")
+	}
+	return reasons
+}
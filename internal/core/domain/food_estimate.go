@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"math"
+	"strings"
+)
+
+// Where a food estimate's nutrition came from
+const (
+	FoodEstimateSourceAI      = "ai"      // estimated by the model
+	FoodEstimateSourceBuiltin = "builtin" // the built-in table of common foods
+)
+
+// Limits for estimating a freeform food
+const (
+	MaxFoodEstimateNameLength = 200
+	DefaultServingGrams       = 100.0 // weight of a serving when the estimate gives none
+)
+
+// estimateUnitGrams is how many grams one of a weight or volume unit is. Volumes are
+// taken at the density of water; any other unit counts pieces or servings.
+var estimateUnitGrams = map[string]float64{
+	"g": 1, "gram": 1, "grams": 1, "kg": 1000, "oz": 28.35, "lb": 453.6,
+	"ml": 1, "l": 1000, "cup": 240, "cups": 240, "tbsp": 15, "tsp": 5,
+}
+
+// FoodEstimate is the estimated nutrition of an amount of a food described in free
+// text. It is never saved as a food, and IsEstimate is always true so clients can
+// label it as such.
+type FoodEstimate struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Grams    float64 `json:"grams"` // the weight the nutrition below is for
+
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+	Fiber         float64 `json:"fiber"`
+
+	Per100g    NutritionEstimate `json:"per_100g"`
+	Source     string            `json:"source"`
+	IsEstimate bool              `json:"is_estimate"`
+}
+
+// NewFoodEstimate scales per-100g nutrition to quantity of unit. Weights and volumes
+// convert directly; pieces and servings use the estimate's typical serving weight.
+func NewFoodEstimate(name string, quantity float64, unit, source string, per100g NutritionEstimate) *FoodEstimate {
+	grams := quantity * servingGrams(per100g)
+	if perUnit, ok := estimateUnitGrams[strings.ToLower(strings.TrimSpace(unit))]; ok {
+		grams = quantity * perUnit
+	}
+	factor := grams / 100
+
+	return &FoodEstimate{
+		Name:          name,
+		Quantity:      quantity,
+		Unit:          unit,
+		Grams:         roundTenth(grams),
+		Calories:      roundTenth(per100g.CaloriesPer100g * factor),
+		Protein:       roundTenth(per100g.ProteinPer100g * factor),
+		Carbohydrates: roundTenth(per100g.CarbsPer100g * factor),
+		Fat:           roundTenth(per100g.FatPer100g * factor),
+		Fiber:         roundTenth(per100g.FiberPer100g * factor),
+		Per100g:       per100g,
+		Source:        source,
+		IsEstimate:    true,
+	}
+}
+
+// servingGrams returns the estimate's typical serving weight, or DefaultServingGrams
+func servingGrams(per100g NutritionEstimate) float64 {
+	if per100g.GramsPerServing > 0 {
+		return per100g.GramsPerServing
+	}
+	return DefaultServingGrams
+}
+
+// roundTenth rounds to one decimal place
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
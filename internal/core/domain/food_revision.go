@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FoodRevision records the fields one update changed on a food, with their
+// values before and after. Logged meals keep the nutrition stored on their
+// meal food items, so a revision never alters past meal totals.
+type FoodRevision struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FoodID           uuid.UUID  `gorm:"type:uuid;not null;index:idx_food_revisions_food" json:"food_id"`
+	EditedBy         *uuid.UUID `gorm:"type:uuid" json:"edited_by,omitempty"`      // nil for system edits or deleted users
+	ChangedFields    string     `gorm:"type:jsonb;not null" json:"changed_fields"` // JSON array of field names
+	Before           string     `gorm:"type:jsonb;not null" json:"before"`         // JSON object of the changed fields' old values
+	After            string     `gorm:"type:jsonb;not null" json:"after"`          // JSON object of the changed fields' new values
+	NutritionChanged bool       `gorm:"not null;default:false" json:"nutrition_changed"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_food_revisions_food" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (FoodRevision) TableName() string {
+	return "food_revisions"
+}
+
+// foodRevisionIgnoredFields are bookkeeping fields that do not count as edits
+var foodRevisionIgnoredFields = map[string]bool{
+	"id":                  true,
+	"created_at":          true,
+	"updated_at":          true,
+	"deleted_at":          true,
+	"ingredients":         true,
+	"serving_conversions": true,
+	"is_starred":          true,
+}
+
+// foodNutritionFields change what a logged serving of the food is worth
+var foodNutritionFields = map[string]bool{
+	"serving_size":  true,
+	"serving_unit":  true,
+	"calories":      true,
+	"protein":       true,
+	"carbohydrates": true,
+	"fat":           true,
+	"fiber":         true,
+	"sugar":         true,
+	"saturated_fat": true,
+	"trans_fat":     true,
+	"cholesterol":   true,
+	"sodium":        true,
+	"potassium":     true,
+}
+
+// NewFoodRevision diffs a food before and after an update, keyed by JSON field
+// name. It returns nil when nothing but bookkeeping fields changed.
+func NewFoodRevision(before, after *Food, editedBy *uuid.UUID) (*FoodRevision, error) {
+	beforeFields, err := foodFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := foodFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for name := range unionKeys(beforeFields, afterFields) {
+		if foodRevisionIgnoredFields[name] {
+			continue
+		}
+		if !reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(changed)
+
+	revision := &FoodRevision{
+		FoodID:   after.ID,
+		EditedBy: editedBy,
+	}
+	oldValues := make(map[string]interface{}, len(changed))
+	newValues := make(map[string]interface{}, len(changed))
+	for _, name := range changed {
+		oldValues[name] = beforeFields[name]
+		newValues[name] = afterFields[name]
+		if foodNutritionFields[name] {
+			revision.NutritionChanged = true
+		}
+	}
+
+	changedJSON, err := json.Marshal(changed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode changed fields: %w", err)
+	}
+	oldJSON, err := json.Marshal(oldValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode previous values: %w", err)
+	}
+	newJSON, err := json.Marshal(newValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new values: %w", err)
+	}
+	revision.ChangedFields = string(changedJSON)
+	revision.Before = string(oldJSON)
+	revision.After = string(newJSON)
+
+	return revision, nil
+}
+
+// foodFields returns the food's JSON fields; omitted optional fields are absent
+func foodFields(food *Food) (map[string]interface{}, error) {
+	data, err := json.Marshal(food)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode food: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode food: %w", err)
+	}
+	return fields, nil
+}
+
+func unionKeys(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
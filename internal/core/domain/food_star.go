@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FoodStar marks a food the user starred for quick adding. Any food can be starred,
+// whoever created it, and starring is independent of the meals it is logged in.
+type FoodStar struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	FoodID uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_food_stars_food" json:"food_id"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (FoodStar) TableName() string {
+	return "food_stars"
+}
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,11 +30,91 @@ type Goal struct {
 	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
 
+	// Summary is computed when goals are listed; it is not stored
+	Summary *GoalSummary `gorm:"-" json:"summary,omitempty"`
+
+	// Warnings flag likely mistakes when the goal is saved, such as macro goals that do
+	// not add up to the calorie goal; they never block the save and are not stored
+	Warnings []string `gorm:"-" json:"warnings,omitempty"`
+
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
 
+// GoalSummary is the actionable status of a goal, derived from its type and the user's data.
+// Fields that do not apply to the goal type are omitted.
+type GoalSummary struct {
+	ProgressPercent float64  `json:"progress_percent"`
+	CurrentValue    *float64 `json:"current_value,omitempty"`
+	Remaining       *float64 `json:"remaining,omitempty"` // in the goal unit (kg for weight goals, workouts for frequency goals)
+	OnTrack         *bool    `json:"on_track,omitempty"`
+	ExpectedValue   *float64 `json:"expected_value,omitempty"` // where a linear schedule to the target date puts the goal today
+	WeekCount       *int     `json:"week_count,omitempty"`     // workouts since Monday
+	WeeklyTarget    *int     `json:"weekly_target,omitempty"`
+}
+
 // TableName specifies the table name for GORM
 func (Goal) TableName() string {
 	return "goals"
 }
+
+// MacroSplitTolerance is how far, as a fraction of the calorie goal, the calories implied by
+// the macro goals may be from it before the goals are flagged as inconsistent
+const MacroSplitTolerance = 0.1
+
+// Calories per gram of each macronutrient
+const (
+	CaloriesPerGramProtein       = 4.0
+	CaloriesPerGramCarbohydrates = 4.0
+	CaloriesPerGramFat           = 9.0
+)
+
+// CheckMacroSplit compares the calories implied by daily macro goals with the calorie goal,
+// treating zero values as goals that are not set. With all three macros set their calories
+// must be within MacroSplitTolerance of the calorie goal; with only some set they must not
+// exceed it. It returns a warning for the user, or "" when the goals are consistent.
+func CheckMacroSplit(targets MacroTargets) string {
+	if targets.Calories <= 0 {
+		return ""
+	}
+
+	var names []string
+	implied := 0.0
+	for _, macro := range []struct {
+		name           string
+		grams, perGram float64
+	}{
+		{"protein", targets.Protein, CaloriesPerGramProtein},
+		{"carbohydrate", targets.Carbohydrates, CaloriesPerGramCarbohydrates},
+		{"fat", targets.Fat, CaloriesPerGramFat},
+	} {
+		if macro.grams > 0 {
+			names = append(names, macro.name)
+			implied += macro.grams * macro.perGram
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	goals := strings.Join(names, " and ")
+	if len(names) == 3 {
+		goals = "protein, carbohydrate and fat"
+	}
+
+	difference := implied - targets.Calories
+	tolerance := targets.Calories * MacroSplitTolerance
+	switch {
+	case len(names) < 3 && difference > tolerance:
+		return fmt.Sprintf("Your %s goals alone add up to %.0f kcal, more than your %.0f kcal calorie goal.",
+			goals, implied, targets.Calories)
+	case len(names) == 3 && math.Abs(difference) > tolerance:
+		direction := "more"
+		if difference < 0 {
+			direction = "less"
+		}
+		return fmt.Sprintf("Your %s goals add up to %.0f kcal, %.0f%% %s than your %.0f kcal calorie goal.",
+			goals, implied, math.Abs(difference)/targets.Calories*100, direction, targets.Calories)
+	}
+	return ""
+}
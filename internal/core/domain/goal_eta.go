@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Outcomes of a goal ETA estimate
+const (
+	GoalETAOnTrack          = "on_track"          // moving toward the target; EstimatedDate is set
+	GoalETAReached          = "reached"           // the latest weigh-in is at or past the target
+	GoalETAFlat             = "flat"              // too slow to reach the target within MaxGoalETAWeeks
+	GoalETAWrongDirection   = "wrong_direction"   // moving away from the target
+	GoalETAInsufficientData = "insufficient_data" // fewer than two weigh-ins on different days
+)
+
+// Confidence in a goal ETA, from how many weigh-ins it rests on and how far apart they are
+const (
+	GoalETAConfidenceLow    = "low"
+	GoalETAConfidenceMedium = "medium"
+	GoalETAConfidenceHigh   = "high"
+)
+
+// MaxGoalETAWeeks is the furthest an ETA is projected; a slower trend counts as flat
+const MaxGoalETAWeeks = 104
+
+// TrendPoint is one measurement of a trend
+type TrendPoint struct {
+	At    time.Time
+	Value float64
+}
+
+// GoalETA projects when a weight goal will be reached at the current trend.
+// Weights and rates are in the goal's unit.
+type GoalETA struct {
+	Status         string     `json:"status"`
+	Current        float64    `json:"current"`
+	Target         float64    `json:"target"`
+	RatePerWeek    float64    `json:"rate_per_week"` // negative when losing
+	EstimatedDate  *time.Time `json:"estimated_date,omitempty"`
+	WeeksRemaining *float64   `json:"weeks_remaining,omitempty"`
+	Measurements   int        `json:"measurements"`
+	SpanDays       int        `json:"span_days"` // from the first weigh-in to the last
+	Confidence     string     `json:"confidence"`
+}
+
+// LinearTrendPerWeek fits a least-squares line through points and returns its slope per
+// week. It reports false when the points do not span any time.
+func LinearTrendPerWeek(points []TrendPoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	// Weeks since the first point keep the sums small
+	origin := points[0].At
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.At.Sub(origin).Hours() / (24 * 7)
+		sumY += p.Value
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+
+	var covariance, variance float64
+	for _, p := range points {
+		dx := p.At.Sub(origin).Hours()/(24*7) - meanX
+		covariance += dx * (p.Value - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return covariance / variance, true
+}
+
+// EstimateGoalETA projects when a weight_loss or weight_gain goal reaches target from the
+// weigh-ins in points, in any order, measured from the latest weigh-in
+func EstimateGoalETA(goalType string, target float64, points []TrendPoint) *GoalETA {
+	eta := &GoalETA{
+		Status:       GoalETAInsufficientData,
+		Target:       target,
+		Measurements: len(points),
+		Confidence:   GoalETAConfidenceLow,
+	}
+	if len(points) == 0 {
+		return eta
+	}
+
+	first, latest := points[0], points[0]
+	for _, p := range points {
+		if p.At.Before(first.At) {
+			first = p
+		}
+		if p.At.After(latest.At) {
+			latest = p
+		}
+	}
+	eta.Current = latest.Value
+	eta.SpanDays = int(latest.At.Sub(first.At).Hours() / 24)
+	eta.Confidence = goalETAConfidence(len(points), eta.SpanDays)
+
+	losing := goalType == "weight_loss"
+	remaining := target - latest.Value
+	if losing {
+		remaining = latest.Value - target
+	}
+	if remaining <= 0 {
+		eta.Status = GoalETAReached
+		return eta
+	}
+
+	rate, ok := LinearTrendPerWeek(points)
+	if !ok || eta.SpanDays < 1 {
+		return eta
+	}
+	eta.RatePerWeek = math.Round(rate*100) / 100
+
+	progress := rate
+	if losing {
+		progress = -rate
+	}
+	switch {
+	case progress < 0:
+		eta.Status = GoalETAWrongDirection
+	case progress == 0 || remaining/progress > MaxGoalETAWeeks:
+		eta.Status = GoalETAFlat
+	default:
+		weeks := remaining / progress
+		date := latest.At.Add(time.Duration(weeks * 7 * 24 * float64(time.Hour)))
+		rounded := math.Round(weeks*10) / 10
+		eta.Status = GoalETAOnTrack
+		eta.EstimatedDate = &date
+		eta.WeeksRemaining = &rounded
+	}
+	return eta
+}
+
+// goalETAConfidence rates a trend by its weigh-ins: high takes about two a week for three
+// weeks, medium a handful over a week and a half
+func goalETAConfidence(measurements, spanDays int) string {
+	switch {
+	case measurements >= 6 && spanDays >= 21:
+		return GoalETAConfidenceHigh
+	case measurements >= 4 && spanDays >= 10:
+		return GoalETAConfidenceMedium
+	}
+	return GoalETAConfidenceLow
+}
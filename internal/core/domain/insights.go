@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Achievement codes
+const (
+	AchievementFirstWorkout = "first_workout"
+	AchievementMealStreak7  = "meal_streak_7"
+	AchievementFirstPR      = "first_pr"
+)
+
+// MealStreakAchievementDays is the meal logging streak that earns AchievementMealStreak7
+const MealStreakAchievementDays = 7
+
+// Achievement is a milestone a user has earned; each code is earned once
+type Achievement struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement_code" json:"user_id"`
+	Code     string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_achievement_code" json:"code"`
+	EarnedAt time.Time `gorm:"not null" json:"earned_at"` // when the milestone was reached, not when it was detected
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (Achievement) TableName() string {
+	return "user_achievements"
+}
+
+// Streak counts consecutive local calendar days with activity
+type Streak struct {
+	Current    int     `json:"current"` // run ending today, or yesterday if today has nothing logged yet
+	Longest    int     `json:"longest"`
+	LastActive *string `json:"last_active,omitempty"` // YYYY-MM-DD
+}
+
+// StreakSummary reports a user's logging streaks and earned achievements
+type StreakSummary struct {
+	Timezone     string         `json:"timezone"`
+	Today        string         `json:"today"`
+	Meals        Streak         `json:"meals"`    // days with at least one logged meal
+	Workouts     Streak         `json:"workouts"` // days with at least one completed workout
+	Achievements []*Achievement `json:"achievements"`
+}
+
+// CalculateStreak computes the current and longest runs of consecutive days.
+// Dates are distinct YYYY-MM-DD strings in ascending order; today is the current
+// local date in the same format. Unparseable dates are skipped.
+func CalculateStreak(dates []string, today string) Streak {
+	var streak Streak
+	if len(dates) == 0 {
+		return streak
+	}
+
+	run := 0
+	var prev time.Time
+	for _, date := range dates {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+
+		if run > 0 && day.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = day
+		streak.Longest = max(streak.Longest, run)
+	}
+	if run == 0 {
+		return streak
+	}
+
+	last := prev.Format("2006-01-02")
+	streak.LastActive = &last
+
+	// The run is still current if it reaches today or yesterday
+	todayDate, err := time.Parse("2006-01-02", today)
+	if err == nil && (prev.Equal(todayDate) || prev.Equal(todayDate.AddDate(0, 0, -1))) {
+		streak.Current = run
+	}
+
+	return streak
+}
+
+// StreakReachedOn returns the first date on which a run of consecutive days reached
+// length, or "" if none did. Dates are as for CalculateStreak.
+func StreakReachedOn(dates []string, length int) string {
+	run := 0
+	var prev time.Time
+	for _, date := range dates {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+
+		if run > 0 && day.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = day
+
+		if run >= length {
+			return date
+		}
+	}
+	return ""
+}
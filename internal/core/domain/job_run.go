@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Names of the scheduled jobs; each is also its key in the scheduler intervals config
+const (
+	JobPhotoCleanup = "photo_cleanup"
+)
+
+// JobRun is the latest run of a scheduled job. It is shared by every instance, so a
+// job runs once per interval however many instances are up.
+type JobRun struct {
+	Name       string     `gorm:"type:varchar(100);primary_key" json:"name"`
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // nil while running or when the run was cut short
+	Error      *string    `gorm:"type:text" json:"error,omitempty"`
+
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (JobRun) TableName() string {
+	return "job_runs"
+}
+
+// Due reports whether a job that runs every interval should run again at now.
+// A job that never ran (nil run) is due.
+func (r *JobRun) Due(interval time.Duration, now time.Time) bool {
+	return r == nil || !now.Before(r.StartedAt.Add(interval))
+}
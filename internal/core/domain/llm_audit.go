@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LLMAuditEntry records one LLM API call for debugging and billing audits
+type LLMAuditEntry struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`            // nil for calls not made on behalf of a user
+	RequestID  *string    `gorm:"type:varchar(100);index" json:"request_id,omitempty"` // X-Request-ID of the HTTP request that made the call
+	Operation  string     `gorm:"type:varchar(50);not null;index" json:"operation"`    // agent, meal_parse, food_estimate, vision, coach_digest, weekly_recap
+	Model      string     `gorm:"type:varchar(255);not null" json:"model"`
+	PromptHash string     `gorm:"type:varchar(64);not null;index" json:"prompt_hash"` // SHA-256 of the request messages before redaction
+	Request    string     `gorm:"type:jsonb;not null" json:"request"`
+	Response   *string    `gorm:"type:jsonb" json:"response,omitempty"`   // nil when the call failed
+	ToolsUsed  *string    `gorm:"type:jsonb" json:"tools_used,omitempty"` // tool names the model called
+
+	PromptTokens     int      `gorm:"not null;default:0" json:"prompt_tokens"`
+	CompletionTokens int      `gorm:"not null;default:0" json:"completion_tokens"`
+	TotalTokens      int      `gorm:"not null;default:0" json:"total_tokens"`
+	LatencyMs        int64    `gorm:"not null;default:0" json:"latency_ms"`         // including retries
+	CostUSD          *float64 `gorm:"type:decimal(12,6)" json:"cost_usd,omitempty"` // as reported by the provider
+
+	Status string  `gorm:"type:varchar(20);not null" json:"status"` // success, error
+	Error  *string `gorm:"type:text" json:"error,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (LLMAuditEntry) TableName() string {
+	return "llm_audit"
+}
+
+// LLMAuditFilter narrows an audit log query; zero values match everything
+type LLMAuditFilter struct {
+	UserID    *uuid.UUID
+	RequestID string
+	Operation string
+	Model     string
+	Status    string
+	From      *time.Time
+	To        *time.Time // exclusive
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxFutureLogWindow is how far past now a meal, activity or measurement may be dated.
+// A day leaves room for clock skew and timezone mix-ups while still catching a wrong year
+// or month, which would otherwise land in summaries and trends that have not happened yet.
+const MaxFutureLogWindow = 24 * time.Hour
+
+// CheckLoggedAt rejects a time more than MaxFutureLogWindow after now. Field names the
+// time in the error, e.g. consumed_at. Past times are always accepted.
+func CheckLoggedAt(field string, at, now time.Time) error {
+	if at.After(now.Add(MaxFutureLogWindow)) {
+		return fmt.Errorf("%w: %s must not be more than 24 hours in the future", ErrInvalidInput, field)
+	}
+	return nil
+}
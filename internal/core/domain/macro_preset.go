@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Goal types with a default macro split
+const (
+	MacroPresetCut      = "cut"      // lose fat
+	MacroPresetMaintain = "maintain" // keep the current weight
+	MacroPresetBulk     = "bulk"     // gain muscle
+)
+
+// Dietary preferences that change a preset's split
+const (
+	DietaryPreferenceKeto        = "keto"
+	DietaryPreferenceHighProtein = "high_protein"
+)
+
+// MacroSplit is the share of calories, in percent, from each macronutrient; the three add
+// up to 100
+type MacroSplit struct {
+	Protein       float64 `json:"protein_percent"`
+	Carbohydrates float64 `json:"carbohydrates_percent"`
+	Fat           float64 `json:"fat_percent"`
+}
+
+// MacroPresets maps a goal type to its macro split
+type MacroPresets map[string]MacroSplit
+
+// DefaultMacroPresets are used for goal types the configuration does not override
+var DefaultMacroPresets = MacroPresets{
+	MacroPresetCut:      {Protein: 40, Carbohydrates: 30, Fat: 30},
+	MacroPresetMaintain: {Protein: 30, Carbohydrates: 40, Fat: 30},
+	MacroPresetBulk:     {Protein: 30, Carbohydrates: 45, Fat: 25},
+}
+
+// Splits the dietary preferences lead to. Keto replaces the preset's split outright; high
+// protein moves highProteinShift points of calories from carbohydrates to protein.
+var ketoMacroSplit = MacroSplit{Protein: 25, Carbohydrates: 5, Fat: 70}
+
+const highProteinShift = 10.0
+
+// MacroPreset is the split chosen for a user's goal type, with what it was chosen from
+type MacroPreset struct {
+	GoalType    string   `json:"goal_type"`
+	Adjustments []string `json:"adjustments,omitempty"` // dietary preferences applied to the split
+	MacroSplit
+}
+
+// ParseMacroSplit reads a split written as protein/carbohydrates/fat percentages, e.g.
+// "40/30/30". The percentages must add up to 100.
+func ParseMacroSplit(value string) (MacroSplit, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return MacroSplit{}, fmt.Errorf("%w: %q is not protein/carbohydrates/fat percentages", ErrInvalidInput, value)
+	}
+
+	var percents [3]float64
+	for i, part := range parts {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || percent < 0 {
+			return MacroSplit{}, fmt.Errorf("%w: %q is not protein/carbohydrates/fat percentages", ErrInvalidInput, value)
+		}
+		percents[i] = percent
+	}
+	split := MacroSplit{Protein: percents[0], Carbohydrates: percents[1], Fat: percents[2]}
+	if total := split.Protein + split.Carbohydrates + split.Fat; math.Abs(total-100) > 0.01 {
+		return MacroSplit{}, fmt.Errorf("%w: %q adds up to %g%%, not 100%%", ErrInvalidInput, value, total)
+	}
+	return split, nil
+}
+
+// WithOverrides returns the presets with each override replacing the split of its goal type
+func (p MacroPresets) WithOverrides(overrides MacroPresets) MacroPresets {
+	merged := make(MacroPresets, len(p)+len(overrides))
+	for goalType, split := range p {
+		merged[goalType] = split
+	}
+	for goalType, split := range overrides {
+		merged[goalType] = split
+	}
+	return merged
+}
+
+// Choose picks the split for a goal type and adjusts it for the user's dietary
+// preferences. An empty or unknown goal type gets the maintain split.
+func (p MacroPresets) Choose(goalType string, dietaryPreferences map[string]interface{}) MacroPreset {
+	goalType = strings.ToLower(strings.TrimSpace(goalType))
+	split, ok := p[goalType]
+	if !ok {
+		goalType = MacroPresetMaintain
+		split = p[MacroPresetMaintain]
+	}
+	preset := MacroPreset{GoalType: goalType, MacroSplit: split}
+
+	if keto, _ := dietaryPreferences[DietaryPreferenceKeto].(bool); keto {
+		preset.MacroSplit = ketoMacroSplit
+		preset.Adjustments = append(preset.Adjustments, DietaryPreferenceKeto)
+	}
+	if highProtein, _ := dietaryPreferences[DietaryPreferenceHighProtein].(bool); highProtein {
+		// Carbohydrates give way first; on a split too low in them fat makes up the rest.
+		// Protein gains only what the two gave, so the split still adds up to 100.
+		fromCarbs := math.Min(highProteinShift, preset.Carbohydrates)
+		fromFat := math.Min(highProteinShift-fromCarbs, preset.Fat)
+		preset.Carbohydrates -= fromCarbs
+		preset.Fat -= fromFat
+		preset.Protein += fromCarbs + fromFat
+		preset.Adjustments = append(preset.Adjustments, DietaryPreferenceHighProtein)
+	}
+	return preset
+}
+
+// Targets turns the split into grams of each macronutrient for a day of calories
+func (s MacroSplit) Targets(calories float64) MacroTargets {
+	return MacroTargets{
+		Calories:      calories,
+		Protein:       math.Round(calories * s.Protein / 100 / CaloriesPerGramProtein),
+		Carbohydrates: math.Round(calories * s.Carbohydrates / 100 / CaloriesPerGramCarbohydrates),
+		Fat:           math.Round(calories * s.Fat / 100 / CaloriesPerGramFat),
+	}
+}
@@ -1,9 +1,11 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Meal represents a meal logged by a user
@@ -11,19 +13,29 @@ type Meal struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID    uuid.UUID `gorm:"type:uuid;not null;index:idx_user_meals" json:"user_id"`
 	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
-	MealType  string    `gorm:"type:varchar(50);not null" json:"meal_type"` // breakfast, lunch, dinner, snack
+	MealType  string    `gorm:"type:varchar(50);not null" json:"meal_type"` // breakfast, lunch, dinner, snack or a custom label
 	ConsumedAt time.Time `gorm:"not null;index:idx_user_meals" json:"consumed_at"`
 	Notes     *string   `gorm:"type:text" json:"notes,omitempty"`
+	PhotoPath *string   `gorm:"type:text" json:"photo_path,omitempty"` // object path of the meal photo in storage
 
 	// Calculated totals (denormalized for performance)
 	TotalCalories     float64 `gorm:"type:decimal(10,2);not null" json:"total_calories"`      // Stored as float64, precision 10,2
 	TotalProtein      float64 `gorm:"type:decimal(10,2);not null" json:"total_protein"`       // Stored as float64, precision 10,2
 	TotalCarbohydrates float64 `gorm:"type:decimal(10,2);not null" json:"total_carbohydrates"` // Stored as float64, precision 10,2
 	TotalFat          float64 `gorm:"type:decimal(10,2);not null" json:"total_fat"`           // Stored as float64, precision 10,2
+	TotalSaturatedFat float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_saturated_fat"` // grams
+	TotalSodium       float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_sodium"`        // milligrams
 
-	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	// Sample meals from the demo seed are flagged so clearing the seed removes only them
+	IsDemo bool `gorm:"not null;default:false" json:"is_demo,omitempty"`
+
+	// PortionWarnings flag items logged far above a typical portion, likely typos the
+	// client can confirm with the user; set when the meal is created and not stored
+	PortionWarnings []PortionWarning `gorm:"-" json:"portion_warnings,omitempty"`
+
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // soft-deleted so a delete can be undone
 
 	// Relationships
 	User      User           `gorm:"foreignKey:UserID" json:"-"`
@@ -48,6 +60,11 @@ type MealFoodItem struct {
 	Protein       float64 `gorm:"type:decimal(10,2);not null" json:"protein"`       // Stored as float64, precision 10,2
 	Carbohydrates float64 `gorm:"type:decimal(10,2);not null" json:"carbohydrates"` // Stored as float64, precision 10,2
 	Fat           float64 `gorm:"type:decimal(10,2);not null" json:"fat"`           // Stored as float64, precision 10,2
+	SaturatedFat  float64 `gorm:"type:decimal(10,2);not null;default:0" json:"saturated_fat"` // grams
+	Sodium        float64 `gorm:"type:decimal(10,2);not null;default:0" json:"sodium"`        // milligrams
+
+	// The food as it was when logged; later edits to the food do not change it
+	Snapshot FoodSnapshot `gorm:"embedded;embeddedPrefix:snapshot_" json:"snapshot"`
 
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 
@@ -61,6 +78,89 @@ func (MealFoodItem) TableName() string {
 	return "meal_food_items"
 }
 
+// FoodSnapshot is a copy of a food's name, serving and per-serving macros,
+// saturated fat and sodium taken when the food is logged
+type FoodSnapshot struct {
+	Name          string  `gorm:"type:varchar(255)" json:"name"`
+	ServingSize   float64 `gorm:"type:decimal(10,2)" json:"serving_size"` // Stored as float64, precision 10,2
+	ServingUnit   string  `gorm:"type:varchar(50)" json:"serving_unit"`
+	Calories      float64 `gorm:"type:decimal(10,2)" json:"calories"`      // Stored as float64, precision 10,2
+	Protein       float64 `gorm:"type:decimal(10,2)" json:"protein"`       // Stored as float64, precision 10,2
+	Carbohydrates float64 `gorm:"type:decimal(10,2)" json:"carbohydrates"` // Stored as float64, precision 10,2
+	Fat           float64 `gorm:"type:decimal(10,2)" json:"fat"`           // Stored as float64, precision 10,2
+	SaturatedFat  float64 `gorm:"type:decimal(10,2)" json:"saturated_fat"` // grams; 0 when the food does not list it
+	Sodium        float64 `gorm:"type:decimal(10,2)" json:"sodium"`        // milligrams; 0 when the food does not list it
+}
+
+// NewFoodSnapshot copies the food's current serving and per-serving nutrition
+func NewFoodSnapshot(food *Food) FoodSnapshot {
+	return FoodSnapshot{
+		Name:          food.Name,
+		ServingSize:   food.ServingSize,
+		ServingUnit:   food.ServingUnit,
+		Calories:      food.Calories,
+		Protein:       food.Protein,
+		Carbohydrates: food.Carbohydrates,
+		Fat:           food.Fat,
+		SaturatedFat:  valueOrZero(food.SaturatedFat),
+		Sodium:        valueOrZero(food.Sodium),
+	}
+}
+
+func valueOrZero(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// IsZero reports whether no snapshot has been taken
+func (s FoodSnapshot) IsZero() bool {
+	return s == FoodSnapshot{}
+}
+
+// Servings converts a logged quantity to servings. A quantity in the serving
+// unit is divided by the serving size (150 g of a 100 g serving is 1.5);
+// any other unit is taken as a count of servings.
+func (s FoodSnapshot) Servings(quantity float64, unit string) float64 {
+	if s.ServingSize > 0 && strings.EqualFold(strings.TrimSpace(unit), s.ServingUnit) {
+		return quantity / s.ServingSize
+	}
+	return quantity
+}
+
+// ApplySnapshot snapshots the food onto the item and recalculates the item's
+// nutrition from it, so the item keeps its nutrition when the food is edited
+func (i *MealFoodItem) ApplySnapshot(food *Food) {
+	i.Snapshot = NewFoodSnapshot(food)
+	i.RecalculateNutrition()
+}
+
+// RecalculateNutrition sets the item's nutrition from its snapshot and quantity
+func (i *MealFoodItem) RecalculateNutrition() {
+	servings := i.Snapshot.Servings(i.Quantity, i.Unit)
+	i.Calories = i.Snapshot.Calories * servings
+	i.Protein = i.Snapshot.Protein * servings
+	i.Carbohydrates = i.Snapshot.Carbohydrates * servings
+	i.Fat = i.Snapshot.Fat * servings
+	i.SaturatedFat = i.Snapshot.SaturatedFat * servings
+	i.Sodium = i.Snapshot.Sodium * servings
+}
+
+// RecalculateTotals sets the meal's totals from its food items
+func (m *Meal) RecalculateTotals() {
+	m.TotalCalories, m.TotalProtein, m.TotalCarbohydrates, m.TotalFat = 0, 0, 0, 0
+	m.TotalSaturatedFat, m.TotalSodium = 0, 0
+	for _, item := range m.FoodItems {
+		m.TotalCalories += item.Calories
+		m.TotalProtein += item.Protein
+		m.TotalCarbohydrates += item.Carbohydrates
+		m.TotalFat += item.Fat
+		m.TotalSaturatedFat += item.SaturatedFat
+		m.TotalSodium += item.Sodium
+	}
+}
+
 // NutritionTotals represents aggregated nutrition information
 type NutritionTotals struct {
 	TotalCalories      float64 `json:"total_calories"`
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// MinMealComparisonHistory is how many past meals of a type are needed before
+	// a meal is compared against them
+	MinMealComparisonHistory = 5
+
+	// mealComparisonSimilarPercent is how far from typical a meal can be, in
+	// percent, and still be described as about typical
+	mealComparisonSimilarPercent = 10.0
+)
+
+// MealComparison compares one meal with the user's typical meal of the same
+// canonical type, e.g. "30% more calories than your typical lunch"
+type MealComparison struct {
+	MealType      string `json:"meal_type"`      // canonical group compared against
+	HistoryCount  int    `json:"history_count"`  // past meals of that type the typical values come from
+	EnoughHistory bool   `json:"enough_history"` // false until MinMealComparisonHistory meals are logged; no figures are given then
+
+	Meal    MacroTargets `json:"meal"`
+	Typical MacroTargets `json:"typical"` // average of the past meals
+
+	// Difference from typical in percent; nil when the typical value is zero
+	CaloriesDeltaPercent      *float64 `json:"calories_delta_percent,omitempty"`
+	ProteinDeltaPercent       *float64 `json:"protein_delta_percent,omitempty"`
+	CarbohydratesDeltaPercent *float64 `json:"carbohydrates_delta_percent,omitempty"`
+	FatDeltaPercent           *float64 `json:"fat_delta_percent,omitempty"`
+
+	// Share of past meals of the type with fewer calories, 0-100
+	CaloriesPercentile *float64 `json:"calories_percentile,omitempty"`
+
+	Summary string `json:"summary"`
+}
+
+// CompareMeal compares a meal's totals with the past meals in history that
+// share its canonical meal type. Meals of other types in history are ignored.
+func CompareMeal(mealType string, meal MacroTargets, history []*Meal) *MealComparison {
+	group := MealTypeGroup(mealType)
+	comparison := &MealComparison{MealType: group, Meal: meal}
+
+	var calories []float64
+	for _, past := range history {
+		if MealTypeGroup(past.MealType) != group {
+			continue
+		}
+		calories = append(calories, past.TotalCalories)
+		comparison.Typical.Calories += past.TotalCalories
+		comparison.Typical.Protein += past.TotalProtein
+		comparison.Typical.Carbohydrates += past.TotalCarbohydrates
+		comparison.Typical.Fat += past.TotalFat
+	}
+	comparison.HistoryCount = len(calories)
+
+	if comparison.HistoryCount < MinMealComparisonHistory {
+		comparison.Typical = MacroTargets{}
+		comparison.Summary = fmt.Sprintf("Log %d more %s meals to see how this one compares to your typical %s.",
+			MinMealComparisonHistory-comparison.HistoryCount, group, group)
+		return comparison
+	}
+	comparison.EnoughHistory = true
+
+	count := float64(comparison.HistoryCount)
+	comparison.Typical.Calories /= count
+	comparison.Typical.Protein /= count
+	comparison.Typical.Carbohydrates /= count
+	comparison.Typical.Fat /= count
+
+	comparison.CaloriesDeltaPercent = deltaPercent(meal.Calories, comparison.Typical.Calories)
+	comparison.ProteinDeltaPercent = deltaPercent(meal.Protein, comparison.Typical.Protein)
+	comparison.CarbohydratesDeltaPercent = deltaPercent(meal.Carbohydrates, comparison.Typical.Carbohydrates)
+	comparison.FatDeltaPercent = deltaPercent(meal.Fat, comparison.Typical.Fat)
+
+	below := 0
+	for _, c := range calories {
+		if c < meal.Calories {
+			below++
+		}
+	}
+	percentile := float64(below) / count * 100
+	comparison.CaloriesPercentile = &percentile
+
+	comparison.Summary = comparison.describe()
+	return comparison
+}
+
+// describe phrases the calorie difference, e.g. "30% more calories than your typical lunch"
+func (c *MealComparison) describe() string {
+	if c.CaloriesDeltaPercent == nil {
+		return fmt.Sprintf("Your typical %s has no calories logged to compare with.", c.MealType)
+	}
+
+	delta := math.Round(*c.CaloriesDeltaPercent)
+	switch {
+	case math.Abs(delta) < mealComparisonSimilarPercent:
+		return fmt.Sprintf("About as many calories as your typical %s.", c.MealType)
+	case delta > 0:
+		return fmt.Sprintf("%.0f%% more calories than your typical %s.", delta, c.MealType)
+	default:
+		return fmt.Sprintf("%.0f%% fewer calories than your typical %s.", -delta, c.MealType)
+	}
+}
+
+// deltaPercent returns how much value differs from typical in percent, or nil
+// when typical is zero
+func deltaPercent(value, typical float64) *float64 {
+	if typical == 0 {
+		return nil
+	}
+	delta := (value - typical) / typical * 100
+	return &delta
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a user's meal distribution came from
+const (
+	MealDistributionSourceManual  = "manual"  // set by the user
+	MealDistributionSourceDefault = "default" // nothing stored: DefaultMealDistribution
+)
+
+// mealDistributionTolerance is how far, in percentage points, the shares of a
+// distribution may add up to away from 100
+const mealDistributionTolerance = 0.5
+
+// MealDistribution is the share of the day's calories and macros, in percent, a user
+// plans to eat in each canonical meal type group. Meals grouped as other have no share.
+type MealDistribution struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	Breakfast float64   `gorm:"type:decimal(5,2);not null" json:"breakfast"`              // %
+	Lunch     float64   `gorm:"type:decimal(5,2);not null" json:"lunch"`                  // %
+	Dinner    float64   `gorm:"type:decimal(5,2);not null" json:"dinner"`                 // %
+	Snack     float64   `gorm:"type:decimal(5,2);not null" json:"snack"`                  // %
+	Source    string    `gorm:"type:varchar(20);not null;default:'manual'" json:"source"` // manual; default when not stored
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (MealDistribution) TableName() string {
+	return "meal_distributions"
+}
+
+// DefaultMealDistribution returns the distribution of a user who has not stored one:
+// a light breakfast, lunch as the largest meal and a tenth of the day left for snacks
+func DefaultMealDistribution(userID uuid.UUID) *MealDistribution {
+	return &MealDistribution{
+		UserID:    userID,
+		Breakfast: 25,
+		Lunch:     35,
+		Dinner:    30,
+		Snack:     10,
+		Source:    MealDistributionSourceDefault,
+	}
+}
+
+// Validate checks each share is between 0 and 100 percent and that together they add
+// up to 100
+func (d *MealDistribution) Validate() error {
+	total := 0.0
+	for _, share := range d.shares() {
+		if share.percent < 0 || share.percent > 100 {
+			return fmt.Errorf("%w: %s must be between 0 and 100 percent", ErrInvalidInput, share.group)
+		}
+		total += share.percent
+	}
+	if math.Abs(total-100) > mealDistributionTolerance {
+		return fmt.Errorf("%w: meal shares must add up to 100 percent, got %.1f", ErrInvalidInput, total)
+	}
+	return nil
+}
+
+type mealShare struct {
+	group   string
+	percent float64
+}
+
+func (d *MealDistribution) shares() []mealShare {
+	return []mealShare{
+		{MealTypeBreakfast, d.Breakfast},
+		{MealTypeLunch, d.Lunch},
+		{MealTypeDinner, d.Dinner},
+		{MealTypeSnack, d.Snack},
+	}
+}
+
+// MealTypeTarget is one meal type group's share of the day's target and what has been
+// logged in it so far
+type MealTypeTarget struct {
+	Group     string       `json:"group"`
+	Percent   float64      `json:"percent"`
+	Target    MacroTargets `json:"target"`
+	Logged    MacroTargets `json:"logged"`
+	Remaining MacroTargets `json:"remaining"` // negative once the group's target is exceeded
+	MealCount int          `json:"meal_count"`
+}
+
+// SplitTargets divides the day's target between the meal type groups by their share.
+// Each target is rounded to 0.1 and the last group with a share takes what is left, so
+// rounding never makes the groups add up to more or less than the day's target.
+func (d *MealDistribution) SplitTargets(daily MacroTargets) []MealTypeTarget {
+	shares := d.shares()
+	total, last := 0.0, -1
+	for i, share := range shares {
+		total += share.percent
+		if share.percent > 0 {
+			last = i
+		}
+	}
+
+	targets := make([]MealTypeTarget, len(shares))
+	left := daily
+	for i, share := range shares {
+		targets[i] = MealTypeTarget{Group: share.group, Percent: share.percent}
+		switch {
+		case i == last:
+			targets[i].Target = left
+		case share.percent > 0:
+			// Shares may be off 100 by the tolerance; scale them so they cover the day
+			fraction := share.percent / total
+			target := MacroTargets{
+				Calories:      roundTenth(daily.Calories * fraction),
+				Protein:       roundTenth(daily.Protein * fraction),
+				Carbohydrates: roundTenth(daily.Carbohydrates * fraction),
+				Fat:           roundTenth(daily.Fat * fraction),
+			}
+			targets[i].Target = target
+			left = MacroTargets{
+				Calories:      left.Calories - target.Calories,
+				Protein:       left.Protein - target.Protein,
+				Carbohydrates: left.Carbohydrates - target.Carbohydrates,
+				Fat:           left.Fat - target.Fat,
+			}
+		}
+		targets[i].Remaining = targets[i].Target
+	}
+	return targets
+}
+
+// CompareMealTargets fills in what has been logged in each group of targets from the
+// day's grouped meals and how much of each target remains
+func CompareMealTargets(targets []MealTypeTarget, groups []MealGroupTotals) []MealTypeTarget {
+	for i := range targets {
+		for _, group := range groups {
+			if group.Group != targets[i].Group {
+				continue
+			}
+			targets[i].MealCount = group.MealCount
+			targets[i].Logged = MacroTargets{
+				Calories:      group.TotalCalories,
+				Protein:       group.TotalProtein,
+				Carbohydrates: group.TotalCarbohydrates,
+				Fat:           group.TotalFat,
+			}
+		}
+		targets[i].Remaining = MacroTargets{
+			Calories:      targets[i].Target.Calories - targets[i].Logged.Calories,
+			Protein:       targets[i].Target.Protein - targets[i].Logged.Protein,
+			Carbohydrates: targets[i].Target.Carbohydrates - targets[i].Logged.Carbohydrates,
+			Fat:           targets[i].Target.Fat - targets[i].Logged.Fat,
+		}
+	}
+	return targets
+}
@@ -0,0 +1,40 @@
+package domain
+
+// MacroTargets holds calorie and macronutrient amounts, e.g. what is left for the day
+type MacroTargets struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+}
+
+// DefaultDailyTargets are used until the user has nutrition goals of their own
+var DefaultDailyTargets = MacroTargets{
+	Calories:      2000,
+	Protein:       150,
+	Carbohydrates: 200,
+	Fat:           65,
+}
+
+// IsZero reports whether no target is set
+func (t MacroTargets) IsZero() bool {
+	return t.Calories <= 0 && t.Protein <= 0 && t.Carbohydrates <= 0 && t.Fat <= 0
+}
+
+// SuggestedFood is a food and portion proposed for a meal
+type SuggestedFood struct {
+	Food          Food    `json:"food"`
+	Quantity      float64 `json:"quantity"`
+	Unit          string  `json:"unit"`
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+}
+
+// MealSuggestion is a set of loggable foods chosen to fit a macro target
+type MealSuggestion struct {
+	Target MacroTargets    `json:"target"`
+	Items  []SuggestedFood `json:"items"`
+	Totals MacroTargets    `json:"totals"`
+}
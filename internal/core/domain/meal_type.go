@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Canonical meal types. Meals may use any label; summaries group them under these.
+const (
+	MealTypeBreakfast = "breakfast"
+	MealTypeLunch     = "lunch"
+	MealTypeDinner    = "dinner"
+	MealTypeSnack     = "snack"
+
+	// MealTypeOther groups custom labels that match no canonical type
+	MealTypeOther = "other"
+
+	// MaxMealTypeLength is the longest meal type label accepted, in characters
+	MaxMealTypeLength = 50
+)
+
+// MealTypeGroups lists the summary groups in display order
+var MealTypeGroups = []string{MealTypeBreakfast, MealTypeLunch, MealTypeDinner, MealTypeSnack, MealTypeOther}
+
+// mealTypeKeywords maps words found in custom labels to their canonical group,
+// checked in order so "post-dinner snack" is a snack rather than dinner
+var mealTypeKeywords = []struct {
+	keyword string
+	group   string
+}{
+	{"snack", MealTypeSnack},
+	{"workout", MealTypeSnack},
+	{"dessert", MealTypeSnack},
+	{"breakfast", MealTypeBreakfast},
+	{"brunch", MealTypeBreakfast},
+	{"lunch", MealTypeLunch},
+	{"dinner", MealTypeDinner},
+	{"supper", MealTypeDinner},
+}
+
+// NormalizeMealType trims and lower-cases a meal type label and collapses inner
+// whitespace. An empty label or one over MaxMealTypeLength is invalid.
+func NormalizeMealType(label string) (string, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(label), " "))
+	if normalized == "" {
+		return "", fmt.Errorf("%w: meal type is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(normalized) > MaxMealTypeLength {
+		return "", fmt.Errorf("%w: meal type must be at most %d characters", ErrInvalidInput, MaxMealTypeLength)
+	}
+	return normalized, nil
+}
+
+// MealTypeGroup returns the canonical group a meal type is summarized under.
+// Custom labels are matched by keyword, e.g. "second breakfast" groups as breakfast
+// and "pre-workout" as a snack; anything else groups as other.
+func MealTypeGroup(mealType string) string {
+	mealType = strings.ToLower(strings.TrimSpace(mealType))
+	switch mealType {
+	case MealTypeBreakfast, MealTypeLunch, MealTypeDinner, MealTypeSnack:
+		return mealType
+	}
+
+	for _, kw := range mealTypeKeywords {
+		if strings.Contains(mealType, kw.keyword) {
+			return kw.group
+		}
+	}
+	return MealTypeOther
+}
+
+// MealGroupTotals totals a day's meals in one canonical meal type group
+type MealGroupTotals struct {
+	Group              string   `json:"group"`
+	MealTypes          []string `json:"meal_types"` // distinct labels logged in this group
+	MealCount          int      `json:"meal_count"`
+	TotalCalories      float64  `json:"total_calories"`
+	TotalProtein       float64  `json:"total_protein"`
+	TotalCarbohydrates float64  `json:"total_carbohydrates"`
+	TotalFat           float64  `json:"total_fat"`
+}
+
+// GroupMealsByType totals meals per canonical group. Only groups with meals are
+// returned, in MealTypeGroups order.
+func GroupMealsByType(meals []*Meal) []MealGroupTotals {
+	byGroup := make(map[string]*MealGroupTotals, len(MealTypeGroups))
+	for _, meal := range meals {
+		group := MealTypeGroup(meal.MealType)
+		totals, ok := byGroup[group]
+		if !ok {
+			totals = &MealGroupTotals{Group: group, MealTypes: []string{}}
+			byGroup[group] = totals
+		}
+
+		if !slices.Contains(totals.MealTypes, meal.MealType) {
+			totals.MealTypes = append(totals.MealTypes, meal.MealType)
+		}
+		totals.MealCount++
+		totals.TotalCalories += meal.TotalCalories
+		totals.TotalProtein += meal.TotalProtein
+		totals.TotalCarbohydrates += meal.TotalCarbohydrates
+		totals.TotalFat += meal.TotalFat
+	}
+
+	groups := make([]MealGroupTotals, 0, len(byGroup))
+	for _, group := range MealTypeGroups {
+		if totals, ok := byGroup[group]; ok {
+			groups = append(groups, *totals)
+		}
+	}
+	return groups
+}
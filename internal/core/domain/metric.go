@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Metric represents a health/fitness metric measurement
@@ -16,9 +17,12 @@ type Metric struct {
 	MeasuredAt time.Time `gorm:"not null;index:idx_user_metrics" json:"measured_at"`
 	Notes      *string   `gorm:"type:text" json:"notes,omitempty"`
 
-	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	// Set on sample measurements added by the demo seed
+	IsDemo bool `gorm:"not null;default:false" json:"is_demo,omitempty"`
+
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // soft-deleted so a delete can be undone
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"-"`
@@ -29,6 +33,42 @@ func (Metric) TableName() string {
 	return "metrics"
 }
 
+// MetricUpdate holds metric fields to correct; nil fields are left as is
+type MetricUpdate struct {
+	Value      *float64
+	Unit       *string
+	MeasuredAt *time.Time
+	Notes      *string
+}
+
+// MaxMetricBatchSize caps how many readings one batch may log
+const MaxMetricBatchSize = 500
+
+// MetricEntry is one reading to log in a batch, e.g. from a wearable sync
+type MetricEntry struct {
+	MetricType string
+	Value      float64
+	Unit       string
+	MeasuredAt time.Time
+	Notes      *string
+}
+
+// MetricBatchResult reports what happened to each reading of a batch
+type MetricBatchResult struct {
+	Received   int                `json:"received"`
+	Created    int                `json:"created"`
+	Duplicates int                `json:"duplicates"` // same type and timestamp as a logged reading or an earlier one in the batch
+	Failed     int                `json:"failed"`
+	Errors     []MetricBatchError `json:"errors"`
+}
+
+// MetricBatchError is why one reading of a batch was rejected
+type MetricBatchError struct {
+	Index      int    `json:"index"` // position of the reading in the batch
+	MetricType string `json:"metric_type"`
+	Message    string `json:"message"`
+}
+
 // DailySummary represents aggregated daily health data
 type DailySummary struct {
 	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -40,6 +80,8 @@ type DailySummary struct {
 	TotalProtein      float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_protein"`       // Stored as float64, precision 10,2
 	TotalCarbohydrates float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_carbohydrates"` // Stored as float64, precision 10,2
 	TotalFat          float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_fat"`           // Stored as float64, precision 10,2
+	TotalSaturatedFat float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_saturated_fat"` // grams
+	TotalSodium       float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_sodium"`        // milligrams
 
 	// Activity totals
 	TotalCaloriesBurned  float64 `gorm:"type:decimal(10,2);not null;default:0" json:"total_calories_burned"` // Stored as float64, precision 10,2
@@ -51,8 +93,32 @@ type DailySummary struct {
 	Weight   *float64 `gorm:"type:decimal(5,2)" json:"weight,omitempty"`    // Stored as float64, precision 5,2, in kg
 	BodyFat  *float64 `gorm:"type:decimal(5,2)" json:"body_fat,omitempty"`  // Stored as float64, precision 5,2, percentage
 
-	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	// Energy balance, computed when the summary is calculated
+	NetCalories   float64  `gorm:"-" json:"net_calories"`             // consumed - burned
+	TDEE          *float64 `gorm:"-" json:"tdee,omitempty"`           // from the profile; nil when it is incomplete
+	EnergyBalance *float64 `gorm:"-" json:"energy_balance,omitempty"` // consumed - TDEE
+
+	// Calorie target for the day, computed when the summary is calculated; see ApplyCalorieTarget
+	CalorieTarget         *float64 `gorm:"-" json:"calorie_target,omitempty"`
+	ExerciseCaloriesAdded float64  `gorm:"-" json:"exercise_calories_added"` // 0 unless the target mode is dynamic
+	CaloriesRemaining     *float64 `gorm:"-" json:"calories_remaining,omitempty"` // negative once the target is exceeded
+
+	// How much of each session's burn counted towards TotalCaloriesBurned once overlapping
+	// sessions were deduplicated, computed when the summary is calculated; for debugging
+	BurnDebug []BurnDecision `gorm:"-" json:"burn_debug,omitempty"`
+
+	// Meals grouped by canonical meal type, computed when the summary is calculated
+	MealGroups []MealGroupTotals `gorm:"-" json:"meal_groups"`
+
+	// The day's calorie target, including exercise added in dynamic mode, and macro targets
+	// split between meal types by the user's meal distribution, against what each has logged
+	MealTargets []MealTypeTarget `gorm:"-" json:"meal_targets,omitempty"`
+
+	// Intake against the user's sodium and saturated fat limit goals, computed when the summary is calculated
+	NutrientLimits []NutrientLimit `gorm:"-" json:"nutrient_limits,omitempty"`
+
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"-"`
@@ -0,0 +1,34 @@
+package domain
+
+// Strictness of the moderation of AI coach output
+const (
+	ModerationOff      = "off"      // replies are not screened
+	ModerationStandard = "standard" // blocks hate, sexual content, self-harm and severe profanity
+	ModerationStrict   = "strict"   // also blocks mild profanity
+)
+
+// Categories a moderator flags content under
+const (
+	ModerationCategoryHate      = "hate"
+	ModerationCategorySexual    = "sexual"
+	ModerationCategorySelfHarm  = "self_harm"
+	ModerationCategoryProfanity = "profanity"
+)
+
+// ModeratedReplyMessage replaces an assistant reply that failed moderation
+const ModeratedReplyMessage = "Sorry, I can't share that response. Could you ask me again in a different way?"
+
+// ModerationResult is a moderator's verdict on a piece of AI output
+type ModerationResult struct {
+	Flagged    bool     `json:"flagged"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+// ValidModerationStrictness reports whether strictness is off, standard or strict
+func ValidModerationStrictness(strictness string) bool {
+	switch strictness {
+	case ModerationOff, ModerationStandard, ModerationStrict:
+		return true
+	}
+	return false
+}
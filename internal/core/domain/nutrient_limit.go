@@ -0,0 +1,53 @@
+package domain
+
+// Goal types of daily nutrient limits. Unlike intake goals they are met by staying at or
+// under the target, e.g. a sodium goal of 2300 keeps sodium under 2300 mg a day.
+const (
+	GoalTypeSodium       = "sodium"        // mg per day
+	GoalTypeSaturatedFat = "saturated_fat" // g per day
+)
+
+// NutrientLimitUnits are the units daily totals and limit goals of each limited nutrient are in
+var NutrientLimitUnits = map[string]string{
+	GoalTypeSodium:       "mg",
+	GoalTypeSaturatedFat: "g",
+}
+
+// NutrientLimit is a day's intake of a nutrient against the user's limit goal for it
+type NutrientLimit struct {
+	Nutrient  string  `json:"nutrient"` // sodium or saturated_fat
+	Limit     float64 `json:"limit"`
+	Unit      string  `json:"unit"`
+	Consumed  float64 `json:"consumed"`
+	Remaining float64 `json:"remaining"` // negative when the limit is exceeded
+	Exceeded  bool    `json:"exceeded"`
+}
+
+// ApplyNutrientLimits sets the summary's NutrientLimits from the limit goals among
+// activeGoals. The first goal of each nutrient wins; nutrients without a goal are left out.
+func (s *DailySummary) ApplyNutrientLimits(activeGoals []*Goal) {
+	consumed := map[string]float64{
+		GoalTypeSodium:       s.TotalSodium,
+		GoalTypeSaturatedFat: s.TotalSaturatedFat,
+	}
+
+	s.NutrientLimits = nil
+	seen := make(map[string]bool)
+	for _, goal := range activeGoals {
+		unit, ok := NutrientLimitUnits[goal.GoalType]
+		if !ok || seen[goal.GoalType] || goal.TargetValue <= 0 {
+			continue
+		}
+		seen[goal.GoalType] = true
+
+		total := consumed[goal.GoalType]
+		s.NutrientLimits = append(s.NutrientLimits, NutrientLimit{
+			Nutrient:  goal.GoalType,
+			Limit:     goal.TargetValue,
+			Unit:      unit,
+			Consumed:  total,
+			Remaining: goal.TargetValue - total,
+			Exceeded:  total > goal.TargetValue,
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a user's nutrition target came from
+const (
+	NutritionTargetSourceManual  = "manual"  // set by the user
+	NutritionTargetSourceProfile = "profile" // derived from the profile's TDEE and weight
+	NutritionTargetSourceDefault = "default" // nothing stored: defaults overridden by active nutrition goals
+)
+
+// DefaultWaterTargetMl is the daily water target used until the user sets one
+const DefaultWaterTargetMl = 2000.0
+
+// Ratios used to derive a target from the profile
+const (
+	ProteinTargetPerKg   = 1.6  // g of protein per kg of body weight
+	FatTargetCalorieRate = 0.3  // share of calories from fat; carbohydrates make up the rest
+	WaterTargetMlPerKg   = 35.0 // ml of water per kg of body weight
+)
+
+// NutritionTarget is a user's daily calorie, macronutrient and water target. Summaries,
+// the coach and the agent all read it through SummaryService.GetNutritionTargets.
+type NutritionTarget struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	Calories      float64   `gorm:"type:decimal(10,2);not null" json:"calories"`
+	Protein       float64   `gorm:"type:decimal(10,2);not null" json:"protein"`       // g
+	Carbohydrates float64   `gorm:"type:decimal(10,2);not null" json:"carbohydrates"` // g
+	Fat           float64   `gorm:"type:decimal(10,2);not null" json:"fat"`           // g
+	WaterMl       float64   `gorm:"type:decimal(10,2);not null" json:"water_ml"`
+	Source        string    `gorm:"type:varchar(20);not null;default:'manual'" json:"source"` // manual or profile; default when not stored
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Warnings flag macros that do not add up to the calories when the target is saved; not stored
+	Warnings []string `gorm:"-" json:"warnings,omitempty"`
+	// MacroPreset is the split the macros were derived from for a goal type; not stored
+	MacroPreset *MacroPreset `gorm:"-" json:"macro_preset,omitempty"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (NutritionTarget) TableName() string {
+	return "nutrition_targets"
+}
+
+// Macros returns the target's calories and macronutrients
+func (t *NutritionTarget) Macros() MacroTargets {
+	return MacroTargets{
+		Calories:      t.Calories,
+		Protein:       t.Protein,
+		Carbohydrates: t.Carbohydrates,
+		Fat:           t.Fat,
+	}
+}
+
+// DefaultNutritionTarget is the target of a user who has not stored one: DefaultDailyTargets,
+// with each value overridden by the user's active goal of the same nutrient, if any
+func DefaultNutritionTarget(userID uuid.UUID, activeGoals []*Goal) *NutritionTarget {
+	macros := DefaultDailyTargets
+	fromGoals := MacroTargetsFromGoals(activeGoals)
+	if fromGoals.Calories > 0 {
+		macros.Calories = fromGoals.Calories
+	}
+	if fromGoals.Protein > 0 {
+		macros.Protein = fromGoals.Protein
+	}
+	if fromGoals.Carbohydrates > 0 {
+		macros.Carbohydrates = fromGoals.Carbohydrates
+	}
+	if fromGoals.Fat > 0 {
+		macros.Fat = fromGoals.Fat
+	}
+
+	return &NutritionTarget{
+		UserID:        userID,
+		Calories:      macros.Calories,
+		Protein:       macros.Protein,
+		Carbohydrates: macros.Carbohydrates,
+		Fat:           macros.Fat,
+		WaterMl:       DefaultWaterTargetMl,
+		Source:        NutritionTargetSourceDefault,
+	}
+}
+
+// DeriveNutritionTarget derives a target from the profile: calories at maintenance (TDEE),
+// protein by body weight, a fixed share of calories from fat and carbohydrates for the
+// rest. It returns nil when the profile lacks what TDEE needs.
+func DeriveNutritionTarget(user *User, at time.Time) *NutritionTarget {
+	tdee := CalculateTDEE(user, at)
+	if tdee == nil {
+		return nil
+	}
+
+	calories := math.Round(*tdee)
+	protein := math.Round(*user.WeightKg * ProteinTargetPerKg)
+	fat := math.Round(calories * FatTargetCalorieRate / CaloriesPerGramFat)
+	carbs := math.Max(0, math.Round((calories-protein*CaloriesPerGramProtein-fat*CaloriesPerGramFat)/CaloriesPerGramCarbohydrates))
+
+	return &NutritionTarget{
+		UserID:        user.ID,
+		Calories:      calories,
+		Protein:       protein,
+		Carbohydrates: carbs,
+		Fat:           fat,
+		WaterMl:       math.Round(*user.WeightKg * WaterTargetMlPerKg),
+		Source:        NutritionTargetSourceProfile,
+	}
+}
+
+// MacroTargetsFromGoals returns the target values of the calories, protein, carbohydrates
+// and fat goals among goals. The first goal of each type wins; types without a goal are zero.
+func MacroTargetsFromGoals(goals []*Goal) MacroTargets {
+	var targets MacroTargets
+	for _, goal := range goals {
+		var target *float64
+		switch goal.GoalType {
+		case "calories":
+			target = &targets.Calories
+		case "protein":
+			target = &targets.Protein
+		case "carbohydrates":
+			target = &targets.Carbohydrates
+		case "fat":
+			target = &targets.Fat
+		}
+		if target != nil && *target == 0 && goal.TargetValue > 0 {
+			*target = goal.TargetValue
+		}
+	}
+	return targets
+}
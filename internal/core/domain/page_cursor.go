@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PageCursor marks the last row of a keyset page: its creation time and ID. The next page
+// continues strictly past that row, so rows inserted or deleted between two fetches never
+// shift a page the way they do with an offset.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// NewPageCursor returns the cursor continuing after the row with this creation time and ID
+func NewPageCursor(createdAt time.Time, id uuid.UUID) *PageCursor {
+	return &PageCursor{CreatedAt: createdAt, ID: id}
+}
+
+// Encode returns the cursor as the opaque string clients send back. Its contents are
+// not part of the API.
+func (c *PageCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodePageCursor reads a cursor made by Encode. An empty string is the first page and
+// returns nil.
+func DecodePageCursor(cursor string) (*PageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("%w: cursor must be a next_cursor returned by the previous page", ErrInvalidInput)
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAtPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, invalid
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtPart)
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return nil, invalid
+	}
+	return &PageCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// MealPage is a page of meals, newest logged first
+type MealPage struct {
+	Meals      []*Meal `json:"meals"`
+	NextCursor string  `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// ActivityPage is a page of activities, newest logged first
+type ActivityPage struct {
+	Activities []*Activity `json:"activities"`
+	NextCursor string      `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// MessagePage is a page of a conversation's messages, oldest first
+type MessagePage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor string     `json:"next_cursor,omitempty"` // empty on the last page
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,11 +9,103 @@ import (
 
 // ParsedMeal represents a meal that has been parsed from text or photo input
 type ParsedMeal struct {
+	// ID lets the user confirm the meal later, with or without adjustments
+	ID                uuid.UUID        `json:"parsed_meal_id"`
 	MealType          string           `json:"meal_type"`
 	LoggedAt          time.Time        `json:"logged_at"`
 	FoodItems         []ParsedFoodItem `json:"food_items"`
+	Totals            MacroTargets     `json:"totals"` // estimated from the matched foods
 	Confidence        float64          `json:"confidence"`
 	NeedsConfirmation bool             `json:"needs_confirmation"`
+
+	// How the meal compares with the user's typical meal of its type; nil when not compared
+	Comparison *MealComparison `json:"comparison,omitempty"`
+
+	// Names of extracted foods that matched no food and could not be estimated
+	UnresolvedItems []string `json:"unresolved_items,omitempty"`
+
+	// Why a photo gave no food items, e.g. image quality "blurry" or notes "no food
+	// detected", so the user can retake it or enter the meal by hand. A meal without
+	// items has no ID and cannot be confirmed.
+	ImageQuality string `json:"image_quality,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+// How meal parsing estimates foods missing from the database when AI is disabled
+const (
+	MealEstimateFallbackDatabase = "database" // only foods already in the database are used
+	MealEstimateFallbackBuiltin  = "builtin"  // common foods use a built-in nutrition table
+)
+
+// SumTotals sets the meal's totals from its food items
+func (m *ParsedMeal) SumTotals() {
+	m.Totals = MacroTargets{}
+	for _, item := range m.FoodItems {
+		m.Totals.Calories += item.Calories
+		m.Totals.Protein += item.Protein
+		m.Totals.Carbohydrates += item.Carbohydrates
+		m.Totals.Fat += item.Fat
+	}
+}
+
+// ParsedItemAdjustment changes one detected food when a parsed meal is confirmed. Index
+// is the item's position in the parsed meal's FoodItems. Exclude leaves the item out;
+// otherwise Quantity, when set, replaces the detected quantity in the same unit.
+type ParsedItemAdjustment struct {
+	Index    int      `json:"index"`
+	Exclude  bool     `json:"exclude,omitempty"`
+	Quantity *float64 `json:"quantity,omitempty"`
+}
+
+// ApplyAdjustments applies the user's changes to the detected foods. Items without an
+// adjustment are kept as detected. An overridden quantity scales the item's nutrition,
+// and the totals are summed again from the items that remain.
+func (m *ParsedMeal) ApplyAdjustments(adjustments []ParsedItemAdjustment) error {
+	adjusted := make(map[int]ParsedItemAdjustment, len(adjustments))
+	for _, adjustment := range adjustments {
+		if adjustment.Index < 0 || adjustment.Index >= len(m.FoodItems) {
+			return fmt.Errorf("%w: item %d does not exist; the meal has %d items", ErrInvalidInput, adjustment.Index, len(m.FoodItems))
+		}
+		if _, seen := adjusted[adjustment.Index]; seen {
+			return fmt.Errorf("%w: item %d is adjusted more than once", ErrInvalidInput, adjustment.Index)
+		}
+		if !adjustment.Exclude && adjustment.Quantity != nil && *adjustment.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity of item %d must be greater than 0; exclude the item to leave it out", ErrInvalidInput, adjustment.Index)
+		}
+		adjusted[adjustment.Index] = adjustment
+	}
+
+	items := make([]ParsedFoodItem, 0, len(m.FoodItems))
+	for i, item := range m.FoodItems {
+		adjustment, ok := adjusted[i]
+		switch {
+		case !ok:
+		case adjustment.Exclude:
+			continue
+		case adjustment.Quantity != nil:
+			item.scaleTo(*adjustment.Quantity)
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("%w: every item is excluded; a meal needs at least one", ErrInvalidInput)
+	}
+
+	m.FoodItems = items
+	m.SumTotals()
+	return nil
+}
+
+// scaleTo changes the item's quantity, scaling its nutrition along
+func (i *ParsedFoodItem) scaleTo(quantity float64) {
+	if i.Quantity > 0 {
+		factor := quantity / i.Quantity
+		i.Calories *= factor
+		i.Protein *= factor
+		i.Carbohydrates *= factor
+		i.Fat *= factor
+	}
+	i.Quantity = quantity
 }
 
 // ParsedFoodItem represents a food item extracted from parsing
@@ -23,6 +116,12 @@ type ParsedFoodItem struct {
 	Unit        string     `json:"unit"`
 	Confidence  float64    `json:"confidence"`
 	AIGenerated bool       `json:"ai_generated"`
+
+	// Nutrition of the quantity, from the matched food
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
 }
 
 // NutritionEstimate represents AI-estimated nutrition information
@@ -32,4 +131,7 @@ type NutritionEstimate struct {
 	CarbsPer100g    float64 `json:"carbs_per_100g"`
 	FatPer100g      float64 `json:"fat_per_100g"`
 	FiberPer100g    float64 `json:"fiber_per_100g"`
+
+	// Typical weight of one piece or serving; 0 when unknown
+	GramsPerServing float64 `json:"grams_per_serving,omitempty"`
 }
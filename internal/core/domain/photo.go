@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// StoredPhoto is a photo object in storage
+type StoredPhoto struct {
+	Path      string    `json:"path"` // object path within the bucket, e.g. <user_id>/<timestamp>.jpg
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PhotoCleanupReport summarizes a photo retention cleanup run
+type PhotoCleanupReport struct {
+	DryRun        bool     `json:"dry_run"`
+	UsersScanned  int      `json:"users_scanned"`
+	PhotosScanned int      `json:"photos_scanned"`
+	Deleted       []string `json:"deleted"` // paths deleted, or that would be deleted in a dry run
+	Failed        int      `json:"failed"`
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PortionLimits are the largest portions, in grams, that are still plausible for one
+// logged item of a food category. A larger quantity is flagged as a likely typo, never
+// refused.
+type PortionLimits struct {
+	MaxGrams        map[string]float64 // by lower-case food category
+	DefaultMaxGrams float64            // for other categories and foods without one
+}
+
+// DefaultPortionLimits are generous on purpose: a big plate of rice passes, an extra
+// zero on the butter does not
+var DefaultPortionLimits = PortionLimits{
+	MaxGrams: map[string]float64{
+		"fat":        100,
+		"seeds":      150,
+		"supplement": 150,
+		"nuts":       250,
+		"legume":     800,
+		"dairy":      1000,
+		"grain":      1000,
+		"protein":    1000,
+		"fruit":      1500,
+		"vegetable":  1500,
+	},
+	DefaultMaxGrams: 2000,
+}
+
+// maxPortionSuggestionSteps bounds how many factors of ten a suggested quantity may drop
+const maxPortionSuggestionSteps = 3
+
+// PortionWarning flags a logged quantity far above a typical portion of the food. The item
+// is saved as logged. For weights and volumes, SuggestedQuantity, in the same unit, is what
+// the quantity most likely meant when dropping a zero or two brings it within the limit.
+type PortionWarning struct {
+	FoodID            uuid.UUID `json:"food_id"`
+	FoodName          string    `json:"food_name"`
+	Quantity          float64   `json:"quantity"`
+	Unit              string    `json:"unit"`
+	Grams             float64   `json:"grams"`
+	MaxGrams          float64   `json:"max_grams"`
+	SuggestedQuantity *float64  `json:"suggested_quantity,omitempty"`
+	Message           string    `json:"message"`
+}
+
+// MaxGramsFor returns the portion limit of a food category
+func (l PortionLimits) MaxGramsFor(category string) float64 {
+	if limit, ok := l.MaxGrams[strings.ToLower(strings.TrimSpace(category))]; ok {
+		return limit
+	}
+	return l.DefaultMaxGrams
+}
+
+// Check returns a warning when the item's quantity is over the limit of the food's
+// category, or nil. Items whose weight cannot be worked out from their unit are not checked.
+func (l PortionLimits) Check(item *MealFoodItem, category string) *PortionWarning {
+	maxGrams := l.MaxGramsFor(category)
+	grams, ok := item.Snapshot.Grams(item.Quantity, item.Unit)
+	if !ok || maxGrams <= 0 || grams <= maxGrams {
+		return nil
+	}
+
+	warning := &PortionWarning{
+		FoodID:   item.FoodID,
+		FoodName: item.Snapshot.Name,
+		Quantity: item.Quantity,
+		Unit:     item.Unit,
+		Grams:    roundTenth(grams),
+		MaxGrams: maxGrams,
+	}
+	_, isWeight := estimateUnitGrams[strings.ToLower(strings.TrimSpace(item.Unit))]
+	amount := fmt.Sprintf("%g %s", item.Quantity, item.Unit)
+	if item.Quantity != grams {
+		amount += fmt.Sprintf(" (%g g)", warning.Grams)
+	}
+	warning.Message = fmt.Sprintf("%s of %s is far more than a typical portion (up to %g g)", amount, item.Snapshot.Name, maxGrams)
+
+	// A stray zero is a likely typo in a weight, not in a count of servings
+	if !isWeight {
+		return warning
+	}
+	for step := 1; step <= maxPortionSuggestionSteps; step++ {
+		scale := math.Pow(10, float64(step))
+		if grams/scale <= maxGrams {
+			suggested := item.Quantity / scale
+			warning.SuggestedQuantity = &suggested
+			warning.Message += fmt.Sprintf("; did you mean %g %s?", suggested, item.Unit)
+			break
+		}
+	}
+	return warning
+}
+
+// Grams converts a logged quantity to grams. Weight and volume units convert directly,
+// volumes at the density of water; any other unit counts servings, which convert when the
+// serving size is in such a unit. ok is false when neither applies.
+func (s FoodSnapshot) Grams(quantity float64, unit string) (grams float64, ok bool) {
+	if perUnit, ok := estimateUnitGrams[strings.ToLower(strings.TrimSpace(unit))]; ok {
+		return quantity * perUnit, true
+	}
+	perUnit, ok := estimateUnitGrams[strings.ToLower(strings.TrimSpace(s.ServingUnit))]
+	if !ok || s.ServingSize <= 0 {
+		return 0, false
+	}
+	return quantity * s.ServingSize * perUnit, true
+}
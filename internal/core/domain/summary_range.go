@@ -0,0 +1,16 @@
+package domain
+
+// MaxSummaryRangeDays caps a range of daily summaries; each uncached day is computed on request
+const MaxSummaryRangeDays = 92
+
+// DefaultSummaryRangeDays is the span of a summary range given only its end
+const DefaultSummaryRangeDays = 30
+
+// DailySummaryRange lists a user's daily summaries over a date range, one per day, for
+// charting calories, activity and weight over time
+type DailySummaryRange struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Timezone string          `json:"timezone"`
+	Days     []*DailySummary `json:"days"` // oldest first
+}
@@ -19,6 +19,16 @@ type User struct {
 	WeightKg     *float64   `gorm:"type:decimal(5,2)" json:"weight_kg,omitempty"` // Stored as float64, precision documented
 	ActivityLevel *string   `gorm:"type:varchar(50)" json:"activity_level,omitempty"`
 
+	// Preferences
+	Timezone           string  `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+	UnitSystem         string  `gorm:"type:varchar(20);not null;default:'metric'" json:"unit_system"` // metric, imperial
+	DietaryPreferences *string `gorm:"type:jsonb" json:"dietary_preferences,omitempty"` // JSON object, e.g. {"vegetarian": true}
+	CalorieTargetMode       string   `gorm:"type:varchar(20);not null;default:'static'" json:"calorie_target_mode"` // static, dynamic
+	ExerciseCalorieFraction *float64 `gorm:"type:decimal(3,2)" json:"exercise_calorie_fraction,omitempty"`           // dynamic mode; nil for the default
+
+	EmailVerified   bool       `gorm:"not null;default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
 	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
@@ -28,3 +38,39 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// Location returns the user's timezone, for deciding which day "today" is. A timezone that
+// is unset, unknown or "Local" (the server's zone) falls back to UTC.
+func (u *User) Location() *time.Location {
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil || u.Timezone == "" || u.Timezone == "Local" {
+		return time.UTC
+	}
+	return loc
+}
+
+// Unit systems
+const (
+	UnitSystemMetric   = "metric"
+	UnitSystemImperial = "imperial"
+)
+
+// UnitSystems lists the supported unit systems
+var UnitSystems = []string{UnitSystemMetric, UnitSystemImperial}
+
+// ActivityLevels lists the supported activity levels, least to most active
+var ActivityLevels = []string{"sedentary", "lightly_active", "moderately_active", "very_active", "extremely_active"}
+
+// ProfileUpdate holds profile fields to change; nil fields are left as is
+type ProfileUpdate struct {
+	Name               *string
+	HeightCm           *float64
+	WeightKg           *float64
+	ActivityLevel      *string
+	Timezone           *string
+	UnitSystem         *string
+	DietaryPreferences map[string]interface{}
+
+	CalorieTargetMode       *string
+	ExerciseCalorieFraction *float64
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action kinds recorded in the user action log
+const (
+	ActionCreate = "create"
+	ActionDelete = "delete"
+	ActionUndo   = "undo"
+)
+
+// Entity types whose creates and deletes can be undone
+const (
+	ActionEntityMeal     = "meal"
+	ActionEntityActivity = "activity"
+	ActionEntityMetric   = "metric"
+	ActionEntityWorkout  = "workout"
+)
+
+// UndoWindow is how long after an action it can still be undone
+const UndoWindow = 5 * time.Minute
+
+// UserAction is an entry in a user's append-only action log. Creates and deletes
+// are logged as they happen; undoing one appends an undo entry pointing at it.
+type UserAction struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_user_actions_user" json:"user_id"`
+	Action     string     `gorm:"type:varchar(20);not null" json:"action"`      // create, delete, undo
+	EntityType string     `gorm:"type:varchar(50);not null" json:"entity_type"` // meal, activity, metric, workout
+	EntityID   uuid.UUID  `gorm:"type:uuid;not null" json:"entity_id"`
+	UndoesID   *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"undoes_id,omitempty"` // the action an undo entry reversed
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_user_actions_user" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UserAction) TableName() string {
+	return "user_actions"
+}
+
+// Undoable reports whether the action can still be undone at now. Undo entries
+// themselves cannot be undone.
+func (a *UserAction) Undoable(now time.Time) bool {
+	if a.Action != ActionCreate && a.Action != ActionDelete {
+		return false
+	}
+	return now.Sub(a.CreatedAt) <= UndoWindow
+}
+
+// UndoResult describes the action an undo reversed
+type UndoResult struct {
+	Action      string      `json:"action"` // the action that was undone: create or delete
+	EntityType  string      `json:"entity_type"`
+	EntityID    uuid.UUID   `json:"entity_id"`
+	PerformedAt time.Time   `json:"performed_at"` // when the undone action happened
+	Entity      interface{} `json:"entity"`       // the record as removed (undone create) or restored (undone delete)
+}
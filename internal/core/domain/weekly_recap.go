@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a weekly recap's encouragement came from
+const (
+	RecapEncouragementSourceAI       = "ai"
+	RecapEncouragementSourceTemplate = "template"
+)
+
+// RecapBestLift is the week's set with the highest estimated one-rep max
+type RecapBestLift struct {
+	ExerciseID         uuid.UUID `json:"exercise_id"`
+	ExerciseName       string    `json:"exercise_name"`
+	Weight             float64   `json:"weight"` // kg
+	Reps               int       `json:"reps"`
+	EstimatedOneRepMax float64   `json:"estimated_one_rep_max"` // kg
+}
+
+// WeeklyRecap is a shareable summary of one ISO week (Monday to Sunday in the user's
+// timezone) for notifications, with a one-line encouragement
+type WeeklyRecap struct {
+	Week     string `json:"week"` // ISO week, e.g. 2025-W47
+	From     string `json:"from"` // Monday, YYYY-MM-DD
+	To       string `json:"to"`   // Sunday, YYYY-MM-DD
+	Timezone string `json:"timezone"`
+
+	Workouts         int            `json:"workouts"`                // finished workouts started in the week
+	TotalVolume      float64        `json:"total_volume"`            // kg
+	WeightChange     *float64       `json:"weight_change,omitempty"` // kg, last weigh-in of the week minus the first; omitted with fewer than two
+	CalorieAdherence float64        `json:"calorie_adherence"`       // percentage of the week's days the calorie target was met
+	DaysLogged       int            `json:"days_logged"`
+	BestLift         *RecapBestLift `json:"best_lift,omitempty"`
+
+	Encouragement       string    `json:"encouragement"`
+	EncouragementSource string    `json:"encouragement_source"` // ai or template
+	GeneratedAt         time.Time `json:"generated_at"`         // recaps are cached per ISO week
+}
+
+// ISOWeek formats the ISO week t falls in, e.g. 2025-W47
+func ISOWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// ParseISOWeek returns midnight in loc on the Monday that starts an ISO week such as 2025-W47
+func ParseISOWeek(week string, loc *time.Location) (time.Time, error) {
+	var year, number int
+	if n, err := fmt.Sscanf(week, "%4d-W%2d", &year, &number); err != nil || n != 2 {
+		return time.Time{}, fmt.Errorf("%w: week must look like 2025-W47", ErrInvalidInput)
+	}
+
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(number-1)*7)
+	if number < 1 || ISOWeek(monday) != week {
+		return time.Time{}, fmt.Errorf("%w: %s is not a week of %d", ErrInvalidInput, week, year)
+	}
+	return monday, nil
+}
+
+// FindBestLift returns the working set with the highest estimated one-rep max among the
+// workouts' sets, or nil when none has weight and reps
+func FindBestLift(workouts []*Workout) *RecapBestLift {
+	var best *RecapBestLift
+	for _, workout := range workouts {
+		for _, workoutExercise := range workout.Exercises {
+			for _, set := range workoutExercise.Sets {
+				if set.IsWarmup() {
+					continue
+				}
+				estimate := EstimateOneRepMax(set.Weight, set.Reps)
+				if estimate == nil || (best != nil && *estimate <= best.EstimatedOneRepMax) {
+					continue
+				}
+				best = &RecapBestLift{
+					ExerciseID:         workoutExercise.ExerciseID,
+					ExerciseName:       workoutExercise.Exercise.Name,
+					Weight:             *set.Weight,
+					Reps:               *set.Reps,
+					EstimatedOneRepMax: *estimate,
+				}
+			}
+		}
+	}
+	return best
+}
+
+// TemplateEncouragement picks an encouragement from the recap's data without an LLM
+func (r *WeeklyRecap) TemplateEncouragement() string {
+	switch {
+	case r.Workouts == 0 && r.DaysLogged == 0:
+		return "A quiet week is a fresh start. Log one meal and one workout this week to get rolling again."
+	case r.BestLift != nil && r.Workouts >= 3:
+		return fmt.Sprintf("%d workouts and a %.0f kg %s. Strong week, keep the momentum going!",
+			r.Workouts, r.BestLift.Weight, r.BestLift.ExerciseName)
+	case r.CalorieAdherence >= 70:
+		return "You hit your calorie target most days this week. That consistency is what drives results."
+	case r.Workouts > 0:
+		return fmt.Sprintf("%d workout%s in the books. Every session counts, aim for one more next week.",
+			r.Workouts, plural(r.Workouts))
+	default:
+		return fmt.Sprintf("You logged food on %d day%s this week. Keep tracking and add a workout next week.",
+			r.DaysLogged, plural(r.DaysLogged))
+	}
+}
+
+// plural returns the "s" for n of something, none for one
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
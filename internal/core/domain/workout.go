@@ -1,9 +1,12 @@
 package domain
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Workout represents a workout session
@@ -16,18 +19,32 @@ type Workout struct {
 
 	DurationMinutes    *int     `gorm:"type:integer" json:"duration_minutes,omitempty"`
 	CaloriesBurned     *float64 `gorm:"type:decimal(10,2)" json:"calories_burned,omitempty"` // Stored as float64, precision 10,2
+	CalorieSource      *string  `gorm:"type:varchar(20)" json:"calorie_source,omitempty"` // device, manual or estimate
 	AverageHeartRate   *int     `gorm:"type:integer" json:"average_heart_rate,omitempty"`
 	MaxHeartRate       *int     `gorm:"type:integer" json:"max_heart_rate,omitempty"`
 
 	Notes *string `gorm:"type:text" json:"notes,omitempty"`
 
-	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	// Totals stored when the workout is finished, so they need not be recomputed from its sets
+	TotalSets           *int     `gorm:"type:integer" json:"total_sets,omitempty"`
+	TotalReps           *int     `gorm:"type:integer" json:"total_reps,omitempty"`
+	TotalVolume         *float64 `gorm:"type:decimal(12,2)" json:"total_volume,omitempty"` // kg, weight × reps summed over sets
+	PersonalRecordCount *int     `gorm:"type:integer" json:"personal_record_count,omitempty"`
+
+	// Set on sample workouts added by the demo seed
+	IsDemo bool `gorm:"not null;default:false" json:"is_demo,omitempty"`
+
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // soft-deleted so a delete can be undone
+
+	// Summary is computed when the workout is finished; it is not stored
+	Summary *WorkoutSummary `gorm:"-" json:"summary,omitempty"`
 
 	// Relationships
 	User      User              `gorm:"foreignKey:UserID" json:"-"`
 	Exercises []WorkoutExercise `gorm:"foreignKey:WorkoutID" json:"exercises,omitempty"`
+	Pauses    []WorkoutPause    `gorm:"foreignKey:WorkoutID" json:"pauses,omitempty"`
 }
 
 // TableName specifies the table name for GORM
@@ -35,6 +52,187 @@ func (Workout) TableName() string {
 	return "workouts"
 }
 
+// Workout statuses a workout list can be filtered by
+const (
+	WorkoutStatusInProgress = "in_progress" // not finished yet
+	WorkoutStatusCompleted  = "completed"
+)
+
+// WorkoutFilter narrows a workout list
+type WorkoutFilter struct {
+	ExerciseID *uuid.UUID // only workouts containing this exercise or one of its aliases
+	Status     string     // WorkoutStatusInProgress or WorkoutStatusCompleted; empty for all
+}
+
+// OpenPause returns the pause the workout is currently in, or nil when it is not paused
+func (w *Workout) OpenPause() *WorkoutPause {
+	for i := range w.Pauses {
+		if w.Pauses[i].ResumedAt == nil {
+			return &w.Pauses[i]
+		}
+	}
+	return nil
+}
+
+// ActiveDuration is the time between the workout's start and end (or at, while it
+// is still going) minus the time spent paused. A pause that has not been resumed
+// counts until end or at.
+func (w *Workout) ActiveDuration(at time.Time) time.Duration {
+	end := at
+	if w.EndTime != nil {
+		end = *w.EndTime
+	}
+	if !end.After(w.StartTime) {
+		return 0
+	}
+
+	active := end.Sub(w.StartTime)
+	for _, pause := range w.Pauses {
+		pausedAt := pause.PausedAt
+		if pausedAt.Before(w.StartTime) {
+			pausedAt = w.StartTime
+		}
+		resumedAt := end
+		if pause.ResumedAt != nil && pause.ResumedAt.Before(end) {
+			resumedAt = *pause.ResumedAt
+		}
+		if resumedAt.After(pausedAt) {
+			active -= resumedAt.Sub(pausedAt)
+		}
+	}
+	return max(0, active)
+}
+
+// TotalWork sums the sets, reps and volume (weight × reps, in kg) logged in the workout.
+// Warmup sets are not counted.
+func (w *Workout) TotalWork() (sets, reps int, volume float64) {
+	for _, exercise := range w.Exercises {
+		for _, set := range exercise.Sets {
+			if set.IsWarmup() {
+				continue
+			}
+			sets++
+			if set.Reps == nil {
+				continue
+			}
+			reps += *set.Reps
+			if set.Weight != nil {
+				volume += *set.Weight * float64(*set.Reps)
+			}
+		}
+	}
+	return sets, reps, volume
+}
+
+// AverageRPE averages the RPE of the workout's working sets that have one, rounded to one
+// decimal. It is nil when no working set was rated.
+func (w *Workout) AverageRPE() *float64 {
+	var total float64
+	var rated int
+	for _, exercise := range w.Exercises {
+		for _, set := range exercise.Sets {
+			if set.IsWarmup() || set.RPE == nil {
+				continue
+			}
+			total += *set.RPE
+			rated++
+		}
+	}
+	if rated == 0 {
+		return nil
+	}
+	average := math.Round(total/float64(rated)*10) / 10
+	return &average
+}
+
+// WorkoutSummary is what a finished workout achieved, for the post-workout screen
+type WorkoutSummary struct {
+	DurationMinutes int              `json:"duration_minutes"` // pauses excluded
+	TotalSets       int              `json:"total_sets"`
+	TotalReps       int              `json:"total_reps"`
+	TotalVolume     float64          `json:"total_volume"` // kg
+	CaloriesBurned  float64          `json:"calories_burned"`
+	AverageRPE      *float64         `json:"average_rpe,omitempty"` // working sets with an RPE only
+	PersonalRecords []PersonalRecord `json:"personal_records"`
+}
+
+// Kinds of personal record
+const (
+	PersonalRecordWeight       = "weight"
+	PersonalRecordEstimated1RM = "estimated_1rm"
+)
+
+// PersonalRecord is a set that beat the user's best on an exercise in earlier workouts
+type PersonalRecord struct {
+	ExerciseID   uuid.UUID `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name"`
+	SetID        uuid.UUID `json:"set_id"`
+	Kind         string    `json:"kind"`          // PersonalRecordWeight or PersonalRecordEstimated1RM
+	Value        float64   `json:"value"`         // kg
+	PreviousBest float64   `json:"previous_best"` // kg
+}
+
+// FindPersonalRecords compares an exercise's sets in a workout with previous, the user's
+// sets of the exercise in earlier workouts, and returns a record for the heaviest weight
+// and for the best estimated one-rep max that beat the earlier bests. Without earlier
+// weighted sets nothing is a record: a first session only sets the baseline. Warmup sets
+// are ignored on both sides.
+func FindPersonalRecords(exercise Exercise, sets []WorkoutSet, previous []*ExerciseSetRecord) []PersonalRecord {
+	var bestWeight, best1RM float64
+	for _, record := range previous {
+		if record.IsWarmup() {
+			continue
+		}
+		if record.Weight != nil {
+			bestWeight = max(bestWeight, *record.Weight)
+		}
+		if estimate := EstimateOneRepMax(record.Weight, record.Reps); estimate != nil {
+			best1RM = max(best1RM, *estimate)
+		}
+	}
+
+	var weightRecord, estimateRecord *PersonalRecord
+	for _, set := range sets {
+		if set.IsWarmup() {
+			continue
+		}
+		if bestWeight > 0 && set.Weight != nil && *set.Weight > bestWeight &&
+			(weightRecord == nil || *set.Weight > weightRecord.Value) {
+			weightRecord = &PersonalRecord{Kind: PersonalRecordWeight, SetID: set.ID, Value: *set.Weight, PreviousBest: bestWeight}
+		}
+		if estimate := EstimateOneRepMax(set.Weight, set.Reps); best1RM > 0 && estimate != nil && *estimate > best1RM &&
+			(estimateRecord == nil || *estimate > estimateRecord.Value) {
+			estimateRecord = &PersonalRecord{Kind: PersonalRecordEstimated1RM, SetID: set.ID, Value: *estimate, PreviousBest: best1RM}
+		}
+	}
+
+	var records []PersonalRecord
+	for _, record := range []*PersonalRecord{weightRecord, estimateRecord} {
+		if record != nil {
+			record.ExerciseID = exercise.ID
+			record.ExerciseName = exercise.Name
+			records = append(records, *record)
+		}
+	}
+	return records
+}
+
+// WorkoutPause is a break taken during a workout, e.g. a long rest or the app
+// being in the background. It does not count toward the workout's duration.
+type WorkoutPause struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkoutID uuid.UUID  `gorm:"type:uuid;not null;index" json:"workout_id"`
+	PausedAt  time.Time  `gorm:"not null" json:"paused_at"`
+	ResumedAt *time.Time `json:"resumed_at,omitempty"` // nil while the workout is paused
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WorkoutPause) TableName() string {
+	return "workout_pauses"
+}
+
 // WorkoutExercise represents an exercise performed in a workout
 type WorkoutExercise struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -49,6 +247,9 @@ type WorkoutExercise struct {
 	Workout  Workout      `gorm:"foreignKey:WorkoutID" json:"-"`
 	Exercise Exercise     `gorm:"foreignKey:ExerciseID" json:"exercise,omitempty"`
 	Sets     []WorkoutSet `gorm:"foreignKey:WorkoutExerciseID" json:"sets,omitempty"`
+
+	// Sets suggested before any are logged, e.g. the sets of the workout this one was cloned from
+	PlannedSets []PlannedSet `gorm:"foreignKey:WorkoutExerciseID" json:"planned_sets,omitempty"`
 }
 
 // TableName specifies the table name for GORM
@@ -56,6 +257,46 @@ func (WorkoutExercise) TableName() string {
 	return "workout_exercises"
 }
 
+// ReorderWorkoutExercises puts the workout exercises listed in ids into that order and
+// renumbers OrderIndex from 0. exercises must be in their current order. The listed
+// exercises share the positions they already held, so a partial list leaves the others
+// where they are.
+func ReorderWorkoutExercises(exercises []*WorkoutExercise, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: at least one workout exercise ID is required", ErrInvalidInput)
+	}
+
+	byID := make(map[uuid.UUID]*WorkoutExercise, len(exercises))
+	for _, we := range exercises {
+		byID[we.ID] = we
+	}
+	listed := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := byID[id]; !ok {
+			return fmt.Errorf("%w: workout exercise %s does not belong to this workout", ErrInvalidInput, id)
+		}
+		if listed[id] {
+			return fmt.Errorf("%w: workout exercise %s is listed more than once", ErrInvalidInput, id)
+		}
+		listed[id] = true
+	}
+
+	reordered := make([]*WorkoutExercise, len(exercises))
+	next := 0
+	for i, we := range exercises {
+		if listed[we.ID] {
+			we = byID[ids[next]]
+			next++
+		}
+		reordered[i] = we
+	}
+	for i, we := range reordered {
+		we.OrderIndex = i
+	}
+	copy(exercises, reordered)
+	return nil
+}
+
 // WorkoutSet represents a set in an exercise
 type WorkoutSet struct {
 	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -68,7 +309,15 @@ type WorkoutSet struct {
 	Distance           *float64 `gorm:"type:decimal(10,2)" json:"distance,omitempty"`    // Stored as float64, precision 10,2, in meters
 	RestSeconds        *int     `gorm:"type:integer" json:"rest_seconds,omitempty"`
 
-	Notes *string `gorm:"type:text" json:"notes,omitempty"`
+	// SetType is normal, warmup, drop or superset. Sets sharing a GroupID were done as one
+	// block: the exercises of a superset alternated, or the weight steps of a drop set.
+	SetType string     `gorm:"type:varchar(20);not null;default:'normal'" json:"set_type"`
+	GroupID *uuid.UUID `gorm:"type:uuid;index" json:"group_id,omitempty"`
+
+	// RPE is how hard the set felt on the 0-10 rate of perceived exertion scale, where 10
+	// means no rep was left in reserve. Half points are common, e.g. 8.5.
+	RPE   *float64 `gorm:"column:rpe;type:decimal(3,1)" json:"rpe,omitempty"`
+	Notes *string  `gorm:"type:text" json:"notes,omitempty"`
 
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 
@@ -80,3 +329,209 @@ type WorkoutSet struct {
 func (WorkoutSet) TableName() string {
 	return "workout_sets"
 }
+
+// PlannedSet is a set a workout exercise is expected to have: a starting point shown to
+// the user, not something performed. Sets actually done are logged as WorkoutSets.
+type PlannedSet struct {
+	ID                uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkoutExerciseID uuid.UUID `gorm:"type:uuid;not null;index" json:"workout_exercise_id"`
+	SetNumber         int       `gorm:"type:integer;not null" json:"set_number"`
+	SetType           string    `gorm:"type:varchar(20);not null;default:'normal'" json:"set_type"`
+
+	Reps            *int     `gorm:"type:integer" json:"reps,omitempty"`
+	Weight          *float64 `gorm:"type:decimal(10,2)" json:"weight,omitempty"` // suggested, in kg
+	DurationSeconds *int     `gorm:"type:integer" json:"duration_seconds,omitempty"`
+	Distance        *float64 `gorm:"type:decimal(10,2)" json:"distance,omitempty"` // in meters
+	RestSeconds     *int     `gorm:"type:integer" json:"rest_seconds,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PlannedSet) TableName() string {
+	return "workout_planned_sets"
+}
+
+// PlanFromSet returns a planned set repeating what was done in set
+func PlanFromSet(set WorkoutSet) PlannedSet {
+	return PlannedSet{
+		ID:              uuid.New(),
+		SetNumber:       set.SetNumber,
+		SetType:         set.SetType,
+		Reps:            set.Reps,
+		Weight:          set.Weight,
+		DurationSeconds: set.DurationSeconds,
+		Distance:        set.Distance,
+		RestSeconds:     set.RestSeconds,
+	}
+}
+
+// MaxSetBatchSize caps how many sets one bulk request may log
+const MaxSetBatchSize = 50
+
+// SetBatchResult reports the sets a bulk request logged and the ones it rejected
+type SetBatchResult struct {
+	Received int             `json:"received"`
+	Created  int             `json:"created"`
+	Failed   int             `json:"failed"`
+	Sets     []*WorkoutSet   `json:"sets"`
+	Errors   []SetBatchError `json:"errors"`
+}
+
+// SetBatchError is why one set of a bulk request was rejected
+type SetBatchError struct {
+	Index   int    `json:"index"` // position of the set in the request
+	Message string `json:"message"`
+}
+
+// Set types
+const (
+	SetTypeNormal   = "normal"
+	SetTypeWarmup   = "warmup"
+	SetTypeDrop     = "drop"     // a step of reduced weight in a drop set
+	SetTypeSuperset = "superset" // alternated with another exercise's sets in the same group
+)
+
+// IsValidSetType reports whether setType is a known set type
+func IsValidSetType(setType string) bool {
+	switch setType {
+	case SetTypeNormal, SetTypeWarmup, SetTypeDrop, SetTypeSuperset:
+		return true
+	}
+	return false
+}
+
+// MaxRPE is the top of the rate of perceived exertion scale: a set taken to failure
+const MaxRPE = 10.0
+
+// ValidateRPE checks that a set's RPE, when given, is on the 0-10 scale
+func ValidateRPE(rpe *float64) error {
+	if rpe != nil && (*rpe < 0 || *rpe > MaxRPE || math.IsNaN(*rpe)) {
+		return fmt.Errorf("%w: rpe must be between 0 and %.0f", ErrInvalidInput, MaxRPE)
+	}
+	return nil
+}
+
+// IsWarmup reports whether the set was a warmup. Warmups are left out of volume and
+// personal records.
+func (s WorkoutSet) IsWarmup() bool {
+	return s.SetType == SetTypeWarmup
+}
+
+// ExerciseSetRecord is one set of an exercise with the workout it was performed in
+type ExerciseSetRecord struct {
+	SetID        uuid.UUID  `json:"set_id"`
+	WorkoutID    uuid.UUID  `json:"workout_id"`
+	WorkoutName  string     `json:"workout_name"`
+	PerformedAt  time.Time  `json:"performed_at"` // workout start time
+	SetNumber    int        `json:"set_number"`
+	SetType      string     `json:"set_type"`
+	GroupID      *uuid.UUID `json:"group_id,omitempty"`
+	Reps         *int       `json:"reps,omitempty"`
+	Weight       *float64   `json:"weight,omitempty"` // kg
+	RPE          *float64   `json:"rpe,omitempty"`
+	Estimated1RM *float64   `json:"estimated_1rm,omitempty"`
+}
+
+// IsWarmup reports whether the set was a warmup
+func (r *ExerciseSetRecord) IsWarmup() bool {
+	return r.SetType == SetTypeWarmup
+}
+
+// EstimateOneRepMax estimates a one-rep max with the Brzycki formula: weight × 36 / (37 − reps).
+// It returns nil when the set has no weight or reps, or too many reps for the formula to hold.
+func EstimateOneRepMax(weight *float64, reps *int) *float64 {
+	if weight == nil || reps == nil || *weight <= 0 || *reps <= 0 || *reps >= 37 {
+		return nil
+	}
+
+	estimate := *weight
+	if *reps > 1 {
+		estimate = *weight * 36 / (37 - float64(*reps))
+	}
+	return &estimate
+}
+
+// ExercisePerformanceRecentSets is how many of the latest sets an exercise's performance lists
+const ExercisePerformanceRecentSets = 10
+
+// ExercisePerformance summarizes a user's history with one exercise
+type ExercisePerformance struct {
+	TotalSets       int                  `json:"total_sets"`
+	WorkoutCount    int                  `json:"workout_count"`
+	LastPerformedAt *time.Time           `json:"last_performed_at,omitempty"`
+	RecentSets      []*ExerciseSetRecord `json:"recent_sets"` // newest first
+
+	// Best estimated one-rep max of the latest workout with a weighted working set
+	CurrentEstimated1RM *float64 `json:"current_estimated_1rm,omitempty"`
+
+	PersonalRecords ExercisePersonalRecords `json:"personal_records"`
+}
+
+// ExercisePersonalRecords holds the user's best sets of an exercise, warmups aside. Each is
+// nil until a set that qualifies is logged; ties keep the first set that reached the record.
+type ExercisePersonalRecords struct {
+	HeaviestWeight   *ExerciseSetRecord `json:"heaviest_weight,omitempty"`
+	MostReps         *ExerciseSetRecord `json:"most_reps,omitempty"`
+	BestEstimated1RM *ExerciseSetRecord `json:"best_estimated_1rm,omitempty"`
+}
+
+// SummarizeExercisePerformance builds an exercise's performance from all of the user's
+// sets of it, oldest first, with Estimated1RM already filled in
+func SummarizeExercisePerformance(records []*ExerciseSetRecord) *ExercisePerformance {
+	performance := &ExercisePerformance{
+		TotalSets:  len(records),
+		RecentSets: []*ExerciseSetRecord{},
+	}
+	if len(records) == 0 {
+		return performance
+	}
+
+	prs := performance.PersonalRecords
+	var lastWorkout uuid.UUID
+	for _, record := range records {
+		if record.WorkoutID != lastWorkout {
+			performance.WorkoutCount++
+			lastWorkout = record.WorkoutID
+		}
+		if record.IsWarmup() {
+			continue
+		}
+		if record.Weight != nil && (prs.HeaviestWeight == nil || *record.Weight > *prs.HeaviestWeight.Weight) {
+			prs.HeaviestWeight = record
+		}
+		if record.Reps != nil && (prs.MostReps == nil || *record.Reps > *prs.MostReps.Reps) {
+			prs.MostReps = record
+		}
+		if record.Estimated1RM != nil && (prs.BestEstimated1RM == nil || *record.Estimated1RM > *prs.BestEstimated1RM.Estimated1RM) {
+			prs.BestEstimated1RM = record
+		}
+	}
+	performance.PersonalRecords = prs
+
+	last := records[len(records)-1].PerformedAt
+	performance.LastPerformedAt = &last
+
+	for i := len(records) - 1; i >= 0 && len(performance.RecentSets) < ExercisePerformanceRecentSets; i-- {
+		performance.RecentSets = append(performance.RecentSets, records[i])
+	}
+
+	// Sets of one workout are adjacent, so the latest weighted workout is found walking back
+	var currentWorkout uuid.UUID
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		if currentWorkout != uuid.Nil && record.WorkoutID != currentWorkout {
+			break
+		}
+		if record.Estimated1RM == nil || record.IsWarmup() {
+			continue
+		}
+		currentWorkout = record.WorkoutID
+		if performance.CurrentEstimated1RM == nil || *record.Estimated1RM > *performance.CurrentEstimated1RM {
+			estimate := *record.Estimated1RM
+			performance.CurrentEstimated1RM = &estimate
+		}
+	}
+
+	return performance
+}
@@ -8,6 +8,12 @@ import (
 	"fitness-tracker/internal/core/domain"
 )
 
+// Transactor runs several repository calls as one unit. Repository calls made with the
+// context passed to fn share its transaction.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
@@ -18,6 +24,20 @@ type UserRepository interface {
 	List(ctx context.Context, limit, offset int) ([]*domain.User, error)
 }
 
+// UserTokenRepository defines the interface for single-use user token operations
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *domain.UserToken) error
+	GetByHash(ctx context.Context, purpose, tokenHash string) (*domain.UserToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string) error
+}
+
+// AccountRepository defines the interface for whole-account data operations
+type AccountRepository interface {
+	ExportUserData(ctx context.Context, userID uuid.UUID) (*domain.UserDataExport, error)
+	PurgeUser(ctx context.Context, userID uuid.UUID) error
+}
+
 // FoodRepository defines the interface for food data operations
 type FoodRepository interface {
 	Create(ctx context.Context, food *domain.Food) error
@@ -26,7 +46,13 @@ type FoodRepository interface {
 	Update(ctx context.Context, food *domain.Food) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, limit, offset int) ([]*domain.Food, error)
-	Search(ctx context.Context, query string, limit, offset int) ([]*domain.Food, error)
+	// Search matches every word of query against name, brand or description, ranking name
+	// matches above brand matches above description matches
+	Search(ctx context.Context, query string, filter domain.FoodSearchFilter, limit, offset int) ([]*domain.Food, error)
+	// ListByCategory returns foods whose category matches ignoring case, verified foods first
+	ListByCategory(ctx context.Context, category string, limit int) ([]*domain.Food, error)
+	// ListVerified returns verified foods with calories and a serving size, the most logged first
+	ListVerified(ctx context.Context, limit int) ([]*domain.Food, error)
 
 	// Ingredient operations
 	AddIngredient(ctx context.Context, ingredient *domain.FoodIngredient) error
@@ -42,13 +68,50 @@ type FoodRepository interface {
 	ListServingUnits(ctx context.Context) ([]*domain.ServingUnit, error)
 }
 
+// FoodSearchCache keeps recent food search results for a short time. Implementations
+// return results the caller may modify without changing the cached ones.
+type FoodSearchCache interface {
+	Get(ctx context.Context, key domain.FoodSearchKey) ([]*domain.Food, bool)
+	Set(ctx context.Context, key domain.FoodSearchKey, foods []*domain.Food)
+	// Invalidate drops the results that list the food or that it may now match
+	Invalidate(ctx context.Context, food *domain.Food)
+	Stats() domain.CacheStats
+}
+
+// FoodRevisionRepository defines the interface for the food change history
+type FoodRevisionRepository interface {
+	Create(ctx context.Context, revision *domain.FoodRevision) error
+	ListByFood(ctx context.Context, foodID uuid.UUID, limit, offset int) ([]*domain.FoodRevision, error)
+}
+
+// FoodStarRepository defines the interface for the foods users starred
+type FoodStarRepository interface {
+	// Star is a no-op when the food is already starred
+	Star(ctx context.Context, star *domain.FoodStar) error
+	Unstar(ctx context.Context, userID, foodID uuid.UUID) error
+	// ListFoods returns the user's starred foods, most recently starred first
+	ListFoods(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Food, error)
+	// StarredIDs returns which of foodIDs the user starred
+	StarredIDs(ctx context.Context, userID uuid.UUID, foodIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
 // MealRepository defines the interface for meal data operations
 type MealRepository interface {
 	Create(ctx context.Context, meal *domain.Meal) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Meal, error)
 	Update(ctx context.Context, meal *domain.Meal) error
+	// UpdateTotals writes only the meal's calculated totals
+	UpdateTotals(ctx context.Context, meal *domain.Meal) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 	ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, limit, offset int) ([]*domain.Meal, error)
+	// ListByUserAfter returns up to limit meals, newest logged first, continuing past after;
+	// a nil cursor starts from the newest
+	ListByUserAfter(ctx context.Context, userID uuid.UUID, after *domain.PageCursor, limit int) ([]*domain.Meal, error)
+	ListRecentFoods(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*domain.RecentFood, error)
+	SumNutritionByDay(ctx context.Context, userID uuid.UUID, start, end time.Time, timezone string) ([]*domain.DailyNutrition, error)
+	ListDeletedMealPhotoPaths(ctx context.Context, userID uuid.UUID, paths []string) ([]string, error)
+	ListLoggedDays(ctx context.Context, userID uuid.UUID, timezone string) ([]string, error)
 
 	// Food item operations
 	AddFoodItem(ctx context.Context, item *domain.MealFoodItem) error
@@ -63,7 +126,11 @@ type ActivityRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Activity, error)
 	Update(ctx context.Context, activity *domain.Activity) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 	ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, limit, offset int) ([]*domain.Activity, error)
+	// ListByUserAfter returns up to limit activities, newest logged first, continuing past
+	// after; a nil cursor starts from the newest
+	ListByUserAfter(ctx context.Context, userID uuid.UUID, after *domain.PageCursor, limit int) ([]*domain.Activity, error)
 	GetTotalsByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) (map[string]interface{}, error)
 }
 
@@ -73,22 +140,39 @@ type WorkoutRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Workout, error)
 	Update(ctx context.Context, workout *domain.Workout) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, limit, offset int) ([]*domain.Workout, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	ListByUser(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, filter domain.WorkoutFilter, limit, offset int) ([]*domain.Workout, error)
+	ListCompletedDays(ctx context.Context, userID uuid.UUID, timezone string) ([]string, error)
+	FirstCompletedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	FirstPersonalRecordAt(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+
+	// Pause operations
+	AddPause(ctx context.Context, pause *domain.WorkoutPause) error
+	UpdatePause(ctx context.Context, pause *domain.WorkoutPause) error
 
 	// Exercise operations
 	CreateExercise(ctx context.Context, exercise *domain.Exercise) error
 	GetExercise(ctx context.Context, id uuid.UUID) (*domain.Exercise, error)
+	// FindExerciseByName matches the name ignoring case; ErrNotFound when there is none
+	FindExerciseByName(ctx context.Context, name string) (*domain.Exercise, error)
+	// ListSimilarExercises returns exercises whose names closely resemble name, best match first
+	ListSimilarExercises(ctx context.Context, name string, limit int) ([]*domain.Exercise, error)
 	ListExercises(ctx context.Context, category string, limit, offset int) ([]*domain.Exercise, error)
 
 	// Workout exercise operations
 	AddWorkoutExercise(ctx context.Context, workoutExercise *domain.WorkoutExercise) error
 	GetWorkoutExercises(ctx context.Context, workoutID uuid.UUID) ([]*domain.WorkoutExercise, error)
+	// UpdateWorkoutExerciseOrder saves the OrderIndex of each exercise in one transaction
+	UpdateWorkoutExerciseOrder(ctx context.Context, workoutID uuid.UUID, exercises []*domain.WorkoutExercise) error
 
 	// Set operations
 	AddSet(ctx context.Context, set *domain.WorkoutSet) error
+	// AddSets inserts the sets in one statement, so either all of them are saved or none
+	AddSets(ctx context.Context, sets []*domain.WorkoutSet) error
 	UpdateSet(ctx context.Context, set *domain.WorkoutSet) error
 	DeleteSet(ctx context.Context, id uuid.UUID) error
 	GetSets(ctx context.Context, workoutExerciseID uuid.UUID) ([]*domain.WorkoutSet, error)
+	ListExerciseSets(ctx context.Context, userID, exerciseID uuid.UUID, startDate, endDate time.Time) ([]*domain.ExerciseSetRecord, error)
 }
 
 // MetricRepository defines the interface for metric data operations
@@ -97,7 +181,13 @@ type MetricRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Metric, error)
 	Update(ctx context.Context, metric *domain.Metric) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 	ListByUser(ctx context.Context, userID uuid.UUID, metricType string, startDate, endDate time.Time, limit, offset int) ([]*domain.Metric, error)
+	ListMeasuredDays(ctx context.Context, userID uuid.UUID, metricType, timezone string) ([]string, error)
+	// CreateBatch inserts one user's metrics in one transaction, skipping any whose type and
+	// measured_at match a stored metric of the user (deleted ones included) or an earlier one
+	// in the batch, and returns those inserted
+	CreateBatch(ctx context.Context, metrics []*domain.Metric) ([]*domain.Metric, error)
 
 	// Daily summary operations
 	CreateOrUpdateDailySummary(ctx context.Context, summary *domain.DailySummary) error
@@ -114,16 +204,75 @@ type GoalRepository interface {
 	ListByUser(ctx context.Context, userID uuid.UUID, status string, limit, offset int) ([]*domain.Goal, error)
 }
 
+// NutritionTargetRepository defines the interface for nutrition target data operations
+type NutritionTargetRepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*domain.NutritionTarget, error)
+	Upsert(ctx context.Context, target *domain.NutritionTarget) error
+}
+
+// MealDistributionRepository defines the interface for meal distribution data operations
+type MealDistributionRepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*domain.MealDistribution, error)
+	Upsert(ctx context.Context, distribution *domain.MealDistribution) error
+}
+
 // ConversationRepository defines the interface for conversation data operations
 type ConversationRepository interface {
 	Create(ctx context.Context, conversation *domain.Conversation) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Conversation, error)
 	Update(ctx context.Context, conversation *domain.Conversation) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// ListByUser returns the user's conversations, most recently active first
 	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Conversation, error)
+	// GetActivity returns the message count and latest message of each conversation that has messages
+	GetActivity(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]domain.ConversationActivity, error)
+	// SaveSummary stores the summary of the conversation's messages up to through
+	SaveSummary(ctx context.Context, conversationID uuid.UUID, summary string, through time.Time) error
 
 	// Message operations
 	AddMessage(ctx context.Context, message *domain.Message) error
 	GetMessages(ctx context.Context, conversationID uuid.UUID, limit, offset int) ([]*domain.Message, error)
 	GetLatestMessages(ctx context.Context, conversationID uuid.UUID, limit int) ([]*domain.Message, error)
+	GetMessagesBefore(ctx context.Context, conversationID uuid.UUID, before time.Time, limit int) ([]*domain.Message, error)
+	// GetMessagesAfter returns up to limit messages, oldest first, continuing past after;
+	// a nil cursor starts from the first message
+	GetMessagesAfter(ctx context.Context, conversationID uuid.UUID, after *domain.PageCursor, limit int) ([]*domain.Message, error)
+}
+
+// LLMAuditRepository defines the interface for LLM call audit entries
+type LLMAuditRepository interface {
+	Create(ctx context.Context, entry *domain.LLMAuditEntry) error
+	List(ctx context.Context, filter domain.LLMAuditFilter, limit, offset int) ([]*domain.LLMAuditEntry, error)
+}
+
+// AchievementRepository defines the interface for earned achievements
+type AchievementRepository interface {
+	CreateIfMissing(ctx context.Context, achievement *domain.Achievement) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Achievement, error)
+}
+
+// UserActionRepository defines the interface for the append-only user action log
+type UserActionRepository interface {
+	Create(ctx context.Context, action *domain.UserAction) error
+	GetLatest(ctx context.Context, userID uuid.UUID) (*domain.UserAction, error)
+}
+
+// JobRunRepository records scheduled job runs and keeps instances from running a job at the same time
+type JobRunRepository interface {
+	// TryLock takes the job's advisory lock without waiting. ok is false when another instance
+	// holds it. release must be called once the run is over.
+	TryLock(ctx context.Context, name string) (release func(), ok bool, err error)
+	GetLastRun(ctx context.Context, name string) (*domain.JobRun, error)
+	SaveRun(ctx context.Context, run *domain.JobRun) error
+}
+
+// DemoRepository stores and removes the sample data of the demo seed
+type DemoRepository interface {
+	// HasDemoData reports whether the user has any sample records, including soft-deleted ones
+	HasDemoData(ctx context.Context, userID uuid.UUID) (bool, error)
+	// CreateDemoData stores all the sample records in a single transaction
+	CreateDemoData(ctx context.Context, data *domain.DemoData) error
+	// DeleteDemoData hard-deletes the user's sample records and anything logged under them,
+	// returning the deleted records
+	DeleteDemoData(ctx context.Context, userID uuid.UUID) (*domain.DemoData, error)
 }
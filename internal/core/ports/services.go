@@ -17,23 +17,81 @@ type AuthService interface {
 	ComparePassword(hashedPassword, password string) error
 	GenerateJWT(userID string) (string, error)
 	ParseJWT(token string) (string, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	SendVerificationEmail(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, token string) error
+}
+
+// ProfileService handles reading and updating the user's profile
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID string) (*domain.User, error)
+	UpdateProfile(ctx context.Context, userID string, update *domain.ProfileUpdate) (*domain.User, error)
+	GetNutritionTargets(ctx context.Context, userID string) (*domain.NutritionTarget, error)
+	SetNutritionTargets(ctx context.Context, userID string, target *domain.NutritionTarget) (*domain.NutritionTarget, error)
+	// DeriveNutritionTargets sets the target from the profile. With a goal type, e.g. cut,
+	// the macros follow that goal's macro preset instead of the protein-per-kg defaults.
+	DeriveNutritionTargets(ctx context.Context, userID, goalType string) (*domain.NutritionTarget, error)
+	GetMealDistribution(ctx context.Context, userID string) (*domain.MealDistribution, error)
+	SetMealDistribution(ctx context.Context, userID string, distribution *domain.MealDistribution) (*domain.MealDistribution, error)
 }
 
 // FoodService handles food database operations
 type FoodService interface {
-	SearchFoods(ctx context.Context, query string, visibility *string, limit int) ([]*domain.Food, error)
+	SearchFoods(ctx context.Context, query string, filter domain.FoodSearchFilter, limit int) ([]*domain.Food, error)
 	GetFood(ctx context.Context, foodID string) (*domain.Food, error)
+	GetRecentFoods(ctx context.Context, userID string, limit int) ([]*domain.RecentFood, error)
+	ListServingUnits(ctx context.Context) ([]domain.ServingUnitGroup, error)
 	CreateFood(ctx context.Context, food *domain.Food) (*domain.Food, error)
-	UpdateFood(ctx context.Context, foodID string, updates map[string]interface{}) (*domain.Food, error)
+	UpdateFood(ctx context.Context, userID, foodID string, updates map[string]interface{}) (*domain.Food, error)
+	GetFoodHistory(ctx context.Context, foodID string, limit, offset int) ([]*domain.FoodRevision, error)
+	GetFoodAlternatives(ctx context.Context, foodID string, limit int) (*domain.FoodAlternatives, error)
 	CreateAIGeneratedFood(ctx context.Context, name string, nutritionData map[string]interface{}) (*domain.Food, error)
+	StarFood(ctx context.Context, userID, foodID string) error
+	UnstarFood(ctx context.Context, userID, foodID string) error
+	GetStarredFoods(ctx context.Context, userID string, limit, offset int) ([]*domain.Food, error)
+	// MarkStarred sets IsStarred on the foods the user starred
+	MarkStarred(ctx context.Context, userID string, foods []*domain.Food)
+}
+
+// FoodEstimator estimates the nutrition of a freeform food without saving it
+type FoodEstimator interface {
+	EstimateFood(ctx context.Context, userID uuid.UUID, name string, quantity float64, unit string) (*domain.FoodEstimate, error)
+}
+
+// MealComparisonService compares meals with the user's typical meal of the same type
+type MealComparisonService interface {
+	CompareMeal(ctx context.Context, userID, mealType string, totals domain.MacroTargets) (*domain.MealComparison, error)
+	CompareLoggedMeal(ctx context.Context, userID, mealID string) (*domain.MealComparison, error)
+}
+
+// MealSuggestionService proposes meals from the food database that fit a macro target
+type MealSuggestionService interface {
+	SuggestMeal(ctx context.Context, userID string, target domain.MacroTargets) (*domain.MealSuggestion, error)
+}
+
+// ParsedMealStore keeps parsed meals until the user confirms them
+type ParsedMealStore interface {
+	// RememberParsedMeal gives the meal a new ID and keeps it for the user
+	RememberParsedMeal(userID uuid.UUID, meal *domain.ParsedMeal)
+	GetParsedMeal(userID, parsedMealID uuid.UUID) (*domain.ParsedMeal, error)
+	// TakeParsedMeal returns the meal and hides it from other callers while it is logged.
+	// Call ForgetParsedMeal once it is logged, or ReturnParsedMeal when logging failed.
+	TakeParsedMeal(userID, parsedMealID uuid.UUID) (*domain.ParsedMeal, error)
+	ReturnParsedMeal(parsedMealID uuid.UUID)
+	ForgetParsedMeal(parsedMealID uuid.UUID)
 }
 
 // MealService handles meal tracking and nutrition calculation
 type MealService interface {
 	GetMeals(ctx context.Context, userID string, date *time.Time) ([]*domain.Meal, error)
+	// ListMealsPage pages through all the user's meals, newest logged first. Cursor is the
+	// next_cursor of the previous page, or empty for the first.
+	ListMealsPage(ctx context.Context, userID, cursor string, limit int) (*domain.MealPage, error)
 	GetMeal(ctx context.Context, mealID string) (*domain.Meal, error)
 	CreateMeal(ctx context.Context, userID string, mealData *domain.Meal) (*domain.Meal, error)
-	ConfirmParsedMeal(ctx context.Context, userID string, parsedMeal *domain.Meal) (*domain.Meal, error)
+	// ConfirmParsedMeal logs the food items of a parsed meal, as the user adjusted them
+	ConfirmParsedMeal(ctx context.Context, userID string, parsedMeal *domain.ParsedMeal) (*domain.Meal, error)
 	UpdateMeal(ctx context.Context, mealID string, updates map[string]interface{}) (*domain.Meal, error)
 	DeleteMeal(ctx context.Context, mealID string) error
 	CalculateMealNutrition(ctx context.Context, mealID string) (*domain.NutritionTotals, error)
@@ -42,28 +100,47 @@ type MealService interface {
 // ActivityService handles activity tracking
 type ActivityService interface {
 	GetActivities(ctx context.Context, userID string, startDate, endDate *time.Time) ([]*domain.Activity, error)
+	// ListActivitiesPage pages through all the user's activities, newest logged first
+	ListActivitiesPage(ctx context.Context, userID, cursor string, limit int) (*domain.ActivityPage, error)
 	GetActivity(ctx context.Context, activityID string) (*domain.Activity, error)
 	CreateActivity(ctx context.Context, userID string, activityData *domain.Activity) (*domain.Activity, error)
 	UpdateActivity(ctx context.Context, activityID string, updates map[string]interface{}) (*domain.Activity, error)
 	DeleteActivity(ctx context.Context, activityID string) error
 }
 
-// WorkoutService handles workout tracking
+// WorkoutService handles workout tracking. IDs arrive already parsed, so malformed ones
+// are rejected where they enter the application rather than here.
 type WorkoutService interface {
-	StartWorkout(ctx context.Context, userID, name string) (*domain.Workout, error)
-	GetWorkouts(ctx context.Context, userID string, startDate, endDate *time.Time) ([]*domain.Workout, error)
-	GetWorkout(ctx context.Context, workoutID string) (*domain.Workout, error)
-	AddExercise(ctx context.Context, workoutID, exerciseID string) (*domain.WorkoutExercise, error)
-	LogSet(ctx context.Context, workoutExerciseID string, setData *domain.WorkoutSet) (*domain.WorkoutSet, error)
-	FinishWorkout(ctx context.Context, workoutID string) error
-	DeleteWorkout(ctx context.Context, workoutID string) error
+	StartWorkout(ctx context.Context, userID uuid.UUID, name string) (*domain.Workout, error)
+	// CloneWorkout starts a new workout with a past workout's exercises, its sets planned but not logged
+	CloneWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error)
+	GetWorkouts(ctx context.Context, userID uuid.UUID, startDate, endDate *time.Time, filter domain.WorkoutFilter) ([]*domain.Workout, error)
+	GetWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error)
+	AddExercise(ctx context.Context, userID, workoutID, exerciseID uuid.UUID) (*domain.WorkoutExercise, error)
+	LogSet(ctx context.Context, workoutExerciseID uuid.UUID, setData *domain.WorkoutSet) (*domain.WorkoutSet, error)
+	// LogSets logs several sets of one workout exercise, numbering them after its last set
+	LogSets(ctx context.Context, userID, workoutID, workoutExerciseID uuid.UUID, sets []*domain.WorkoutSet) (*domain.SetBatchResult, error)
+	FinishWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error)
+	PauseWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error)
+	ResumeWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error)
+	ReorderExercises(ctx context.Context, userID, workoutID uuid.UUID, workoutExerciseIDs []uuid.UUID) ([]*domain.WorkoutExercise, error)
+	GetExerciseHistory(ctx context.Context, userID, exerciseID uuid.UUID, from, to *time.Time) ([]*domain.ExerciseSetRecord, error)
+	GetExercise(ctx context.Context, exerciseID uuid.UUID) (*domain.Exercise, error)
+	// FindExercise resolves a free-text exercise name to the library exercise it most likely means
+	FindExercise(ctx context.Context, name string) (*domain.Exercise, error)
+	CreateExercise(ctx context.Context, exercise *domain.Exercise) (*domain.Exercise, bool, error)
+	GetExercisePerformance(ctx context.Context, userID, exerciseID uuid.UUID) (*domain.ExercisePerformance, error)
+	DeleteWorkout(ctx context.Context, userID, workoutID uuid.UUID) error
 }
 
 // MetricService handles health metrics tracking
 type MetricService interface {
 	LogMetric(ctx context.Context, userID, metricType string, value float64, unit string, recordedAt time.Time) (*domain.Metric, error)
+	LogMetricBatch(ctx context.Context, userID string, entries []domain.MetricEntry) (*domain.MetricBatchResult, error)
 	GetMetricTrend(ctx context.Context, userID, metricType string, startDate, endDate *time.Time) ([]*domain.Metric, error)
 	GetLatestMetric(ctx context.Context, userID, metricType string) (*domain.Metric, error)
+	UpdateMetric(ctx context.Context, userID, metricID string, update *domain.MetricUpdate) (*domain.Metric, error)
+	DeleteMetric(ctx context.Context, userID, metricID string) error
 }
 
 // GoalService handles user goals
@@ -78,12 +155,31 @@ type GoalService interface {
 type SummaryService interface {
 	GetDailySummary(ctx context.Context, userID string, date time.Time) (*domain.DailySummary, error)
 	CalculateDailySummary(ctx context.Context, userID string, date time.Time) (*domain.DailySummary, error)
+	GetAdherence(ctx context.Context, userID string, from, to time.Time) (*domain.AdherenceSummary, error)
+	GetNutritionTargets(ctx context.Context, userID string) (*domain.NutritionTarget, error)
+	RefreshDailySummary(ctx context.Context, userID string, date time.Time) (*domain.DailySummary, error)
+	GetSummaryRange(ctx context.Context, userID string, from, to time.Time) (*domain.DailySummaryRange, error)
+}
+
+// InsightsService computes motivation features such as streaks and achievements
+type InsightsService interface {
+	GetStreaks(ctx context.Context, userID string) (*domain.StreakSummary, error)
+	// GetActivityCalendar flags the days of a year, such as "2025", with logging; an empty
+	// year means the current one
+	GetActivityCalendar(ctx context.Context, userID, year string) (*domain.ActivityCalendar, error)
+}
+
+// WeeklyRecapService summarizes a user's week for notifications
+type WeeklyRecapService interface {
+	// GetWeeklyRecap recaps an ISO week such as 2025-W47; an empty week means last week
+	GetWeeklyRecap(ctx context.Context, userID, week string) (*domain.WeeklyRecap, error)
 }
 
 // AgentResponse represents the response from the AI agent
 type AgentResponse struct {
 	Message    string    `json:"message"`
 	ToolsUsed  []string  `json:"tools_used"`
+	Model      string    `json:"model"` // the model that wrote the reply, which may be a fallback
 	Confidence float64   `json:"confidence"`
 	CreatedAt  time.Time `json:"created_at"`
 }
@@ -92,3 +188,65 @@ type AgentResponse struct {
 type AgentService interface {
 	SendMessage(ctx context.Context, userID uuid.UUID, message string) (*AgentResponse, error)
 }
+
+// EmailSender delivers transactional emails (password reset, email verification, etc.)
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+// AccountService handles account export and deletion
+type AccountService interface {
+	ExportAccount(ctx context.Context, userID string) (*domain.UserDataExport, error)
+	DeleteAccount(ctx context.Context, userID, password string) error
+}
+
+// PhotoStorage stores user-uploaded photos
+type PhotoStorage interface {
+	ListImages(ctx context.Context, userID string) ([]domain.StoredPhoto, error)
+	DeleteImage(ctx context.Context, objectPath string) error
+}
+
+// PhotoRetentionService removes stored photos past the retention window
+type PhotoRetentionService interface {
+	Cleanup(ctx context.Context) (*domain.PhotoCleanupReport, error)
+}
+
+// LLMAuditService records LLM calls to the audit log and queries them for operators
+type LLMAuditService interface {
+	Record(ctx context.Context, entry *domain.LLMAuditEntry)
+	ListEntries(ctx context.Context, filter domain.LLMAuditFilter, limit, offset int) ([]*domain.LLMAuditEntry, error)
+}
+
+// UndoService reverses a user's most recent create or delete
+type UndoService interface {
+	UndoLastAction(ctx context.Context, userID string) (*domain.UndoResult, error)
+}
+
+// ConversationService lists the user's chat conversations
+type ConversationService interface {
+	ListConversations(ctx context.Context, userID string, limit, offset int) ([]domain.ConversationPreview, error)
+	// ListMessages pages through one of the user's conversations, oldest message first
+	ListMessages(ctx context.Context, userID, conversationID, cursor string, limit int) (*domain.MessagePage, error)
+}
+
+// CoachService builds the proactive coach digest shown outside of chat
+type CoachService interface {
+	GetDigest(ctx context.Context, userID string) (*domain.CoachDigest, error)
+}
+
+// ContentModerator screens AI coach output before it is stored or shown to the user
+type ContentModerator interface {
+	Moderate(ctx context.Context, text string) (*domain.ModerationResult, error)
+}
+
+// EventPublisher publishes domain events for downstream consumers
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, payload interface{}) error
+}
+
+// DemoService fills an account with sample data so a new user's dashboards are not empty,
+// and removes it again
+type DemoService interface {
+	SeedDemoData(ctx context.Context, userID string) (*domain.DemoSeedResult, error)
+	ClearDemoData(ctx context.Context, userID string) (*domain.DemoSeedResult, error)
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"fitness-tracker/internal/config"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// DefaultKeyID is used when no key ID is configured
+const DefaultKeyID = "default"
+
+var (
+	// ErrUnknownKeyID is returned when a token references a key that is not active
+	ErrUnknownKeyID = errors.New("unknown signing key id")
+
+	// ErrUnexpectedSigningMethod is returned when a token's alg doesn't match its key
+	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+)
+
+// Key is a single signing/verification key identified by its kid
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   interface{} // nil for verification-only keys
+	VerifyKey interface{}
+}
+
+// KeySet holds the current signing key and every key still accepted for verification.
+// Tokens are always signed with the current key; any active key can verify.
+type KeySet struct {
+	current *Key
+	keys    map[string]*Key
+}
+
+// NewKeySet creates a key set signing with current and also accepting the previous keys
+func NewKeySet(current *Key, previous ...*Key) (*KeySet, error) {
+	if current == nil || current.SignKey == nil {
+		return nil, fmt.Errorf("current key must be able to sign")
+	}
+
+	ks := &KeySet{
+		current: current,
+		keys:    map[string]*Key{current.ID: current},
+	}
+
+	for _, key := range previous {
+		if key == nil {
+			continue
+		}
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id: %s", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	return ks, nil
+}
+
+// NewHMACKey creates an HS256 key from a shared secret
+func NewHMACKey(id, secret string) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   []byte(secret),
+		VerifyKey: []byte(secret),
+	}
+}
+
+// NewRSAKey creates an RS256 key. privateKey may be nil for verification-only keys.
+func NewRSAKey(id string, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) *Key {
+	if publicKey == nil && privateKey != nil {
+		publicKey = &privateKey.PublicKey
+	}
+
+	key := &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodRS256,
+		VerifyKey: publicKey,
+	}
+	if privateKey != nil {
+		key.SignKey = privateKey
+	}
+
+	return key
+}
+
+// NewKeySetFromConfig builds a key set from JWT configuration
+func NewKeySetFromConfig(cfg *config.JWTConfig) (*KeySet, error) {
+	keyID := cfg.KeyID
+	if keyID == "" {
+		keyID = DefaultKeyID
+	}
+
+	previous, err := parsePreviousKeys(cfg.PreviousKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", AlgorithmHS256:
+		keys := make([]*Key, 0, len(previous))
+		for id, secret := range previous {
+			keys = append(keys, NewHMACKey(id, secret))
+		}
+		return NewKeySet(NewHMACKey(keyID, cfg.Secret), keys...)
+
+	case AlgorithmRS256:
+		privatePEM, err := os.ReadFile(cfg.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+		}
+
+		keys := make([]*Key, 0, len(previous))
+		for id, path := range previous {
+			publicPEM, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT public key %s: %w", id, err)
+			}
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse JWT public key %s: %w", id, err)
+			}
+			keys = append(keys, NewRSAKey(id, nil, publicKey))
+		}
+		return NewKeySet(NewRSAKey(keyID, privateKey, nil), keys...)
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.Algorithm)
+	}
+}
+
+// parsePreviousKeys parses "kid=value" entries (optionally comma-separated) into a map
+func parsePreviousKeys(entries []string) (map[string]string, error) {
+	keys := make(map[string]string, len(entries))
+	for _, entry := range strings.Split(strings.Join(entries, ","), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid previous JWT key entry, expected kid=value")
+		}
+		keys[parts[0]] = parts[1]
+	}
+	return keys, nil
+}
+
+// CurrentKeyID returns the kid used for newly signed tokens
+func (ks *KeySet) CurrentKeyID() string {
+	return ks.current.ID
+}
+
+// Sign signs the claims with the current key and sets the kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.current.Method, claims)
+	token.Header["kid"] = ks.current.ID
+
+	tokenString, err := token.SignedString(ks.current.SignKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// Parse verifies a token against the active keys.
+// Tokens without a kid (issued before rotation support) are checked against the current key.
+func (ks *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, ks.keyFunc)
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := ks.current
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		found, exists := ks.keys[kid]
+		if !exists {
+			return nil, ErrUnknownKeyID
+		}
+		key = found
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnexpectedSigningMethod
+	}
+
+	return key.VerifyKey, nil
+}
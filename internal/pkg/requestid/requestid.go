@@ -0,0 +1,32 @@
+// Package requestid carries the ID of the HTTP request being served through a
+// context.Context, so logs and audit records made deep in the service layer can
+// be correlated with the request that caused them.
+package requestid
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext returns the request ID carried by ctx, or "" when there is none
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// Logf logs like log.Printf, adding the request ID carried by ctx when there is one
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if requestID := FromContext(ctx); requestID != "" {
+		message += " request_id=" + requestID
+	}
+	log.Print(message)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	apperrors "fitness-tracker/internal/pkg/errors"
+)
+
+// MaxDateRangeDays caps how many days a date range query may span, counting both ends
+const MaxDateRangeDays = 366
+
+// ParseDateRange parses optional start and end dates in YYYY-MM-DD format.
+//
+// When only one bound is given the other is filled in: a missing end defaults to
+// today (or the start, if that is later) and a missing start to defaultDays before
+// the end, counting both ends. When neither is given both are nil so callers keep
+// their own defaults.
+//
+// A malformed date returns a ValidationError whose "field" detail is "start" or
+// "end". An end before the start, or a range longer than MaxDateRangeDays,
+// returns a BadRequestError.
+func ParseDateRange(startStr, endStr string, defaultDays int) (*time.Time, *time.Time, error) {
+	if startStr == "" && endStr == "" {
+		return nil, nil, nil
+	}
+
+	var start, end time.Time
+	if startStr != "" {
+		parsed, err := ParseDate(startStr)
+		if err != nil {
+			return nil, nil, apperrors.ValidationError("start date must use YYYY-MM-DD format", "start")
+		}
+		start = parsed
+	}
+	if endStr != "" {
+		parsed, err := ParseDate(endStr)
+		if err != nil {
+			return nil, nil, apperrors.ValidationError("end date must use YYYY-MM-DD format", "end")
+		}
+		end = parsed
+	}
+
+	switch {
+	case endStr == "":
+		end = StartOfDay(NowUTC())
+		if start.After(end) {
+			end = start
+		}
+	case startStr == "":
+		if defaultDays < 1 {
+			defaultDays = 1
+		}
+		start = end.AddDate(0, 0, -(defaultDays - 1))
+	}
+
+	if end.Before(start) {
+		return nil, nil, apperrors.BadRequestError("end date must not be before start date").
+			WithDetails("start", FormatDate(start)).
+			WithDetails("end", FormatDate(end))
+	}
+	if days := DaysBetween(start, end) + 1; days > MaxDateRangeDays {
+		return nil, nil, apperrors.BadRequestError(fmt.Sprintf("date range must not exceed %d days", MaxDateRangeDays)).
+			WithDetails("days", days).
+			WithDetails("max_days", MaxDateRangeDays)
+	}
+
+	return &start, &end, nil
+}
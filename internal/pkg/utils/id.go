@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	apperrors "fitness-tracker/internal/pkg/errors"
+)
+
+// ParseID parses a UUID given by a client in field, such as a path parameter or a
+// request body property. A missing or malformed ID returns a BadRequestError whose
+// "field" detail names the field, so callers answer 400 instead of failing deeper in.
+func ParseID(value, field string) (uuid.UUID, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return uuid.Nil, apperrors.BadRequestError(field+" is required").WithDetails("field", field)
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, apperrors.BadRequestError(fmt.Sprintf("%s must be a valid UUID", field)).
+			WithDetails("field", field).
+			WithDetails("value", value)
+	}
+	return id, nil
+}
+
+// ParseIDs parses a list of IDs with ParseID, failing on the first malformed one
+func ParseIDs(values []string, field string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(values))
+	for i, value := range values {
+		id, err := ParseID(value, field)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NormalizeName lowercases a name, drops punctuation, singularizes plain plurals
+// and sorts the words, so "Bananas" and "banana" or "Chicken, grilled" and
+// "grilled chicken" normalize to the same string.
+func NormalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			words[i] = strings.TrimSuffix(word, "s")
+		}
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// NameSimilarity scores how alike two names are from 0 (nothing in common) to 1
+// (the same after normalization). The score is one minus the edit distance
+// between the normalized names relative to the longer of them, so small typos
+// still score high.
+func NameSimilarity(a, b string) float64 {
+	left := []rune(NormalizeName(a))
+	right := []rune(NormalizeName(b))
+
+	longest := max(len(left), len(right))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(editDistance(left, right))/float64(longest)
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strconv"
+
+	apperrors "fitness-tracker/internal/pkg/errors"
+)
+
+// ParseLimit parses an optional page size. An empty string returns defaultLimit.
+// Limits above maxLimit are capped to it; a maxLimit of 0 disables the cap.
+// A non-integer or non-positive limit returns a ValidationError for "limit".
+func ParseLimit(limitStr string, defaultLimit, maxLimit int) (int, error) {
+	limit := defaultLimit
+	if limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			return 0, apperrors.ValidationError("limit must be a positive integer", "limit")
+		}
+		limit = parsed
+	}
+
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit, nil
+}
+
+// ParseOffset parses an optional page offset, defaulting to 0.
+// A non-integer or negative offset returns a ValidationError for "offset".
+func ParseOffset(offsetStr string) (int, error) {
+	if offsetStr == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		return 0, apperrors.ValidationError("offset must be a non-negative integer", "offset")
+	}
+	return offset, nil
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// nutritionKeys are the JSON fields holding calories or nutrient amounts. Totals
+// of them ("total_" prefix) are nutrition fields too.
+var nutritionKeys = map[string]bool{
+	"calories":        true,
+	"calories_burned": true,
+	"net_calories":    true,
+	"tdee":            true,
+	"energy_balance":  true,
+	"protein":         true,
+	"carbohydrates":   true,
+	"fat":             true,
+	"fiber":           true,
+	"sugar":           true,
+	"saturated_fat":   true,
+	"trans_fat":       true,
+	"cholesterol":     true,
+	"sodium":          true,
+	"potassium":       true,
+	"protein_density": true,
+}
+
+// IsNutritionKey reports whether a JSON field holds calories or a nutrient amount
+func IsNutritionKey(key string) bool {
+	return nutritionKeys[strings.TrimPrefix(key, "total_")]
+}
+
+// RoundHalfAwayFromZero rounds value to the given number of decimal places, with
+// halves rounded away from zero: 0.5 becomes 1 and -0.5 becomes -1. The value is
+// rounded as written in decimal, so 2.675 becomes 2.68 even though its nearest
+// float64 is slightly below 2.675. Negative places return the value unchanged.
+func RoundHalfAwayFromZero(value float64, places int) float64 {
+	if places < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+
+	// The shortest representation that parses back to value is the value as written
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return value
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	exact.Mul(exact, new(big.Rat).SetInt(scale))
+
+	half := new(big.Rat).Abs(exact)
+	half.Add(half, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(half.Num(), half.Denom())
+	if exact.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+
+	result, _ := new(big.Rat).SetFrac(rounded, scale).Float64()
+	return result
+}
+
+// RoundNutritionJSON rounds every numeric nutrition field in a JSON document to
+// the given number of decimal places; other values are left as they are. Object
+// keys come back in sorted order.
+func RoundNutritionJSON(data []byte, places int) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(roundNutritionValue(document, "", places))
+}
+
+func roundNutritionValue(value interface{}, key string, places int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = roundNutritionValue(child, k, places)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = roundNutritionValue(child, key, places)
+		}
+	case json.Number:
+		if !IsNutritionKey(key) {
+			return v
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return v
+		}
+		return RoundHalfAwayFromZero(f, places)
+	}
+	return value
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+type accountService struct {
+	userRepo     ports.UserRepository
+	accountRepo  ports.AccountRepository
+	photoStorage ports.PhotoStorage
+	events       ports.EventPublisher
+}
+
+// NewAccountService creates a new account service.
+// photoStorage may be nil when photo uploads aren't configured.
+func NewAccountService(
+	userRepo ports.UserRepository,
+	accountRepo ports.AccountRepository,
+	photoStorage ports.PhotoStorage,
+	events ports.EventPublisher,
+) ports.AccountService {
+	return &accountService{
+		userRepo:     userRepo,
+		accountRepo:  accountRepo,
+		photoStorage: photoStorage,
+		events:       events,
+	}
+}
+
+func (s *accountService) ExportAccount(ctx context.Context, userID string) (*domain.UserDataExport, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	export, err := s.accountRepo.ExportUserData(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export account: %w", err)
+	}
+
+	return export, nil
+}
+
+// DeleteAccount permanently removes the user and all data they own.
+// The password must be re-entered to confirm. Shared catalog data (foods, exercises) is kept.
+func (s *accountService) DeleteAccount(ctx context.Context, userID, password string) error {
+	if password == "" {
+		return domain.ErrInvalidInput
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+
+	if err := s.accountRepo.PurgeUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	// Stored photos live outside the database transaction; failures are logged
+	// and left to downstream cleanup via the account.deleted event.
+	s.deletePhotos(ctx, userID)
+
+	event := domain.AccountDeletedEvent{
+		UserID:    id,
+		DeletedAt: time.Now(),
+	}
+	if err := s.events.Publish(ctx, domain.EventAccountDeleted, event); err != nil {
+		requestid.Logf(ctx, "[Account] Failed to publish %s for user %s: %v", domain.EventAccountDeleted, userID, err)
+	}
+
+	return nil
+}
+
+// deletePhotos removes all of the user's stored photos, best effort
+func (s *accountService) deletePhotos(ctx context.Context, userID string) {
+	if s.photoStorage == nil {
+		return
+	}
+
+	photos, err := s.photoStorage.ListImages(ctx, userID)
+	if err != nil {
+		requestid.Logf(ctx, "[Account] Failed to list photos for user %s: %v", userID, err)
+		return
+	}
+
+	var failed []error
+	for _, photo := range photos {
+		if err := s.photoStorage.DeleteImage(ctx, photo.Path); err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		requestid.Logf(ctx, "[Account] Failed to delete %d photos for user %s: %v", len(failed), userID, errors.Join(failed...))
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,16 +12,50 @@ import (
 )
 
 type activityService struct {
-	activityRepo ports.ActivityRepository
+	activityRepo   ports.ActivityRepository
+	actionRepo     ports.UserActionRepository
+	summaryService ports.SummaryService
 }
 
 // NewActivityService creates a new activity service
-func NewActivityService(activityRepo ports.ActivityRepository) ports.ActivityService {
+func NewActivityService(activityRepo ports.ActivityRepository, actionRepo ports.UserActionRepository, summaryService ports.SummaryService) ports.ActivityService {
 	return &activityService{
-		activityRepo: activityRepo,
+		activityRepo:   activityRepo,
+		actionRepo:     actionRepo,
+		summaryService: summaryService,
 	}
 }
 
+// ListActivitiesPage pages by creation time rather than start time, so an activity logged
+// late for an earlier day still shows up at the head of the list instead of mid-way through
+func (s *activityService) ListActivitiesPage(ctx context.Context, userID, cursor string, limit int) (*domain.ActivityPage, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	after, err := domain.DecodePageCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", domain.ErrInvalidInput)
+	}
+
+	// One extra row says whether another page follows
+	activities, err := s.activityRepo.ListByUserAfter(ctx, userUUID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+
+	page := &domain.ActivityPage{Activities: activities}
+	if len(activities) > limit {
+		page.Activities = activities[:limit]
+		last := page.Activities[limit-1]
+		page.NextCursor = domain.NewPageCursor(last.CreatedAt, last.ID).Encode()
+	}
+	return page, nil
+}
+
 func (s *activityService) GetActivities(ctx context.Context, userID string, startDate, endDate *time.Time) ([]*domain.Activity, error) {
 	if userID == "" {
 		return nil, domain.ErrInvalidInput
@@ -90,6 +125,14 @@ func (s *activityService) CreateActivity(ctx context.Context, userID string, act
 	if activityData.StartTime.IsZero() {
 		activityData.StartTime = time.Now()
 	}
+	if err := domain.CheckLoggedAt("start_time", activityData.StartTime, time.Now()); err != nil {
+		return nil, err
+	}
+	if activityData.EndTime != nil {
+		if err := domain.CheckLoggedAt("end_time", *activityData.EndTime, time.Now()); err != nil {
+			return nil, err
+		}
+	}
 
 	// Validate activity type
 	validTypes := map[string]bool{
@@ -115,11 +158,27 @@ func (s *activityService) CreateActivity(ctx context.Context, userID string, act
 	if activityData.CaloriesBurned != nil && *activityData.CaloriesBurned < 0 {
 		return nil, domain.ErrInvalidInput
 	}
+	if activityData.CalorieSource != nil && !slices.Contains(domain.CalorieSources, *activityData.CalorieSource) {
+		return nil, fmt.Errorf("%w: calorie_source must be device, manual or estimate", domain.ErrInvalidInput)
+	}
+
+	// End time and duration must agree, whoever set them
+	if err := activityData.ReconcileDuration(); err != nil {
+		return nil, err
+	}
+	if err := activityData.ApplyRoute(); err != nil {
+		return nil, err
+	}
+	if err := activityData.CheckPace(); err != nil {
+		return nil, err
+	}
 
 	// Create activity
 	if err := s.activityRepo.Create(ctx, activityData); err != nil {
 		return nil, fmt.Errorf("failed to create activity: %w", err)
 	}
+	recordAction(ctx, s.actionRepo, activityData.UserID, domain.ActionCreate, domain.ActionEntityActivity, activityData.ID)
+	refreshDailySummaries(ctx, s.summaryService, activityData.UserID, activityData.StartTime)
 
 	return activityData, nil
 }
@@ -139,6 +198,7 @@ func (s *activityService) UpdateActivity(ctx context.Context, activityID string,
 	if err != nil {
 		return nil, fmt.Errorf("failed to get activity: %w", err)
 	}
+	previousStart := existing.StartTime
 
 	// Apply updates
 	if activityType, ok := updates["activity_type"].(string); ok {
@@ -158,12 +218,42 @@ func (s *activityService) UpdateActivity(ctx context.Context, activityID string,
 		existing.ActivityType = activityType
 	}
 
-	if duration, ok := updates["duration_minutes"].(float64); ok {
+	startTime, startChanged, err := timeUpdate(updates, "start_time")
+	if err != nil {
+		return nil, err
+	}
+	if startChanged {
+		if err := domain.CheckLoggedAt("start_time", startTime, time.Now()); err != nil {
+			return nil, err
+		}
+		existing.StartTime = startTime
+	}
+
+	endTime, endChanged, err := timeUpdate(updates, "end_time")
+	if err != nil {
+		return nil, err
+	}
+	if endChanged {
+		if err := domain.CheckLoggedAt("end_time", endTime, time.Now()); err != nil {
+			return nil, err
+		}
+		existing.EndTime = &endTime
+	}
+
+	duration, durationChanged := updates["duration_minutes"].(float64)
+	if durationChanged {
 		if duration < 0 {
 			return nil, domain.ErrInvalidInput
 		}
 		durationInt := int(duration)
 		existing.DurationMinutes = &durationInt
+	} else if startChanged || endChanged {
+		// The old duration no longer fits the new interval; derive it again
+		existing.DurationMinutes = nil
+	}
+
+	if err := existing.ReconcileDuration(); err != nil {
+		return nil, err
 	}
 
 	if calories, ok := updates["calories_burned"].(float64); ok {
@@ -173,9 +263,19 @@ func (s *activityService) UpdateActivity(ctx context.Context, activityID string,
 		existing.CaloriesBurned = &calories
 	}
 
+	if source, ok := updates["calorie_source"].(string); ok {
+		if !slices.Contains(domain.CalorieSources, source) {
+			return nil, fmt.Errorf("%w: calorie_source must be device, manual or estimate", domain.ErrInvalidInput)
+		}
+		existing.CalorieSource = &source
+	}
+
 	if distance, ok := updates["distance"].(float64); ok {
 		existing.Distance = &distance
 	}
+	if err := existing.CheckPace(); err != nil {
+		return nil, err
+	}
 
 	if notes, ok := updates["notes"].(string); ok {
 		existing.Notes = &notes
@@ -185,6 +285,7 @@ func (s *activityService) UpdateActivity(ctx context.Context, activityID string,
 	if err := s.activityRepo.Update(ctx, existing); err != nil {
 		return nil, fmt.Errorf("failed to update activity: %w", err)
 	}
+	refreshDailySummaries(ctx, s.summaryService, existing.UserID, previousStart, existing.StartTime)
 
 	return existing, nil
 }
@@ -200,7 +301,7 @@ func (s *activityService) DeleteActivity(ctx context.Context, activityID string)
 	}
 
 	// Verify activity exists
-	_, err = s.activityRepo.GetByID(ctx, id)
+	activity, err := s.activityRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get activity: %w", err)
 	}
@@ -209,6 +310,23 @@ func (s *activityService) DeleteActivity(ctx context.Context, activityID string)
 	if err := s.activityRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete activity: %w", err)
 	}
+	recordAction(ctx, s.actionRepo, activity.UserID, domain.ActionDelete, domain.ActionEntityActivity, activity.ID)
+	refreshDailySummaries(ctx, s.summaryService, activity.UserID, activity.StartTime)
 
 	return nil
 }
+
+// timeUpdate reads an RFC 3339 time from updates, reporting whether key was present
+func timeUpdate(updates map[string]interface{}, key string) (time.Time, bool, error) {
+	switch value := updates[key].(type) {
+	case time.Time:
+		return value, true, nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s must be an RFC 3339 time", domain.ErrInvalidInput, key)
+		}
+		return parsed, true, nil
+	}
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// summaryKeepRecent is how many of the newest messages stay verbatim when older ones are summarized
+const summaryKeepRecent = 6
+
+// maxConversationSummaryLength caps a stored summary, in characters; a longer reply is cut
+// at its last line break before the cap
+const maxConversationSummaryLength = 4000
+
+// WithHistorySummarization summarizes older messages once the history sent with a turn
+// holds more than maxMessages messages or maxTokens estimated tokens; 0 disables either
+// trigger. The summary replaces those messages in later prompts, so long conversations
+// stop growing the prompt. The stored messages are never changed.
+func (s *AgentService) WithHistorySummarization(maxMessages, maxTokens int) *AgentService {
+	s.summarizeAfterMessages = maxMessages
+	s.summarizeAfterTokens = maxTokens
+	return s
+}
+
+// historyTooLong reports whether messages have crossed a summarization threshold
+func (s *AgentService) historyTooLong(messages []*domain.Message) bool {
+	if s.summarizeAfterMessages > 0 && len(messages) > s.summarizeAfterMessages {
+		return true
+	}
+	if s.summarizeAfterTokens > 0 {
+		tokens := 0
+		for _, msg := range messages {
+			tokens += domain.EstimateTokens(msg.Content)
+		}
+		return tokens > s.summarizeAfterTokens
+	}
+	return false
+}
+
+// compactHistory returns the summary and messages to put in the prompt. Messages the
+// conversation's summary already covers are dropped. When the rest is too long, all but
+// the newest few are folded into a new summary, which is saved on the conversation. A
+// failed summary is logged and the history is sent as it is.
+func (s *AgentService) compactHistory(ctx context.Context, conversation *domain.Conversation, messages []*domain.Message, userID uuid.UUID) (string, []*domain.Message) {
+	summary := ""
+	if conversation.Summary != nil {
+		summary = *conversation.Summary
+	}
+
+	recent := make([]*domain.Message, 0, len(messages))
+	for _, msg := range messages {
+		// The system prompt is rebuilt every turn and must stay first
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		if conversation.SummarizedThrough != nil && !msg.CreatedAt.After(*conversation.SummarizedThrough) {
+			continue
+		}
+		recent = append(recent, msg)
+	}
+
+	if !s.historyTooLong(recent) || len(recent) <= 1 {
+		return summary, recent
+	}
+
+	keep := min(summaryKeepRecent, len(recent)-1)
+	older, recent := recent[:len(recent)-keep], recent[len(recent)-keep:]
+
+	updated, err := s.summarizeMessages(ctx, summary, older, userID)
+	if err != nil {
+		requestid.Logf(ctx, "[AgentService] Warning: failed to summarize conversation %s: %v", conversation.ID, err)
+		return summary, append(older, recent...)
+	}
+
+	through := older[len(older)-1].CreatedAt
+	if err := s.conversationRepo.SaveSummary(ctx, conversation.ID, updated, through); err != nil {
+		// The summary still serves this turn; the next one summarizes again
+		requestid.Logf(ctx, "[AgentService] Warning: failed to save conversation summary: %v", err)
+	}
+	conversation.Summary = &updated
+	conversation.SummarizedThrough = &through
+
+	requestid.Logf(ctx, "[AgentService] Summarized %d messages of conversation %s", len(older), conversation.ID)
+	return updated, recent
+}
+
+// summarizeMessages asks the model to fold messages into the previous summary
+func (s *AgentService) summarizeMessages(ctx context.Context, previous string, messages []*domain.Message, userID uuid.UUID) (string, error) {
+	systemPrompt := `You maintain the memory of a conversation between a user and their AI fitness and nutrition coach.
+Rewrite the previous summary to also cover the new messages, as a short list of plain-text facts.
+Keep everything that matters for coaching later: goals and targets with their numbers, dietary preferences, allergies and restrictions, injuries and health conditions, schedule and equipment, what the user has tried and how it went, and any advice or plans agreed on.
+Drop greetings, small talk and anything later corrected. Do not invent details. Reply with the summary only.`
+
+	var transcript strings.Builder
+	if previous != "" {
+		transcript.WriteString("Previous summary:\n")
+		transcript.WriteString(previous)
+		transcript.WriteString("\n\n")
+	}
+	transcript.WriteString("New messages:\n")
+	for _, msg := range messages {
+		fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, msg.Content)
+	}
+
+	chatMessages := []external.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: transcript.String()},
+	}
+
+	ctx = external.WithAuditContext(ctx, external.AuditOperationChatSummary, userID)
+	resp, err := s.openRouterClient.Chat(ctx, chatMessages, s.defaultModel)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate summary: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from AI")
+	}
+
+	summary := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if summary == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	if runes := []rune(summary); len(runes) > maxConversationSummaryLength {
+		summary = string(runes[:maxConversationSummaryLength])
+		if cut := strings.LastIndex(summary, "\n"); cut > 0 {
+			summary = summary[:cut]
+		}
+	}
+	return summary, nil
+}
+
+// summaryPromptMessage carries the conversation summary into the prompt, right after the
+// system prompt
+func summaryPromptMessage(summary string) external.Message {
+	return external.Message{
+		Role:    "system",
+		Content: "Summary of the earlier conversation, whose messages are no longer shown:\n" + summary,
+	}
+}
@@ -4,24 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 
 	"fitness-tracker/internal/adapters/external"
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
 )
 
+// conversationHistoryLimit is how many previous messages are sent with each turn
+const conversationHistoryLimit = 20
+
 // AgentService handles AI agent interactions with tool support
 type AgentService struct {
-	// Service dependencies
-	mealService     ports.MealService
-	foodService     ports.FoodService
+	// Service dependencies used to build the user context; tools hold their own
 	activityService ports.ActivityService
-	workoutService  ports.WorkoutService
-	metricService   ports.MetricService
 	goalService     ports.GoalService
 	summaryService  ports.SummaryService
 
@@ -32,16 +33,53 @@ type AgentService struct {
 	// External client
 	openRouterClient *external.OpenRouterClient
 
+	// Optional; screens replies before they are stored or returned
+	moderator ports.ContentModerator
+
+	// Optional; holds meals log_meal was unsure of until the user confirms them
+	pendingMeals ports.ParsedMealStore
+
 	// Configuration
 	defaultModel string
+	temperature  *float64 // nil uses the client's default
+
+	// History summarization thresholds; 0 disables a trigger
+	summarizeAfterMessages int
+	summarizeAfterTokens   int
+
+	// Tools the model can call
+	tools   *ToolRegistry
+	logMeal *logMealTool
+}
+
+// toolCallKey identifies a tool call within a conversation
+type toolCallKey struct {
+	conversationID uuid.UUID
+	callID         string
+}
+
+// unknownToolResult is returned to the model when it calls a tool that is not registered
+type unknownToolResult struct {
+	Error          string   `json:"error"`
+	Tool           string   `json:"tool"`
+	Message        string   `json:"message"`
+	AvailableTools []string `json:"available_tools"`
+}
+
+func (r unknownToolResult) Render() string {
+	return r.Message
 }
 
 // AgentResponse represents the response from the AI agent
 type AgentResponse struct {
 	Message    string    `json:"message"`
 	ToolsUsed  []string  `json:"tools_used"`
+	Model      string    `json:"model"` // the model that wrote the reply, which may be a fallback
 	Confidence float64   `json:"confidence"`
 	CreatedAt  time.Time `json:"created_at"`
+
+	// A meal the agent did not log because it needs the user's confirmation; nil when none
+	PendingMeal *domain.ParsedMeal `json:"pending_meal,omitempty"`
 }
 
 // NewAgentService creates a new agent service
@@ -53,28 +91,79 @@ func NewAgentService(
 	metricService ports.MetricService,
 	goalService ports.GoalService,
 	summaryService ports.SummaryService,
+	mealSuggestionService ports.MealSuggestionService,
+	mealComparisonService ports.MealComparisonService,
 	conversationRepo ports.ConversationRepository,
 	userRepo ports.UserRepository,
 	openRouterClient *external.OpenRouterClient,
 ) *AgentService {
-	return &AgentService{
-		mealService:      mealService,
-		foodService:      foodService,
+	s := &AgentService{
 		activityService:  activityService,
-		workoutService:   workoutService,
-		metricService:    metricService,
 		goalService:      goalService,
 		summaryService:   summaryService,
 		conversationRepo: conversationRepo,
 		userRepo:         userRepo,
 		openRouterClient: openRouterClient,
 		defaultModel:     "deepseek/deepseek-chat",
-	}
+		logMeal:          &logMealTool{mealService: mealService, foodService: foodService, minConfidence: DefaultAutoLogConfidence},
+	}
+	s.tools = NewToolRegistry(
+		s.logMeal,
+		&recentMealsTool{mealService: mealService},
+		&compareMealTool{mealComparisonService: mealComparisonService},
+		&searchFoodsTool{foodService: foodService},
+		&suggestFoodSwapsTool{foodService: foodService},
+		&dailyMacrosTool{summaryService: summaryService},
+		&remainingMacrosTool{userRepo: userRepo, summaryService: summaryService},
+		&suggestMealTool{userRepo: userRepo, summaryService: summaryService, mealSuggestionService: mealSuggestionService},
+		&adherenceTool{summaryService: summaryService},
+		&recentWorkoutsTool{workoutService: workoutService},
+		&recentActivitiesTool{activityService: activityService},
+		&logWeightTool{metricService: metricService},
+		&weightTrendTool{metricService: metricService},
+		&goalETATool{goalService: goalService, metricService: metricService},
+		&createGoalTool{goalService: goalService},
+		&updateGoalProgressTool{goalService: goalService},
+	)
+	return s
+}
+
+// WithModerator screens every reply through the moderator; a flagged reply is logged and
+// replaced with domain.ModeratedReplyMessage. A nil moderator leaves replies unscreened.
+func (s *AgentService) WithModerator(moderator ports.ContentModerator) *AgentService {
+	s.moderator = moderator
+	return s
+}
+
+// WithTemperature sets the sampling temperature of chat replies and the tool calls made
+// while writing them
+func (s *AgentService) WithTemperature(temperature float64) *AgentService {
+	s.temperature = &temperature
+	return s
+}
+
+// WithFoodEstimator lets the model estimate the nutrition of foods that are not in the
+// database, without saving them
+func (s *AgentService) WithFoodEstimator(estimator ports.FoodEstimator) *AgentService {
+	s.tools.Register(&estimateFoodTool{estimator: estimator})
+	return s
+}
+
+// WithMealLogConfirmation holds the meals log_meal logs with less than minConfidence, or
+// without a quantity, in store until the user confirms them through confirm_meal in a
+// later turn. Without it such meals are not logged and the model is asked to check with
+// the user before logging them again.
+func (s *AgentService) WithMealLogConfirmation(store ports.ParsedMealStore, minConfidence float64) *AgentService {
+	s.pendingMeals = store
+	s.logMeal.pending = store
+	s.logMeal.minConfidence = minConfidence
+	s.tools.Register(&confirmMealTool{mealService: s.logMeal.mealService, pending: store})
+	return s
 }
 
 // SendMessage processes a user message and returns an AI response
 func (s *AgentService) SendMessage(ctx context.Context, userID uuid.UUID, message string) (*AgentResponse, error) {
-	log.Printf("[AgentService] Processing message for user %s", userID)
+	requestid.Logf(ctx, "[AgentService] Processing message for user %s", userID)
 
 	// Get or create conversation
 	conversation, err := s.getOrCreateConversation(ctx, userID)
@@ -82,8 +171,8 @@ func (s *AgentService) SendMessage(ctx context.Context, userID uuid.UUID, messag
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
-	// Load last 20 messages for context
-	messages, err := s.conversationRepo.GetLatestMessages(ctx, conversation.ID, 20)
+	// Load recent history for context, oldest first
+	messages, err := s.conversationRepo.GetLatestMessages(ctx, conversation.ID, conversationHistoryLimit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load messages: %w", err)
 	}
@@ -91,7 +180,7 @@ func (s *AgentService) SendMessage(ctx context.Context, userID uuid.UUID, messag
 	// Build user context
 	userContext, err := s.buildUserContext(ctx, userID)
 	if err != nil {
-		log.Printf("[AgentService] Warning: failed to build user context: %v", err)
+		requestid.Logf(ctx, "[AgentService] Warning: failed to build user context: %v", err)
 		userContext = "User context unavailable"
 	}
 
@@ -103,13 +192,24 @@ func (s *AgentService) SendMessage(ctx context.Context, userID uuid.UUID, messag
 		{Role: "system", Content: systemPrompt},
 	}
 
-	for _, msg := range messages {
+	// Older messages give way to a summary once the history gets long
+	summary, history := s.compactHistory(ctx, conversation, messages, userID)
+	if summary != "" {
+		chatMessages = append(chatMessages, summaryPromptMessage(summary))
+	}
+
+	for _, msg := range history {
 		chatMessages = append(chatMessages, external.Message{
 			Role:    msg.Role,
 			Content: msg.Content,
 		})
 	}
 
+	// A meal held for confirmation last turn is settled by this message
+	if pending := s.lastPendingMeal(userID, messages); pending != nil {
+		chatMessages = append(chatMessages, pendingMealPromptMessage(pending))
+	}
+
 	// Add current user message
 	chatMessages = append(chatMessages, external.Message{
 		Role:    "user",
@@ -120,21 +220,30 @@ func (s *AgentService) SendMessage(ctx context.Context, userID uuid.UUID, messag
 	toolDefs := s.buildToolDefinitions()
 
 	// Execute LLM call with tools
-	response, toolsUsed, err := s.executeWithTools(ctx, chatMessages, toolDefs, userID)
+	response, metadata, err := s.executeWithTools(ctx, conversation.ID, chatMessages, toolDefs, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute LLM: %w", err)
 	}
 
-	// Save user message
+	// Users may be minors, so the reply is screened before it is stored or returned
+	if err := moderateOutput(ctx, s.moderator, response); err != nil {
+		requestid.Logf(ctx, "[AgentService] Redacted reply for user %s: %v", userID, err)
+		response = domain.ModeratedReplyMessage
+		metadata.Moderated = true
+	}
+
+	// Save user message. Timestamps are stored with microsecond precision, so the
+	// reply is pinned after the question to keep the turn order on reload.
+	userCreatedAt := time.Now().Truncate(time.Microsecond)
 	userMsg := &domain.Message{
 		ID:             uuid.New(),
 		ConversationID: conversation.ID,
 		Role:           "user",
 		Content:        message,
-		CreatedAt:      time.Now(),
+		CreatedAt:      userCreatedAt,
 	}
 	if err := s.conversationRepo.AddMessage(ctx, userMsg); err != nil {
-		log.Printf("[AgentService] Warning: failed to save user message: %v", err)
+		requestid.Logf(ctx, "[AgentService] Warning: failed to save user message: %v", err)
 	}
 
 	// Save assistant response
@@ -143,26 +252,77 @@ func (s *AgentService) SendMessage(ctx context.Context, userID uuid.UUID, messag
 		ConversationID: conversation.ID,
 		Role:           "assistant",
 		Content:        response,
-		CreatedAt:      time.Now(),
+		CreatedAt:      nextMessageTime(userCreatedAt),
 	}
-	if len(toolsUsed) > 0 {
-		metadata := map[string]interface{}{
-			"tools_used": toolsUsed,
-		}
-		metadataJSON, _ := json.Marshal(metadata)
-		metadataStr := string(metadataJSON)
-		assistantMsg.Metadata = &metadataStr
+	if err := assistantMsg.SetMetadata(metadata); err != nil {
+		requestid.Logf(ctx, "[AgentService] Warning: failed to record message metadata: %v", err)
 	}
 	if err := s.conversationRepo.AddMessage(ctx, assistantMsg); err != nil {
-		log.Printf("[AgentService] Warning: failed to save assistant message: %v", err)
+		requestid.Logf(ctx, "[AgentService] Warning: failed to save assistant message: %v", err)
 	}
 
-	return &AgentResponse{
+	agentResponse := &AgentResponse{
 		Message:    response,
-		ToolsUsed:  toolsUsed,
+		ToolsUsed:  metadata.ToolsUsed,
+		Model:      metadata.Model,
 		Confidence: 0.85,
 		CreatedAt:  time.Now(),
-	}, nil
+	}
+	if metadata.PendingMealID != nil && s.pendingMeals != nil {
+		// Confirmed in the same turn when it is no longer held
+		if pending, err := s.pendingMeals.GetParsedMeal(userID, *metadata.PendingMealID); err == nil {
+			agentResponse.PendingMeal = pending
+		}
+	}
+	return agentResponse, nil
+}
+
+// lastPendingMeal returns the meal the latest assistant reply held for confirmation, if
+// it is still waiting for the user
+func (s *AgentService) lastPendingMeal(userID uuid.UUID, messages []*domain.Message) *domain.ParsedMeal {
+	if s.pendingMeals == nil {
+		return nil
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		metadata, err := messages[i].GetMetadata()
+		if err != nil || metadata.PendingMealID == nil {
+			return nil
+		}
+		pending, err := s.pendingMeals.GetParsedMeal(userID, *metadata.PendingMealID)
+		if err != nil {
+			return nil
+		}
+		return pending
+	}
+	return nil
+}
+
+// pendingMealPromptMessage reminds the model of the meal awaiting the user's confirmation,
+// right before the user's reply to it
+func pendingMealPromptMessage(meal *domain.ParsedMeal) external.Message {
+	items := make([]string, len(meal.FoodItems))
+	for i, item := range meal.FoodItems {
+		items[i] = fmt.Sprintf("%g %s %s", item.Quantity, item.Unit, item.FoodName)
+	}
+	return external.Message{
+		Role: "system",
+		Content: fmt.Sprintf("Your last reply asked the user to confirm this %s before logging it: %s (%.0f kcal). "+
+			"If the user confirms, call confirm_meal with parsed_meal_id %s. If they correct it, call log_meal with the corrections; "+
+			"if they decline, do not log it.",
+			meal.MealType, strings.Join(items, ", "), meal.Totals.Calories, meal.ID),
+	}
+}
+
+// nextMessageTime returns the current time, moved past prev when both fall on the same microsecond
+func nextMessageTime(prev time.Time) time.Time {
+	now := time.Now().Truncate(time.Microsecond)
+	if !now.After(prev) {
+		return prev.Add(time.Microsecond)
+	}
+	return now
 }
 
 // getOrCreateConversation gets the most recent conversation or creates a new one
@@ -193,34 +353,71 @@ func (s *AgentService) getOrCreateConversation(ctx context.Context, userID uuid.
 	return conversation, nil
 }
 
-// buildUserContext builds context information about the user
+// buildUserContext builds context information about the user. The lookups are
+// independent, so they run concurrently; only a missing user fails the context, the
+// rest are left out with a warning when they fail.
 func (s *AgentService) buildUserContext(ctx context.Context, userID uuid.UUID) (string, error) {
-	user, err := s.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return "", err
-	}
+	var (
+		user       *domain.User
+		goals      []*domain.Goal
+		summary    *domain.DailySummary
+		target     *domain.NutritionTarget
+		activities []*domain.Activity
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		user, err = s.userRepo.GetByID(gctx, userID)
+		return err
+	})
 
 	// Get active goals
-	goals, err := s.goalService.GetGoals(ctx, userID.String(), stringPtr("active"))
-	if err != nil {
-		log.Printf("[AgentService] Warning: failed to get goals: %v", err)
-		goals = []*domain.Goal{}
-	}
+	g.Go(func() error {
+		var err error
+		goals, err = s.goalService.GetGoals(gctx, userID.String(), stringPtr("active"))
+		if err != nil {
+			requestid.Logf(ctx, "[AgentService] Warning: failed to get goals: %v", err)
+			goals = []*domain.Goal{}
+		}
+		return nil
+	})
 
-	// Get today's summary
-	today := time.Now()
-	summary, err := s.summaryService.GetDailySummary(ctx, userID.String(), today)
-	if err != nil {
-		log.Printf("[AgentService] Warning: failed to get daily summary: %v", err)
-	}
+	// Get today's summary and the targets it is measured against
+	g.Go(func() error {
+		var err error
+		summary, err = s.summaryService.GetDailySummary(gctx, userID.String(), time.Now())
+		if err != nil {
+			requestid.Logf(ctx, "[AgentService] Warning: failed to get daily summary: %v", err)
+			summary = nil
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		target, err = s.summaryService.GetNutritionTargets(gctx, userID.String())
+		if err != nil {
+			requestid.Logf(ctx, "[AgentService] Warning: failed to get nutrition targets: %v", err)
+			target = nil
+		}
+		return nil
+	})
 
 	// Get recent activities (last 7 days)
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -7)
-	activities, err := s.activityService.GetActivities(ctx, userID.String(), &startDate, &endDate)
-	if err != nil {
-		log.Printf("[AgentService] Warning: failed to get activities: %v", err)
-		activities = []*domain.Activity{}
+	g.Go(func() error {
+		endDate := time.Now()
+		startDate := endDate.AddDate(0, 0, -7)
+		var err error
+		activities, err = s.activityService.GetActivities(gctx, userID.String(), &startDate, &endDate)
+		if err != nil {
+			requestid.Logf(ctx, "[AgentService] Warning: failed to get activities: %v", err)
+			activities = []*domain.Activity{}
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return "", err
 	}
 
 	// Build context string
@@ -235,11 +432,16 @@ func (s *AgentService) buildUserContext(ctx context.Context, userID uuid.UUID) (
 	}
 
 	if summary != nil {
+		if target == nil {
+			target = domain.DefaultNutritionTarget(userID, goals)
+		}
+
 		context += fmt.Sprintf("\nToday's Nutrition:\n")
-		context += fmt.Sprintf("- Calories: %.0f / %.0f\n", summary.TotalCalories, 2000)
-		context += fmt.Sprintf("- Protein: %.1fg / %.1fg\n", summary.TotalProtein, 150)
-		context += fmt.Sprintf("- Carbs: %.1fg / %.1fg\n", summary.TotalCarbohydrates, 200)
-		context += fmt.Sprintf("- Fat: %.1fg / %.1fg\n", summary.TotalFat, 65)
+		context += macroProgress(summary, target.Macros())
+		if len(summary.MealTargets) > 0 {
+			context += "\nToday's Nutrition by Meal:\n"
+			context += mealTargetProgress(summary.MealTargets)
+		}
 	}
 
 	if len(activities) > 0 {
@@ -262,427 +464,142 @@ Guidelines:
 - Provide evidence-based advice
 - ALWAYS use tools to get accurate data before answering
 - Never hallucinate meal or workout history
+- Tie food advice to the meal it concerns, using each meal's remaining share of the day's target
 
 When user asks about progress, meals, or workouts, use the appropriate tool first.`, userContext)
 }
 
+// registerTools builds the tool registry. Each tool's name is declared once, in its
+// definition, and both the schemas sent to the model and the dispatcher derive from it.
 // buildToolDefinitions creates tool definitions for function calling
 func (s *AgentService) buildToolDefinitions() []external.Tool {
-	return []external.Tool{
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "log_meal",
-				Description: "Log a meal with food items",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"food_items": map[string]interface{}{
-							"type": "array",
-							"items": map[string]interface{}{
-								"type": "object",
-								"properties": map[string]interface{}{
-									"food_id":  map[string]string{"type": "string"},
-									"quantity": map[string]string{"type": "number"},
-									"unit":     map[string]string{"type": "string"},
-								},
-							},
-						},
-						"meal_type": map[string]interface{}{
-							"type": "string",
-							"enum": []string{"breakfast", "lunch", "dinner", "snack"},
-						},
-						"timestamp": map[string]string{"type": "string"},
-					},
-					"required": []string{"food_items", "meal_type"},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "get_recent_meals",
-				Description: "Get user's recent meals",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"days": map[string]interface{}{
-							"type":        "integer",
-							"description": "Number of days to look back",
-							"default":     7,
-						},
-					},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "search_foods",
-				Description: "Search for foods in the database",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"query": map[string]interface{}{
-							"type":        "string",
-							"description": "Search query for food name",
-						},
-					},
-					"required": []string{"query"},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "calculate_daily_macros",
-				Description: "Calculate daily macro totals for a specific date",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"date": map[string]interface{}{
-							"type":        "string",
-							"description": "Date in YYYY-MM-DD format",
-						},
-					},
-					"required": []string{"date"},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "get_recent_workouts",
-				Description: "Get user's recent workouts",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"days": map[string]interface{}{
-							"type":        "integer",
-							"description": "Number of days to look back",
-							"default":     7,
-						},
-					},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "get_recent_activities",
-				Description: "Get user's recent activities",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"days": map[string]interface{}{
-							"type":        "integer",
-							"description": "Number of days to look back",
-							"default":     7,
-						},
-					},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "log_weight",
-				Description: "Log a weight measurement",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"weight": map[string]interface{}{
-							"type":        "number",
-							"description": "Weight in kg",
-						},
-						"date": map[string]interface{}{
-							"type":        "string",
-							"description": "Date in YYYY-MM-DD format",
-						},
-					},
-					"required": []string{"weight"},
-				},
-			},
-		},
-		{
-			Type: "function",
-			Function: external.ToolFunction{
-				Name:        "get_weight_trend",
-				Description: "Get weight trend data",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"days": map[string]interface{}{
-							"type":        "integer",
-							"description": "Number of days to look back",
-							"default":     30,
-						},
-					},
-				},
-			},
-		},
-	}
+	return s.tools.Definitions()
 }
 
-// executeWithTools executes the LLM call with tool support
-func (s *AgentService) executeWithTools(ctx context.Context, messages []external.Message, toolDefs []external.Tool, userID uuid.UUID) (string, []string, error) {
-	toolsUsed := []string{}
+// executeWithTools executes the LLM call with tool support. Its metadata holds the tools
+// called, the tokens and cost of every completion, and the model that wrote the final
+// reply, which differs from defaultModel when the client fell back to another model.
+func (s *AgentService) executeWithTools(ctx context.Context, conversationID uuid.UUID, messages []external.Message, toolDefs []external.Tool, userID uuid.UUID) (string, *domain.MessageMetadata, error) {
+	metadata := &domain.MessageMetadata{ToolsUsed: []string{}}
 	maxIterations := 5
+	ctx = external.WithAuditContext(ctx, external.AuditOperationAgent, userID)
+
+	// Results of tool calls already executed in this turn, so a repeated call
+	// (e.g. log_meal replayed by the model) does not apply its side effect twice
+	executed := make(map[toolCallKey]string)
 
 	for i := 0; i < maxIterations; i++ {
 		// Call OpenRouter with tools
-		response, err := s.openRouterClient.ChatWithTools(ctx, messages, toolDefs, s.defaultModel)
+		response, err := s.openRouterClient.ChatWithToolsAndOptions(ctx, messages, toolDefs, s.defaultModel, external.ChatOptions{Temperature: s.temperature})
 		if err != nil {
-			return "", toolsUsed, fmt.Errorf("OpenRouter API call failed: %w", err)
+			return "", metadata, fmt.Errorf("OpenRouter API call failed: %w", err)
 		}
+		addUsage(metadata, response.Usage)
 
 		if len(response.Choices) == 0 {
-			return "", toolsUsed, fmt.Errorf("no response choices returned")
+			return "", metadata, fmt.Errorf("no response choices returned")
 		}
 
 		choice := response.Choices[0]
+		metadata.Model = response.Model
 
 		// Check if we have tool calls
 		if len(choice.Message.ToolCalls) == 0 {
 			// No more tool calls, return final response
-			return choice.Message.Content, toolsUsed, nil
+			return choice.Message.Content, metadata, nil
 		}
 
+		// Echo the assistant turn so tool results can reference its calls
+		messages = append(messages, external.Message{
+			Role:      "assistant",
+			Content:   choice.Message.Content,
+			ToolCalls: choice.Message.ToolCalls,
+		})
+
 		// Execute tool calls
 		for _, toolCall := range choice.Message.ToolCalls {
-			log.Printf("[AgentService] Executing tool: %s with args: %s", toolCall.Function.Name, toolCall.Function.Arguments)
-
-			result, err := s.executeTool(ctx, toolCall.Function.Name, toolCall.Function.Arguments, userID)
-			if err != nil {
-				log.Printf("[AgentService] Tool execution failed: %v", err)
-				result = fmt.Sprintf("Error: %v", err)
+			key := toolCallKey{conversationID: conversationID, callID: toolCall.ID}
+			content, seen := executed[key]
+			if seen {
+				requestid.Logf(ctx, "[AgentService] Skipping duplicate tool call %s (%s)", toolCall.ID, toolCall.Function.Name)
+			} else {
+				var result ToolResult
+				if _, ok := s.tools.Get(toolCall.Function.Name); !ok {
+					// Let the model recover instead of failing the whole turn
+					requestid.Logf(ctx, "[AgentService] Warning: model called unknown tool %q", toolCall.Function.Name)
+					result = s.unknownToolResponse(toolCall.Function.Name)
+				} else {
+					requestid.Logf(ctx, "[AgentService] Executing tool: %s with args: %s", toolCall.Function.Name, toolCall.Function.Arguments)
+
+					result, err = s.executeTool(ctx, toolCall.Function.Name, toolCall.Function.Arguments, userID)
+					if err != nil {
+						requestid.Logf(ctx, "[AgentService] Tool execution failed: %v", err)
+						result = toolError{Error: "tool_failed", Tool: toolCall.Function.Name, Message: err.Error()}
+					} else {
+						requestid.Logf(ctx, "[AgentService] Tool %s returned:\n%s", toolCall.Function.Name, result.Render())
+						if pending, ok := result.(pendingMealResult); ok && pending.ParsedMealID != nil {
+							metadata.PendingMealID = pending.ParsedMealID
+						}
+					}
+
+					metadata.ToolsUsed = append(metadata.ToolsUsed, toolCall.Function.Name)
+				}
+				content = encodeToolResult(result)
 			}
 
-			toolsUsed = append(toolsUsed, toolCall.Function.Name)
+			// Calls without an ID cannot be told apart, so they are never deduplicated
+			if toolCall.ID != "" {
+				executed[key] = content
+			}
 
 			// Add tool result to messages
 			messages = append(messages, external.Message{
-				Role:    "assistant",
-				Content: "",
-			})
-			messages = append(messages, external.Message{
-				Role:    "tool",
-				Content: result,
+				Role:       "tool",
+				Content:    content,
+				ToolCallID: toolCall.ID,
 			})
 		}
 	}
 
-	return "Maximum tool iterations reached", toolsUsed, nil
+	return "Maximum tool iterations reached", metadata, nil
 }
 
-// executeTool executes a specific tool function
-func (s *AgentService) executeTool(ctx context.Context, toolName, arguments string, userID uuid.UUID) (string, error) {
-	var args map[string]interface{}
-	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
-		return "", fmt.Errorf("failed to parse arguments: %w", err)
-	}
-
-	switch toolName {
-	case "log_meal":
-		return s.toolLogMeal(ctx, args, userID)
-	case "get_recent_meals":
-		return s.toolGetRecentMeals(ctx, args, userID)
-	case "search_foods":
-		return s.toolSearchFoods(ctx, args, userID)
-	case "calculate_daily_macros":
-		return s.toolCalculateDailyMacros(ctx, args, userID)
-	case "get_recent_workouts":
-		return s.toolGetRecentWorkouts(ctx, args, userID)
-	case "get_recent_activities":
-		return s.toolGetRecentActivities(ctx, args, userID)
-	case "log_weight":
-		return s.toolLogWeight(ctx, args, userID)
-	case "get_weight_trend":
-		return s.toolGetWeightTrend(ctx, args, userID)
-	default:
-		return "", fmt.Errorf("unknown tool: %s", toolName)
-	}
-}
-
-// Tool implementations
-
-func (s *AgentService) toolLogMeal(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	// Implementation would create a meal using MealService
-	return "Meal logging not yet implemented", nil
-}
-
-func (s *AgentService) toolGetRecentMeals(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	days := 7
-	if d, ok := args["days"].(float64); ok {
-		days = int(d)
-	}
-
-	endDate := time.Now()
-	_ = endDate.AddDate(0, 0, -days)
-
-	// Note: The actual implementation would need a GetMeals method that accepts date range
-	// For now, we'll return a placeholder
-	return fmt.Sprintf("Retrieved meals from last %d days", days), nil
-}
-
-func (s *AgentService) toolSearchFoods(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	query, ok := args["query"].(string)
-	if !ok {
-		return "", fmt.Errorf("query parameter required")
-	}
-
-	foods, err := s.foodService.SearchFoods(ctx, query, nil, 10)
-	if err != nil {
-		return "", err
-	}
-
-	result := fmt.Sprintf("Found %d foods matching '%s':\n", len(foods), query)
-	for i, food := range foods {
-		if i >= 10 {
-			break
-		}
-		result += fmt.Sprintf("- %s (%.0f cal, %.1fg protein, %.1fg carbs, %.1fg fat per %s)\n",
-			food.Name, food.Calories, food.Protein, food.Carbohydrates, food.Fat, food.ServingUnit)
-	}
-
-	return result, nil
-}
-
-func (s *AgentService) toolCalculateDailyMacros(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	dateStr, ok := args["date"].(string)
-	if !ok {
-		return "", fmt.Errorf("date parameter required")
-	}
-
-	date, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		return "", fmt.Errorf("invalid date format: %w", err)
-	}
-
-	summary, err := s.summaryService.GetDailySummary(ctx, userID.String(), date)
-	if err != nil {
-		return "", err
-	}
-
-	result := fmt.Sprintf("Daily macros for %s:\n", dateStr)
-	result += fmt.Sprintf("- Calories: %.0f / %.0f\n", summary.TotalCalories, 2000)
-	result += fmt.Sprintf("- Protein: %.1fg / %.1fg\n", summary.TotalProtein, 150)
-	result += fmt.Sprintf("- Carbs: %.1fg / %.1fg\n", summary.TotalCarbohydrates, 200)
-	result += fmt.Sprintf("- Fat: %.1fg / %.1fg\n", summary.TotalFat, 65)
-
-	return result, nil
-}
-
-func (s *AgentService) toolGetRecentWorkouts(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	days := 7
-	if d, ok := args["days"].(float64); ok {
-		days = int(d)
-	}
-
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -days)
-
-	workouts, err := s.workoutService.GetWorkouts(ctx, userID.String(), &startDate, &endDate)
-	if err != nil {
-		return "", err
-	}
-
-	result := fmt.Sprintf("Found %d workouts in the last %d days:\n", len(workouts), days)
-	for _, workout := range workouts {
-		duration := ""
-		if workout.DurationMinutes != nil {
-			duration = fmt.Sprintf(" (%d min)", *workout.DurationMinutes)
+// addUsage adds a completion's tokens and cost to the turn's metadata
+func addUsage(metadata *domain.MessageMetadata, usage external.Usage) {
+	metadata.PromptTokens += usage.PromptTokens
+	metadata.CompletionTokens += usage.CompletionTokens
+	metadata.TotalTokens += usage.TotalTokens
+	if usage.Cost != nil {
+		cost := *usage.Cost
+		if metadata.CostUSD != nil {
+			cost += *metadata.CostUSD
 		}
-		result += fmt.Sprintf("- %s on %s%s\n", workout.Name, workout.StartTime.Format("2006-01-02"), duration)
+		metadata.CostUSD = &cost
 	}
-
-	return result, nil
 }
 
-func (s *AgentService) toolGetRecentActivities(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	days := 7
-	if d, ok := args["days"].(float64); ok {
-		days = int(d)
-	}
-
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -days)
-
-	activities, err := s.activityService.GetActivities(ctx, userID.String(), &startDate, &endDate)
-	if err != nil {
-		return "", err
-	}
-
-	result := fmt.Sprintf("Found %d activities in the last %d days:\n", len(activities), days)
-	for _, activity := range activities {
-		duration := ""
-		if activity.DurationMinutes != nil {
-			duration = fmt.Sprintf(" (%d min)", *activity.DurationMinutes)
-		}
-		calories := ""
-		if activity.CaloriesBurned != nil {
-			calories = fmt.Sprintf(", %.0f cal", *activity.CaloriesBurned)
-		}
-		result += fmt.Sprintf("- %s on %s%s%s\n", activity.ActivityType, activity.StartTime.Format("2006-01-02"), duration, calories)
+// executeTool executes a specific tool function
+func (s *AgentService) executeTool(ctx context.Context, toolName, arguments string, userID uuid.UUID) (ToolResult, error) {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
-	return result, nil
-}
-
-func (s *AgentService) toolLogWeight(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	weight, ok := args["weight"].(float64)
+	tool, ok := s.tools.Get(toolName)
 	if !ok {
-		return "", fmt.Errorf("weight parameter required")
+		return nil, fmt.Errorf("unknown tool: %s", toolName)
 	}
-
-	date := time.Now()
-	if dateStr, ok := args["date"].(string); ok {
-		parsedDate, err := time.Parse("2006-01-02", dateStr)
-		if err == nil {
-			date = parsedDate
-		}
-	}
-
-	_, err := s.metricService.LogMetric(ctx, userID.String(), "weight", weight, "kg", date)
-	if err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("Logged weight: %.1f kg on %s", weight, date.Format("2006-01-02")), nil
+	return tool.Execute(ctx, args, userID)
 }
 
-func (s *AgentService) toolGetWeightTrend(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (string, error) {
-	days := 30
-	if d, ok := args["days"].(float64); ok {
-		days = int(d)
-	}
-
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -days)
-
-	metrics, err := s.metricService.GetMetricTrend(ctx, userID.String(), "weight", &startDate, &endDate)
-	if err != nil {
-		return "", err
-	}
-
-	if len(metrics) == 0 {
-		return fmt.Sprintf("No weight data found for the last %d days", days), nil
+// unknownToolResponse builds the structured result fed back for an unregistered tool
+func (s *AgentService) unknownToolResponse(toolName string) ToolResult {
+	return unknownToolResult{
+		Error:          "unknown_tool",
+		Tool:           toolName,
+		Message:        fmt.Sprintf("Tool %q does not exist. Use one of the available tools or answer without a tool.", toolName),
+		AvailableTools: s.tools.Names(),
 	}
-
-	result := fmt.Sprintf("Weight trend (last %d days, %d measurements):\n", days, len(metrics))
-	for _, metric := range metrics {
-		result += fmt.Sprintf("- %s: %.1f kg\n", metric.MeasuredAt.Format("2006-01-02"), metric.Value)
-	}
-
-	// Calculate trend
-	if len(metrics) >= 2 {
-		first := metrics[0].Value
-		last := metrics[len(metrics)-1].Value
-		change := last - first
-		result += fmt.Sprintf("\nChange: %.1f kg", change)
-	}
-
-	return result, nil
 }
 
 // Helper functions
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// dailyMacrosTool totals the user's macros for a day
+type dailyMacrosTool struct {
+	summaryService ports.SummaryService
+}
+
+func (t *dailyMacrosTool) Name() string {
+	return "calculate_daily_macros"
+}
+
+func (t *dailyMacrosTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Calculate daily macro totals for a specific date",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"date": map[string]interface{}{
+					"type":        "string",
+					"description": "Date in YYYY-MM-DD format",
+				},
+			},
+			"required": []string{"date"},
+		},
+	})
+}
+
+// dailyMacrosResult is a day's macro totals against the user's targets
+type dailyMacrosResult struct {
+	Date     string                 `json:"date"`
+	Consumed domain.MacroTargets    `json:"consumed"`
+	Targets  domain.MacroTargets    `json:"targets"`
+	Limits   []domain.NutrientLimit `json:"limits,omitempty"`
+
+	summary *domain.DailySummary
+}
+
+func (r *dailyMacrosResult) Render() string {
+	return fmt.Sprintf("Daily macros for %s:\n", r.Date) + macroProgress(r.summary, r.Targets)
+}
+
+func (t *dailyMacrosTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	dateStr, ok := args["date"].(string)
+	if !ok {
+		return nil, fmt.Errorf("date parameter required")
+	}
+
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
+	}
+
+	summary, err := t.summaryService.GetDailySummary(ctx, userID.String(), date)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := t.summaryService.GetNutritionTargets(ctx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	targets := target.Macros()
+	if summary.CalorieTarget != nil {
+		// Includes calories earned back by exercise when the user's target is dynamic
+		targets.Calories = *summary.CalorieTarget
+	}
+
+	limits := make([]domain.NutrientLimit, 0, len(summary.NutrientLimits))
+	for _, limit := range summary.NutrientLimits {
+		limit.Consumed = roundTenth(limit.Consumed)
+		limit.Remaining = roundTenth(limit.Remaining)
+		limits = append(limits, limit)
+	}
+
+	return &dailyMacrosResult{
+		Date: dateStr,
+		Consumed: roundMacros(domain.MacroTargets{
+			Calories:      summary.TotalCalories,
+			Protein:       summary.TotalProtein,
+			Carbohydrates: summary.TotalCarbohydrates,
+			Fat:           summary.TotalFat,
+		}),
+		Targets: roundMacros(targets),
+		Limits:  limits,
+		summary: summary,
+	}, nil
+}
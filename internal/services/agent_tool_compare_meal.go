@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// compareMealTool compares a logged meal with the user's typical meal of the same type
+type compareMealTool struct {
+	mealComparisonService ports.MealComparisonService
+}
+
+func (t *compareMealTool) Name() string {
+	return "compare_meal"
+}
+
+func (t *compareMealTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Compare a logged meal's calories and macros with the user's typical meal of the same type (e.g. their typical lunch), to tell whether it was heavier or lighter than usual",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"meal_id": map[string]interface{}{
+					"type":        "string",
+					"description": "ID of the meal to compare; defaults to the most recently logged meal",
+				},
+			},
+		},
+	})
+}
+
+// mealComparisonResult is the comparison with its figures rounded for the model
+type mealComparisonResult struct {
+	*domain.MealComparison
+}
+
+func (r mealComparisonResult) Render() string {
+	c := r.MealComparison
+	if !c.EnoughHistory {
+		return fmt.Sprintf("Only %d past %s meals logged, not enough to tell what is typical. %s",
+			c.HistoryCount, c.MealType, c.Summary)
+	}
+
+	result := c.Summary + "\n"
+	result += fmt.Sprintf("This meal: %.0f cal, %.0fg protein, %.0fg carbs, %.0fg fat\n",
+		c.Meal.Calories, c.Meal.Protein, c.Meal.Carbohydrates, c.Meal.Fat)
+	result += fmt.Sprintf("Typical %s (average of %d): %.0f cal, %.0fg protein, %.0fg carbs, %.0fg fat\n",
+		c.MealType, c.HistoryCount, c.Typical.Calories, c.Typical.Protein, c.Typical.Carbohydrates, c.Typical.Fat)
+	if c.CaloriesPercentile != nil {
+		result += fmt.Sprintf("More calories than %.0f%% of past %s meals\n", *c.CaloriesPercentile, c.MealType)
+	}
+	return result
+}
+
+func (t *compareMealTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	mealID, _ := args["meal_id"].(string)
+
+	comparison, err := t.mealComparisonService.CompareLoggedMeal(ctx, userID.String(), mealID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return toolNote{Note: "No logged meal found to compare."}, nil
+		}
+		return nil, err
+	}
+
+	rounded := *comparison
+	rounded.Meal = roundMacros(comparison.Meal)
+	rounded.Typical = roundMacros(comparison.Typical)
+	for _, percent := range []**float64{
+		&rounded.CaloriesDeltaPercent, &rounded.ProteinDeltaPercent,
+		&rounded.CarbohydratesDeltaPercent, &rounded.FatDeltaPercent, &rounded.CaloriesPercentile,
+	} {
+		if *percent != nil {
+			value := math.Round(**percent)
+			*percent = &value
+		}
+	}
+
+	return mealComparisonResult{&rounded}, nil
+}
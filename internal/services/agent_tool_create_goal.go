@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// createGoalTool sets a new goal for the user, such as a target weight by a date
+type createGoalTool struct {
+	goalService ports.GoalService
+}
+
+func (t *createGoalTool) Name() string {
+	return "create_goal"
+}
+
+func (t *createGoalTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Create a goal for the user. For weight goals target_value is the target body weight, not the amount to lose or gain: for \"lose 5kg\" subtract 5 from the current weight. Only call it when the user asks to set a goal.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"goal_type": map[string]interface{}{
+					"type": "string",
+					"enum": slices.Sorted(maps.Keys(validGoalTypes)),
+				},
+				"target_value": map[string]interface{}{
+					"type":        "number",
+					"description": "Target to reach, greater than zero",
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"description": "Unit of the target, e.g. kg, lb, steps, g (default: kg for weight goals)",
+				},
+				"target_date": map[string]interface{}{
+					"type":        "string",
+					"description": "Deadline in YYYY-MM-DD format, after today",
+				},
+				"description": map[string]interface{}{
+					"type":        "string",
+					"description": "Short description of the goal in the user's words",
+				},
+			},
+			"required": []string{"goal_type", "target_value"},
+		},
+	})
+}
+
+// goalResult confirms a created or updated goal
+type goalResult struct {
+	ID           uuid.UUID `json:"id"`
+	GoalType     string    `json:"goal_type"`
+	Description  string    `json:"description,omitempty"`
+	TargetValue  float64   `json:"target_value"`
+	CurrentValue *float64  `json:"current_value,omitempty"`
+	Unit         string    `json:"unit,omitempty"`
+	TargetDate   string    `json:"target_date,omitempty"`
+	Status       string    `json:"status"`
+	Warnings     []string  `json:"warnings,omitempty"`
+}
+
+func newGoalResult(goal *domain.Goal) *goalResult {
+	result := &goalResult{
+		ID:           goal.ID,
+		GoalType:     goal.GoalType,
+		Description:  goal.Description,
+		TargetValue:  goal.TargetValue,
+		CurrentValue: goal.CurrentValue,
+		Unit:         goal.Unit,
+		Status:       goal.Status,
+		Warnings:     goal.Warnings,
+	}
+	if goal.TargetDate != nil {
+		result.TargetDate = goal.TargetDate.Format("2006-01-02")
+	}
+	return result
+}
+
+func (r *goalResult) Render() string {
+	result := fmt.Sprintf("Goal %s: %s to %.1f %s", r.ID, r.GoalType, r.TargetValue, r.Unit)
+	if r.TargetDate != "" {
+		result += " by " + r.TargetDate
+	}
+	if r.CurrentValue != nil {
+		result += fmt.Sprintf(", currently %.1f", *r.CurrentValue)
+	}
+	result += " (" + r.Status + ")"
+	for _, warning := range r.Warnings {
+		result += "\nWarning: " + warning
+	}
+	return result
+}
+
+func (t *createGoalTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	goalType, _ := args["goal_type"].(string)
+	if !validGoalTypes[goalType] {
+		return nil, fmt.Errorf("%w: goal_type must be one of %s", domain.ErrInvalidInput, strings.Join(slices.Sorted(maps.Keys(validGoalTypes)), ", "))
+	}
+	target, ok := args["target_value"].(float64)
+	if !ok || target <= 0 {
+		return nil, fmt.Errorf("%w: target_value must be a number greater than zero", domain.ErrInvalidInput)
+	}
+
+	goal := &domain.Goal{GoalType: goalType, TargetValue: target}
+	goal.Description, _ = args["description"].(string)
+	goal.Unit, _ = args["unit"].(string)
+	if goal.Unit == "" && (goalType == "weight_loss" || goalType == "weight_gain") {
+		goal.Unit = "kg"
+	}
+
+	if dateStr, ok := args["target_date"].(string); ok && dateStr != "" {
+		targetDate, err := parseGoalDeadline(dateStr)
+		if err != nil {
+			return nil, err
+		}
+		goal.TargetDate = &targetDate
+	}
+
+	created, err := t.goalService.CreateGoal(ctx, userID.String(), goal)
+	if err != nil {
+		return nil, err
+	}
+	return newGoalResult(created), nil
+}
+
+// parseGoalDeadline parses a YYYY-MM-DD deadline, which must fall after today
+func parseGoalDeadline(dateStr string) (time.Time, error) {
+	targetDate, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: target_date must be in YYYY-MM-DD format", domain.ErrInvalidInput)
+	}
+	if !targetDate.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%w: target_date must be after today", domain.ErrInvalidInput)
+	}
+	return targetDate, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// estimateFoodTool estimates a freeform food's nutrition without logging or saving it
+type estimateFoodTool struct {
+	estimator ports.FoodEstimator
+}
+
+func (t *estimateFoodTool) Name() string {
+	return "estimate_food"
+}
+
+func (t *estimateFoodTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Estimate calories and macros for an amount of any food, like \"a Big Mac\" or \"200g lasagna\", without logging it. Use for questions about a food's nutrition; use search_foods to find foods in the database.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "The food, as the user described it",
+				},
+				"quantity": map[string]interface{}{
+					"type":        "number",
+					"description": "Amount of the food (default 1)",
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"description": "Unit of the amount: g, kg, oz, ml, cup, tbsp, tsp, piece or serving (default serving)",
+				},
+			},
+			"required": []string{"name"},
+		},
+	})
+}
+
+// foodEstimateResult is the estimated nutrition of an amount of food
+type foodEstimateResult struct {
+	*domain.FoodEstimate
+}
+
+func (r foodEstimateResult) Render() string {
+	return fmt.Sprintf("Estimate for %g %s of %s (about %.0fg): %.0f cal, %.1fg protein, %.1fg carbs, %.1fg fat. This is an estimate, not a logged food.",
+		r.Quantity, r.Unit, r.Name, r.Grams, r.Calories, r.Protein, r.Carbohydrates, r.Fat)
+}
+
+func (t *estimateFoodTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("name parameter required")
+	}
+	quantity, _ := args["quantity"].(float64)
+	unit, _ := args["unit"].(string)
+
+	estimate, err := t.estimator.EstimateFood(ctx, userID, name, quantity, unit)
+	if errors.Is(err, domain.ErrNotFound) {
+		return toolNote{Note: fmt.Sprintf("No nutrition estimate is available for %q", name)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return foodEstimateResult{FoodEstimate: estimate}, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// goalETADefaultDays is how far back the weight trend looks unless the model asks otherwise
+const goalETADefaultDays = 56
+
+// goalETATool projects when the user's active weight goal will be reached at their current trend
+type goalETATool struct {
+	goalService   ports.GoalService
+	metricService ports.MetricService
+}
+
+func (t *goalETATool) Name() string {
+	return "estimate_goal_eta"
+}
+
+func (t *goalETATool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Estimate when the user will reach their active weight goal at their current weight trend (kg or lb per week), with a confidence note. Use it for questions like \"at this rate, when will I hit 75kg?\"",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days of weigh-ins the trend is fitted to",
+					"default":     goalETADefaultDays,
+				},
+			},
+		},
+	})
+}
+
+// goalETAResult projects when the user's weight goal is reached. The trend fields are
+// left out when there are too few weigh-ins; Advice tells the model how to respond.
+type goalETAResult struct {
+	GoalType       string   `json:"goal_type"`
+	Target         float64  `json:"target"`
+	Unit           string   `json:"unit"`
+	Status         string   `json:"status"`
+	Days           int      `json:"days"` // how far back weigh-ins were looked at
+	Current        *float64 `json:"current,omitempty"`
+	RatePerWeek    *float64 `json:"rate_per_week,omitempty"`
+	EstimatedDate  string   `json:"estimated_date,omitempty"`
+	WeeksRemaining *float64 `json:"weeks_remaining,omitempty"`
+	Measurements   int      `json:"measurements"`
+	SpanDays       int      `json:"span_days"`
+	Confidence     string   `json:"confidence,omitempty"`
+	Advice         string   `json:"advice,omitempty"`
+}
+
+func (r *goalETAResult) Render() string {
+	result := fmt.Sprintf("Goal: %s to %.1f %s\n", r.GoalType, r.Target, r.Unit)
+	if r.Status == domain.GoalETAInsufficientData {
+		return result + fmt.Sprintf("Not enough weigh-ins in the last %d days to project a date (%d found). %s", r.Days, r.Measurements, r.Advice)
+	}
+
+	result += fmt.Sprintf("Current: %.1f %s\n", *r.Current, r.Unit)
+	if r.Status == domain.GoalETAReached {
+		return result + r.Advice
+	}
+
+	result += fmt.Sprintf("Trend: %+.2f %s/week over %d days (%d weigh-ins)\n", *r.RatePerWeek, r.Unit, r.SpanDays, r.Measurements)
+	switch r.Status {
+	case domain.GoalETAOnTrack:
+		result += fmt.Sprintf("Estimated date: %s (about %.1f weeks)\n", r.EstimatedDate, *r.WeeksRemaining)
+	case domain.GoalETAWrongDirection, domain.GoalETAFlat:
+		result += "Flag: " + r.Advice + "\n"
+	}
+
+	result += fmt.Sprintf("Confidence: %s (%d weigh-ins over %d days)", r.Confidence, r.Measurements, r.SpanDays)
+	if r.Confidence != domain.GoalETAConfidenceHigh {
+		result += "; more frequent weigh-ins would make the projection more reliable"
+	}
+	return result
+}
+
+func (t *goalETATool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	days := goalETADefaultDays
+	if d, ok := args["days"].(float64); ok && d >= 7 {
+		days = int(d)
+	}
+
+	active := "active"
+	goals, err := t.goalService.GetGoals(ctx, userID.String(), &active)
+	if err != nil {
+		return nil, err
+	}
+	var goal *domain.Goal
+	for _, g := range goals {
+		if g.GoalType == "weight_loss" || g.GoalType == "weight_gain" {
+			goal = g
+			break
+		}
+	}
+	if goal == nil {
+		return toolNote{Note: "The user has no active weight goal. Suggest setting one with a target weight."}, nil
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+	weights, err := t.metricService.GetMetricTrend(ctx, userID.String(), "weight", &startDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := goal.Unit
+	if unit == "" {
+		unit = "kg"
+	}
+	points := make([]domain.TrendPoint, len(weights))
+	for i, weight := range weights {
+		points[i] = domain.TrendPoint{At: weight.MeasuredAt, Value: weightInUnit(weight, unit)}
+	}
+	eta := domain.EstimateGoalETA(goal.GoalType, goal.TargetValue, points)
+
+	result := &goalETAResult{
+		GoalType:     goal.GoalType,
+		Target:       roundTenth(goal.TargetValue),
+		Unit:         unit,
+		Status:       eta.Status,
+		Days:         days,
+		Measurements: eta.Measurements,
+		SpanDays:     eta.SpanDays,
+	}
+	if eta.Status == domain.GoalETAInsufficientData {
+		result.Advice = "At least two weigh-ins on different days are needed. Do not guess a date; suggest weighing in a few times a week."
+		return result, nil
+	}
+
+	current := roundTenth(eta.Current)
+	result.Current = &current
+	if eta.Status == domain.GoalETAReached {
+		result.Advice = "The latest weigh-in has already reached the target."
+		return result, nil
+	}
+
+	rate := math.Round(eta.RatePerWeek*100) / 100
+	result.RatePerWeek = &rate
+	result.Confidence = eta.Confidence
+	switch eta.Status {
+	case domain.GoalETAOnTrack:
+		weeks := roundTenth(*eta.WeeksRemaining)
+		result.WeeksRemaining = &weeks
+		result.EstimatedDate = eta.EstimatedDate.Format("2006-01-02")
+	case domain.GoalETAWrongDirection:
+		result.Advice = "the trend is moving away from the target, so no date can be projected at this rate."
+	case domain.GoalETAFlat:
+		result.Advice = fmt.Sprintf("the trend is flat; at this rate the target is more than %d weeks away.", domain.MaxGoalETAWeeks)
+	}
+	return result, nil
+}
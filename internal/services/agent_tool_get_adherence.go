@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// adherenceTool reports how consistently the user hit their targets
+type adherenceTool struct {
+	summaryService ports.SummaryService
+}
+
+func (t *adherenceTool) Name() string {
+	return "get_adherence"
+}
+
+func (t *adherenceTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Get how consistently the user hit their calorie and macro targets, per day and as a percentage",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days to look back, including today",
+					"default":     7,
+				},
+			},
+		},
+	})
+}
+
+// adherenceResult is the adherence summary with totals and percentages rounded
+type adherenceResult struct {
+	*domain.AdherenceSummary
+}
+
+func (r adherenceResult) Render() string {
+	summary := r.AdherenceSummary
+	result := fmt.Sprintf("Target adherence %s to %s (%d of %d days logged):\n",
+		summary.From, summary.To, summary.DaysLogged, len(summary.Days))
+	result += fmt.Sprintf("- Calories (%.0f): %.0f%% of days\n", summary.Targets.Calories, summary.Adherence.Calories)
+	result += fmt.Sprintf("- Protein (%.0fg): %.0f%% of days\n", summary.Targets.Protein, summary.Adherence.Protein)
+	result += fmt.Sprintf("- Carbs (%.0fg): %.0f%% of days\n", summary.Targets.Carbohydrates, summary.Adherence.Carbohydrates)
+	result += fmt.Sprintf("- Fat (%.0fg): %.0f%% of days\n", summary.Targets.Fat, summary.Adherence.Fat)
+	result += fmt.Sprintf("- All targets: %.0f%% of days\n", summary.Adherence.Overall)
+
+	for _, day := range summary.Days {
+		if !day.Logged {
+			result += fmt.Sprintf("%s: nothing logged\n", day.Date)
+			continue
+		}
+		result += fmt.Sprintf("%s: %.0f cal, %.0fg protein (calories met: %t, protein met: %t)\n",
+			day.Date, day.Totals.Calories, day.Totals.Protein, day.CaloriesMet, day.ProteinMet)
+	}
+	return result
+}
+
+func (t *adherenceTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	days := 7
+	if d, ok := args["days"].(float64); ok && d >= 1 {
+		days = int(d)
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -(days - 1))
+
+	summary, err := t.summaryService.GetAdherence(ctx, userID.String(), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rounded := *summary
+	rounded.Targets = roundMacros(summary.Targets)
+	rounded.Days = make([]domain.DayAdherence, len(summary.Days))
+	for i, day := range summary.Days {
+		day.Totals = roundMacros(day.Totals)
+		rounded.Days[i] = day
+	}
+	rounded.Adherence = domain.AdherencePercentages{
+		Calories:      math.Round(summary.Adherence.Calories),
+		Protein:       math.Round(summary.Adherence.Protein),
+		Carbohydrates: math.Round(summary.Adherence.Carbohydrates),
+		Fat:           math.Round(summary.Adherence.Fat),
+		Overall:       math.Round(summary.Adherence.Overall),
+	}
+
+	return adherenceResult{&rounded}, nil
+}
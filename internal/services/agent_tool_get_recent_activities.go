@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/ports"
+)
+
+// recentActivitiesTool lists the user's recent activities
+type recentActivitiesTool struct {
+	activityService ports.ActivityService
+}
+
+func (t *recentActivitiesTool) Name() string {
+	return "get_recent_activities"
+}
+
+func (t *recentActivitiesTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Get user's recent activities",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days to look back",
+					"default":     7,
+				},
+			},
+		},
+	})
+}
+
+// recentActivitiesResult lists activities over the last Days days
+type recentActivitiesResult struct {
+	Days       int              `json:"days"`
+	Activities []activityResult `json:"activities"`
+}
+
+type activityResult struct {
+	Type            string   `json:"type"`
+	Date            string   `json:"date"`
+	DurationMinutes *int     `json:"duration_minutes,omitempty"`
+	Calories        *float64 `json:"calories,omitempty"`
+}
+
+func (r *recentActivitiesResult) Render() string {
+	result := fmt.Sprintf("Found %d activities in the last %d days:\n", len(r.Activities), r.Days)
+	for _, activity := range r.Activities {
+		duration := ""
+		if activity.DurationMinutes != nil {
+			duration = fmt.Sprintf(" (%d min)", *activity.DurationMinutes)
+		}
+		calories := ""
+		if activity.Calories != nil {
+			calories = fmt.Sprintf(", %.0f cal", *activity.Calories)
+		}
+		result += fmt.Sprintf("- %s on %s%s%s\n", activity.Type, activity.Date, duration, calories)
+	}
+	return result
+}
+
+func (t *recentActivitiesTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	days := 7
+	if d, ok := args["days"].(float64); ok {
+		days = int(d)
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	activities, err := t.activityService.GetActivities(ctx, userID.String(), &startDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &recentActivitiesResult{Days: days, Activities: make([]activityResult, 0, len(activities))}
+	for _, activity := range activities {
+		var calories *float64
+		if activity.CaloriesBurned != nil {
+			rounded := math.Round(*activity.CaloriesBurned)
+			calories = &rounded
+		}
+		result.Activities = append(result.Activities, activityResult{
+			Type:            activity.ActivityType,
+			Date:            activity.StartTime.Format("2006-01-02"),
+			DurationMinutes: activity.DurationMinutes,
+			Calories:        calories,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// recentWorkoutsTool lists the user's recent workouts
+type recentWorkoutsTool struct {
+	workoutService ports.WorkoutService
+}
+
+func (t *recentWorkoutsTool) Name() string {
+	return "get_recent_workouts"
+}
+
+func (t *recentWorkoutsTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Get user's recent workouts, optionally only those that included a given exercise",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days to look back",
+					"default":     7,
+				},
+				"exercise": map[string]interface{}{
+					"type":        "string",
+					"description": "Only workouts that included this exercise, e.g. 'squat'",
+				},
+			},
+		},
+	})
+}
+
+// recentWorkoutsResult lists workouts over the last Days days
+type recentWorkoutsResult struct {
+	Days     int             `json:"days"`
+	Exercise string          `json:"exercise,omitempty"` // the exercise the workouts were filtered by
+	Workouts []workoutResult `json:"workouts"`
+}
+
+type workoutResult struct {
+	Name            string `json:"name"`
+	Date            string `json:"date"`
+	DurationMinutes *int   `json:"duration_minutes,omitempty"`
+}
+
+func (r *recentWorkoutsResult) Render() string {
+	with := ""
+	if r.Exercise != "" {
+		with = " with " + r.Exercise
+	}
+	result := fmt.Sprintf("Found %d workouts%s in the last %d days:\n", len(r.Workouts), with, r.Days)
+	for _, workout := range r.Workouts {
+		duration := ""
+		if workout.DurationMinutes != nil {
+			duration = fmt.Sprintf(" (%d min)", *workout.DurationMinutes)
+		}
+		result += fmt.Sprintf("- %s on %s%s\n", workout.Name, workout.Date, duration)
+	}
+	return result
+}
+
+func (t *recentWorkoutsTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	days := 7
+	if d, ok := args["days"].(float64); ok {
+		days = int(d)
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	var filter domain.WorkoutFilter
+	exerciseName := ""
+	if name, ok := args["exercise"].(string); ok && name != "" {
+		exercise, err := t.workoutService.FindExercise(ctx, name)
+		if errors.Is(err, domain.ErrNotFound) {
+			return toolNote{Note: fmt.Sprintf("No exercise matching %q was found in the exercise library", name)}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		filter.ExerciseID = &exercise.ID
+		exerciseName = exercise.Name
+	}
+
+	workouts, err := t.workoutService.GetWorkouts(ctx, userID, &startDate, &endDate, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &recentWorkoutsResult{Days: days, Exercise: exerciseName, Workouts: make([]workoutResult, 0, len(workouts))}
+	for _, workout := range workouts {
+		result.Workouts = append(result.Workouts, workoutResult{
+			Name:            workout.Name,
+			Date:            workout.StartTime.Format("2006-01-02"),
+			DurationMinutes: workout.DurationMinutes,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// remainingMacrosTool reports what is left of today's targets, today being the user's
+// local day, so "how much protein do I have left" takes one call
+type remainingMacrosTool struct {
+	userRepo       ports.UserRepository
+	summaryService ports.SummaryService
+}
+
+func (t *remainingMacrosTool) Name() string {
+	return "get_remaining_macros"
+}
+
+func (t *remainingMacrosTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Get the calories, protein, carbs and fat the user has consumed today, their targets, and what remains",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+	})
+}
+
+// remainingMacrosResult is today's intake against the user's targets. Remaining never
+// drops below zero; a target already exceeded shows as consumed above target.
+type remainingMacrosResult struct {
+	Date      string              `json:"date"`
+	Timezone  string              `json:"timezone"`
+	Consumed  domain.MacroTargets `json:"consumed"`
+	Targets   domain.MacroTargets `json:"targets"`
+	Remaining domain.MacroTargets `json:"remaining"`
+}
+
+func (r *remainingMacrosResult) Render() string {
+	return fmt.Sprintf("Remaining for %s (%s): %s\n- Consumed: %s\n- Targets: %s",
+		r.Date, r.Timezone, renderMacros(r.Remaining), renderMacros(r.Consumed), renderMacros(r.Targets))
+}
+
+func (t *remainingMacrosTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	result, err := remainingMacros(ctx, t.userRepo, t.summaryService, userID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/ports"
+)
+
+// weightTrendTool reports the user's weight trend
+type weightTrendTool struct {
+	metricService ports.MetricService
+}
+
+func (t *weightTrendTool) Name() string {
+	return "get_weight_trend"
+}
+
+func (t *weightTrendTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Get weight trend data",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days to look back",
+					"default":     30,
+				},
+			},
+		},
+	})
+}
+
+// weightTrendResult is the user's weigh-ins over the last Days days, oldest first
+type weightTrendResult struct {
+	Days         int                 `json:"days"`
+	Measurements []weightMeasurement `json:"measurements"`
+	// ChangeKg is the last weigh-in minus the first; it needs at least two
+	ChangeKg *float64 `json:"change_kg,omitempty"`
+}
+
+type weightMeasurement struct {
+	Date     string  `json:"date"`
+	WeightKg float64 `json:"weight_kg"`
+}
+
+func (r *weightTrendResult) Render() string {
+	result := fmt.Sprintf("Weight trend (last %d days, %d measurements):\n", r.Days, len(r.Measurements))
+	for _, m := range r.Measurements {
+		result += fmt.Sprintf("- %s: %.1f kg\n", m.Date, m.WeightKg)
+	}
+	if r.ChangeKg != nil {
+		result += fmt.Sprintf("\nChange: %.1f kg", *r.ChangeKg)
+	}
+	return result
+}
+
+func (t *weightTrendTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	days := 30
+	if d, ok := args["days"].(float64); ok {
+		days = int(d)
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	metrics, err := t.metricService.GetMetricTrend(ctx, userID.String(), "weight", &startDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) == 0 {
+		return toolNote{Note: fmt.Sprintf("No weight data found for the last %d days", days)}, nil
+	}
+
+	result := &weightTrendResult{Days: days, Measurements: make([]weightMeasurement, 0, len(metrics))}
+	for _, metric := range metrics {
+		result.Measurements = append(result.Measurements, weightMeasurement{
+			Date:     metric.MeasuredAt.Format("2006-01-02"),
+			WeightKg: roundTenth(metric.Value),
+		})
+	}
+
+	// Calculate trend
+	if len(metrics) >= 2 {
+		change := roundTenth(metrics[len(metrics)-1].Value - metrics[0].Value)
+		result.ChangeKg = &change
+	}
+
+	return result, nil
+}
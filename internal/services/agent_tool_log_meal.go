@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// DefaultAutoLogConfidence is the confidence the model must report for log_meal to log a
+// meal straight away; below it the meal waits for the user to confirm
+const DefaultAutoLogConfidence = 0.8
+
+// logMealTool logs a meal for the user. Meals the model is unsure of, or that leave a
+// quantity out, are held as pending until the user confirms them with confirm_meal.
+type logMealTool struct {
+	mealService ports.MealService
+	foodService ports.FoodService
+
+	// Holds meals awaiting confirmation; without it the model is asked to confirm with
+	// the user itself and call log_meal again
+	pending       ports.ParsedMealStore
+	minConfidence float64
+}
+
+// loggedMealResult reports a meal log_meal or confirm_meal saved
+type loggedMealResult struct {
+	Status   string              `json:"status"` // always "logged"
+	MealID   uuid.UUID           `json:"meal_id"`
+	Name     string              `json:"name"`
+	MealType string              `json:"meal_type"`
+	Totals   domain.MacroTargets `json:"totals"`
+}
+
+func (r loggedMealResult) Render() string {
+	return fmt.Sprintf("Logged %s as %s: %.0f kcal, %.1fg protein, %.1fg carbs, %.1fg fat",
+		r.Name, r.MealType, r.Totals.Calories, r.Totals.Protein, r.Totals.Carbohydrates, r.Totals.Fat)
+}
+
+// pendingMealResult reports a meal that was not logged because it needs the user's
+// confirmation first
+type pendingMealResult struct {
+	Status       string             `json:"status"` // always "pending_confirmation"
+	ParsedMealID *uuid.UUID         `json:"parsed_meal_id,omitempty"`
+	Meal         *domain.ParsedMeal `json:"meal"`
+	Reason       string             `json:"reason"`
+	Message      string             `json:"message"`
+}
+
+func (r pendingMealResult) Render() string {
+	return r.Message
+}
+
+func (t *logMealTool) Name() string {
+	return "log_meal"
+}
+
+func (t *logMealTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name: t.Name(),
+		Description: "Log a meal with food items. Give your confidence that the foods and quantities are what the user ate; " +
+			"an unsure log is not saved until the user confirms it.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"food_items": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"food_id":  map[string]string{"type": "string"},
+							"quantity": map[string]string{"type": "number"},
+							"unit":     map[string]string{"type": "string"},
+						},
+						"required": []string{"food_id"},
+					},
+				},
+				"meal_type": map[string]interface{}{
+					"type":        "string",
+					"description": "breakfast, lunch, dinner, snack, or the user's own label such as \"pre-workout\"",
+					"maxLength":   domain.MaxMealTypeLength,
+				},
+				"timestamp": map[string]string{"type": "string"},
+				"confidence": map[string]interface{}{
+					"type":        "number",
+					"minimum":     0,
+					"maximum":     1,
+					"description": "How sure you are, from 0 to 1, that these foods and quantities are what the user ate",
+				},
+			},
+			"required": []string{"food_items", "meal_type", "confidence"},
+		},
+	})
+}
+
+func (t *logMealTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	meal, ambiguous, err := t.parseMeal(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var reason string
+	switch {
+	case ambiguous != "":
+		reason = ambiguous
+	case meal.Confidence < t.minConfidence:
+		reason = fmt.Sprintf("confidence %.2f is below %.2f", meal.Confidence, t.minConfidence)
+	default:
+		logged, err := t.mealService.ConfirmParsedMeal(ctx, userID.String(), meal)
+		if err != nil {
+			return nil, err
+		}
+		return newLoggedMealResult(logged), nil
+	}
+
+	meal.NeedsConfirmation = true
+	result := pendingMealResult{Status: "pending_confirmation", Meal: meal, Reason: reason}
+	if t.pending == nil {
+		result.Message = fmt.Sprintf("Not logged: %s. Check the foods and quantities with the user, then call log_meal again.", reason)
+		return result, nil
+	}
+
+	t.pending.RememberParsedMeal(userID, meal)
+	result.ParsedMealID = &meal.ID
+	result.Message = fmt.Sprintf("Not logged yet: %s. Show the user what would be logged and ask them to confirm; "+
+		"call confirm_meal with parsed_meal_id %s once they do, or log_meal again with their corrections.", reason, meal.ID)
+	return result, nil
+}
+
+// parseMeal builds the meal described by the arguments, with each food's nutrition for its
+// quantity. The reason it is ambiguous is returned when an item has no quantity.
+func (t *logMealTool) parseMeal(ctx context.Context, args map[string]interface{}) (*domain.ParsedMeal, string, error) {
+	rawItems, _ := args["food_items"].([]interface{})
+	if len(rawItems) == 0 {
+		return nil, "", fmt.Errorf("%w: food_items must list at least one food", domain.ErrInvalidInput)
+	}
+	mealTypeArg, _ := args["meal_type"].(string)
+	mealType, err := domain.NormalizeMealType(mealTypeArg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	meal := &domain.ParsedMeal{MealType: mealType, LoggedAt: time.Now()}
+	if timestamp, ok := args["timestamp"].(string); ok && timestamp != "" {
+		loggedAt, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: timestamp must be RFC 3339, e.g. 2025-11-19T12:30:00Z", domain.ErrInvalidInput)
+		}
+		meal.LoggedAt = loggedAt
+	}
+	// A log without a confidence is treated as unsure
+	if confidence, ok := args["confidence"].(float64); ok {
+		meal.Confidence = min(max(confidence, 0), 1)
+	}
+
+	var missingQuantity []string
+	for i, raw := range rawItems {
+		item, _ := raw.(map[string]interface{})
+		foodID, _ := item["food_id"].(string)
+		if _, err := uuid.Parse(foodID); err != nil {
+			return nil, "", fmt.Errorf("%w: food_items[%d] needs the food_id of a food from search_foods", domain.ErrInvalidInput, i)
+		}
+		food, err := t.foodService.GetFood(ctx, foodID)
+		if err != nil {
+			return nil, "", fmt.Errorf("food_items[%d]: %w", i, err)
+		}
+
+		quantity, _ := item["quantity"].(float64)
+		unit, _ := item["unit"].(string)
+		if quantity <= 0 {
+			missingQuantity = append(missingQuantity, food.Name)
+			quantity, unit = 1, "serving"
+		}
+
+		portion := domain.MealFoodItem{Quantity: quantity, Unit: unit}
+		portion.ApplySnapshot(food)
+		meal.FoodItems = append(meal.FoodItems, domain.ParsedFoodItem{
+			FoodID:        &food.ID,
+			FoodName:      food.Name,
+			Quantity:      quantity,
+			Unit:          unit,
+			Confidence:    meal.Confidence,
+			Calories:      portion.Calories,
+			Protein:       portion.Protein,
+			Carbohydrates: portion.Carbohydrates,
+			Fat:           portion.Fat,
+		})
+	}
+	meal.SumTotals()
+
+	if len(missingQuantity) > 0 {
+		return meal, "no quantity given for " + strings.Join(missingQuantity, ", "), nil
+	}
+	return meal, "", nil
+}
+
+// confirmMealTool logs a meal log_meal held for confirmation, once the user agrees
+type confirmMealTool struct {
+	mealService ports.MealService
+	pending     ports.ParsedMealStore
+}
+
+func (t *confirmMealTool) Name() string {
+	return "confirm_meal"
+}
+
+func (t *confirmMealTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Log a meal that log_meal held for confirmation, after the user confirmed it",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"parsed_meal_id": map[string]string{"type": "string"},
+			},
+			"required": []string{"parsed_meal_id"},
+		},
+	})
+}
+
+func (t *confirmMealTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	idArg, _ := args["parsed_meal_id"].(string)
+	parsedMealID, err := uuid.Parse(idArg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsed_meal_id must be the ID log_meal returned", domain.ErrInvalidInput)
+	}
+
+	meal, err := t.pending.TakeParsedMeal(userID, parsedMealID)
+	if err != nil {
+		return nil, err
+	}
+	logged, err := t.mealService.ConfirmParsedMeal(ctx, userID.String(), meal)
+	if err != nil {
+		t.pending.ReturnParsedMeal(parsedMealID)
+		return nil, err
+	}
+	t.pending.ForgetParsedMeal(parsedMealID)
+
+	return newLoggedMealResult(logged), nil
+}
+
+func newLoggedMealResult(meal *domain.Meal) loggedMealResult {
+	return loggedMealResult{
+		Status:   "logged",
+		MealID:   meal.ID,
+		Name:     meal.Name,
+		MealType: meal.MealType,
+		Totals: domain.MacroTargets{
+			Calories:      meal.TotalCalories,
+			Protein:       meal.TotalProtein,
+			Carbohydrates: meal.TotalCarbohydrates,
+			Fat:           meal.TotalFat,
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/ports"
+)
+
+// logWeightTool records a weight measurement
+type logWeightTool struct {
+	metricService ports.MetricService
+}
+
+func (t *logWeightTool) Name() string {
+	return "log_weight"
+}
+
+func (t *logWeightTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Log a weight measurement",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"weight": map[string]interface{}{
+					"type":        "number",
+					"description": "Weight in kg",
+				},
+				"date": map[string]interface{}{
+					"type":        "string",
+					"description": "Date in YYYY-MM-DD format",
+				},
+			},
+			"required": []string{"weight"},
+		},
+	})
+}
+
+// loggedWeightResult confirms a logged weight
+type loggedWeightResult struct {
+	WeightKg float64 `json:"weight_kg"`
+	Date     string  `json:"date"`
+}
+
+func (r loggedWeightResult) Render() string {
+	return fmt.Sprintf("Logged weight: %.1f kg on %s", r.WeightKg, r.Date)
+}
+
+func (t *logWeightTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	weight, ok := args["weight"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("weight parameter required")
+	}
+
+	date := time.Now()
+	if dateStr, ok := args["date"].(string); ok {
+		parsedDate, err := time.Parse("2006-01-02", dateStr)
+		if err == nil {
+			date = parsedDate
+		}
+	}
+
+	_, err := t.metricService.LogMetric(ctx, userID.String(), "weight", weight, "kg", date)
+	if err != nil {
+		return nil, err
+	}
+
+	return loggedWeightResult{WeightKg: weight, Date: date.Format("2006-01-02")}, nil
+}
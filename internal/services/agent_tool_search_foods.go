@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// searchFoodsTool searches the food database
+type searchFoodsTool struct {
+	foodService ports.FoodService
+}
+
+func (t *searchFoodsTool) Name() string {
+	return "search_foods"
+}
+
+func (t *searchFoodsTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Search for foods in the database",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Search query for food name",
+				},
+			},
+			"required": []string{"query"},
+		},
+	})
+}
+
+// foodSearchResult lists the foods matching a query, with nutrition per serving
+type foodSearchResult struct {
+	Query string       `json:"query"`
+	Foods []foodResult `json:"foods"`
+}
+
+type foodResult struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+	ServingSize   float64 `json:"serving_size"`
+	ServingUnit   string  `json:"serving_unit"`
+}
+
+func (r *foodSearchResult) Render() string {
+	result := fmt.Sprintf("Found %d foods matching '%s':\n", len(r.Foods), r.Query)
+	for _, food := range r.Foods {
+		result += fmt.Sprintf("- %s (%.0f cal, %.1fg protein, %.1fg carbs, %.1fg fat per %s)\n",
+			food.Name, food.Calories, food.Protein, food.Carbohydrates, food.Fat, food.ServingUnit)
+	}
+	return result
+}
+
+func (t *searchFoodsTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	query, ok := args["query"].(string)
+	if !ok {
+		return nil, fmt.Errorf("query parameter required")
+	}
+
+	foods, err := t.foodService.SearchFoods(ctx, query, domain.FoodSearchFilter{}, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &foodSearchResult{Query: query, Foods: make([]foodResult, 0, len(foods))}
+	for i, food := range foods {
+		if i >= 10 {
+			break
+		}
+		macros := roundMacros(domain.MacroTargets{
+			Calories:      food.Calories,
+			Protein:       food.Protein,
+			Carbohydrates: food.Carbohydrates,
+			Fat:           food.Fat,
+		})
+		result.Foods = append(result.Foods, foodResult{
+			ID:            food.ID.String(),
+			Name:          food.Name,
+			Calories:      macros.Calories,
+			Protein:       macros.Protein,
+			Carbohydrates: macros.Carbohydrates,
+			Fat:           macros.Fat,
+			ServingSize:   food.ServingSize,
+			ServingUnit:   food.ServingUnit,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// suggestFoodSwapsTool suggests healthier foods of the same category
+type suggestFoodSwapsTool struct {
+	foodService ports.FoodService
+}
+
+func (t *suggestFoodSwapsTool) Name() string {
+	return "suggest_food_swaps"
+}
+
+func (t *suggestFoodSwapsTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Suggest healthier swaps for a food: foods in the same category with more protein or fiber and less sugar or saturated fat per calorie. Use it for requests like \"suggest a healthier version of this\"",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"food": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the food to find swaps for",
+				},
+			},
+			"required": []string{"food"},
+		},
+	})
+}
+
+// foodSwapsResult lists healthier swaps for a food. Density figures are per 100 kcal.
+type foodSwapsResult struct {
+	Food         string                  `json:"food"`
+	Density      *domain.NutrientDensity `json:"density,omitempty"`
+	Alternatives []foodSwap              `json:"alternatives"`
+	Note         string                  `json:"note,omitempty"`
+}
+
+type foodSwap struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Density     domain.NutrientDensity `json:"density"`
+	Calories    float64                `json:"calories"`
+	ServingSize float64                `json:"serving_size"`
+	ServingUnit string                 `json:"serving_unit"`
+	Reasons     []string               `json:"reasons,omitempty"`
+}
+
+func (r *foodSwapsResult) Render() string {
+	result := fmt.Sprintf("Swaps for %s", r.Food)
+	if r.Density != nil {
+		result += fmt.Sprintf(" (score %.1f; per 100 kcal: %.1fg protein, %.1fg fiber, %.1fg sugar)",
+			r.Density.Score, r.Density.Protein, r.Density.Fiber, r.Density.Sugar)
+	}
+	result += ":\n"
+	if len(r.Alternatives) == 0 {
+		return result + r.Note
+	}
+
+	for _, alternative := range r.Alternatives {
+		result += fmt.Sprintf("- %s (score %.1f, %.0f cal per %.0f %s)",
+			alternative.Name, alternative.Density.Score, alternative.Calories, alternative.ServingSize, alternative.ServingUnit)
+		if len(alternative.Reasons) > 0 {
+			result += ": " + strings.Join(alternative.Reasons, ", ")
+		}
+		result += "\n"
+	}
+	return result
+}
+
+// roundDensity rounds a nutrient density to one decimal for tool results
+func roundDensity(d domain.NutrientDensity) domain.NutrientDensity {
+	return domain.NutrientDensity{
+		Protein:      roundTenth(d.Protein),
+		Fiber:        roundTenth(d.Fiber),
+		Sugar:        roundTenth(d.Sugar),
+		SaturatedFat: roundTenth(d.SaturatedFat),
+		Score:        roundTenth(d.Score),
+	}
+}
+
+func (t *suggestFoodSwapsTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	name, ok := args["food"].(string)
+	if !ok || strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("food parameter required")
+	}
+
+	foods, err := t.foodService.SearchFoods(ctx, name, domain.FoodSearchFilter{}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(foods) == 0 {
+		return toolNote{Note: fmt.Sprintf("No food matching '%s' was found in the database.", name)}, nil
+	}
+
+	swaps, err := t.foodService.GetFoodAlternatives(ctx, foods[0].ID.String(), defaultFoodAlternativesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &foodSwapsResult{Food: swaps.Food.Name, Alternatives: make([]foodSwap, 0, len(swaps.Alternatives))}
+	if swaps.Density != nil {
+		density := roundDensity(*swaps.Density)
+		result.Density = &density
+	}
+	if len(swaps.Alternatives) == 0 {
+		result.Note = swaps.Note + ". Do not invent swaps from the database; general advice is fine."
+		return result, nil
+	}
+
+	for _, alternative := range swaps.Alternatives {
+		result.Alternatives = append(result.Alternatives, foodSwap{
+			ID:          alternative.Food.ID.String(),
+			Name:        alternative.Food.Name,
+			Density:     roundDensity(alternative.Density),
+			Calories:    math.Round(alternative.Food.Calories),
+			ServingSize: alternative.Food.ServingSize,
+			ServingUnit: alternative.Food.ServingUnit,
+			Reasons:     alternative.Reasons,
+		})
+	}
+	return result, nil
+}
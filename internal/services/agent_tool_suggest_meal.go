@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// suggestMealTool suggests a meal that fits the user's remaining macros
+type suggestMealTool struct {
+	userRepo              ports.UserRepository
+	summaryService        ports.SummaryService
+	mealSuggestionService ports.MealSuggestionService
+}
+
+func (t *suggestMealTool) Name() string {
+	return "suggest_meal"
+}
+
+func (t *suggestMealTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Suggest a meal of specific foods and quantities from the food database that fits the user's remaining macros for today and their dietary preferences. Omitted targets default to what is left for today.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"calories": map[string]interface{}{
+					"type":        "number",
+					"description": "Calories the meal should provide",
+				},
+				"protein": map[string]interface{}{
+					"type":        "number",
+					"description": "Protein in grams the meal should provide",
+				},
+				"carbs": map[string]interface{}{
+					"type":        "number",
+					"description": "Carbohydrates in grams the meal should provide",
+				},
+				"fat": map[string]interface{}{
+					"type":        "number",
+					"description": "Fat in grams the meal should provide",
+				},
+			},
+		},
+	})
+}
+
+// mealSuggestionResult is a suggested meal: each food with the quantity to eat, and the totals
+type mealSuggestionResult struct {
+	Target domain.MacroTargets `json:"target"`
+	Items  []suggestedItem     `json:"items"`
+	Totals domain.MacroTargets `json:"totals"`
+}
+
+type suggestedItem struct {
+	FoodID   string  `json:"food_id"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	domain.MacroTargets
+}
+
+func (r *mealSuggestionResult) Render() string {
+	result := fmt.Sprintf("Suggested meal for target %s:\n", renderMacros(r.Target))
+	for _, item := range r.Items {
+		result += fmt.Sprintf("- %s (food_id: %s): %.0f %s (%s)\n",
+			item.Name, item.FoodID, item.Quantity, item.Unit, renderMacros(item.MacroTargets))
+	}
+	return result + "Total: " + renderMacros(r.Totals)
+}
+
+func (t *suggestMealTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	today, err := remainingMacros(ctx, t.userRepo, t.summaryService, userID)
+	if err != nil {
+		return nil, err
+	}
+	target := today.Remaining
+
+	if v, ok := args["calories"].(float64); ok {
+		target.Calories = v
+	}
+	if v, ok := args["protein"].(float64); ok {
+		target.Protein = v
+	}
+	if v, ok := args["carbs"].(float64); ok {
+		target.Carbohydrates = v
+	}
+	if v, ok := args["fat"].(float64); ok {
+		target.Fat = v
+	}
+
+	if target.IsZero() {
+		return toolNote{Note: "The user has already reached all of today's targets; no meal is needed to hit them."}, nil
+	}
+
+	suggestion, err := t.mealSuggestionService.SuggestMeal(ctx, userID.String(), target)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(suggestion.Items) == 0 {
+		return toolNote{Note: "No foods in the database fit the target and the user's dietary preferences"}, nil
+	}
+
+	result := &mealSuggestionResult{
+		Target: roundMacros(target),
+		Items:  make([]suggestedItem, 0, len(suggestion.Items)),
+		Totals: roundMacros(suggestion.Totals),
+	}
+	for _, item := range suggestion.Items {
+		result.Items = append(result.Items, suggestedItem{
+			FoodID:   item.Food.ID.String(),
+			Name:     item.Food.Name,
+			Quantity: math.Round(item.Quantity),
+			Unit:     item.Unit,
+			MacroTargets: roundMacros(domain.MacroTargets{
+				Calories:      item.Calories,
+				Protein:       item.Protein,
+				Carbohydrates: item.Carbohydrates,
+				Fat:           item.Fat,
+			}),
+		})
+	}
+
+	return result, nil
+}
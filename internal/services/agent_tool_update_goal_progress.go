@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// updateGoalProgressTool records progress on one of the user's goals, or marks it completed or abandoned
+type updateGoalProgressTool struct {
+	goalService ports.GoalService
+}
+
+func (t *updateGoalProgressTool) Name() string {
+	return "update_goal_progress"
+}
+
+func (t *updateGoalProgressTool) Definition() external.Tool {
+	return functionTool(external.ToolFunction{
+		Name:        t.Name(),
+		Description: "Update progress on one of the user's goals, or mark it completed or abandoned. Identify the goal by goal_id, or by goal_type to pick the active goal of that type.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"goal_id": map[string]interface{}{
+					"type":        "string",
+					"description": "ID of the goal",
+				},
+				"goal_type": map[string]interface{}{
+					"type":        "string",
+					"description": "Type of the active goal to update when goal_id is not known",
+				},
+				"current_value": map[string]interface{}{
+					"type":        "number",
+					"description": "Progress so far, in the goal's unit",
+				},
+				"status": map[string]interface{}{
+					"type": "string",
+					"enum": []string{"active", "completed", "abandoned"},
+				},
+			},
+		},
+	})
+}
+
+func (t *updateGoalProgressTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error) {
+	updates := make(map[string]interface{})
+	if current, ok := args["current_value"].(float64); ok {
+		if current < 0 {
+			return nil, fmt.Errorf("%w: current_value must not be negative", domain.ErrInvalidInput)
+		}
+		updates["current_value"] = current
+	}
+	if status, ok := args["status"].(string); ok && status != "" {
+		if !validGoalStatuses[status] {
+			return nil, fmt.Errorf("%w: status must be one of active, completed, abandoned", domain.ErrInvalidInput)
+		}
+		updates["status"] = status
+	}
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("%w: give current_value or status", domain.ErrInvalidInput)
+	}
+
+	goal, note, err := t.findGoal(ctx, args, userID)
+	if err != nil || goal == nil {
+		return note, err
+	}
+
+	updated, err := t.goalService.UpdateGoal(ctx, goal.ID.String(), updates)
+	if err != nil {
+		return nil, err
+	}
+	return newGoalResult(updated), nil
+}
+
+// findGoal resolves the goal to update among the user's own goals, so a goal ID from
+// another account is never touched. When no goal matches it returns a note instead.
+func (t *updateGoalProgressTool) findGoal(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (*domain.Goal, ToolResult, error) {
+	goals, err := t.goalService.GetGoals(ctx, userID.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if goalID, ok := args["goal_id"].(string); ok && goalID != "" {
+		for _, goal := range goals {
+			if goal.ID.String() == goalID {
+				return goal, nil, nil
+			}
+		}
+		return nil, toolNote{Note: "The user has no goal with that ID. List their goals before updating one."}, nil
+	}
+
+	goalType, _ := args["goal_type"].(string)
+	var active []*domain.Goal
+	for _, goal := range goals {
+		if goal.Status == "active" && (goalType == "" || goal.GoalType == goalType) {
+			active = append(active, goal)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil, toolNote{Note: "The user has no matching active goal. Offer to create one with create_goal."}, nil
+	case 1:
+		return active[0], nil, nil
+	}
+	return nil, toolNote{Note: fmt.Sprintf("The user has %d matching active goals; ask which one they mean and pass its goal_id.", len(active))}, nil
+}
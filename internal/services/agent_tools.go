@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+// Tool is a function the agent can call. Each tool owns its name, schema and
+// implementation, so adding a tool is a single self-contained file.
+type Tool interface {
+	// Name is the function name the model uses to call the tool
+	Name() string
+
+	// Definition is the schema sent to the model; its function name must equal Name()
+	Definition() external.Tool
+
+	// Execute runs the tool with the arguments supplied by the model
+	Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (ToolResult, error)
+}
+
+// ToolResult is the structured result of a tool call. The model receives it as compact
+// JSON, which costs fewer tokens than prose and keeps every field for the model to
+// reason over. Render is a readable form of the same result for logs.
+type ToolResult interface {
+	Render() string
+}
+
+// toolNote is a result with no data, only guidance for the model such as why nothing was found
+type toolNote struct {
+	Note string `json:"note"`
+}
+
+func (n toolNote) Render() string {
+	return n.Note
+}
+
+// toolError is fed back to the model when a tool fails, so it can recover or explain
+type toolError struct {
+	Error   string `json:"error"`
+	Tool    string `json:"tool"`
+	Message string `json:"message"`
+}
+
+func (e toolError) Render() string {
+	return fmt.Sprintf("%s failed: %s", e.Tool, e.Message)
+}
+
+// encodeToolResult returns the compact JSON the model receives for a result
+func encodeToolResult(result ToolResult) string {
+	data, err := json.Marshal(result)
+	if err != nil {
+		data, _ = json.Marshal(toolError{Error: "encoding_failed", Message: err.Error()})
+	}
+	return string(data)
+}
+
+// roundMacros rounds macros to one decimal for tool results; more digits only cost tokens
+func roundMacros(m domain.MacroTargets) domain.MacroTargets {
+	return domain.MacroTargets{
+		Calories:      math.Round(m.Calories),
+		Protein:       roundTenth(m.Protein),
+		Carbohydrates: roundTenth(m.Carbohydrates),
+		Fat:           roundTenth(m.Fat),
+	}
+}
+
+// renderMacros formats macros for rendered tool results
+func renderMacros(m domain.MacroTargets) string {
+	return fmt.Sprintf("%.0f cal, %.1fg protein, %.1fg carbs, %.1fg fat", m.Calories, m.Protein, m.Carbohydrates, m.Fat)
+}
+
+// ToolRegistry holds the agent's tools keyed by name, preserving registration order
+type ToolRegistry struct {
+	tools map[string]Tool
+	names []string
+}
+
+// NewToolRegistry creates a registry containing the given tools
+func NewToolRegistry(tools ...Tool) *ToolRegistry {
+	r := &ToolRegistry{tools: make(map[string]Tool, len(tools))}
+	for _, tool := range tools {
+		r.Register(tool)
+	}
+	return r
+}
+
+// Register adds a tool, replacing any tool already registered under the same name
+func (r *ToolRegistry) Register(tool Tool) {
+	if _, exists := r.tools[tool.Name()]; !exists {
+		r.names = append(r.names, tool.Name())
+	}
+	r.tools[tool.Name()] = tool
+}
+
+// Get returns the tool registered under name
+func (r *ToolRegistry) Get(name string) (Tool, bool) {
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Names returns the registered tool names in registration order
+func (r *ToolRegistry) Names() []string {
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+	return names
+}
+
+// Definitions returns the schemas of all registered tools in registration order
+func (r *ToolRegistry) Definitions() []external.Tool {
+	defs := make([]external.Tool, 0, len(r.names))
+	for _, name := range r.names {
+		defs = append(defs, r.tools[name].Definition())
+	}
+	return defs
+}
+
+// functionTool wraps a function schema in the tool envelope expected by the API
+func functionTool(fn external.ToolFunction) external.Tool {
+	return external.Tool{
+		Type:     "function",
+		Function: fn,
+	}
+}
+
+// remainingMacros compares what the user has eaten today with their targets. Today is the
+// user's local day, and the calorie target includes calories earned back by exercise when
+// the user's target is dynamic, so every tool reports the same remaining amounts.
+func remainingMacros(ctx context.Context, userRepo ports.UserRepository, summaryService ports.SummaryService, userID uuid.UUID) (*remainingMacrosResult, error) {
+	user, err := userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	loc := user.Location()
+
+	// The summary's day runs midnight to midnight in the location of the date it is given
+	today := time.Now().In(loc)
+	summary, err := summaryService.GetDailySummary(ctx, userID.String(), today)
+	if err != nil {
+		return nil, err
+	}
+	target, err := summaryService.GetNutritionTargets(ctx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	targets := target.Macros()
+	if summary.CalorieTarget != nil {
+		targets.Calories = *summary.CalorieTarget
+	}
+	consumed := domain.MacroTargets{
+		Calories:      summary.TotalCalories,
+		Protein:       summary.TotalProtein,
+		Carbohydrates: summary.TotalCarbohydrates,
+		Fat:           summary.TotalFat,
+	}
+
+	return &remainingMacrosResult{
+		Date:     today.Format("2006-01-02"),
+		Timezone: loc.String(),
+		Consumed: roundMacros(consumed),
+		Targets:  roundMacros(targets),
+		Remaining: roundMacros(domain.MacroTargets{
+			Calories:      math.Max(0, targets.Calories-consumed.Calories),
+			Protein:       math.Max(0, targets.Protein-consumed.Protein),
+			Carbohydrates: math.Max(0, targets.Carbohydrates-consumed.Carbohydrates),
+			Fat:           math.Max(0, targets.Fat-consumed.Fat),
+		}),
+	}, nil
+}
+
+// nutrientLimitLabels names the limited nutrients in progress lines
+var nutrientLimitLabels = map[string]string{
+	domain.GoalTypeSodium:       "Sodium",
+	domain.GoalTypeSaturatedFat: "Saturated fat",
+}
+
+// macroProgress lists a day's macro totals against the user's targets, then its sodium and
+// saturated fat against any limit goals, one per line
+func macroProgress(summary *domain.DailySummary, targets domain.MacroTargets) string {
+	progress := fmt.Sprintf("- Calories: %.0f / %.0f\n", summary.TotalCalories, targets.Calories)
+	progress += fmt.Sprintf("- Protein: %.1fg / %.1fg\n", summary.TotalProtein, targets.Protein)
+	progress += fmt.Sprintf("- Carbs: %.1fg / %.1fg\n", summary.TotalCarbohydrates, targets.Carbohydrates)
+	progress += fmt.Sprintf("- Fat: %.1fg / %.1fg\n", summary.TotalFat, targets.Fat)
+	for _, limit := range summary.NutrientLimits {
+		progress += fmt.Sprintf("- %s: %.1f%s / %.1f%s limit\n",
+			nutrientLimitLabels[limit.Nutrient], limit.Consumed, limit.Unit, limit.Limit, limit.Unit)
+	}
+	return progress
+}
+
+// mealTargetProgress lists each meal type's share of the day's target against what has
+// been logged in it, so advice can name the meal to adjust
+func mealTargetProgress(targets []domain.MealTypeTarget) string {
+	var progress string
+	for _, meal := range targets {
+		if meal.Percent == 0 && meal.MealCount == 0 {
+			continue
+		}
+		progress += fmt.Sprintf("- %s (%.0f%%): %.0f / %.0f kcal, protein %.1fg / %.1fg, carbs %.1fg / %.1fg, fat %.1fg / %.1fg\n",
+			meal.Group, meal.Percent,
+			meal.Logged.Calories, meal.Target.Calories,
+			meal.Logged.Protein, meal.Target.Protein,
+			meal.Logged.Carbohydrates, meal.Target.Carbohydrates,
+			meal.Logged.Fat, meal.Target.Fat)
+	}
+	return progress
+}
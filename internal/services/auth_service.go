@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,21 +14,45 @@ import (
 	"github.com/google/uuid"
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/auth"
+	"fitness-tracker/internal/pkg/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// passwordResetTokenTTL is how long a password reset token stays valid
+	passwordResetTokenTTL = time.Hour
+
+	// emailVerificationTokenTTL is how long an email verification token stays valid
+	emailVerificationTokenTTL = 24 * time.Hour
+
+	// userTokenBytes is the amount of randomness in reset/verification tokens
+	userTokenBytes = 32
+)
+
 type authService struct {
-	userRepo  ports.UserRepository
-	jwtSecret string
-	jwtExpiry time.Duration
+	userRepo    ports.UserRepository
+	tokenRepo   ports.UserTokenRepository
+	emailSender ports.EmailSender
+	keys        *auth.KeySet
+	jwtExpiry   time.Duration
 }
 
-// NewAuthService creates a new authentication service
-func NewAuthService(userRepo ports.UserRepository, jwtSecret string, jwtExpiry time.Duration) ports.AuthService {
+// NewAuthService creates a new authentication service.
+// Tokens are signed with the key set's current key and verified against any active key.
+func NewAuthService(
+	userRepo ports.UserRepository,
+	tokenRepo ports.UserTokenRepository,
+	emailSender ports.EmailSender,
+	keys *auth.KeySet,
+	jwtExpiry time.Duration,
+) ports.AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		jwtExpiry: jwtExpiry,
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		emailSender: emailSender,
+		keys:        keys,
+		jwtExpiry:   jwtExpiry,
 	}
 }
 
@@ -36,7 +64,7 @@ func (s *authService) Register(ctx context.Context, email, password, name string
 
 	// Check if user already exists
 	existing, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil && err != domain.ErrNotFound {
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
 		return nil, "", fmt.Errorf("failed to check existing user: %w", err)
 	}
 	if existing != nil {
@@ -71,6 +99,9 @@ func (s *authService) Register(ctx context.Context, email, password, name string
 		return nil, "", fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// A failed verification email shouldn't fail registration; the user can request a new one
+	_ = s.sendVerificationEmail(ctx, user)
+
 	// Generate JWT token
 	token, err := s.GenerateJWT(userID.String())
 	if err != nil {
@@ -91,7 +122,7 @@ func (s *authService) Login(ctx context.Context, email, password string) (*domai
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, "", domain.ErrInvalidCredentials
 		}
 		return nil, "", fmt.Errorf("failed to get user: %w", err)
@@ -128,7 +159,7 @@ func (s *authService) ValidateToken(ctx context.Context, tokenString string) (st
 	// Verify user exists
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return "", domain.ErrUnauthorized
 		}
 		return "", fmt.Errorf("failed to get user: %w", err)
@@ -165,24 +196,11 @@ func (s *authService) GenerateJWT(userID string) (string, error) {
 		"iat":     time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
-	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
-	}
-
-	return tokenString, nil
+	return s.keys.Sign(claims)
 }
 
 func (s *authService) ParseJWT(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
-
+	token, err := s.keys.Parse(tokenString)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse token: %w", err)
 	}
@@ -203,3 +221,202 @@ func (s *authService) ParseJWT(tokenString string) (string, error) {
 
 	return userID, nil
 }
+
+// RequestPasswordReset issues a single-use reset token and emails it to the user.
+// It returns nil for unknown emails so callers can't probe which accounts exist.
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	if email == "" {
+		return domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Only the most recent reset link should work
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, domain.TokenPurposePasswordReset); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	rawToken, err := s.issueUserToken(ctx, user.ID, domain.TokenPurposePasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Use this token to reset your password: %s\n\nIt expires in %d minutes. If you didn't request a reset, you can ignore this email.",
+		rawToken, int(passwordResetTokenTTL.Minutes()),
+	)
+	if err := s.emailSender.SendEmail(ctx, user.Email, "Reset your password", body); err != nil {
+		return fmt.Errorf("failed to send reset email: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and consumes the token
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if token == "" || newPassword == "" {
+		return domain.ErrInvalidInput
+	}
+
+	if result := utils.ValidatePassword(newPassword); !result.Valid {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidInput, strings.Join(result.Errors, "; "))
+	}
+
+	userToken, err := s.consumeUserToken(ctx, token, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	hashedPassword, err := s.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.PasswordHash = hashedPassword
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	return nil
+}
+
+// issueUserToken stores the hash of a new random token and returns the raw token
+func (s *authService) issueUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	buf := make([]byte, userTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	rawToken := hex.EncodeToString(buf)
+
+	userToken := &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, userToken); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return rawToken, nil
+}
+
+// consumeUserToken validates a raw token and marks it used so it can't be replayed
+func (s *authService) consumeUserToken(ctx context.Context, rawToken, purpose string) (*domain.UserToken, error) {
+	userToken, err := s.tokenRepo.GetByHash(ctx, purpose, hashUserToken(rawToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	now := time.Now()
+	if !userToken.IsUsable(now) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// MarkUsed only succeeds once, which guards against concurrent reuse
+	if err := s.tokenRepo.MarkUsed(ctx, userToken.ID, now); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	return userToken, nil
+}
+
+// hashUserToken returns the hex SHA-256 of a raw token for storage and lookup
+func hashUserToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// SendVerificationEmail issues a new email verification token, invalidating any earlier one
+func (s *authService) SendVerificationEmail(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.EmailVerified {
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail marks the token owner's email as verified and consumes the token
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return domain.ErrInvalidInput
+	}
+
+	userToken, err := s.consumeUserToken(ctx, token, domain.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return nil
+}
+
+// sendVerificationEmail invalidates earlier verification tokens and emails a fresh one
+func (s *authService) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, domain.TokenPurposeEmailVerification); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	rawToken, err := s.issueUserToken(ctx, user.ID, domain.TokenPurposeEmailVerification, emailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Verify your email by visiting /api/v1/auth/verify?token=%s\n\nThe link expires in %d hours.",
+		rawToken, int(emailVerificationTokenTTL.Hours()),
+	)
+	if err := s.emailSender.SendEmail(ctx, user.Email, "Verify your email", body); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
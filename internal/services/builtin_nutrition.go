@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/utils"
+)
+
+// builtinNutrition is per-100g nutrition of common foods, rounded from USDA
+// FoodData Central. Grains and legumes are cooked weights.
+var builtinNutrition = map[string]domain.NutritionEstimate{
+	"apple":          {CaloriesPer100g: 52, ProteinPer100g: 0.3, CarbsPer100g: 14, FatPer100g: 0.2, FiberPer100g: 2.4},
+	"banana":         {CaloriesPer100g: 89, ProteinPer100g: 1.1, CarbsPer100g: 23, FatPer100g: 0.3, FiberPer100g: 2.6},
+	"orange":         {CaloriesPer100g: 47, ProteinPer100g: 0.9, CarbsPer100g: 12, FatPer100g: 0.1, FiberPer100g: 2.4},
+	"avocado":        {CaloriesPer100g: 160, ProteinPer100g: 2, CarbsPer100g: 8.5, FatPer100g: 14.7, FiberPer100g: 6.7},
+	"egg":            {CaloriesPer100g: 143, ProteinPer100g: 12.6, CarbsPer100g: 0.7, FatPer100g: 9.5},
+	"chicken breast": {CaloriesPer100g: 165, ProteinPer100g: 31, FatPer100g: 3.6},
+	"ground beef":    {CaloriesPer100g: 250, ProteinPer100g: 26, FatPer100g: 15},
+	"salmon":         {CaloriesPer100g: 208, ProteinPer100g: 20, FatPer100g: 13},
+	"tuna":           {CaloriesPer100g: 132, ProteinPer100g: 28, FatPer100g: 1.3},
+	"tofu":           {CaloriesPer100g: 76, ProteinPer100g: 8, CarbsPer100g: 1.9, FatPer100g: 4.8, FiberPer100g: 0.3},
+	"white rice":     {CaloriesPer100g: 130, ProteinPer100g: 2.7, CarbsPer100g: 28, FatPer100g: 0.3, FiberPer100g: 0.4},
+	"brown rice":     {CaloriesPer100g: 112, ProteinPer100g: 2.3, CarbsPer100g: 24, FatPer100g: 0.8, FiberPer100g: 1.8},
+	"pasta":          {CaloriesPer100g: 158, ProteinPer100g: 5.8, CarbsPer100g: 31, FatPer100g: 0.9, FiberPer100g: 1.8},
+	"bread":          {CaloriesPer100g: 265, ProteinPer100g: 9, CarbsPer100g: 49, FatPer100g: 3.2, FiberPer100g: 2.7},
+	"oats":           {CaloriesPer100g: 389, ProteinPer100g: 16.9, CarbsPer100g: 66, FatPer100g: 6.9, FiberPer100g: 10.6},
+	"lentils":        {CaloriesPer100g: 116, ProteinPer100g: 9, CarbsPer100g: 20, FatPer100g: 0.4, FiberPer100g: 7.9},
+	"black beans":    {CaloriesPer100g: 132, ProteinPer100g: 8.9, CarbsPer100g: 24, FatPer100g: 0.5, FiberPer100g: 8.7},
+	"potato":         {CaloriesPer100g: 77, ProteinPer100g: 2, CarbsPer100g: 17, FatPer100g: 0.1, FiberPer100g: 2.2},
+	"sweet potato":   {CaloriesPer100g: 86, ProteinPer100g: 1.6, CarbsPer100g: 20, FatPer100g: 0.1, FiberPer100g: 3},
+	"broccoli":       {CaloriesPer100g: 34, ProteinPer100g: 2.8, CarbsPer100g: 7, FatPer100g: 0.4, FiberPer100g: 2.6},
+	"spinach":        {CaloriesPer100g: 23, ProteinPer100g: 2.9, CarbsPer100g: 3.6, FatPer100g: 0.4, FiberPer100g: 2.2},
+	"carrot":         {CaloriesPer100g: 41, ProteinPer100g: 0.9, CarbsPer100g: 10, FatPer100g: 0.2, FiberPer100g: 2.8},
+	"tomato":         {CaloriesPer100g: 18, ProteinPer100g: 0.9, CarbsPer100g: 3.9, FatPer100g: 0.2, FiberPer100g: 1.2},
+	"milk":           {CaloriesPer100g: 61, ProteinPer100g: 3.2, CarbsPer100g: 4.8, FatPer100g: 3.3},
+	"greek yogurt":   {CaloriesPer100g: 59, ProteinPer100g: 10, CarbsPer100g: 3.6, FatPer100g: 0.4},
+	"cheddar cheese": {CaloriesPer100g: 403, ProteinPer100g: 25, CarbsPer100g: 1.3, FatPer100g: 33},
+	"butter":         {CaloriesPer100g: 717, ProteinPer100g: 0.9, CarbsPer100g: 0.1, FatPer100g: 81},
+	"olive oil":      {CaloriesPer100g: 884, FatPer100g: 100},
+	"almonds":        {CaloriesPer100g: 579, ProteinPer100g: 21, CarbsPer100g: 22, FatPer100g: 50, FiberPer100g: 12.5},
+	"peanut butter":  {CaloriesPer100g: 588, ProteinPer100g: 25, CarbsPer100g: 20, FatPer100g: 50, FiberPer100g: 6},
+}
+
+// lookupBuiltinNutrition returns the built-in food whose name is closest to name,
+// if it is close enough to be the same food
+func lookupBuiltinNutrition(name string) (string, domain.NutritionEstimate, bool) {
+	var bestName string
+	bestScore := 0.0
+	for candidate := range builtinNutrition {
+		score := utils.NameSimilarity(name, candidate)
+		// Map order is random; break ties by name so lookups are stable
+		if score > bestScore || (score == bestScore && candidate < bestName) {
+			bestName, bestScore = candidate, score
+		}
+	}
+	if bestScore < aiFoodDuplicateThreshold {
+		return "", domain.NutritionEstimate{}, false
+	}
+	return bestName, builtinNutrition[bestName], true
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// maxDigestTipLength caps an AI tip; longer replies fall back to the template tip
+const maxDigestTipLength = 280
+
+// cachedDigestTip is the tip last generated for a user, valid while the day's
+// data keeps the same fingerprint
+type cachedDigestTip struct {
+	fingerprint string
+	tip         string
+	source      string
+	generatedAt time.Time
+}
+
+type coachService struct {
+	userRepo       ports.UserRepository
+	summaryService ports.SummaryService
+	goalService    ports.GoalService
+
+	// Optional; without a client every tip comes from the template
+	openRouterClient *external.OpenRouterClient
+	model            string
+	moderator        ports.ContentModerator // optional; a flagged tip falls back to the template
+
+	mu   sync.Mutex
+	tips map[uuid.UUID]cachedDigestTip
+}
+
+// NewCoachService creates a coach service. When openRouterClient is nil the
+// digest tip is always templated and no LLM call is made; when moderator is nil
+// AI tips are not screened.
+func NewCoachService(
+	userRepo ports.UserRepository,
+	summaryService ports.SummaryService,
+	goalService ports.GoalService,
+	openRouterClient *external.OpenRouterClient,
+	model string,
+	moderator ports.ContentModerator,
+) ports.CoachService {
+	return &coachService{
+		userRepo:         userRepo,
+		summaryService:   summaryService,
+		goalService:      goalService,
+		openRouterClient: openRouterClient,
+		model:            model,
+		moderator:        moderator,
+		tips:             make(map[uuid.UUID]cachedDigestTip),
+	}
+}
+
+// GetDigest composes today's digest from the summary and goal services. The
+// figures are always current; the tip is generated once per day and again only
+// when the data changes materially, so most requests make no LLM call.
+func (s *coachService) GetDigest(ctx context.Context, userID string) (*domain.CoachDigest, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	loc := user.Location()
+	today := time.Now().In(loc)
+
+	summary, err := s.summaryService.GetDailySummary(ctx, userID, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summary: %w", err)
+	}
+
+	active := "active"
+	goals, err := s.goalService.GetGoals(ctx, userID, &active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	target, err := s.summaryService.GetNutritionTargets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrition targets: %w", err)
+	}
+
+	digest := &domain.CoachDigest{
+		Date:    today.Format("2006-01-02"),
+		Targets: target.Macros(),
+		Consumed: domain.MacroTargets{
+			Calories:      summary.TotalCalories,
+			Protein:       summary.TotalProtein,
+			Carbohydrates: summary.TotalCarbohydrates,
+			Fat:           summary.TotalFat,
+		},
+		WorkedOut:       summary.TotalExerciseMinutes > 0 || summary.TotalCaloriesBurned > 0,
+		ExerciseMinutes: summary.TotalExerciseMinutes,
+		Goals:           make([]domain.DigestGoal, 0, len(goals)),
+	}
+	// In dynamic mode today's exercise raises the calorie target
+	if summary.CalorieTarget != nil {
+		digest.Targets.Calories = *summary.CalorieTarget
+	}
+
+	for _, goal := range goals {
+		digestGoal := domain.DigestGoal{
+			ID:          goal.ID,
+			GoalType:    goal.GoalType,
+			Description: goal.Description,
+		}
+		if goal.Summary != nil {
+			digestGoal.ProgressPercent = goal.Summary.ProgressPercent
+			digestGoal.OnTrack = goal.Summary.OnTrack
+		}
+		digest.Goals = append(digest.Goals, digestGoal)
+	}
+
+	digest.Remaining = domain.MacroTargets{
+		Calories:      math.Max(0, digest.Targets.Calories-digest.Consumed.Calories),
+		Protein:       math.Max(0, digest.Targets.Protein-digest.Consumed.Protein),
+		Carbohydrates: math.Max(0, digest.Targets.Carbohydrates-digest.Consumed.Carbohydrates),
+		Fat:           math.Max(0, digest.Targets.Fat-digest.Consumed.Fat),
+	}
+
+	s.applyTip(ctx, userUUID, digest)
+	return digest, nil
+}
+
+// applyTip reuses the cached tip when the digest's fingerprint is unchanged,
+// otherwise writes a new one
+func (s *coachService) applyTip(ctx context.Context, userID uuid.UUID, digest *domain.CoachDigest) {
+	fingerprint := digest.Fingerprint()
+
+	s.mu.Lock()
+	cached, ok := s.tips[userID]
+	s.mu.Unlock()
+
+	if !ok || cached.fingerprint != fingerprint {
+		cached = cachedDigestTip{
+			fingerprint: fingerprint,
+			tip:         digest.TemplateTip(),
+			source:      domain.DigestTipSourceTemplate,
+			generatedAt: time.Now(),
+		}
+		if tip, err := s.generateTip(ctx, userID, digest); err != nil {
+			requestid.Logf(ctx, "[Coach] Falling back to template tip for user %s: %v", userID, err)
+		} else if tip != "" {
+			cached.tip = tip
+			cached.source = domain.DigestTipSourceAI
+		}
+
+		// One entry per user: a new day's fingerprint replaces yesterday's
+		s.mu.Lock()
+		s.tips[userID] = cached
+		s.mu.Unlock()
+	}
+
+	digest.Tip = cached.tip
+	digest.TipSource = cached.source
+	digest.TipGeneratedAt = cached.generatedAt
+}
+
+// generateTip asks the model to phrase one actionable tip from the digest's
+// data. It returns an empty tip without error when AI is disabled.
+func (s *coachService) generateTip(ctx context.Context, userID uuid.UUID, digest *domain.CoachDigest) (string, error) {
+	if s.openRouterClient == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(digest)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode digest: %w", err)
+	}
+
+	systemPrompt := `You are a supportive fitness and nutrition coach writing the tip on a home-screen card.
+You get today's data as JSON: targets, consumed and remaining calories and macros (grams), whether the user worked out, and their active goals.
+Reply with exactly one short, specific, actionable tip (at most two sentences, no greeting, no markdown) based on the most important thing in the data.`
+
+	messages := []external.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: string(data)},
+	}
+
+	ctx = external.WithAuditContext(ctx, external.AuditOperationCoachDigest, userID)
+	resp, err := s.openRouterClient.Chat(ctx, messages, s.model)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate tip: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from AI")
+	}
+
+	tip := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if tip == "" || len(tip) > maxDigestTipLength {
+		return "", fmt.Errorf("unusable tip of %d characters", len(tip))
+	}
+	if err := moderateOutput(ctx, s.moderator, tip); err != nil {
+		return "", err
+	}
+	return tip, nil
+}
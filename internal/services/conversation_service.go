@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type conversationService struct {
+	conversationRepo ports.ConversationRepository
+}
+
+// NewConversationService creates a new conversation service
+func NewConversationService(conversationRepo ports.ConversationRepository) ports.ConversationService {
+	return &conversationService{conversationRepo: conversationRepo}
+}
+
+// ListConversations returns previews of the user's conversations, most recently active first
+func (s *conversationService) ListConversations(ctx context.Context, userID string, limit, offset int) ([]domain.ConversationPreview, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	conversations, err := s.conversationRepo.ListByUser(ctx, userUUID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(conversations))
+	for i, conversation := range conversations {
+		ids[i] = conversation.ID
+	}
+	activity, err := s.conversationRepo.GetActivity(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation activity: %w", err)
+	}
+
+	previews := make([]domain.ConversationPreview, len(conversations))
+	for i, conversation := range conversations {
+		previews[i] = domain.NewConversationPreview(conversation, activity[conversation.ID])
+	}
+	return previews, nil
+}
+
+// ListMessages returns a page of a conversation's messages. A conversation that belongs to
+// another user or was deleted is reported as not found. The caller bounds limit.
+func (s *conversationService) ListMessages(ctx context.Context, userID, conversationID, cursor string, limit int) (*domain.MessagePage, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	conversationUUID, err := uuid.Parse(conversationID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	after, err := domain.DecodePageCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", domain.ErrInvalidInput)
+	}
+
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	if conversation.UserID != userUUID || conversation.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	messages, err := s.conversationRepo.GetMessagesAfter(ctx, conversationUUID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	page := &domain.MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = domain.NewPageCursor(last.CreatedAt, last.ID).Encode()
+	}
+	return page, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+type demoService struct {
+	demoRepo       ports.DemoRepository
+	userRepo       ports.UserRepository
+	summaryService ports.SummaryService
+}
+
+// NewDemoService creates a new demo service
+func NewDemoService(
+	demoRepo ports.DemoRepository,
+	userRepo ports.UserRepository,
+	summaryService ports.SummaryService,
+) ports.DemoService {
+	return &demoService{
+		demoRepo:       demoRepo,
+		userRepo:       userRepo,
+		summaryService: summaryService,
+	}
+}
+
+// SeedDemoData adds a few days of sample meals, activities, workouts and weigh-ins ending
+// today in the user's timezone. An account is seeded once; clear the seed to seed it again.
+func (s *demoService) SeedDemoData(ctx context.Context, userID string) (*domain.DemoSeedResult, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	seeded, err := s.demoRepo.HasDemoData(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check demo data: %w", err)
+	}
+	if seeded {
+		return nil, fmt.Errorf("%w: demo data is already seeded; clear it first", domain.ErrConflict)
+	}
+
+	loc := user.Location()
+	weight := 0.0
+	if user.WeightKg != nil {
+		weight = *user.WeightKg
+	}
+
+	data := domain.BuildDemoData(id, time.Now(), loc, weight)
+	if err := s.demoRepo.CreateDemoData(ctx, data); err != nil {
+		return nil, fmt.Errorf("failed to seed demo data: %w", err)
+	}
+	s.refreshDailySummaries(ctx, id, loc, data)
+
+	requestid.Logf(ctx, "[Demo] Seeded demo data for user %s", id)
+	return data.Counts(), nil
+}
+
+// ClearDemoData permanently deletes the sample records, leaving the user's own data. Clearing
+// an account without sample data deletes nothing.
+func (s *demoService) ClearDemoData(ctx context.Context, userID string) (*domain.DemoSeedResult, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	loc := user.Location()
+
+	deleted, err := s.demoRepo.DeleteDemoData(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear demo data: %w", err)
+	}
+	s.refreshDailySummaries(ctx, id, loc, deleted)
+
+	requestid.Logf(ctx, "[Demo] Cleared demo data for user %s", id)
+	return deleted.Counts(), nil
+}
+
+// refreshDailySummaries recomputes the stored summary of every day the sample data falls on,
+// so seeded days show up in summary ranges and cleared days lose the sample totals. A failed
+// refresh is logged: the data itself is already saved.
+func (s *demoService) refreshDailySummaries(ctx context.Context, userID uuid.UUID, loc *time.Location, data *domain.DemoData) {
+	var times []time.Time
+	for _, meal := range data.Meals {
+		times = append(times, meal.ConsumedAt)
+	}
+	for _, activity := range data.Activities {
+		times = append(times, activity.StartTime)
+	}
+	for _, workout := range data.Workouts {
+		times = append(times, workout.StartTime)
+	}
+	for _, metric := range data.Metrics {
+		times = append(times, metric.MeasuredAt)
+	}
+
+	refreshed := make(map[string]bool)
+	for _, t := range times {
+		day := t.In(loc).Format("2006-01-02")
+		if refreshed[day] {
+			continue
+		}
+		refreshed[day] = true
+		if _, err := s.summaryService.RefreshDailySummary(ctx, userID.String(), t); err != nil {
+			requestid.Logf(ctx, "[Demo] Failed to refresh daily summary of %s for user %s: %v", day, userID, err)
+		}
+	}
+}
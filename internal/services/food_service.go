@@ -2,26 +2,56 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// recentFoodsWindow limits recent foods to what the user logged lately
+	recentFoodsWindow = 90 * 24 * time.Hour
+
+	defaultRecentFoodsLimit = 20
+	maxRecentFoodsLimit     = 50
+
+	defaultFoodHistoryLimit = 20
+	maxFoodHistoryLimit     = 100
+
+	defaultStarredFoodsLimit = 50
+	maxStarredFoodsLimit     = 100
+
+	defaultFoodAlternativesLimit = 5
+	maxFoodAlternativesLimit     = 20
+
+	// foodAlternativeCandidates bounds how many foods of the category are scored
+	foodAlternativeCandidates = 500
+)
+
 type foodService struct {
-	foodRepo ports.FoodRepository
+	foodRepo     ports.FoodRepository
+	mealRepo     ports.MealRepository
+	revisionRepo ports.FoodRevisionRepository
+	starRepo     ports.FoodStarRepository
 }
 
 // NewFoodService creates a new food service
-func NewFoodService(foodRepo ports.FoodRepository) ports.FoodService {
+func NewFoodService(foodRepo ports.FoodRepository, mealRepo ports.MealRepository, revisionRepo ports.FoodRevisionRepository, starRepo ports.FoodStarRepository) ports.FoodService {
 	return &foodService{
-		foodRepo: foodRepo,
+		foodRepo:     foodRepo,
+		mealRepo:     mealRepo,
+		revisionRepo: revisionRepo,
+		starRepo:     starRepo,
 	}
 }
 
-func (s *foodService) SearchFoods(ctx context.Context, query string, visibility *string, limit int) ([]*domain.Food, error) {
+func (s *foodService) SearchFoods(ctx context.Context, query string, filter domain.FoodSearchFilter, limit int) ([]*domain.Food, error) {
 	if limit <= 0 {
 		limit = 20 // default limit
 	}
@@ -29,11 +59,24 @@ func (s *foodService) SearchFoods(ctx context.Context, query string, visibility
 		limit = 100 // max limit
 	}
 
-	foods, err := s.foodRepo.Search(ctx, query, visibility, limit)
+	switch filter.Sort {
+	case "":
+		filter.Sort = domain.FoodSortRelevance
+	case domain.FoodSortRelevance, domain.FoodSortProteinDensity, domain.FoodSortCalories:
+	default:
+		return nil, fmt.Errorf("%w: sort must be %s, %s or %s", domain.ErrInvalidInput,
+			domain.FoodSortRelevance, domain.FoodSortProteinDensity, domain.FoodSortCalories)
+	}
+
+	foods, err := s.foodRepo.Search(ctx, query, filter, limit, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search foods: %w", err)
 	}
 
+	for _, food := range foods {
+		food.ProteinDensity = food.CalculateProteinDensity()
+	}
+
 	return foods, nil
 }
 
@@ -44,7 +87,7 @@ func (s *foodService) GetFood(ctx context.Context, foodID string) (*domain.Food,
 
 	food, err := s.foodRepo.GetByID(ctx, foodID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get food: %w", err)
@@ -85,15 +128,26 @@ func (s *foodService) CreateFood(ctx context.Context, food *domain.Food) (*domai
 	return food, nil
 }
 
-func (s *foodService) UpdateFood(ctx context.Context, foodID string, updates map[string]interface{}) (*domain.Food, error) {
+// UpdateFood applies the updates and records the changed fields in the food's history.
+// Meals already logged keep the nutrition stored on their items.
+func (s *foodService) UpdateFood(ctx context.Context, userID, foodID string, updates map[string]interface{}) (*domain.Food, error) {
 	if foodID == "" {
 		return nil, domain.ErrInvalidInput
 	}
 
+	var editedBy *uuid.UUID
+	if userID != "" {
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		editedBy = &userUUID
+	}
+
 	// Verify food exists
 	existing, err := s.foodRepo.GetByID(ctx, foodID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get food: %w", err)
@@ -111,8 +165,101 @@ func (s *foodService) UpdateFood(ctx context.Context, foodID string, updates map
 		return nil, fmt.Errorf("failed to update food: %w", err)
 	}
 
-	// Return updated food
-	return s.foodRepo.GetByID(ctx, existing.ID)
+	updated, err := s.foodRepo.GetByID(ctx, existing.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated food: %w", err)
+	}
+
+	revision, err := domain.NewFoodRevision(existing, updated, editedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff food: %w", err)
+	}
+	if revision != nil {
+		if err := s.revisionRepo.Create(ctx, revision); err != nil {
+			return nil, fmt.Errorf("failed to record food revision: %w", err)
+		}
+	}
+
+	return updated, nil
+}
+
+// GetFoodHistory returns the food's revisions, newest first
+func (s *foodService) GetFoodHistory(ctx context.Context, foodID string, limit, offset int) ([]*domain.FoodRevision, error) {
+	foodUUID, err := uuid.Parse(foodID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", domain.ErrInvalidInput)
+	}
+
+	if limit <= 0 {
+		limit = defaultFoodHistoryLimit
+	}
+	if limit > maxFoodHistoryLimit {
+		limit = maxFoodHistoryLimit
+	}
+
+	if _, err := s.foodRepo.GetByID(ctx, foodUUID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get food: %w", err)
+	}
+
+	revisions, err := s.revisionRepo.ListByFood(ctx, foodUUID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get food history: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetFoodAlternatives suggests foods of the same category with a better nutrient density
+// than the food, for healthier swaps
+func (s *foodService) GetFoodAlternatives(ctx context.Context, foodID string, limit int) (*domain.FoodAlternatives, error) {
+	foodUUID, err := uuid.Parse(foodID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if limit <= 0 {
+		limit = defaultFoodAlternativesLimit
+	}
+	if limit > maxFoodAlternativesLimit {
+		limit = maxFoodAlternativesLimit
+	}
+
+	food, err := s.foodRepo.GetByID(ctx, foodUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get food: %w", err)
+	}
+
+	result := &domain.FoodAlternatives{Food: food, Alternatives: []domain.FoodAlternative{}}
+	density, ok := domain.FoodNutrientDensity(food)
+	if !ok {
+		result.Note = "The food has no calories to compare nutrients against"
+		return result, nil
+	}
+	result.Density = &density
+	if food.Category == nil || strings.TrimSpace(*food.Category) == "" {
+		result.Note = "The food has no category, so there is nothing to compare it with"
+		return result, nil
+	}
+
+	candidates, err := s.foodRepo.ListByCategory(ctx, *food.Category, foodAlternativeCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foods in category: %w", err)
+	}
+
+	result.Alternatives = domain.RankFoodAlternatives(food, candidates, limit)
+	if len(result.Alternatives) == 0 {
+		result.Note = "No food in the category scores better"
+	}
+	return result, nil
 }
 
 func (s *foodService) CreateAIGeneratedFood(ctx context.Context, name string, nutritionData map[string]interface{}) (*domain.Food, error) {
@@ -157,3 +304,138 @@ func (s *foodService) CreateAIGeneratedFood(ctx context.Context, name string, nu
 
 	return food, nil
 }
+
+// GetRecentFoods returns the user's recently logged foods with their typical serving, for quick re-logging
+func (s *foodService) GetRecentFoods(ctx context.Context, userID string, limit int) ([]*domain.RecentFood, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if limit <= 0 {
+		limit = defaultRecentFoodsLimit
+	}
+	if limit > maxRecentFoodsLimit {
+		limit = maxRecentFoodsLimit
+	}
+
+	since := time.Now().Add(-recentFoodsWindow)
+	foods, err := s.mealRepo.ListRecentFoods(ctx, userUUID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent foods: %w", err)
+	}
+
+	recentFoods := make([]*domain.Food, len(foods))
+	for i, recent := range foods {
+		recentFoods[i] = &recent.Food
+	}
+	s.MarkStarred(ctx, userID, recentFoods)
+
+	return foods, nil
+}
+
+// StarFood stars a food for the user; starring it again is a no-op
+func (s *foodService) StarFood(ctx context.Context, userID, foodID string) error {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+	foodUUID, err := uuid.Parse(foodID)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+
+	if _, err := s.foodRepo.GetByID(ctx, foodUUID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to get food: %w", err)
+	}
+
+	if err := s.starRepo.Star(ctx, &domain.FoodStar{UserID: userUUID, FoodID: foodUUID}); err != nil {
+		return fmt.Errorf("failed to star food: %w", err)
+	}
+
+	return nil
+}
+
+// UnstarFood removes the user's star from a food; a food that is not starred is left as is
+func (s *foodService) UnstarFood(ctx context.Context, userID, foodID string) error {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+	foodUUID, err := uuid.Parse(foodID)
+	if err != nil {
+		return domain.ErrInvalidInput
+	}
+
+	if err := s.starRepo.Unstar(ctx, userUUID, foodUUID); err != nil {
+		return fmt.Errorf("failed to unstar food: %w", err)
+	}
+
+	return nil
+}
+
+// GetStarredFoods returns the foods the user starred, most recently starred first
+func (s *foodService) GetStarredFoods(ctx context.Context, userID string, limit, offset int) ([]*domain.Food, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", domain.ErrInvalidInput)
+	}
+
+	if limit <= 0 {
+		limit = defaultStarredFoodsLimit
+	}
+	if limit > maxStarredFoodsLimit {
+		limit = maxStarredFoodsLimit
+	}
+
+	foods, err := s.starRepo.ListFoods(ctx, userUUID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get starred foods: %w", err)
+	}
+
+	for _, food := range foods {
+		food.IsStarred = true
+	}
+
+	return foods, nil
+}
+
+// MarkStarred sets IsStarred on the foods the user starred. The flag is a convenience, so a
+// failed lookup is logged and the foods are left unmarked.
+func (s *foodService) MarkStarred(ctx context.Context, userID string, foods []*domain.Food) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil || len(foods) == 0 {
+		return
+	}
+
+	foodIDs := make([]uuid.UUID, len(foods))
+	for i, food := range foods {
+		foodIDs[i] = food.ID
+	}
+
+	starred, err := s.starRepo.StarredIDs(ctx, userUUID, foodIDs)
+	if err != nil {
+		requestid.Logf(ctx, "[Foods] Failed to load starred foods for user %s: %v", userID, err)
+		return
+	}
+
+	for _, food := range foods {
+		food.IsStarred = starred[food.ID]
+	}
+}
+
+// ListServingUnits returns every serving unit grouped by category
+func (s *foodService) ListServingUnits(ctx context.Context) ([]domain.ServingUnitGroup, error) {
+	units, err := s.foodRepo.ListServingUnits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list serving units: %w", err)
+	}
+
+	return domain.GroupServingUnits(units), nil
+}
@@ -2,22 +2,75 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+const (
+	// goalListLimit caps how many goals are returned for a user
+	goalListLimit = 100
+
+	// goalWeightHistoryLimit caps the weight entries read to find a goal's starting weight
+	goalWeightHistoryLimit = 1000
+
+	// goalWorkoutWeekLimit caps the workouts counted for a weekly frequency goal
+	goalWorkoutWeekLimit = 100
 )
 
+var validGoalTypes = map[string]bool{
+	"weight_loss":       true,
+	"weight_gain":       true,
+	"muscle_gain":       true,
+	"fat_loss":          true,
+	"endurance":         true,
+	"strength":          true,
+	"steps":             true,
+	"calories":          true,
+	"protein":           true, // daily intake goals, in grams
+	"carbohydrates":     true,
+	"fat":               true,
+	"sodium":            true, // daily limits: sodium in mg, saturated fat in g
+	"saturated_fat":     true,
+	"water_intake":      true,
+	"sleep":             true,
+	"workout_frequency": true,
+	"other":             true,
+}
+
+// nutritionGoalTypes are the daily intake goals whose macro split is checked against the calorie goal
+var nutritionGoalTypes = map[string]bool{
+	"calories":      true,
+	"protein":       true,
+	"carbohydrates": true,
+	"fat":           true,
+}
+
+var validGoalStatuses = map[string]bool{
+	"active":    true,
+	"completed": true,
+	"abandoned": true,
+}
+
 type goalService struct {
-	goalRepo ports.GoalRepository
+	goalRepo    ports.GoalRepository
+	metricRepo  ports.MetricRepository
+	workoutRepo ports.WorkoutRepository
 }
 
 // NewGoalService creates a new goal service
-func NewGoalService(goalRepo ports.GoalRepository) ports.GoalService {
+func NewGoalService(goalRepo ports.GoalRepository, metricRepo ports.MetricRepository, workoutRepo ports.WorkoutRepository) ports.GoalService {
 	return &goalService{
-		goalRepo: goalRepo,
+		goalRepo:    goalRepo,
+		metricRepo:  metricRepo,
+		workoutRepo: workoutRepo,
 	}
 }
 
@@ -26,51 +79,44 @@ func (s *goalService) CreateGoal(ctx context.Context, userID string, goalData *d
 		return nil, domain.ErrInvalidInput
 	}
 
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
 	// Validate required fields
-	if goalData.GoalType == "" || goalData.TargetValue == 0 {
+	if goalData.GoalType == "" || goalData.TargetValue <= 0 {
 		return nil, domain.ErrInvalidInput
 	}
 
 	// Validate goal type
-	validTypes := map[string]bool{
-		"weight_loss":   true,
-		"weight_gain":   true,
-		"muscle_gain":   true,
-		"fat_loss":      true,
-		"endurance":     true,
-		"strength":      true,
-		"steps":         true,
-		"calories":      true,
-		"water_intake":  true,
-		"sleep":         true,
-		"other":         true,
-	}
-	if !validTypes[goalData.GoalType] {
+	if !validGoalTypes[goalData.GoalType] {
 		return nil, domain.ErrInvalidInput
 	}
 
 	// Set defaults
-	if goalData.ID == "" {
-		goalData.ID = uuid.New().String()
+	if goalData.ID == uuid.Nil {
+		goalData.ID = uuid.New()
 	}
-	goalData.UserID = userID
+	goalData.UserID = userUUID
 
 	if goalData.Status == "" {
 		goalData.Status = "active"
 	}
+	if goalData.StartDate.IsZero() {
+		goalData.StartDate = time.Now()
+	}
+	if unit, ok := domain.NutrientLimitUnits[goalData.GoalType]; ok && goalData.Unit == "" {
+		goalData.Unit = unit
+	}
 
 	// Validate status
-	validStatuses := map[string]bool{
-		"active":    true,
-		"completed": true,
-		"abandoned": true,
-	}
-	if !validStatuses[goalData.Status] {
+	if !validGoalStatuses[goalData.Status] {
 		return nil, domain.ErrInvalidInput
 	}
 
 	// Validate target date if provided
-	if !goalData.TargetDate.IsZero() && goalData.TargetDate.Before(time.Now()) {
+	if goalData.TargetDate != nil && goalData.TargetDate.Before(time.Now()) {
 		return nil, domain.ErrInvalidInput
 	}
 
@@ -78,122 +124,278 @@ func (s *goalService) CreateGoal(ctx context.Context, userID string, goalData *d
 	if err := s.goalRepo.Create(ctx, goalData); err != nil {
 		return nil, fmt.Errorf("failed to create goal: %w", err)
 	}
+	goalData.Warnings = s.macroSplitWarnings(ctx, goalData)
 
 	return goalData, nil
 }
 
+// GetGoals lists the user's goals, each with its computed summary
 func (s *goalService) GetGoals(ctx context.Context, userID string, status *string) ([]*domain.Goal, error) {
 	if userID == "" {
 		return nil, domain.ErrInvalidInput
 	}
 
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
 	// Validate status if provided
+	statusFilter := ""
 	if status != nil {
-		validStatuses := map[string]bool{
-			"active":    true,
-			"completed": true,
-			"abandoned": true,
-		}
-		if !validStatuses[*status] {
+		if !validGoalStatuses[*status] {
 			return nil, domain.ErrInvalidInput
 		}
+		statusFilter = *status
 	}
 
-	goals, err := s.goalRepo.GetByUser(ctx, userID, status)
+	goals, err := s.goalRepo.ListByUser(ctx, userUUID, statusFilter, goalListLimit, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get goals: %w", err)
 	}
 
+	now := time.Now()
+	for _, goal := range goals {
+		summary, err := s.summarizeGoal(ctx, goal, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize goal: %w", err)
+		}
+		goal.Summary = summary
+	}
+
 	return goals, nil
 }
 
 func (s *goalService) UpdateGoal(ctx context.Context, goalID string, updates map[string]interface{}) (*domain.Goal, error) {
-	if goalID == "" {
+	goalUUID, err := uuid.Parse(goalID)
+	if err != nil {
 		return nil, domain.ErrInvalidInput
 	}
 
 	// Verify goal exists
-	existing, err := s.goalRepo.GetByID(ctx, goalID)
+	goal, err := s.goalRepo.GetByID(ctx, goalUUID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get goal: %w", err)
 	}
 
-	// Validate status if being updated
+	// Validate and apply each update
 	if status, ok := updates["status"].(string); ok {
-		validStatuses := map[string]bool{
-			"active":    true,
-			"completed": true,
-			"abandoned": true,
-		}
-		if !validStatuses[status] {
+		if !validGoalStatuses[status] {
 			return nil, domain.ErrInvalidInput
 		}
+		goal.Status = status
 	}
 
-	// Validate goal type if being updated
 	if goalType, ok := updates["goal_type"].(string); ok {
-		validTypes := map[string]bool{
-			"weight_loss":   true,
-			"weight_gain":   true,
-			"muscle_gain":   true,
-			"fat_loss":      true,
-			"endurance":     true,
-			"strength":      true,
-			"steps":         true,
-			"calories":      true,
-			"water_intake":  true,
-			"sleep":         true,
-			"other":         true,
-		}
-		if !validTypes[goalType] {
+		if !validGoalTypes[goalType] {
 			return nil, domain.ErrInvalidInput
 		}
+		goal.GoalType = goalType
 	}
 
-	// Validate target date if being updated
 	if targetDate, ok := updates["target_date"].(time.Time); ok {
 		if targetDate.Before(time.Now()) {
 			return nil, domain.ErrInvalidInput
 		}
+		goal.TargetDate = &targetDate
 	}
 
-	// Validate target value if being updated
 	if targetValue, ok := updates["target_value"].(float64); ok {
 		if targetValue <= 0 {
 			return nil, domain.ErrInvalidInput
 		}
+		goal.TargetValue = targetValue
+	}
+
+	if currentValue, ok := updates["current_value"].(float64); ok {
+		goal.CurrentValue = &currentValue
+	}
+	if description, ok := updates["description"].(string); ok {
+		goal.Description = description
+	}
+	if unit, ok := updates["unit"].(string); ok {
+		goal.Unit = unit
 	}
 
 	// Update goal
-	if err := s.goalRepo.Update(ctx, goalID, updates); err != nil {
+	if err := s.goalRepo.Update(ctx, goal); err != nil {
 		return nil, fmt.Errorf("failed to update goal: %w", err)
 	}
+	goal.Warnings = s.macroSplitWarnings(ctx, goal)
 
-	// Return updated goal
-	return s.goalRepo.GetByID(ctx, existing.ID)
+	return goal, nil
 }
 
 func (s *goalService) DeleteGoal(ctx context.Context, goalID string) error {
-	if goalID == "" {
+	goalUUID, err := uuid.Parse(goalID)
+	if err != nil {
 		return domain.ErrInvalidInput
 	}
 
 	// Verify goal exists
-	_, err := s.goalRepo.GetByID(ctx, goalID)
-	if err != nil {
-		if err == domain.ErrNotFound {
+	if _, err := s.goalRepo.GetByID(ctx, goalUUID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
 			return domain.ErrNotFound
 		}
 		return fmt.Errorf("failed to get goal: %w", err)
 	}
 
 	// Delete goal
-	if err := s.goalRepo.Delete(ctx, goalID); err != nil {
+	if err := s.goalRepo.Delete(ctx, goalUUID); err != nil {
 		return fmt.Errorf("failed to delete goal: %w", err)
 	}
 
 	return nil
 }
+
+// macroSplitWarnings checks a saved nutrition goal together with the user's other active
+// nutrition goals, warning when the macro goals do not add up to the calorie goal. Checking
+// is best effort: a failure is logged and the goal is saved without warnings.
+func (s *goalService) macroSplitWarnings(ctx context.Context, goal *domain.Goal) []string {
+	if !nutritionGoalTypes[goal.GoalType] || goal.Status != "active" {
+		return nil
+	}
+
+	goals, err := s.goalRepo.ListByUser(ctx, goal.UserID, "active", goalListLimit, 0)
+	if err != nil {
+		requestid.Logf(ctx, "[Goals] Failed to check macro split for user %s: %v", goal.UserID, err)
+		return nil
+	}
+
+	// The saved goal comes first, then the newest active goal of each other type
+	targets := domain.MacroTargetsFromGoals(append([]*domain.Goal{goal}, goals...))
+	if warning := domain.CheckMacroSplit(targets); warning != "" {
+		return []string{warning}
+	}
+	return nil
+}
+
+// summarizeGoal derives the goal's status from the user's logged data
+func (s *goalService) summarizeGoal(ctx context.Context, goal *domain.Goal, now time.Time) (*domain.GoalSummary, error) {
+	switch goal.GoalType {
+	case "weight_loss", "weight_gain":
+		return s.summarizeWeightGoal(ctx, goal, now)
+	case "workout_frequency":
+		return s.summarizeFrequencyGoal(ctx, goal, now)
+	}
+
+	// Other goal types only have the manually tracked current value
+	summary := &domain.GoalSummary{CurrentValue: goal.CurrentValue}
+	if goal.CurrentValue != nil && goal.TargetValue != 0 {
+		summary.ProgressPercent = clampPercent(*goal.CurrentValue / goal.TargetValue * 100)
+	}
+	return summary, nil
+}
+
+// summarizeWeightGoal measures progress from the first weight logged since the goal started
+// to the latest one, and compares the latest against a linear schedule to the target date
+func (s *goalService) summarizeWeightGoal(ctx context.Context, goal *domain.Goal, now time.Time) (*domain.GoalSummary, error) {
+	weights, err := s.metricRepo.ListByUser(ctx, goal.UserID, "weight", goal.StartDate, now, goalWeightHistoryLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weight history: %w", err)
+	}
+
+	// Entries are newest first; without any, fall back to the value recorded on the goal
+	var start, current float64
+	switch {
+	case len(weights) > 0:
+		current = weightInUnit(weights[0], goal.Unit)
+		start = weightInUnit(weights[len(weights)-1], goal.Unit)
+	case goal.CurrentValue != nil:
+		current = *goal.CurrentValue
+		start = current
+	default:
+		return &domain.GoalSummary{}, nil
+	}
+
+	losing := goal.GoalType == "weight_loss"
+	remaining := goal.TargetValue - current
+	if losing {
+		remaining = current - goal.TargetValue
+	}
+	remaining = roundTenth(math.Max(0, remaining))
+
+	summary := &domain.GoalSummary{
+		CurrentValue: &current,
+		Remaining:    &remaining,
+	}
+
+	if total := goal.TargetValue - start; total != 0 {
+		summary.ProgressPercent = clampPercent((current - start) / total * 100)
+	} else {
+		summary.ProgressPercent = 100
+	}
+	if remaining == 0 {
+		summary.ProgressPercent = 100
+	}
+
+	if goal.TargetDate != nil && goal.TargetDate.After(goal.StartDate) {
+		elapsed := math.Min(1, math.Max(0, now.Sub(goal.StartDate).Hours()/goal.TargetDate.Sub(goal.StartDate).Hours()))
+		expected := roundTenth(start + (goal.TargetValue-start)*elapsed)
+		onTrack := remaining == 0 || (losing && current <= expected) || (!losing && current >= expected)
+
+		summary.ExpectedValue = &expected
+		summary.OnTrack = &onTrack
+	}
+
+	return summary, nil
+}
+
+// summarizeFrequencyGoal counts this week's workouts against the weekly target.
+// The goal is on track while the count keeps pace with the target across the week.
+func (s *goalService) summarizeFrequencyGoal(ctx context.Context, goal *domain.Goal, now time.Time) (*domain.GoalSummary, error) {
+	weekStart := startOfWeek(now)
+	workouts, err := s.workoutRepo.ListByUser(ctx, goal.UserID, weekStart, now, domain.WorkoutFilter{}, goalWorkoutWeekLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workouts: %w", err)
+	}
+
+	count := len(workouts)
+	target := int(math.Round(goal.TargetValue))
+	remaining := float64(max(0, target-count))
+	current := float64(count)
+
+	daysElapsed := int(now.Sub(weekStart).Hours()/24) + 1
+	onTrack := count*7 >= target*daysElapsed
+
+	summary := &domain.GoalSummary{
+		CurrentValue: &current,
+		Remaining:    &remaining,
+		OnTrack:      &onTrack,
+		WeekCount:    &count,
+		WeeklyTarget: &target,
+	}
+	if target > 0 {
+		summary.ProgressPercent = clampPercent(float64(count) / float64(target) * 100)
+	}
+
+	return summary, nil
+}
+
+// weightInUnit converts a weight entry to the goal's unit (kg unless the goal is in pounds)
+func weightInUnit(metric *domain.Metric, unit string) float64 {
+	kg := weightKg(metric)
+	switch unit {
+	case "lb", "lbs", "pound", "pounds":
+		return roundTenth(kg / kgPerPound)
+	}
+	return roundTenth(kg)
+}
+
+// startOfWeek returns midnight on the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	year, month, day := t.AddDate(0, 0, -daysSinceMonday).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func clampPercent(p float64) float64 {
+	return math.Round(math.Min(100, math.Max(0, p))*10) / 10
+}
+
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+type insightsService struct {
+	userRepo        ports.UserRepository
+	mealRepo        ports.MealRepository
+	workoutRepo     ports.WorkoutRepository
+	metricRepo      ports.MetricRepository
+	achievementRepo ports.AchievementRepository
+}
+
+// NewInsightsService creates a new insights service
+func NewInsightsService(
+	userRepo ports.UserRepository,
+	mealRepo ports.MealRepository,
+	workoutRepo ports.WorkoutRepository,
+	metricRepo ports.MetricRepository,
+	achievementRepo ports.AchievementRepository,
+) ports.InsightsService {
+	return &insightsService{
+		userRepo:        userRepo,
+		mealRepo:        mealRepo,
+		workoutRepo:     workoutRepo,
+		metricRepo:      metricRepo,
+		achievementRepo: achievementRepo,
+	}
+}
+
+// GetStreaks reports the user's meal logging and workout streaks in their timezone.
+// Achievements reached since the last check are recorded before they are returned.
+func (s *insightsService) GetStreaks(ctx context.Context, userID string) (*domain.StreakSummary, error) {
+	userUUID, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().In(loc).Format("2006-01-02")
+
+	mealDays, err := s.mealRepo.ListLoggedDays(ctx, userUUID, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meal days: %w", err)
+	}
+
+	workoutDays, err := s.workoutRepo.ListCompletedDays(ctx, userUUID, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout days: %w", err)
+	}
+
+	achievements, err := s.updateAchievements(ctx, userUUID, loc, mealDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StreakSummary{
+		Timezone:     loc.String(),
+		Today:        today,
+		Meals:        domain.CalculateStreak(mealDays, today),
+		Workouts:     domain.CalculateStreak(workoutDays, today),
+		Achievements: achievements,
+	}, nil
+}
+
+// GetActivityCalendar flags each day of the year, in the user's timezone, on which a meal,
+// a finished workout or a weight was logged. The current year stops at today and a
+// future year has no days.
+func (s *insightsService) GetActivityCalendar(ctx context.Context, userID, year string) (*domain.ActivityCalendar, error) {
+	userUUID, loc, err := s.userLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().In(loc)
+
+	calendarYear, err := domain.ParseCalendarYear(year, today)
+	if err != nil {
+		return nil, err
+	}
+
+	mealDays, err := s.mealRepo.ListLoggedDays(ctx, userUUID, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meal days: %w", err)
+	}
+
+	workoutDays, err := s.workoutRepo.ListCompletedDays(ctx, userUUID, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout days: %w", err)
+	}
+
+	weightDays, err := s.metricRepo.ListMeasuredDays(ctx, userUUID, "weight", loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weight days: %w", err)
+	}
+
+	return domain.NewActivityCalendar(calendarYear, loc.String(), today, mealDays, workoutDays, weightDays), nil
+}
+
+// userLocation returns the user's ID and timezone, UTC when none is set
+func (s *insightsService) userLocation(ctx context.Context, userID string) (uuid.UUID, *time.Location, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return uuid.Nil, nil, domain.ErrNotFound
+		}
+		return uuid.Nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	loc := user.Location()
+	return userUUID, loc, nil
+}
+
+// updateAchievements records any achievement the user has reached but not yet been awarded,
+// dated when it was reached, and returns all of the user's achievements in earned order
+func (s *insightsService) updateAchievements(ctx context.Context, userID uuid.UUID, loc *time.Location, mealDays []string) ([]*domain.Achievement, error) {
+	achievements, err := s.achievementRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achievements: %w", err)
+	}
+
+	earned := make(map[string]bool, len(achievements))
+	for _, achievement := range achievements {
+		earned[achievement.Code] = true
+	}
+
+	reached := make(map[string]time.Time)
+
+	if !earned[domain.AchievementFirstWorkout] {
+		at, err := s.workoutRepo.FirstCompletedAt(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get first workout: %w", err)
+		}
+		if at != nil {
+			reached[domain.AchievementFirstWorkout] = *at
+		}
+	}
+
+	if !earned[domain.AchievementMealStreak7] {
+		if date := domain.StreakReachedOn(mealDays, domain.MealStreakAchievementDays); date != "" {
+			if day, err := time.ParseInLocation("2006-01-02", date, loc); err == nil {
+				reached[domain.AchievementMealStreak7] = day
+			}
+		}
+	}
+
+	if !earned[domain.AchievementFirstPR] {
+		at, err := s.workoutRepo.FirstPersonalRecordAt(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get personal records: %w", err)
+		}
+		if at != nil {
+			reached[domain.AchievementFirstPR] = *at
+		}
+	}
+
+	if len(reached) == 0 {
+		return achievements, nil
+	}
+
+	for code, at := range reached {
+		achievement := &domain.Achievement{UserID: userID, Code: code, EarnedAt: at}
+		if err := s.achievementRepo.CreateIfMissing(ctx, achievement); err != nil {
+			return nil, fmt.Errorf("failed to record achievement: %w", err)
+		}
+	}
+
+	// Reload so a concurrent award of the same code is returned once, with its stored ID
+	achievements, err = s.achievementRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achievements: %w", err)
+	}
+	return achievements, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+const (
+	defaultLLMAuditLimit = 50
+	maxLLMAuditLimit     = 200
+)
+
+var (
+	auditEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	// Phone numbers need separators or a leading +, so dates, timestamps and token counts are kept
+	auditPhonePattern = regexp.MustCompile(`\+\d{1,3}[\s.-]?\d[\d\s.-]{6,}\d|\(?\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`)
+
+	// Inline photos can show faces and are too large to keep in an audit row
+	auditImageDataPattern = regexp.MustCompile(`(data:image/[A-Za-z0-9.+-]+;base64,)[A-Za-z0-9+/=]+`)
+)
+
+type llmAuditService struct {
+	auditRepo ports.LLMAuditRepository
+	enabled   bool
+	redactPII bool
+}
+
+// NewLLMAuditService creates a service that persists LLM calls when enabled.
+// With redactPII set, emails, phone numbers and inline image data are scrubbed
+// from the stored request, response and error.
+func NewLLMAuditService(auditRepo ports.LLMAuditRepository, enabled, redactPII bool) ports.LLMAuditService {
+	return &llmAuditService{
+		auditRepo: auditRepo,
+		enabled:   enabled,
+		redactPII: redactPII,
+	}
+}
+
+// Record stores the entry. Failures are logged rather than returned so auditing never breaks an AI call.
+func (s *llmAuditService) Record(ctx context.Context, entry *domain.LLMAuditEntry) {
+	if !s.enabled || entry == nil {
+		return
+	}
+
+	if s.redactPII {
+		entry.Request = redactPII(entry.Request)
+		if entry.Response != nil {
+			response := redactPII(*entry.Response)
+			entry.Response = &response
+		}
+		if entry.Error != nil {
+			message := redactPII(*entry.Error)
+			entry.Error = &message
+		}
+	}
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		requestid.Logf(ctx, "[LLMAudit] Failed to record %s call to %s: %v", entry.Operation, entry.Model, err)
+	}
+}
+
+// ListEntries returns audit entries matching the filter, newest first
+func (s *llmAuditService) ListEntries(ctx context.Context, filter domain.LLMAuditFilter, limit, offset int) ([]*domain.LLMAuditEntry, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", domain.ErrInvalidInput)
+	}
+	if filter.Status != "" && filter.Status != "success" && filter.Status != "error" {
+		return nil, fmt.Errorf("%w: status must be success or error", domain.ErrInvalidInput)
+	}
+
+	if limit <= 0 {
+		limit = defaultLLMAuditLimit
+	}
+	if limit > maxLLMAuditLimit {
+		limit = maxLLMAuditLimit
+	}
+
+	entries, err := s.auditRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LLM audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// redactPII replaces emails, phone numbers and inline image data with placeholders
+func redactPII(text string) string {
+	text = auditImageDataPattern.ReplaceAllString(text, "${1}[REDACTED]")
+	text = auditEmailPattern.ReplaceAllString(text, "[REDACTED_EMAIL]")
+	return auditPhonePattern.ReplaceAllString(text, "[REDACTED_PHONE]")
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+const (
+	// mealComparisonWindow is how far back past meals are taken as typical
+	mealComparisonWindow = 90 * 24 * time.Hour
+
+	// mealComparisonHistoryLimit caps the past meals loaded for a comparison
+	mealComparisonHistoryLimit = 500
+)
+
+type mealComparisonService struct {
+	mealRepo ports.MealRepository
+}
+
+// NewMealComparisonService creates a service comparing meals with the user's typical meals
+func NewMealComparisonService(mealRepo ports.MealRepository) ports.MealComparisonService {
+	return &mealComparisonService{mealRepo: mealRepo}
+}
+
+// CompareMeal compares a meal that has not been logged yet, such as a parsed
+// meal awaiting confirmation, with the user's past meals of its type
+func (s *mealComparisonService) CompareMeal(ctx context.Context, userID, mealType string, totals domain.MacroTargets) (*domain.MealComparison, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	history, err := s.history(ctx, userUUID, time.Now(), uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	return domain.CompareMeal(mealType, totals, history), nil
+}
+
+// CompareLoggedMeal compares a logged meal with the user's meals of its type
+// logged before it. An empty mealID compares the user's most recent meal.
+func (s *mealComparisonService) CompareLoggedMeal(ctx context.Context, userID, mealID string) (*domain.MealComparison, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	meal, err := s.loggedMeal(ctx, userUUID, mealID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := domain.MacroTargets{
+		Calories:      meal.TotalCalories,
+		Protein:       meal.TotalProtein,
+		Carbohydrates: meal.TotalCarbohydrates,
+		Fat:           meal.TotalFat,
+	}
+
+	// Only earlier meals count, so comparing an old meal is not skewed by later ones
+	history, err := s.history(ctx, userUUID, meal.ConsumedAt, meal.ID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.CompareMeal(meal.MealType, totals, history), nil
+}
+
+// loggedMeal returns the user's meal with mealID, or their most recent meal when mealID is empty
+func (s *mealComparisonService) loggedMeal(ctx context.Context, userID uuid.UUID, mealID string) (*domain.Meal, error) {
+	if mealID == "" {
+		meals, err := s.mealRepo.ListByUser(ctx, userID, time.Time{}, time.Time{}, 1, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest meal: %w", err)
+		}
+		if len(meals) == 0 {
+			return nil, domain.ErrNotFound
+		}
+		return meals[0], nil
+	}
+
+	mealUUID, err := uuid.Parse(mealID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	meal, err := s.mealRepo.GetByID(ctx, mealUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get meal: %w", err)
+	}
+	if meal.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	return meal, nil
+}
+
+// history returns the user's meals in the comparison window before the given
+// time, leaving out the meal being compared
+func (s *mealComparisonService) history(ctx context.Context, userID uuid.UUID, before time.Time, exclude uuid.UUID) ([]*domain.Meal, error) {
+	meals, err := s.mealRepo.ListByUser(ctx, userID, before.Add(-mealComparisonWindow), before, mealComparisonHistoryLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meal history: %w", err)
+	}
+
+	history := make([]*domain.Meal, 0, len(meals))
+	for _, meal := range meals {
+		if meal.ID != exclude {
+			history = append(history, meal)
+		}
+	}
+	return history, nil
+}
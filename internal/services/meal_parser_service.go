@@ -2,32 +2,158 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"fitness-tracker/internal/adapters/external"
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/utils"
+	"fitness-tracker/internal/pkg/requestid"
 
 	"github.com/google/uuid"
 )
 
+// aiFoodDuplicateThreshold is the name similarity at which an existing
+// AI-generated food is reused instead of estimating and creating another
+const aiFoodDuplicateThreshold = 0.85
+
+// defaultConfidenceFloor is the average confidence below which a parse is rejected
+// rather than offered for confirmation
+const defaultConfidenceFloor = 0.3
+
+// textFallbackConfidence is the confidence of foods split from text without AI,
+// low enough that the parsed meal always asks for confirmation
+const textFallbackConfidence = 0.6
+
+// foodEstimateCacheTTL is how long a food's estimated nutrition is reused, so asking
+// about the same food again does not make another LLM call
+const foodEstimateCacheTTL = 15 * time.Minute
+
+// parsedMealTTL is how long a parsed meal can be confirmed by its ID
+const parsedMealTTL = 30 * time.Minute
+
+// errFoodNotResolved reports a food that matched nothing in the database and
+// could not be estimated
+var errFoodNotResolved = errors.New("food not found and no estimate available")
+
 // MealParserService handles parsing meals from text and photos
 type MealParserService struct {
 	openRouterClient *external.OpenRouterClient
 	visionClient     *external.VisionClient
+	mealComparison   ports.MealComparisonService // optional
 	foodRepository   ports.FoodRepository
+
+	// Without an API key text is split into foods by simple rules and foods missing
+	// from the database are handled by estimateFallback
+	aiEnabled        bool
+	estimateFallback string
+
+	// Parses averaging less confidence fail with domain.ErrLowConfidence
+	confidenceFloor float64
+
+	// Sampling temperature of parsing, estimation and photo analysis; 0 by default so
+	// the same input gives the same foods
+	temperature float64
+
+	// Recent food estimates by normalized name
+	estimateMu sync.Mutex
+	estimates  map[string]cachedFoodEstimate
+
+	// Parsed meals awaiting confirmation by ID. They live in memory, so a confirmation
+	// must reach the instance that parsed the meal; see the single-instance note in the
+	// configuration docs.
+	parsedMu sync.Mutex
+	parsed   map[uuid.UUID]pendingParsedMeal
+}
+
+// pendingParsedMeal is a parsed meal the user has not confirmed yet
+type pendingParsedMeal struct {
+	userID    uuid.UUID
+	meal      domain.ParsedMeal
+	expiresAt time.Time
+	taken     bool // being logged by TakeParsedMeal's caller
+}
+
+// cachedFoodEstimate is a food's per-100g nutrition and where it came from
+type cachedFoodEstimate struct {
+	name      string
+	source    string
+	nutrition domain.NutritionEstimate
+	expiresAt time.Time
 }
 
-// NewMealParserService creates a new meal parser service
-func NewMealParserService(apiKey string, foodRepo ports.FoodRepository) *MealParserService {
-	return &MealParserService{
+// NewMealParserService creates a new meal parser service.
+// When auditor is non-nil every AI call it makes is recorded in the audit log.
+// An empty apiKey disables AI; see WithEstimateFallback.
+func NewMealParserService(apiKey string, foodRepo ports.FoodRepository, auditor external.CallAuditor) *MealParserService {
+	s := &MealParserService{
 		openRouterClient: external.NewOpenRouterClient(apiKey),
 		visionClient:     external.NewVisionClient(apiKey),
 		foodRepository:   foodRepo,
+		aiEnabled:        apiKey != "",
+		estimateFallback: domain.MealEstimateFallbackDatabase,
+		confidenceFloor:  defaultConfidenceFloor,
+		estimates:        make(map[string]cachedFoodEstimate),
+		parsed:           make(map[uuid.UUID]pendingParsedMeal),
 	}
+	if auditor != nil {
+		s.openRouterClient.WithAuditor(auditor)
+		s.visionClient.WithAuditor(auditor)
+	}
+	return s
+}
+
+// WithFallbackModels sets the models text parsing falls back to when its model
+// is rate limited or failing. Photo parsing needs a vision model and keeps its own.
+func (s *MealParserService) WithFallbackModels(models ...string) *MealParserService {
+	s.openRouterClient.WithFallbackModels(models...)
+	return s
+}
+
+// WithBaseURL sends text parsing, food estimation and photo analysis to another
+// OpenRouter-compatible endpoint. An empty baseURL keeps OpenRouter's.
+func (s *MealParserService) WithBaseURL(baseURL string) *MealParserService {
+	s.openRouterClient.WithBaseURL(baseURL)
+	s.visionClient.WithBaseURL(baseURL)
+	return s
+}
+
+// WithTemperature sets the sampling temperature of the parser's AI calls. Values above
+// the default 0 make parses of the same text vary.
+func (s *MealParserService) WithTemperature(temperature float64) *MealParserService {
+	s.temperature = temperature
+	s.visionClient.WithTemperature(temperature)
+	return s
+}
+
+// WithMealComparison compares every parsed meal with the user's typical meal of its type
+func (s *MealParserService) WithMealComparison(mealComparison ports.MealComparisonService) *MealParserService {
+	s.mealComparison = mealComparison
+	return s
+}
+
+// WithEstimateFallback sets how foods missing from the database are handled when AI is
+// disabled: domain.MealEstimateFallbackDatabase (the default) leaves them unresolved,
+// domain.MealEstimateFallbackBuiltin estimates common foods from a built-in table.
+func (s *MealParserService) WithEstimateFallback(fallback string) *MealParserService {
+	s.estimateFallback = fallback
+	return s
+}
+
+// WithConfidenceFloor sets the average confidence below which ParseText and ParsePhoto
+// fail with domain.ErrLowConfidence instead of returning the meal. Meals at or above
+// the floor are still flagged NeedsConfirmation when their confidence is low. Zero
+// accepts every parse.
+func (s *MealParserService) WithConfidenceFloor(floor float64) *MealParserService {
+	s.confidenceFloor = floor
+	return s
 }
 
 // ExtractedFoodItem represents a food item extracted from AI
@@ -38,13 +164,51 @@ type ExtractedFoodItem struct {
 	Confidence float64 `json:"confidence"`
 }
 
+// mealExtractionTool is the tool ParseText forces the model to call, so the extracted
+// meal arrives as the call's JSON arguments rather than free text
+var mealExtractionTool = external.Tool{
+	Type: "function",
+	Function: external.ToolFunction{
+		Name:        "record_meal",
+		Description: "Record the meal type and food items extracted from the user's text",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"meal_type": map[string]interface{}{
+					"type":        "string",
+					"description": "breakfast, lunch, dinner, snack or the user's own label",
+				},
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"name":       map[string]interface{}{"type": "string"},
+							"quantity":   map[string]interface{}{"type": "number"},
+							"unit":       map[string]interface{}{"type": "string"},
+							"confidence": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+						},
+						"required": []string{"name", "quantity", "unit", "confidence"},
+					},
+				},
+			},
+			"required": []string{"meal_type", "items"},
+		},
+	},
+}
+
 // ParseText parses meal information from text input
 func (s *MealParserService) ParseText(ctx context.Context, userID uuid.UUID, text string) (*domain.ParsedMeal, error) {
+	if !s.aiEnabled {
+		return s.parseTextWithoutAI(ctx, userID, text)
+	}
+	ctx = external.WithAuditContext(ctx, external.AuditOperationMealParse, userID)
+
 	// System prompt for food extraction
 	systemPrompt := `You are a nutrition expert. Extract food items, quantities, and meal type from the user's text.
 Return a JSON object with:
 {
-  "meal_type": "breakfast|lunch|dinner|snack",
+  "meal_type": "breakfast|lunch|dinner|snack|custom label",
   "items": [
     {
       "name": "food name",
@@ -55,6 +219,7 @@ Return a JSON object with:
   ]
 }
 
+If the user names their own meal label (e.g. "pre-workout", "second breakfast"), use it as meal_type.
 If meal type cannot be determined, infer from context or time of day. Use standard units (prefer grams for solids, ml for liquids).`
 
 	// Get AI response using OpenRouter client
@@ -63,7 +228,9 @@ If meal type cannot be determined, infer from context or time of day. Use standa
 		{Role: "user", Content: text},
 	}
 
-	resp, err := s.openRouterClient.Chat(ctx, messages, "deepseek/deepseek-chat")
+	tools := []external.Tool{mealExtractionTool}
+	opts := external.ToolCallOptions(mealExtractionTool.Function.Name).WithTemperature(s.temperature)
+	resp, err := s.openRouterClient.ChatWithToolsAndOptions(ctx, messages, tools, "deepseek/deepseek-chat", opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse text with AI: %w", err)
 	}
@@ -72,7 +239,11 @@ If meal type cannot be determined, infer from context or time of day. Use standa
 		return nil, fmt.Errorf("no response from AI")
 	}
 
-	response := resp.Choices[0].Message.Content
+	// Models that ignore tool_choice answer in the message content instead
+	response, ok := resp.ToolArguments(mealExtractionTool.Function.Name)
+	if !ok {
+		response = resp.Choices[0].Message.Content
+	}
 
 	// Parse AI response
 	var aiResponse struct {
@@ -80,54 +251,62 @@ If meal type cannot be determined, infer from context or time of day. Use standa
 		Items    []ExtractedFoodItem `json:"items"`
 	}
 
-	// Clean response (remove markdown code blocks if present)
-	cleanedResponse := strings.TrimSpace(response)
-	cleanedResponse = strings.TrimPrefix(cleanedResponse, "```json")
-	cleanedResponse = strings.TrimPrefix(cleanedResponse, "```")
-	cleanedResponse = strings.TrimSuffix(cleanedResponse, "```")
-	cleanedResponse = strings.TrimSpace(cleanedResponse)
-
-	if err := json.Unmarshal([]byte(cleanedResponse), &aiResponse); err != nil {
+	// Tool arguments are a bare object; DecodeJSONContent falls back to stripping
+	// markdown fences from content replies
+	if err := external.DecodeJSONContent(response, &aiResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse AI response: %w (response: %s)", err, response)
 	}
 
-	// Process each food item
-	parsedItems := make([]domain.ParsedFoodItem, 0, len(aiResponse.Items))
-	totalConfidence := 0.0
-
-	for _, item := range aiResponse.Items {
-		parsedItem, err := s.processFoodItem(ctx, userID, item)
-		if err != nil {
-			// Log error but continue processing other items
-			fmt.Printf("Warning: failed to process food item %s: %v\n", item.Name, err)
-			continue
-		}
-		parsedItems = append(parsedItems, parsedItem)
-		totalConfidence += item.Confidence
-	}
-
+	parsedItems, avgConfidence, unresolved := s.processFoodItems(ctx, userID, aiResponse.Items)
 	if len(parsedItems) == 0 {
 		return nil, fmt.Errorf("no valid food items could be extracted")
 	}
+	if err := s.checkConfidence(avgConfidence); err != nil {
+		return nil, err
+	}
 
-	avgConfidence := totalConfidence / float64(len(parsedItems))
+	// Keep the model's label, custom or not, unless it is empty or too long
+	mealType, err := domain.NormalizeMealType(aiResponse.MealType)
+	if err != nil {
+		mealType = s.inferMealType(time.Now())
+	}
 
-	return &domain.ParsedMeal{
-		MealType:          aiResponse.MealType,
+	parsed := &domain.ParsedMeal{
+		MealType:          mealType,
 		LoggedAt:          time.Now(),
 		FoodItems:         parsedItems,
 		Confidence:        avgConfidence,
 		NeedsConfirmation: avgConfidence < 0.8, // Require confirmation if confidence is low
-	}, nil
+		UnresolvedItems:   unresolved,
+	}
+	s.compareWithTypical(ctx, userID, parsed)
+	s.RememberParsedMeal(userID, parsed)
+	return parsed, nil
 }
 
-// ParsePhoto parses meal information from photo input
-func (s *MealParserService) ParsePhoto(ctx context.Context, userID uuid.UUID, photoURL string) (*domain.ParsedMeal, error) {
+// ParsePhoto parses meal information from photo input. The photo is an http(s)
+// URL or a base64 data URL, so clients can send an image without uploading it first.
+func (s *MealParserService) ParsePhoto(ctx context.Context, userID uuid.UUID, photo string) (*domain.ParsedMeal, error) {
+	if !s.aiEnabled {
+		return nil, fmt.Errorf("%w: photo parsing needs AI", domain.ErrAIUnavailable)
+	}
+	ctx = external.WithAuditContext(ctx, external.AuditOperationMealParse, userID)
+
 	// Analyze image with vision AI
-	result, err := s.visionClient.AnalyzeFoodPhoto(ctx, photoURL)
+	result, err := s.visionClient.AnalyzeFoodPhoto(ctx, photo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze image: %w", err)
 	}
+	if len(result.Items) == 0 {
+		return &domain.ParsedMeal{
+			MealType:          s.inferMealType(time.Now()),
+			LoggedAt:          time.Now(),
+			FoodItems:         []domain.ParsedFoodItem{},
+			NeedsConfirmation: true,
+			ImageQuality:      result.ImageQuality,
+			Notes:             result.Notes,
+		}, nil
+	}
 
 	// Convert vision result to extracted items
 	extractedItems := make([]ExtractedFoodItem, len(result.Items))
@@ -144,15 +323,143 @@ func (s *MealParserService) ParsePhoto(ctx context.Context, userID uuid.UUID, ph
 		}
 	}
 
-	// Process each food item
-	parsedItems := make([]domain.ParsedFoodItem, 0, len(extractedItems))
+	parsedItems, avgConfidence, unresolved := s.processFoodItems(ctx, userID, extractedItems)
+	if len(parsedItems) == 0 {
+		return nil, fmt.Errorf("no valid food items could be extracted from image")
+	}
+	if err := s.checkConfidence(avgConfidence); err != nil {
+		return nil, err
+	}
+
+	// Infer meal type based on time of day
+	mealType := s.inferMealType(time.Now())
+
+	parsed := &domain.ParsedMeal{
+		MealType:          mealType,
+		LoggedAt:          time.Now(),
+		FoodItems:         parsedItems,
+		Confidence:        avgConfidence,
+		NeedsConfirmation: avgConfidence < 0.7, // Photos typically need more confirmation
+		UnresolvedItems:   unresolved,
+	}
+	s.compareWithTypical(ctx, userID, parsed)
+	s.RememberParsedMeal(userID, parsed)
+	return parsed, nil
+}
+
+// parseTextWithoutAI parses a meal typed as a list of foods, e.g. "200g chicken breast,
+// 2 eggs and a banana for lunch", using only foods in the database or, with the builtin
+// fallback, the built-in nutrition table
+func (s *MealParserService) parseTextWithoutAI(ctx context.Context, userID uuid.UUID, text string) (*domain.ParsedMeal, error) {
+	mealType, items := splitMealText(text)
+
+	parsedItems, avgConfidence, unresolved := s.processFoodItems(ctx, userID, items)
+	if len(parsedItems) == 0 {
+		if len(unresolved) > 0 {
+			return nil, fmt.Errorf("%w: no food found for %s", domain.ErrNotFound, strings.Join(unresolved, ", "))
+		}
+		return nil, fmt.Errorf("%w: no food items found in text", domain.ErrInvalidInput)
+	}
+	if err := s.checkConfidence(avgConfidence); err != nil {
+		return nil, err
+	}
+
+	if mealType == "" {
+		mealType = s.inferMealType(time.Now())
+	}
+
+	parsed := &domain.ParsedMeal{
+		MealType:          mealType,
+		LoggedAt:          time.Now(),
+		FoodItems:         parsedItems,
+		Confidence:        avgConfidence,
+		NeedsConfirmation: true,
+		UnresolvedItems:   unresolved,
+	}
+	s.compareWithTypical(ctx, userID, parsed)
+	s.RememberParsedMeal(userID, parsed)
+	return parsed, nil
+}
+
+var (
+	// mealTextSeparator splits typed foods apart
+	mealTextSeparator = regexp.MustCompile(`(?i)\s*(?:[,;+&\n]|\band\b|\bwith\b)\s*`)
+	// mealTextMealType finds a standard meal type named in the text, like "for lunch"
+	mealTextMealType = regexp.MustCompile(`(?i)\b(?:for\s+)?(?:a\s+)?(breakfast|lunch|dinner|snack)\b`)
+	// mealTextFiller is leading text that is not part of a food name
+	mealTextFiller = regexp.MustCompile(`(?i)^(?:i\s+(?:had|ate)|had|ate|some)\s+`)
+	// mealTextQuantity reads a leading amount and unit, like "200g", "1.5 cups of" or "a"
+	mealTextQuantity = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?|an?|one)\s*(g|grams?|kg|ml|l|oz|cups?|tbsp|tsp|pieces?|slices?)?\s+(?:of\s+)?`)
+)
+
+// mealTextUnits maps a typed unit to the unit stored and how many of it the typed unit is
+var mealTextUnits = map[string]struct {
+	unit   string
+	factor float64
+}{
+	"g": {"g", 1}, "gram": {"g", 1}, "grams": {"g", 1}, "kg": {"g", 1000}, "oz": {"g", 28.35},
+	"ml": {"ml", 1}, "l": {"ml", 1000},
+	"cup": {"cup", 1}, "cups": {"cup", 1}, "tbsp": {"tbsp", 1}, "tsp": {"tsp", 1},
+	"piece": {"piece", 1}, "pieces": {"piece", 1}, "slice": {"piece", 1}, "slices": {"piece", 1},
+}
+
+// splitMealText splits a typed meal into foods with their amounts, without AI. A food
+// without an amount counts as one serving. It also returns the standard meal type the
+// text names, or "" when it names none.
+func splitMealText(text string) (string, []ExtractedFoodItem) {
+	mealType := ""
+	if match := mealTextMealType.FindStringSubmatch(text); match != nil {
+		mealType = strings.ToLower(match[1])
+	}
+	text = mealTextMealType.ReplaceAllString(text, "")
+
+	var items []ExtractedFoodItem
+	for _, part := range mealTextSeparator.Split(text, -1) {
+		part = mealTextFiller.ReplaceAllString(strings.TrimSpace(part), "")
+		item := ExtractedFoodItem{Quantity: 1, Unit: "serving", Confidence: textFallbackConfidence}
+
+		if match := mealTextQuantity.FindStringSubmatch(part + " "); match != nil {
+			if amount, err := strconv.ParseFloat(match[1], 64); err == nil {
+				item.Quantity = amount
+			}
+			item.Unit = "piece"
+			if unit, ok := mealTextUnits[strings.ToLower(match[2])]; ok {
+				item.Quantity *= unit.factor
+				item.Unit = unit.unit
+			}
+			part = strings.TrimSpace((part + " ")[len(match[0]):])
+		}
+
+		item.Name = strings.Join(strings.Fields(part), " ")
+		if item.Name == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+	return mealType, items
+}
+
+// checkConfidence rejects a parse whose average confidence is below the floor
+func (s *MealParserService) checkConfidence(confidence float64) error {
+	if confidence < s.confidenceFloor {
+		return fmt.Errorf("%w (confidence %.2f, minimum %.2f)", domain.ErrLowConfidence, confidence, s.confidenceFloor)
+	}
+	return nil
+}
+
+// processFoodItems resolves extracted items to foods. Items that cannot be resolved are
+// logged and returned by name; the confidence is the average of the resolved items.
+func (s *MealParserService) processFoodItems(ctx context.Context, userID uuid.UUID, items []ExtractedFoodItem) ([]domain.ParsedFoodItem, float64, []string) {
+	parsedItems := make([]domain.ParsedFoodItem, 0, len(items))
+	var unresolved []string
 	totalConfidence := 0.0
 
-	for _, item := range extractedItems {
+	for _, item := range items {
 		parsedItem, err := s.processFoodItem(ctx, userID, item)
 		if err != nil {
 			// Log error but continue processing other items
-			fmt.Printf("Warning: failed to process food item %s: %v\n", item.Name, err)
+			requestid.Logf(ctx, "[MealParser] Warning: failed to process food item %s: %v", item.Name, err)
+			unresolved = append(unresolved, item.Name)
 			continue
 		}
 		parsedItems = append(parsedItems, parsedItem)
@@ -160,21 +467,9 @@ func (s *MealParserService) ParsePhoto(ctx context.Context, userID uuid.UUID, ph
 	}
 
 	if len(parsedItems) == 0 {
-		return nil, fmt.Errorf("no valid food items could be extracted from image")
+		return parsedItems, 0, unresolved
 	}
-
-	avgConfidence := totalConfidence / float64(len(parsedItems))
-
-	// Infer meal type based on time of day
-	mealType := s.inferMealType(time.Now())
-
-	return &domain.ParsedMeal{
-		MealType:          mealType,
-		LoggedAt:          time.Now(),
-		FoodItems:         parsedItems,
-		Confidence:        avgConfidence,
-		NeedsConfirmation: avgConfidence < 0.7, // Photos typically need more confirmation
-	}, nil
+	return parsedItems, totalConfidence / float64(len(parsedItems)), unresolved
 }
 
 // processFoodItem processes a single extracted food item
@@ -183,14 +478,19 @@ func (s *MealParserService) processFoodItem(ctx context.Context, userID uuid.UUI
 	food, err := s.matchFoodInDatabase(ctx, item.Name)
 	if err == nil && food != nil {
 		// Found matching food in database
-		return domain.ParsedFoodItem{
-			FoodID:      &food.ID,
-			FoodName:    food.Name,
-			Quantity:    item.Quantity,
-			Unit:        item.Unit,
-			Confidence:  item.Confidence,
-			AIGenerated: false,
-		}, nil
+		return newParsedFoodItem(food, item, item.Confidence, false), nil
+	}
+
+	if !s.aiEnabled {
+		if s.estimateFallback != domain.MealEstimateFallbackBuiltin {
+			return domain.ParsedFoodItem{}, errFoodNotResolved
+		}
+		builtinFood, err := s.createBuiltinFood(ctx, item.Name)
+		if err != nil {
+			return domain.ParsedFoodItem{}, err
+		}
+		// Table values are typical, not specific to what was eaten
+		return newParsedFoodItem(builtinFood, item, item.Confidence*0.8, false), nil
 	}
 
 	// No match found - create AI-generated food
@@ -199,20 +499,131 @@ func (s *MealParserService) processFoodItem(ctx context.Context, userID uuid.UUI
 		return domain.ParsedFoodItem{}, fmt.Errorf("failed to create AI food: %w", err)
 	}
 
+	// Reduce confidence for AI-generated foods
+	return newParsedFoodItem(aiFood, item, item.Confidence*0.8, true), nil
+}
+
+// newParsedFoodItem builds a parsed item for the food, with the nutrition of the extracted quantity
+func newParsedFoodItem(food *domain.Food, item ExtractedFoodItem, confidence float64, aiGenerated bool) domain.ParsedFoodItem {
+	portion := domain.MealFoodItem{Quantity: item.Quantity, Unit: item.Unit}
+	portion.ApplySnapshot(food)
+
 	return domain.ParsedFoodItem{
-		FoodID:      &aiFood.ID,
-		FoodName:    aiFood.Name,
-		Quantity:    item.Quantity,
-		Unit:        item.Unit,
-		Confidence:  item.Confidence * 0.8, // Reduce confidence for AI-generated foods
-		AIGenerated: true,
-	}, nil
+		FoodID:        &food.ID,
+		FoodName:      food.Name,
+		Quantity:      item.Quantity,
+		Unit:          item.Unit,
+		Confidence:    confidence,
+		AIGenerated:   aiGenerated,
+		Calories:      portion.Calories,
+		Protein:       portion.Protein,
+		Carbohydrates: portion.Carbohydrates,
+		Fat:           portion.Fat,
+	}
+}
+
+// compareWithTypical totals the parsed meal and, when a comparison service is
+// set, compares it with the user's typical meal of its type. A failed
+// comparison is logged and left out; it never fails the parse.
+func (s *MealParserService) compareWithTypical(ctx context.Context, userID uuid.UUID, meal *domain.ParsedMeal) {
+	meal.SumTotals()
+	if s.mealComparison == nil {
+		return
+	}
+
+	comparison, err := s.mealComparison.CompareMeal(ctx, userID.String(), meal.MealType, meal.Totals)
+	if err != nil {
+		requestid.Logf(ctx, "[MealParser] Warning: failed to compare meal with history: %v", err)
+		return
+	}
+	meal.Comparison = comparison
+}
+
+// RememberParsedMeal gives the parsed meal an ID and keeps it for parsedMealTTL so the
+// user can confirm it by that ID
+func (s *MealParserService) RememberParsedMeal(userID uuid.UUID, meal *domain.ParsedMeal) {
+	meal.ID = uuid.New()
+	stored := *meal
+	stored.FoodItems = slices.Clone(meal.FoodItems)
+
+	s.parsedMu.Lock()
+	defer s.parsedMu.Unlock()
+	now := time.Now()
+	for id, pending := range s.parsed {
+		if now.After(pending.expiresAt) {
+			delete(s.parsed, id)
+		}
+	}
+	s.parsed[meal.ID] = pendingParsedMeal{userID: userID, meal: stored, expiresAt: now.Add(parsedMealTTL)}
+}
+
+// GetParsedMeal returns a copy of the user's parsed meal awaiting confirmation.
+// ErrNotFound when it expired, was already confirmed or is being confirmed, or belongs to
+// someone else.
+func (s *MealParserService) GetParsedMeal(userID, parsedMealID uuid.UUID) (*domain.ParsedMeal, error) {
+	s.parsedMu.Lock()
+	defer s.parsedMu.Unlock()
+
+	pending, err := s.pendingParsedMeal(userID, parsedMealID)
+	if err != nil {
+		return nil, err
+	}
+	meal := pending.meal
+	meal.FoodItems = slices.Clone(pending.meal.FoodItems)
+	return &meal, nil
+}
+
+// TakeParsedMeal is GetParsedMeal for a caller about to log the meal. Until the caller
+// forgets or returns it, the meal is hidden from every other caller, so a double tap or a
+// retried confirmation cannot log it twice.
+func (s *MealParserService) TakeParsedMeal(userID, parsedMealID uuid.UUID) (*domain.ParsedMeal, error) {
+	s.parsedMu.Lock()
+	defer s.parsedMu.Unlock()
+
+	pending, err := s.pendingParsedMeal(userID, parsedMealID)
+	if err != nil {
+		return nil, err
+	}
+	pending.taken = true
+	s.parsed[parsedMealID] = pending
+
+	meal := pending.meal
+	meal.FoodItems = slices.Clone(pending.meal.FoodItems)
+	return &meal, nil
+}
+
+// ReturnParsedMeal puts back a meal taken with TakeParsedMeal that could not be logged,
+// so the user can confirm it again
+func (s *MealParserService) ReturnParsedMeal(parsedMealID uuid.UUID) {
+	s.parsedMu.Lock()
+	defer s.parsedMu.Unlock()
+	if pending, ok := s.parsed[parsedMealID]; ok {
+		pending.taken = false
+		s.parsed[parsedMealID] = pending
+	}
+}
+
+// ForgetParsedMeal drops a parsed meal once it has been logged, so it is not logged twice
+func (s *MealParserService) ForgetParsedMeal(parsedMealID uuid.UUID) {
+	s.parsedMu.Lock()
+	defer s.parsedMu.Unlock()
+	delete(s.parsed, parsedMealID)
+}
+
+// pendingParsedMeal looks up a meal that can still be confirmed; the caller holds parsedMu.
+// ErrNotFound when it expired, was taken or belongs to someone else.
+func (s *MealParserService) pendingParsedMeal(userID, parsedMealID uuid.UUID) (pendingParsedMeal, error) {
+	pending, ok := s.parsed[parsedMealID]
+	if !ok || pending.taken || pending.userID != userID || time.Now().After(pending.expiresAt) {
+		return pendingParsedMeal{}, fmt.Errorf("%w: parsed meal not found or expired; parse the meal again", domain.ErrNotFound)
+	}
+	return pending, nil
 }
 
 // matchFoodInDatabase attempts to find a matching food in the database
 func (s *MealParserService) matchFoodInDatabase(ctx context.Context, name string) (*domain.Food, error) {
 	// Search for food using full-text search
-	foods, err := s.foodRepository.SearchFoods(ctx, name, 5)
+	foods, err := s.foodRepository.Search(ctx, name, domain.FoodSearchFilter{}, 5, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -221,14 +632,171 @@ func (s *MealParserService) matchFoodInDatabase(ctx context.Context, name string
 		return nil, fmt.Errorf("no matching foods found")
 	}
 
-	// Return best match (first result from search)
-	// In a production system, we might want to implement fuzzy matching scoring
-	return &foods[0], nil
+	// Return the result whose name is closest to the one extracted
+	food, _ := bestNameMatch(name, foods)
+	return food, nil
+}
+
+// findAIFoodDuplicate looks for an AI-generated food whose name is close enough
+// to foodName to reuse. Candidates are searched word by word so "ripe banana"
+// still finds "Banana". It returns nil when there is none.
+func (s *MealParserService) findAIFoodDuplicate(ctx context.Context, foodName string) (*domain.Food, error) {
+	seen := make(map[uuid.UUID]bool)
+	var candidates []*domain.Food
+	for _, word := range strings.Fields(utils.NormalizeName(foodName)) {
+		foods, err := s.foodRepository.Search(ctx, word, domain.FoodSearchFilter{}, 20, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, food := range foods {
+			if seen[food.ID] || food.Source == nil || *food.Source != "ai_generated" {
+				continue
+			}
+			seen[food.ID] = true
+			candidates = append(candidates, food)
+		}
+	}
+
+	food, score := bestNameMatch(foodName, candidates)
+	if food == nil || score < aiFoodDuplicateThreshold {
+		return nil, nil
+	}
+	return food, nil
+}
+
+// bestNameMatch returns the food whose name is most similar to name, with its
+// similarity score. Ties keep the earlier food.
+func bestNameMatch(name string, foods []*domain.Food) (*domain.Food, float64) {
+	var best *domain.Food
+	bestScore := -1.0
+	for _, food := range foods {
+		if score := utils.NameSimilarity(name, food.Name); score > bestScore {
+			best, bestScore = food, score
+		}
+	}
+	return best, bestScore
 }
 
-// createAIFood creates a new AI-generated food with estimated nutrition
+// createAIFood creates a new AI-generated food with estimated nutrition. An
+// existing AI-generated food with a near-identical name is reused instead, so
+// everyone logging "banana" shares one food and its nutrition.
 func (s *MealParserService) createAIFood(ctx context.Context, userID uuid.UUID, foodName string) (*domain.Food, error) {
-	// Use AI to estimate nutrition per 100g
+	existing, err := s.findAIFoodDuplicate(ctx, foodName)
+	if err != nil {
+		requestid.Logf(ctx, "[MealParser] Duplicate lookup failed for %q: %v", foodName, err)
+	} else if existing != nil {
+		return existing, nil
+	}
+
+	nutrition, err := s.estimateNutrition(ctx, userID, foodName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create food entity matching the actual Food domain model
+	source := "ai_generated"
+	fiber := nutrition.FiberPer100g
+
+	food := &domain.Food{
+		ID:            uuid.New(),
+		Name:          foodName,
+		Brand:         nil, // AI-generated foods have no brand
+		ServingSize:   100.0, // Base serving is 100g
+		ServingUnit:   "g",
+		Calories:      nutrition.CaloriesPer100g,
+		Protein:       nutrition.ProteinPer100g,
+		Carbohydrates: nutrition.CarbsPer100g,
+		Fat:           nutrition.FatPer100g,
+		Fiber:         &fiber,
+		IsVerified:    false,
+		Source:        &source,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	// Save to database
+	if err := s.foodRepository.Create(ctx, food); err != nil {
+		return nil, fmt.Errorf("failed to save AI-generated food: %w", err)
+	}
+
+	return food, nil
+}
+
+// EstimateFood estimates the nutrition of quantity of unit of a freeform food without
+// saving anything. Nutrition comes from the model, or from the built-in table when AI is
+// disabled; a food the table does not know then fails with domain.ErrNotFound. Each
+// food's estimate is cached for foodEstimateCacheTTL.
+func (s *MealParserService) EstimateFood(ctx context.Context, userID uuid.UUID, name string, quantity float64, unit string) (*domain.FoodEstimate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: food name is required", domain.ErrInvalidInput)
+	}
+	if len(name) > domain.MaxFoodEstimateNameLength {
+		return nil, fmt.Errorf("%w: food name must be at most %d characters", domain.ErrInvalidInput, domain.MaxFoodEstimateNameLength)
+	}
+	if quantity < 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", domain.ErrInvalidInput)
+	}
+	if quantity == 0 {
+		quantity = 1
+	}
+	unit = strings.TrimSpace(unit)
+	if unit == "" {
+		unit = "serving"
+	}
+
+	cached, err := s.cachedEstimate(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewFoodEstimate(cached.name, quantity, unit, cached.source, cached.nutrition), nil
+}
+
+// cachedEstimate returns the food's per-100g nutrition, estimating it when it is not
+// cached or its entry has expired
+func (s *MealParserService) cachedEstimate(ctx context.Context, userID uuid.UUID, name string) (cachedFoodEstimate, error) {
+	key := utils.NormalizeName(name)
+	now := time.Now()
+
+	s.estimateMu.Lock()
+	cached, ok := s.estimates[key]
+	s.estimateMu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	cached = cachedFoodEstimate{name: name, source: domain.FoodEstimateSourceAI}
+	if s.aiEnabled {
+		nutrition, err := s.estimateNutrition(ctx, userID, name)
+		if err != nil {
+			return cachedFoodEstimate{}, err
+		}
+		cached.nutrition = *nutrition
+	} else {
+		builtinName, nutrition, ok := lookupBuiltinNutrition(name)
+		if !ok {
+			return cachedFoodEstimate{}, fmt.Errorf("%w: no nutrition estimate for %q", domain.ErrNotFound, name)
+		}
+		cached.name = strings.ToUpper(builtinName[:1]) + builtinName[1:]
+		cached.source = domain.FoodEstimateSourceBuiltin
+		cached.nutrition = nutrition
+	}
+	cached.expiresAt = now.Add(foodEstimateCacheTTL)
+
+	s.estimateMu.Lock()
+	for k, entry := range s.estimates {
+		if !now.Before(entry.expiresAt) {
+			delete(s.estimates, k)
+		}
+	}
+	s.estimates[key] = cached
+	s.estimateMu.Unlock()
+	return cached, nil
+}
+
+// estimateNutrition asks the model for the food's nutrition per 100g and the weight of
+// a typical serving
+func (s *MealParserService) estimateNutrition(ctx context.Context, userID uuid.UUID, foodName string) (*domain.NutritionEstimate, error) {
 	systemPrompt := `You are a nutrition expert. Estimate the nutrition information per 100g for the given food.
 Return a JSON object with:
 {
@@ -236,7 +804,8 @@ Return a JSON object with:
   "protein": numeric_value_in_grams,
   "carbs": numeric_value_in_grams,
   "fat": numeric_value_in_grams,
-  "fiber": numeric_value_in_grams
+  "fiber": numeric_value_in_grams,
+  "serving_grams": weight_in_grams_of_one_typical_piece_or_serving
 }
 
 Provide realistic estimates based on typical nutrition values for this type of food.`
@@ -246,7 +815,8 @@ Provide realistic estimates based on typical nutrition values for this type of f
 		{Role: "user", Content: fmt.Sprintf("Food: %s", foodName)},
 	}
 
-	resp, err := s.openRouterClient.Chat(ctx, messages, "deepseek/deepseek-chat")
+	ctx = external.WithAuditContext(ctx, external.AuditOperationFoodEstimate, userID)
+	resp, err := s.openRouterClient.ChatWithOptions(ctx, messages, "deepseek/deepseek-chat", external.StructuredOutputOptions().WithTemperature(s.temperature))
 	if err != nil {
 		return nil, fmt.Errorf("failed to estimate nutrition: %w", err)
 	}
@@ -255,42 +825,47 @@ Provide realistic estimates based on typical nutrition values for this type of f
 		return nil, fmt.Errorf("no response from AI")
 	}
 
-	response := resp.Choices[0].Message.Content
-
-	// Parse nutrition estimate
 	var nutrition struct {
-		Calories float64 `json:"calories"`
-		Protein  float64 `json:"protein"`
-		Carbs    float64 `json:"carbs"`
-		Fat      float64 `json:"fat"`
-		Fiber    float64 `json:"fiber"`
+		Calories     float64 `json:"calories"`
+		Protein      float64 `json:"protein"`
+		Carbs        float64 `json:"carbs"`
+		Fat          float64 `json:"fat"`
+		Fiber        float64 `json:"fiber"`
+		ServingGrams float64 `json:"serving_grams"`
 	}
-
-	// Clean response
-	cleanedResponse := strings.TrimSpace(response)
-	cleanedResponse = strings.TrimPrefix(cleanedResponse, "```json")
-	cleanedResponse = strings.TrimPrefix(cleanedResponse, "```")
-	cleanedResponse = strings.TrimSuffix(cleanedResponse, "```")
-	cleanedResponse = strings.TrimSpace(cleanedResponse)
-
-	if err := json.Unmarshal([]byte(cleanedResponse), &nutrition); err != nil {
+	if err := external.DecodeJSONContent(resp.Choices[0].Message.Content, &nutrition); err != nil {
 		return nil, fmt.Errorf("failed to parse nutrition estimate: %w", err)
 	}
 
-	// Create food entity matching the actual Food domain model
-	source := "ai_generated"
-	fiber := nutrition.Fiber
+	return &domain.NutritionEstimate{
+		CaloriesPer100g: nutrition.Calories,
+		ProteinPer100g:  nutrition.Protein,
+		CarbsPer100g:    nutrition.Carbs,
+		FatPer100g:      nutrition.Fat,
+		FiberPer100g:    nutrition.Fiber,
+		GramsPerServing: nutrition.ServingGrams,
+	}, nil
+}
 
+// createBuiltinFood saves a food with nutrition from the built-in table, so later parses
+// match it in the database
+func (s *MealParserService) createBuiltinFood(ctx context.Context, foodName string) (*domain.Food, error) {
+	name, nutrition, ok := lookupBuiltinNutrition(foodName)
+	if !ok {
+		return nil, errFoodNotResolved
+	}
+
+	source := "builtin"
+	fiber := nutrition.FiberPer100g
 	food := &domain.Food{
 		ID:            uuid.New(),
-		Name:          foodName,
-		Brand:         nil, // AI-generated foods have no brand
-		ServingSize:   100.0, // Base serving is 100g
+		Name:          strings.ToUpper(name[:1]) + name[1:],
+		ServingSize:   100.0,
 		ServingUnit:   "g",
-		Calories:      nutrition.Calories,
-		Protein:       nutrition.Protein,
-		Carbohydrates: nutrition.Carbs,
-		Fat:           nutrition.Fat,
+		Calories:      nutrition.CaloriesPer100g,
+		Protein:       nutrition.ProteinPer100g,
+		Carbohydrates: nutrition.CarbsPer100g,
+		Fat:           nutrition.FatPer100g,
 		Fiber:         &fiber,
 		IsVerified:    false,
 		Source:        &source,
@@ -298,11 +873,9 @@ Provide realistic estimates based on typical nutrition values for this type of f
 		UpdatedAt:     time.Now(),
 	}
 
-	// Save to database
 	if err := s.foodRepository.Create(ctx, food); err != nil {
-		return nil, fmt.Errorf("failed to save AI-generated food: %w", err)
+		return nil, fmt.Errorf("failed to save built-in food: %w", err)
 	}
-
 	return food, nil
 }
 
@@ -312,14 +885,14 @@ func (s *MealParserService) inferMealType(t time.Time) string {
 
 	switch {
 	case hour >= 5 && hour < 11:
-		return "breakfast"
+		return domain.MealTypeBreakfast
 	case hour >= 11 && hour < 15:
-		return "lunch"
+		return domain.MealTypeLunch
 	case hour >= 15 && hour < 18:
-		return "snack"
+		return domain.MealTypeSnack
 	case hour >= 18 || hour < 5:
-		return "dinner"
+		return domain.MealTypeDinner
 	default:
-		return "snack"
+		return domain.MealTypeSnack
 	}
 }
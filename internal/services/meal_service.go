@@ -2,24 +2,36 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
 )
 
 type mealService struct {
-	mealRepo ports.MealRepository
-	foodRepo ports.FoodRepository
+	mealRepo       ports.MealRepository
+	foodRepo       ports.FoodRepository
+	actionRepo     ports.UserActionRepository
+	summaryService ports.SummaryService
+	transactor     ports.Transactor
+	portionLimits  *domain.PortionLimits
 }
 
-// NewMealService creates a new meal service
-func NewMealService(mealRepo ports.MealRepository, foodRepo ports.FoodRepository) ports.MealService {
+// NewMealService creates a new meal service. Items logged above portionLimits are flagged
+// in the created meal's PortionWarnings; nil portionLimits turns the check off.
+func NewMealService(mealRepo ports.MealRepository, foodRepo ports.FoodRepository, actionRepo ports.UserActionRepository, summaryService ports.SummaryService, transactor ports.Transactor, portionLimits *domain.PortionLimits) ports.MealService {
 	return &mealService{
-		mealRepo: mealRepo,
-		foodRepo: foodRepo,
+		mealRepo:       mealRepo,
+		foodRepo:       foodRepo,
+		actionRepo:     actionRepo,
+		summaryService: summaryService,
+		transactor:     transactor,
+		portionLimits:  portionLimits,
 	}
 }
 
@@ -36,6 +48,34 @@ func (s *mealService) GetMeals(ctx context.Context, userID string, date *time.Ti
 	return meals, nil
 }
 
+// ListMealsPage fetches one row past the page so it can tell whether another page follows
+func (s *mealService) ListMealsPage(ctx context.Context, userID, cursor string, limit int) (*domain.MealPage, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	after, err := domain.DecodePageCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", domain.ErrInvalidInput)
+	}
+
+	meals, err := s.mealRepo.ListByUserAfter(ctx, userUUID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meals: %w", err)
+	}
+
+	page := &domain.MealPage{Meals: meals}
+	if len(meals) > limit {
+		page.Meals = meals[:limit]
+		last := page.Meals[limit-1]
+		page.NextCursor = domain.NewPageCursor(last.CreatedAt, last.ID).Encode()
+	}
+	return page, nil
+}
+
 func (s *mealService) GetMeal(ctx context.Context, mealID string) (*domain.Meal, error) {
 	if mealID == "" {
 		return nil, domain.ErrInvalidInput
@@ -43,7 +83,7 @@ func (s *mealService) GetMeal(ctx context.Context, mealID string) (*domain.Meal,
 
 	meal, err := s.mealRepo.GetByID(ctx, mealID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get meal: %w", err)
@@ -66,71 +106,83 @@ func (s *mealService) CreateMeal(ctx context.Context, userID string, mealData *d
 	if mealData.ConsumedAt.IsZero() {
 		mealData.ConsumedAt = time.Now()
 	}
-
-	// Validate meal type
-	validTypes := map[string]bool{
-		"breakfast": true,
-		"lunch":     true,
-		"dinner":    true,
-		"snack":     true,
+	if err := domain.CheckLoggedAt("consumed_at", mealData.ConsumedAt, time.Now()); err != nil {
+		return nil, err
 	}
-	if !validTypes[mealData.MealType] {
-		return nil, domain.ErrInvalidInput
+
+	// Meal types are free-form labels; summaries group them by canonical type
+	mealType, err := domain.NormalizeMealType(mealData.MealType)
+	if err != nil {
+		return nil, err
 	}
+	mealData.MealType = mealType
+
+	// The meal, its items and its totals are written together, so a failed item leaves
+	// no meal behind
+	items := mealData.FoodItems
+	mealData.FoodItems = nil
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.mealRepo.Create(ctx, mealData); err != nil {
+			return fmt.Errorf("failed to create meal: %w", err)
+		}
 
-	// Create meal
-	if err := s.mealRepo.Create(ctx, mealData); err != nil {
-		return nil, fmt.Errorf("failed to create meal: %w", err)
+		for i := range items {
+			items[i].MealID = mealData.ID
+			if err := s.mealRepo.AddFoodItem(ctx, &items[i]); err != nil {
+				return fmt.Errorf("failed to add food item %d: %w", i, err)
+			}
+		}
+
+		mealData.FoodItems = items
+		mealData.RecalculateTotals()
+		if err := s.mealRepo.UpdateTotals(ctx, mealData); err != nil {
+			return fmt.Errorf("failed to update meal totals: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		mealData.FoodItems = items
+		return nil, err
 	}
+	recordAction(ctx, s.actionRepo, mealData.UserID, domain.ActionCreate, domain.ActionEntityMeal, mealData.ID)
+	refreshDailySummaries(ctx, s.summaryService, mealData.UserID, mealData.ConsumedAt)
+	mealData.PortionWarnings = s.portionWarnings(ctx, mealData.FoodItems)
 
 	return mealData, nil
 }
 
-func (s *mealService) ConfirmParsedMeal(ctx context.Context, userID string, parsedMeal *domain.Meal) (*domain.Meal, error) {
-	if userID == "" || parsedMeal == nil {
+// ConfirmParsedMeal logs a parsed meal's food items with each food's current nutrition.
+// Every item must have matched a food; items the user does not want should be excluded
+// from the parsed meal first.
+func (s *mealService) ConfirmParsedMeal(ctx context.Context, userID string, parsedMeal *domain.ParsedMeal) (*domain.Meal, error) {
+	if userID == "" || parsedMeal == nil || len(parsedMeal.FoodItems) == 0 {
 		return nil, domain.ErrInvalidInput
 	}
 
-	// Set defaults
-	parsedMeal.ID = uuid.New().String()
-	parsedMeal.UserID = userID
-	parsedMeal.Source = "ai_parsed"
-
-	if parsedMeal.ConsumedAt.IsZero() {
-		parsedMeal.ConsumedAt = time.Now()
-	}
-
-	// Validate and process food items
-	for i, item := range parsedMeal.Items {
-		if item.FoodID == "" {
-			return nil, domain.ErrInvalidInput
+	names := make([]string, len(parsedMeal.FoodItems))
+	items := make([]domain.MealFoodItem, len(parsedMeal.FoodItems))
+	for i, parsed := range parsedMeal.FoodItems {
+		if parsed.FoodID == nil {
+			return nil, fmt.Errorf("%w: %q matched no food; exclude it or log it separately", domain.ErrInvalidInput, parsed.FoodName)
 		}
 
-		// Verify food exists
-		food, err := s.foodRepo.GetByID(ctx, item.FoodID)
+		food, err := s.foodRepo.GetByID(ctx, *parsed.FoodID)
 		if err != nil {
 			return nil, fmt.Errorf("food item %d not found: %w", i, err)
 		}
 
-		// Set item ID if not present
-		if item.ID == "" {
-			item.ID = uuid.New().String()
-		}
-		item.MealID = parsedMeal.ID
-
-		// Calculate nutrition based on quantity
-		item.Calories = food.Calories * item.Quantity
-		item.Protein = food.Protein * item.Quantity
-		item.Carbs = food.Carbs * item.Quantity
-		item.Fat = food.Fat * item.Quantity
+		items[i] = domain.MealFoodItem{FoodID: food.ID, Quantity: parsed.Quantity, Unit: parsed.Unit}
+		items[i].ApplySnapshot(food)
+		names[i] = food.Name
 	}
 
-	// Create meal
-	if err := s.mealRepo.Create(ctx, parsedMeal); err != nil {
-		return nil, fmt.Errorf("failed to create parsed meal: %w", err)
+	meal := &domain.Meal{
+		Name:       strings.Join(names, ", "),
+		MealType:   parsedMeal.MealType,
+		ConsumedAt: parsedMeal.LoggedAt,
+		FoodItems:  items,
 	}
-
-	return parsedMeal, nil
+	return s.CreateMeal(ctx, userID, meal)
 }
 
 func (s *mealService) UpdateMeal(ctx context.Context, mealID string, updates map[string]interface{}) (*domain.Meal, error) {
@@ -141,7 +193,7 @@ func (s *mealService) UpdateMeal(ctx context.Context, mealID string, updates map
 	// Verify meal exists
 	existing, err := s.mealRepo.GetByID(ctx, mealID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get meal: %w", err)
@@ -149,14 +201,20 @@ func (s *mealService) UpdateMeal(ctx context.Context, mealID string, updates map
 
 	// Validate meal type if being updated
 	if mealType, ok := updates["meal_type"].(string); ok {
-		validTypes := map[string]bool{
-			"breakfast": true,
-			"lunch":     true,
-			"dinner":    true,
-			"snack":     true,
+		normalized, err := domain.NormalizeMealType(mealType)
+		if err != nil {
+			return nil, err
 		}
-		if !validTypes[mealType] {
-			return nil, domain.ErrInvalidInput
+		updates["meal_type"] = normalized
+	}
+
+	consumedAt, consumedAtChanged, err := timeUpdate(updates, "consumed_at")
+	if err != nil {
+		return nil, err
+	}
+	if consumedAtChanged {
+		if err := domain.CheckLoggedAt("consumed_at", consumedAt, time.Now()); err != nil {
+			return nil, err
 		}
 	}
 
@@ -165,8 +223,15 @@ func (s *mealService) UpdateMeal(ctx context.Context, mealID string, updates map
 		return nil, fmt.Errorf("failed to update meal: %w", err)
 	}
 
-	// Return updated meal
-	return s.mealRepo.GetByID(ctx, existing.ID)
+	updated, err := s.mealRepo.GetByID(ctx, existing.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meal: %w", err)
+	}
+
+	// Moving a meal to another day changes both days' summaries
+	refreshDailySummaries(ctx, s.summaryService, updated.UserID, existing.ConsumedAt, updated.ConsumedAt)
+
+	return updated, nil
 }
 
 func (s *mealService) DeleteMeal(ctx context.Context, mealID string) error {
@@ -175,9 +240,9 @@ func (s *mealService) DeleteMeal(ctx context.Context, mealID string) error {
 	}
 
 	// Verify meal exists
-	_, err := s.mealRepo.GetByID(ctx, mealID)
+	meal, err := s.mealRepo.GetByID(ctx, mealID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return domain.ErrNotFound
 		}
 		return fmt.Errorf("failed to get meal: %w", err)
@@ -187,10 +252,43 @@ func (s *mealService) DeleteMeal(ctx context.Context, mealID string) error {
 	if err := s.mealRepo.Delete(ctx, mealID); err != nil {
 		return fmt.Errorf("failed to delete meal: %w", err)
 	}
+	recordAction(ctx, s.actionRepo, meal.UserID, domain.ActionDelete, domain.ActionEntityMeal, meal.ID)
+	refreshDailySummaries(ctx, s.summaryService, meal.UserID, meal.ConsumedAt)
 
 	return nil
 }
 
+// portionWarnings checks the saved items against the portion limits of their foods'
+// categories. Checking is best effort: a food that cannot be read is logged and skipped.
+func (s *mealService) portionWarnings(ctx context.Context, items []domain.MealFoodItem) []domain.PortionWarning {
+	if s.portionLimits == nil {
+		return nil
+	}
+
+	categories := make(map[uuid.UUID]string)
+	var warnings []domain.PortionWarning
+	for i := range items {
+		item := &items[i]
+		category, ok := categories[item.FoodID]
+		if !ok {
+			food, err := s.foodRepo.GetByID(ctx, item.FoodID)
+			if err != nil {
+				requestid.Logf(ctx, "[Meals] Failed to get food %s for the portion check: %v", item.FoodID, err)
+				continue
+			}
+			if food.Category != nil {
+				category = *food.Category
+			}
+			categories[item.FoodID] = category
+		}
+
+		if warning := s.portionLimits.Check(item, category); warning != nil {
+			warnings = append(warnings, *warning)
+		}
+	}
+	return warnings
+}
+
 func (s *mealService) CalculateMealNutrition(ctx context.Context, mealID string) (*domain.NutritionTotals, error) {
 	if mealID == "" {
 		return nil, domain.ErrInvalidInput
@@ -199,7 +297,7 @@ func (s *mealService) CalculateMealNutrition(ctx context.Context, mealID string)
 	// Get meal with items
 	meal, err := s.mealRepo.GetByID(ctx, mealID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get meal: %w", err)
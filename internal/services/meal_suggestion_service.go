@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+)
+
+const (
+	// maxSuggestedItems caps how many foods a suggested meal contains
+	maxSuggestedItems = 4
+
+	// suggestionCatalogSize is how many catalog foods are considered besides the user's own
+	suggestionCatalogSize = 200
+
+	// suggestionRecentFoods is how many of the user's recent foods are considered
+	suggestionRecentFoods = 30
+
+	// overshootPenalty weighs going over a target more heavily than falling short
+	overshootPenalty = 2.0
+)
+
+// suggestionPortions are the serving multiples tried for each food
+var suggestionPortions = []float64{0.5, 1, 1.5, 2}
+
+// Keywords matched against food names and categories for dietary restrictions
+var (
+	meatKeywords   = []string{"chicken", "beef", "pork", "turkey", "lamb", "bacon", "ham", "sausage", "fish", "salmon", "tuna", "shrimp", "meat", "veal", "duck"}
+	animalKeywords = []string{"milk", "cheese", "yogurt", "butter", "cream", "egg", "whey", "honey"}
+
+	// Plant foods named after the animal product they replace
+	plantBasedPhrases = []string{
+		"peanut butter", "almond butter", "cashew butter", "apple butter", "cocoa butter",
+		"coconut milk", "almond milk", "soy milk", "oat milk", "rice milk",
+		"coconut cream", "coconut yogurt", "soy yogurt", "cream of tartar",
+	}
+)
+
+type mealSuggestionService struct {
+	foodRepo ports.FoodRepository
+	mealRepo ports.MealRepository
+	userRepo ports.UserRepository
+}
+
+// NewMealSuggestionService creates a new meal suggestion service
+func NewMealSuggestionService(foodRepo ports.FoodRepository, mealRepo ports.MealRepository, userRepo ports.UserRepository) ports.MealSuggestionService {
+	return &mealSuggestionService{
+		foodRepo: foodRepo,
+		mealRepo: mealRepo,
+		userRepo: userRepo,
+	}
+}
+
+// SuggestMeal greedily picks foods and portions that bring the meal closest to the target.
+// Foods the user logged recently are considered alongside verified catalog foods.
+func (s *mealSuggestionService) SuggestMeal(ctx context.Context, userID string, target domain.MacroTargets) (*domain.MealSuggestion, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if target.IsZero() || target.Calories < 0 || target.Protein < 0 || target.Carbohydrates < 0 || target.Fat < 0 {
+		return nil, fmt.Errorf("%w: target must have at least one positive value and no negative values", domain.ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	candidates, err := s.candidateFoods(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := dietaryExclusions(user.DietaryPreferences)
+	filtered := make([]*domain.Food, 0, len(candidates))
+	for _, food := range candidates {
+		if food.Calories <= 0 || food.ServingSize <= 0 || excluded.excludes(food) {
+			continue
+		}
+		filtered = append(filtered, food)
+	}
+
+	return fitMeal(target, filtered), nil
+}
+
+// candidateFoods returns the user's recent foods followed by verified catalog foods, deduplicated
+func (s *mealSuggestionService) candidateFoods(ctx context.Context, userID uuid.UUID) ([]*domain.Food, error) {
+	recent, err := s.mealRepo.ListRecentFoods(ctx, userID, time.Now().Add(-recentFoodsWindow), suggestionRecentFoods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent foods: %w", err)
+	}
+
+	catalog, err := s.foodRepo.ListVerified(ctx, suggestionCatalogSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foods: %w", err)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(recent)+len(catalog))
+	foods := make([]*domain.Food, 0, len(recent)+len(catalog))
+	for _, r := range recent {
+		food := r.Food
+		seen[food.ID] = true
+		foods = append(foods, &food)
+	}
+	for _, food := range catalog {
+		if seen[food.ID] {
+			continue
+		}
+		seen[food.ID] = true
+		foods = append(foods, food)
+	}
+
+	return foods, nil
+}
+
+// fitMeal adds one food portion at a time, keeping the addition that lowers the fit error most,
+// and stops when no addition improves the fit
+func fitMeal(target domain.MacroTargets, foods []*domain.Food) *domain.MealSuggestion {
+	suggestion := &domain.MealSuggestion{
+		Target: target,
+		Items:  []domain.SuggestedFood{},
+	}
+
+	used := make(map[uuid.UUID]bool)
+	for len(suggestion.Items) < maxSuggestedItems {
+		bestErr := fitError(target, suggestion.Totals)
+		var best *domain.SuggestedFood
+
+		for _, food := range foods {
+			if used[food.ID] {
+				continue
+			}
+			for _, portion := range suggestionPortions {
+				item := portionOf(food, portion)
+				if e := fitError(target, addMacros(suggestion.Totals, item)); e < bestErr {
+					bestErr = e
+					candidate := item
+					best = &candidate
+				}
+			}
+		}
+
+		if best == nil {
+			break
+		}
+
+		used[best.Food.ID] = true
+		suggestion.Items = append(suggestion.Items, *best)
+		suggestion.Totals = addMacros(suggestion.Totals, *best)
+	}
+
+	return suggestion
+}
+
+// portionOf scales a food's per-serving nutrition to the given number of servings
+func portionOf(food *domain.Food, servings float64) domain.SuggestedFood {
+	return domain.SuggestedFood{
+		Food:          *food,
+		Quantity:      food.ServingSize * servings,
+		Unit:          food.ServingUnit,
+		Calories:      food.Calories * servings,
+		Protein:       food.Protein * servings,
+		Carbohydrates: food.Carbohydrates * servings,
+		Fat:           food.Fat * servings,
+	}
+}
+
+func addMacros(totals domain.MacroTargets, item domain.SuggestedFood) domain.MacroTargets {
+	return domain.MacroTargets{
+		Calories:      totals.Calories + item.Calories,
+		Protein:       totals.Protein + item.Protein,
+		Carbohydrates: totals.Carbohydrates + item.Carbohydrates,
+		Fat:           totals.Fat + item.Fat,
+	}
+}
+
+// fitError sums the squared relative error for every target that is set
+func fitError(target, totals domain.MacroTargets) float64 {
+	return relativeError(target.Calories, totals.Calories) +
+		relativeError(target.Protein, totals.Protein) +
+		relativeError(target.Carbohydrates, totals.Carbohydrates) +
+		relativeError(target.Fat, totals.Fat)
+}
+
+func relativeError(target, actual float64) float64 {
+	if target <= 0 {
+		return 0
+	}
+	diff := (actual - target) / target
+	if diff > 0 {
+		diff *= overshootPenalty
+	}
+	return math.Pow(diff, 2)
+}
+
+// foodExclusions are the keywords a user's dietary preferences rule out. Keywords match
+// whole words of a food's name or category, or their plural, so "egg" rules out "Boiled
+// Eggs" but not "Eggplant".
+type foodExclusions struct {
+	diet   []string // from vegetarian or vegan; plant foods named after animal products pass
+	listed []string // the user's own exclusions and allergies, matched exactly as given
+}
+
+// dietaryExclusions turns the user's dietary preferences into keywords foods must not match.
+// Supported keys: "vegetarian", "vegan" (bool) and "exclude", "allergies" (list of ingredients).
+func dietaryExclusions(preferences *string) foodExclusions {
+	var excluded foodExclusions
+	if preferences == nil || *preferences == "" {
+		return excluded
+	}
+
+	var prefs map[string]interface{}
+	if err := json.Unmarshal([]byte(*preferences), &prefs); err != nil {
+		return excluded
+	}
+
+	if vegan, _ := prefs["vegan"].(bool); vegan {
+		excluded.diet = append(excluded.diet, meatKeywords...)
+		excluded.diet = append(excluded.diet, animalKeywords...)
+	} else if vegetarian, _ := prefs["vegetarian"].(bool); vegetarian {
+		excluded.diet = append(excluded.diet, meatKeywords...)
+	}
+
+	for _, key := range []string{"exclude", "allergies"} {
+		items, _ := prefs[key].([]interface{})
+		for _, item := range items {
+			if keyword, ok := item.(string); ok && strings.TrimSpace(keyword) != "" {
+				excluded.listed = append(excluded.listed, keyword)
+			}
+		}
+	}
+
+	return excluded
+}
+
+// excludes reports whether the food's name or category matches any excluded keyword
+func (e foodExclusions) excludes(food *domain.Food) bool {
+	text := food.Name
+	if food.Category != nil {
+		text += " " + *food.Category
+	}
+	words := splitWords(text)
+
+	if matchesAny(words, e.listed) {
+		return true
+	}
+	if len(e.diet) == 0 {
+		return false
+	}
+
+	// "Peanut butter" is not dairy: drop plant-based phrases before the diet keywords
+	for _, phrase := range plantBasedPhrases {
+		phraseWords := splitWords(phrase)
+		for at := phraseIndex(words, phraseWords); at >= 0; at = phraseIndex(words, phraseWords) {
+			words = slices.Delete(slices.Clone(words), at, at+len(phraseWords))
+		}
+	}
+	return matchesAny(words, e.diet)
+}
+
+// matchesAny reports whether any keyword appears in words
+func matchesAny(words []string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if phraseIndex(words, splitWords(keyword)) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// phraseIndex returns where the phrase starts in words, or -1. Each word of the phrase
+// also matches its plural.
+func phraseIndex(words, phrase []string) int {
+	if len(phrase) == 0 {
+		return -1
+	}
+	for start := 0; start+len(phrase) <= len(words); start++ {
+		matched := true
+		for i, want := range phrase {
+			word := words[start+i]
+			if word != want && word != want+"s" && word != want+"es" {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return start
+		}
+	}
+	return -1
+}
+
+// splitWords lowercases text and splits it into words of letters and digits
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,14 +13,43 @@ import (
 	"fitness-tracker/internal/core/ports"
 )
 
+const (
+	// metricTrendLimit caps how many measurements a trend returns
+	metricTrendLimit = 1000
+
+	// kgPerPound converts weights logged in pounds
+	kgPerPound = 0.45359237
+)
+
+// metricValueRanges lists the known metric types and bounds a plausible reading of each, in
+// kg for weight and muscle mass; anything outside is taken as a device or entry error
+var metricValueRanges = map[string]struct{ min, max float64 }{
+	"weight":         {20, 400},
+	"body_fat":       {2, 75}, // percent
+	"muscle_mass":    {5, 200},
+	"water":          {0, 20000}, // ml
+	"bmi":            {10, 80},
+	"blood_pressure": {30, 300},  // mmHg
+	"heart_rate":     {25, 250},  // bpm
+	"steps":          {0, 100000},
+	"sleep":          {0, 24}, // hours
+	"other":          {0, math.MaxFloat64},
+}
+
 type metricService struct {
-	metricRepo ports.MetricRepository
+	metricRepo     ports.MetricRepository
+	userRepo       ports.UserRepository
+	actionRepo     ports.UserActionRepository
+	summaryService ports.SummaryService
 }
 
 // NewMetricService creates a new metric service
-func NewMetricService(metricRepo ports.MetricRepository) ports.MetricService {
+func NewMetricService(metricRepo ports.MetricRepository, userRepo ports.UserRepository, actionRepo ports.UserActionRepository, summaryService ports.SummaryService) ports.MetricService {
 	return &metricService{
-		metricRepo: metricRepo,
+		metricRepo:     metricRepo,
+		userRepo:       userRepo,
+		actionRepo:     actionRepo,
+		summaryService: summaryService,
 	}
 }
 
@@ -26,77 +58,167 @@ func (s *metricService) LogMetric(ctx context.Context, userID, metricType string
 		return nil, domain.ErrInvalidInput
 	}
 
-	// Validate metric type
-	validTypes := map[string]bool{
-		"weight":         true,
-		"body_fat":       true,
-		"muscle_mass":    true,
-		"water":          true,
-		"bmi":            true,
-		"blood_pressure": true,
-		"heart_rate":     true,
-		"steps":          true,
-		"sleep":          true,
-		"other":          true,
-	}
-	if !validTypes[metricType] {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
 		return nil, domain.ErrInvalidInput
 	}
 
-	// Validate value
-	if value < 0 {
-		return nil, domain.ErrInvalidInput
+	// Validate metric type and value
+	if err := checkMetricValue(metricType, value, unit); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
 	}
 
 	// Set defaults
 	metric := &domain.Metric{
-		ID:         uuid.New().String(),
-		UserID:     userID,
+		ID:         uuid.New(),
+		UserID:     userUUID,
 		MetricType: metricType,
 		Value:      value,
 		Unit:       unit,
-		RecordedAt: recordedAt,
+		MeasuredAt: recordedAt,
 	}
 
-	if metric.RecordedAt.IsZero() {
-		metric.RecordedAt = time.Now()
+	if metric.MeasuredAt.IsZero() {
+		metric.MeasuredAt = time.Now()
+	}
+	if err := domain.CheckLoggedAt("measured_at", metric.MeasuredAt, time.Now()); err != nil {
+		return nil, err
 	}
 
 	// Create metric
 	if err := s.metricRepo.Create(ctx, metric); err != nil {
 		return nil, fmt.Errorf("failed to log metric: %w", err)
 	}
+	recordAction(ctx, s.actionRepo, metric.UserID, domain.ActionCreate, domain.ActionEntityMetric, metric.ID)
 
 	return metric, nil
 }
 
+// LogMetricBatch logs many readings at once, such as a wearable's daily sync. Each reading
+// is validated on its own and rejected readings are reported by position without failing
+// the rest. Readings with the same type and timestamp as one already logged are skipped,
+// so a sync can safely be retried. Batch readings are not added to the undo history.
+func (s *metricService) LogMetricBatch(ctx context.Context, userID string, entries []domain.MetricEntry) (*domain.MetricBatchResult, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: at least one metric is required", domain.ErrInvalidInput)
+	}
+	if len(entries) > domain.MaxMetricBatchSize {
+		return nil, fmt.Errorf("%w: at most %d metrics per batch", domain.ErrInvalidInput, domain.MaxMetricBatchSize)
+	}
+
+	result := &domain.MetricBatchResult{
+		Received: len(entries),
+		Errors:   []domain.MetricBatchError{},
+	}
+
+	metrics := make([]*domain.Metric, 0, len(entries))
+	for i, entry := range entries {
+		entry.MetricType = strings.TrimSpace(entry.MetricType)
+		entry.Unit = strings.TrimSpace(entry.Unit)
+		if err := validateMetricEntry(entry); err != nil {
+			result.Errors = append(result.Errors, domain.MetricBatchError{
+				Index:      i,
+				MetricType: entry.MetricType,
+				Message:    err.Error(),
+			})
+			continue
+		}
+
+		metrics = append(metrics, &domain.Metric{
+			ID:         uuid.New(),
+			UserID:     userUUID,
+			MetricType: entry.MetricType,
+			Value:      entry.Value,
+			Unit:       entry.Unit,
+			// Stored with microsecond precision; truncating keeps duplicates comparable
+			MeasuredAt: entry.MeasuredAt.Truncate(time.Microsecond),
+			Notes:      entry.Notes,
+		})
+	}
+	result.Failed = len(result.Errors)
+
+	created, err := s.metricRepo.CreateBatch(ctx, metrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log metrics: %w", err)
+	}
+	result.Created = len(created)
+	result.Duplicates = len(metrics) - len(created)
+
+	return result, nil
+}
+
+// validateMetricEntry checks a batch reading's type, unit, timestamp and value range
+func validateMetricEntry(entry domain.MetricEntry) error {
+	if _, ok := metricValueRanges[entry.MetricType]; !ok {
+		return fmt.Errorf("unknown metric type %q", entry.MetricType)
+	}
+	if entry.Unit == "" {
+		return fmt.Errorf("unit is required")
+	}
+	if entry.MeasuredAt.IsZero() {
+		return fmt.Errorf("measured_at is required")
+	}
+	if err := domain.CheckLoggedAt("measured_at", entry.MeasuredAt, time.Now()); err != nil {
+		return err
+	}
+
+	return checkMetricValue(entry.MetricType, entry.Value, entry.Unit)
+}
+
+// checkMetricValue checks a reading against the plausible range of its metric type,
+// converting weights to kg first
+func checkMetricValue(metricType string, value float64, unit string) error {
+	valueRange, ok := metricValueRanges[metricType]
+	if !ok {
+		return fmt.Errorf("unknown metric type %q", metricType)
+	}
+
+	if metricType == "weight" || metricType == "muscle_mass" {
+		value = weightKg(&domain.Metric{Value: value, Unit: unit})
+	}
+	if value < valueRange.min || value > valueRange.max {
+		return fmt.Errorf("%s value %g is outside the plausible range %g to %g", metricType, value, valueRange.min, valueRange.max)
+	}
+	return nil
+}
+
 func (s *metricService) GetMetricTrend(ctx context.Context, userID, metricType string, startDate, endDate *time.Time) ([]*domain.Metric, error) {
 	if userID == "" || metricType == "" {
 		return nil, domain.ErrInvalidInput
 	}
 
 	// Validate metric type
-	validTypes := map[string]bool{
-		"weight":         true,
-		"body_fat":       true,
-		"muscle_mass":    true,
-		"water":          true,
-		"bmi":            true,
-		"blood_pressure": true,
-		"heart_rate":     true,
-		"steps":          true,
-		"sleep":          true,
-		"other":          true,
-	}
-	if !validTypes[metricType] {
+	if _, ok := metricValueRanges[metricType]; !ok {
 		return nil, domain.ErrInvalidInput
 	}
 
-	metrics, err := s.metricRepo.GetByUserAndType(ctx, userID, metricType, startDate, endDate)
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var start, end time.Time
+	if startDate != nil {
+		start = *startDate
+	}
+	if endDate != nil {
+		end = *endDate
+	}
+
+	metrics, err := s.metricRepo.ListByUser(ctx, userUUID, metricType, start, end, metricTrendLimit, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metric trend: %w", err)
 	}
 
+	// Trends read oldest to newest
+	for i, j := 0, len(metrics)-1; i < j; i, j = i+1, j-1 {
+		metrics[i], metrics[j] = metrics[j], metrics[i]
+	}
+
 	return metrics, nil
 }
 
@@ -106,29 +228,210 @@ func (s *metricService) GetLatestMetric(ctx context.Context, userID, metricType
 	}
 
 	// Validate metric type
-	validTypes := map[string]bool{
-		"weight":         true,
-		"body_fat":       true,
-		"muscle_mass":    true,
-		"water":          true,
-		"bmi":            true,
-		"blood_pressure": true,
-		"heart_rate":     true,
-		"steps":          true,
-		"sleep":          true,
-		"other":          true,
-	}
-	if !validTypes[metricType] {
+	if _, ok := metricValueRanges[metricType]; !ok {
+		return nil, domain.ErrInvalidInput
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	metrics, err := s.metricRepo.ListByUser(ctx, userUUID, metricType, time.Time{}, time.Time{}, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest metric: %w", err)
+	}
+	if len(metrics) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	return metrics[0], nil
+}
+
+// UpdateMetric corrects a mis-logged measurement and refreshes values derived from it
+func (s *metricService) UpdateMetric(ctx context.Context, userID, metricID string, update *domain.MetricUpdate) (*domain.Metric, error) {
+	metric, err := s.getOwnedMetric(ctx, userID, metricID)
+	if err != nil {
+		return nil, err
+	}
+	previous := *metric
+
+	if update.Value != nil {
+		metric.Value = *update.Value
+	}
+	if update.Unit != nil {
+		if strings.TrimSpace(*update.Unit) == "" {
+			return nil, fmt.Errorf("%w: unit must not be empty", domain.ErrInvalidInput)
+		}
+		metric.Unit = strings.TrimSpace(*update.Unit)
+	}
+	if update.Value != nil || update.Unit != nil {
+		if err := checkMetricValue(metric.MetricType, metric.Value, metric.Unit); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+	}
+	if update.MeasuredAt != nil {
+		if update.MeasuredAt.IsZero() {
+			return nil, fmt.Errorf("%w: measured_at must be set", domain.ErrInvalidInput)
+		}
+		if err := domain.CheckLoggedAt("measured_at", *update.MeasuredAt, time.Now()); err != nil {
+			return nil, err
+		}
+		metric.MeasuredAt = *update.MeasuredAt
+	}
+	if update.Notes != nil {
+		metric.Notes = update.Notes
+	}
+
+	if err := s.metricRepo.Update(ctx, metric); err != nil {
+		return nil, fmt.Errorf("failed to update metric: %w", err)
+	}
+
+	if err := refreshMetricDerived(ctx, s.metricRepo, s.userRepo, s.summaryService, &previous, metric); err != nil {
+		return nil, err
+	}
+
+	return metric, nil
+}
+
+// DeleteMetric removes a measurement and refreshes values derived from it
+func (s *metricService) DeleteMetric(ctx context.Context, userID, metricID string) error {
+	metric, err := s.getOwnedMetric(ctx, userID, metricID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.metricRepo.Delete(ctx, metric.ID); err != nil {
+		return fmt.Errorf("failed to delete metric: %w", err)
+	}
+	recordAction(ctx, s.actionRepo, metric.UserID, domain.ActionDelete, domain.ActionEntityMetric, metric.ID)
+
+	return refreshMetricDerived(ctx, s.metricRepo, s.userRepo, s.summaryService, metric, nil)
+}
+
+// getOwnedMetric loads a metric, reporting metrics owned by other users as not found
+func (s *metricService) getOwnedMetric(ctx context.Context, userID, metricID string) (*domain.Metric, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
 		return nil, domain.ErrInvalidInput
 	}
 
-	metric, err := s.metricRepo.GetLatest(ctx, userID, metricType)
+	metricUUID, err := uuid.Parse(metricID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		return nil, domain.ErrInvalidInput
+	}
+
+	metric, err := s.metricRepo.GetByID(ctx, metricUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to get latest metric: %w", err)
+		return nil, fmt.Errorf("failed to get metric: %w", err)
+	}
+
+	if metric.UserID != userUUID {
+		return nil, domain.ErrNotFound
 	}
 
 	return metric, nil
 }
+
+// refreshMetricDerived brings values computed from a weight or body fat entry back in line after
+// it was changed (current is the new state), deleted (current is nil) or restored (current is the
+// entry itself). This covers the BMI logged alongside a weight, the profile's current weight and
+// the summary of each day it touched.
+func refreshMetricDerived(ctx context.Context, metricRepo ports.MetricRepository, userRepo ports.UserRepository, summaryService ports.SummaryService, previous, current *domain.Metric) error {
+	if previous.MetricType != "weight" && previous.MetricType != "body_fat" {
+		return nil
+	}
+
+	if previous.MetricType == "weight" {
+		if err := refreshPairedBMI(ctx, metricRepo, userRepo, previous, current); err != nil {
+			return err
+		}
+		if err := refreshProfileWeight(ctx, metricRepo, userRepo, previous.UserID); err != nil {
+			return err
+		}
+	}
+
+	days := []time.Time{previous.MeasuredAt}
+	if current != nil && !current.MeasuredAt.Equal(previous.MeasuredAt) {
+		days = append(days, current.MeasuredAt)
+	}
+	for _, day := range days {
+		if _, err := summaryService.RefreshDailySummary(ctx, previous.UserID.String(), day); err != nil {
+			return fmt.Errorf("failed to refresh daily summary: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// refreshPairedBMI recomputes or removes the BMI entry recorded at the same moment as a weight
+func refreshPairedBMI(ctx context.Context, metricRepo ports.MetricRepository, userRepo ports.UserRepository, previous, current *domain.Metric) error {
+	bmis, err := metricRepo.ListByUser(ctx, previous.UserID, "bmi", previous.MeasuredAt, previous.MeasuredAt, 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get bmi: %w", err)
+	}
+	if len(bmis) == 0 {
+		return nil
+	}
+	bmi := bmis[0]
+
+	if current == nil {
+		if err := metricRepo.Delete(ctx, bmi.ID); err != nil {
+			return fmt.Errorf("failed to delete bmi: %w", err)
+		}
+		return nil
+	}
+
+	user, err := userRepo.GetByID(ctx, previous.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.HeightCm == nil || *user.HeightCm <= 0 {
+		return nil
+	}
+
+	heightM := *user.HeightCm / 100
+	bmi.Value = math.Round(weightKg(current)/(heightM*heightM)*10) / 10
+	bmi.MeasuredAt = current.MeasuredAt
+	if err := metricRepo.Update(ctx, bmi); err != nil {
+		return fmt.Errorf("failed to update bmi: %w", err)
+	}
+
+	return nil
+}
+
+// refreshProfileWeight sets the profile's current weight to the latest remaining weight entry
+func refreshProfileWeight(ctx context.Context, metricRepo ports.MetricRepository, userRepo ports.UserRepository, userID uuid.UUID) error {
+	latest, err := metricRepo.ListByUser(ctx, userID, "weight", time.Time{}, time.Time{}, 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get latest weight: %w", err)
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+
+	user, err := userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	weight := math.Round(weightKg(latest[0])*100) / 100
+	user.WeightKg = &weight
+	if err := userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update profile weight: %w", err)
+	}
+
+	return nil
+}
+
+// weightKg returns a weight entry's value in kilograms
+func weightKg(metric *domain.Metric) float64 {
+	switch strings.ToLower(metric.Unit) {
+	case "lb", "lbs", "pound", "pounds":
+		return metric.Value * kgPerPound
+	}
+	return metric.Value
+}
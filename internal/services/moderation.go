@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fitness-tracker/internal/core/ports"
+)
+
+// errOutputFlagged is returned for AI output a moderator flagged
+var errOutputFlagged = errors.New("output flagged by moderation")
+
+// moderateOutput screens AI output before it is stored or shown; a nil moderator screens
+// nothing. A moderator failure is an error like a flag, so callers fall back to safe text
+// rather than show output that was never screened.
+func moderateOutput(ctx context.Context, moderator ports.ContentModerator, text string) error {
+	if moderator == nil {
+		return nil
+	}
+
+	result, err := moderator.Moderate(ctx, text)
+	if err != nil {
+		return fmt.Errorf("failed to moderate output: %w", err)
+	}
+	if result.Flagged {
+		return fmt.Errorf("%w: %s", errOutputFlagged, strings.Join(result.Categories, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// photoRetentionBatchSize is how many users are loaded per page during a cleanup run
+const photoRetentionBatchSize = 100
+
+type photoRetentionService struct {
+	userRepo     ports.UserRepository
+	mealRepo     ports.MealRepository
+	photoStorage ports.PhotoStorage
+	retention    time.Duration
+	dryRun       bool
+}
+
+// NewPhotoRetentionService creates a service that deletes photos older than retention whose
+// meal was deleted. In dry-run mode it only logs what would be deleted.
+func NewPhotoRetentionService(
+	userRepo ports.UserRepository,
+	mealRepo ports.MealRepository,
+	photoStorage ports.PhotoStorage,
+	retention time.Duration,
+	dryRun bool,
+) ports.PhotoRetentionService {
+	return &photoRetentionService{
+		userRepo:     userRepo,
+		mealRepo:     mealRepo,
+		photoStorage: photoStorage,
+		retention:    retention,
+		dryRun:       dryRun,
+	}
+}
+
+// Cleanup scans every user's photos and removes expired ones whose meal was deleted.
+// A failure for one user is logged and does not stop the run.
+func (s *photoRetentionService) Cleanup(ctx context.Context) (*domain.PhotoCleanupReport, error) {
+	if s.retention <= 0 {
+		return nil, fmt.Errorf("%w: retention must be positive", domain.ErrInvalidInput)
+	}
+
+	report := &domain.PhotoCleanupReport{
+		DryRun:  s.dryRun,
+		Deleted: []string{},
+	}
+	cutoff := time.Now().Add(-s.retention)
+
+	for offset := 0; ; offset += photoRetentionBatchSize {
+		users, err := s.userRepo.List(ctx, photoRetentionBatchSize, offset)
+		if err != nil {
+			return report, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if err := s.cleanupUser(ctx, user, cutoff, report); err != nil {
+				requestid.Logf(ctx, "[PhotoRetention] Failed to clean up photos for user %s: %v", user.ID, err)
+				report.Failed++
+			}
+		}
+
+		if len(users) < photoRetentionBatchSize {
+			break
+		}
+	}
+
+	requestid.Logf(ctx, "[PhotoRetention] Scanned %d photos for %d users, %s %d (dry_run=%t, failed=%d)",
+		report.PhotosScanned, report.UsersScanned, deletedVerb(s.dryRun), len(report.Deleted), s.dryRun, report.Failed)
+
+	return report, nil
+}
+
+// cleanupUser deletes the user's photos created before cutoff whose meal was deleted. A photo
+// no meal records is kept, since it cannot be told apart from the photo of a live meal.
+func (s *photoRetentionService) cleanupUser(ctx context.Context, user *domain.User, cutoff time.Time, report *domain.PhotoCleanupReport) error {
+	report.UsersScanned++
+
+	photos, err := s.photoStorage.ListImages(ctx, user.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to list photos: %w", err)
+	}
+	report.PhotosScanned += len(photos)
+
+	var expired []string
+	for _, photo := range photos {
+		if photo.CreatedAt.Before(cutoff) {
+			expired = append(expired, photo.Path)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	deletable, err := s.mealRepo.ListDeletedMealPhotoPaths(ctx, user.ID, expired)
+	if err != nil {
+		return fmt.Errorf("failed to check photo references: %w", err)
+	}
+
+	for _, path := range deletable {
+		if s.dryRun {
+			requestid.Logf(ctx, "[PhotoRetention] Dry run: would delete %s", path)
+			report.Deleted = append(report.Deleted, path)
+			continue
+		}
+
+		if err := s.photoStorage.DeleteImage(ctx, path); err != nil {
+			requestid.Logf(ctx, "[PhotoRetention] Failed to delete %s: %v", path, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted = append(report.Deleted, path)
+	}
+
+	return nil
+}
+
+func deletedVerb(dryRun bool) string {
+	if dryRun {
+		return "would delete"
+	}
+	return "deleted"
+}
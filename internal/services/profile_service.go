@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/utils"
+)
+
+// Bounds for a manually set nutrition target
+const (
+	maxTargetCalories = 10000.0
+	maxTargetMacro    = 1000.0  // g
+	maxTargetWaterMl  = 10000.0 // ml
+)
+
+type profileService struct {
+	userRepo         ports.UserRepository
+	goalRepo         ports.GoalRepository
+	targetRepo       ports.NutritionTargetRepository
+	distributionRepo ports.MealDistributionRepository
+	macroPresets     domain.MacroPresets
+}
+
+// NewProfileService creates a new profile service. Targets derived for a goal type use
+// macroPresets, or domain.DefaultMacroPresets when nil.
+func NewProfileService(
+	userRepo ports.UserRepository,
+	goalRepo ports.GoalRepository,
+	targetRepo ports.NutritionTargetRepository,
+	distributionRepo ports.MealDistributionRepository,
+	macroPresets domain.MacroPresets,
+) ports.ProfileService {
+	if macroPresets == nil {
+		macroPresets = domain.DefaultMacroPresets
+	}
+	return &profileService{
+		userRepo:         userRepo,
+		goalRepo:         goalRepo,
+		targetRepo:       targetRepo,
+		distributionRepo: distributionRepo,
+		macroPresets:     macroPresets,
+	}
+}
+
+func (s *profileService) GetProfile(ctx context.Context, userID string) (*domain.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+func (s *profileService) UpdateProfile(ctx context.Context, userID string, update *domain.ProfileUpdate) (*domain.User, error) {
+	if update == nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if result := validateProfileUpdate(update); !result.Valid {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, strings.Join(result.Errors, "; "))
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	if update.Name != nil {
+		nameParts := strings.SplitN(strings.TrimSpace(*update.Name), " ", 2)
+		user.FirstName = nameParts[0]
+		user.LastName = ""
+		if len(nameParts) > 1 {
+			user.LastName = nameParts[1]
+		}
+	}
+	if update.HeightCm != nil {
+		user.HeightCm = update.HeightCm
+	}
+	if update.WeightKg != nil {
+		user.WeightKg = update.WeightKg
+	}
+	if update.ActivityLevel != nil {
+		user.ActivityLevel = update.ActivityLevel
+	}
+	if update.Timezone != nil {
+		user.Timezone = *update.Timezone
+	}
+	if update.UnitSystem != nil {
+		user.UnitSystem = *update.UnitSystem
+	}
+	if update.CalorieTargetMode != nil {
+		user.CalorieTargetMode = *update.CalorieTargetMode
+	}
+	if update.ExerciseCalorieFraction != nil {
+		user.ExerciseCalorieFraction = update.ExerciseCalorieFraction
+	}
+	if update.DietaryPreferences != nil {
+		data, err := json.Marshal(update.DietaryPreferences)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid dietary preferences", domain.ErrInvalidInput)
+		}
+		preferences := string(data)
+		user.DietaryPreferences = &preferences
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// GetNutritionTargets returns the user's daily nutrition target, stored or default
+func (s *profileService) GetNutritionTargets(ctx context.Context, userID string) (*domain.NutritionTarget, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return resolveNutritionTarget(ctx, s.targetRepo, s.goalRepo, id)
+}
+
+// SetNutritionTargets stores a manual nutrition target, replacing any previous one. Water
+// defaults to DefaultWaterTargetMl when not given. Macros that do not add up to the
+// calories are saved with a warning.
+func (s *profileService) SetNutritionTargets(ctx context.Context, userID string, target *domain.NutritionTarget) (*domain.NutritionTarget, error) {
+	if target == nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if result := validateNutritionTarget(target); !result.Valid {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, strings.Join(result.Errors, "; "))
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	target.UserID = id
+	target.Source = domain.NutritionTargetSourceManual
+	if target.WaterMl == 0 {
+		target.WaterMl = domain.DefaultWaterTargetMl
+	}
+
+	return s.saveNutritionTarget(ctx, target)
+}
+
+// DeriveNutritionTargets stores a nutrition target derived from the profile's height,
+// weight, age, sex and activity level, replacing any previous one
+func (s *profileService) DeriveNutritionTargets(ctx context.Context, userID, goalType string) (*domain.NutritionTarget, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	target := domain.DeriveNutritionTarget(user, time.Now())
+	if target == nil {
+		return nil, fmt.Errorf("%w: height, weight and date of birth are required to derive targets", domain.ErrInvalidInput)
+	}
+
+	if goalType != "" {
+		// Same shape as the profile's dietary_preferences; a malformed value adjusts nothing
+		var preferences map[string]interface{}
+		if user.DietaryPreferences != nil {
+			_ = json.Unmarshal([]byte(*user.DietaryPreferences), &preferences)
+		}
+		preset := s.macroPresets.Choose(goalType, preferences)
+		macros := preset.Targets(target.Calories)
+		target.Protein = macros.Protein
+		target.Carbohydrates = macros.Carbohydrates
+		target.Fat = macros.Fat
+		target.MacroPreset = &preset
+	}
+
+	return s.saveNutritionTarget(ctx, target)
+}
+
+func (s *profileService) saveNutritionTarget(ctx context.Context, target *domain.NutritionTarget) (*domain.NutritionTarget, error) {
+	if err := s.targetRepo.Upsert(ctx, target); err != nil {
+		return nil, fmt.Errorf("failed to save nutrition target: %w", err)
+	}
+
+	if warning := domain.CheckMacroSplit(target.Macros()); warning != "" {
+		target.Warnings = []string{warning}
+	}
+	return target, nil
+}
+
+// GetMealDistribution returns the user's split of the day's target between meal types,
+// stored or default
+func (s *profileService) GetMealDistribution(ctx context.Context, userID string) (*domain.MealDistribution, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return resolveMealDistribution(ctx, s.distributionRepo, id)
+}
+
+// SetMealDistribution stores the user's split of the day's target between meal types,
+// replacing any previous one. The shares must add up to 100 percent.
+func (s *profileService) SetMealDistribution(ctx context.Context, userID string, distribution *domain.MealDistribution) (*domain.MealDistribution, error) {
+	if distribution == nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if err := distribution.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	distribution.UserID = id
+	distribution.Source = domain.MealDistributionSourceManual
+	if err := s.distributionRepo.Upsert(ctx, distribution); err != nil {
+		return nil, fmt.Errorf("failed to save meal distribution: %w", err)
+	}
+	return distribution, nil
+}
+
+// validateNutritionTarget checks a manually set target is within plausible bounds
+func validateNutritionTarget(target *domain.NutritionTarget) *utils.ValidationResult {
+	result := utils.NewValidationResult()
+
+	if target.Calories <= 0 || target.Calories > maxTargetCalories {
+		result.AddError(fmt.Sprintf("Calories must be greater than 0 and at most %.0f", maxTargetCalories))
+	}
+	for _, macro := range []struct {
+		name  string
+		grams float64
+	}{
+		{"Protein", target.Protein},
+		{"Carbohydrates", target.Carbohydrates},
+		{"Fat", target.Fat},
+	} {
+		if macro.grams < 0 || macro.grams > maxTargetMacro {
+			result.AddError(fmt.Sprintf("%s must be between 0 and %.0f g", macro.name, maxTargetMacro))
+		}
+	}
+	if target.WaterMl < 0 || target.WaterMl > maxTargetWaterMl {
+		result.AddError(fmt.Sprintf("Water must be between 0 and %.0f ml", maxTargetWaterMl))
+	}
+
+	return result
+}
+
+// validateProfileUpdate checks each provided field with the shared validators
+func validateProfileUpdate(update *domain.ProfileUpdate) *utils.ValidationResult {
+	result := utils.NewValidationResult()
+
+	if update.Name != nil && !utils.ValidateStringLength(*update.Name, 2, 200) {
+		result.AddError("Name must be between 2 and 200 characters")
+	}
+	if update.HeightCm != nil && !utils.ValidateHeight(*update.HeightCm) {
+		result.AddError("Height must be between 50 and 300 cm")
+	}
+	if update.WeightKg != nil && !utils.ValidateWeight(*update.WeightKg) {
+		result.AddError("Weight must be between 20 and 500 kg")
+	}
+	if update.ActivityLevel != nil && !utils.ValidateEnum(*update.ActivityLevel, domain.ActivityLevels) {
+		result.AddError("Activity level must be one of: " + strings.Join(domain.ActivityLevels, ", "))
+	}
+	if update.UnitSystem != nil && !utils.ValidateEnum(*update.UnitSystem, domain.UnitSystems) {
+		result.AddError("Unit system must be one of: " + strings.Join(domain.UnitSystems, ", "))
+	}
+	if update.CalorieTargetMode != nil && !utils.ValidateEnum(*update.CalorieTargetMode, domain.CalorieTargetModes) {
+		result.AddError("Calorie target mode must be one of: " + strings.Join(domain.CalorieTargetModes, ", "))
+	}
+	if update.ExerciseCalorieFraction != nil && (*update.ExerciseCalorieFraction < 0 || *update.ExerciseCalorieFraction > 1) {
+		result.AddError("Exercise calorie fraction must be between 0 and 1")
+	}
+	if update.Timezone != nil {
+		// "Local" would load the server's own zone rather than the user's
+		if *update.Timezone == "" || *update.Timezone == "Local" {
+			result.AddError("Timezone must be a valid IANA timezone")
+		} else if _, err := time.LoadLocation(*update.Timezone); err != nil {
+			result.AddError("Timezone must be a valid IANA timezone")
+		}
+	}
+
+	return result
+}
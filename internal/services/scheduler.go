@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// schedulerCheckInterval is how often each instance checks whether a job is due. Checks
+// are cheap, so a job whose last runner went away is picked up within this time.
+const schedulerCheckInterval = time.Minute
+
+// Job is periodic work run by the Scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs every Interval across all instances. Before a run an
+// instance takes the job's advisory lock and checks the shared last run, so only one
+// instance runs a job and it runs once per interval.
+type Scheduler struct {
+	jobRuns ports.JobRunRepository
+	jobs    map[string]Job
+	order   []string
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler. Jobs are registered with Register before Start.
+func NewScheduler(jobRuns ports.JobRunRepository) *Scheduler {
+	return &Scheduler{
+		jobRuns: jobRuns,
+		jobs:    make(map[string]Job),
+	}
+}
+
+// Register adds a job. Names must be unique and intervals positive.
+func (s *Scheduler) Register(job Job) error {
+	switch {
+	case job.Name == "":
+		return fmt.Errorf("%w: job name is required", domain.ErrInvalidInput)
+	case job.Interval <= 0:
+		return fmt.Errorf("%w: job %s needs a positive interval", domain.ErrInvalidInput, job.Name)
+	case job.Run == nil:
+		return fmt.Errorf("%w: job %s has nothing to run", domain.ErrInvalidInput, job.Name)
+	}
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("%w: job %s is already registered", domain.ErrConflict, job.Name)
+	}
+
+	s.jobs[job.Name] = job
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start checks every registered job right away and then periodically until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, name := range s.order {
+		job := s.jobs[name]
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+}
+
+// Wait blocks until the jobs started by Start have stopped
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	check := schedulerCheckInterval
+	if job.Interval < check {
+		check = job.Interval
+	}
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunIfDue(ctx, job.Name); err != nil && ctx.Err() == nil {
+			requestid.Logf(ctx, "[Scheduler] Job %s failed: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunIfDue runs the job when no instance is running it and its interval has passed since
+// the last run started. It reports whether the job ran; err is the job's error, if any.
+func (s *Scheduler) RunIfDue(ctx context.Context, name string) (bool, error) {
+	job, ok := s.jobs[name]
+	if !ok {
+		return false, fmt.Errorf("%w: job %s is not registered", domain.ErrNotFound, name)
+	}
+
+	release, locked, err := s.jobRuns.TryLock(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	defer release()
+
+	last, err := s.jobRuns.GetLastRun(ctx, name)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return false, fmt.Errorf("failed to get last job run: %w", err)
+	}
+	now := time.Now()
+	if !last.Due(job.Interval, now) {
+		return false, nil
+	}
+
+	run := &domain.JobRun{Name: name, StartedAt: now}
+	if err := s.jobRuns.SaveRun(ctx, run); err != nil {
+		return false, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	// Log lines of one run share an ID, like those of a request
+	runCtx := requestid.NewContext(ctx, fmt.Sprintf("job-%s-%s", name, uuid.New().String()))
+	requestid.Logf(runCtx, "[Scheduler] Running job %s", name)
+	runErr := runJob(runCtx, job)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	if runErr != nil {
+		message := runErr.Error()
+		run.Error = &message
+	}
+	// Record the outcome even when shutdown cancelled the run
+	if err := s.jobRuns.SaveRun(context.WithoutCancel(ctx), run); err != nil {
+		requestid.Logf(runCtx, "[Scheduler] Failed to record the end of job %s: %v", name, err)
+	}
+	requestid.Logf(runCtx, "[Scheduler] Job %s finished in %s", name, finished.Sub(now).Round(time.Millisecond))
+
+	return true, runErr
+}
+
+// runJob runs the job, turning a panic into an error so one job cannot stop the others
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -2,29 +2,62 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/google/uuid"
+
 	"fitness-tracker/internal/core/domain"
 	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
 )
 
+// dailySummaryLimit caps how many meals, activities or workouts a single day can contribute
+const dailySummaryLimit = 500
+
 type summaryService struct {
-	mealRepo     ports.MealRepository
-	activityRepo ports.ActivityRepository
-	workoutRepo  ports.WorkoutRepository
+	mealRepo         ports.MealRepository
+	activityRepo     ports.ActivityRepository
+	workoutRepo      ports.WorkoutRepository
+	userRepo         ports.UserRepository
+	goalRepo         ports.GoalRepository
+	targetRepo       ports.NutritionTargetRepository
+	distributionRepo ports.MealDistributionRepository
+	metricRepo       ports.MetricRepository
+
+	// Order in which overlapping sessions' calorie burns are trusted
+	sourcePriority []string
 }
 
-// NewSummaryService creates a new summary service
+// NewSummaryService creates a new summary service. sourcePriority orders the calorie
+// burn sources trusted when sessions overlap; nil uses domain.DefaultCalorieSourcePriority.
 func NewSummaryService(
 	mealRepo ports.MealRepository,
 	activityRepo ports.ActivityRepository,
 	workoutRepo ports.WorkoutRepository,
+	userRepo ports.UserRepository,
+	goalRepo ports.GoalRepository,
+	targetRepo ports.NutritionTargetRepository,
+	distributionRepo ports.MealDistributionRepository,
+	metricRepo ports.MetricRepository,
+	sourcePriority []string,
 ) ports.SummaryService {
+	if len(sourcePriority) == 0 {
+		sourcePriority = domain.DefaultCalorieSourcePriority
+	}
 	return &summaryService{
-		mealRepo:     mealRepo,
-		activityRepo: activityRepo,
-		workoutRepo:  workoutRepo,
+		mealRepo:         mealRepo,
+		activityRepo:     activityRepo,
+		workoutRepo:      workoutRepo,
+		userRepo:         userRepo,
+		goalRepo:         goalRepo,
+		targetRepo:       targetRepo,
+		distributionRepo: distributionRepo,
+		metricRepo:       metricRepo,
+
+		sourcePriority: sourcePriority,
 	}
 }
 
@@ -37,75 +70,475 @@ func (s *summaryService) GetDailySummary(ctx context.Context, userID string, dat
 	return s.CalculateDailySummary(ctx, userID, date)
 }
 
+// CalculateDailySummary totals a day's nutrition and burn. Burned calories include activities
+// and workouts; workouts without a logged burn are estimated from their duration and sets. Time
+// covered by several sessions is only counted once, from the most trusted source.
 func (s *summaryService) CalculateDailySummary(ctx context.Context, userID string, date time.Time) (*domain.DailySummary, error) {
-	if userID == "" {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
 		return nil, domain.ErrInvalidInput
 	}
 
-	// Normalize date to start of day
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Normalize date to start of day; repository ranges are inclusive
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	endOfDay := startOfDay.AddDate(0, 0, 1).Add(-time.Microsecond)
 
 	summary := &domain.DailySummary{
-		UserID: userID,
+		UserID: userUUID,
 		Date:   startOfDay,
-		Nutrition: domain.NutritionTotals{
-			Calories: 0,
-			Protein:  0,
-			Carbs:    0,
-			Fat:      0,
-			Fiber:    0,
-			Sugar:    0,
-			Sodium:   0,
-		},
-		CaloriesBurned:  0,
-		WorkoutsCount:   0,
-		ActivitiesCount: 0,
-		MealsCount:      0,
-	}
-
-	// Get meals for the day
-	meals, err := s.mealRepo.GetByUserAndDate(ctx, userID, &startOfDay)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get meals: %w", err)
 	}
 
 	// Calculate nutrition totals from meals
-	summary.MealsCount = len(meals)
+	meals, err := s.mealRepo.ListByUser(ctx, userUUID, startOfDay, endOfDay, dailySummaryLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meals: %w", err)
+	}
 	for _, meal := range meals {
-		for _, item := range meal.Items {
-			summary.Nutrition.Calories += item.Calories
-			summary.Nutrition.Protein += item.Protein
-			summary.Nutrition.Carbs += item.Carbs
-			summary.Nutrition.Fat += item.Fat
-			summary.Nutrition.Fiber += item.Fiber
-			summary.Nutrition.Sugar += item.Sugar
-			summary.Nutrition.Sodium += item.Sodium
-		}
+		summary.TotalCalories += meal.TotalCalories
+		summary.TotalProtein += meal.TotalProtein
+		summary.TotalCarbohydrates += meal.TotalCarbohydrates
+		summary.TotalFat += meal.TotalFat
+		summary.TotalSaturatedFat += meal.TotalSaturatedFat
+		summary.TotalSodium += meal.TotalSodium
 	}
+	summary.MealGroups = domain.GroupMealsByType(meals)
 
-	// Get activities for the day
-	activities, err := s.activityRepo.GetByUserAndDateRange(ctx, userID, &startOfDay, &endOfDay)
+	// Compare sodium and saturated fat with the user's limit goals
+	goals, err := s.goalRepo.ListByUser(ctx, userUUID, "active", goalListLimit, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activities: %w", err)
+		return nil, fmt.Errorf("failed to get goals: %w", err)
 	}
+	summary.ApplyNutrientLimits(goals)
 
 	// Calculate calories burned from activities
-	summary.ActivitiesCount = len(activities)
+	activities, err := s.activityRepo.ListByUser(ctx, userUUID, startOfDay, endOfDay, dailySummaryLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activities: %w", err)
+	}
+	var burns []domain.BurnEntry
 	for _, activity := range activities {
-		summary.CaloriesBurned += activity.CaloriesBurned
+		if activity.CaloriesBurned != nil {
+			burns = append(burns, activityBurn(activity))
+		}
+		if activity.DurationMinutes != nil {
+			summary.TotalExerciseMinutes += *activity.DurationMinutes
+		}
+		if activity.Steps != nil {
+			summary.TotalSteps += *activity.Steps
+		}
+		if activity.Distance != nil {
+			summary.TotalDistance += *activity.Distance
+		}
 	}
 
-	// Get workouts for the day
-	workouts, err := s.workoutRepo.GetByUserAndDateRange(ctx, userID, &startOfDay, &endOfDay)
+	// Add workouts, estimating the burn when none was logged
+	workouts, err := s.workoutRepo.ListByUser(ctx, userUUID, startOfDay, endOfDay, domain.WorkoutFilter{}, dailySummaryLimit, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workouts: %w", err)
 	}
+	bodyWeight := domain.DefaultBodyWeightKg
+	if user.WeightKg != nil {
+		bodyWeight = *user.WeightKg
+	}
+	for _, workout := range workouts {
+		duration := 0
+		if workout.DurationMinutes != nil {
+			duration = *workout.DurationMinutes
+		} else if workout.EndTime != nil {
+			duration = int(workout.EndTime.Sub(workout.StartTime).Minutes())
+		}
+
+		burns = append(burns, workoutBurn(workout, duration, bodyWeight))
+		summary.TotalExerciseMinutes += duration
+	}
+	summary.TotalCaloriesBurned, summary.BurnDebug = domain.DeduplicateBurn(burns, s.sourcePriority)
+
+	// Energy balance
+	summary.NetCalories = summary.TotalCalories - summary.TotalCaloriesBurned
+	summary.TDEE = domain.CalculateTDEE(user, startOfDay)
+	if summary.TDEE != nil {
+		balance := summary.TotalCalories - *summary.TDEE
+		summary.EnergyBalance = &balance
+	}
+
+	// Remaining calories against the target, raised by exercise in dynamic mode
+	target, err := resolveNutritionTarget(ctx, s.targetRepo, s.goalRepo, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	summary.ApplyCalorieTarget(target.Calories, user)
+
+	// Split the day's target between meal types and compare each with its meals
+	distribution, err := resolveMealDistribution(ctx, s.distributionRepo, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	daily := target.Macros()
+	daily.Calories = *summary.CalorieTarget
+	summary.MealTargets = domain.CompareMealTargets(distribution.SplitTargets(daily), summary.MealGroups)
+
+	return summary, nil
+}
+
+// GetAdherence reports, per day in the user's timezone, which nutrition targets were met.
+// A zero from/to defaults to the last 7 days including today.
+func (s *summaryService) GetAdherence(ctx context.Context, userID string, from, to time.Time) (*domain.AdherenceSummary, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	loc := user.Location()
+
+	if to.IsZero() {
+		to = time.Now().In(loc)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -6)
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	if last.Before(start) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidInput)
+	}
+	if last.Sub(start) >= domain.MaxAdherenceRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range must not exceed %d days", domain.ErrInvalidInput, domain.MaxAdherenceRangeDays)
+	}
+
+	target, err := resolveNutritionTarget(ctx, s.targetRepo, s.goalRepo, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	targets := target.Macros()
+
+	totals, err := s.mealRepo.SumNutritionByDay(ctx, userUUID, start, last.AddDate(0, 0, 1), loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum nutrition: %w", err)
+	}
+
+	totalsByDate := make(map[string]*domain.DailyNutrition, len(totals))
+	for _, day := range totals {
+		totalsByDate[day.Date] = day
+	}
+
+	summary := &domain.AdherenceSummary{
+		From:     start.Format("2006-01-02"),
+		To:       last.Format("2006-01-02"),
+		Timezone: loc.String(),
+		Targets:  targets,
+		Days:     []domain.DayAdherence{},
+	}
+
+	var caloriesMet, proteinMet, carbsMet, fatMet, allMet int
+	for day := start; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		adherence := domain.DayAdherence{Date: date}
+
+		if nutrition, ok := totalsByDate[date]; ok {
+			adherence.Logged = true
+			adherence.Totals = domain.MacroTargets{
+				Calories:      nutrition.Calories,
+				Protein:       nutrition.Protein,
+				Carbohydrates: nutrition.Carbohydrates,
+				Fat:           nutrition.Fat,
+			}
+			adherence.CaloriesMet = withinTolerance(nutrition.Calories, targets.Calories)
+			adherence.ProteinMet = nutrition.Protein >= targets.Protein*domain.ProteinAdherenceFloor
+			adherence.CarbohydratesMet = withinTolerance(nutrition.Carbohydrates, targets.Carbohydrates)
+			adherence.FatMet = withinTolerance(nutrition.Fat, targets.Fat)
+			adherence.AllMet = adherence.CaloriesMet && adherence.ProteinMet && adherence.CarbohydratesMet && adherence.FatMet
+
+			summary.DaysLogged++
+		}
+
+		if adherence.CaloriesMet {
+			caloriesMet++
+		}
+		if adherence.ProteinMet {
+			proteinMet++
+		}
+		if adherence.CarbohydratesMet {
+			carbsMet++
+		}
+		if adherence.FatMet {
+			fatMet++
+		}
+		if adherence.AllMet {
+			allMet++
+		}
+
+		summary.Days = append(summary.Days, adherence)
+	}
+
+	days := len(summary.Days)
+	summary.Adherence = domain.AdherencePercentages{
+		Calories:      percentOf(caloriesMet, days),
+		Protein:       percentOf(proteinMet, days),
+		Carbohydrates: percentOf(carbsMet, days),
+		Fat:           percentOf(fatMet, days),
+		Overall:       percentOf(allMet, days),
+	}
+
+	return summary, nil
+}
+
+// GetNutritionTargets returns the user's daily nutrition target. Adherence, the coach and
+// the agent all read targets here so they agree on what the user is aiming for.
+func (s *summaryService) GetNutritionTargets(ctx context.Context, userID string) (*domain.NutritionTarget, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
 
-	summary.WorkoutsCount = len(workouts)
+	return resolveNutritionTarget(ctx, s.targetRepo, s.goalRepo, userUUID)
+}
+
+// RefreshDailySummary recomputes and stores the summary of the day date falls on in the
+// user's timezone. Changes to logged data call it for every day they touch, so a meal or
+// workout logged, moved or deleted in the past updates that day's stored summary rather
+// than today's.
+func (s *summaryService) RefreshDailySummary(ctx context.Context, userID string, date time.Time) (*domain.DailySummary, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
 
-	// Note: Calories burned from workouts could be calculated based on exercises
-	// For now, we'll focus on activities for calorie tracking
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	loc := user.Location()
+
+	summary, err := s.CalculateDailySummary(ctx, userID, date.In(loc))
+	if err != nil {
+		return nil, err
+	}
+	start := summary.Date
+	end := start.AddDate(0, 0, 1).Add(-time.Microsecond)
+
+	// Weigh-ins are newest first; the day keeps its latest weight and body fat
+	weights, err := s.metricRepo.ListByUser(ctx, userUUID, "weight", start, end, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weights: %w", err)
+	}
+	if len(weights) > 0 {
+		weight := weightKg(weights[0])
+		summary.Weight = &weight
+	}
+	bodyFats, err := s.metricRepo.ListByUser(ctx, userUUID, "body_fat", start, end, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get body fat: %w", err)
+	}
+	if len(bodyFats) > 0 {
+		summary.BodyFat = &bodyFats[0].Value
+	}
+
+	summary.UpdatedAt = time.Now()
+	if err := s.metricRepo.CreateOrUpdateDailySummary(ctx, summary); err != nil {
+		return nil, fmt.Errorf("failed to update daily summary: %w", err)
+	}
 
 	return summary, nil
 }
+
+// refreshDailySummaries recomputes the stored summary of each day a change to logged meals,
+// activities, workouts or metrics touched, however far in the past. Goal progress is derived
+// from logged data when read, so it needs no refresh. A failed refresh is logged: the change
+// itself is already saved.
+func refreshDailySummaries(ctx context.Context, summaryService ports.SummaryService, userID uuid.UUID, days ...time.Time) {
+	for i, day := range days {
+		if i > 0 && day.Equal(days[i-1]) {
+			continue
+		}
+		if _, err := summaryService.RefreshDailySummary(ctx, userID.String(), day); err != nil {
+			requestid.Logf(ctx, "[Summary] Failed to refresh daily summary of %s for user %s: %v", day.Format("2006-01-02"), userID, err)
+		}
+	}
+}
+
+// GetSummaryRange lists the daily summaries from from to to in the user's timezone, oldest
+// first. Past days come from the stored summaries; days without one, and today, whose totals
+// may still change, are computed and stored on the way. Days after today are left out. A zero
+// to defaults to today and a zero from to DefaultSummaryRangeDays days ending on to.
+func (s *summaryService) GetSummaryRange(ctx context.Context, userID string, from, to time.Time) (*domain.DailySummaryRange, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	loc := user.Location()
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if to.IsZero() {
+		to = today
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(domain.DefaultSummaryRangeDays - 1))
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	if last.Before(start) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidInput)
+	}
+	if last.Sub(start) >= domain.MaxSummaryRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range must not exceed %d days", domain.ErrInvalidInput, domain.MaxSummaryRangeDays)
+	}
+
+	summaryRange := &domain.DailySummaryRange{
+		From:     start.Format("2006-01-02"),
+		To:       last.Format("2006-01-02"),
+		Timezone: loc.String(),
+		Days:     []*domain.DailySummary{},
+	}
+	if last.After(today) {
+		last = today
+	}
+	if start.After(last) {
+		return summaryRange, nil
+	}
+
+	stored, err := s.metricRepo.ListDailySummaries(ctx, userUUID, start, last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
+	}
+	// Stored days are measured against the current target, as computed days are
+	target, err := resolveNutritionTarget(ctx, s.targetRepo, s.goalRepo, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	storedByDate := make(map[string]*domain.DailySummary, len(stored))
+	for _, summary := range stored {
+		storedByDate[summary.Date.Format("2006-01-02")] = summary
+	}
+
+	for day := start; !day.After(last); day = day.AddDate(0, 0, 1) {
+		summary, ok := storedByDate[day.Format("2006-01-02")]
+		if ok && day.Before(today) {
+			summary.NetCalories = summary.TotalCalories - summary.TotalCaloriesBurned
+			summary.ApplyCalorieTarget(target.Calories, user)
+		} else if summary, err = s.RefreshDailySummary(ctx, userID, day); err != nil {
+			return nil, err
+		}
+		summaryRange.Days = append(summaryRange.Days, summary)
+	}
+
+	return summaryRange, nil
+}
+
+// resolveNutritionTarget returns the user's stored nutrition target or, without one, the
+// defaults overridden by their active calories, protein, carbohydrates and fat goals
+func resolveNutritionTarget(ctx context.Context, targetRepo ports.NutritionTargetRepository, goalRepo ports.GoalRepository, userID uuid.UUID) (*domain.NutritionTarget, error) {
+	target, err := targetRepo.Get(ctx, userID)
+	if err == nil {
+		return target, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get nutrition target: %w", err)
+	}
+
+	goals, err := goalRepo.ListByUser(ctx, userID, "active", goalListLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	return domain.DefaultNutritionTarget(userID, goals), nil
+}
+
+// resolveMealDistribution returns the user's stored meal distribution or, without one,
+// the default split
+func resolveMealDistribution(ctx context.Context, repo ports.MealDistributionRepository, userID uuid.UUID) (*domain.MealDistribution, error) {
+	distribution, err := repo.Get(ctx, userID)
+	if err == nil {
+		return distribution, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get meal distribution: %w", err)
+	}
+	return domain.DefaultMealDistribution(userID), nil
+}
+
+// activityBurn returns the activity's logged burn over the time it covered. Activities
+// without a source were entered by hand.
+func activityBurn(activity *domain.Activity) domain.BurnEntry {
+	end := activity.StartTime
+	if activity.EndTime != nil {
+		end = *activity.EndTime
+	} else if activity.DurationMinutes != nil {
+		end = activity.StartTime.Add(time.Duration(*activity.DurationMinutes) * time.Minute)
+	}
+
+	source := domain.CalorieSourceManual
+	if activity.CalorieSource != nil {
+		source = *activity.CalorieSource
+	}
+	return domain.BurnEntry{
+		Kind:     domain.BurnKindActivity,
+		ID:       activity.ID,
+		Start:    activity.StartTime,
+		End:      end,
+		Calories: *activity.CaloriesBurned,
+		Source:   source,
+	}
+}
+
+// workoutBurn returns the workout's burn over its duration, estimating it from the
+// duration and body weight when none was logged
+func workoutBurn(workout *domain.Workout, durationMinutes int, bodyWeight float64) domain.BurnEntry {
+	entry := domain.BurnEntry{
+		Kind:   domain.BurnKindWorkout,
+		ID:     workout.ID,
+		Start:  workout.StartTime,
+		End:    workout.StartTime.Add(time.Duration(durationMinutes) * time.Minute),
+		Source: domain.CalorieSourceManual,
+	}
+	if workout.EndTime != nil {
+		entry.End = *workout.EndTime
+	}
+
+	switch {
+	case workout.CaloriesBurned == nil:
+		entry.Calories = domain.EstimateWorkoutCalories(workout, bodyWeight)
+		entry.Source = domain.CalorieSourceEstimate
+	case workout.CalorieSource != nil:
+		entry.Calories = *workout.CaloriesBurned
+		entry.Source = *workout.CalorieSource
+	default:
+		entry.Calories = *workout.CaloriesBurned
+	}
+	return entry
+}
+
+// withinTolerance reports whether actual is within AdherenceTolerance of target
+func withinTolerance(actual, target float64) bool {
+	if target <= 0 {
+		return true
+	}
+	return math.Abs(actual-target) <= target*domain.AdherenceTolerance
+}
+
+// percentOf returns count/total as a percentage rounded to one decimal
+func percentOf(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*1000) / 10
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+
+	"github.com/google/uuid"
+)
+
+type undoService struct {
+	actionRepo   ports.UserActionRepository
+	mealRepo     ports.MealRepository
+	activityRepo ports.ActivityRepository
+	metricRepo   ports.MetricRepository
+	workoutRepo  ports.WorkoutRepository
+	userRepo     ports.UserRepository
+
+	summaryService ports.SummaryService
+}
+
+// NewUndoService creates a service that reverses the last create or delete in a
+// user's action log
+func NewUndoService(
+	actionRepo ports.UserActionRepository,
+	mealRepo ports.MealRepository,
+	activityRepo ports.ActivityRepository,
+	metricRepo ports.MetricRepository,
+	workoutRepo ports.WorkoutRepository,
+	userRepo ports.UserRepository,
+	summaryService ports.SummaryService,
+) ports.UndoService {
+	return &undoService{
+		actionRepo:   actionRepo,
+		mealRepo:     mealRepo,
+		activityRepo: activityRepo,
+		metricRepo:   metricRepo,
+		workoutRepo:  workoutRepo,
+		userRepo:     userRepo,
+
+		summaryService: summaryService,
+	}
+}
+
+// UndoLastAction reverses the user's most recent action if it is a create or
+// delete made within domain.UndoWindow: a created record is deleted and a
+// deleted record is restored. Only the latest action is considered, so once it
+// has been undone there is nothing left to undo and ErrNotFound is returned.
+func (s *undoService) UndoLastAction(ctx context.Context, userID string) (*domain.UndoResult, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	action, err := s.actionRepo.GetLatest(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get last action: %w", err)
+	}
+	if !action.Undoable(time.Now()) {
+		return nil, domain.ErrNotFound
+	}
+
+	var entity interface{}
+	switch action.Action {
+	case domain.ActionCreate:
+		entity, err = s.remove(ctx, action.EntityType, action.EntityID)
+	case domain.ActionDelete:
+		entity, err = s.restore(ctx, action.EntityType, action.EntityID)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to undo %s %s: %w", action.Action, action.EntityType, err)
+	}
+
+	// The unique undoes_id keeps a concurrent undo from reversing the same action twice
+	undo := &domain.UserAction{
+		UserID:     userUUID,
+		Action:     domain.ActionUndo,
+		EntityType: action.EntityType,
+		EntityID:   action.EntityID,
+		UndoesID:   &action.ID,
+	}
+	if err := s.actionRepo.Create(ctx, undo); err != nil {
+		return nil, fmt.Errorf("failed to record undo: %w", err)
+	}
+
+	s.refreshDerived(ctx, action.Action, userUUID, entity)
+
+	return &domain.UndoResult{
+		Action:      action.Action,
+		EntityType:  action.EntityType,
+		EntityID:    action.EntityID,
+		PerformedAt: action.CreatedAt,
+		Entity:      entity,
+	}, nil
+}
+
+// refreshDerived brings data computed from the undone record back in line, as its own
+// service does when the record is logged or deleted. The undo is already saved, so
+// failures are only logged.
+func (s *undoService) refreshDerived(ctx context.Context, undone string, userID uuid.UUID, entity interface{}) {
+	switch record := entity.(type) {
+	case *domain.Meal:
+		refreshDailySummaries(ctx, s.summaryService, userID, record.ConsumedAt)
+	case *domain.Activity:
+		refreshDailySummaries(ctx, s.summaryService, userID, record.StartTime)
+	case *domain.Workout:
+		refreshDailySummaries(ctx, s.summaryService, userID, record.StartTime)
+	case *domain.Metric:
+		// A removed entry is refreshed like a delete, a restored one like an edit in place
+		current := record
+		if undone == domain.ActionCreate {
+			current = nil
+		}
+		if err := refreshMetricDerived(ctx, s.metricRepo, s.userRepo, s.summaryService, record, current); err != nil {
+			requestid.Logf(ctx, "[Undo] Failed to refresh values derived from metric %s for user %s: %v", record.ID, userID, err)
+		}
+	}
+}
+
+// remove deletes a record the user created and returns it as it was
+func (s *undoService) remove(ctx context.Context, entityType string, id uuid.UUID) (interface{}, error) {
+	switch entityType {
+	case domain.ActionEntityMeal:
+		meal, err := s.mealRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return meal, s.mealRepo.Delete(ctx, id)
+	case domain.ActionEntityActivity:
+		activity, err := s.activityRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return activity, s.activityRepo.Delete(ctx, id)
+	case domain.ActionEntityMetric:
+		metric, err := s.metricRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return metric, s.metricRepo.Delete(ctx, id)
+	case domain.ActionEntityWorkout:
+		workout, err := s.workoutRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return workout, s.workoutRepo.Delete(ctx, id)
+	}
+	return nil, fmt.Errorf("unsupported entity type %q", entityType)
+}
+
+// restore reverses the soft delete of a record and returns it
+func (s *undoService) restore(ctx context.Context, entityType string, id uuid.UUID) (interface{}, error) {
+	switch entityType {
+	case domain.ActionEntityMeal:
+		if err := s.mealRepo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		return s.mealRepo.GetByID(ctx, id)
+	case domain.ActionEntityActivity:
+		if err := s.activityRepo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		return s.activityRepo.GetByID(ctx, id)
+	case domain.ActionEntityMetric:
+		if err := s.metricRepo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		return s.metricRepo.GetByID(ctx, id)
+	case domain.ActionEntityWorkout:
+		if err := s.workoutRepo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		return s.workoutRepo.GetByID(ctx, id)
+	}
+	return nil, fmt.Errorf("unsupported entity type %q", entityType)
+}
+
+// recordAction appends a create or delete to the user's action log. Failures are
+// logged rather than returned: the action itself succeeded and only its undo is lost.
+func recordAction(ctx context.Context, actionRepo ports.UserActionRepository, userID uuid.UUID, action, entityType string, entityID uuid.UUID) {
+	entry := &domain.UserAction{
+		UserID:     userID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	if err := actionRepo.Create(ctx, entry); err != nil {
+		requestid.Logf(ctx, "[Undo] Failed to record %s %s %s for user %s: %v", action, entityType, entityID, userID, err)
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/pkg/requestid"
+)
+
+// Limits for a weekly recap
+const (
+	maxRecapEncouragementLength = 200 // longer replies fall back to the template encouragement
+	recapWorkoutLimit           = 100 // workouts a single week can contribute
+	recapWeightLimit            = 100 // weigh-ins a single week can contribute
+)
+
+// recapCacheKey identifies a user's recap of one ISO week
+type recapCacheKey struct {
+	userID uuid.UUID
+	week   string
+}
+
+type weeklyRecapService struct {
+	userRepo       ports.UserRepository
+	workoutRepo    ports.WorkoutRepository
+	metricRepo     ports.MetricRepository
+	summaryService ports.SummaryService
+
+	// Optional; without a client every encouragement comes from the template
+	openRouterClient *external.OpenRouterClient
+	model            string
+	moderator        ports.ContentModerator // optional; a flagged encouragement falls back to the template
+
+	mu     sync.Mutex
+	recaps map[recapCacheKey]*domain.WeeklyRecap
+}
+
+// NewWeeklyRecapService creates a weekly recap service. When openRouterClient is nil
+// the encouragement is always templated and no LLM call is made; when moderator is nil
+// AI encouragements are not screened.
+func NewWeeklyRecapService(
+	userRepo ports.UserRepository,
+	workoutRepo ports.WorkoutRepository,
+	metricRepo ports.MetricRepository,
+	summaryService ports.SummaryService,
+	openRouterClient *external.OpenRouterClient,
+	model string,
+	moderator ports.ContentModerator,
+) ports.WeeklyRecapService {
+	return &weeklyRecapService{
+		userRepo:         userRepo,
+		workoutRepo:      workoutRepo,
+		metricRepo:       metricRepo,
+		summaryService:   summaryService,
+		openRouterClient: openRouterClient,
+		model:            model,
+		moderator:        moderator,
+		recaps:           make(map[recapCacheKey]*domain.WeeklyRecap),
+	}
+}
+
+// GetWeeklyRecap recaps a finished ISO week in the user's timezone, last week by default.
+// A week's recap is composed once, with at most one LLM call, and then served from cache.
+func (s *weeklyRecapService) GetWeeklyRecap(ctx context.Context, userID, week string) (*domain.WeeklyRecap, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	loc := user.Location()
+
+	thisWeek := startOfWeek(time.Now().In(loc))
+	start := thisWeek.AddDate(0, 0, -7)
+	if week != "" {
+		if start, err = domain.ParseISOWeek(week, loc); err != nil {
+			return nil, err
+		}
+		if !start.Before(thisWeek) {
+			return nil, fmt.Errorf("%w: %s has not finished yet", domain.ErrInvalidInput, week)
+		}
+	}
+
+	key := recapCacheKey{userID: userUUID, week: domain.ISOWeek(start)}
+	s.mu.Lock()
+	cached, ok := s.recaps[key]
+	s.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	recap, err := s.composeRecap(ctx, userUUID, key.week, start)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.recaps[key] = recap
+	s.mu.Unlock()
+	return recap, nil
+}
+
+// composeRecap totals the week starting at start from workouts, weigh-ins and adherence,
+// then writes its encouragement
+func (s *weeklyRecapService) composeRecap(ctx context.Context, userID uuid.UUID, week string, start time.Time) (*domain.WeeklyRecap, error) {
+	sunday := start.AddDate(0, 0, 6)
+	// BETWEEN is inclusive, so stop just before next Monday
+	end := start.AddDate(0, 0, 7).Add(-time.Microsecond)
+
+	recap := &domain.WeeklyRecap{
+		Week:     week,
+		From:     start.Format("2006-01-02"),
+		To:       sunday.Format("2006-01-02"),
+		Timezone: start.Location().String(),
+	}
+
+	workouts, err := s.workoutRepo.ListByUser(ctx, userID, start, end, domain.WorkoutFilter{}, recapWorkoutLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workouts: %w", err)
+	}
+
+	finished := make([]*domain.Workout, 0, len(workouts))
+	for _, workout := range workouts {
+		if workout.EndTime == nil {
+			continue
+		}
+		finished = append(finished, workout)
+
+		if workout.TotalVolume != nil {
+			recap.TotalVolume += *workout.TotalVolume
+		} else {
+			_, _, volume := workout.TotalWork()
+			recap.TotalVolume += volume
+		}
+	}
+	recap.Workouts = len(finished)
+	recap.TotalVolume = math.Round(recap.TotalVolume*100) / 100
+	recap.BestLift = domain.FindBestLift(finished)
+
+	// Weigh-ins are newest first
+	weights, err := s.metricRepo.ListByUser(ctx, userID, "weight", start, end, recapWeightLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weight history: %w", err)
+	}
+	if len(weights) >= 2 {
+		change := roundTenth(weightKg(weights[0]) - weightKg(weights[len(weights)-1]))
+		recap.WeightChange = &change
+	}
+
+	adherence, err := s.summaryService.GetAdherence(ctx, userID.String(), start, sunday)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adherence: %w", err)
+	}
+	recap.CalorieAdherence = adherence.Adherence.Calories
+	recap.DaysLogged = adherence.DaysLogged
+
+	recap.Encouragement = recap.TemplateEncouragement()
+	recap.EncouragementSource = domain.RecapEncouragementSourceTemplate
+	if encouragement, err := s.generateEncouragement(ctx, userID, recap); err != nil {
+		requestid.Logf(ctx, "[WeeklyRecap] Falling back to template encouragement for user %s: %v", userID, err)
+	} else if encouragement != "" {
+		recap.Encouragement = encouragement
+		recap.EncouragementSource = domain.RecapEncouragementSourceAI
+	}
+	recap.GeneratedAt = time.Now()
+
+	return recap, nil
+}
+
+// generateEncouragement asks the model to phrase one line of encouragement from the
+// recap's data. It returns an empty line without error when AI is disabled.
+func (s *weeklyRecapService) generateEncouragement(ctx context.Context, userID uuid.UUID, recap *domain.WeeklyRecap) (string, error) {
+	if s.openRouterClient == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(recap)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode recap: %w", err)
+	}
+
+	systemPrompt := `You are a supportive fitness coach writing the headline of a weekly recap notification.
+You get the week's data as JSON: finished workouts, total volume lifted (kg), weight change (kg), the percentage of days the calorie target was met, days with food logged and the best lift.
+Reply with exactly one short, upbeat, specific sentence (no greeting, no hashtags, no markdown) that celebrates something real in the data.`
+
+	messages := []external.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: string(data)},
+	}
+
+	ctx = external.WithAuditContext(ctx, external.AuditOperationWeeklyRecap, userID)
+	resp, err := s.openRouterClient.Chat(ctx, messages, s.model)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate encouragement: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from AI")
+	}
+
+	encouragement := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if encouragement == "" || len(encouragement) > maxRecapEncouragementLength {
+		return "", fmt.Errorf("unusable encouragement of %d characters", len(encouragement))
+	}
+	if err := moderateOutput(ctx, s.moderator, encouragement); err != nil {
+		return "", err
+	}
+	return encouragement, nil
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,43 +14,132 @@ import (
 	"fitness-tracker/internal/core/ports"
 )
 
+// workoutListLimit caps how many workouts GetWorkouts returns
+const workoutListLimit = 500
+
 type workoutService struct {
-	workoutRepo ports.WorkoutRepository
+	workoutRepo    ports.WorkoutRepository
+	userRepo       ports.UserRepository
+	actionRepo     ports.UserActionRepository
+	summaryService ports.SummaryService
 }
 
 // NewWorkoutService creates a new workout service
-func NewWorkoutService(workoutRepo ports.WorkoutRepository) ports.WorkoutService {
+func NewWorkoutService(workoutRepo ports.WorkoutRepository, userRepo ports.UserRepository, actionRepo ports.UserActionRepository, summaryService ports.SummaryService) ports.WorkoutService {
 	return &workoutService{
-		workoutRepo: workoutRepo,
+		workoutRepo:    workoutRepo,
+		userRepo:       userRepo,
+		actionRepo:     actionRepo,
+		summaryService: summaryService,
 	}
 }
 
-func (s *workoutService) StartWorkout(ctx context.Context, userID, name string) (*domain.Workout, error) {
-	if userID == "" {
+func (s *workoutService) StartWorkout(ctx context.Context, userID uuid.UUID, name string) (*domain.Workout, error) {
+	if userID == uuid.Nil {
 		return nil, domain.ErrInvalidInput
 	}
 
 	workout := &domain.Workout{
-		ID:        uuid.New().String(),
+		ID:        uuid.New(),
 		UserID:    userID,
 		Name:      name,
-		Status:    "in_progress",
 		StartTime: time.Now(),
 	}
 
 	if err := s.workoutRepo.Create(ctx, workout); err != nil {
 		return nil, fmt.Errorf("failed to start workout: %w", err)
 	}
+	recordAction(ctx, s.actionRepo, workout.UserID, domain.ActionCreate, domain.ActionEntityWorkout, workout.ID)
 
 	return workout, nil
 }
 
-func (s *workoutService) GetWorkouts(ctx context.Context, userID string, startDate, endDate *time.Time) ([]*domain.Workout, error) {
-	if userID == "" {
-		return nil, domain.ErrInvalidInput
+// CloneWorkout starts a new workout repeating one of the user's workouts: the same name
+// and exercises in the same order, with each set the source logged planned for the new
+// workout as a suggestion. Nothing is logged, so the clone starts with no sets.
+func (s *workoutService) CloneWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	source, err := s.getOwnedWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
 	}
 
-	workouts, err := s.workoutRepo.GetByUserAndDateRange(ctx, userID, startDate, endDate)
+	workout := &domain.Workout{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      source.Name,
+		StartTime: time.Now(),
+		Exercises: make([]domain.WorkoutExercise, 0, len(source.Exercises)),
+	}
+
+	exercises := slices.Clone(source.Exercises)
+	slices.SortStableFunc(exercises, func(a, b domain.WorkoutExercise) int {
+		return cmp.Compare(a.OrderIndex, b.OrderIndex)
+	})
+	for i, sourceExercise := range exercises {
+		sets := slices.Clone(sourceExercise.Sets)
+		slices.SortStableFunc(sets, func(a, b domain.WorkoutSet) int {
+			return cmp.Compare(a.SetNumber, b.SetNumber)
+		})
+
+		workoutExercise := domain.WorkoutExercise{
+			ID:          uuid.New(),
+			WorkoutID:   workout.ID,
+			ExerciseID:  sourceExercise.ExerciseID,
+			OrderIndex:  i,
+			Notes:       sourceExercise.Notes,
+			PlannedSets: make([]domain.PlannedSet, 0, len(sets)),
+		}
+		for _, set := range sets {
+			planned := domain.PlanFromSet(set)
+			planned.WorkoutExerciseID = workoutExercise.ID
+			workoutExercise.PlannedSets = append(workoutExercise.PlannedSets, planned)
+		}
+		workout.Exercises = append(workout.Exercises, workoutExercise)
+	}
+
+	if err := s.workoutRepo.Create(ctx, workout); err != nil {
+		return nil, fmt.Errorf("failed to clone workout: %w", err)
+	}
+	recordAction(ctx, s.actionRepo, workout.UserID, domain.ActionCreate, domain.ActionEntityWorkout, workout.ID)
+
+	for i := range workout.Exercises {
+		workout.Exercises[i].Exercise = exercises[i].Exercise
+	}
+	return workout, nil
+}
+
+// GetWorkouts returns the user's workouts started within the dates, both days included,
+// newest first. With no dates every workout is considered. A filter on an exercise also
+// matches workouts that logged one of its aliases.
+func (s *workoutService) GetWorkouts(ctx context.Context, userID uuid.UUID, startDate, endDate *time.Time, filter domain.WorkoutFilter) ([]*domain.Workout, error) {
+	switch filter.Status {
+	case "", domain.WorkoutStatusInProgress, domain.WorkoutStatusCompleted:
+	default:
+		return nil, fmt.Errorf("%w: status must be %s or %s", domain.ErrInvalidInput, domain.WorkoutStatusInProgress, domain.WorkoutStatusCompleted)
+	}
+
+	if filter.ExerciseID != nil {
+		exercise, err := s.workoutRepo.GetExercise(ctx, *filter.ExerciseID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, domain.ErrNotFound
+			}
+			return nil, fmt.Errorf("failed to get exercise: %w", err)
+		}
+		canonicalID := exercise.CanonicalExerciseID()
+		filter.ExerciseID = &canonicalID
+	}
+
+	var start, end time.Time
+	if startDate != nil {
+		start = *startDate
+	}
+	if endDate != nil {
+		// Include the whole end day
+		end = endDate.AddDate(0, 0, 1)
+	}
+
+	workouts, err := s.workoutRepo.ListByUser(ctx, userID, start, end, filter, workoutListLimit, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workouts: %w", err)
 	}
@@ -54,145 +147,504 @@ func (s *workoutService) GetWorkouts(ctx context.Context, userID string, startDa
 	return workouts, nil
 }
 
-func (s *workoutService) GetWorkout(ctx context.Context, workoutID string) (*domain.Workout, error) {
-	if workoutID == "" {
-		return nil, domain.ErrInvalidInput
+// GetWorkout returns one of the user's workouts. Another user's workout is reported as
+// not found.
+func (s *workoutService) GetWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	return s.getOwnedWorkout(ctx, userID, workoutID)
+}
+
+// AddExercise appends a library exercise to the end of the user's unfinished workout
+func (s *workoutService) AddExercise(ctx context.Context, userID, workoutID, exerciseID uuid.UUID) (*domain.WorkoutExercise, error) {
+	workout, err := s.getActiveWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
 	}
 
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	exercise, err := s.workoutRepo.GetExercise(ctx, exerciseID)
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil, domain.ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to get workout: %w", err)
+		return nil, fmt.Errorf("failed to get exercise: %w", err)
 	}
 
-	return workout, nil
+	workoutExercise := &domain.WorkoutExercise{
+		ID:         uuid.New(),
+		WorkoutID:  workout.ID,
+		ExerciseID: exercise.ID,
+		OrderIndex: len(workout.Exercises),
+		Sets:       []domain.WorkoutSet{},
+	}
+	if err := s.workoutRepo.AddWorkoutExercise(ctx, workoutExercise); err != nil {
+		return nil, fmt.Errorf("failed to add exercise to workout: %w", err)
+	}
+
+	workoutExercise.Exercise = *exercise
+	return workoutExercise, nil
 }
 
-func (s *workoutService) AddExercise(ctx context.Context, workoutID, exerciseID string) (*domain.WorkoutExercise, error) {
-	if workoutID == "" || exerciseID == "" {
+func (s *workoutService) LogSet(ctx context.Context, workoutExerciseID uuid.UUID, setData *domain.WorkoutSet) (*domain.WorkoutSet, error) {
+	if workoutExerciseID == uuid.Nil || setData == nil {
 		return nil, domain.ErrInvalidInput
 	}
+	if err := prepareSet(setData); err != nil {
+		return nil, err
+	}
+	setData.WorkoutExerciseID = workoutExerciseID
 
-	// Verify workout exists and is in progress
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	// Create set
+	if err := s.workoutRepo.AddSet(ctx, setData); err != nil {
+		return nil, fmt.Errorf("failed to log set: %w", err)
+	}
+
+	return setData, nil
+}
+
+// LogSets logs several sets of one exercise in the user's unfinished workout, numbering
+// them after the exercise's last set. Each set is validated on its own; rejected sets are
+// reported by position and the rest are saved together.
+func (s *workoutService) LogSets(ctx context.Context, userID, workoutID, workoutExerciseID uuid.UUID, sets []*domain.WorkoutSet) (*domain.SetBatchResult, error) {
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("%w: at least one set is required", domain.ErrInvalidInput)
+	}
+	if len(sets) > domain.MaxSetBatchSize {
+		return nil, fmt.Errorf("%w: at most %d sets per request", domain.ErrInvalidInput, domain.MaxSetBatchSize)
+	}
+
+	workout, err := s.getActiveWorkout(ctx, userID, workoutID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return nil, domain.ErrNotFound
+		return nil, err
+	}
+	var workoutExercise *domain.WorkoutExercise
+	for i := range workout.Exercises {
+		if workout.Exercises[i].ID == workoutExerciseID {
+			workoutExercise = &workout.Exercises[i]
+			break
 		}
-		return nil, fmt.Errorf("failed to get workout: %w", err)
+	}
+	if workoutExercise == nil {
+		return nil, domain.ErrNotFound
 	}
 
-	if workout.Status != "in_progress" {
-		return nil, domain.ErrInvalidInput
+	nextSetNumber := 1
+	for _, set := range workoutExercise.Sets {
+		if set.SetNumber >= nextSetNumber {
+			nextSetNumber = set.SetNumber + 1
+		}
 	}
 
-	// Verify exercise exists
-	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	result := &domain.SetBatchResult{
+		Received: len(sets),
+		Sets:     []*domain.WorkoutSet{},
+		Errors:   []domain.SetBatchError{},
+	}
+	for i, set := range sets {
+		if set == nil {
+			result.Errors = append(result.Errors, domain.SetBatchError{Index: i, Message: "set is empty"})
+			continue
+		}
+		if err := prepareSet(set); err != nil {
+			result.Errors = append(result.Errors, domain.SetBatchError{Index: i, Message: err.Error()})
+			continue
+		}
+		set.WorkoutExerciseID = workoutExercise.ID
+		set.SetNumber = nextSetNumber
+		nextSetNumber++
+		result.Sets = append(result.Sets, set)
+	}
+	result.Failed = len(result.Errors)
+
+	if err := s.workoutRepo.AddSets(ctx, result.Sets); err != nil {
+		return nil, fmt.Errorf("failed to log sets: %w", err)
+	}
+	result.Created = len(result.Sets)
+
+	return result, nil
+}
+
+// prepareSet validates a set to log and fills in its ID and set type when missing
+func prepareSet(set *domain.WorkoutSet) error {
+	if set.Reps != nil && *set.Reps < 0 {
+		return fmt.Errorf("%w: reps must not be negative", domain.ErrInvalidInput)
+	}
+	if set.Weight != nil && *set.Weight < 0 {
+		return fmt.Errorf("%w: weight must not be negative", domain.ErrInvalidInput)
+	}
+	if set.DurationSeconds != nil && *set.DurationSeconds < 0 {
+		return fmt.Errorf("%w: duration must not be negative", domain.ErrInvalidInput)
+	}
+	if set.Distance != nil && *set.Distance < 0 {
+		return fmt.Errorf("%w: distance must not be negative", domain.ErrInvalidInput)
+	}
+	if set.RestSeconds != nil && *set.RestSeconds < 0 {
+		return fmt.Errorf("%w: rest must not be negative", domain.ErrInvalidInput)
+	}
+	if err := domain.ValidateRPE(set.RPE); err != nil {
+		return err
+	}
+
+	if set.ID == uuid.Nil {
+		set.ID = uuid.New()
+	}
+	if set.SetType == "" {
+		set.SetType = domain.SetTypeNormal
+	}
+	if !domain.IsValidSetType(set.SetType) {
+		return fmt.Errorf("%w: set_type must be normal, warmup, drop or superset", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+// FinishWorkout ends the user's workout and summarizes it: duration without breaks, sets,
+// reps, volume, calories and the personal records set. The totals are stored on the workout.
+func (s *workoutService) FinishWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	workout, err := s.getActiveWorkout(ctx, userID, workoutID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return nil, domain.ErrNotFound
+		return nil, err
+	}
+
+	endTime := time.Now()
+
+	// Finishing while paused ends the pause, so the break is not counted as training
+	if pause := workout.OpenPause(); pause != nil {
+		pause.ResumedAt = &endTime
+		if err := s.workoutRepo.UpdatePause(ctx, pause); err != nil {
+			return nil, fmt.Errorf("failed to end pause: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get exercise: %w", err)
 	}
 
-	// Create workout exercise
-	workoutExercise := &domain.WorkoutExercise{
-		ID:         uuid.New().String(),
-		WorkoutID:  workoutID,
-		ExerciseID: exerciseID,
-		Sets:       []domain.WorkoutSet{},
+	// Breaks are excluded so calorie estimates and analytics reflect time actually training
+	durationMinutes := int(workout.ActiveDuration(endTime).Minutes())
+	workout.EndTime = &endTime
+	workout.DurationMinutes = &durationMinutes
+
+	// Estimate calories burned unless already provided (e.g. from a wearable)
+	if workout.CaloriesBurned == nil {
+		calories := domain.EstimateWorkoutCalories(workout, s.userWeightKg(ctx, workout.UserID))
+		source := domain.CalorieSourceEstimate
+		workout.CaloriesBurned = &calories
+		workout.CalorieSource = &source
 	}
 
-	// Note: Repository should handle adding to workout.Exercises
-	if err := s.workoutRepo.AddExercise(ctx, workoutExercise); err != nil {
-		return nil, fmt.Errorf("failed to add exercise to workout: %w", err)
+	records, err := s.personalRecords(ctx, workout)
+	if err != nil {
+		return nil, err
 	}
 
-	workoutExercise.Exercise = exercise
-	return workoutExercise, nil
+	sets, reps, volume := workout.TotalWork()
+	volume = math.Round(volume*100) / 100
+	recordCount := len(records)
+	workout.TotalSets = &sets
+	workout.TotalReps = &reps
+	workout.TotalVolume = &volume
+	workout.PersonalRecordCount = &recordCount
+
+	if err := s.workoutRepo.Update(ctx, workout); err != nil {
+		return nil, fmt.Errorf("failed to finish workout: %w", err)
+	}
+	refreshDailySummaries(ctx, s.summaryService, workout.UserID, workout.StartTime)
+
+	workout.Summary = &domain.WorkoutSummary{
+		DurationMinutes: durationMinutes,
+		TotalSets:       sets,
+		TotalReps:       reps,
+		TotalVolume:     volume,
+		CaloriesBurned:  *workout.CaloriesBurned,
+		AverageRPE:      workout.AverageRPE(),
+		PersonalRecords: records,
+	}
+	return workout, nil
 }
 
-func (s *workoutService) LogSet(ctx context.Context, workoutExerciseID string, setData *domain.WorkoutSet) (*domain.WorkoutSet, error) {
-	if workoutExerciseID == "" || setData == nil {
-		return nil, domain.ErrInvalidInput
+// personalRecords returns the records the workout's sets set against the user's earlier
+// workouts. An exercise done in several blocks of the workout, or under several of its
+// aliases, is compared as a whole.
+func (s *workoutService) personalRecords(ctx context.Context, workout *domain.Workout) ([]domain.PersonalRecord, error) {
+	var order []uuid.UUID
+	exercises := make(map[uuid.UUID]domain.Exercise)
+	sets := make(map[uuid.UUID][]domain.WorkoutSet)
+	for _, workoutExercise := range workout.Exercises {
+		exerciseID := workoutExercise.ExerciseID
+		if workoutExercise.Exercise.CanonicalID != nil {
+			exerciseID = *workoutExercise.Exercise.CanonicalID
+		}
+		if _, seen := exercises[exerciseID]; !seen {
+			order = append(order, exerciseID)
+			exercises[exerciseID] = workoutExercise.Exercise
+		}
+		sets[exerciseID] = append(sets[exerciseID], workoutExercise.Sets...)
 	}
 
-	// Validate set data
-	if setData.Reps < 0 {
-		return nil, domain.ErrInvalidInput
+	records := []domain.PersonalRecord{}
+	for _, exerciseID := range order {
+		previous, err := s.workoutRepo.ListExerciseSets(ctx, workout.UserID, exerciseID, time.Time{}, workout.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exercise history: %w", err)
+		}
+		records = append(records, domain.FindPersonalRecords(exercises[exerciseID], sets[exerciseID], previous)...)
 	}
-	if setData.Weight != nil && *setData.Weight < 0 {
-		return nil, domain.ErrInvalidInput
+	return records, nil
+}
+
+// PauseWorkout starts a break in an unfinished workout. Time until ResumeWorkout
+// (or FinishWorkout) does not count toward the workout's duration.
+func (s *workoutService) PauseWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	workout, err := s.getActiveWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
 	}
-	if setData.Duration != nil && *setData.Duration < 0 {
-		return nil, domain.ErrInvalidInput
+	if workout.OpenPause() != nil {
+		return nil, fmt.Errorf("%w: workout is already paused", domain.ErrConflict)
 	}
 
-	// Set defaults
-	if setData.ID == "" {
-		setData.ID = uuid.New().String()
+	pause := domain.WorkoutPause{
+		WorkoutID: workout.ID,
+		PausedAt:  time.Now(),
+	}
+	if err := s.workoutRepo.AddPause(ctx, &pause); err != nil {
+		return nil, fmt.Errorf("failed to pause workout: %w", err)
 	}
-	setData.WorkoutExerciseID = workoutExerciseID
 
-	// Create set
-	if err := s.workoutRepo.LogSet(ctx, setData); err != nil {
-		return nil, fmt.Errorf("failed to log set: %w", err)
+	workout.Pauses = append(workout.Pauses, pause)
+	return workout, nil
+}
+
+// ResumeWorkout ends the break started by PauseWorkout
+func (s *workoutService) ResumeWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	workout, err := s.getActiveWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
 	}
 
-	return setData, nil
+	pause := workout.OpenPause()
+	if pause == nil {
+		return nil, fmt.Errorf("%w: workout is not paused", domain.ErrConflict)
+	}
+
+	resumedAt := time.Now()
+	pause.ResumedAt = &resumedAt
+	if err := s.workoutRepo.UpdatePause(ctx, pause); err != nil {
+		return nil, fmt.Errorf("failed to resume workout: %w", err)
+	}
+
+	return workout, nil
 }
 
-func (s *workoutService) FinishWorkout(ctx context.Context, workoutID string) error {
-	if workoutID == "" {
-		return domain.ErrInvalidInput
+// ReorderExercises moves the listed workout exercises into the given order. Exercises
+// left out keep their positions. Finished workouts can be reordered too.
+func (s *workoutService) ReorderExercises(ctx context.Context, userID, workoutID uuid.UUID, workoutExerciseIDs []uuid.UUID) ([]*domain.WorkoutExercise, error) {
+	if _, err := s.getOwnedWorkout(ctx, userID, workoutID); err != nil {
+		return nil, err
 	}
 
-	// Verify workout exists and is in progress
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	exercises, err := s.workoutRepo.GetWorkoutExercises(ctx, workoutID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return domain.ErrNotFound
+		return nil, fmt.Errorf("failed to get workout exercises: %w", err)
+	}
+	if err := domain.ReorderWorkoutExercises(exercises, workoutExerciseIDs); err != nil {
+		return nil, err
+	}
+	if err := s.workoutRepo.UpdateWorkoutExerciseOrder(ctx, workoutID, exercises); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: workout exercises changed while reordering", domain.ErrConflict)
 		}
-		return fmt.Errorf("failed to get workout: %w", err)
+		return nil, fmt.Errorf("failed to reorder workout exercises: %w", err)
+	}
+
+	return exercises, nil
+}
+
+// getActiveWorkout returns the user's workout if it has not been finished yet.
+// Another user's workout is reported as not found.
+func (s *workoutService) getActiveWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	workout, err := s.getOwnedWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return nil, err
 	}
+	if workout.EndTime != nil {
+		return nil, fmt.Errorf("%w: workout is already finished", domain.ErrInvalidInput)
+	}
+
+	return workout, nil
+}
 
-	if workout.Status != "in_progress" {
-		return domain.ErrInvalidInput
+// getOwnedWorkout returns the user's workout, reporting another user's workout as not found
+func (s *workoutService) getOwnedWorkout(ctx context.Context, userID, workoutID uuid.UUID) (*domain.Workout, error) {
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get workout: %w", err)
 	}
+	if workout.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+
+	return workout, nil
+}
 
-	// Update workout status and end time
-	updates := map[string]interface{}{
-		"status":   "completed",
-		"end_time": time.Now(),
+func (s *workoutService) DeleteWorkout(ctx context.Context, userID, workoutID uuid.UUID) error {
+	workout, err := s.getOwnedWorkout(ctx, userID, workoutID)
+	if err != nil {
+		return err
 	}
 
-	if err := s.workoutRepo.Update(ctx, workoutID, updates); err != nil {
-		return fmt.Errorf("failed to finish workout: %w", err)
+	// Soft-delete the workout; its exercises and sets are kept so an undo restores them
+	if err := s.workoutRepo.Delete(ctx, workout.ID); err != nil {
+		return fmt.Errorf("failed to delete workout: %w", err)
 	}
+	recordAction(ctx, s.actionRepo, workout.UserID, domain.ActionDelete, domain.ActionEntityWorkout, workout.ID)
+	refreshDailySummaries(ctx, s.summaryService, workout.UserID, workout.StartTime)
 
 	return nil
 }
 
-func (s *workoutService) DeleteWorkout(ctx context.Context, workoutID string) error {
-	if workoutID == "" {
-		return domain.ErrInvalidInput
+// GetExerciseHistory returns every set the user logged for an exercise and its aliases,
+// oldest first, with an estimated one-rep max for weighted sets
+func (s *workoutService) GetExerciseHistory(ctx context.Context, userID, exerciseID uuid.UUID, from, to *time.Time) ([]*domain.ExerciseSetRecord, error) {
+	var startDate, endDate time.Time
+	if from != nil {
+		startDate = *from
+	}
+	if to != nil {
+		// Include the whole end day
+		endDate = to.AddDate(0, 0, 1)
+	}
+	if !startDate.IsZero() && !endDate.IsZero() && !startDate.Before(endDate) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidInput)
 	}
 
-	// Verify workout exists
-	_, err := s.workoutRepo.GetByID(ctx, workoutID)
+	exercise, err := s.workoutRepo.GetExercise(ctx, exerciseID)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			return domain.ErrNotFound
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
 		}
-		return fmt.Errorf("failed to get workout: %w", err)
+		return nil, fmt.Errorf("failed to get exercise: %w", err)
 	}
 
-	// Delete workout (cascade should handle exercises and sets)
-	if err := s.workoutRepo.Delete(ctx, workoutID); err != nil {
-		return fmt.Errorf("failed to delete workout: %w", err)
+	// An alias shares its canonical exercise's history
+	records, err := s.workoutRepo.ListExerciseSets(ctx, userID, exercise.CanonicalExerciseID(), startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise history: %w", err)
 	}
 
-	return nil
+	for _, record := range records {
+		record.Estimated1RM = domain.EstimateOneRepMax(record.Weight, record.Reps)
+	}
+
+	return records, nil
+}
+
+// GetExercise returns an exercise from the library
+func (s *workoutService) GetExercise(ctx context.Context, exerciseID uuid.UUID) (*domain.Exercise, error) {
+	exercise, err := s.workoutRepo.GetExercise(ctx, exerciseID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get exercise: %w", err)
+	}
+	return exercise, nil
+}
+
+// FindExercise returns the exercise named name, ignoring case and extra whitespace, or
+// else the library exercise with the most similar name. ErrNotFound when nothing is close.
+func (s *workoutService) FindExercise(ctx context.Context, name string) (*domain.Exercise, error) {
+	name = domain.NormalizeExerciseName(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: exercise name is required", domain.ErrInvalidInput)
+	}
+
+	exercise, err := s.workoutRepo.FindExerciseByName(ctx, name)
+	if err == nil {
+		return exercise, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to look up exercise: %w", err)
+	}
+
+	similar, err := s.workoutRepo.ListSimilarExercises(ctx, name, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up exercise: %w", err)
+	}
+	if len(similar) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return similar[0], nil
+}
+
+// similarExerciseLimit caps how many likely duplicates CreateExercise warns about
+const similarExerciseLimit = 3
+
+// CreateExercise adds an exercise to the library. The name is trimmed and its whitespace
+// collapsed; when an exercise of the same name (ignoring case) exists, that one is returned
+// and created is false. Otherwise the new exercise carries a warning for each library
+// exercise with a closely matching name, unless it was created as an alias of another.
+func (s *workoutService) CreateExercise(ctx context.Context, exercise *domain.Exercise) (*domain.Exercise, bool, error) {
+	exercise.Name = domain.NormalizeExerciseName(exercise.Name)
+	if exercise.Name == "" {
+		return nil, false, fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if exercise.Category == "" {
+		return nil, false, fmt.Errorf("%w: category is required", domain.ErrInvalidInput)
+	}
+
+	existing, err := s.workoutRepo.FindExerciseByName(ctx, exercise.Name)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, false, fmt.Errorf("failed to look up exercise: %w", err)
+	}
+
+	if exercise.CanonicalID != nil {
+		canonical, err := s.workoutRepo.GetExercise(ctx, *exercise.CanonicalID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, false, fmt.Errorf("%w: canonical exercise not found", domain.ErrInvalidInput)
+			}
+			return nil, false, fmt.Errorf("failed to get canonical exercise: %w", err)
+		}
+		// Point at the root so aliases never chain
+		canonicalID := canonical.CanonicalExerciseID()
+		exercise.CanonicalID = &canonicalID
+	}
+
+	var similar []*domain.Exercise
+	if exercise.CanonicalID == nil {
+		similar, err = s.workoutRepo.ListSimilarExercises(ctx, exercise.Name, similarExerciseLimit)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to look up similar exercises: %w", err)
+		}
+	}
+
+	if err := s.workoutRepo.CreateExercise(ctx, exercise); err != nil {
+		return nil, false, fmt.Errorf("failed to create exercise: %w", err)
+	}
+
+	for _, match := range similar {
+		exercise.Warnings = append(exercise.Warnings, fmt.Sprintf("possible duplicate of %q (%s)", match.Name, match.ID))
+	}
+
+	return exercise, true, nil
+}
+
+// GetExercisePerformance summarizes the user's history with an exercise: recent sets,
+// current estimated one-rep max and personal records
+func (s *workoutService) GetExercisePerformance(ctx context.Context, userID, exerciseID uuid.UUID) (*domain.ExercisePerformance, error) {
+	records, err := s.GetExerciseHistory(ctx, userID, exerciseID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return domain.SummarizeExercisePerformance(records), nil
+}
+
+// userWeightKg returns the user's recorded weight or a sensible default
+func (s *workoutService) userWeightKg(ctx context.Context, userID uuid.UUID) float64 {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user.WeightKg == nil || *user.WeightKg <= 0 {
+		return domain.DefaultBodyWeightKg
+	}
+	return *user.WeightKg
 }
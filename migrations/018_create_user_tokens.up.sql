@@ -0,0 +1,13 @@
+-- Create user_tokens table for single-use tokens (password reset, etc.)
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(50) NOT NULL, -- 'password_reset'
+    token_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA-256 hex of the token, never the raw token
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_tokens_user ON user_tokens(user_id);
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
@@ -0,0 +1,3 @@
+-- Remove email verification fields from users table
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,5 @@
+-- Add email verification fields to users table
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN user_tokens.purpose IS 'password_reset, email_verification';
@@ -0,0 +1,3 @@
+-- Remove profile preference fields from users table
+ALTER TABLE users DROP COLUMN IF EXISTS unit_system;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,5 @@
+-- Add profile preference fields to users table
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unit_system VARCHAR(20) NOT NULL DEFAULT 'metric';
+
+COMMENT ON COLUMN users.unit_system IS 'metric, imperial';
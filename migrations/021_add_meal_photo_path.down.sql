@@ -0,0 +1,3 @@
+-- Remove photo link from meals
+DROP INDEX IF EXISTS idx_meals_photo_path;
+ALTER TABLE meals DROP COLUMN IF EXISTS photo_path;
@@ -0,0 +1,6 @@
+-- Link meals to their photo in storage so unreferenced photos can be cleaned up
+ALTER TABLE meals ADD COLUMN IF NOT EXISTS photo_path TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_meals_photo_path ON meals(photo_path) WHERE photo_path IS NOT NULL;
+
+COMMENT ON COLUMN meals.photo_path IS 'Object path in the meal-photos bucket';
@@ -0,0 +1,2 @@
+-- Drop llm_audit table
+DROP TABLE IF EXISTS llm_audit;
@@ -0,0 +1,24 @@
+-- Operational audit trail of LLM calls, written only when auditing is enabled
+CREATE TABLE IF NOT EXISTS llm_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID, -- no foreign key so entries outlive deleted accounts for billing disputes
+    operation VARCHAR(50) NOT NULL, -- 'agent', 'meal_parse', 'food_estimate', 'vision'
+    model VARCHAR(255) NOT NULL,
+    prompt_hash VARCHAR(64) NOT NULL,
+    request JSONB NOT NULL,
+    response JSONB,
+    tools_used JSONB,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    cost_usd DECIMAL(12, 6),
+    status VARCHAR(20) NOT NULL, -- 'success', 'error'
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_audit_created_at ON llm_audit(created_at);
+CREATE INDEX IF NOT EXISTS idx_llm_audit_user_created ON llm_audit(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_llm_audit_operation ON llm_audit(operation);
+CREATE INDEX IF NOT EXISTS idx_llm_audit_prompt_hash ON llm_audit(prompt_hash);
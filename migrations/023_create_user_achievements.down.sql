@@ -0,0 +1,2 @@
+-- Drop user_achievements table
+DROP TABLE IF EXISTS user_achievements;
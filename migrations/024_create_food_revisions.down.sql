@@ -0,0 +1,2 @@
+-- Drop food_revisions table
+DROP TABLE IF EXISTS food_revisions;
@@ -0,0 +1,13 @@
+-- Change history of foods; each row holds the fields one update changed
+CREATE TABLE IF NOT EXISTS food_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    food_id UUID NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_fields JSONB NOT NULL, -- ["calories", "protein"]
+    before JSONB NOT NULL,
+    after JSONB NOT NULL,
+    nutrition_changed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_food_revisions_food ON food_revisions(food_id, created_at DESC);
@@ -0,0 +1,8 @@
+-- Remove food snapshots from meal_food_items
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_fat;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_carbohydrates;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_protein;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_calories;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_serving_unit;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_serving_size;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_name;
@@ -0,0 +1,20 @@
+-- Snapshot of each logged food's serving and per-serving macros at log time
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_name VARCHAR(255);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_serving_size DECIMAL(10,2);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_serving_unit VARCHAR(50);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_calories DECIMAL(10,2);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_protein DECIMAL(10,2);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_carbohydrates DECIMAL(10,2);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_fat DECIMAL(10,2);
+
+-- Existing items are snapshotted from the food as it is now, the closest record available
+UPDATE meal_food_items AS mfi
+SET snapshot_name = f.name,
+    snapshot_serving_size = f.serving_size,
+    snapshot_serving_unit = f.serving_unit,
+    snapshot_calories = f.calories,
+    snapshot_protein = f.protein,
+    snapshot_carbohydrates = f.carbohydrates,
+    snapshot_fat = f.fat
+FROM foods AS f
+WHERE f.id = mfi.food_id AND mfi.snapshot_name IS NULL;
@@ -0,0 +1,2 @@
+-- Drop user_actions table; deleted_at columns are kept so soft-deleted rows stay hidden
+DROP TABLE IF EXISTS user_actions;
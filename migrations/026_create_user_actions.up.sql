@@ -0,0 +1,23 @@
+-- Append-only log of each user's undoable creates and deletes
+CREATE TABLE IF NOT EXISTS user_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL, -- create, delete, undo
+    entity_type VARCHAR(50) NOT NULL, -- meal, activity, metric, workout
+    entity_id UUID NOT NULL,
+    undoes_id UUID UNIQUE REFERENCES user_actions(id) ON DELETE CASCADE, -- set on undo entries
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_actions_user ON user_actions(user_id, created_at DESC);
+
+-- Undoable entities are soft-deleted so a delete can be reversed
+ALTER TABLE meals ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE metrics ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_meals_deleted_at ON meals(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_activities_deleted_at ON activities(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_metrics_deleted_at ON metrics(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_workouts_deleted_at ON workouts(deleted_at);
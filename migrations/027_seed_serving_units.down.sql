@@ -0,0 +1,9 @@
+-- Remove the seeded units nothing refers to; the columns added for the
+-- application model are kept
+DELETE FROM serving_units su
+WHERE su.name IN ('g', 'oz', 'cup', 'tbsp', 'tsp', 'ml', 'piece', 'slice')
+  AND NOT EXISTS (SELECT 1 FROM food_ingredients fi WHERE fi.serving_unit_id = su.id)
+  AND NOT EXISTS (SELECT 1 FROM meal_food_items mfi WHERE mfi.serving_unit_id = su.id);
+
+DROP INDEX IF EXISTS idx_serving_units_deleted_at;
+DROP INDEX IF EXISTS idx_serving_units_category;
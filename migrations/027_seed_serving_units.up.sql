@@ -0,0 +1,28 @@
+-- Bring serving_units in line with the application model
+ALTER TABLE serving_units ADD COLUMN IF NOT EXISTS display_name VARCHAR(100);
+ALTER TABLE serving_units ADD COLUMN IF NOT EXISTS category VARCHAR(50);
+ALTER TABLE serving_units ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE serving_units ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE serving_units ADD COLUMN IF NOT EXISTS unit_type VARCHAR(50);
+ALTER TABLE serving_units ALTER COLUMN unit_type DROP NOT NULL;
+
+UPDATE serving_units SET category = unit_type WHERE category IS NULL;
+UPDATE serving_units SET display_name = name WHERE display_name IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_serving_units_category ON serving_units(category);
+CREATE INDEX IF NOT EXISTS idx_serving_units_deleted_at ON serving_units(deleted_at);
+
+-- Seed a standard set of units when none exist yet
+INSERT INTO serving_units (name, display_name, category, unit_type)
+SELECT v.name, v.display_name, v.category, v.category
+FROM (VALUES
+    ('g', 'Gram', 'weight'),
+    ('oz', 'Ounce', 'weight'),
+    ('cup', 'Cup', 'volume'),
+    ('tbsp', 'Tablespoon', 'volume'),
+    ('tsp', 'Teaspoon', 'volume'),
+    ('ml', 'Milliliter', 'volume'),
+    ('piece', 'Piece', 'count'),
+    ('slice', 'Slice', 'count')
+) AS v(name, display_name, category)
+WHERE NOT EXISTS (SELECT 1 FROM serving_units);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_llm_audit_request_id;
+
+ALTER TABLE llm_audit DROP COLUMN IF EXISTS request_id;
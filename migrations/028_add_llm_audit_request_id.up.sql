@@ -0,0 +1,4 @@
+-- Correlate each LLM call with the HTTP request that made it
+ALTER TABLE llm_audit ADD COLUMN IF NOT EXISTS request_id VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_llm_audit_request_id ON llm_audit(request_id);
@@ -0,0 +1,2 @@
+-- Drop workout_pauses table
+DROP TABLE IF EXISTS workout_pauses;
@@ -0,0 +1,10 @@
+-- Breaks taken during a workout; they are excluded from its duration
+CREATE TABLE IF NOT EXISTS workout_pauses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workout_id UUID NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    paused_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_pauses_workout_id ON workout_pauses(workout_id);
@@ -0,0 +1,4 @@
+-- Drop food search trigram indexes
+DROP INDEX IF EXISTS idx_foods_description_trgm;
+DROP INDEX IF EXISTS idx_foods_brand_trgm;
+DROP INDEX IF EXISTS idx_foods_name_trgm;
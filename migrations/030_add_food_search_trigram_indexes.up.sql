@@ -0,0 +1,8 @@
+-- Trigram indexes let food search match substrings of name, brand and description
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE foods ADD COLUMN IF NOT EXISTS description TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_foods_name_trgm ON foods USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_foods_brand_trgm ON foods USING gin (brand gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_foods_description_trgm ON foods USING gin (description gin_trgm_ops);
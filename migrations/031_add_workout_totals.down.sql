@@ -0,0 +1,5 @@
+-- Remove stored workout totals
+ALTER TABLE workouts DROP COLUMN IF EXISTS personal_record_count;
+ALTER TABLE workouts DROP COLUMN IF EXISTS total_volume;
+ALTER TABLE workouts DROP COLUMN IF EXISTS total_reps;
+ALTER TABLE workouts DROP COLUMN IF EXISTS total_sets;
@@ -0,0 +1,7 @@
+-- Store a finished workout's totals so summaries need not be recomputed from its sets
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS total_sets INTEGER;
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS total_reps INTEGER;
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS total_volume DECIMAL(12,2);
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS personal_record_count INTEGER;
+
+COMMENT ON COLUMN workouts.total_volume IS 'Weight x reps summed over sets, in kg';
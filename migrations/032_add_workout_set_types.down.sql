@@ -0,0 +1,5 @@
+-- Remove set types and groups
+DROP INDEX IF EXISTS idx_workout_sets_group_id;
+ALTER TABLE workout_sets DROP CONSTRAINT IF EXISTS check_workout_set_type;
+ALTER TABLE workout_sets DROP COLUMN IF EXISTS group_id;
+ALTER TABLE workout_sets DROP COLUMN IF EXISTS set_type;
@@ -0,0 +1,10 @@
+-- Type sets and group them into supersets and drop sets
+ALTER TABLE workout_sets ADD COLUMN IF NOT EXISTS set_type VARCHAR(20) NOT NULL DEFAULT 'normal';
+ALTER TABLE workout_sets ADD COLUMN IF NOT EXISTS group_id UUID;
+
+ALTER TABLE workout_sets ADD CONSTRAINT check_workout_set_type
+    CHECK (set_type IN ('normal', 'warmup', 'drop', 'superset'));
+
+CREATE INDEX IF NOT EXISTS idx_workout_sets_group_id ON workout_sets(group_id) WHERE group_id IS NOT NULL;
+
+COMMENT ON COLUMN workout_sets.group_id IS 'Shared by the sets of one superset or drop set';
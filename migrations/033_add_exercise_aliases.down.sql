@@ -0,0 +1,5 @@
+-- Remove exercise aliases
+DROP INDEX IF EXISTS idx_exercises_name_trgm;
+DROP INDEX IF EXISTS idx_exercises_lower_name;
+DROP INDEX IF EXISTS idx_exercises_canonical_id;
+ALTER TABLE exercises DROP COLUMN IF EXISTS canonical_id;
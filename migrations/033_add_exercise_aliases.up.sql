@@ -0,0 +1,10 @@
+-- Let exercises alias a canonical exercise and find near-duplicate names
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS canonical_id UUID REFERENCES exercises(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_exercises_canonical_id ON exercises(canonical_id) WHERE canonical_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_exercises_lower_name ON exercises(lower(name));
+CREATE INDEX IF NOT EXISTS idx_exercises_name_trgm ON exercises USING gin (lower(name) gin_trgm_ops);
+
+COMMENT ON COLUMN exercises.canonical_id IS 'Exercise whose history and personal records this alias shares';
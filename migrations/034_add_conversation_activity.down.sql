@@ -0,0 +1,2 @@
+-- Remove the conversation activity index; backfilled updated_at values are kept
+DROP INDEX IF EXISTS idx_conversations_user_updated;
@@ -0,0 +1,11 @@
+-- Conversations list by latest activity; updated_at now moves with each new message
+UPDATE conversations c
+SET updated_at = m.last_message_at
+FROM (
+    SELECT conversation_id, MAX(created_at) AS last_message_at
+    FROM messages
+    GROUP BY conversation_id
+) m
+WHERE m.conversation_id = c.id AND m.last_message_at > c.updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_conversations_user_updated ON conversations(user_id, updated_at DESC);
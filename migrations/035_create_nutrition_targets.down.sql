@@ -0,0 +1,2 @@
+-- Drop nutrition_targets table
+DROP TABLE IF EXISTS nutrition_targets;
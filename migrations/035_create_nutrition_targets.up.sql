@@ -0,0 +1,12 @@
+-- Create nutrition_targets table: one daily calorie, macro and water target per user
+CREATE TABLE IF NOT EXISTS nutrition_targets (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    calories DECIMAL(10,2) NOT NULL,
+    protein DECIMAL(10,2) NOT NULL,
+    carbohydrates DECIMAL(10,2) NOT NULL,
+    fat DECIMAL(10,2) NOT NULL,
+    water_ml DECIMAL(10,2) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual', -- 'manual', 'profile'
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,8 @@
+-- Create job_runs table: the latest run of each scheduled job, shared by all instances
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(100) PRIMARY KEY,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+-- Remove saturated fat and sodium totals
+ALTER TABLE daily_summaries DROP COLUMN IF EXISTS total_sodium;
+ALTER TABLE daily_summaries DROP COLUMN IF EXISTS total_saturated_fat;
+ALTER TABLE meals DROP COLUMN IF EXISTS total_sodium;
+ALTER TABLE meals DROP COLUMN IF EXISTS total_saturated_fat;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS sodium;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS saturated_fat;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_sodium;
+ALTER TABLE meal_food_items DROP COLUMN IF EXISTS snapshot_saturated_fat;
@@ -0,0 +1,36 @@
+-- Track saturated fat (g) and sodium (mg) alongside the macros on logged foods, meals and daily summaries
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_saturated_fat DECIMAL(10,2);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS snapshot_sodium DECIMAL(10,2);
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS saturated_fat DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE meal_food_items ADD COLUMN IF NOT EXISTS sodium DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE meals ADD COLUMN IF NOT EXISTS total_saturated_fat DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE meals ADD COLUMN IF NOT EXISTS total_sodium DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN IF NOT EXISTS total_saturated_fat DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN IF NOT EXISTS total_sodium DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Existing items take the values from the food as it is now, the closest record available;
+-- a quantity in the serving unit is divided by the serving size, any other is a count of servings
+UPDATE meal_food_items AS mfi
+SET snapshot_saturated_fat = COALESCE(f.saturated_fat, 0),
+    snapshot_sodium = COALESCE(f.sodium, 0),
+    saturated_fat = COALESCE(f.saturated_fat, 0) * servings.count,
+    sodium = COALESCE(f.sodium, 0) * servings.count
+FROM foods AS f,
+     LATERAL (SELECT CASE
+         WHEN mfi.snapshot_serving_size > 0 AND LOWER(TRIM(mfi.unit)) = LOWER(mfi.snapshot_serving_unit)
+             THEN mfi.quantity / mfi.snapshot_serving_size
+         ELSE mfi.quantity
+     END AS count) AS servings
+WHERE f.id = mfi.food_id AND mfi.snapshot_sodium IS NULL;
+
+UPDATE meals AS m
+SET total_saturated_fat = totals.saturated_fat,
+    total_sodium = totals.sodium
+FROM (
+    SELECT meal_id, SUM(saturated_fat) AS saturated_fat, SUM(sodium) AS sodium
+    FROM meal_food_items
+    GROUP BY meal_id
+) AS totals
+WHERE totals.meal_id = m.id;
+
+-- Stored daily summaries pick up the new totals when they are next recalculated
@@ -0,0 +1,9 @@
+-- Remove demo seed flags
+DROP INDEX IF EXISTS idx_metrics_demo;
+DROP INDEX IF EXISTS idx_workouts_demo;
+DROP INDEX IF EXISTS idx_activities_demo;
+DROP INDEX IF EXISTS idx_meals_demo;
+ALTER TABLE metrics DROP COLUMN IF EXISTS is_demo;
+ALTER TABLE workouts DROP COLUMN IF EXISTS is_demo;
+ALTER TABLE activities DROP COLUMN IF EXISTS is_demo;
+ALTER TABLE meals DROP COLUMN IF EXISTS is_demo;
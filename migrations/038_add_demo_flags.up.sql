@@ -0,0 +1,11 @@
+-- Flag sample records added by the demo seed so they can be cleared without touching the user's own data
+ALTER TABLE meals ADD COLUMN IF NOT EXISTS is_demo BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS is_demo BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS is_demo BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE metrics ADD COLUMN IF NOT EXISTS is_demo BOOLEAN NOT NULL DEFAULT false;
+
+-- Few rows are ever flagged, so partial indexes keep the lookups small
+CREATE INDEX IF NOT EXISTS idx_meals_demo ON meals(user_id) WHERE is_demo;
+CREATE INDEX IF NOT EXISTS idx_activities_demo ON activities(user_id) WHERE is_demo;
+CREATE INDEX IF NOT EXISTS idx_workouts_demo ON workouts(user_id) WHERE is_demo;
+CREATE INDEX IF NOT EXISTS idx_metrics_demo ON metrics(user_id) WHERE is_demo;
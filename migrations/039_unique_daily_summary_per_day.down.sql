@@ -0,0 +1,2 @@
+-- Drop the one-summary-per-day index
+DROP INDEX IF EXISTS idx_user_date;
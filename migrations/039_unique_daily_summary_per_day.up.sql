@@ -0,0 +1,9 @@
+-- One stored summary per user and day, so concurrent recomputes upsert the same row.
+-- Duplicates left by earlier races are removed first, keeping the most recently updated.
+DELETE FROM daily_summaries AS d
+USING daily_summaries AS newer
+WHERE d.user_id = newer.user_id
+  AND d.date = newer.date
+  AND (d.updated_at, d.id) < (newer.updated_at, newer.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_date ON daily_summaries(user_id, date);
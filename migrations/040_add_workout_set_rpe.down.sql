@@ -0,0 +1,3 @@
+-- Remove per-set RPE
+ALTER TABLE workout_sets DROP CONSTRAINT IF EXISTS check_workout_set_rpe;
+ALTER TABLE workout_sets DROP COLUMN IF EXISTS rpe;
@@ -0,0 +1,7 @@
+-- Rate of perceived exertion per set, on the 0-10 scale
+ALTER TABLE workout_sets ADD COLUMN IF NOT EXISTS rpe DECIMAL(3,1);
+
+ALTER TABLE workout_sets ADD CONSTRAINT check_workout_set_rpe
+    CHECK (rpe IS NULL OR (rpe >= 0 AND rpe <= 10));
+
+COMMENT ON COLUMN workout_sets.rpe IS 'Rate of perceived exertion, 0-10; 10 is a set taken to failure';
@@ -0,0 +1,3 @@
+-- Remove conversation summaries
+ALTER TABLE conversations DROP COLUMN IF EXISTS summarized_through;
+ALTER TABLE conversations DROP COLUMN IF EXISTS summary;
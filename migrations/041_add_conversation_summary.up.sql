@@ -0,0 +1,5 @@
+-- Rolling summary of older messages, sent to the model in their place
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS summary TEXT;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS summarized_through TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN conversations.summarized_through IS 'created_at of the last message the summary covers';
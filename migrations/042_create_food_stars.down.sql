@@ -0,0 +1,2 @@
+-- Remove food stars
+DROP TABLE IF EXISTS food_stars;
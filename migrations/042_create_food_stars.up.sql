@@ -0,0 +1,9 @@
+-- Foods a user starred for quick adding; any food can be starred, not only the user's own
+CREATE TABLE IF NOT EXISTS food_stars (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    food_id UUID NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, food_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_food_stars_food ON food_stars(food_id);
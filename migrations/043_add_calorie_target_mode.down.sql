@@ -0,0 +1,3 @@
+-- Remove calorie target mode settings
+ALTER TABLE users DROP COLUMN IF EXISTS exercise_calorie_fraction;
+ALTER TABLE users DROP COLUMN IF EXISTS calorie_target_mode;
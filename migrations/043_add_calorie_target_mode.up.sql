@@ -0,0 +1,6 @@
+-- Whether the daily calorie target grows with the calories burned that day
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calorie_target_mode VARCHAR(20) NOT NULL DEFAULT 'static';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS exercise_calorie_fraction DECIMAL(3,2);
+
+COMMENT ON COLUMN users.calorie_target_mode IS 'static, dynamic';
+COMMENT ON COLUMN users.exercise_calorie_fraction IS 'Share of burned calories added to a dynamic target; NULL for the default of 0.5';
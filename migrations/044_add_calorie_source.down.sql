@@ -0,0 +1,3 @@
+-- Remove calorie burn sources
+ALTER TABLE workouts DROP COLUMN IF EXISTS calorie_source;
+ALTER TABLE activities DROP COLUMN IF EXISTS calorie_source;
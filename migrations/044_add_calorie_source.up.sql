@@ -0,0 +1,6 @@
+-- Where a session's calories burned came from, so overlapping sessions trust the better source
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS calorie_source VARCHAR(20);
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS calorie_source VARCHAR(20);
+
+COMMENT ON COLUMN activities.calorie_source IS 'device, manual, estimate; NULL is treated as manual';
+COMMENT ON COLUMN workouts.calorie_source IS 'device, manual, estimate; NULL is manual when calories_burned is set, estimate otherwise';
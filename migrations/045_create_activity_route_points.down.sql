@@ -0,0 +1,2 @@
+-- Drop activity_route_points table
+DROP TABLE IF EXISTS activity_route_points;
@@ -0,0 +1,11 @@
+-- GPS tracks of activities, one row per recorded point in route order
+CREATE TABLE IF NOT EXISTS activity_route_points (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    activity_id UUID NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    latitude DECIMAL(9,6) NOT NULL,
+    longitude DECIMAL(9,6) NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_route_points_activity_id ON activity_route_points(activity_id, sequence);
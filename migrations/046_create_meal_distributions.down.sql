@@ -0,0 +1,2 @@
+-- Drop meal_distributions table
+DROP TABLE IF EXISTS meal_distributions;
@@ -0,0 +1,11 @@
+-- Create meal_distributions table: each user's share of the day's target per meal type, in percent
+CREATE TABLE IF NOT EXISTS meal_distributions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    breakfast DECIMAL(5,2) NOT NULL,
+    lunch DECIMAL(5,2) NOT NULL,
+    dinner DECIMAL(5,2) NOT NULL,
+    snack DECIMAL(5,2) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual', -- 'manual'
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
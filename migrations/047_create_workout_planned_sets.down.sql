@@ -0,0 +1,2 @@
+-- Drop workout_planned_sets table
+DROP TABLE IF EXISTS workout_planned_sets;
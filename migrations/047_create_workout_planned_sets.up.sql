@@ -0,0 +1,16 @@
+-- Create workout_planned_sets table: sets suggested for a workout exercise before any are logged
+CREATE TABLE IF NOT EXISTS workout_planned_sets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workout_exercise_id UUID NOT NULL REFERENCES workout_exercises(id) ON DELETE CASCADE,
+    set_number INTEGER NOT NULL,
+    set_type VARCHAR(20) NOT NULL DEFAULT 'normal',
+    reps INTEGER,
+    weight DECIMAL(10,2), -- suggested, in kg
+    duration_seconds INTEGER,
+    distance DECIMAL(10,2), -- meters
+    rest_seconds INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_workout_planned_set_type CHECK (set_type IN ('normal', 'warmup', 'drop', 'superset'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_planned_sets_exercise ON workout_planned_sets(workout_exercise_id, set_number);
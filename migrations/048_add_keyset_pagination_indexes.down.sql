@@ -0,0 +1,4 @@
+-- Drop keyset pagination indexes
+DROP INDEX IF EXISTS idx_messages_conversation_created_id;
+DROP INDEX IF EXISTS idx_activities_user_created_id;
+DROP INDEX IF EXISTS idx_meals_user_created_id;
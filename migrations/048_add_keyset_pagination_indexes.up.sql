@@ -0,0 +1,5 @@
+-- Keyset pages of meals, activities and messages seek on (created_at, id) instead of
+-- scanning past an offset
+CREATE INDEX IF NOT EXISTS idx_meals_user_created_id ON meals (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_activities_user_created_id ON activities (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_created_id ON messages (conversation_id, created_at, id);
@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// captureEventPublisher records published events
+type captureEventPublisher struct {
+	events []string
+}
+
+func (p *captureEventPublisher) Publish(ctx context.Context, eventType string, payload interface{}) error {
+	p.events = append(p.events, eventType)
+	return nil
+}
+
+// seedAccountData creates one row in every user-owned table
+func seedAccountData(t *testing.T, db *gorm.DB, userID uuid.UUID) {
+	food := CreateTestFood(t, db, "Seed Food "+userID.String(), 100)
+	exercise := CreateTestExercise(t, db, "Seed Exercise "+userID.String(), "strength")
+
+	meal := CreateTestMeal(t, db, userID, "lunch")
+	require.NoError(t, db.Create(&domain.MealFoodItem{
+		MealID: meal.ID, FoodID: food.ID, Quantity: 1, Unit: "serving", Calories: 100,
+	}).Error)
+
+	activity := CreateTestActivity(t, db, userID, "running")
+	require.NoError(t, db.Create(&[]domain.RoutePoint{
+		{ActivityID: activity.ID, Sequence: 0, Latitude: 52.52, Longitude: 13.405, RecordedAt: activity.StartTime},
+		{ActivityID: activity.ID, Sequence: 1, Latitude: 52.521, Longitude: 13.406, RecordedAt: activity.StartTime.Add(time.Minute)},
+	}).Error)
+
+	workout := &domain.Workout{UserID: userID, Name: "Seed Workout", StartTime: time.Now()}
+	require.NoError(t, db.Create(workout).Error)
+	workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: 1}
+	require.NoError(t, db.Create(workoutExercise).Error)
+	require.NoError(t, db.Create(&domain.WorkoutSet{
+		WorkoutExerciseID: workoutExercise.ID, SetNumber: 1, Reps: intPtr(10), Weight: float64Ptr(50),
+	}).Error)
+
+	require.NoError(t, db.Create(&domain.PlannedSet{
+		WorkoutExerciseID: workoutExercise.ID, SetNumber: 2, Reps: intPtr(10), Weight: float64Ptr(52.5),
+	}).Error)
+
+	resumedAt := time.Now()
+	require.NoError(t, db.Create(&domain.WorkoutPause{
+		WorkoutID: workout.ID, PausedAt: resumedAt.Add(-5 * time.Minute), ResumedAt: &resumedAt,
+	}).Error)
+
+	require.NoError(t, db.Create(&domain.Metric{
+		UserID: userID, MetricType: "weight", Value: 80, Unit: "kg", MeasuredAt: time.Now(),
+	}).Error)
+	require.NoError(t, db.Create(&domain.DailySummary{UserID: userID, Date: time.Now()}).Error)
+	require.NoError(t, db.Create(&domain.NutritionTarget{
+		UserID: userID, Calories: 2000, Protein: 150, Carbohydrates: 200, Fat: 67, WaterMl: 2500, Source: domain.NutritionTargetSourceManual,
+	}).Error)
+	require.NoError(t, db.Create(&domain.MealDistribution{
+		UserID: userID, Breakfast: 25, Lunch: 35, Dinner: 30, Snack: 10, Source: domain.MealDistributionSourceManual,
+	}).Error)
+	require.NoError(t, db.Create(&domain.Goal{
+		UserID: userID, GoalType: "weight_loss", Description: "Lose weight", TargetValue: 75, Unit: "kg", StartDate: time.Now(),
+	}).Error)
+
+	conversation := &domain.Conversation{UserID: userID}
+	require.NoError(t, db.Create(conversation).Error)
+	require.NoError(t, db.Create(&domain.Message{
+		ConversationID: conversation.ID, Role: "user", Content: "Hello coach",
+	}).Error)
+
+	require.NoError(t, db.Create(&domain.UserToken{
+		UserID: userID, Purpose: domain.TokenPurposePasswordReset, TokenHash: userID.String(), ExpiresAt: time.Now().Add(time.Hour),
+	}).Error)
+
+	response := `{"content":"Hi there"}`
+	toolsUsed := `["get_remaining_macros"]`
+	require.NoError(t, db.Create(&domain.LLMAuditEntry{
+		UserID: &userID, Operation: "agent", Model: "test-model", PromptHash: userID.String(),
+		Request: `{"messages":[{"role":"user","content":"Hello coach"}]}`, Response: &response, ToolsUsed: &toolsUsed,
+		TotalTokens: 42, Status: "success",
+	}).Error)
+}
+
+// countRows counts rows matching the query in a table
+func countRows(t *testing.T, db *gorm.DB, table, query string, args ...interface{}) int64 {
+	var count int64
+	require.NoError(t, db.Table(table).Where(query, args...).Count(&count).Error)
+	return count
+}
+
+func TestAccountDeletion(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	db := testDB.DB
+	events := &captureEventPublisher{}
+	accountService := services.NewAccountService(
+		postgres.NewUserRepository(db),
+		postgres.NewAccountRepository(db),
+		nil,
+		events,
+	)
+
+	t.Run("Wrong password keeps the account", func(t *testing.T) {
+		user := CreateTestUser(t, db, "keep@example.com")
+
+		err := accountService.DeleteAccount(ctx, user.ID.String(), "not_my_password")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+		assert.Equal(t, int64(1), countRows(t, db, "users", "id = ?", user.ID))
+	})
+
+	t.Run("Export includes all user data", func(t *testing.T) {
+		user := CreateTestUser(t, db, "export@example.com")
+		seedAccountData(t, db, user.ID)
+
+		export, err := accountService.ExportAccount(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, export.User.ID)
+		assert.Len(t, export.Meals, 1)
+		assert.Len(t, export.Meals[0].FoodItems, 1)
+		require.Len(t, export.Activities, 1)
+		assert.Len(t, export.Activities[0].Route, 2)
+		require.Len(t, export.Workouts, 1)
+		require.Len(t, export.Workouts[0].Exercises, 1)
+		assert.Len(t, export.Workouts[0].Exercises[0].Sets, 1)
+		assert.Len(t, export.Workouts[0].Exercises[0].PlannedSets, 1)
+		assert.Len(t, export.Workouts[0].Pauses, 1)
+		assert.Len(t, export.Metrics, 1)
+		assert.Len(t, export.DailySummaries, 1)
+		assert.Len(t, export.Goals, 1)
+		require.NotNil(t, export.NutritionTarget)
+		assert.Equal(t, 2000.0, export.NutritionTarget.Calories)
+		require.NotNil(t, export.MealDistribution)
+		assert.Equal(t, 35.0, export.MealDistribution.Lunch)
+		require.Len(t, export.Conversations, 1)
+		assert.Len(t, export.Conversations[0].Messages, 1)
+	})
+
+	t.Run("Delete purges all rows without orphans", func(t *testing.T) {
+		user := CreateTestUser(t, db, "delete@example.com")
+		seedAccountData(t, db, user.ID)
+
+		other := CreateTestUser(t, db, "other@example.com")
+		seedAccountData(t, db, other.ID)
+
+		err := accountService.DeleteAccount(ctx, user.ID.String(), "test_password123")
+		require.NoError(t, err)
+		assert.Contains(t, events.events, domain.EventAccountDeleted)
+
+		// Nothing owned by the deleted user remains
+		assert.Zero(t, countRows(t, db, "users", "id = ?", user.ID))
+		for _, table := range []string{"meals", "activities", "workouts", "metrics", "daily_summaries", "goals", "conversations", "user_tokens", "nutrition_targets", "meal_distributions", "llm_audit"} {
+			assert.Zero(t, countRows(t, db, table, "user_id = ?", user.ID), table)
+		}
+
+		// No child rows point at missing parents
+		assert.Zero(t, countRows(t, db, "meal_food_items", "meal_id NOT IN (SELECT id FROM meals)"))
+		assert.Zero(t, countRows(t, db, "workout_exercises", "workout_id NOT IN (SELECT id FROM workouts)"))
+		assert.Zero(t, countRows(t, db, "workout_pauses", "workout_id NOT IN (SELECT id FROM workouts)"))
+		assert.Zero(t, countRows(t, db, "workout_planned_sets", "workout_exercise_id NOT IN (SELECT id FROM workout_exercises)"))
+		assert.Zero(t, countRows(t, db, "workout_sets", "workout_exercise_id NOT IN (SELECT id FROM workout_exercises)"))
+		assert.Zero(t, countRows(t, db, "activity_route_points", "activity_id NOT IN (SELECT id FROM activities)"))
+		assert.Zero(t, countRows(t, db, "messages", "conversation_id NOT IN (SELECT id FROM conversations)"))
+
+		// LLM calls stay on record for billing, stripped of the user and the conversation
+		assert.Equal(t, int64(1), countRows(t, db, "llm_audit",
+			"prompt_hash = ? AND user_id IS NULL AND request = '{}'::jsonb AND response IS NULL AND tools_used IS NULL AND total_tokens = 42", user.ID.String()))
+
+		// Other users are untouched
+		assert.Equal(t, int64(1), countRows(t, db, "users", "id = ?", other.ID))
+		assert.Equal(t, int64(1), countRows(t, db, "meals", "user_id = ?", other.ID))
+		assert.Equal(t, int64(1), countRows(t, db, "workouts", "user_id = ?", other.ID))
+		assert.Equal(t, int64(1), countRows(t, db, "conversations", "user_id = ?", other.ID))
+		assert.Equal(t, int64(1), countRows(t, db, "nutrition_targets", "user_id = ?", other.ID))
+		assert.Equal(t, int64(1), countRows(t, db, "meal_distributions", "user_id = ?", other.ID))
+		assert.Equal(t, int64(1), countRows(t, db, "llm_audit", "user_id = ? AND response IS NOT NULL", other.ID))
+	})
+}
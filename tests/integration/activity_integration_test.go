@@ -1,11 +1,13 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"fitness-tracker/internal/adapters/repositories/postgres"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -288,3 +290,236 @@ func TestActivityStatistics(t *testing.T) {
 		assert.Equal(t, 900.0, totalCalories)
 	})
 }
+
+func TestActivityTimeValidation(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "activity_times@example.com")
+	activityService := services.NewActivityService(
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	end := start.Add(45 * time.Minute)
+
+	t.Run("Duration is derived from the interval", func(t *testing.T) {
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "running",
+			StartTime:    start,
+			EndTime:      &end,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, activity.DurationMinutes)
+		assert.Equal(t, 45, *activity.DurationMinutes)
+	})
+
+	t.Run("End before start is rejected", func(t *testing.T) {
+		before := start.Add(-time.Minute)
+		_, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "running",
+			StartTime:    start,
+			EndTime:      &before,
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Duration must match the interval", func(t *testing.T) {
+		_, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType:    "running",
+			StartTime:       start,
+			EndTime:         &end,
+			DurationMinutes: intPtr(60),
+		})
+		require.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "does not match")
+
+		// A minute either way is rounding
+		_, err = activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType:    "running",
+			StartTime:       start,
+			EndTime:         &end,
+			DurationMinutes: intPtr(46),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Updates are checked and recompute the duration", func(t *testing.T) {
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "cycling",
+			StartTime:    start,
+			EndTime:      &end,
+		})
+		require.NoError(t, err)
+
+		updated, err := activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{
+			"end_time": start.Add(90 * time.Minute).Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 90, *updated.DurationMinutes)
+
+		_, err = activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{
+			"duration_minutes": float64(30),
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{
+			"start_time": start.Add(2 * time.Hour).Format(time.RFC3339),
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Times more than a day ahead are rejected", func(t *testing.T) {
+		nextWeek := time.Now().AddDate(0, 0, 7)
+		_, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "running",
+			StartTime:    nextWeek,
+		})
+		require.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "start_time")
+
+		lastYear := time.Now().AddDate(-1, 0, 0)
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "running",
+			StartTime:    lastYear,
+		})
+		require.NoError(t, err)
+
+		_, err = activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{
+			"end_time": nextWeek.Format(time.RFC3339),
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestActivityPace(t *testing.T) {
+	distance := func(km float64) *float64 { return &km }
+
+	t.Run("Plausible paces pass", func(t *testing.T) {
+		for _, activity := range []domain.Activity{
+			{ActivityType: "running", Distance: distance(5), DurationMinutes: intPtr(25)},
+			{ActivityType: "walking", Distance: distance(4), DurationMinutes: intPtr(50)},
+			{ActivityType: "cycling", Distance: distance(40), DurationMinutes: intPtr(75)},
+			{ActivityType: "yoga", DurationMinutes: intPtr(60)},
+			{ActivityType: "running", Distance: distance(5)},
+		} {
+			assert.NoError(t, activity.CheckPace(), "%s", activity.ActivityType)
+		}
+	})
+
+	t.Run("Impossible speeds are rejected", func(t *testing.T) {
+		run := domain.Activity{ActivityType: "running", Distance: distance(5), DurationMinutes: intPtr(2)}
+		err := run.CheckPace()
+		require.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "150.0 km/h")
+
+		walk := domain.Activity{ActivityType: "walking", Distance: distance(10), DurationMinutes: intPtr(30)}
+		assert.ErrorIs(t, walk.CheckPace(), domain.ErrInvalidInput)
+
+		// Fine on a bike, not on foot
+		ride := domain.Activity{ActivityType: "cycling", Distance: distance(20), DurationMinutes: intPtr(30)}
+		assert.NoError(t, ride.CheckPace())
+	})
+}
+
+func TestActivityRoute(t *testing.T) {
+	start := time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC)
+	// Due north along a meridian: 0.009 degrees of latitude is about 1 km
+	route := func(points, secondsApart int) []domain.RoutePoint {
+		track := make([]domain.RoutePoint, points)
+		for i := range track {
+			track[i] = domain.RoutePoint{
+				Latitude:   52.0 + 0.009*float64(i),
+				Longitude:  4.9,
+				RecordedAt: start.Add(time.Duration(i*secondsApart) * time.Second),
+			}
+		}
+		return track
+	}
+
+	t.Run("Distance is measured along the route", func(t *testing.T) {
+		assert.InDelta(t, 5.0, domain.RouteDistanceKm(route(6, 300)), 0.01)
+		assert.Zero(t, domain.RouteDistanceKm(route(1, 300)))
+	})
+
+	t.Run("Invalid routes are rejected", func(t *testing.T) {
+		assert.ErrorIs(t, domain.ValidateRoute(route(1, 300)), domain.ErrInvalidInput)
+
+		offMap := route(3, 300)
+		offMap[1].Latitude = 91
+		assert.ErrorIs(t, domain.ValidateRoute(offMap), domain.ErrInvalidInput)
+
+		backwards := route(3, 300)
+		backwards[2].RecordedAt = start
+		assert.ErrorIs(t, domain.ValidateRoute(backwards), domain.ErrInvalidInput)
+	})
+
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "activity_route@example.com")
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	activityService := services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+
+	t.Run("Route sets distance and duration and is stored", func(t *testing.T) {
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "running",
+			StartTime:    start,
+			Route:        route(6, 300),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, activity.Distance)
+		assert.InDelta(t, 5.0, *activity.Distance, 0.01)
+		require.NotNil(t, activity.DurationMinutes)
+		assert.Equal(t, 25, *activity.DurationMinutes)
+
+		stored, err := activityRepo.GetByID(ctx, activity.ID)
+		require.NoError(t, err)
+		require.Len(t, stored.Route, 6)
+		for i, point := range stored.Route {
+			assert.Equal(t, i, point.Sequence)
+		}
+		assert.InDelta(t, 52.045, stored.Route[5].Latitude, 0.000001)
+	})
+
+	t.Run("A given distance is kept", func(t *testing.T) {
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "cycling",
+			StartTime:    start,
+			Distance:     func(km float64) *float64 { return &km }(5.2),
+			Route:        route(6, 300),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 5.2, *activity.Distance)
+	})
+
+	t.Run("A route covered too fast is rejected", func(t *testing.T) {
+		// 5 km in 2 minutes
+		_, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "running",
+			StartTime:    start,
+			Route:        route(6, 24),
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Updates that make the pace impossible are rejected", func(t *testing.T) {
+		end := start.Add(30 * time.Minute)
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType: "walking",
+			StartTime:    start,
+			EndTime:      &end,
+		})
+		require.NoError(t, err)
+
+		_, err = activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{"distance": float64(3)})
+		require.NoError(t, err)
+		_, err = activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{"distance": float64(15)})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
@@ -3,15 +3,21 @@ package integration
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"fitness-tracker/internal/adapters/external"
 	"fitness-tracker/internal/adapters/repositories/postgres"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/services"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,21 +44,13 @@ func MockOpenRouterServer(t *testing.T, responseContent string, toolCalls []exte
 			Created: time.Now().Unix(),
 			Model:   req.Model,
 			Choices: []struct {
-				Index   int `json:"index"`
-				Message struct {
-					Role      string               `json:"role"`
-					Content   string               `json:"content"`
-					ToolCalls []external.ToolCall  `json:"tool_calls,omitempty"`
-				} `json:"message"`
-				FinishReason string `json:"finish_reason"`
+				Index        int              `json:"index"`
+				Message      external.Message `json:"message"`
+				FinishReason string           `json:"finish_reason"`
 			}{
 				{
 					Index: 0,
-					Message: struct {
-						Role      string               `json:"role"`
-						Content   string               `json:"content"`
-						ToolCalls []external.ToolCall  `json:"tool_calls,omitempty"`
-					}{
+					Message: external.Message{
 						Role:      "assistant",
 						Content:   responseContent,
 						ToolCalls: toolCalls,
@@ -60,11 +58,7 @@ func MockOpenRouterServer(t *testing.T, responseContent string, toolCalls []exte
 					FinishReason: "stop",
 				},
 			},
-			Usage: struct {
-				PromptTokens     int `json:"prompt_tokens"`
-				CompletionTokens int `json:"completion_tokens"`
-				TotalTokens      int `json:"total_tokens"`
-			}{
+			Usage: external.Usage{
 				PromptTokens:     100,
 				CompletionTokens: 50,
 				TotalTokens:      150,
@@ -98,7 +92,7 @@ func TestAgentToolExecution(t *testing.T) {
 					Name      string `json:"name"`
 					Arguments string `json:"arguments"`
 				}{
-					Name:      "search_food",
+					Name:      "search_foods",
 					Arguments: `{"query": "chicken"}`,
 				},
 			},
@@ -181,6 +175,1262 @@ func TestAgentToolExecution(t *testing.T) {
 	})
 }
 
+func TestAgentUnknownToolCall(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_unknown_tool@example.com")
+
+	// First turn calls a tool that does not exist, second turn answers
+	var requests []external.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		message := map[string]interface{}{"role": "assistant", "content": "Here is what I found."}
+		if len(requests) == 1 {
+			message = map[string]interface{}{
+				"role":    "assistant",
+				"content": "",
+				"tool_calls": []map[string]interface{}{
+					{
+						"id":       "call_unknown",
+						"type":     "function",
+						"function": map[string]string{"name": "search_food", "arguments": `{"query": "chicken"}`},
+					},
+				},
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "test-response-id",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	agent := services.NewAgentService(
+		nil, nil,
+		services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		nil, nil,
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		nil, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	)
+
+	resp, err := agent.SendMessage(context.Background(), user.ID, "How much protein is in chicken?")
+	require.NoError(t, err)
+	assert.Equal(t, "Here is what I found.", resp.Message)
+	assert.Empty(t, resp.ToolsUsed)
+
+	// The unknown tool result was fed back, tied to the original call
+	require.Len(t, requests, 2)
+	msgs := requests[1].Messages
+	toolMsg := msgs[len(msgs)-1]
+	assert.Equal(t, "tool", toolMsg.Role)
+	assert.Equal(t, "call_unknown", toolMsg.ToolCallID)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(toolMsg.Content), &result))
+	assert.Equal(t, "unknown_tool", result["error"])
+	assert.Equal(t, "search_food", result["tool"])
+	assert.Contains(t, result["available_tools"], "search_foods")
+
+	assistantMsg := msgs[len(msgs)-2]
+	assert.Equal(t, "assistant", assistantMsg.Role)
+	require.Len(t, assistantMsg.ToolCalls, 1)
+	assert.Equal(t, "call_unknown", assistantMsg.ToolCalls[0].ID)
+}
+
+// stubTool is a minimal tool used to exercise the registry
+type stubTool struct {
+	name   string
+	result stubResult
+}
+
+// stubResult is a tool result that renders as itself
+type stubResult string
+
+func (r stubResult) Render() string {
+	return string(r)
+}
+
+func (t *stubTool) Name() string {
+	return t.name
+}
+
+func (t *stubTool) Definition() external.Tool {
+	return external.Tool{
+		Type:     "function",
+		Function: external.ToolFunction{Name: t.name, Description: "stub"},
+	}
+}
+
+func (t *stubTool) Execute(ctx context.Context, args map[string]interface{}, userID uuid.UUID) (services.ToolResult, error) {
+	return t.result, nil
+}
+
+func TestAgentDuplicateToolCall(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_duplicate_tool@example.com")
+
+	// The same log_weight call arrives twice in one message and is replayed in the next
+	logWeight := map[string]interface{}{
+		"id":       "call_weight",
+		"type":     "function",
+		"function": map[string]string{"name": "log_weight", "arguments": `{"weight": 82.5}`},
+	}
+	var requests []external.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		message := map[string]interface{}{"role": "assistant", "content": "Logged your weight."}
+		switch len(requests) {
+		case 1:
+			message = map[string]interface{}{"role": "assistant", "content": "", "tool_calls": []interface{}{logWeight, logWeight}}
+		case 2:
+			message = map[string]interface{}{"role": "assistant", "content": "", "tool_calls": []interface{}{logWeight}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "test-response-id",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	agent := services.NewAgentService(
+		nil, nil,
+		services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		nil,
+		services.NewMetricService(postgres.NewMetricRepository(testDB.DB), userRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		nil, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	)
+
+	resp, err := agent.SendMessage(context.Background(), user.ID, "I weigh 82.5 kg")
+	require.NoError(t, err)
+	assert.Equal(t, "Logged your weight.", resp.Message)
+	assert.Equal(t, []string{"log_weight"}, resp.ToolsUsed)
+
+	// Only one weight entry was written
+	var count int64
+	require.NoError(t, testDB.DB.Model(&domain.Metric{}).
+		Where("user_id = ? AND metric_type = ?", user.ID, "weight").
+		Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// Every duplicate still got the original result, tied to its call
+	require.Len(t, requests, 3)
+	var toolResults []external.Message
+	for _, msg := range requests[2].Messages {
+		if msg.Role == "tool" {
+			toolResults = append(toolResults, msg)
+		}
+	}
+	require.Len(t, toolResults, 3)
+	for _, msg := range toolResults {
+		assert.Equal(t, "call_weight", msg.ToolCallID)
+		assert.Equal(t, toolResults[0].Content, msg.Content)
+	}
+	var logged map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(toolResults[0].Content), &logged))
+	assert.Equal(t, 82.5, logged["weight_kg"])
+}
+
+func TestAgentGoalTools(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_goal_tools@example.com")
+
+	// The mock model answers the first request with the given tool call and then replies
+	var requests []external.ChatRequest
+	var toolName, toolArgs string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		message := map[string]interface{}{"role": "assistant", "content": "Done."}
+		if len(requests) == 1 {
+			message = map[string]interface{}{"role": "assistant", "content": "", "tool_calls": []interface{}{
+				map[string]interface{}{
+					"id":       "call_goal",
+					"type":     "function",
+					"function": map[string]string{"name": toolName, "arguments": toolArgs},
+				},
+			}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "test-response-id",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	agent := services.NewAgentService(
+		nil, nil,
+		services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		nil, nil,
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		nil, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	)
+
+	// send runs one turn with the mock calling the tool, returning the tool's result
+	send := func(name, args string) map[string]interface{} {
+		requests, toolName, toolArgs = nil, name, args
+		resp, err := agent.SendMessage(context.Background(), user.ID, "Update my goals")
+		require.NoError(t, err)
+		assert.Equal(t, []string{name}, resp.ToolsUsed)
+
+		require.Len(t, requests, 2)
+		for _, msg := range requests[1].Messages {
+			if msg.Role == "tool" {
+				var result map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(msg.Content), &result))
+				return result
+			}
+		}
+		t.Fatal("no tool result was sent back to the model")
+		return nil
+	}
+
+	deadline := time.Now().AddDate(0, 3, 0).Format("2006-01-02")
+
+	t.Run("Creates a goal", func(t *testing.T) {
+		result := send("create_goal", fmt.Sprintf(`{"goal_type": "weight_loss", "target_value": 75, "target_date": %q, "description": "Lose 5kg"}`, deadline))
+		assert.Equal(t, "weight_loss", result["goal_type"])
+		assert.Equal(t, 75.0, result["target_value"])
+		assert.Equal(t, "kg", result["unit"])
+		assert.Equal(t, deadline, result["target_date"])
+		assert.Equal(t, "active", result["status"])
+
+		var goal domain.Goal
+		require.NoError(t, testDB.DB.Where("user_id = ?", user.ID).First(&goal).Error)
+		assert.Equal(t, 75.0, goal.TargetValue)
+		assert.Equal(t, "Lose 5kg", goal.Description)
+		require.NotNil(t, goal.TargetDate)
+	})
+
+	t.Run("Rejects out of bounds goals", func(t *testing.T) {
+		for _, args := range []string{
+			`{"goal_type": "get_famous", "target_value": 1}`,
+			`{"goal_type": "weight_loss", "target_value": -5}`,
+			`{"goal_type": "weight_loss", "target_value": 70, "target_date": "2020-01-01"}`,
+			`{"goal_type": "weight_loss", "target_value": 70, "target_date": "June"}`,
+		} {
+			result := send("create_goal", args)
+			assert.Equal(t, "tool_failed", result["error"], args)
+		}
+
+		var count int64
+		require.NoError(t, testDB.DB.Model(&domain.Goal{}).Where("user_id = ?", user.ID).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Updates progress on the active goal of a type", func(t *testing.T) {
+		result := send("update_goal_progress", `{"goal_type": "weight_loss", "current_value": 78.5}`)
+		assert.Equal(t, 78.5, result["current_value"])
+
+		var goal domain.Goal
+		require.NoError(t, testDB.DB.Where("user_id = ?", user.ID).First(&goal).Error)
+		require.NotNil(t, goal.CurrentValue)
+		assert.Equal(t, 78.5, *goal.CurrentValue)
+	})
+
+	t.Run("Never updates another user's goal", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "agent_goal_tools_other@example.com")
+		otherGoal := &domain.Goal{UserID: other.ID, GoalType: "steps", TargetValue: 10000, Unit: "steps", StartDate: time.Now(), Status: "active"}
+		require.NoError(t, goalRepo.Create(context.Background(), otherGoal))
+
+		result := send("update_goal_progress", fmt.Sprintf(`{"goal_id": %q, "status": "abandoned"}`, otherGoal.ID))
+		assert.Contains(t, result["note"], "no goal with that ID")
+
+		stored, err := goalRepo.GetByID(context.Background(), otherGoal.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "active", stored.Status)
+	})
+}
+
+func TestAgentMealLogConfirmation(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_meal_confirmation@example.com")
+	food := CreateTestFood(t, testDB.DB, "Chicken Breast", 165)
+
+	// Each turn answers with the scripted tool calls first, then a plain reply
+	var requests []external.ChatRequest
+	var script [][]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		message := map[string]interface{}{"role": "assistant", "content": "Done."}
+		if len(script) > 0 {
+			message = map[string]interface{}{"role": "assistant", "content": "", "tool_calls": script[0]}
+			script = script[1:]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "test-response-id",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	toolCall := func(id, name, arguments string) []interface{} {
+		return []interface{}{map[string]interface{}{
+			"id":       id,
+			"type":     "function",
+			"function": map[string]string{"name": name, "arguments": arguments},
+		}}
+	}
+	logMeal := func(quantity string, confidence float64) string {
+		return fmt.Sprintf(`{"meal_type": "lunch", "confidence": %g, "food_items": [{"food_id": "%s"%s}]}`, confidence, food.ID, quantity)
+	}
+	lastToolResult := func() map[string]interface{} {
+		var result map[string]interface{}
+		for _, msg := range requests[len(requests)-1].Messages {
+			if msg.Role == "tool" {
+				result = nil
+				require.NoError(t, json.Unmarshal([]byte(msg.Content), &result))
+			}
+		}
+		return result
+	}
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	actionRepo := postgres.NewUserActionRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	mealService := services.NewMealService(mealRepo, foodRepo, actionRepo, summaryService, postgres.NewTransactor(testDB.DB), nil)
+	foodService := services.NewFoodService(foodRepo, mealRepo, postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+	pendingMeals := services.NewMealParserService("", foodRepo, nil)
+
+	agent := services.NewAgentService(
+		mealService, foodService,
+		services.NewActivityService(activityRepo, actionRepo, summaryService),
+		nil, nil,
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		nil, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	).WithMealLogConfirmation(pendingMeals, 0.8)
+
+	countMeals := func() int64 {
+		var count int64
+		require.NoError(t, testDB.DB.Model(&domain.Meal{}).Where("user_id = ?", user.ID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("Confident logs are saved directly", func(t *testing.T) {
+		script = [][]interface{}{toolCall("call_direct", "log_meal", logMeal(`, "quantity": 200, "unit": "g"`, 0.95))}
+
+		resp, err := agent.SendMessage(context.Background(), user.ID, "I had 200g of chicken breast for lunch")
+		require.NoError(t, err)
+		assert.Nil(t, resp.PendingMeal)
+		assert.Equal(t, int64(1), countMeals())
+
+		result := lastToolResult()
+		assert.Equal(t, "logged", result["status"])
+		assert.InDelta(t, 330.0, result["totals"].(map[string]interface{})["calories"], 0.01)
+	})
+
+	var pendingID uuid.UUID
+	t.Run("Unsure logs wait for confirmation", func(t *testing.T) {
+		script = [][]interface{}{toolCall("call_unsure", "log_meal", logMeal(`, "quantity": 150, "unit": "g"`, 0.5))}
+
+		resp, err := agent.SendMessage(context.Background(), user.ID, "Had some chicken for lunch")
+		require.NoError(t, err)
+		require.NotNil(t, resp.PendingMeal)
+		assert.True(t, resp.PendingMeal.NeedsConfirmation)
+		assert.InDelta(t, 247.5, resp.PendingMeal.Totals.Calories, 0.01)
+		assert.Equal(t, int64(1), countMeals(), "nothing is logged until the user confirms")
+		pendingID = resp.PendingMeal.ID
+
+		result := lastToolResult()
+		assert.Equal(t, "pending_confirmation", result["status"])
+		assert.Equal(t, pendingID.String(), result["parsed_meal_id"])
+
+		// The pending meal is recorded on the reply
+		conversationRepo := postgres.NewConversationRepository(testDB.DB)
+		conversations, err := conversationRepo.ListByUser(context.Background(), user.ID, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, conversations, 1)
+		messages, err := conversationRepo.GetLatestMessages(context.Background(), conversations[0].ID, 1)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		metadata, err := messages[0].GetMetadata()
+		require.NoError(t, err)
+		require.NotNil(t, metadata.PendingMealID)
+		assert.Equal(t, pendingID, *metadata.PendingMealID)
+	})
+
+	t.Run("The next turn reminds the model and confirming logs the meal", func(t *testing.T) {
+		require.NotEqual(t, uuid.Nil, pendingID)
+		script = [][]interface{}{toolCall("call_confirm", "confirm_meal", fmt.Sprintf(`{"parsed_meal_id": "%s"}`, pendingID))}
+		turnStart := len(requests)
+
+		resp, err := agent.SendMessage(context.Background(), user.ID, "Yes, that's right")
+		require.NoError(t, err)
+		assert.Nil(t, resp.PendingMeal)
+		assert.Equal(t, int64(2), countMeals())
+		assert.Equal(t, "logged", lastToolResult()["status"])
+
+		msgs := requests[turnStart].Messages
+		reminder := msgs[len(msgs)-2]
+		assert.Equal(t, "system", reminder.Role)
+		assert.Contains(t, reminder.Content, pendingID.String())
+		assert.Equal(t, "Yes, that's right", msgs[len(msgs)-1].Content)
+
+		// A confirmed meal cannot be confirmed twice
+		_, err = pendingMeals.GetParsedMeal(user.ID, pendingID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Without a reminder once nothing is pending", func(t *testing.T) {
+		turnStart := len(requests)
+		_, err := agent.SendMessage(context.Background(), user.ID, "Thanks")
+		require.NoError(t, err)
+
+		for _, msg := range requests[turnStart].Messages[1:] {
+			assert.NotEqual(t, "system", msg.Role)
+		}
+	})
+
+	t.Run("Missing quantities need confirmation however confident", func(t *testing.T) {
+		script = [][]interface{}{toolCall("call_no_quantity", "log_meal", logMeal("", 1))}
+
+		resp, err := agent.SendMessage(context.Background(), user.ID, "Chicken for lunch again")
+		require.NoError(t, err)
+		require.NotNil(t, resp.PendingMeal)
+		assert.Contains(t, lastToolResult()["reason"], "Chicken Breast")
+		assert.Equal(t, int64(2), countMeals())
+	})
+}
+
+func TestAgentPromptMessageOrder(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_prompt_order@example.com")
+
+	var requests []external.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "test-response-id",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]interface{}{"role": "assistant", "content": fmt.Sprintf("Reply %d", len(requests))},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	// Seed 25 alternating turns, inserted newest first so insertion order does not help
+	title := "History"
+	conversation := &domain.Conversation{UserID: user.ID, Title: &title}
+	require.NoError(t, testDB.DB.Create(conversation).Error)
+
+	start := time.Now().Add(-time.Hour)
+	for i := 24; i >= 0; i-- {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		require.NoError(t, testDB.DB.Create(&domain.Message{
+			ConversationID: conversation.ID,
+			Role:           role,
+			Content:        fmt.Sprintf("History %d", i),
+			CreatedAt:      start.Add(time.Duration(i) * time.Minute),
+		}).Error)
+	}
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	agent := services.NewAgentService(
+		nil, nil,
+		services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		nil, nil,
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		nil, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	)
+
+	t.Run("System prompt, then history oldest to newest, then the new message", func(t *testing.T) {
+		_, err := agent.SendMessage(context.Background(), user.ID, "First question")
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		msgs := requests[0].Messages
+		require.Len(t, msgs, 22) // system + last 20 + current
+
+		assert.Equal(t, "system", msgs[0].Role)
+		for i, msg := range msgs[1:21] {
+			assert.Equal(t, fmt.Sprintf("History %d", i+5), msg.Content)
+		}
+		assert.Equal(t, "user", msgs[21].Role)
+		assert.Equal(t, "First question", msgs[21].Content)
+	})
+
+	t.Run("The previous turn is replayed as question then answer", func(t *testing.T) {
+		_, err := agent.SendMessage(context.Background(), user.ID, "Second question")
+		require.NoError(t, err)
+
+		require.Len(t, requests, 2)
+		msgs := requests[1].Messages
+		require.Len(t, msgs, 22)
+
+		assert.Equal(t, "user", msgs[19].Role)
+		assert.Equal(t, "First question", msgs[19].Content)
+		assert.Equal(t, "assistant", msgs[20].Role)
+		assert.Equal(t, "Reply 1", msgs[20].Content)
+		assert.Equal(t, "Second question", msgs[21].Content)
+	})
+}
+
+func TestAgentRemainingMacros(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "agent_remaining_macros@example.com")
+	user.Timezone = "Pacific/Kiritimati"
+	require.NoError(t, testDB.DB.Save(user).Error)
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	targetRepo := postgres.NewNutritionTargetRepository(testDB.DB)
+	profileService := services.NewProfileService(userRepo, goalRepo, targetRepo, postgres.NewMealDistributionRepository(testDB.DB), nil)
+	_, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{
+		Calories: 2000, Protein: 150, Carbohydrates: 200, Fat: 60,
+	})
+	require.NoError(t, err)
+
+	// 500 kcal, 30g protein, 60g carbs, 15g fat today, and a heavy meal that only
+	// counts if today is computed wrongly
+	CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	old := CreateTestMeal(t, testDB.DB, user.ID, "dinner")
+	old.ConsumedAt = time.Now().Add(-48 * time.Hour)
+	old.TotalFat = 90
+	require.NoError(t, testDB.DB.Save(old).Error)
+
+	var requests []external.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		message := map[string]interface{}{"role": "assistant", "content": "You have 120g of protein left."}
+		if len(requests) == 1 {
+			message = map[string]interface{}{
+				"role":    "assistant",
+				"content": "",
+				"tool_calls": []map[string]interface{}{{
+					"id":       "call_remaining",
+					"type":     "function",
+					"function": map[string]string{"name": "get_remaining_macros", "arguments": `{}`},
+				}, {
+					"id":       "call_suggest",
+					"type":     "function",
+					"function": map[string]string{"name": "suggest_meal", "arguments": `{}`},
+				}},
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "test-response-id",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		targetRepo,
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	suggestions := &recordingMealSuggestions{}
+	agent := services.NewAgentService(
+		nil, nil,
+		services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		nil, nil,
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		suggestions, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	)
+
+	response, err := agent.SendMessage(ctx, user.ID, "How much protein do I have left today?")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get_remaining_macros", "suggest_meal"}, response.ToolsUsed)
+	require.Len(t, requests, 2)
+
+	var toolMsg external.Message
+	for _, msg := range requests[1].Messages {
+		if msg.ToolCallID == "call_remaining" {
+			toolMsg = msg
+		}
+	}
+	assert.Equal(t, "tool", toolMsg.Role)
+
+	var remaining struct {
+		Date      string              `json:"date"`
+		Timezone  string              `json:"timezone"`
+		Consumed  domain.MacroTargets `json:"consumed"`
+		Targets   domain.MacroTargets `json:"targets"`
+		Remaining domain.MacroTargets `json:"remaining"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(toolMsg.Content), &remaining))
+
+	loc, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	assert.Equal(t, "Pacific/Kiritimati", remaining.Timezone)
+	assert.Equal(t, time.Now().In(loc).Format("2006-01-02"), remaining.Date)
+
+	assert.Equal(t, domain.MacroTargets{Calories: 500, Protein: 30, Carbohydrates: 60, Fat: 15}, remaining.Consumed)
+	assert.Equal(t, domain.MacroTargets{Calories: 2000, Protein: 150, Carbohydrates: 200, Fat: 60}, remaining.Targets)
+	assert.Equal(t, domain.MacroTargets{Calories: 1500, Protein: 120, Carbohydrates: 140, Fat: 45}, remaining.Remaining)
+
+	// suggest_meal aims at the same remaining macros get_remaining_macros reported
+	assert.Equal(t, remaining.Remaining, suggestions.target)
+}
+
+// recordingMealSuggestions records the target it was asked to suggest a meal for
+type recordingMealSuggestions struct {
+	target domain.MacroTargets
+}
+
+func (r *recordingMealSuggestions) SuggestMeal(ctx context.Context, userID string, target domain.MacroTargets) (*domain.MealSuggestion, error) {
+	r.target = target
+	return &domain.MealSuggestion{Target: target, Items: []domain.SuggestedFood{}}, nil
+}
+
+// Failing stand-ins for the services the agent reads its user context from
+type failingGoalService struct{ ports.GoalService }
+
+func (failingGoalService) GetGoals(ctx context.Context, userID string, status *string) ([]*domain.Goal, error) {
+	return nil, errors.New("goals unavailable")
+}
+
+type failingSummaryService struct{ ports.SummaryService }
+
+func (failingSummaryService) GetDailySummary(ctx context.Context, userID string, date time.Time) (*domain.DailySummary, error) {
+	return nil, errors.New("summary unavailable")
+}
+
+func (failingSummaryService) GetNutritionTargets(ctx context.Context, userID string) (*domain.NutritionTarget, error) {
+	return nil, errors.New("targets unavailable")
+}
+
+type failingActivityService struct{ ports.ActivityService }
+
+func (failingActivityService) GetActivities(ctx context.Context, userID string, startDate, endDate *time.Time) ([]*domain.Activity, error) {
+	return nil, errors.New("activities unavailable")
+}
+
+func TestAgentUserContextPartialFailure(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_context_failure@example.com")
+
+	var systemPrompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		systemPrompt = req.Messages[0].Content
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "test-response-id",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]interface{}{"role": "assistant", "content": "Hello!"},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	agent := services.NewAgentService(
+		nil, nil,
+		failingActivityService{},
+		nil, nil,
+		failingGoalService{},
+		failingSummaryService{},
+		nil, nil,
+		postgres.NewConversationRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	)
+
+	_, err := agent.SendMessage(context.Background(), user.ID, "How am I doing?")
+	require.NoError(t, err)
+
+	// The user's details still make it into the prompt; the failed lookups are left out
+	assert.Contains(t, systemPrompt, fmt.Sprintf("User: %s %s", user.FirstName, user.LastName))
+	assert.NotContains(t, systemPrompt, "User context unavailable")
+	assert.NotContains(t, systemPrompt, "Today's Nutrition")
+	assert.NotContains(t, systemPrompt, "Recent Activity")
+}
+
+func TestAgentTemperature(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	user := CreateTestUser(t, testDB.DB, "agent_temperature@example.com")
+
+	// The first completion calls a tool, so both the tool round and the reply are checked
+	var requests []external.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		message := map[string]interface{}{"role": "assistant", "content": "Keep it up!"}
+		if len(requests)%2 == 1 {
+			message = map[string]interface{}{"role": "assistant", "content": "", "tool_calls": []interface{}{map[string]interface{}{
+				"id":       fmt.Sprintf("call_%d", len(requests)),
+				"type":     "function",
+				"function": map[string]string{"name": "no_such_tool", "arguments": `{}`},
+			}}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "test-response-id",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+	}))
+	defer server.Close()
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	newAgent := func() *services.AgentService {
+		return services.NewAgentService(
+			nil, nil,
+			services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+			nil, nil,
+			services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+			summaryService,
+			nil, nil,
+			postgres.NewConversationRepository(testDB.DB),
+			userRepo,
+			external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+		)
+	}
+
+	temperatures := func(from int) []float64 {
+		var sent []float64
+		for _, req := range requests[from:] {
+			require.NotNil(t, req.Temperature, "temperature must be sent")
+			sent = append(sent, *req.Temperature)
+		}
+		return sent
+	}
+
+	t.Run("Chat defaults to 0.7", func(t *testing.T) {
+		_, err := newAgent().SendMessage(context.Background(), user.ID, "Motivate me")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.7, 0.7}, temperatures(0))
+	})
+
+	t.Run("The configured temperature covers tool rounds and the reply", func(t *testing.T) {
+		from := len(requests)
+		_, err := newAgent().WithTemperature(0.3).SendMessage(context.Background(), user.ID, "Motivate me again")
+		require.NoError(t, err)
+		assert.Equal(t, []float64{0.3, 0.3}, temperatures(from))
+	})
+}
+
+func TestAgentHistorySummarization(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "agent_summary@example.com")
+
+	// Summary requests are the ones sent without tools
+	var chats, summaries []external.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req external.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		content := "- Vegetarian\n- Goal: lose 5 kg by June"
+		if len(req.Tools) > 0 {
+			chats = append(chats, req)
+			content = fmt.Sprintf("Reply %d", len(chats))
+		} else {
+			summaries = append(summaries, req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "test-response-id",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]interface{}{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	title := "Long chat"
+	conversation := &domain.Conversation{UserID: user.ID, Title: &title}
+	require.NoError(t, testDB.DB.Create(conversation).Error)
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 12; i++ {
+		role, content := "user", fmt.Sprintf("History %d", i)
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		if i == 0 {
+			content = "I'm vegetarian and want to lose 5 kg by June"
+		}
+		require.NoError(t, testDB.DB.Create(&domain.Message{
+			ConversationID: conversation.ID,
+			Role:           role,
+			Content:        content,
+			CreatedAt:      start.Add(time.Duration(i) * time.Minute),
+		}).Error)
+	}
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	conversationRepo := postgres.NewConversationRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	agent := services.NewAgentService(
+		nil, nil,
+		services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+		nil, nil,
+		services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB)),
+		summaryService,
+		nil, nil,
+		conversationRepo,
+		userRepo,
+		external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+	).WithHistorySummarization(8, 0)
+
+	t.Run("Older messages are replaced by a summary", func(t *testing.T) {
+		_, err := agent.SendMessage(ctx, user.ID, "What should I have for dinner?")
+		require.NoError(t, err)
+
+		// The six oldest messages were summarized, starting with the user's goal
+		require.Len(t, summaries, 1)
+		transcript := summaries[0].Messages[1].Content
+		assert.Contains(t, transcript, "I'm vegetarian and want to lose 5 kg by June")
+		assert.Contains(t, transcript, "History 5")
+		assert.NotContains(t, transcript, "History 6")
+
+		// system prompt, summary, the six newest messages, then the question
+		require.Len(t, chats, 1)
+		msgs := chats[0].Messages
+		require.Len(t, msgs, 9)
+		assert.Equal(t, "system", msgs[1].Role)
+		assert.Contains(t, msgs[1].Content, "Goal: lose 5 kg by June")
+		assert.Equal(t, "History 6", msgs[2].Content)
+		assert.Equal(t, "History 11", msgs[7].Content)
+		assert.Equal(t, "What should I have for dinner?", msgs[8].Content)
+
+		stored, err := conversationRepo.GetByID(ctx, conversation.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.Summary)
+		assert.Contains(t, *stored.Summary, "Vegetarian")
+		require.NotNil(t, stored.SummarizedThrough)
+		assert.WithinDuration(t, start.Add(5*time.Minute), *stored.SummarizedThrough, time.Millisecond)
+	})
+
+	t.Run("Full history is kept for display", func(t *testing.T) {
+		messages, err := conversationRepo.GetMessages(ctx, conversation.ID, 50, 0)
+		require.NoError(t, err)
+		require.Len(t, messages, 14)
+		assert.Equal(t, "I'm vegetarian and want to lose 5 kg by June", messages[0].Content)
+	})
+
+	t.Run("The stored summary carries over to later turns", func(t *testing.T) {
+		_, err := agent.SendMessage(ctx, user.ID, "And for breakfast?")
+		require.NoError(t, err)
+
+		// Eight unsummarized messages are within the threshold, so no new summary
+		assert.Len(t, summaries, 1)
+		require.Len(t, chats, 2)
+		msgs := chats[1].Messages
+		require.Len(t, msgs, 11)
+		assert.Contains(t, msgs[1].Content, "Goal: lose 5 kg by June")
+		assert.Equal(t, "History 6", msgs[2].Content)
+		assert.Equal(t, "What should I have for dinner?", msgs[8].Content)
+		assert.Equal(t, "Reply 1", msgs[9].Content)
+		assert.Equal(t, "And for breakfast?", msgs[10].Content)
+	})
+
+	t.Run("Token estimate", func(t *testing.T) {
+		assert.Equal(t, 0, domain.EstimateTokens(""))
+		assert.Equal(t, 1, domain.EstimateTokens("hi"))
+		assert.Equal(t, 25, domain.EstimateTokens(strings.Repeat("a", 100)))
+	})
+}
+
+// stubModerator flags any text containing one of its words
+type stubModerator struct {
+	words []string
+	err   error
+}
+
+func (m *stubModerator) Moderate(ctx context.Context, text string) (*domain.ModerationResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	result := &domain.ModerationResult{}
+	for _, word := range m.words {
+		if strings.Contains(strings.ToLower(text), word) {
+			result.Flagged = true
+			result.Categories = append(result.Categories, domain.ModerationCategoryProfanity)
+		}
+	}
+	return result, nil
+}
+
+func TestAgentReplyModeration(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	conversationRepo := postgres.NewConversationRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		workoutRepo,
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+
+	// newAgent answers every message with reply, screened by moderator
+	newAgent := func(t *testing.T, reply string, moderator *stubModerator) *services.AgentService {
+		server := MockOpenRouterServer(t, reply, nil)
+		t.Cleanup(server.Close)
+
+		agent := services.NewAgentService(
+			nil, nil,
+			services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), summaryService),
+			nil, nil,
+			services.NewGoalService(goalRepo, metricRepo, workoutRepo),
+			summaryService,
+			nil, nil,
+			conversationRepo,
+			userRepo,
+			external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+		)
+		if moderator != nil {
+			agent.WithModerator(moderator)
+		}
+		return agent
+	}
+
+	// lastReply returns the assistant message stored for the user's latest turn
+	lastReply := func(t *testing.T, userID uuid.UUID) *domain.Message {
+		conversations, err := conversationRepo.ListByUser(ctx, userID, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, conversations, 1)
+
+		messages, err := conversationRepo.GetLatestMessages(ctx, conversations[0].ID, 1)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "assistant", messages[0].Role)
+		return messages[0]
+	}
+
+	t.Run("Benign replies pass unchanged", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "moderation_benign@example.com")
+		reply := "Great job hitting your protein target, keep it up!"
+		agent := newAgent(t, reply, &stubModerator{words: []string{"darn"}})
+
+		response, err := agent.SendMessage(ctx, user.ID, "How did I do?")
+		require.NoError(t, err)
+		assert.Equal(t, reply, response.Message)
+
+		stored := lastReply(t, user.ID)
+		assert.Equal(t, reply, stored.Content)
+		require.NotNil(t, stored.Metadata)
+		assert.NotContains(t, *stored.Metadata, "moderated")
+
+		metadata, err := stored.GetMetadata()
+		require.NoError(t, err)
+		assert.False(t, metadata.Moderated)
+		assert.Equal(t, 100, metadata.PromptTokens)
+		assert.Equal(t, 150, metadata.TotalTokens)
+	})
+
+	t.Run("Flagged replies are redacted before they are stored", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "moderation_flagged@example.com")
+		agent := newAgent(t, "Darn, you skipped leg day again.", &stubModerator{words: []string{"darn"}})
+
+		response, err := agent.SendMessage(ctx, user.ID, "How did I do?")
+		require.NoError(t, err)
+		assert.Equal(t, domain.ModeratedReplyMessage, response.Message)
+
+		stored := lastReply(t, user.ID)
+		assert.Equal(t, domain.ModeratedReplyMessage, stored.Content)
+		require.NotNil(t, stored.Metadata)
+		assert.Contains(t, *stored.Metadata, `"moderated":true`)
+	})
+
+	t.Run("A failing moderator redacts rather than passing unscreened output", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "moderation_failing@example.com")
+		agent := newAgent(t, "Nice work today.", &stubModerator{err: fmt.Errorf("moderation unavailable")})
+
+		response, err := agent.SendMessage(ctx, user.ID, "How did I do?")
+		require.NoError(t, err)
+		assert.Equal(t, domain.ModeratedReplyMessage, response.Message)
+	})
+
+	t.Run("Without a moderator replies are not screened", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "moderation_off@example.com")
+		agent := newAgent(t, "Darn, you skipped leg day again.", nil)
+
+		response, err := agent.SendMessage(ctx, user.ID, "How did I do?")
+		require.NoError(t, err)
+		assert.Equal(t, "Darn, you skipped leg day again.", response.Message)
+	})
+}
+
+func TestKeywordModerator(t *testing.T) {
+	ctx := context.Background()
+	benign := []string{
+		"Crush your squats today and assess how your knees feel afterwards.",
+		"Hello! You are 20 g short on protein, try Greek yogurt as a snack.",
+		"That was a hell of a workout, rest up tomorrow.",
+	}
+
+	t.Run("Off disables screening", func(t *testing.T) {
+		assert.Nil(t, external.NewKeywordModerator(domain.ModerationOff))
+	})
+
+	t.Run("Standard passes benign coaching and mild language", func(t *testing.T) {
+		moderator := external.NewKeywordModerator(domain.ModerationStandard)
+		for _, text := range append(benign, "Damn, that is a big PR!") {
+			result, err := moderator.Moderate(ctx, text)
+			require.NoError(t, err)
+			assert.False(t, result.Flagged, text)
+		}
+	})
+
+	t.Run("Standard flags unsafe output by category", func(t *testing.T) {
+		moderator := external.NewKeywordModerator(domain.ModerationStandard)
+		cases := map[string]string{
+			"If you ate that much, make yourself throw up.": domain.ModerationCategorySelfHarm,
+			"What the fuck was that set?":                   domain.ModerationCategoryProfanity,
+			"Only a retard skips warmups.":                  domain.ModerationCategoryHate,
+		}
+		for text, category := range cases {
+			result, err := moderator.Moderate(ctx, text)
+			require.NoError(t, err)
+			assert.True(t, result.Flagged, text)
+			assert.Equal(t, []string{category}, result.Categories)
+		}
+	})
+
+	t.Run("Strict also flags mild profanity", func(t *testing.T) {
+		moderator := external.NewKeywordModerator(domain.ModerationStrict)
+		result, err := moderator.Moderate(ctx, "Damn, that is a big PR!")
+		require.NoError(t, err)
+		assert.True(t, result.Flagged)
+		assert.Equal(t, []string{domain.ModerationCategoryProfanity}, result.Categories)
+
+		for _, text := range benign {
+			result, err := moderator.Moderate(ctx, text)
+			require.NoError(t, err)
+			assert.False(t, result.Flagged, text)
+		}
+	})
+}
+
+func TestToolRegistry(t *testing.T) {
+	registry := services.NewToolRegistry(
+		&stubTool{name: "first", result: "one"},
+		&stubTool{name: "second", result: "two"},
+	)
+
+	t.Run("Definitions follow registration order", func(t *testing.T) {
+		defs := registry.Definitions()
+		require.Len(t, defs, 2)
+		assert.Equal(t, "first", defs[0].Function.Name)
+		assert.Equal(t, "second", defs[1].Function.Name)
+		assert.Equal(t, []string{"first", "second"}, registry.Names())
+	})
+
+	t.Run("Dispatches by name", func(t *testing.T) {
+		tool, ok := registry.Get("second")
+		require.True(t, ok)
+		result, err := tool.Execute(context.Background(), nil, uuid.New())
+		require.NoError(t, err)
+		assert.Equal(t, "two", result.Render())
+
+		_, ok = registry.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("Re-registering replaces without duplicating", func(t *testing.T) {
+		registry.Register(&stubTool{name: "first", result: "uno"})
+		assert.Equal(t, []string{"first", "second"}, registry.Names())
+
+		tool, _ := registry.Get("first")
+		result, _ := tool.Execute(context.Background(), nil, uuid.New())
+		assert.Equal(t, "uno", result.Render())
+	})
+}
+
 func TestConversationFlow(t *testing.T) {
 	// Setup
 	testDB := SetupTestDB(t)
@@ -382,6 +1632,42 @@ func TestMessageOrdering(t *testing.T) {
 		assert.Equal(t, "Second message", retrievedMessages[1].Content)
 		assert.Equal(t, "Third message", retrievedMessages[2].Content)
 	})
+
+	t.Run("Older pages load before a cursor", func(t *testing.T) {
+		title := "Test Paging"
+		conversation := &domain.Conversation{UserID: user.ID, Title: &title}
+		require.NoError(t, testDB.DB.Create(conversation).Error)
+
+		start := time.Now().Add(-time.Hour)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, testDB.DB.Create(&domain.Message{
+				ConversationID: conversation.ID,
+				Role:           "user",
+				Content:        fmt.Sprintf("Message %d", i),
+				CreatedAt:      start.Add(time.Duration(i) * time.Minute),
+			}).Error)
+		}
+
+		repo := postgres.NewConversationRepository(testDB.DB)
+		ctx := context.Background()
+
+		latest, err := repo.GetLatestMessages(ctx, conversation.ID, 2)
+		require.NoError(t, err)
+		require.Len(t, latest, 2)
+		assert.Equal(t, "Message 3", latest[0].Content)
+		assert.Equal(t, "Message 4", latest[1].Content)
+
+		older, err := repo.GetMessagesBefore(ctx, conversation.ID, latest[0].CreatedAt, 2)
+		require.NoError(t, err)
+		require.Len(t, older, 2)
+		assert.Equal(t, "Message 1", older[0].Content)
+		assert.Equal(t, "Message 2", older[1].Content)
+
+		oldest, err := repo.GetMessagesBefore(ctx, conversation.ID, older[0].CreatedAt, 2)
+		require.NoError(t, err)
+		require.Len(t, oldest, 1)
+		assert.Equal(t, "Message 0", oldest[0].Content)
+	})
 }
 
 func TestConversationDeletion(t *testing.T) {
@@ -429,3 +1715,139 @@ func TestConversationDeletion(t *testing.T) {
 		assert.Equal(t, int64(0), messageCount, "Messages should be cascade deleted")
 	})
 }
+
+func TestPreviewText(t *testing.T) {
+	assert.Equal(t, "Hello there", domain.PreviewText("  Hello\n\n  there ", 20))
+	assert.Equal(t, "Hello…", domain.PreviewText("Hello there", 7), "the cut drops the trailing space")
+	assert.Equal(t, "héllo", domain.PreviewText("héllo", 5), "length counts characters, not bytes")
+}
+
+func TestListConversations(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "conversation_list@example.com")
+	other := CreateTestUser(t, testDB.DB, "conversation_list_other@example.com")
+
+	conversationRepo := postgres.NewConversationRepository(testDB.DB)
+	conversationService := services.NewConversationService(conversationRepo)
+
+	start := time.Date(2025, 11, 19, 8, 0, 0, 0, time.UTC)
+	createConversation := func(userID uuid.UUID, title string, createdAt time.Time) *domain.Conversation {
+		conversation := &domain.Conversation{UserID: userID, Title: &title, CreatedAt: createdAt, UpdatedAt: createdAt}
+		require.NoError(t, conversationRepo.Create(ctx, conversation))
+		return conversation
+	}
+	addMessage := func(conversation *domain.Conversation, role, content string, at time.Time) {
+		require.NoError(t, conversationRepo.AddMessage(ctx, &domain.Message{
+			ConversationID: conversation.ID,
+			Role:           role,
+			Content:        content,
+			CreatedAt:      at,
+		}))
+	}
+
+	breakfast := createConversation(user.ID, "Breakfast ideas", start)
+	addMessage(breakfast, "user", "What should I eat?", start.Add(time.Hour))
+	addMessage(breakfast, "assistant", "Oats with berries.", start.Add(time.Hour+time.Second))
+
+	empty := createConversation(user.ID, "New Conversation", start.Add(2*time.Hour))
+
+	// Created first, but the latest message makes it the most recently active
+	recovery := createConversation(user.ID, "Recovery", start.AddDate(0, 0, -1))
+	addMessage(recovery, "user", "How do I recover?", start.Add(-time.Hour))
+	addMessage(recovery, "user", "After legs?", start.Add(3*time.Hour))
+	longReply := strings.Repeat("Sleep and protein. ", 10)
+	addMessage(recovery, "assistant", longReply, start.Add(3*time.Hour+time.Second))
+
+	createConversation(other.ID, "Not mine", start.Add(4*time.Hour))
+
+	t.Run("Most recently active first with previews", func(t *testing.T) {
+		previews, err := conversationService.ListConversations(ctx, user.ID.String(), 10, 0)
+		require.NoError(t, err)
+		require.Len(t, previews, 3)
+
+		assert.Equal(t, recovery.ID, previews[0].ID)
+		assert.Equal(t, 3, previews[0].MessageCount)
+		require.NotNil(t, previews[0].LastMessage)
+		assert.Equal(t, domain.PreviewText(longReply, domain.ConversationPreviewLength), *previews[0].LastMessage)
+		assert.True(t, strings.HasSuffix(*previews[0].LastMessage, "…"))
+		assert.Equal(t, "assistant", *previews[0].LastMessageRole)
+		assert.True(t, previews[0].UpdatedAt.Equal(start.Add(3*time.Hour+time.Second)))
+
+		assert.Equal(t, empty.ID, previews[1].ID)
+		assert.Equal(t, 0, previews[1].MessageCount)
+		assert.Nil(t, previews[1].LastMessage)
+
+		assert.Equal(t, breakfast.ID, previews[2].ID)
+		assert.Equal(t, 2, previews[2].MessageCount)
+		assert.Equal(t, "Oats with berries.", *previews[2].LastMessage)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		previews, err := conversationService.ListConversations(ctx, user.ID.String(), 1, 1)
+		require.NoError(t, err)
+		require.Len(t, previews, 1)
+		assert.Equal(t, empty.ID, previews[0].ID)
+	})
+
+	t.Run("Invalid user ID", func(t *testing.T) {
+		_, err := conversationService.ListConversations(ctx, "not-a-uuid", 10, 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestMessageMetadata(t *testing.T) {
+	t.Run("Metadata round-trips through the message", func(t *testing.T) {
+		cost := 0.0042
+		metadata := &domain.MessageMetadata{
+			Model:            "openai/gpt-4o-mini",
+			ToolsUsed:        []string{"search_food", "log_meal"},
+			PromptTokens:     1200,
+			CompletionTokens: 300,
+			TotalTokens:      1500,
+			CostUSD:          &cost,
+		}
+
+		var message domain.Message
+		require.NoError(t, message.SetMetadata(metadata))
+		require.NotNil(t, message.Metadata)
+		assert.Contains(t, *message.Metadata, `"tools_used":["search_food","log_meal"]`)
+
+		decoded, err := message.GetMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, metadata, decoded)
+	})
+
+	t.Run("Metadata written before it was typed is still read", func(t *testing.T) {
+		stored := `{"model":"openai/gpt-4o","tools_used":["get_daily_summary"],"moderated":true}`
+		message := domain.Message{Metadata: &stored}
+
+		metadata, err := message.GetMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, "openai/gpt-4o", metadata.Model)
+		assert.Equal(t, []string{"get_daily_summary"}, metadata.ToolsUsed)
+		assert.True(t, metadata.Moderated)
+		assert.Nil(t, metadata.CostUSD)
+	})
+
+	t.Run("Messages without metadata read as empty", func(t *testing.T) {
+		var message domain.Message
+		metadata, err := message.GetMetadata()
+		require.NoError(t, err)
+		assert.True(t, metadata.IsZero())
+
+		require.NoError(t, message.SetMetadata(&domain.MessageMetadata{}))
+		assert.Nil(t, message.Metadata)
+	})
+
+	t.Run("Malformed metadata is an error", func(t *testing.T) {
+		stored := `{"tools_used": "search_food"}`
+		message := domain.Message{Metadata: &stored}
+
+		_, err := message.GetMetadata()
+		assert.Error(t, err)
+	})
+}
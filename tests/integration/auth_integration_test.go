@@ -1,14 +1,20 @@
 package integration
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"regexp"
 	"testing"
 	"time"
 
 	"fitness-tracker/internal/adapters/repositories/postgres"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/auth"
 	"fitness-tracker/internal/services"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
@@ -281,3 +287,383 @@ func TestGetUserByEmail(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestJWTKeyRotation(t *testing.T) {
+	userID := uuid.New().String()
+
+	newClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"user_id": userID,
+			"exp":     time.Now().Add(24 * time.Hour).Unix(),
+			"iat":     time.Now().Unix(),
+		}
+	}
+
+	oldSecret := "old_secret_key_for_rotation_tests_32+"
+	newSecret := "new_secret_key_for_rotation_tests_32+"
+
+	oldKeys, err := auth.NewKeySet(auth.NewHMACKey("2024-01", oldSecret))
+	require.NoError(t, err)
+
+	oldToken, err := oldKeys.Sign(newClaims())
+	require.NoError(t, err)
+
+	t.Run("Token signed with old key validates during overlap", func(t *testing.T) {
+		rotated, err := auth.NewKeySet(
+			auth.NewHMACKey("2024-02", newSecret),
+			auth.NewHMACKey("2024-01", oldSecret),
+		)
+		require.NoError(t, err)
+
+		token, err := rotated.Parse(oldToken)
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+		assert.Equal(t, "2024-01", token.Header["kid"])
+	})
+
+	t.Run("New tokens are signed with the current key", func(t *testing.T) {
+		rotated, err := auth.NewKeySet(
+			auth.NewHMACKey("2024-02", newSecret),
+			auth.NewHMACKey("2024-01", oldSecret),
+		)
+		require.NoError(t, err)
+
+		tokenString, err := rotated.Sign(newClaims())
+		require.NoError(t, err)
+
+		token, err := rotated.Parse(tokenString)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-02", token.Header["kid"])
+
+		// The old key set doesn't know the new kid
+		_, err = oldKeys.Parse(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("Token signed with retired key fails", func(t *testing.T) {
+		retired, err := auth.NewKeySet(auth.NewHMACKey("2024-02", newSecret))
+		require.NoError(t, err)
+
+		_, err = retired.Parse(oldToken)
+		assert.Error(t, err, "Should fail once the old key is removed")
+	})
+
+	t.Run("Legacy token without kid validates against current key", func(t *testing.T) {
+		legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims())
+		tokenString, err := legacy.SignedString([]byte(oldSecret))
+		require.NoError(t, err)
+
+		_, err = oldKeys.Parse(tokenString)
+		assert.NoError(t, err)
+	})
+
+	t.Run("RS256 token verifies with public key only", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		signer, err := auth.NewKeySet(auth.NewRSAKey("rsa-1", privateKey, nil))
+		require.NoError(t, err)
+
+		tokenString, err := signer.Sign(newClaims())
+		require.NoError(t, err)
+
+		// A verifier holding only the public key accepts the token
+		verifier, err := auth.NewKeySet(
+			auth.NewHMACKey("hmac-1", newSecret),
+			auth.NewRSAKey("rsa-1", nil, &privateKey.PublicKey),
+		)
+		require.NoError(t, err)
+
+		token, err := verifier.Parse(tokenString)
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+	})
+
+	t.Run("Algorithm mismatch for kid is rejected", func(t *testing.T) {
+		// HS256 token claiming the RSA key's kid must not verify
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims())
+		forged.Header["kid"] = "rsa-1"
+		tokenString, err := forged.SignedString([]byte(newSecret))
+		require.NoError(t, err)
+
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		keys, err := auth.NewKeySet(
+			auth.NewHMACKey("hmac-1", newSecret),
+			auth.NewRSAKey("rsa-1", nil, &privateKey.PublicKey),
+		)
+		require.NoError(t, err)
+
+		_, err = keys.Parse(tokenString)
+		assert.Error(t, err)
+	})
+}
+
+// captureEmailSender records sent emails instead of delivering them
+type captureEmailSender struct {
+	sent []string
+}
+
+func (s *captureEmailSender) SendEmail(ctx context.Context, to, subject, body string) error {
+	s.sent = append(s.sent, body)
+	return nil
+}
+
+// lastToken extracts the most recently emailed token
+func (s *captureEmailSender) lastToken(t *testing.T) string {
+	require.NotEmpty(t, s.sent, "Expected an email to be sent")
+	token := regexp.MustCompile(`[0-9a-f]{64}`).FindString(s.sent[len(s.sent)-1])
+	require.NotEmpty(t, token, "Expected a token in the email body")
+	return token
+}
+
+func TestPasswordReset(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	tokenRepo := postgres.NewUserTokenRepository(testDB.DB)
+	sender := &captureEmailSender{}
+
+	keys, err := auth.NewKeySet(auth.NewHMACKey("test", "test_secret_key_for_password_reset_tests"))
+	require.NoError(t, err)
+	authService := services.NewAuthService(userRepo, tokenRepo, sender, keys, time.Hour)
+
+	newPassword := "NewSecurePass123!"
+
+	t.Run("Reset password with valid token", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "reset@example.com")
+
+		err := authService.RequestPasswordReset(ctx, user.Email)
+		require.NoError(t, err)
+
+		err = authService.ResetPassword(ctx, sender.lastToken(t), newPassword)
+		require.NoError(t, err)
+
+		// Login works with the new password only
+		_, _, err = authService.Login(ctx, user.Email, newPassword)
+		assert.NoError(t, err)
+		_, _, err = authService.Login(ctx, user.Email, "test_password123")
+		assert.Error(t, err)
+	})
+
+	t.Run("Used token cannot be reused", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "reuse@example.com")
+
+		require.NoError(t, authService.RequestPasswordReset(ctx, user.Email))
+		token := sender.lastToken(t)
+
+		require.NoError(t, authService.ResetPassword(ctx, token, newPassword))
+
+		err := authService.ResetPassword(ctx, token, "AnotherPass456!")
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("Expired token is rejected", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "expired@example.com")
+
+		require.NoError(t, authService.RequestPasswordReset(ctx, user.Email))
+		token := sender.lastToken(t)
+
+		// Move the token's expiry into the past
+		err := testDB.DB.Exec("UPDATE user_tokens SET expires_at = ? WHERE user_id = ?",
+			time.Now().Add(-time.Minute), user.ID).Error
+		require.NoError(t, err)
+
+		err = authService.ResetPassword(ctx, token, newPassword)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("Requesting a new token invalidates the previous one", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "twice@example.com")
+
+		require.NoError(t, authService.RequestPasswordReset(ctx, user.Email))
+		firstToken := sender.lastToken(t)
+		require.NoError(t, authService.RequestPasswordReset(ctx, user.Email))
+		secondToken := sender.lastToken(t)
+
+		err := authService.ResetPassword(ctx, firstToken, newPassword)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+		err = authService.ResetPassword(ctx, secondToken, newPassword)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Weak password is rejected and token stays usable", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "weak@example.com")
+
+		require.NoError(t, authService.RequestPasswordReset(ctx, user.Email))
+		token := sender.lastToken(t)
+
+		err := authService.ResetPassword(ctx, token, "weakpass")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		err = authService.ResetPassword(ctx, token, newPassword)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown email does not error or send email", func(t *testing.T) {
+		sentBefore := len(sender.sent)
+
+		err := authService.RequestPasswordReset(ctx, "nobody@example.com")
+		assert.NoError(t, err)
+		assert.Len(t, sender.sent, sentBefore)
+	})
+}
+
+func TestEmailVerification(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	tokenRepo := postgres.NewUserTokenRepository(testDB.DB)
+	sender := &captureEmailSender{}
+
+	keys, err := auth.NewKeySet(auth.NewHMACKey("test", "test_secret_key_for_verification_tests"))
+	require.NoError(t, err)
+	authService := services.NewAuthService(userRepo, tokenRepo, sender, keys, time.Hour)
+
+	t.Run("Registration sends verification email and user starts unverified", func(t *testing.T) {
+		user, _, err := authService.Register(ctx, "verify@example.com", "SecurePass123!", "Verify Me")
+		require.NoError(t, err)
+		assert.False(t, user.EmailVerified)
+
+		token := sender.lastToken(t)
+		require.NoError(t, authService.VerifyEmail(ctx, token))
+
+		updated, err := userRepo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, updated.EmailVerified)
+		assert.NotNil(t, updated.EmailVerifiedAt)
+
+		// Token is single-use
+		err = authService.VerifyEmail(ctx, token)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("Re-send invalidates the previous token", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "resend@example.com")
+
+		require.NoError(t, authService.SendVerificationEmail(ctx, user.ID.String()))
+		firstToken := sender.lastToken(t)
+		require.NoError(t, authService.SendVerificationEmail(ctx, user.ID.String()))
+		secondToken := sender.lastToken(t)
+		assert.NotEqual(t, firstToken, secondToken)
+
+		err := authService.VerifyEmail(ctx, firstToken)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+		err = authService.VerifyEmail(ctx, secondToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Re-send after verification is rejected", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "already@example.com")
+
+		require.NoError(t, authService.SendVerificationEmail(ctx, user.ID.String()))
+		require.NoError(t, authService.VerifyEmail(ctx, sender.lastToken(t)))
+
+		err := authService.SendVerificationEmail(ctx, user.ID.String())
+		assert.ErrorIs(t, err, domain.ErrEmailAlreadyVerified)
+	})
+
+	t.Run("Expired verification token is rejected", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "stale@example.com")
+
+		require.NoError(t, authService.SendVerificationEmail(ctx, user.ID.String()))
+		token := sender.lastToken(t)
+
+		err := testDB.DB.Exec("UPDATE user_tokens SET expires_at = ? WHERE user_id = ?",
+			time.Now().Add(-time.Minute), user.ID).Error
+		require.NoError(t, err)
+
+		err = authService.VerifyEmail(ctx, token)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("Password reset token cannot verify email", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "crosspurpose@example.com")
+
+		require.NoError(t, authService.RequestPasswordReset(ctx, user.Email))
+
+		err := authService.VerifyEmail(ctx, sender.lastToken(t))
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
+
+func TestProfileService(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	profileService := services.NewProfileService(
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		nil,
+	)
+
+	t.Run("Update and read back profile", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "profile_service@example.com")
+
+		updated, err := profileService.UpdateProfile(ctx, user.ID.String(), &domain.ProfileUpdate{
+			Name:               stringPtr("Jane Smith"),
+			HeightCm:           float64Ptr(168),
+			WeightKg:           float64Ptr(62.5),
+			ActivityLevel:      stringPtr("very_active"),
+			Timezone:           stringPtr("Europe/Berlin"),
+			UnitSystem:         stringPtr(domain.UnitSystemImperial),
+			DietaryPreferences: map[string]interface{}{"vegetarian": true},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, updated.PasswordHash)
+
+		profile, err := profileService.GetProfile(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", profile.FirstName)
+		assert.Equal(t, "Smith", profile.LastName)
+		assert.Equal(t, 168.0, *profile.HeightCm)
+		assert.Equal(t, 62.5, *profile.WeightKg)
+		assert.Equal(t, "very_active", *profile.ActivityLevel)
+		assert.Equal(t, "Europe/Berlin", profile.Timezone)
+		assert.Equal(t, domain.UnitSystemImperial, profile.UnitSystem)
+		require.NotNil(t, profile.DietaryPreferences)
+		assert.JSONEq(t, `{"vegetarian": true}`, *profile.DietaryPreferences)
+	})
+
+	t.Run("Omitted fields are unchanged", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "partial@example.com")
+
+		_, err := profileService.UpdateProfile(ctx, user.ID.String(), &domain.ProfileUpdate{WeightKg: float64Ptr(80)})
+		require.NoError(t, err)
+
+		profile, err := profileService.UpdateProfile(ctx, user.ID.String(), &domain.ProfileUpdate{HeightCm: float64Ptr(180)})
+		require.NoError(t, err)
+		assert.Equal(t, 80.0, *profile.WeightKg)
+		assert.Equal(t, "Test", profile.FirstName)
+		assert.Equal(t, domain.UnitSystemMetric, profile.UnitSystem)
+	})
+
+	t.Run("Invalid values are rejected", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "invalid_profile@example.com")
+
+		invalid := []*domain.ProfileUpdate{
+			{HeightCm: float64Ptr(20)},
+			{WeightKg: float64Ptr(900)},
+			{ActivityLevel: stringPtr("couch_potato")},
+			{Timezone: stringPtr("Mars/Olympus_Mons")},
+			{Timezone: stringPtr("Local")},
+			{UnitSystem: stringPtr("furlongs")},
+		}
+		for _, update := range invalid {
+			_, err := profileService.UpdateProfile(ctx, user.ID.String(), update)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		}
+	})
+}
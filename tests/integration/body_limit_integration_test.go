@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fitness-tracker/internal/adapters/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// echo binds the body like the handlers do, answering 413 when it was cut off
+	echo := func(c *gin.Context) {
+		var req map[string]string
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"code": "BODY_TOO_LARGE", "message": middleware.BodyTooLargeMessage(tooLarge.Limit)})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+
+	router := gin.New()
+	router.Use(middleware.BodyLimit(100))
+	router.POST("/default", echo)
+	router.POST("/import", middleware.BodyLimit(1000), echo)
+	router.POST("/login", middleware.BodyLimit(40), echo)
+
+	// bodyOf returns a JSON body of exactly size bytes
+	bodyOf := func(size int) string {
+		body := `{"note":"` + strings.Repeat("x", size-11) + `"}`
+		require.Len(t, body, size)
+		return body
+	}
+
+	post := func(path, body string, chunked bool) *httptest.ResponseRecorder {
+		var reader io.Reader = strings.NewReader(body)
+		if chunked {
+			reader = io.MultiReader(reader) // hides the length, as a chunked upload does
+		}
+		req := httptest.NewRequest(http.MethodPost, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assertTooLarge := func(t *testing.T, w *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var resp map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "BODY_TOO_LARGE", resp["code"])
+	}
+
+	t.Run("A body at the limit is accepted", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post("/default", bodyOf(100), false).Code)
+		assert.Equal(t, http.StatusOK, post("/default", bodyOf(100), true).Code)
+	})
+
+	t.Run("A body over the limit is refused", func(t *testing.T) {
+		w := post("/default", bodyOf(101), false)
+		assertTooLarge(t, w)
+		assert.Contains(t, w.Body.String(), "must not exceed 100 bytes")
+	})
+
+	t.Run("A chunked body over the limit is cut off while reading", func(t *testing.T) {
+		assertTooLarge(t, post("/default", bodyOf(101), true))
+	})
+
+	t.Run("A route can raise the global limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post("/import", bodyOf(1000), false).Code)
+		assert.Equal(t, http.StatusOK, post("/import", bodyOf(1000), true).Code)
+		assertTooLarge(t, post("/import", bodyOf(1001), false))
+	})
+
+	t.Run("A route can lower the global limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, post("/login", bodyOf(40), false).Code)
+		assertTooLarge(t, post("/login", bodyOf(41), false))
+		assertTooLarge(t, post("/login", bodyOf(41), true))
+	})
+}
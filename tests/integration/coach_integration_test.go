@@ -0,0 +1,166 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoachDigest(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		workoutRepo,
+		userRepo,
+		goalRepo,
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	goalService := services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), workoutRepo)
+
+	user := CreateTestUser(t, testDB.DB, "coach_digest@example.com")
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "breakfast")
+
+	t.Run("Templated tip when AI is disabled", func(t *testing.T) {
+		coachService := services.NewCoachService(userRepo, summaryService, goalService, nil, "", nil)
+
+		digest, err := coachService.GetDigest(ctx, user.ID.String())
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Now().UTC().Format("2006-01-02"), digest.Date)
+		assert.Equal(t, meal.TotalCalories, digest.Consumed.Calories)
+		assert.Equal(t, domain.DefaultDailyTargets.Calories-meal.TotalCalories, digest.Remaining.Calories)
+		assert.False(t, digest.WorkedOut)
+		assert.Equal(t, domain.DigestTipSourceTemplate, digest.TipSource)
+		assert.Equal(t, digest.TemplateTip(), digest.Tip)
+	})
+
+	t.Run("AI tip is generated once until the data changes materially", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": "test-response-id",
+				"choices": []map[string]interface{}{{
+					"index":         0,
+					"message":       map[string]interface{}{"role": "assistant", "content": "Add a lean protein source to dinner."},
+					"finish_reason": "stop",
+				}},
+			})
+		}))
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		coachService := services.NewCoachService(userRepo, summaryService, goalService, client, "test-model", nil)
+
+		digest, err := coachService.GetDigest(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, domain.DigestTipSourceAI, digest.TipSource)
+		assert.Equal(t, "Add a lean protein source to dinner.", digest.Tip)
+
+		_, err = coachService.GetDigest(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+
+		// Training is a material change
+		activity := CreateTestActivity(t, testDB.DB, user.ID, "running")
+		require.NotNil(t, activity)
+
+		digest, err = coachService.GetDigest(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.True(t, digest.WorkedOut)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Invalid user ID", func(t *testing.T) {
+		coachService := services.NewCoachService(userRepo, summaryService, goalService, nil, "", nil)
+
+		_, err := coachService.GetDigest(ctx, "not-a-uuid")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestCoachDigestFingerprint(t *testing.T) {
+	goalID := uuid.New()
+	onTrack := true
+	digest := domain.CoachDigest{
+		Date:     "2025-11-19",
+		Targets:  domain.DefaultDailyTargets,
+		Consumed: domain.MacroTargets{Calories: 1210, Protein: 81, Carbohydrates: 120, Fat: 40},
+		Goals:    []domain.DigestGoal{{ID: goalID, ProgressPercent: 41, OnTrack: &onTrack}},
+	}
+	fingerprint := digest.Fingerprint()
+
+	// A small snack and a point of goal progress are not material
+	small := digest
+	small.Consumed.Calories = 1290
+	small.Consumed.Protein = 85
+	small.Goals = []domain.DigestGoal{{ID: goalID, ProgressPercent: 44, OnTrack: &onTrack}}
+	assert.Equal(t, fingerprint, small.Fingerprint())
+
+	material := digest
+	material.Consumed.Calories = 1310
+	assert.NotEqual(t, fingerprint, material.Fingerprint())
+
+	material = digest
+	material.WorkedOut = true
+	assert.NotEqual(t, fingerprint, material.Fingerprint())
+
+	offTrack := false
+	material = digest
+	material.Goals = []domain.DigestGoal{{ID: goalID, ProgressPercent: 41, OnTrack: &offTrack}}
+	assert.NotEqual(t, fingerprint, material.Fingerprint())
+
+	material = digest
+	material.Date = "2025-11-20"
+	assert.NotEqual(t, fingerprint, material.Fingerprint())
+}
+
+func TestCoachDigestTemplateTip(t *testing.T) {
+	digest := domain.CoachDigest{
+		Targets:   domain.DefaultDailyTargets,
+		Consumed:  domain.MacroTargets{Calories: 2400, Protein: 160},
+		Remaining: domain.MacroTargets{Protein: 0},
+		WorkedOut: true,
+	}
+	assert.Contains(t, digest.TemplateTip(), "over today's calorie target")
+
+	digest.Consumed = domain.MacroTargets{Calories: 1200, Protein: 60}
+	digest.Remaining = domain.MacroTargets{Calories: 800, Protein: 90}
+	assert.Contains(t, digest.TemplateTip(), "90g of protein")
+
+	digest.Remaining.Protein = 10
+	digest.WorkedOut = false
+	assert.Contains(t, digest.TemplateTip(), "haven't trained today")
+
+	offTrack := false
+	digest.WorkedOut = true
+	digest.Goals = []domain.DigestGoal{{Description: "Lose 5kg", OnTrack: &offTrack}}
+	assert.Contains(t, digest.TemplateTip(), "\"Lose 5kg\" is behind schedule")
+
+	digest.Goals = nil
+	assert.Contains(t, digest.TemplateTip(), "on track today")
+}
@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fitness-tracker/internal/adapters/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCompression(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	foods := make([]gin.H, 200)
+	for i := range foods {
+		foods[i] = gin.H{"id": i, "name": fmt.Sprintf("Food %d", i), "calories": 120.5}
+	}
+
+	router := gin.New()
+	router.Use(middleware.Compress(1024))
+	router.GET("/large", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"foods": foods})
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for i := 0; i < 50; i++ {
+			fmt.Fprintf(c.Writer, "data: {\"chunk\": %d, \"text\": \"a streamed piece of the reply\"}\n\n", i)
+			c.Writer.Flush()
+		}
+	})
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	plain := get("/large", "")
+	require.Equal(t, http.StatusOK, plain.Code)
+
+	t.Run("Large JSON is gzipped when requested", func(t *testing.T) {
+		w := get("/large", "gzip, deflate, br")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+		assert.Less(t, w.Body.Len(), plain.Body.Len())
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, plain.Body.String(), string(body))
+
+		var decoded map[string][]gin.H
+		require.NoError(t, json.Unmarshal(body, &decoded))
+		assert.Len(t, decoded["foods"], 200)
+	})
+
+	t.Run("Deflate is used when gzip is not accepted", func(t *testing.T) {
+		w := get("/large", "gzip;q=0, deflate")
+		assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+
+		reader, err := zlib.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, plain.Body.String(), string(body))
+	})
+
+	t.Run("Left alone without Accept-Encoding", func(t *testing.T) {
+		assert.Empty(t, plain.Header().Get("Content-Encoding"))
+		assert.True(t, json.Valid(plain.Body.Bytes()))
+	})
+
+	t.Run("Small responses are not compressed", func(t *testing.T) {
+		w := get("/small", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.JSONEq(t, `{"status": "ok"}`, w.Body.String())
+	})
+
+	t.Run("Streaming routes are not compressed", func(t *testing.T) {
+		w := get("/stream", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Body.String(), "data: {\"chunk\": 49")
+
+		req := httptest.NewRequest(http.MethodGet, "/large", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Accept", "text/event-stream")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, plain.Body.String(), rec.Body.String())
+	})
+}
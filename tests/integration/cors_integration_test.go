@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fitness-tracker/internal/adapters/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(origins ...string) *gin.Engine {
+		router := gin.New()
+		router.Use(middleware.CORS(middleware.CORSConfig{
+			AllowedOrigins:   origins,
+			AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut},
+			AllowedHeaders:   []string{"Content-Type", "Authorization"},
+			ExposedHeaders:   []string{"Content-Length"},
+			AllowCredentials: true,
+			MaxAge:           3600,
+		}))
+		router.Use(middleware.RequestID())
+		router.GET("/api/v1/meals", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+		return router
+	}
+
+	send := func(router *gin.Engine, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	preflight := map[string]string{
+		"Access-Control-Request-Method":  http.MethodPut,
+		"Access-Control-Request-Headers": "authorization",
+	}
+
+	t.Run("Listed origin is echoed with credentials", func(t *testing.T) {
+		router := newRouter("https://app.example.com", "*")
+		w := send(router, http.MethodGet, "/api/v1/meals", "https://app.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin")
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+		assert.NotEmpty(t, w.Header().Get(middleware.RequestIDHeader))
+	})
+
+	t.Run("Origin allowed only by the wildcard gets no credentials", func(t *testing.T) {
+		router := newRouter("https://app.example.com", "*")
+		w := send(router, http.MethodGet, "/api/v1/meals", "https://other.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Disallowed origin gets no CORS headers", func(t *testing.T) {
+		router := newRouter("https://app.example.com")
+		w := send(router, http.MethodGet, "/api/v1/meals", "https://evil.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+		w = send(router, http.MethodOptions, "/api/v1/meals", "https://evil.example.com", preflight)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Preflight is answered for any route", func(t *testing.T) {
+		router := newRouter("https://app.example.com")
+		for _, path := range []string{"/api/v1/meals", "/api/v1/workouts/123/exercises/reorder"} {
+			w := send(router, http.MethodOptions, path, "https://APP.example.com/", preflight)
+
+			require.Equal(t, http.StatusNoContent, w.Code, path)
+			assert.Equal(t, "https://APP.example.com/", w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			assert.Equal(t, "GET, POST, PUT", w.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+		}
+	})
+
+	t.Run("Requests without an origin pass through", func(t *testing.T) {
+		router := newRouter("https://app.example.com")
+		w := send(router, http.MethodGet, "/api/v1/meals", "", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	apperrors "fitness-tracker/internal/pkg/errors"
+	"fitness-tracker/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDateRange(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := utils.ParseDate(s)
+		require.NoError(t, err)
+		return d
+	}
+
+	t.Run("No dates leaves both bounds to the caller", func(t *testing.T) {
+		start, end, err := utils.ParseDateRange("", "", 30)
+		require.NoError(t, err)
+		assert.Nil(t, start)
+		assert.Nil(t, end)
+	})
+
+	t.Run("Both dates", func(t *testing.T) {
+		start, end, err := utils.ParseDateRange("2025-01-01", "2025-01-31", 30)
+		require.NoError(t, err)
+		assert.Equal(t, day("2025-01-01"), *start)
+		assert.Equal(t, day("2025-01-31"), *end)
+	})
+
+	t.Run("Only end defaults the start", func(t *testing.T) {
+		start, end, err := utils.ParseDateRange("", "2025-01-31", 30)
+		require.NoError(t, err)
+		assert.Equal(t, day("2025-01-02"), *start)
+		assert.Equal(t, day("2025-01-31"), *end)
+	})
+
+	t.Run("Only start defaults the end to today", func(t *testing.T) {
+		recent := time.Now().UTC().AddDate(0, 0, -10).Format(utils.DateFormat)
+		start, end, err := utils.ParseDateRange(recent, "", 30)
+		require.NoError(t, err)
+		assert.Equal(t, day(recent), *start)
+		assert.Equal(t, utils.StartOfDay(time.Now().UTC()), *end)
+	})
+
+	t.Run("Malformed dates are validation errors", func(t *testing.T) {
+		_, _, err := utils.ParseDateRange("01/02/2025", "", 30)
+		require.True(t, apperrors.IsValidation(err))
+		appErr, _ := apperrors.GetAppError(err)
+		assert.Equal(t, "start", appErr.Details["field"])
+
+		_, _, err = utils.ParseDateRange("2025-01-01", "2025-13-01", 30)
+		require.True(t, apperrors.IsValidation(err))
+		appErr, _ = apperrors.GetAppError(err)
+		assert.Equal(t, "end", appErr.Details["field"])
+	})
+
+	t.Run("Inverted range is a bad request", func(t *testing.T) {
+		_, _, err := utils.ParseDateRange("2025-02-01", "2025-01-01", 30)
+		appErr, ok := apperrors.GetAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperrors.ErrorTypeBadRequest, appErr.Type)
+		assert.Equal(t, 400, appErr.GetHTTPStatus())
+	})
+
+	t.Run("Span is capped", func(t *testing.T) {
+		_, _, err := utils.ParseDateRange("2024-01-01", "2024-12-31", 30)
+		require.NoError(t, err, "366 days in a leap year is allowed")
+
+		_, _, err = utils.ParseDateRange("2024-01-01", "2025-01-01", 30)
+		appErr, ok := apperrors.GetAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperrors.ErrorTypeBadRequest, appErr.Type)
+		assert.Equal(t, 367, appErr.Details["days"])
+	})
+}
+
+func TestParsePagination(t *testing.T) {
+	t.Run("Missing limit uses the default", func(t *testing.T) {
+		limit, err := utils.ParseLimit("", 20, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 20, limit)
+	})
+
+	t.Run("Limit above the max is capped", func(t *testing.T) {
+		limit, err := utils.ParseLimit("1000000", 20, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 100, limit)
+
+		limit, err = utils.ParseLimit("50", 20, 100)
+		require.NoError(t, err)
+		assert.Equal(t, 50, limit)
+	})
+
+	t.Run("Zero, negative and malformed limits are rejected", func(t *testing.T) {
+		for _, limitStr := range []string{"0", "-5", "ten", "10abc"} {
+			_, err := utils.ParseLimit(limitStr, 20, 100)
+			assert.True(t, apperrors.IsValidation(err), limitStr)
+		}
+	})
+
+	t.Run("Offset defaults to zero and rejects negatives", func(t *testing.T) {
+		offset, err := utils.ParseOffset("")
+		require.NoError(t, err)
+		assert.Zero(t, offset)
+
+		offset, err = utils.ParseOffset("40")
+		require.NoError(t, err)
+		assert.Equal(t, 40, offset)
+
+		_, err = utils.ParseOffset("-1")
+		assert.True(t, apperrors.IsValidation(err))
+	})
+}
@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDemoSeed(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	demoService := services.NewDemoService(postgres.NewDemoRepository(testDB.DB), userRepo, summaryService)
+
+	user := CreateTestUser(t, testDB.DB, "demo@example.com")
+	own := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+
+	t.Run("Seeds days of flagged sample data", func(t *testing.T) {
+		result, err := demoService.SeedDemoData(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Positive(t, result.Meals)
+		assert.Positive(t, result.Activities)
+		assert.Positive(t, result.Workouts)
+		assert.Positive(t, result.Metrics)
+
+		var meals []*domain.Meal
+		require.NoError(t, testDB.DB.Where("user_id = ? AND is_demo", user.ID).Find(&meals).Error)
+		assert.Len(t, meals, result.Meals)
+		for _, meal := range meals {
+			assert.False(t, meal.ConsumedAt.After(time.Now()), "sample meal placed in the future")
+		}
+
+		var summaries int64
+		require.NoError(t, testDB.DB.Model(&domain.DailySummary{}).Where("user_id = ?", user.ID).Count(&summaries).Error)
+		assert.Positive(t, summaries)
+	})
+
+	t.Run("An account is seeded once", func(t *testing.T) {
+		_, err := demoService.SeedDemoData(ctx, user.ID.String())
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("Clearing removes only the sample data", func(t *testing.T) {
+		// Food the user logged under a sample meal goes with it
+		var sample domain.Meal
+		require.NoError(t, testDB.DB.Where("user_id = ? AND is_demo", user.ID).First(&sample).Error)
+		food := CreateTestFood(t, testDB.DB, "Banana", 89)
+		require.NoError(t, mealRepo.AddFoodItem(ctx, &domain.MealFoodItem{MealID: sample.ID, FoodID: food.ID, Quantity: 1, Unit: "serving"}))
+
+		result, err := demoService.ClearDemoData(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Positive(t, result.Meals)
+
+		for _, model := range []interface{}{&domain.Meal{}, &domain.Activity{}, &domain.Workout{}, &domain.Metric{}} {
+			var count int64
+			require.NoError(t, testDB.DB.Unscoped().Model(model).Where("user_id = ? AND is_demo", user.ID).Count(&count).Error)
+			assert.Zero(t, count)
+		}
+		var items int64
+		require.NoError(t, testDB.DB.Model(&domain.MealFoodItem{}).Where("meal_id = ?", sample.ID).Count(&items).Error)
+		assert.Zero(t, items)
+
+		kept, err := mealRepo.GetByID(ctx, own.ID)
+		require.NoError(t, err)
+		assert.False(t, kept.IsDemo)
+
+		// Nothing left to clear, and the account can be seeded again
+		result, err = demoService.ClearDemoData(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, domain.DemoSeedResult{}, *result)
+		_, err = demoService.SeedDemoData(ctx, user.ID.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Today only holds what already happened", func(t *testing.T) {
+		loc := time.UTC
+		now := time.Date(2025, 11, 19, 10, 0, 0, 0, loc)
+		data := domain.BuildDemoData(user.ID, now, loc, 82)
+
+		for _, meal := range data.Meals {
+			assert.False(t, meal.ConsumedAt.After(now))
+			assert.True(t, meal.IsDemo)
+		}
+		for _, metric := range data.Metrics {
+			assert.False(t, metric.MeasuredAt.After(now))
+		}
+		first := now.AddDate(0, 0, -(domain.DemoSeedDays - 1))
+		assert.Equal(t, first.Format("2006-01-02"), data.Meals[0].ConsumedAt.Format("2006-01-02"))
+		assert.InDelta(t, 82.0, data.Metrics[len(data.Metrics)-1].Value, 0.01)
+	})
+}
@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Handlers map errors matching domain.ErrNotFound to 404, so every service must report a
+// missing resource that way, whatever message it wraps the error in
+func TestNotFoundErrors(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	actionRepo := postgres.NewUserActionRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		workoutRepo,
+		userRepo,
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+	mealService := services.NewMealService(mealRepo, foodRepo, actionRepo, summaryService, postgres.NewTransactor(testDB.DB), nil)
+	foodService := services.NewFoodService(foodRepo, mealRepo, postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+	activityService := services.NewActivityService(postgres.NewActivityRepository(testDB.DB), actionRepo, summaryService)
+	workoutService := services.NewWorkoutService(workoutRepo, userRepo, actionRepo, summaryService)
+	goalService := services.NewGoalService(postgres.NewGoalRepository(testDB.DB), metricRepo, workoutRepo)
+
+	missing := uuid.New().String()
+	user := CreateTestUser(t, testDB.DB, "not_found_errors@example.com")
+
+	t.Run("Missing resources match ErrNotFound", func(t *testing.T) {
+		checks := map[string]func() error{
+			"meal": func() error {
+				_, err := mealService.GetMeal(ctx, missing)
+				return err
+			},
+			"delete meal": func() error { return mealService.DeleteMeal(ctx, missing) },
+			"food": func() error {
+				_, err := foodService.GetFood(ctx, missing)
+				return err
+			},
+			"activity": func() error {
+				_, err := activityService.GetActivity(ctx, missing)
+				return err
+			},
+			"delete activity": func() error { return activityService.DeleteActivity(ctx, missing) },
+			"workout": func() error {
+				_, err := workoutService.GetWorkout(ctx, user.ID, uuid.New())
+				return err
+			},
+			"delete workout": func() error { return workoutService.DeleteWorkout(ctx, user.ID, uuid.New()) },
+			"delete goal":    func() error { return goalService.DeleteGoal(ctx, missing) },
+		}
+
+		for name, check := range checks {
+			assert.ErrorIs(t, check(), domain.ErrNotFound, name)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fitness-tracker/internal/adapters/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagConditionalGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	food := gin.H{"id": "food-1", "name": "Chicken Breast", "calories": 165.0}
+	router := gin.New()
+	router.GET("/foods/:id", middleware.ETag(), func(c *gin.Context) {
+		if c.Param("id") != "food-1" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusOK, food)
+	})
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/foods/food-1", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.JSONEq(t, `{"id": "food-1", "name": "Chicken Breast", "calories": 165}`, first.Body.String())
+
+	t.Run("The same content gets the same tag", func(t *testing.T) {
+		assert.Equal(t, etag, get("/foods/food-1", "").Header().Get("ETag"))
+	})
+
+	t.Run("A matching If-None-Match gets 304 without a body", func(t *testing.T) {
+		w := get("/foods/food-1", etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		// Any tag in a list matches, as does the strong form of the weak tag
+		assert.Equal(t, http.StatusNotModified, get("/foods/food-1", `"other", `+etag).Code)
+		assert.Equal(t, http.StatusNotModified, get("/foods/food-1", etag[2:]).Code)
+	})
+
+	t.Run("A stale tag gets the full response", func(t *testing.T) {
+		food["calories"] = 170.0
+		defer func() { food["calories"] = 165.0 }()
+
+		w := get("/foods/food-1", etag)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id": "food-1", "name": "Chicken Breast", "calories": 170}`, w.Body.String())
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("Errors are not tagged", func(t *testing.T) {
+		w := get("/foods/missing", etag)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), "not found")
+	})
+}
@@ -1,11 +1,15 @@
 package integration
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"fitness-tracker/internal/adapters/repositories/postgres"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -80,6 +84,177 @@ func TestFoodSearch(t *testing.T) {
 	})
 }
 
+func TestFoodSearchAcrossFields(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+
+	food := func(name string, brand, description *string, verified bool) *domain.Food {
+		f := &domain.Food{
+			Name:          name,
+			Brand:         brand,
+			Description:   description,
+			ServingSize:   100,
+			ServingUnit:   "g",
+			Calories:      200,
+			Protein:       20,
+			Carbohydrates: 20,
+			Fat:           5,
+			IsVerified:    verified,
+		}
+		require.NoError(t, foodRepo.Create(ctx, f))
+		return f
+	}
+
+	questBar := food("Protein Bar Cookies & Cream", stringPtr("Quest"), nil, true)
+	questChips := food("Protein Chips", stringPtr("Quest"), nil, false)
+	questInName := food("Quest Bar Style Snack", nil, nil, false)
+	describedOnly := food("Snack Bites", nil, stringPtr("Tastes like a Quest bar"), true)
+	food("Granola Bar", stringPtr("Nature Valley"), nil, true)
+
+	t.Run("Brand-only query finds branded foods", func(t *testing.T) {
+		results, err := foodRepo.Search(ctx, "quest", domain.FoodSearchFilter{}, 10, 0)
+		require.NoError(t, err)
+
+		ids := make([]uuid.UUID, 0, len(results))
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		assert.Contains(t, ids, questBar.ID)
+		assert.Contains(t, ids, questChips.ID)
+		assert.Len(t, results, 4)
+	})
+
+	t.Run("Words may match different fields", func(t *testing.T) {
+		results, err := foodRepo.Search(ctx, "Quest bar", domain.FoodSearchFilter{}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, results, 3, "Quest Protein Chips has no bar and Granola Bar is not Quest")
+
+		// Both words in the name beat a brand match, which beats a description match
+		assert.Equal(t, questInName.ID, results[0].ID)
+		assert.Equal(t, questBar.ID, results[1].ID)
+		assert.Equal(t, describedOnly.ID, results[2].ID)
+	})
+
+	t.Run("Verified only", func(t *testing.T) {
+		results, err := foodRepo.Search(ctx, "quest", domain.FoodSearchFilter{VerifiedOnly: true}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, result := range results {
+			assert.True(t, result.IsVerified)
+		}
+	})
+
+	t.Run("LIKE wildcards match literally", func(t *testing.T) {
+		results, err := foodRepo.Search(ctx, "%", domain.FoodSearchFilter{}, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestFoodSearchSort(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	foodService := services.NewFoodService(foodRepo, postgres.NewMealRepository(testDB.DB), postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+
+	food := func(name string, calories, protein float64) *domain.Food {
+		f := &domain.Food{
+			Name:        name,
+			ServingSize: 100,
+			ServingUnit: "g",
+			Calories:    calories,
+			Protein:     protein,
+			IsVerified:  true,
+		}
+		require.NoError(t, foodRepo.Create(ctx, f))
+		return f
+	}
+
+	// Equally relevant to "chicken", so relevance falls back to alphabetical order
+	bouillon := food("Chicken Bouillon Zero", 0, 0)
+	breast := food("Chicken Breast", 165, 31)
+	nuggets := food("Chicken Nuggets", 300, 15)
+	salad := food("Chicken Salad", 120, 12)
+
+	search := func(t *testing.T, sort string) []*domain.Food {
+		results, err := foodService.SearchFoods(ctx, "chicken", domain.FoodSearchFilter{Sort: sort}, 10)
+		require.NoError(t, err)
+		return results
+	}
+	ids := func(foods []*domain.Food) []uuid.UUID {
+		result := make([]uuid.UUID, 0, len(foods))
+		for _, f := range foods {
+			result = append(result, f.ID)
+		}
+		return result
+	}
+
+	t.Run("Relevance is the default", func(t *testing.T) {
+		expected := []uuid.UUID{bouillon.ID, breast.ID, nuggets.ID, salad.ID}
+		assert.Equal(t, expected, ids(search(t, "")))
+		assert.Equal(t, expected, ids(search(t, domain.FoodSortRelevance)))
+	})
+
+	t.Run("Protein density puts the most protein per calorie first", func(t *testing.T) {
+		results := search(t, domain.FoodSortProteinDensity)
+		assert.Equal(t, []uuid.UUID{breast.ID, salad.ID, nuggets.ID, bouillon.ID}, ids(results))
+
+		require.NotNil(t, results[0].ProteinDensity)
+		assert.InDelta(t, 18.79, *results[0].ProteinDensity, 0.01)
+		assert.Nil(t, results[3].ProteinDensity, "a food without calories has no density")
+	})
+
+	t.Run("Calories puts the lightest first", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{bouillon.ID, salad.ID, breast.ID, nuggets.ID}, ids(search(t, domain.FoodSortCalories)))
+	})
+
+	t.Run("Unknown sort is rejected", func(t *testing.T) {
+		_, err := foodService.SearchFoods(ctx, "chicken", domain.FoodSearchFilter{Sort: "tastiest"}, 10)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestListVerifiedFoods(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	user := CreateTestUser(t, testDB.DB, "verified_foods@example.com")
+
+	apple := CreateTestFood(t, testDB.DB, "Apple", 52)
+	zucchini := CreateTestFood(t, testDB.DB, "Zucchini", 17)
+	unverified := &domain.Food{Name: "Unverified Bar", ServingSize: 50, ServingUnit: "g", Calories: 200}
+	water := &domain.Food{Name: "Water", ServingSize: 100, ServingUnit: "ml", IsVerified: true}
+	for _, food := range []*domain.Food{unverified, water} {
+		require.NoError(t, foodRepo.Create(ctx, food))
+	}
+
+	// Logged foods come first whatever their name
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Create(&domain.MealFoodItem{
+		MealID: meal.ID, FoodID: zucchini.ID, Quantity: 1, Unit: "serving", Calories: 17,
+	}).Error)
+
+	foods, err := foodRepo.ListVerified(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, foods, 2)
+	assert.Equal(t, zucchini.ID, foods[0].ID)
+	assert.Equal(t, apple.ID, foods[1].ID)
+
+	again, err := foodRepo.ListVerified(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, foods[1].ID, again[1].ID, "order is stable between calls")
+}
+
 func TestCreateCustomFood(t *testing.T) {
 	// Setup
 	testDB := SetupTestDB(t)
@@ -298,3 +473,414 @@ func TestGetFoodsByCategory(t *testing.T) {
 		}
 	})
 }
+
+func TestGetRecentFoods(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	foodService := services.NewFoodService(foodRepo, mealRepo, postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+
+	user := CreateTestUser(t, testDB.DB, "recentfoods@example.com")
+	oats := CreateTestFood(t, testDB.DB, "Oats", 389)
+	banana := CreateTestFood(t, testDB.DB, "Banana", 89)
+	rice := CreateTestFood(t, testDB.DB, "Rice", 130)
+
+	logFood := func(food *domain.Food, quantity float64, unit string, consumedAt time.Time) {
+		meal := CreateTestMeal(t, testDB.DB, user.ID, "breakfast")
+		require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", consumedAt).Error)
+		require.NoError(t, mealRepo.AddFoodItem(ctx, &domain.MealFoodItem{
+			MealID:   meal.ID,
+			FoodID:   food.ID,
+			Quantity: quantity,
+			Unit:     unit,
+		}))
+	}
+
+	now := time.Now()
+	logFood(oats, 50, "g", now.Add(-72*time.Hour))
+	logFood(oats, 50, "g", now.Add(-48*time.Hour))
+	logFood(oats, 80, "g", now.Add(-24*time.Hour))
+	logFood(banana, 1, "piece", now.Add(-2*time.Hour))
+	logFood(rice, 200, "g", now.AddDate(0, 0, -120)) // outside the recent window
+
+	t.Run("Deduplicated and ordered by recency", func(t *testing.T) {
+		recent, err := foodService.GetRecentFoods(ctx, user.ID.String(), 0)
+		require.NoError(t, err)
+		require.Len(t, recent, 2)
+
+		assert.Equal(t, banana.ID, recent[0].Food.ID)
+		assert.Equal(t, 1, recent[0].TimesLogged)
+
+		assert.Equal(t, oats.ID, recent[1].Food.ID)
+		assert.Equal(t, 3, recent[1].TimesLogged)
+		assert.Equal(t, 50.0, recent[1].TypicalQuantity)
+		assert.Equal(t, "g", recent[1].TypicalUnit)
+	})
+
+	t.Run("Typical quantity and unit come from the same entries", func(t *testing.T) {
+		yogurtUser := CreateTestUser(t, testDB.DB, "recentfoods-yogurt@example.com")
+		yogurt := CreateTestFood(t, testDB.DB, "Yogurt", 61)
+		for i, entry := range []struct {
+			quantity float64
+			unit     string
+		}{{1, "cup"}, {1, "cup"}, {150, "g"}, {200, "g"}, {250, "g"}} {
+			meal := CreateTestMeal(t, testDB.DB, yogurtUser.ID, "snack")
+			require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", now.Add(-time.Duration(i+1)*time.Hour)).Error)
+			require.NoError(t, mealRepo.AddFoodItem(ctx, &domain.MealFoodItem{MealID: meal.ID, FoodID: yogurt.ID, Quantity: entry.quantity, Unit: entry.unit}))
+		}
+
+		// The most common quantity (1) and unit (g) were never logged together
+		recent, err := foodService.GetRecentFoods(ctx, yogurtUser.ID.String(), 0)
+		require.NoError(t, err)
+		require.Len(t, recent, 1)
+		assert.Equal(t, 5, recent[0].TimesLogged)
+		assert.Equal(t, 1.0, recent[0].TypicalQuantity)
+		assert.Equal(t, "cup", recent[0].TypicalUnit)
+	})
+
+	t.Run("Limit is applied", func(t *testing.T) {
+		recent, err := foodService.GetRecentFoods(ctx, user.ID.String(), 1)
+		require.NoError(t, err)
+		require.Len(t, recent, 1)
+		assert.Equal(t, banana.ID, recent[0].Food.ID)
+	})
+
+	t.Run("Other users' history is excluded", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "recentfoods-other@example.com")
+		recent, err := foodService.GetRecentFoods(ctx, other.ID.String(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, recent)
+	})
+
+	t.Run("Invalid user ID", func(t *testing.T) {
+		_, err := foodService.GetRecentFoods(ctx, "not-a-uuid", 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestFoodStars(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	foodService := services.NewFoodService(foodRepo, mealRepo, postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+
+	user := CreateTestUser(t, testDB.DB, "foodstars@example.com")
+	other := CreateTestUser(t, testDB.DB, "foodstars-other@example.com")
+	oats := CreateTestFood(t, testDB.DB, "Oats", 389)
+	require.NoError(t, testDB.DB.Model(oats).Update("is_verified", true).Error)
+	shake := CreateTestFood(t, testDB.DB, "Homemade Protein Shake", 220)
+	banana := CreateTestFood(t, testDB.DB, "Banana", 89)
+
+	t.Run("Star verified and own foods", func(t *testing.T) {
+		require.NoError(t, foodService.StarFood(ctx, user.ID.String(), oats.ID.String()))
+		require.NoError(t, foodService.StarFood(ctx, user.ID.String(), shake.ID.String()))
+		// Starring twice is a no-op
+		require.NoError(t, foodService.StarFood(ctx, user.ID.String(), oats.ID.String()))
+
+		starred, err := foodService.GetStarredFoods(ctx, user.ID.String(), 0, 0)
+		require.NoError(t, err)
+		require.Len(t, starred, 2)
+		for _, food := range starred {
+			assert.True(t, food.IsStarred)
+		}
+		assert.ElementsMatch(t, []uuid.UUID{oats.ID, shake.ID}, []uuid.UUID{starred[0].ID, starred[1].ID})
+	})
+
+	t.Run("Stars are per user", func(t *testing.T) {
+		starred, err := foodService.GetStarredFoods(ctx, other.ID.String(), 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, starred)
+	})
+
+	t.Run("Marks starred foods in results", func(t *testing.T) {
+		foods, err := foodService.SearchFoods(ctx, "a", domain.FoodSearchFilter{}, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, foods)
+
+		foodService.MarkStarred(ctx, user.ID.String(), foods)
+		for _, food := range foods {
+			assert.Equal(t, food.ID != banana.ID, food.IsStarred, food.Name)
+		}
+
+		foodService.MarkStarred(ctx, other.ID.String(), foods)
+		for _, food := range foods {
+			assert.False(t, food.IsStarred, food.Name)
+		}
+	})
+
+	t.Run("Recent foods carry the star", func(t *testing.T) {
+		meal := CreateTestMeal(t, testDB.DB, user.ID, "breakfast")
+		for _, food := range []*domain.Food{oats, banana} {
+			require.NoError(t, mealRepo.AddFoodItem(ctx, &domain.MealFoodItem{
+				MealID:   meal.ID,
+				FoodID:   food.ID,
+				Quantity: 100,
+				Unit:     "g",
+			}))
+		}
+
+		recent, err := foodService.GetRecentFoods(ctx, user.ID.String(), 0)
+		require.NoError(t, err)
+		require.Len(t, recent, 2)
+		for _, r := range recent {
+			assert.Equal(t, r.Food.ID == oats.ID, r.Food.IsStarred, r.Food.Name)
+		}
+	})
+
+	t.Run("Unstar", func(t *testing.T) {
+		require.NoError(t, foodService.UnstarFood(ctx, user.ID.String(), oats.ID.String()))
+		// Unstarring a food that is not starred is a no-op
+		require.NoError(t, foodService.UnstarFood(ctx, user.ID.String(), banana.ID.String()))
+
+		starred, err := foodService.GetStarredFoods(ctx, user.ID.String(), 0, 0)
+		require.NoError(t, err)
+		require.Len(t, starred, 1)
+		assert.Equal(t, shake.ID, starred[0].ID)
+	})
+
+	t.Run("Unknown or invalid food", func(t *testing.T) {
+		err := foodService.StarFood(ctx, user.ID.String(), uuid.New().String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		err = foodService.StarFood(ctx, user.ID.String(), "not-a-uuid")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = foodService.GetStarredFoods(ctx, user.ID.String(), 0, -1)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestFoodRevisions(t *testing.T) {
+	t.Run("Diff records only the changed fields", func(t *testing.T) {
+		before := &domain.Food{ID: uuid.New(), Name: "Oats", ServingSize: 100, ServingUnit: "g", Calories: 389}
+		after := *before
+		after.Calories = 379
+		after.Brand = stringPtr("Acme")
+		after.UpdatedAt = time.Now()
+
+		editor := uuid.New()
+		revision, err := domain.NewFoodRevision(before, &after, &editor)
+		require.NoError(t, err)
+		require.NotNil(t, revision)
+
+		assert.Equal(t, before.ID, revision.FoodID)
+		assert.Equal(t, &editor, revision.EditedBy)
+		assert.JSONEq(t, `["brand", "calories"]`, revision.ChangedFields)
+		assert.JSONEq(t, `{"brand": null, "calories": 389}`, revision.Before)
+		assert.JSONEq(t, `{"brand": "Acme", "calories": 379}`, revision.After)
+		assert.True(t, revision.NutritionChanged)
+	})
+
+	t.Run("Non-nutrition edits are flagged as such", func(t *testing.T) {
+		before := &domain.Food{ID: uuid.New(), Name: "Oats", Calories: 389}
+		after := *before
+		after.Name = "Rolled oats"
+
+		revision, err := domain.NewFoodRevision(before, &after, nil)
+		require.NoError(t, err)
+		require.NotNil(t, revision)
+		assert.False(t, revision.NutritionChanged)
+		assert.Nil(t, revision.EditedBy)
+	})
+
+	t.Run("No revision when only bookkeeping fields change", func(t *testing.T) {
+		before := &domain.Food{ID: uuid.New(), Name: "Oats", Calories: 389}
+		after := *before
+		after.UpdatedAt = time.Now()
+
+		revision, err := domain.NewFoodRevision(before, &after, nil)
+		require.NoError(t, err)
+		assert.Nil(t, revision)
+	})
+}
+
+func TestFoodRevisionHistory(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	revisionRepo := postgres.NewFoodRevisionRepository(testDB.DB)
+	user := CreateTestUser(t, testDB.DB, "foodhistory@example.com")
+	food := CreateTestFood(t, testDB.DB, "Oats", 389)
+
+	// Log a serving before the food is edited
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "breakfast")
+	item := &domain.MealFoodItem{MealID: meal.ID, FoodID: food.ID, Quantity: 1, Unit: "serving", Calories: 389}
+	require.NoError(t, testDB.DB.Create(item).Error)
+
+	for i, calories := range []float64{379, 370} {
+		before := *food
+		food.Calories = calories
+		require.NoError(t, testDB.DB.Save(food).Error)
+
+		revision, err := domain.NewFoodRevision(&before, food, &user.ID)
+		require.NoError(t, err)
+		revision.CreatedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		require.NoError(t, revisionRepo.Create(ctx, revision))
+	}
+
+	t.Run("Lists revisions newest first", func(t *testing.T) {
+		revisions, err := revisionRepo.ListByFood(ctx, food.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.JSONEq(t, `{"calories": 370}`, revisions[0].After)
+		assert.JSONEq(t, `{"calories": 389}`, revisions[1].Before)
+
+		page, err := revisionRepo.ListByFood(ctx, food.ID, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, revisions[1].ID, page[0].ID)
+	})
+
+	t.Run("Logged meal items keep their nutrition", func(t *testing.T) {
+		var stored domain.MealFoodItem
+		require.NoError(t, testDB.DB.First(&stored, "id = ?", item.ID).Error)
+		assert.InDelta(t, 389.0, stored.Calories, 0.01)
+	})
+
+	t.Run("Deleting the editor keeps the history", func(t *testing.T) {
+		accountRepo := postgres.NewAccountRepository(testDB.DB)
+		require.NoError(t, accountRepo.PurgeUser(ctx, user.ID))
+
+		revisions, err := revisionRepo.ListByFood(ctx, food.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Nil(t, revisions[0].EditedBy)
+	})
+}
+
+func TestListServingUnits(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	foodService := services.NewFoodService(foodRepo, postgres.NewMealRepository(testDB.DB), postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+
+	for _, unit := range []*domain.ServingUnit{
+		{Name: "slice", DisplayName: "Slice", Category: domain.ServingUnitCategoryCount},
+		{Name: "cup", DisplayName: "Cup", Category: domain.ServingUnitCategoryVolume},
+		{Name: "g", DisplayName: "Gram", Category: domain.ServingUnitCategoryWeight},
+		{Name: "oz", DisplayName: "Ounce", Category: domain.ServingUnitCategoryWeight},
+	} {
+		require.NoError(t, testDB.DB.Create(unit).Error)
+	}
+
+	groups, err := foodService.ListServingUnits(ctx)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, domain.ServingUnitCategoryWeight, groups[0].Category)
+	require.Len(t, groups[0].Units, 2)
+	assert.Equal(t, "g", groups[0].Units[0].Name)
+	assert.Equal(t, "oz", groups[0].Units[1].Name)
+	assert.Equal(t, domain.ServingUnitCategoryVolume, groups[1].Category)
+	assert.Equal(t, domain.ServingUnitCategoryCount, groups[2].Category)
+}
+
+func TestGroupServingUnits(t *testing.T) {
+	units := []*domain.ServingUnit{
+		{Name: "pinch", Category: "other"},
+		{Name: "cup", Category: domain.ServingUnitCategoryVolume},
+		{Name: "piece", Category: domain.ServingUnitCategoryCount},
+		{Name: "tbsp", Category: domain.ServingUnitCategoryVolume},
+		{Name: "g", Category: domain.ServingUnitCategoryWeight},
+	}
+
+	groups := domain.GroupServingUnits(units)
+	require.Len(t, groups, 4)
+	assert.Equal(t, domain.ServingUnitCategoryWeight, groups[0].Category)
+	assert.Equal(t, domain.ServingUnitCategoryVolume, groups[1].Category)
+	assert.Equal(t, []*domain.ServingUnit{units[1], units[3]}, groups[1].Units)
+	assert.Equal(t, domain.ServingUnitCategoryCount, groups[2].Category)
+	assert.Equal(t, "other", groups[3].Category)
+
+	assert.Empty(t, domain.GroupServingUnits(nil))
+}
+
+func TestGetFoodAlternatives(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	foodService := services.NewFoodService(foodRepo, postgres.NewMealRepository(testDB.DB), postgres.NewFoodRevisionRepository(testDB.DB), postgres.NewFoodStarRepository(testDB.DB))
+
+	createFood := func(name string, category *string, calories, protein, sugar float64) *domain.Food {
+		food := &domain.Food{
+			Name:          name,
+			Category:      category,
+			ServingSize:   100,
+			ServingUnit:   "g",
+			Calories:      calories,
+			Protein:       protein,
+			Carbohydrates: sugar,
+			Fat:           2,
+			Sugar:         float64Ptr(sugar),
+		}
+		require.NoError(t, testDB.DB.Create(food).Error)
+		return food
+	}
+
+	dairy, lowerDairy, bakery := "Dairy", "dairy", "Bakery"
+	fruitYogurt := createFood("Fruit Yogurt", &dairy, 100, 4, 13)
+	greekYogurt := createFood("Plain Greek Yogurt", &lowerDairy, 60, 10, 4)
+	skyr := createFood("Skyr", &dairy, 65, 11, 3)
+	createFood("Chocolate Milk", &dairy, 80, 3, 12)
+	createFood("Croissant", &bakery, 400, 8, 10)
+	uncategorized := createFood("Mystery Snack", nil, 200, 5, 10)
+	water := createFood("Sparkling Water", &dairy, 0, 0, 0)
+
+	t.Run("Ranks better foods of the same category", func(t *testing.T) {
+		result, err := foodService.GetFoodAlternatives(ctx, fruitYogurt.ID.String(), 0)
+		require.NoError(t, err)
+		require.NotNil(t, result.Density)
+		assert.InDelta(t, -9.0, result.Density.Score, 0.01)
+
+		require.Len(t, result.Alternatives, 2)
+		assert.Equal(t, skyr.ID, result.Alternatives[0].Food.ID)
+		assert.Equal(t, greekYogurt.ID, result.Alternatives[1].Food.ID)
+		assert.Contains(t, result.Alternatives[0].Reasons, "higher protein per calorie")
+		assert.Contains(t, result.Alternatives[0].Reasons, "less sugar per calorie")
+		assert.Empty(t, result.Note)
+
+		limited, err := foodService.GetFoodAlternatives(ctx, fruitYogurt.ID.String(), 1)
+		require.NoError(t, err)
+		assert.Len(t, limited.Alternatives, 1)
+	})
+
+	t.Run("Best food in its category has no alternatives", func(t *testing.T) {
+		result, err := foodService.GetFoodAlternatives(ctx, skyr.ID.String(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, result.Alternatives)
+		assert.NotEmpty(t, result.Note)
+	})
+
+	t.Run("Foods without a category or calories are handled", func(t *testing.T) {
+		for _, food := range []*domain.Food{uncategorized, water} {
+			result, err := foodService.GetFoodAlternatives(ctx, food.ID.String(), 0)
+			require.NoError(t, err)
+			assert.NotNil(t, result.Alternatives)
+			assert.Empty(t, result.Alternatives)
+			assert.NotEmpty(t, result.Note)
+		}
+	})
+
+	t.Run("Invalid and unknown IDs", func(t *testing.T) {
+		_, err := foodService.GetFoodAlternatives(ctx, "not-a-uuid", 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = foodService.GetFoodAlternatives(ctx, uuid.New().String(), 0)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/cache"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFoodRepository answers searches from an in-memory food list, counting them
+type countingFoodRepository struct {
+	ports.FoodRepository
+	foods    []*domain.Food
+	searches int
+}
+
+func (r *countingFoodRepository) Search(ctx context.Context, query string, filter domain.FoodSearchFilter, limit, offset int) ([]*domain.Food, error) {
+	r.searches++
+	var found []*domain.Food
+	for _, food := range r.foods {
+		if strings.Contains(strings.ToLower(food.Name), query) && len(found) < limit {
+			copied := *food
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (r *countingFoodRepository) Create(ctx context.Context, food *domain.Food) error {
+	food.ID = uuid.New()
+	r.foods = append(r.foods, food)
+	return nil
+}
+
+func (r *countingFoodRepository) Update(ctx context.Context, food *domain.Food) error {
+	for i := range r.foods {
+		if r.foods[i].ID == food.ID {
+			r.foods[i] = food
+		}
+	}
+	return nil
+}
+
+func TestFoodSearchCache(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(ttl time.Duration) (*countingFoodRepository, ports.FoodSearchCache, ports.FoodRepository) {
+		backing := &countingFoodRepository{foods: []*domain.Food{
+			{ID: uuid.New(), Name: "Egg"},
+			{ID: uuid.New(), Name: "White Rice"},
+			{ID: uuid.New(), Name: "Brown Rice"},
+		}}
+		searchCache := cache.NewFoodSearchCache(100, ttl)
+		return backing, searchCache, cache.NewFoodRepository(backing, searchCache)
+	}
+
+	t.Run("Repeated searches are served from the cache", func(t *testing.T) {
+		backing, searchCache, repo := setup(time.Minute)
+
+		first, err := repo.Search(ctx, "rice", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+
+		// Case and spacing do not matter
+		again, err := repo.Search(ctx, "  RICE ", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+		assert.Equal(t, 1, backing.searches)
+
+		// Another limit or filter is another search
+		_, err = repo.Search(ctx, "rice", domain.FoodSearchFilter{}, 1, 0)
+		require.NoError(t, err)
+		_, err = repo.Search(ctx, "rice", domain.FoodSearchFilter{VerifiedOnly: true}, 20, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, backing.searches)
+
+		stats := searchCache.Stats()
+		assert.Equal(t, uint64(1), stats.Hits)
+		assert.Equal(t, uint64(3), stats.Misses)
+		assert.InDelta(t, 0.25, stats.HitRate, 0.001)
+		assert.Equal(t, 3, stats.Entries)
+	})
+
+	t.Run("Cached results cannot be changed by callers", func(t *testing.T) {
+		_, _, repo := setup(time.Minute)
+
+		first, err := repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		first[0].IsStarred = true
+
+		again, err := repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		assert.False(t, again[0].IsStarred)
+	})
+
+	t.Run("Creating a food drops the searches it may match", func(t *testing.T) {
+		backing, _, repo := setup(time.Minute)
+
+		_, err := repo.Search(ctx, "rice", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		_, err = repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, backing.searches)
+
+		require.NoError(t, repo.Create(ctx, &domain.Food{Name: "Jasmine Rice"}))
+
+		found, err := repo.Search(ctx, "rice", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		assert.Len(t, found, 3, "the new food is found")
+		_, err = repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, backing.searches, "searches the food cannot match stay cached")
+	})
+
+	t.Run("Updating a food drops the searches listing it", func(t *testing.T) {
+		backing, _, repo := setup(time.Minute)
+
+		found, err := repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+
+		renamed := *found[0]
+		renamed.Name = "Hard Boiled Ovum"
+		require.NoError(t, repo.Update(ctx, &renamed))
+
+		found, err = repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+		assert.Equal(t, 2, backing.searches)
+	})
+
+	t.Run("Entries expire after the TTL", func(t *testing.T) {
+		backing, _, repo := setup(20 * time.Millisecond)
+
+		_, err := repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+		_, err = repo.Search(ctx, "egg", domain.FoodSearchFilter{}, 20, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, backing.searches)
+	})
+
+	t.Run("The least recently used search is evicted first", func(t *testing.T) {
+		searchCache := cache.NewFoodSearchCache(2, time.Minute)
+		key := func(query string) domain.FoodSearchKey { return domain.FoodSearchKey{Query: query, Limit: 20} }
+
+		searchCache.Set(ctx, key("egg"), nil)
+		searchCache.Set(ctx, key("rice"), nil)
+		_, ok := searchCache.Get(ctx, key("egg"))
+		require.True(t, ok)
+		searchCache.Set(ctx, key("oats"), nil)
+
+		_, ok = searchCache.Get(ctx, key("rice"))
+		assert.False(t, ok)
+		_, ok = searchCache.Get(ctx, key("egg"))
+		assert.True(t, ok)
+		_, ok = searchCache.Get(ctx, key("oats"))
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,272 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalSummary(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	goalService := services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB))
+
+	user := CreateTestUser(t, testDB.DB, "goals@example.com")
+	now := time.Now()
+
+	logWeight := func(value float64, at time.Time) {
+		require.NoError(t, testDB.DB.Create(&domain.Metric{
+			UserID:     user.ID,
+			MetricType: "weight",
+			Value:      value,
+			Unit:       "kg",
+			MeasuredAt: at,
+		}).Error)
+	}
+
+	// Halfway through a 60 day plan from 90 kg to 80 kg, at 86 kg
+	targetDate := now.AddDate(0, 0, 30)
+	require.NoError(t, goalRepo.Create(ctx, &domain.Goal{
+		UserID:      user.ID,
+		GoalType:    "weight_loss",
+		Description: "Get to 80 kg",
+		TargetValue: 80,
+		Unit:        "kg",
+		StartDate:   now.AddDate(0, 0, -30),
+		TargetDate:  &targetDate,
+		Status:      "active",
+	}))
+	logWeight(90, now.AddDate(0, 0, -29))
+	logWeight(88, now.AddDate(0, 0, -15))
+	logWeight(86, now.Add(-time.Hour))
+
+	// Three workouts a week, one done so far
+	require.NoError(t, goalRepo.Create(ctx, &domain.Goal{
+		UserID:      user.ID,
+		GoalType:    "workout_frequency",
+		Description: "Train three times a week",
+		TargetValue: 3,
+		Unit:        "workouts",
+		StartDate:   now.AddDate(0, -1, 0),
+		Status:      "active",
+	}))
+	require.NoError(t, testDB.DB.Create(&domain.Workout{
+		UserID:    user.ID,
+		Name:      "Upper body",
+		StartTime: now.Add(-time.Second),
+	}).Error)
+
+	active := "active"
+	goals, err := goalService.GetGoals(ctx, user.ID.String(), &active)
+	require.NoError(t, err)
+	require.Len(t, goals, 2)
+
+	byType := map[string]*domain.Goal{}
+	for _, goal := range goals {
+		require.NotNil(t, goal.Summary, goal.GoalType)
+		byType[goal.GoalType] = goal
+	}
+
+	t.Run("Weight goal is measured against a linear schedule", func(t *testing.T) {
+		summary := byType["weight_loss"].Summary
+
+		require.NotNil(t, summary.CurrentValue)
+		assert.Equal(t, 86.0, *summary.CurrentValue)
+		require.NotNil(t, summary.Remaining)
+		assert.Equal(t, 6.0, *summary.Remaining)
+		assert.InDelta(t, 40.0, summary.ProgressPercent, 0.1)
+
+		// The schedule expects 85 kg by now
+		require.NotNil(t, summary.ExpectedValue)
+		assert.InDelta(t, 85.0, *summary.ExpectedValue, 0.1)
+		require.NotNil(t, summary.OnTrack)
+		assert.False(t, *summary.OnTrack)
+	})
+
+	t.Run("Reaching the schedule puts the goal on track", func(t *testing.T) {
+		logWeight(84.5, now.Add(-time.Minute))
+
+		goals, err := goalService.GetGoals(ctx, user.ID.String(), &active)
+		require.NoError(t, err)
+		for _, goal := range goals {
+			if goal.GoalType == "weight_loss" {
+				require.NotNil(t, goal.Summary.OnTrack)
+				assert.True(t, *goal.Summary.OnTrack)
+				assert.Equal(t, 4.5, *goal.Summary.Remaining)
+			}
+		}
+	})
+
+	t.Run("Workout frequency counts this week against the target", func(t *testing.T) {
+		summary := byType["workout_frequency"].Summary
+
+		require.NotNil(t, summary.WeekCount)
+		assert.Equal(t, 1, *summary.WeekCount)
+		require.NotNil(t, summary.WeeklyTarget)
+		assert.Equal(t, 3, *summary.WeeklyTarget)
+		assert.Equal(t, 2.0, *summary.Remaining)
+		assert.InDelta(t, 33.3, summary.ProgressPercent, 0.1)
+		assert.NotNil(t, summary.OnTrack)
+	})
+
+	t.Run("Rejects an unknown status filter", func(t *testing.T) {
+		status := "in_progress"
+		_, err := goalService.GetGoals(ctx, user.ID.String(), &status)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestCheckMacroSplit(t *testing.T) {
+	t.Run("Consistent split", func(t *testing.T) {
+		// 150 x 4 + 200 x 4 + 65 x 9 = 1985 kcal
+		assert.Empty(t, domain.CheckMacroSplit(domain.MacroTargets{Calories: 2000, Protein: 150, Carbohydrates: 200, Fat: 65}))
+	})
+
+	t.Run("Full split far from the calorie goal", func(t *testing.T) {
+		// 200 x 4 + 300 x 4 + 100 x 9 = 2900 kcal
+		warning := domain.CheckMacroSplit(domain.MacroTargets{Calories: 2000, Protein: 200, Carbohydrates: 300, Fat: 100})
+		assert.Equal(t, "Your protein, carbohydrate and fat goals add up to 2900 kcal, 45% more than your 2000 kcal calorie goal.", warning)
+
+		warning = domain.CheckMacroSplit(domain.MacroTargets{Calories: 2500, Protein: 100, Carbohydrates: 150, Fat: 50})
+		assert.Contains(t, warning, "less than your 2500 kcal calorie goal")
+	})
+
+	t.Run("Partial split only warns when it exceeds the calorie goal", func(t *testing.T) {
+		assert.Empty(t, domain.CheckMacroSplit(domain.MacroTargets{Calories: 2000, Protein: 150}))
+
+		warning := domain.CheckMacroSplit(domain.MacroTargets{Calories: 1800, Protein: 250, Fat: 120})
+		assert.Equal(t, "Your protein and fat goals alone add up to 2080 kcal, more than your 1800 kcal calorie goal.", warning)
+	})
+
+	t.Run("Nothing to compare without a calorie goal", func(t *testing.T) {
+		assert.Empty(t, domain.CheckMacroSplit(domain.MacroTargets{Protein: 400, Carbohydrates: 400, Fat: 200}))
+	})
+}
+
+func TestEstimateGoalETA(t *testing.T) {
+	start := time.Date(2025, 10, 1, 7, 0, 0, 0, time.UTC)
+
+	// weighIns returns one weigh-in every three days, changing by step each time
+	weighIns := func(count int, first, step float64) []domain.TrendPoint {
+		points := make([]domain.TrendPoint, count)
+		for i := range points {
+			points[i] = domain.TrendPoint{At: start.AddDate(0, 0, 3*i), Value: first + step*float64(i)}
+		}
+		return points
+	}
+
+	t.Run("Projects a date from the trend", func(t *testing.T) {
+		// Losing 0.3 kg every 3 days is 0.7 kg a week; 8 weigh-ins over 21 days
+		points := weighIns(8, 82, -0.3)
+		eta := domain.EstimateGoalETA("weight_loss", 75, points)
+
+		assert.Equal(t, domain.GoalETAOnTrack, eta.Status)
+		assert.InDelta(t, 79.9, eta.Current, 1e-9)
+		assert.Equal(t, -0.7, eta.RatePerWeek)
+		assert.Equal(t, 21, eta.SpanDays)
+		assert.Equal(t, domain.GoalETAConfidenceHigh, eta.Confidence)
+
+		// 4.9 kg to go at 0.7 kg a week from the last weigh-in on October 22
+		require.NotNil(t, eta.WeeksRemaining)
+		assert.Equal(t, 7.0, *eta.WeeksRemaining)
+		require.NotNil(t, eta.EstimatedDate)
+		assert.Equal(t, "2025-12-10", eta.EstimatedDate.Format("2006-01-02"))
+	})
+
+	t.Run("Order of the weigh-ins does not matter", func(t *testing.T) {
+		points := weighIns(8, 82, -0.3)
+		reversed := make([]domain.TrendPoint, len(points))
+		for i, p := range points {
+			reversed[len(points)-1-i] = p
+		}
+		assert.Equal(t, domain.EstimateGoalETA("weight_loss", 75, points), domain.EstimateGoalETA("weight_loss", 75, reversed))
+	})
+
+	t.Run("Flags a trend moving the wrong way", func(t *testing.T) {
+		eta := domain.EstimateGoalETA("weight_loss", 75, weighIns(4, 80, 0.2))
+		assert.Equal(t, domain.GoalETAWrongDirection, eta.Status)
+		assert.Nil(t, eta.EstimatedDate)
+		assert.Equal(t, domain.GoalETAConfidenceLow, eta.Confidence)
+	})
+
+	t.Run("Flags a flat trend", func(t *testing.T) {
+		eta := domain.EstimateGoalETA("weight_gain", 90, weighIns(5, 80, 0))
+		assert.Equal(t, domain.GoalETAFlat, eta.Status)
+		assert.Equal(t, domain.GoalETAConfidenceMedium, eta.Confidence)
+
+		// 10 kg at 0.05 kg a week is over MaxGoalETAWeeks away
+		eta = domain.EstimateGoalETA("weight_gain", 90, weighIns(5, 80, 0.02))
+		assert.Equal(t, domain.GoalETAFlat, eta.Status)
+		assert.Nil(t, eta.EstimatedDate)
+	})
+
+	t.Run("Reached and insufficient data", func(t *testing.T) {
+		eta := domain.EstimateGoalETA("weight_loss", 75, weighIns(3, 76, -0.6))
+		assert.Equal(t, domain.GoalETAReached, eta.Status)
+
+		eta = domain.EstimateGoalETA("weight_loss", 75, weighIns(1, 80, 0))
+		assert.Equal(t, domain.GoalETAInsufficientData, eta.Status)
+		assert.Equal(t, 1, eta.Measurements)
+
+		eta = domain.EstimateGoalETA("weight_loss", 75, nil)
+		assert.Equal(t, domain.GoalETAInsufficientData, eta.Status)
+	})
+}
+
+func TestGoalMacroSplitWarnings(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	goalService := services.NewGoalService(postgres.NewGoalRepository(testDB.DB), postgres.NewMetricRepository(testDB.DB), postgres.NewWorkoutRepository(testDB.DB))
+	user := CreateTestUser(t, testDB.DB, "goal_macros@example.com")
+
+	create := func(goalType string, target float64, unit string) *domain.Goal {
+		goal, err := goalService.CreateGoal(ctx, user.ID.String(), &domain.Goal{
+			GoalType:    goalType,
+			Description: goalType + " per day",
+			TargetValue: target,
+			Unit:        unit,
+		})
+		require.NoError(t, err)
+		return goal
+	}
+
+	calories := create("calories", 2000, "kcal")
+	assert.Empty(t, calories.Warnings)
+	assert.Empty(t, create("protein", 200, "g").Warnings)
+	assert.Empty(t, create("carbohydrates", 200, "g").Warnings)
+
+	t.Run("Inconsistent macro goal is saved with a warning", func(t *testing.T) {
+		fat := create("fat", 120, "g")
+		require.Len(t, fat.Warnings, 1)
+		assert.Contains(t, fat.Warnings[0], "add up to 2680 kcal")
+
+		goals, err := goalService.GetGoals(ctx, user.ID.String(), nil)
+		require.NoError(t, err)
+		assert.Len(t, goals, 4)
+	})
+
+	t.Run("Updating the calorie goal re-checks the split", func(t *testing.T) {
+		updated, err := goalService.UpdateGoal(ctx, calories.ID.String(), map[string]interface{}{"target_value": 2700.0})
+		require.NoError(t, err)
+		assert.Empty(t, updated.Warnings)
+	})
+
+	t.Run("Other goal types are not checked", func(t *testing.T) {
+		goal := create("steps", 10000, "steps")
+		assert.Nil(t, goal.Warnings)
+	})
+}
@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	apperrors "fitness-tracker/internal/pkg/errors"
+	"fitness-tracker/internal/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseID(t *testing.T) {
+	t.Run("Valid IDs parse", func(t *testing.T) {
+		want := uuid.New()
+		id, err := utils.ParseID(want.String(), "id")
+		require.NoError(t, err)
+		assert.Equal(t, want, id)
+
+		id, err = utils.ParseID(" "+want.String()+" ", "id")
+		require.NoError(t, err)
+		assert.Equal(t, want, id)
+	})
+
+	t.Run("Malformed IDs are bad requests, not server errors", func(t *testing.T) {
+		for _, value := range []string{"", "   ", "42", "not-a-uuid", "00000000-0000-0000-0000-000000000000", uuid.NewString() + "x"} {
+			_, err := utils.ParseID(value, "workout_id")
+			require.Error(t, err, "value %q", value)
+
+			appErr, ok := apperrors.GetAppError(err)
+			require.True(t, ok, "value %q", value)
+			assert.Equal(t, http.StatusBadRequest, appErr.GetHTTPStatus(), "value %q", value)
+			assert.Equal(t, "workout_id", appErr.Details["field"])
+			assert.Contains(t, appErr.Message, "workout_id")
+		}
+	})
+
+	t.Run("Lists fail on the first malformed ID", func(t *testing.T) {
+		first, second := uuid.New(), uuid.New()
+		ids, err := utils.ParseIDs([]string{first.String(), second.String()}, "workout_exercise_ids")
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first, second}, ids)
+
+		_, err = utils.ParseIDs([]string{first.String(), "not-a-uuid"}, "workout_exercise_ids")
+		appErr, ok := apperrors.GetAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, appErr.GetHTTPStatus())
+		assert.Equal(t, "not-a-uuid", appErr.Details["value"])
+	})
+}
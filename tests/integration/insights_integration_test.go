@@ -0,0 +1,418 @@
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateStreak(t *testing.T) {
+	today := "2025-11-19"
+
+	t.Run("No days", func(t *testing.T) {
+		assert.Equal(t, domain.Streak{}, domain.CalculateStreak(nil, today))
+	})
+
+	t.Run("Run through yesterday is still current", func(t *testing.T) {
+		streak := domain.CalculateStreak([]string{"2025-11-10", "2025-11-11", "2025-11-16", "2025-11-17", "2025-11-18"}, today)
+		assert.Equal(t, 3, streak.Current)
+		assert.Equal(t, 3, streak.Longest)
+		require.NotNil(t, streak.LastActive)
+		assert.Equal(t, "2025-11-18", *streak.LastActive)
+	})
+
+	t.Run("A missed day breaks the run", func(t *testing.T) {
+		streak := domain.CalculateStreak([]string{"2025-11-01", "2025-11-02", "2025-11-03", "2025-11-04", "2025-11-17"}, today)
+		assert.Equal(t, 0, streak.Current)
+		assert.Equal(t, 4, streak.Longest)
+	})
+
+	t.Run("Runs cross month ends", func(t *testing.T) {
+		streak := domain.CalculateStreak([]string{"2025-10-30", "2025-10-31", "2025-11-01"}, "2025-11-01")
+		assert.Equal(t, 3, streak.Current)
+	})
+
+	t.Run("First day a run reaches a length", func(t *testing.T) {
+		days := []string{"2025-11-01", "2025-11-02", "2025-11-05", "2025-11-06", "2025-11-07"}
+		assert.Equal(t, "2025-11-07", domain.StreakReachedOn(days, 3))
+		assert.Equal(t, "", domain.StreakReachedOn(days, 4))
+	})
+}
+
+func TestStreaksAndAchievements(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	achievementRepo := postgres.NewAchievementRepository(testDB.DB)
+	insightsService := services.NewInsightsService(
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewMealRepository(testDB.DB),
+		workoutRepo,
+		postgres.NewMetricRepository(testDB.DB),
+		achievementRepo,
+	)
+
+	user := CreateTestUser(t, testDB.DB, "streaks@example.com")
+	require.NoError(t, testDB.DB.Model(user).Update("timezone", "America/New_York").Error)
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	logMeal := func(at time.Time) {
+		meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+		require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", at).Error)
+	}
+
+	// Meals on each of the last 7 days, two on one day, and a late dinner the day
+	// before that which is already the next day in UTC
+	for day := 0; day < 7; day++ {
+		logMeal(today.AddDate(0, 0, -day).Add(12 * time.Hour))
+	}
+	logMeal(today.Add(8 * time.Hour))
+	logMeal(today.AddDate(0, 0, -7).Add(23*time.Hour + 30*time.Minute))
+
+	squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+	logWorkout := func(start time.Time, finished bool, weight float64) *domain.Workout {
+		workout := &domain.Workout{UserID: user.ID, Name: "Legs", StartTime: start}
+		if finished {
+			end := start.Add(time.Hour)
+			workout.EndTime = &end
+		}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+
+		workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: squat.ID, OrderIndex: 1}
+		require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+		require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+			WorkoutExerciseID: workoutExercise.ID,
+			SetNumber:         1,
+			Reps:              intPtr(5),
+			Weight:            float64Ptr(weight),
+		}).Error)
+		return workout
+	}
+
+	first := logWorkout(today.AddDate(0, 0, -4).Add(18*time.Hour), true, 100)
+	logWorkout(today.AddDate(0, 0, -3).Add(18*time.Hour), true, 100) // matching the best is not a record
+	record := logWorkout(today.AddDate(0, 0, -2).Add(18*time.Hour), true, 105)
+	logWorkout(today.AddDate(0, 0, -1).Add(18*time.Hour), false, 90) // never finished
+
+	t.Run("Streaks in the user's timezone", func(t *testing.T) {
+		summary, err := insightsService.GetStreaks(ctx, user.ID.String())
+		require.NoError(t, err)
+
+		assert.Equal(t, "America/New_York", summary.Timezone)
+		assert.Equal(t, today.Format("2006-01-02"), summary.Today)
+
+		assert.Equal(t, 8, summary.Meals.Current)
+		assert.Equal(t, 8, summary.Meals.Longest)
+
+		// Finished workouts on days -4 to -2; yesterday's is unfinished, so the run is over
+		assert.Equal(t, 0, summary.Workouts.Current)
+		assert.Equal(t, 3, summary.Workouts.Longest)
+		require.NotNil(t, summary.Workouts.LastActive)
+		assert.Equal(t, today.AddDate(0, 0, -2).Format("2006-01-02"), *summary.Workouts.LastActive)
+	})
+
+	t.Run("Achievements are recorded once, dated when reached", func(t *testing.T) {
+		summary, err := insightsService.GetStreaks(ctx, user.ID.String())
+		require.NoError(t, err)
+
+		earned := make(map[string]time.Time)
+		for _, achievement := range summary.Achievements {
+			earned[achievement.Code] = achievement.EarnedAt
+		}
+		require.Len(t, earned, 3)
+
+		assert.WithinDuration(t, *first.EndTime, earned[domain.AchievementFirstWorkout], time.Second)
+		assert.WithinDuration(t, record.StartTime, earned[domain.AchievementFirstPR], time.Second)
+		assert.WithinDuration(t, today.AddDate(0, 0, -1), earned[domain.AchievementMealStreak7], time.Second)
+
+		stored, err := achievementRepo.ListByUser(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, stored, 3)
+	})
+
+	t.Run("New user has no streaks", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "no_streaks@example.com")
+
+		summary, err := insightsService.GetStreaks(ctx, other.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "UTC", summary.Timezone)
+		assert.Equal(t, domain.Streak{}, summary.Meals)
+		assert.Empty(t, summary.Achievements)
+	})
+}
+
+func TestActivityCalendarDays(t *testing.T) {
+	today := time.Date(2025, 1, 6, 15, 0, 0, 0, time.UTC)
+
+	t.Run("Days are flagged up to today", func(t *testing.T) {
+		calendar := domain.NewActivityCalendar(2025, "UTC", today,
+			[]string{"2024-12-31", "2025-01-01", "2025-01-02", "2025-01-04", "2025-01-05", "2025-01-06", "2025-01-07"},
+			[]string{"2025-01-02", "2025-01-06"},
+			[]string{"2025-01-04", "2025-01-06"},
+		)
+
+		assert.Equal(t, "2025-01-06", calendar.Today)
+		assert.Equal(t, 3, calendar.StartWeekday) // a Wednesday
+		assert.Equal(t, 365, calendar.DaysInYear)
+		assert.Equal(t, []int{1, 3, 0, 5, 1, 7}, calendar.Days)
+		assert.Equal(t, domain.ActivityCalendarTotals{MealDays: 5, WorkoutDays: 2, WeightDays: 2, ActiveDays: 5}, calendar.Totals)
+	})
+
+	t.Run("A past year has every day", func(t *testing.T) {
+		calendar := domain.NewActivityCalendar(2024, "UTC", today, []string{"2024-02-29", "2024-12-31"}, nil, nil)
+		assert.Equal(t, 366, calendar.DaysInYear)
+		require.Len(t, calendar.Days, 366)
+		assert.Equal(t, domain.CalendarMealLogged, calendar.Days[59])
+		assert.Equal(t, domain.CalendarMealLogged, calendar.Days[365])
+		assert.Equal(t, 2, calendar.Totals.ActiveDays)
+	})
+
+	t.Run("A future year has no days", func(t *testing.T) {
+		calendar := domain.NewActivityCalendar(2026, "UTC", today, []string{"2026-01-01"}, nil, nil)
+		assert.Empty(t, calendar.Days)
+		assert.NotNil(t, calendar.Days)
+		assert.Zero(t, calendar.Totals)
+	})
+
+	t.Run("Year parsing", func(t *testing.T) {
+		year, err := domain.ParseCalendarYear("", today)
+		require.NoError(t, err)
+		assert.Equal(t, 2025, year)
+
+		year, err = domain.ParseCalendarYear("2023", today)
+		require.NoError(t, err)
+		assert.Equal(t, 2023, year)
+
+		for _, invalid := range []string{"last", "1969", "10000", "2025.5"} {
+			_, err := domain.ParseCalendarYear(invalid, today)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput, invalid)
+		}
+	})
+}
+
+func TestActivityCalendar(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	insightsService := services.NewInsightsService(
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		postgres.NewAchievementRepository(testDB.DB),
+	)
+
+	user := CreateTestUser(t, testDB.DB, "activity_calendar@example.com")
+	require.NoError(t, testDB.DB.Model(user).Update("timezone", "Asia/Tokyo").Error)
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Last year, so every day is in the past whenever the test runs
+	year := time.Now().In(loc).Year() - 1
+	day := func(month time.Month, date, hour int) time.Time {
+		return time.Date(year, month, date, hour, 0, 0, 0, loc)
+	}
+	index := func(month time.Month, date int) int {
+		return day(month, date, 0).YearDay() - 1
+	}
+
+	// A late meal on March 3 UTC is already March 4 in Tokyo
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "dinner")
+	require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day(time.March, 4, 1)).Error)
+	deleted := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Model(deleted).Update("consumed_at", day(time.March, 10, 12)).Error)
+	require.NoError(t, postgres.NewMealRepository(testDB.DB).Delete(ctx, deleted.ID))
+
+	finished := day(time.March, 4, 19)
+	end := finished.Add(time.Hour)
+	require.NoError(t, testDB.DB.Create(&domain.Workout{UserID: user.ID, Name: "Push", StartTime: finished, EndTime: &end}).Error)
+	require.NoError(t, testDB.DB.Create(&domain.Workout{UserID: user.ID, Name: "Pull", StartTime: day(time.March, 5, 19)}).Error)
+
+	for _, metric := range []*domain.Metric{
+		{UserID: user.ID, MetricType: "weight", Value: 80, Unit: "kg", MeasuredAt: day(time.July, 1, 7)},
+		{UserID: user.ID, MetricType: "body_fat", Value: 18, Unit: "%", MeasuredAt: day(time.July, 2, 7)},
+	} {
+		require.NoError(t, testDB.DB.Create(metric).Error)
+	}
+
+	t.Run("Days are flagged in the user's timezone", func(t *testing.T) {
+		calendar, err := insightsService.GetActivityCalendar(ctx, user.ID.String(), strconv.Itoa(year))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Asia/Tokyo", calendar.Timezone)
+		assert.Equal(t, calendar.DaysInYear, len(calendar.Days))
+		assert.Equal(t, domain.CalendarMealLogged|domain.CalendarWorkoutDone, calendar.Days[index(time.March, 4)])
+		assert.Zero(t, calendar.Days[index(time.March, 3)])
+		assert.Zero(t, calendar.Days[index(time.March, 5)], "unfinished workouts do not count")
+		assert.Zero(t, calendar.Days[index(time.March, 10)], "deleted meals do not count")
+		assert.Equal(t, domain.CalendarWeightLogged, calendar.Days[index(time.July, 1)])
+		assert.Zero(t, calendar.Days[index(time.July, 2)], "only weight measurements count")
+		assert.Equal(t, 2, calendar.Totals.ActiveDays)
+	})
+
+	t.Run("The current year ends today", func(t *testing.T) {
+		calendar, err := insightsService.GetActivityCalendar(ctx, user.ID.String(), "")
+		require.NoError(t, err)
+
+		today := time.Now().In(loc)
+		assert.Equal(t, year+1, calendar.Year)
+		assert.Equal(t, today.Format("2006-01-02"), calendar.Today)
+		assert.Len(t, calendar.Days, today.YearDay())
+	})
+
+	t.Run("Malformed years are rejected", func(t *testing.T) {
+		_, err := insightsService.GetActivityCalendar(ctx, user.ID.String(), "next")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestParseISOWeek(t *testing.T) {
+	monday, err := domain.ParseISOWeek("2025-W01", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), monday, "week 1 can start in the previous year")
+
+	monday, err = domain.ParseISOWeek("2026-W53", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 12, 28, 0, 0, 0, 0, time.UTC), monday)
+
+	assert.Equal(t, "2025-W47", domain.ISOWeek(time.Date(2025, 11, 19, 12, 0, 0, 0, time.UTC)))
+
+	for _, week := range []string{"2025-W53", "2025-W00", "2025-47", "2025-W7", "last week"} {
+		_, err := domain.ParseISOWeek(week, time.UTC)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, week)
+	}
+}
+
+func TestWeeklyRecap(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		workoutRepo,
+		userRepo,
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+	recapService := services.NewWeeklyRecapService(userRepo, workoutRepo, metricRepo, summaryService, nil, "", nil)
+
+	user := CreateTestUser(t, testDB.DB, "weekly_recap@example.com")
+	squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+
+	now := time.Now().UTC()
+	thisMonday := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	lastMonday := thisMonday.AddDate(0, 0, -7)
+	tuesday := lastMonday.AddDate(0, 0, 1).Add(18 * time.Hour)
+	finishedAt := tuesday.Add(time.Hour)
+
+	require.NoError(t, testDB.DB.Create(&domain.Workout{
+		UserID:    user.ID,
+		Name:      "Legs",
+		StartTime: tuesday,
+		EndTime:   &finishedAt,
+		Exercises: []domain.WorkoutExercise{{
+			ExerciseID: squat.ID,
+			OrderIndex: 1,
+			Sets: []domain.WorkoutSet{
+				{SetNumber: 1, Reps: intPtr(5), Weight: float64Ptr(120), SetType: "warmup"},
+				{SetNumber: 2, Reps: intPtr(5), Weight: float64Ptr(100)},
+				{SetNumber: 3, Reps: intPtr(8), Weight: float64Ptr(90)},
+			},
+		}},
+	}).Error)
+	// Unfinished workouts do not count
+	require.NoError(t, testDB.DB.Create(&domain.Workout{
+		UserID: user.ID, Name: "Abandoned", StartTime: tuesday.Add(24 * time.Hour),
+	}).Error)
+
+	for _, weighIn := range []struct {
+		at    time.Time
+		value float64
+	}{
+		{lastMonday.Add(8 * time.Hour), 80},
+		{lastMonday.AddDate(0, 0, 5), 79.4},
+		{thisMonday.Add(8 * time.Hour), 70}, // this week
+	} {
+		require.NoError(t, testDB.DB.Create(&domain.Metric{
+			UserID: user.ID, MetricType: "weight", Value: weighIn.value, Unit: "kg", MeasuredAt: weighIn.at,
+		}).Error)
+	}
+
+	t.Run("Last week by default", func(t *testing.T) {
+		recap, err := recapService.GetWeeklyRecap(ctx, user.ID.String(), "")
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.ISOWeek(lastMonday), recap.Week)
+		assert.Equal(t, lastMonday.Format("2006-01-02"), recap.From)
+		assert.Equal(t, lastMonday.AddDate(0, 0, 6).Format("2006-01-02"), recap.To)
+		assert.Equal(t, 1, recap.Workouts)
+		assert.InDelta(t, 1220, recap.TotalVolume, 0.01, "warmups are not counted")
+		require.NotNil(t, recap.WeightChange)
+		assert.InDelta(t, -0.6, *recap.WeightChange, 0.001)
+		assert.Equal(t, 0, recap.DaysLogged)
+
+		// 100 kg × 5 estimates higher than 90 kg × 8; the heavier warmup is ignored
+		require.NotNil(t, recap.BestLift)
+		assert.Equal(t, "Squat", recap.BestLift.ExerciseName)
+		assert.Equal(t, 100.0, recap.BestLift.Weight)
+		assert.Equal(t, 5, recap.BestLift.Reps)
+		assert.InDelta(t, 112.5, recap.BestLift.EstimatedOneRepMax, 0.01)
+
+		assert.Equal(t, domain.RecapEncouragementSourceTemplate, recap.EncouragementSource)
+		assert.Equal(t, recap.TemplateEncouragement(), recap.Encouragement)
+	})
+
+	t.Run("Recaps are cached per week", func(t *testing.T) {
+		first, err := recapService.GetWeeklyRecap(ctx, user.ID.String(), domain.ISOWeek(lastMonday))
+		require.NoError(t, err)
+
+		laterEnd := finishedAt.Add(24 * time.Hour)
+		require.NoError(t, testDB.DB.Create(&domain.Workout{
+			UserID: user.ID, Name: "Backdated", StartTime: finishedAt.Add(23 * time.Hour), EndTime: &laterEnd,
+		}).Error)
+
+		again, err := recapService.GetWeeklyRecap(ctx, user.ID.String(), domain.ISOWeek(lastMonday))
+		require.NoError(t, err)
+		assert.Equal(t, first.Workouts, again.Workouts)
+		assert.Equal(t, first.GeneratedAt, again.GeneratedAt)
+
+		older, err := recapService.GetWeeklyRecap(ctx, user.ID.String(), domain.ISOWeek(lastMonday.AddDate(0, 0, -7)))
+		require.NoError(t, err)
+		assert.Equal(t, 0, older.Workouts)
+		assert.Nil(t, older.BestLift)
+	})
+
+	t.Run("Unfinished and malformed weeks are rejected", func(t *testing.T) {
+		_, err := recapService.GetWeeklyRecap(ctx, user.ID.String(), domain.ISOWeek(now))
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = recapService.GetWeeklyRecap(ctx, user.ID.String(), "2025-47")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
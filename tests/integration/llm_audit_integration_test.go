@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditedOpenRouter replies with usage and a tool call, or with an API error when failing is set
+func fakeAuditedOpenRouter(t *testing.T, content string, failing bool, lastRequest *map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(lastRequest))
+
+		w.Header().Set("Content-Type", "application/json")
+		if failing {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{"message": "quota exceeded for jane@example.com", "type": "rate_limit", "code": "429"},
+			})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":    "gen-1",
+			"model": "test-model",
+			"choices": []map[string]interface{}{{
+				"index": 0,
+				"message": map[string]interface{}{
+					"role":    "assistant",
+					"content": content,
+					"tool_calls": []map[string]interface{}{{
+						"id":       "call_1",
+						"type":     "function",
+						"function": map[string]string{"name": "search_foods", "arguments": `{"query":"eggs"}`},
+					}},
+				},
+				"finish_reason": "tool_calls",
+			}},
+			"usage": map[string]interface{}{"prompt_tokens": 120, "completion_tokens": 30, "total_tokens": 150, "cost": 0.00042},
+		})
+	}))
+}
+
+func TestLLMAuditLog(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "audit@example.com")
+	auditRepo := postgres.NewLLMAuditRepository(testDB.DB)
+
+	prompt := "Log 2 eggs. Reach me at jane@example.com or +1 555 123 4567 on 2025-11-19"
+	messages := []external.Message{external.NewTextMessage("user", prompt)}
+	userFilter := domain.LLMAuditFilter{UserID: &user.ID}
+
+	t.Run("Records a redacted call with usage and tools", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeAuditedOpenRouter(t, "Done", false, &lastRequest)
+		defer server.Close()
+
+		auditService := services.NewLLMAuditService(auditRepo, true, true)
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL).WithAuditor(auditService)
+
+		callCtx := external.WithAuditContext(ctx, external.AuditOperationMealParse, user.ID)
+		_, err := client.Chat(callCtx, messages, "test-model")
+		require.NoError(t, err)
+
+		// Usage accounting is requested so the cost is reported
+		assert.Equal(t, map[string]interface{}{"include": true}, lastRequest["usage"])
+
+		entries, err := auditService.ListEntries(ctx, userFilter, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		entry := entries[0]
+		assert.Equal(t, external.AuditOperationMealParse, entry.Operation)
+		assert.Equal(t, "test-model", entry.Model)
+		assert.Equal(t, "success", entry.Status)
+		assert.Len(t, entry.PromptHash, 64)
+		assert.Equal(t, 120, entry.PromptTokens)
+		assert.Equal(t, 30, entry.CompletionTokens)
+		assert.Equal(t, 150, entry.TotalTokens)
+		require.NotNil(t, entry.CostUSD)
+		assert.InDelta(t, 0.00042, *entry.CostUSD, 1e-9)
+		require.NotNil(t, entry.ToolsUsed)
+		assert.JSONEq(t, `["search_foods"]`, *entry.ToolsUsed)
+
+		assert.NotContains(t, entry.Request, "jane@example.com")
+		assert.NotContains(t, entry.Request, "555 123 4567")
+		assert.Contains(t, entry.Request, "[REDACTED_EMAIL]")
+		assert.Contains(t, entry.Request, "[REDACTED_PHONE]")
+		assert.Contains(t, entry.Request, "2025-11-19") // dates are not phone numbers
+		require.NotNil(t, entry.Response)
+		assert.Contains(t, *entry.Response, "gen-1")
+	})
+
+	t.Run("Records failed calls", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeAuditedOpenRouter(t, "", true, &lastRequest)
+		defer server.Close()
+
+		auditService := services.NewLLMAuditService(auditRepo, true, true)
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL).WithAuditor(auditService)
+
+		callCtx := external.WithAuditContext(ctx, external.AuditOperationAgent, user.ID)
+		_, err := client.Chat(callCtx, messages, "test-model")
+		require.Error(t, err)
+
+		entries, err := auditService.ListEntries(ctx, domain.LLMAuditFilter{UserID: &user.ID, Status: "error"}, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, external.AuditOperationAgent, entries[0].Operation)
+		require.NotNil(t, entries[0].Error)
+		assert.Contains(t, *entries[0].Error, "quota exceeded")
+		assert.NotContains(t, *entries[0].Error, "jane@example.com")
+	})
+
+	t.Run("Vision calls keep the user and drop image data", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeAuditedOpenRouter(t, `{"items": [{"name": "Egg", "quantity": 2, "unit": "piece"}]}`, false, &lastRequest)
+		defer server.Close()
+
+		auditService := services.NewLLMAuditService(auditRepo, true, true)
+		vision := external.NewVisionClientWithBaseURL("test-key", server.URL).WithAuditor(auditService)
+
+		image := external.ImageDataURL("image/png", []byte("not really a png"))
+		callCtx := external.WithAuditContext(ctx, external.AuditOperationMealParse, user.ID)
+		_, err := vision.AnalyzeFoodPhoto(callCtx, image)
+		require.NoError(t, err)
+
+		entries, err := auditService.ListEntries(ctx, domain.LLMAuditFilter{UserID: &user.ID, Operation: external.AuditOperationVision}, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Contains(t, entries[0].Request, "data:image/png;base64,[REDACTED]")
+		assert.False(t, strings.Contains(entries[0].Request, strings.TrimPrefix(image, "data:image/png;base64,")))
+	})
+
+	t.Run("Disabled audit stores nothing", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "unaudited@example.com")
+
+		var lastRequest map[string]interface{}
+		server := fakeAuditedOpenRouter(t, "Done", false, &lastRequest)
+		defer server.Close()
+
+		auditService := services.NewLLMAuditService(auditRepo, false, true)
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL).WithAuditor(auditService)
+
+		_, err := client.Chat(external.WithAuditContext(ctx, external.AuditOperationAgent, other.ID), messages, "test-model")
+		require.NoError(t, err)
+
+		entries, err := auditService.ListEntries(ctx, domain.LLMAuditFilter{UserID: &other.ID}, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Rejects an unknown status filter", func(t *testing.T) {
+		auditService := services.NewLLMAuditService(auditRepo, true, true)
+
+		_, err := auditService.ListEntries(ctx, domain.LLMAuditFilter{Status: "pending"}, 0, 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareMeal(t *testing.T) {
+	lunches := func(calories ...float64) []*domain.Meal {
+		meals := make([]*domain.Meal, 0, len(calories))
+		for _, c := range calories {
+			meals = append(meals, &domain.Meal{MealType: "lunch", TotalCalories: c, TotalProtein: 30})
+		}
+		return meals
+	}
+
+	t.Run("Heavier than the typical lunch", func(t *testing.T) {
+		history := append(lunches(500, 550, 600, 650, 700), &domain.Meal{MealType: "dinner", TotalCalories: 2000})
+
+		comparison := domain.CompareMeal("Lunch", domain.MacroTargets{Calories: 780, Protein: 30}, history)
+		require.True(t, comparison.EnoughHistory)
+		assert.Equal(t, "lunch", comparison.MealType)
+		assert.Equal(t, 5, comparison.HistoryCount, "meals of other types are ignored")
+		assert.InDelta(t, 600, comparison.Typical.Calories, 0.01)
+
+		require.NotNil(t, comparison.CaloriesDeltaPercent)
+		assert.InDelta(t, 30, *comparison.CaloriesDeltaPercent, 0.01)
+		require.NotNil(t, comparison.ProteinDeltaPercent)
+		assert.InDelta(t, 0, *comparison.ProteinDeltaPercent, 0.01)
+		assert.Nil(t, comparison.FatDeltaPercent, "no fat in past lunches to compare with")
+
+		require.NotNil(t, comparison.CaloriesPercentile)
+		assert.InDelta(t, 100, *comparison.CaloriesPercentile, 0.01)
+		assert.Equal(t, "30% more calories than your typical lunch.", comparison.Summary)
+	})
+
+	t.Run("Lighter and about typical", func(t *testing.T) {
+		history := lunches(500, 550, 600, 650, 700)
+
+		lighter := domain.CompareMeal("lunch", domain.MacroTargets{Calories: 450}, history)
+		assert.Equal(t, "25% fewer calories than your typical lunch.", lighter.Summary)
+		assert.InDelta(t, 0, *lighter.CaloriesPercentile, 0.01)
+
+		typical := domain.CompareMeal("lunch", domain.MacroTargets{Calories: 620}, history)
+		assert.Equal(t, "About as many calories as your typical lunch.", typical.Summary)
+		assert.InDelta(t, 60, *typical.CaloriesPercentile, 0.01)
+	})
+
+	t.Run("Custom labels compare within their group", func(t *testing.T) {
+		comparison := domain.CompareMeal("work lunch", domain.MacroTargets{Calories: 600}, lunches(500, 550, 600, 650, 700))
+		assert.Equal(t, "lunch", comparison.MealType)
+		assert.True(t, comparison.EnoughHistory)
+	})
+
+	t.Run("Insufficient history gives no figures", func(t *testing.T) {
+		comparison := domain.CompareMeal("lunch", domain.MacroTargets{Calories: 900}, lunches(500, 600))
+		assert.False(t, comparison.EnoughHistory)
+		assert.Equal(t, 2, comparison.HistoryCount)
+		assert.Nil(t, comparison.CaloriesDeltaPercent)
+		assert.Nil(t, comparison.CaloriesPercentile)
+		assert.Zero(t, comparison.Typical)
+		assert.Equal(t, "Log 3 more lunch meals to see how this one compares to your typical lunch.", comparison.Summary)
+	})
+}
+
+func TestMealComparisonService(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "meal_comparison@example.com")
+	other := CreateTestUser(t, testDB.DB, "meal_comparison_other@example.com")
+	comparisonService := services.NewMealComparisonService(postgres.NewMealRepository(testDB.DB))
+
+	logMeal := func(userID uuid.UUID, mealType string, calories float64, consumedAt time.Time) *domain.Meal {
+		meal := &domain.Meal{
+			UserID:        userID,
+			Name:          "Meal",
+			MealType:      mealType,
+			ConsumedAt:    consumedAt,
+			TotalCalories: calories,
+		}
+		require.NoError(t, testDB.DB.Create(meal).Error)
+		return meal
+	}
+
+	now := time.Now()
+	for i, calories := range []float64{500, 550, 600, 650, 700} {
+		logMeal(user.ID, "lunch", calories, now.AddDate(0, 0, -(i+1)))
+	}
+	logMeal(other.ID, "lunch", 3000, now.AddDate(0, 0, -1))
+	heavy := logMeal(user.ID, "lunch", 900, now.Add(-time.Minute))
+
+	t.Run("Logged meal compared with earlier meals", func(t *testing.T) {
+		comparison, err := comparisonService.CompareLoggedMeal(ctx, user.ID.String(), heavy.ID.String())
+		require.NoError(t, err)
+		require.True(t, comparison.EnoughHistory)
+		assert.Equal(t, 5, comparison.HistoryCount, "the meal itself and other users' meals are left out")
+		assert.InDelta(t, 50, *comparison.CaloriesDeltaPercent, 0.01)
+	})
+
+	t.Run("Defaults to the most recent meal", func(t *testing.T) {
+		comparison, err := comparisonService.CompareLoggedMeal(ctx, user.ID.String(), "")
+		require.NoError(t, err)
+		assert.InDelta(t, 900, comparison.Meal.Calories, 0.01)
+	})
+
+	t.Run("Unlogged meal", func(t *testing.T) {
+		comparison, err := comparisonService.CompareMeal(ctx, user.ID.String(), "lunch", domain.MacroTargets{Calories: 640})
+		require.NoError(t, err)
+		assert.Equal(t, 6, comparison.HistoryCount)
+	})
+
+	t.Run("Another user's meal is not found", func(t *testing.T) {
+		_, err := comparisonService.CompareLoggedMeal(ctx, other.ID.String(), heavy.ID.String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
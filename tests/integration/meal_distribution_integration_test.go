@@ -0,0 +1,185 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMealDistributionSplit(t *testing.T) {
+	daily := domain.MacroTargets{Calories: 2325, Protein: 151, Carbohydrates: 203, Fat: 67}
+
+	sum := func(targets []domain.MealTypeTarget) domain.MacroTargets {
+		var total domain.MacroTargets
+		for _, target := range targets {
+			total.Calories += target.Target.Calories
+			total.Protein += target.Target.Protein
+			total.Carbohydrates += target.Target.Carbohydrates
+			total.Fat += target.Target.Fat
+		}
+		return total
+	}
+
+	t.Run("The default split follows each share and adds up to the day", func(t *testing.T) {
+		targets := domain.DefaultMealDistribution(uuid.New()).SplitTargets(daily)
+		require.Len(t, targets, 4)
+
+		assert.Equal(t, domain.MealTypeBreakfast, targets[0].Group)
+		assert.InDelta(t, 581.3, targets[0].Target.Calories, 0.001)
+		assert.Equal(t, domain.MealTypeLunch, targets[1].Group)
+		assert.InDelta(t, 813.8, targets[1].Target.Calories, 0.001)
+		assert.Equal(t, domain.MealTypeSnack, targets[3].Group)
+
+		total := sum(targets)
+		assert.InDelta(t, daily.Calories, total.Calories, 1e-9)
+		assert.InDelta(t, daily.Protein, total.Protein, 1e-9)
+		assert.InDelta(t, daily.Carbohydrates, total.Carbohydrates, 1e-9)
+		assert.InDelta(t, daily.Fat, total.Fat, 1e-9)
+	})
+
+	t.Run("Uneven shares still add up to the day", func(t *testing.T) {
+		for _, distribution := range []domain.MealDistribution{
+			{Breakfast: 33.33, Lunch: 33.33, Dinner: 33.34},
+			{Breakfast: 12.5, Lunch: 37.5, Dinner: 37.5, Snack: 12.5},
+			{Breakfast: 20, Lunch: 20, Dinner: 20, Snack: 39.6}, // within the tolerance of 100
+		} {
+			total := sum(distribution.SplitTargets(daily))
+			assert.InDelta(t, daily.Calories, total.Calories, 1e-9)
+			assert.InDelta(t, daily.Protein, total.Protein, 1e-9)
+			assert.InDelta(t, daily.Carbohydrates, total.Carbohydrates, 1e-9)
+			assert.InDelta(t, daily.Fat, total.Fat, 1e-9)
+		}
+	})
+
+	t.Run("Meal types without a share get no target", func(t *testing.T) {
+		distribution := domain.MealDistribution{Lunch: 50, Dinner: 50}
+		targets := distribution.SplitTargets(daily)
+
+		assert.Zero(t, targets[0].Target)
+		assert.Zero(t, targets[3].Target)
+		assert.InDelta(t, daily.Calories/2, targets[1].Target.Calories, 0.05)
+		assert.InDelta(t, daily.Calories, sum(targets).Calories, 1e-9)
+	})
+
+	t.Run("Shares must add up to 100 percent", func(t *testing.T) {
+		assert.NoError(t, domain.DefaultMealDistribution(uuid.New()).Validate())
+		assert.NoError(t, (&domain.MealDistribution{Breakfast: 33.33, Lunch: 33.33, Dinner: 33.33}).Validate())
+		assert.ErrorIs(t, (&domain.MealDistribution{Breakfast: 25, Lunch: 25, Dinner: 25}).Validate(), domain.ErrInvalidInput)
+		assert.ErrorIs(t, (&domain.MealDistribution{Breakfast: 120, Lunch: -20}).Validate(), domain.ErrInvalidInput)
+	})
+
+	t.Run("Logged meals are compared with their meal type's target", func(t *testing.T) {
+		targets := domain.CompareMealTargets(domain.DefaultMealDistribution(uuid.New()).SplitTargets(daily), []domain.MealGroupTotals{
+			{Group: domain.MealTypeBreakfast, MealCount: 2, TotalCalories: 700, TotalProtein: 40},
+			{Group: domain.MealTypeOther, MealCount: 1, TotalCalories: 300},
+		})
+
+		assert.Equal(t, 2, targets[0].MealCount)
+		assert.InDelta(t, 700.0, targets[0].Logged.Calories, 0.001)
+		assert.InDelta(t, 581.3-700, targets[0].Remaining.Calories, 0.001)
+		assert.Zero(t, targets[1].Logged)
+		assert.Equal(t, targets[1].Target, targets[1].Remaining)
+	})
+}
+
+func TestMealDistributions(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	targetRepo := postgres.NewNutritionTargetRepository(testDB.DB)
+	distributionRepo := postgres.NewMealDistributionRepository(testDB.DB)
+	profileService := services.NewProfileService(userRepo, goalRepo, targetRepo, distributionRepo, nil)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		goalRepo,
+		targetRepo,
+		distributionRepo,
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	t.Run("Users start with the default distribution", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "distribution_default@example.com")
+
+		distribution, err := profileService.GetMealDistribution(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, domain.MealDistributionSourceDefault, distribution.Source)
+		assert.Equal(t, 35.0, distribution.Lunch)
+	})
+
+	t.Run("Set distribution replaces the previous one", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "distribution_set@example.com")
+
+		_, err := profileService.SetMealDistribution(ctx, user.ID.String(), &domain.MealDistribution{
+			Breakfast: 25, Lunch: 25, Dinner: 25, Snack: 25,
+		})
+		require.NoError(t, err)
+		_, err = profileService.SetMealDistribution(ctx, user.ID.String(), &domain.MealDistribution{
+			Breakfast: 40, Lunch: 30, Dinner: 30,
+		})
+		require.NoError(t, err)
+
+		stored, err := profileService.GetMealDistribution(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, domain.MealDistributionSourceManual, stored.Source)
+		assert.Equal(t, 40.0, stored.Breakfast)
+		assert.Equal(t, 0.0, stored.Snack)
+	})
+
+	t.Run("Shares not adding up to 100 are rejected", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "distribution_invalid@example.com")
+
+		_, err := profileService.SetMealDistribution(ctx, user.ID.String(), &domain.MealDistribution{
+			Breakfast: 50, Lunch: 50, Dinner: 50,
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("The daily summary splits the target by the distribution", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "distribution_summary@example.com")
+		_, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{
+			Calories: 2000, Protein: 150, Carbohydrates: 200, Fat: 67,
+		})
+		require.NoError(t, err)
+		_, err = profileService.SetMealDistribution(ctx, user.ID.String(), &domain.MealDistribution{
+			Breakfast: 30, Lunch: 30, Dinner: 30, Snack: 10,
+		})
+		require.NoError(t, err)
+
+		day := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+		meal := CreateTestMeal(t, testDB.DB, user.ID, "breakfast")
+		require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day.Add(8*time.Hour)).Error)
+
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+		require.Len(t, summary.MealTargets, 4)
+
+		breakfast := summary.MealTargets[0]
+		assert.Equal(t, domain.MealTypeBreakfast, breakfast.Group)
+		assert.InDelta(t, 600.0, breakfast.Target.Calories, 0.001)
+		assert.InDelta(t, 500.0, breakfast.Logged.Calories, 0.01)
+		assert.InDelta(t, 100.0, breakfast.Remaining.Calories, 0.01)
+		assert.Equal(t, 1, breakfast.MealCount)
+
+		var total float64
+		for _, target := range summary.MealTargets {
+			total += target.Target.Calories
+		}
+		assert.InDelta(t, *summary.CalorieTarget, total, 1e-9)
+	})
+}
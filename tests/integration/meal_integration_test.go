@@ -1,13 +1,17 @@
 package integration
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"fitness-tracker/internal/adapters/repositories/postgres"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
 	"fitness-tracker/internal/services"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,7 +30,18 @@ func TestMealFlow(t *testing.T) {
 	// Initialize repositories and services
 	mealRepo := postgres.NewMealRepository(testDB.DB)
 	foodRepo := postgres.NewFoodRepository(testDB.DB)
-	mealService := services.NewMealService(mealRepo, foodRepo)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	mealService := services.NewMealService(mealRepo, foodRepo, postgres.NewUserActionRepository(testDB.DB), summaryService, postgres.NewTransactor(testDB.DB), nil)
 
 	t.Run("Create and confirm meal", func(t *testing.T) {
 		// Create meal
@@ -96,6 +111,129 @@ func TestMealFlow(t *testing.T) {
 	})
 }
 
+func TestBackdatedMealSummary(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+	mealService := services.NewMealService(mealRepo, postgres.NewFoodRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), summaryService, postgres.NewTransactor(testDB.DB), nil)
+
+	user := CreateTestUser(t, testDB.DB, "backdated_meal@example.com")
+	today := time.Now().UTC()
+	lastWeek := today.AddDate(0, 0, -7)
+	twoWeeksAgo := today.AddDate(0, 0, -14)
+
+	storedSummary := func(t *testing.T, day time.Time) *domain.DailySummary {
+		summary, err := metricRepo.GetDailySummary(ctx, user.ID, day)
+		require.NoError(t, err)
+		return summary
+	}
+
+	meal, err := mealService.CreateMeal(ctx, user.ID.String(), &domain.Meal{
+		Name:               "Forgotten Dinner",
+		MealType:           "dinner",
+		ConsumedAt:         lastWeek,
+		TotalCalories:      700,
+		TotalProtein:       40,
+		TotalCarbohydrates: 80,
+		TotalFat:           20,
+	})
+	require.NoError(t, err)
+
+	t.Run("Logging a past meal updates that day's summary", func(t *testing.T) {
+		summary := storedSummary(t, lastWeek)
+		assert.Equal(t, 700.0, summary.TotalCalories)
+		assert.Equal(t, 40.0, summary.TotalProtein)
+
+		_, err := metricRepo.GetDailySummary(ctx, user.ID, today)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Moving a meal updates both days", func(t *testing.T) {
+		_, err := mealService.UpdateMeal(ctx, meal.ID.String(), map[string]interface{}{"consumed_at": twoWeeksAgo})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.0, storedSummary(t, lastWeek).TotalCalories)
+		assert.Equal(t, 700.0, storedSummary(t, twoWeeksAgo).TotalCalories)
+	})
+
+	t.Run("Deleting a past meal clears it from the summary", func(t *testing.T) {
+		require.NoError(t, mealService.DeleteMeal(ctx, meal.ID.String()))
+
+		summary := storedSummary(t, twoWeeksAgo)
+		assert.Equal(t, 0.0, summary.TotalCalories)
+		assert.Equal(t, 0.0, summary.TotalFat)
+	})
+}
+
+func TestMealConsumedAtWindow(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	mealService := services.NewMealService(mealRepo, postgres.NewFoodRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), summaryService, postgres.NewTransactor(testDB.DB), nil)
+	user := CreateTestUser(t, testDB.DB, "meal_future@example.com")
+
+	newMeal := func(consumedAt time.Time) *domain.Meal {
+		return &domain.Meal{Name: "Oatmeal", MealType: "breakfast", ConsumedAt: consumedAt, TotalCalories: 300}
+	}
+
+	t.Run("A meal dated next year is rejected", func(t *testing.T) {
+		_, err := mealService.CreateMeal(ctx, user.ID.String(), newMeal(time.Now().AddDate(1, 0, 0)))
+		require.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "consumed_at")
+	})
+
+	t.Run("Clock skew and past dates are accepted", func(t *testing.T) {
+		_, err := mealService.CreateMeal(ctx, user.ID.String(), newMeal(time.Now().Add(3*time.Hour)))
+		require.NoError(t, err)
+
+		_, err = mealService.CreateMeal(ctx, user.ID.String(), newMeal(time.Now().AddDate(-1, 0, 0)))
+		require.NoError(t, err)
+	})
+
+	t.Run("Moving a meal into the future is rejected", func(t *testing.T) {
+		meal, err := mealService.CreateMeal(ctx, user.ID.String(), newMeal(time.Now()))
+		require.NoError(t, err)
+
+		_, err = mealService.UpdateMeal(ctx, meal.ID.String(), map[string]interface{}{"consumed_at": time.Now().AddDate(0, 0, 2)})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		updated, err := mealService.UpdateMeal(ctx, meal.ID.String(), map[string]interface{}{
+			"consumed_at": time.Now().AddDate(0, 0, -2).Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		assert.True(t, updated.ConsumedAt.Before(time.Now().AddDate(0, 0, -1)))
+	})
+}
+
 func TestMealWithCustomFood(t *testing.T) {
 	// Setup
 	testDB := SetupTestDB(t)
@@ -241,3 +379,329 @@ func TestGetMealsInRange(t *testing.T) {
 		assert.Len(t, retrievedMeals, 2) // Should get today's and yesterday's meals
 	})
 }
+
+func TestSuggestMeal(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	suggestionService := services.NewMealSuggestionService(foodRepo, mealRepo, userRepo)
+
+	user := CreateTestUser(t, testDB.DB, "suggest_meal@example.com")
+
+	chicken := &domain.Food{Name: "Chicken Breast", ServingSize: 100, ServingUnit: "g", Calories: 165, Protein: 31, Carbohydrates: 0, Fat: 3.6, IsVerified: true}
+	tofu := &domain.Food{Name: "Firm Tofu", ServingSize: 100, ServingUnit: "g", Calories: 144, Protein: 17, Carbohydrates: 3, Fat: 9, IsVerified: true}
+	rice := &domain.Food{Name: "White Rice", ServingSize: 100, ServingUnit: "g", Calories: 130, Protein: 2.7, Carbohydrates: 28, Fat: 0.3, IsVerified: true}
+	for _, food := range []*domain.Food{chicken, tofu, rice} {
+		require.NoError(t, foodRepo.Create(ctx, food))
+	}
+
+	target := domain.MacroTargets{Calories: 500, Protein: 50, Carbohydrates: 40, Fat: 10}
+
+	t.Run("Suggests loggable foods close to the target", func(t *testing.T) {
+		suggestion, err := suggestionService.SuggestMeal(ctx, user.ID.String(), target)
+		require.NoError(t, err)
+		require.NotEmpty(t, suggestion.Items)
+
+		for _, item := range suggestion.Items {
+			assert.NotEqual(t, uuid.Nil, item.Food.ID)
+			assert.Greater(t, item.Quantity, 0.0)
+			assert.NotEmpty(t, item.Unit)
+		}
+		assert.InDelta(t, target.Protein, suggestion.Totals.Protein, 20)
+		assert.LessOrEqual(t, suggestion.Totals.Calories, target.Calories*1.2)
+	})
+
+	t.Run("Respects dietary preferences", func(t *testing.T) {
+		prefs := `{"vegetarian": true}`
+		require.NoError(t, testDB.DB.Model(user).Update("dietary_preferences", prefs).Error)
+
+		suggestion, err := suggestionService.SuggestMeal(ctx, user.ID.String(), target)
+		require.NoError(t, err)
+		require.NotEmpty(t, suggestion.Items)
+
+		for _, item := range suggestion.Items {
+			assert.NotEqual(t, chicken.ID, item.Food.ID)
+		}
+	})
+
+	t.Run("Diet keywords match whole words", func(t *testing.T) {
+		peanutButter := &domain.Food{Name: "Peanut Butter", ServingSize: 100, ServingUnit: "g", Calories: 588, Protein: 25, Carbohydrates: 20, Fat: 50, IsVerified: true}
+		eggplant := &domain.Food{Name: "Eggplant", ServingSize: 100, ServingUnit: "g", Calories: 25, Protein: 1, Carbohydrates: 6, Fat: 0.2, IsVerified: true}
+		eggs := &domain.Food{Name: "Scrambled Eggs", ServingSize: 100, ServingUnit: "g", Calories: 149, Protein: 10, Carbohydrates: 1.6, Fat: 11, IsVerified: true}
+		for _, food := range []*domain.Food{peanutButter, eggplant, eggs} {
+			require.NoError(t, foodRepo.Create(ctx, food))
+		}
+		require.NoError(t, testDB.DB.Model(user).Update("dietary_preferences", `{"vegan": true}`).Error)
+
+		// Each target is one serving of the food, so the food is suggested unless excluded
+		suggests := func(food *domain.Food) bool {
+			suggestion, err := suggestionService.SuggestMeal(ctx, user.ID.String(), domain.MacroTargets{
+				Calories: food.Calories, Protein: food.Protein, Carbohydrates: food.Carbohydrates, Fat: food.Fat,
+			})
+			require.NoError(t, err)
+			for _, item := range suggestion.Items {
+				if item.Food.ID == food.ID {
+					return true
+				}
+			}
+			return false
+		}
+		assert.True(t, suggests(peanutButter), "peanut butter is plant-based")
+		assert.True(t, suggests(eggplant), "eggplant is not an egg")
+		assert.False(t, suggests(eggs), "eggs are not vegan")
+	})
+
+	t.Run("Rejects empty target", func(t *testing.T) {
+		_, err := suggestionService.SuggestMeal(ctx, user.ID.String(), domain.MacroTargets{})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestMealNutritionSnapshot(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	user := CreateTestUser(t, testDB.DB, "meal_snapshot@example.com")
+	food := CreateTestFood(t, testDB.DB, "Greek Yogurt", 60.0)
+
+	// Log 150 g of a food with a 100 g serving
+	meal := &domain.Meal{
+		UserID:     user.ID,
+		Name:       "Breakfast",
+		MealType:   "breakfast",
+		ConsumedAt: time.Now(),
+		FoodItems: []domain.MealFoodItem{
+			{FoodID: food.ID, Quantity: 150, Unit: "g"},
+		},
+	}
+	require.NoError(t, mealRepo.Create(ctx, meal))
+	assert.Equal(t, 90.0, meal.TotalCalories)
+	assert.Equal(t, 15.0, meal.TotalProtein)
+
+	// Add a second item through the food item path
+	item := &domain.MealFoodItem{MealID: meal.ID, FoodID: food.ID, Quantity: 1, Unit: "serving"}
+	require.NoError(t, mealRepo.AddFoodItem(ctx, item))
+	assert.Equal(t, 60.0, item.Calories)
+	assert.Equal(t, "Greek Yogurt", item.Snapshot.Name)
+
+	logged, err := mealRepo.GetByID(ctx, meal.ID)
+	require.NoError(t, err)
+	logged.RecalculateTotals()
+	require.NoError(t, mealRepo.Update(ctx, logged))
+
+	// Edit the food after it was logged
+	food.Name = "Greek Yogurt (Reformulated)"
+	food.Calories = 120.0
+	food.Protein = 20.0
+	require.NoError(t, foodRepo.Update(ctx, food))
+
+	t.Run("Meal totals are unchanged by the edit", func(t *testing.T) {
+		retrieved, err := mealRepo.GetByID(ctx, meal.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 150.0, retrieved.TotalCalories)
+		assert.Equal(t, 25.0, retrieved.TotalProtein)
+
+		require.Len(t, retrieved.FoodItems, 2)
+		for _, foodItem := range retrieved.FoodItems {
+			assert.Equal(t, "Greek Yogurt", foodItem.Snapshot.Name)
+			assert.Equal(t, 60.0, foodItem.Snapshot.Calories)
+			assert.Equal(t, 120.0, foodItem.Food.Calories)
+		}
+
+		retrieved.RecalculateTotals()
+		assert.Equal(t, 150.0, retrieved.TotalCalories)
+	})
+
+	t.Run("Quantity changes are priced from the snapshot", func(t *testing.T) {
+		item.Quantity = 2
+		require.NoError(t, mealRepo.UpdateFoodItem(ctx, item))
+		assert.Equal(t, 120.0, item.Calories)
+		assert.Equal(t, 20.0, item.Protein)
+	})
+}
+
+func TestFoodSnapshotServings(t *testing.T) {
+	snapshot := domain.NewFoodSnapshot(&domain.Food{
+		Name:        "Rice",
+		ServingSize: 100,
+		ServingUnit: "g",
+		Calories:    130,
+		Protein:     2.7,
+	})
+
+	assert.Equal(t, 1.5, snapshot.Servings(150, "g"))
+	assert.Equal(t, 1.5, snapshot.Servings(150, " G "))
+	assert.Equal(t, 2.0, snapshot.Servings(2, "cup"))
+
+	item := domain.MealFoodItem{Quantity: 50, Unit: "g", Snapshot: snapshot}
+	item.RecalculateNutrition()
+	assert.Equal(t, 65.0, item.Calories)
+	assert.InDelta(t, 1.35, item.Protein, 1e-9)
+}
+
+func TestMealCreationTransaction(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	transactor := postgres.NewTransactor(testDB.DB)
+	user := CreateTestUser(t, testDB.DB, "meal_tx@example.com")
+	food := CreateTestFood(t, testDB.DB, "Rice", 130.0)
+
+	countMeals := func() int64 {
+		var count int64
+		require.NoError(t, testDB.DB.Unscoped().Model(&domain.Meal{}).Where("user_id = ?", user.ID).Count(&count).Error)
+		return count
+	}
+	newMeal := func() *domain.Meal {
+		return &domain.Meal{UserID: user.ID, Name: "Dinner", MealType: "dinner", ConsumedAt: time.Now()}
+	}
+
+	t.Run("Failure mid-way rolls back earlier writes", func(t *testing.T) {
+		meal := newMeal()
+		err := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := mealRepo.Create(ctx, meal); err != nil {
+				return err
+			}
+			if err := mealRepo.AddFoodItem(ctx, &domain.MealFoodItem{MealID: meal.ID, FoodID: food.ID, Quantity: 100, Unit: "g"}); err != nil {
+				return err
+			}
+			// The food does not exist, so the snapshot lookup fails
+			return mealRepo.AddFoodItem(ctx, &domain.MealFoodItem{MealID: meal.ID, FoodID: uuid.New(), Quantity: 50, Unit: "g"})
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, int64(0), countMeals())
+		var items int64
+		require.NoError(t, testDB.DB.Model(&domain.MealFoodItem{}).Where("meal_id = ?", meal.ID).Count(&items).Error)
+		assert.Equal(t, int64(0), items)
+	})
+
+	t.Run("Nested calls join the outer transaction", func(t *testing.T) {
+		errAbort := errors.New("abort")
+		err := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+				return mealRepo.Create(ctx, newMeal())
+			}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+		assert.Equal(t, int64(0), countMeals())
+	})
+
+	t.Run("Success commits every write", func(t *testing.T) {
+		meal := newMeal()
+		err := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := mealRepo.Create(ctx, meal); err != nil {
+				return err
+			}
+			item := domain.MealFoodItem{MealID: meal.ID, FoodID: food.ID, Quantity: 200, Unit: "g"}
+			if err := mealRepo.AddFoodItem(ctx, &item); err != nil {
+				return err
+			}
+			meal.FoodItems = []domain.MealFoodItem{item}
+			meal.RecalculateTotals()
+			return mealRepo.UpdateTotals(ctx, meal)
+		})
+		require.NoError(t, err)
+
+		stored, err := mealRepo.GetByID(ctx, meal.ID)
+		require.NoError(t, err)
+		assert.Len(t, stored.FoodItems, 1)
+		assert.Equal(t, 260.0, stored.TotalCalories)
+	})
+}
+
+func TestMealPortionWarnings(t *testing.T) {
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	newMealService := func(limits *domain.PortionLimits) ports.MealService {
+		return services.NewMealService(mealRepo, postgres.NewFoodRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), summaryService, postgres.NewTransactor(testDB.DB), limits)
+	}
+	mealService := newMealService(&domain.DefaultPortionLimits)
+
+	user := CreateTestUser(t, testDB.DB, "portion_warnings@example.com")
+	withCategory := func(name, category string, calories float64) *domain.Food {
+		food := CreateTestFood(t, testDB.DB, name, calories)
+		require.NoError(t, testDB.DB.Model(food).Update("category", category).Error)
+		return food
+	}
+	butter := withCategory("Butter", "fat", 717)
+	chicken := withCategory("Chicken Breast", "protein", 165)
+
+	logMeal := func(t *testing.T, service ports.MealService, items ...domain.MealFoodItem) *domain.Meal {
+		meal, err := service.CreateMeal(ctx, user.ID.String(), &domain.Meal{Name: "Lunch", MealType: "lunch", FoodItems: items})
+		require.NoError(t, err)
+		return meal
+	}
+
+	t.Run("Normal portions are not flagged", func(t *testing.T) {
+		meal := logMeal(t, mealService,
+			domain.MealFoodItem{FoodID: butter.ID, Quantity: 10, Unit: "g"},
+			domain.MealFoodItem{FoodID: chicken.ID, Quantity: 250, Unit: "g"},
+		)
+		assert.Empty(t, meal.PortionWarnings)
+	})
+
+	t.Run("A typo-sized portion is flagged with a suggestion but still saved", func(t *testing.T) {
+		meal := logMeal(t, mealService,
+			domain.MealFoodItem{FoodID: butter.ID, Quantity: 5000, Unit: "g"},
+			domain.MealFoodItem{FoodID: chicken.ID, Quantity: 200, Unit: "g"},
+		)
+		require.Len(t, meal.PortionWarnings, 1)
+		warning := meal.PortionWarnings[0]
+		assert.Equal(t, butter.ID, warning.FoodID)
+		assert.Equal(t, 5000.0, warning.Grams)
+		assert.Equal(t, 100.0, warning.MaxGrams)
+		require.NotNil(t, warning.SuggestedQuantity)
+		assert.Equal(t, 50.0, *warning.SuggestedQuantity)
+		assert.Contains(t, warning.Message, "did you mean 50 g?")
+
+		saved, err := mealRepo.GetByID(ctx, meal.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 717*50+165*2, saved.TotalCalories, 0.01)
+	})
+
+	t.Run("Servings are converted to grams", func(t *testing.T) {
+		meal := logMeal(t, mealService, domain.MealFoodItem{FoodID: butter.ID, Quantity: 3, Unit: "serving"})
+		require.Len(t, meal.PortionWarnings, 1)
+		assert.Equal(t, 300.0, meal.PortionWarnings[0].Grams)
+	})
+
+	t.Run("Limits are configurable and the check can be turned off", func(t *testing.T) {
+		strict := domain.PortionLimits{MaxGrams: map[string]float64{"protein": 150}, DefaultMaxGrams: 2000}
+		meal := logMeal(t, newMealService(&strict), domain.MealFoodItem{FoodID: chicken.ID, Quantity: 200, Unit: "g"})
+		require.Len(t, meal.PortionWarnings, 1)
+		assert.Equal(t, 150.0, meal.PortionWarnings[0].MaxGrams)
+
+		meal = logMeal(t, newMealService(nil), domain.MealFoodItem{FoodID: butter.ID, Quantity: 5000, Unit: "g"})
+		assert.Empty(t, meal.PortionWarnings)
+	})
+}
@@ -0,0 +1,390 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMealTextWithoutAI(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "meal_parser_no_ai@example.com")
+	oatmeal := CreateTestFood(t, testDB.DB, "Oatmeal", 150)
+
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+
+	t.Run("Database only resolves known foods and lists the rest", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil)
+
+		parsed, err := parser.ParseText(ctx, user.ID, "200g oatmeal and a dragonfruit for breakfast")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MealTypeBreakfast, parsed.MealType)
+		assert.True(t, parsed.NeedsConfirmation)
+		assert.Equal(t, []string{"dragonfruit"}, parsed.UnresolvedItems)
+
+		require.Len(t, parsed.FoodItems, 1)
+		item := parsed.FoodItems[0]
+		assert.Equal(t, oatmeal.ID, *item.FoodID)
+		assert.False(t, item.AIGenerated)
+		assert.Equal(t, 200.0, item.Quantity)
+		assert.Equal(t, "g", item.Unit)
+		assert.InDelta(t, 300, item.Calories, 0.01)
+	})
+
+	t.Run("Database only fails when nothing resolves", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil)
+
+		_, err := parser.ParseText(ctx, user.ID, "2 bananas")
+		require.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Contains(t, err.Error(), "bananas")
+	})
+
+	t.Run("Builtin table estimates common foods", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil).
+			WithEstimateFallback(domain.MealEstimateFallbackBuiltin)
+
+		parsed, err := parser.ParseText(ctx, user.ID, "2 bananas, 100g dragonfruit")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dragonfruit"}, parsed.UnresolvedItems)
+
+		require.Len(t, parsed.FoodItems, 1)
+		item := parsed.FoodItems[0]
+		assert.Equal(t, "Banana", item.FoodName)
+		assert.Equal(t, "piece", item.Unit)
+		assert.InDelta(t, 178, item.Calories, 0.01, "two 100g servings")
+		assert.InDelta(t, 0.48, parsed.Confidence, 0.001)
+
+		// The estimate is saved, so later parses find it without the table
+		dbOnly := services.NewMealParserService("", foodRepo, nil)
+		parsed, err = dbOnly.ParseText(ctx, user.ID, "a banana")
+		require.NoError(t, err)
+		require.Len(t, parsed.FoodItems, 1)
+		assert.Equal(t, *item.FoodID, *parsed.FoodItems[0].FoodID)
+	})
+
+	t.Run("Photos need AI", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil)
+
+		_, err := parser.ParsePhoto(ctx, user.ID, "https://example.com/meal.jpg")
+		assert.ErrorIs(t, err, domain.ErrAIUnavailable)
+	})
+}
+
+func TestEstimateFoodWithoutAI(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "food_estimate@example.com")
+	parser := services.NewMealParserService("", postgres.NewFoodRepository(testDB.DB), nil)
+
+	var foodsBefore int64
+	require.NoError(t, testDB.DB.Model(&domain.Food{}).Count(&foodsBefore).Error)
+
+	t.Run("Weights scale the per-100g estimate", func(t *testing.T) {
+		estimate, err := parser.EstimateFood(ctx, user.ID, "banana", 150, "g")
+		require.NoError(t, err)
+
+		assert.True(t, estimate.IsEstimate)
+		assert.Equal(t, domain.FoodEstimateSourceBuiltin, estimate.Source)
+		assert.Equal(t, "Banana", estimate.Name)
+		assert.InDelta(t, 150, estimate.Grams, 0.01)
+		assert.InDelta(t, 133.5, estimate.Calories, 0.01)
+		assert.InDelta(t, 89, estimate.Per100g.CaloriesPer100g, 0.01)
+	})
+
+	t.Run("Defaults to one serving", func(t *testing.T) {
+		estimate, err := parser.EstimateFood(ctx, user.ID, "Banana", 0, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1.0, estimate.Quantity)
+		assert.Equal(t, "serving", estimate.Unit)
+		assert.InDelta(t, domain.DefaultServingGrams, estimate.Grams, 0.01)
+		assert.InDelta(t, 89, estimate.Calories, 0.01)
+	})
+
+	t.Run("Nothing is saved", func(t *testing.T) {
+		var count int64
+		require.NoError(t, testDB.DB.Model(&domain.Food{}).Count(&count).Error)
+		assert.Equal(t, foodsBefore, count)
+	})
+
+	t.Run("Unknown foods are not found", func(t *testing.T) {
+		_, err := parser.EstimateFood(ctx, user.ID, "Big Mac", 1, "piece")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Name is required", func(t *testing.T) {
+		_, err := parser.EstimateFood(ctx, user.ID, "  ", 1, "g")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestMealParserConfidenceFloor(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "meal_parser_floor@example.com")
+	CreateTestFood(t, testDB.DB, "Oatmeal", 150)
+
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+
+	// Foods split from text without AI have a confidence of 0.6
+	t.Run("At the floor the meal is returned for confirmation", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil).WithConfidenceFloor(0.6)
+
+		parsed, err := parser.ParseText(ctx, user.ID, "200g oatmeal")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.6, parsed.Confidence, 0.001)
+		assert.True(t, parsed.NeedsConfirmation)
+	})
+
+	t.Run("Below the floor the parse is rejected", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil).WithConfidenceFloor(0.61)
+
+		parsed, err := parser.ParseText(ctx, user.ID, "200g oatmeal")
+		assert.Nil(t, parsed)
+		require.ErrorIs(t, err, domain.ErrLowConfidence)
+		assert.Contains(t, err.Error(), "enter it manually")
+	})
+
+	t.Run("Default floor accepts a confident parse", func(t *testing.T) {
+		parser := services.NewMealParserService("", foodRepo, nil)
+
+		_, err := parser.ParseText(ctx, user.ID, "200g oatmeal")
+		assert.NoError(t, err)
+	})
+}
+
+func TestConfirmParsedMealAdjustments(t *testing.T) {
+	newParsedMeal := func() *domain.ParsedMeal {
+		meal := &domain.ParsedMeal{
+			MealType: domain.MealTypeLunch,
+			FoodItems: []domain.ParsedFoodItem{
+				{FoodName: "Rice", Quantity: 200, Unit: "g", Calories: 260, Protein: 5, Carbohydrates: 56, Fat: 1},
+				{FoodName: "Chicken", Quantity: 150, Unit: "g", Calories: 248, Protein: 46, Carbohydrates: 0, Fat: 5},
+				{FoodName: "Sauce", Quantity: 50, Unit: "g", Calories: 100, Protein: 1, Carbohydrates: 10, Fat: 6},
+			},
+		}
+		meal.SumTotals()
+		return meal
+	}
+
+	t.Run("Excluding an item recomputes the totals", func(t *testing.T) {
+		meal := newParsedMeal()
+		require.NoError(t, meal.ApplyAdjustments([]domain.ParsedItemAdjustment{{Index: 2, Exclude: true}}))
+
+		require.Len(t, meal.FoodItems, 2)
+		assert.Equal(t, "Rice", meal.FoodItems[0].FoodName)
+		assert.Equal(t, "Chicken", meal.FoodItems[1].FoodName)
+		assert.InDelta(t, 508, meal.Totals.Calories, 0.01)
+		assert.InDelta(t, 51, meal.Totals.Protein, 0.01)
+		assert.InDelta(t, 6, meal.Totals.Fat, 0.01)
+	})
+
+	t.Run("A quantity override scales the item's nutrition", func(t *testing.T) {
+		meal := newParsedMeal()
+		quantity := 100.0
+		require.NoError(t, meal.ApplyAdjustments([]domain.ParsedItemAdjustment{{Index: 0, Quantity: &quantity}}))
+
+		require.Len(t, meal.FoodItems, 3)
+		rice := meal.FoodItems[0]
+		assert.Equal(t, 100.0, rice.Quantity)
+		assert.InDelta(t, 130, rice.Calories, 0.01)
+		assert.InDelta(t, 28, rice.Carbohydrates, 0.01)
+		assert.InDelta(t, 478, meal.Totals.Calories, 0.01)
+	})
+
+	t.Run("Invalid adjustments are rejected", func(t *testing.T) {
+		zero := 0.0
+		for name, adjustments := range map[string][]domain.ParsedItemAdjustment{
+			"unknown index":  {{Index: 3, Exclude: true}},
+			"negative index": {{Index: -1, Exclude: true}},
+			"duplicate":      {{Index: 0, Exclude: true}, {Index: 0}},
+			"zero quantity":  {{Index: 1, Quantity: &zero}},
+			"all excluded":   {{Index: 0, Exclude: true}, {Index: 1, Exclude: true}, {Index: 2, Exclude: true}},
+		} {
+			meal := newParsedMeal()
+			err := meal.ApplyAdjustments(adjustments)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput, name)
+			assert.Len(t, meal.FoodItems, 3, "%s leaves the meal unchanged", name)
+		}
+	})
+
+	t.Run("Parsed meals are kept for their user until confirmed", func(t *testing.T) {
+		testDB := SetupTestDB(t)
+		defer TeardownTestDB(t, testDB)
+
+		ctx := context.Background()
+		user := CreateTestUser(t, testDB.DB, "parsed_meal_store@example.com")
+		other := CreateTestUser(t, testDB.DB, "parsed_meal_store_other@example.com")
+		CreateTestFood(t, testDB.DB, "Oatmeal", 150)
+		parser := services.NewMealParserService("", postgres.NewFoodRepository(testDB.DB), nil)
+
+		parsed, err := parser.ParseText(ctx, user.ID, "200g oatmeal for breakfast")
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, parsed.ID)
+
+		stored, err := parser.GetParsedMeal(user.ID, parsed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, parsed.FoodItems, stored.FoodItems)
+
+		// Adjusting the copy leaves the stored meal as parsed
+		quantity := 100.0
+		require.NoError(t, stored.ApplyAdjustments([]domain.ParsedItemAdjustment{{Index: 0, Quantity: &quantity}}))
+		again, err := parser.GetParsedMeal(user.ID, parsed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 200.0, again.FoodItems[0].Quantity)
+
+		_, err = parser.GetParsedMeal(other.ID, parsed.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = parser.TakeParsedMeal(other.ID, parsed.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		// Only one of several concurrent confirmations gets the meal
+		var wg sync.WaitGroup
+		var taken atomic.Int32
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := parser.TakeParsedMeal(user.ID, parsed.ID); err == nil {
+					taken.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), taken.Load())
+		_, err = parser.GetParsedMeal(user.ID, parsed.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		// A meal that failed to log can be confirmed again
+		parser.ReturnParsedMeal(parsed.ID)
+		retaken, err := parser.TakeParsedMeal(user.ID, parsed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 200.0, retaken.FoodItems[0].Quantity)
+
+		parser.ForgetParsedMeal(parsed.ID)
+		parser.ReturnParsedMeal(parsed.ID)
+		_, err = parser.GetParsedMeal(user.ID, parsed.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestMealParserTemperature(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// Each call gets an empty but valid reply; only the request matters here
+	sentTemperature := func(t *testing.T, call func(parser *services.MealParserService)) float64 {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, `{"meal_type": "lunch", "items": []}`, &lastRequest)
+		defer server.Close()
+
+		parser := services.NewMealParserService("test-key", nil, nil).WithBaseURL(server.URL)
+		call(parser)
+		require.NotNil(t, lastRequest, "no request reached the server")
+		temperature, ok := lastRequest["temperature"].(float64)
+		require.True(t, ok, "temperature must be sent, even when 0")
+		return temperature
+	}
+
+	t.Run("Parsing defaults to 0", func(t *testing.T) {
+		assert.Equal(t, 0.0, sentTemperature(t, func(parser *services.MealParserService) {
+			_, _ = parser.ParseText(ctx, userID, "a bowl of soup")
+		}))
+		assert.Equal(t, 0.0, sentTemperature(t, func(parser *services.MealParserService) {
+			_, _ = parser.ParsePhoto(ctx, userID, "https://example.com/meal.jpg")
+		}))
+	})
+
+	t.Run("Every parsing call uses the configured temperature", func(t *testing.T) {
+		assert.Equal(t, 0.2, sentTemperature(t, func(parser *services.MealParserService) {
+			_, _ = parser.WithTemperature(0.2).ParseText(ctx, userID, "a bowl of soup")
+		}))
+		assert.Equal(t, 0.2, sentTemperature(t, func(parser *services.MealParserService) {
+			_, _ = parser.WithTemperature(0.2).ParsePhoto(ctx, userID, "https://example.com/meal.jpg")
+		}))
+	})
+
+	t.Run("Structured output options keep JSON mode with another temperature", func(t *testing.T) {
+		opts := external.StructuredOutputOptions().WithTemperature(0.5)
+		require.NotNil(t, opts.Temperature)
+		assert.Equal(t, 0.5, *opts.Temperature)
+		assert.Equal(t, external.ResponseFormatJSONObject, opts.ResponseFormat.Type)
+		assert.Equal(t, 0.0, *external.StructuredOutputOptions().Temperature)
+	})
+}
+
+func TestMealParserToolExtraction(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "meal_parser_tool@example.com")
+	oatmeal := CreateTestFood(t, testDB.DB, "Oatmeal", 150)
+
+	foodRepo := postgres.NewFoodRepository(testDB.DB)
+	extracted := `{"meal_type": "breakfast", "items": [{"name": "Oatmeal", "quantity": 200, "unit": "g", "confidence": 0.9}]}`
+
+	parse := func(t *testing.T, message map[string]interface{}) (*domain.ParsedMeal, map[string]interface{}) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouterMessage(t, message, &lastRequest)
+		defer server.Close()
+
+		parser := services.NewMealParserService("test-key", foodRepo, nil).WithBaseURL(server.URL)
+		parsed, err := parser.ParseText(ctx, user.ID, "200g oatmeal for breakfast")
+		require.NoError(t, err)
+		return parsed, lastRequest
+	}
+
+	t.Run("The meal is read from the forced tool call", func(t *testing.T) {
+		parsed, lastRequest := parse(t, map[string]interface{}{
+			"role": "assistant",
+			"tool_calls": []map[string]interface{}{{
+				"id":       "call_1",
+				"type":     "function",
+				"function": map[string]string{"name": "record_meal", "arguments": extracted},
+			}},
+		})
+
+		choice, ok := lastRequest["tool_choice"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "record_meal", choice["function"].(map[string]interface{})["name"])
+		require.Len(t, lastRequest["tools"], 1)
+
+		assert.Equal(t, domain.MealTypeBreakfast, parsed.MealType)
+		require.Len(t, parsed.FoodItems, 1)
+		assert.Equal(t, oatmeal.ID, *parsed.FoodItems[0].FoodID)
+		assert.Equal(t, 200.0, parsed.FoodItems[0].Quantity)
+	})
+
+	t.Run("Content replies from models that ignore the tool still parse", func(t *testing.T) {
+		parsed, _ := parse(t, map[string]interface{}{
+			"role":    "assistant",
+			"content": "Here is the meal:\n```json\n" + extracted + "\n```",
+		})
+
+		assert.Equal(t, domain.MealTypeBreakfast, parsed.MealType)
+		require.Len(t, parsed.FoodItems, 1)
+		assert.Equal(t, oatmeal.ID, *parsed.FoodItems[0].FoodID)
+	})
+}
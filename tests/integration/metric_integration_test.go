@@ -0,0 +1,272 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricCorrection(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	metricService := services.NewMetricService(postgres.NewMetricRepository(testDB.DB), userRepo, postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+
+	user := CreateTestUser(t, testDB.DB, "metrics@example.com")
+	require.NoError(t, testDB.DB.Model(user).Update("height_cm", 180.0).Error)
+
+	day1 := time.Date(2025, 11, 10, 8, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	_, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 80.0, "kg", day1)
+	require.NoError(t, err)
+	misLogged, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 91.0, "kg", day2)
+	require.NoError(t, err)
+	bmi, err := metricService.LogMetric(ctx, user.ID.String(), "bmi", 28.1, "kg/m2", day2)
+	require.NoError(t, err)
+
+	t.Run("Editing a weight entry is reflected in the trend", func(t *testing.T) {
+		value := 81.0
+		updated, err := metricService.UpdateMetric(ctx, user.ID.String(), misLogged.ID.String(), &domain.MetricUpdate{
+			Value: &value,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 81.0, updated.Value)
+
+		trend, err := metricService.GetMetricTrend(ctx, user.ID.String(), "weight", nil, nil)
+		require.NoError(t, err)
+		require.Len(t, trend, 2)
+		assert.Equal(t, 80.0, trend[0].Value)
+		assert.Equal(t, 81.0, trend[1].Value)
+	})
+
+	t.Run("Derived values follow the corrected weight", func(t *testing.T) {
+		// BMI logged with the weight is recomputed from the profile height
+		trend, err := metricService.GetMetricTrend(ctx, user.ID.String(), "bmi", nil, nil)
+		require.NoError(t, err)
+		require.Len(t, trend, 1)
+		assert.Equal(t, bmi.ID, trend[0].ID)
+		assert.InDelta(t, 25.0, trend[0].Value, 0.01)
+
+		// Profile weight tracks the latest entry
+		refreshed, err := userRepo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, refreshed.WeightKg)
+		assert.Equal(t, 81.0, *refreshed.WeightKg)
+	})
+
+	t.Run("Rejects invalid corrections", func(t *testing.T) {
+		negative := -1.0
+		_, err := metricService.UpdateMetric(ctx, user.ID.String(), misLogged.ID.String(), &domain.MetricUpdate{
+			Value: &negative,
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		implausible := 810.0
+		_, err = metricService.UpdateMetric(ctx, user.ID.String(), misLogged.ID.String(), &domain.MetricUpdate{
+			Value: &implausible,
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Metrics of other users are not found", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "metrics_other@example.com")
+		value := 60.0
+
+		_, err := metricService.UpdateMetric(ctx, other.ID.String(), misLogged.ID.String(), &domain.MetricUpdate{
+			Value: &value,
+		})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		err = metricService.DeleteMetric(ctx, other.ID.String(), misLogged.ID.String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		err = metricService.DeleteMetric(ctx, user.ID.String(), uuid.New().String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Deleting a weight entry removes its BMI and rolls back the profile weight", func(t *testing.T) {
+		require.NoError(t, metricService.DeleteMetric(ctx, user.ID.String(), misLogged.ID.String()))
+
+		trend, err := metricService.GetMetricTrend(ctx, user.ID.String(), "weight", nil, nil)
+		require.NoError(t, err)
+		require.Len(t, trend, 1)
+		assert.Equal(t, 80.0, trend[0].Value)
+
+		bmis, err := metricService.GetMetricTrend(ctx, user.ID.String(), "bmi", nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, bmis)
+
+		refreshed, err := userRepo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, refreshed.WeightKg)
+		assert.Equal(t, 80.0, *refreshed.WeightKg)
+	})
+}
+
+func TestMetricSummaryDay(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	metricService := services.NewMetricService(metricRepo, postgres.NewUserRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+
+	user := CreateTestUser(t, testDB.DB, "metric_summary_day@example.com")
+	require.NoError(t, testDB.DB.Model(user).Update("timezone", "America/New_York").Error)
+
+	// 03:00 UTC on the 10th is still the evening of the 9th in New York
+	weighIn, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 80.0, "kg", time.Date(2025, 11, 10, 3, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	value := 79.0
+	_, err = metricService.UpdateMetric(ctx, user.ID.String(), weighIn.ID.String(), &domain.MetricUpdate{Value: &value})
+	require.NoError(t, err)
+
+	summary, err := metricRepo.GetDailySummary(ctx, user.ID, time.Date(2025, 11, 9, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NotNil(t, summary.Weight)
+	assert.Equal(t, 79.0, *summary.Weight)
+
+	_, err = metricRepo.GetDailySummary(ctx, user.ID, time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestMetricFutureDate(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricService := services.NewMetricService(postgres.NewMetricRepository(testDB.DB), postgres.NewUserRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+	user := CreateTestUser(t, testDB.DB, "metric_future@example.com")
+
+	_, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 80.0, "kg", time.Now().AddDate(0, 1, 0))
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "measured_at")
+
+	metric, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 80.0, "kg", time.Now().AddDate(0, -1, 0))
+	require.NoError(t, err)
+
+	future := time.Now().AddDate(0, 0, 3)
+	_, err = metricService.UpdateMetric(ctx, user.ID.String(), metric.ID.String(), &domain.MetricUpdate{MeasuredAt: &future})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestLogMetricValueRange(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricService := services.NewMetricService(postgres.NewMetricRepository(testDB.DB), postgres.NewUserRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+	user := CreateTestUser(t, testDB.DB, "metric_range@example.com")
+	measuredAt := time.Date(2025, 11, 19, 7, 0, 0, 0, time.UTC)
+
+	t.Run("Implausible readings are rejected like in a batch", func(t *testing.T) {
+		_, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 5000, "kg", measuredAt)
+		require.ErrorIs(t, err, domain.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "plausible range")
+
+		_, err = metricService.LogMetric(ctx, user.ID.String(), "heart_rate", 900, "bpm", measuredAt)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = metricService.LogMetric(ctx, user.ID.String(), "water", -1, "ml", measuredAt)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Weights are checked in kg", func(t *testing.T) {
+		_, err := metricService.LogMetric(ctx, user.ID.String(), "weight", 180, "lb", measuredAt)
+		assert.NoError(t, err)
+
+		_, err = metricService.LogMetric(ctx, user.ID.String(), "weight", 900, "lb", measuredAt)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Unknown types are rejected", func(t *testing.T) {
+		_, err := metricService.LogMetric(ctx, user.ID.String(), "vo2_max", 45, "ml/kg/min", measuredAt)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = metricService.GetMetricTrend(ctx, user.ID.String(), "vo2_max", nil, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = metricService.GetLatestMetric(ctx, user.ID.String(), "vo2_max")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestLogMetricBatch(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	metricService := services.NewMetricService(metricRepo, postgres.NewUserRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+
+	user := CreateTestUser(t, testDB.DB, "metric_batch@example.com")
+	morning := time.Date(2025, 11, 19, 7, 0, 0, 0, time.UTC)
+
+	entries := []domain.MetricEntry{
+		{MetricType: "weight", Value: 81.2, Unit: "kg", MeasuredAt: morning},
+		{MetricType: "heart_rate", Value: 58, Unit: "bpm", MeasuredAt: morning},
+		{MetricType: "heart_rate", Value: 58, Unit: "bpm", MeasuredAt: morning},
+		{MetricType: "heart_rate", Value: 900, Unit: "bpm", MeasuredAt: morning.Add(time.Minute)},
+		{MetricType: "weight", Value: 180, Unit: "lb", MeasuredAt: morning.AddDate(0, 0, 1)},
+		{MetricType: "vo2_max", Value: 45, Unit: "ml/kg/min", MeasuredAt: morning},
+		{MetricType: "steps", Value: 8000, Unit: "steps"},
+		{MetricType: "weight", Value: 81, Unit: "kg", MeasuredAt: time.Now().Add(48 * time.Hour)},
+	}
+
+	t.Run("Valid rows are logged and bad rows reported", func(t *testing.T) {
+		result, err := metricService.LogMetricBatch(ctx, user.ID.String(), entries)
+		require.NoError(t, err)
+		assert.Equal(t, 8, result.Received)
+		assert.Equal(t, 3, result.Created)
+		assert.Equal(t, 1, result.Duplicates, "the repeated heart rate reading is logged once")
+		assert.Equal(t, 4, result.Failed)
+
+		require.Len(t, result.Errors, 4)
+		assert.Equal(t, 3, result.Errors[0].Index)
+		assert.Contains(t, result.Errors[0].Message, "plausible range")
+		assert.Equal(t, 5, result.Errors[1].Index)
+		assert.Contains(t, result.Errors[1].Message, "unknown metric type")
+		assert.Equal(t, 6, result.Errors[2].Index)
+		assert.Contains(t, result.Errors[2].Message, "measured_at is required")
+		assert.Equal(t, 7, result.Errors[3].Index)
+		assert.Contains(t, result.Errors[3].Message, "more than 24 hours in the future")
+
+		heartRates, err := metricRepo.ListByUser(ctx, user.ID, "heart_rate", time.Time{}, time.Time{}, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, heartRates, 1)
+	})
+
+	t.Run("Resubmitting the batch logs nothing new", func(t *testing.T) {
+		result, err := metricService.LogMetricBatch(ctx, user.ID.String(), entries)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Created)
+		assert.Equal(t, 4, result.Duplicates)
+
+		weights, err := metricRepo.ListByUser(ctx, user.ID, "weight", time.Time{}, time.Time{}, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, weights, 2)
+	})
+
+	t.Run("Oversized batch is rejected", func(t *testing.T) {
+		oversized := make([]domain.MetricEntry, domain.MaxMetricBatchSize+1)
+		_, err := metricService.LogMetricBatch(ctx, user.ID.String(), oversized)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
@@ -0,0 +1,26 @@
+package integration
+
+import (
+	"testing"
+
+	"fitness-tracker/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "banana", utils.NormalizeName("Bananas"))
+	assert.Equal(t, "chicken grilled", utils.NormalizeName("Chicken, grilled"))
+	assert.Equal(t, "chicken grilled", utils.NormalizeName("grilled chicken"))
+	assert.Equal(t, "glass", utils.NormalizeName("glass"))
+	assert.Equal(t, "", utils.NormalizeName(" - "))
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, utils.NameSimilarity("Banana", "bananas"))
+	assert.Equal(t, 1.0, utils.NameSimilarity("Grilled Chicken", "chicken (grilled)"))
+	assert.GreaterOrEqual(t, utils.NameSimilarity("chiken breast", "chicken breast"), 0.85)
+	assert.Less(t, utils.NameSimilarity("banana", "banana bread"), 0.85)
+	assert.Less(t, utils.NameSimilarity("apple", "pineapple juice"), 0.5)
+	assert.Equal(t, 0.0, utils.NameSimilarity("", ""))
+}
@@ -0,0 +1,317 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveNutritionTarget(t *testing.T) {
+	dob := time.Date(1990, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2020, 6, 2, 0, 0, 0, 0, time.UTC)
+	user := &domain.User{
+		WeightKg:    float64Ptr(80),
+		HeightCm:    float64Ptr(180),
+		DateOfBirth: &dob,
+		Gender:      stringPtr("male"),
+	}
+
+	// BMR 1780 kcal, sedentary TDEE 2136 kcal
+	target := domain.DeriveNutritionTarget(user, at)
+	require.NotNil(t, target)
+	assert.Equal(t, domain.NutritionTargetSourceProfile, target.Source)
+	assert.Equal(t, 2136.0, target.Calories)
+	assert.Equal(t, 128.0, target.Protein)
+	assert.Equal(t, 71.0, target.Fat)
+	assert.Equal(t, 246.0, target.Carbohydrates)
+	assert.Equal(t, 2800.0, target.WaterMl)
+	assert.Empty(t, domain.CheckMacroSplit(target.Macros()))
+
+	user.DateOfBirth = nil
+	assert.Nil(t, domain.DeriveNutritionTarget(user, at))
+}
+
+func TestMacroPresets(t *testing.T) {
+	const calories = 2400.0
+
+	// The grams of each macro add back up to the calories, give or take rounding
+	assertCoherent := func(t *testing.T, preset domain.MacroPreset) {
+		assert.InDelta(t, 100, preset.Protein+preset.Carbohydrates+preset.Fat, 0.01)
+		targets := preset.Targets(calories)
+		implied := targets.Protein*domain.CaloriesPerGramProtein +
+			targets.Carbohydrates*domain.CaloriesPerGramCarbohydrates +
+			targets.Fat*domain.CaloriesPerGramFat
+		assert.InDelta(t, calories, implied, 10)
+		assert.Empty(t, domain.CheckMacroSplit(targets))
+	}
+
+	t.Run("Each default preset", func(t *testing.T) {
+		for _, goalType := range []string{domain.MacroPresetCut, domain.MacroPresetMaintain, domain.MacroPresetBulk} {
+			t.Run(goalType, func(t *testing.T) {
+				preset := domain.DefaultMacroPresets.Choose(goalType, nil)
+				assert.Equal(t, goalType, preset.GoalType)
+				assert.Empty(t, preset.Adjustments)
+				assertCoherent(t, preset)
+			})
+		}
+
+		// Cut: 40% of 2400 kcal is 240g protein, 30% is 180g carbs and 80g fat
+		cut := domain.DefaultMacroPresets.Choose("Cut", nil).Targets(calories)
+		assert.Equal(t, domain.MacroTargets{Calories: calories, Protein: 240, Carbohydrates: 180, Fat: 80}, cut)
+	})
+
+	t.Run("Unknown goal types get the maintain split", func(t *testing.T) {
+		preset := domain.DefaultMacroPresets.Choose("lose_weight", nil)
+		assert.Equal(t, domain.MacroPresetMaintain, preset.GoalType)
+		assert.Equal(t, domain.DefaultMacroPresets[domain.MacroPresetMaintain], preset.MacroSplit)
+	})
+
+	t.Run("Dietary preferences adjust the split", func(t *testing.T) {
+		keto := domain.DefaultMacroPresets.Choose(domain.MacroPresetBulk, map[string]interface{}{"keto": true})
+		assert.Equal(t, []string{"keto"}, keto.Adjustments)
+		assert.Equal(t, 70.0, keto.Fat)
+		assertCoherent(t, keto)
+
+		highProtein := domain.DefaultMacroPresets.Choose(domain.MacroPresetCut, map[string]interface{}{"high_protein": true})
+		assert.Equal(t, 50.0, highProtein.Protein)
+		assert.Equal(t, 20.0, highProtein.Carbohydrates)
+		assertCoherent(t, highProtein)
+
+		// Keto leaves 5% carbs, so the rest of the high-protein shift comes out of fat
+		both := domain.DefaultMacroPresets.Choose(domain.MacroPresetCut, map[string]interface{}{"keto": true, "high_protein": true})
+		assert.Equal(t, []string{"keto", "high_protein"}, both.Adjustments)
+		assert.Equal(t, domain.MacroSplit{Protein: 35, Carbohydrates: 0, Fat: 65}, both.MacroSplit)
+		assertCoherent(t, both)
+
+		// Protein gains only what carbohydrates and fat had left to give
+		lean := domain.MacroPresets{"lean": {Protein: 94, Carbohydrates: 4, Fat: 2}}.Choose("lean", map[string]interface{}{"high_protein": true})
+		assert.Equal(t, domain.MacroSplit{Protein: 100, Carbohydrates: 0, Fat: 0}, lean.MacroSplit)
+	})
+
+	t.Run("Configured splits override the defaults", func(t *testing.T) {
+		split, err := domain.ParseMacroSplit("35/35/30")
+		require.NoError(t, err)
+		presets := domain.DefaultMacroPresets.WithOverrides(domain.MacroPresets{"recomp": split})
+
+		recomp := presets.Choose("recomp", nil)
+		assert.Equal(t, "recomp", recomp.GoalType)
+		assertCoherent(t, recomp)
+		assert.Equal(t, domain.DefaultMacroPresets[domain.MacroPresetCut], presets.Choose(domain.MacroPresetCut, nil).MacroSplit)
+
+		_, err = domain.ParseMacroSplit("50/30/30")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		_, err = domain.ParseMacroSplit("40/60")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestNutritionTargets(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	goalRepo := postgres.NewGoalRepository(testDB.DB)
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	targetRepo := postgres.NewNutritionTargetRepository(testDB.DB)
+	profileService := services.NewProfileService(userRepo, goalRepo, targetRepo, postgres.NewMealDistributionRepository(testDB.DB), nil)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		workoutRepo,
+		userRepo,
+		goalRepo,
+		targetRepo,
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+	goalService := services.NewGoalService(goalRepo, postgres.NewMetricRepository(testDB.DB), workoutRepo)
+
+	t.Run("Defaults are overridden by active nutrition goals", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "targets_default@example.com")
+		require.NoError(t, testDB.DB.Create(&domain.Goal{
+			UserID:      user.ID,
+			GoalType:    "protein",
+			TargetValue: 170,
+			Unit:        "g",
+			StartDate:   time.Now(),
+			Status:      "active",
+		}).Error)
+
+		target, err := profileService.GetNutritionTargets(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, domain.NutritionTargetSourceDefault, target.Source)
+		assert.Equal(t, domain.DefaultDailyTargets.Calories, target.Calories)
+		assert.Equal(t, 170.0, target.Protein)
+		assert.Equal(t, domain.DefaultWaterTargetMl, target.WaterMl)
+	})
+
+	t.Run("Set target replaces the previous one", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "targets_set@example.com")
+
+		_, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{
+			Calories: 3000, Protein: 200, Carbohydrates: 300, Fat: 100, WaterMl: 3000,
+		})
+		require.NoError(t, err)
+
+		target, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{
+			Calories: 2400, Protein: 180, Carbohydrates: 240, Fat: 80,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domain.NutritionTargetSourceManual, target.Source)
+		assert.Equal(t, domain.DefaultWaterTargetMl, target.WaterMl)
+		assert.Empty(t, target.Warnings)
+
+		stored, err := profileService.GetNutritionTargets(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, 2400.0, stored.Calories)
+		assert.Equal(t, 80.0, stored.Fat)
+	})
+
+	t.Run("Inconsistent macros are saved with a warning", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "targets_warning@example.com")
+
+		target, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{
+			Calories: 1500, Protein: 200, Carbohydrates: 300, Fat: 100,
+		})
+		require.NoError(t, err)
+		require.Len(t, target.Warnings, 1)
+	})
+
+	t.Run("Invalid targets are rejected", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "targets_invalid@example.com")
+
+		_, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{Protein: 150})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{Calories: 2000, Fat: -1})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Deriving needs a complete profile", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "targets_derive@example.com")
+
+		_, err := profileService.DeriveNutritionTargets(ctx, user.ID.String(), "")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		dob := time.Now().AddDate(-30, 0, -1)
+		user.WeightKg = float64Ptr(80)
+		user.HeightCm = float64Ptr(180)
+		user.DateOfBirth = &dob
+		require.NoError(t, testDB.DB.Save(user).Error)
+
+		target, err := profileService.DeriveNutritionTargets(ctx, user.ID.String(), "")
+		require.NoError(t, err)
+		assert.Equal(t, domain.NutritionTargetSourceProfile, target.Source)
+
+		stored, err := summaryService.GetNutritionTargets(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, target.Calories, stored.Calories)
+		assert.Nil(t, target.MacroPreset)
+
+		// A goal type splits the same calories by its preset, adjusted for dietary preferences
+		require.NoError(t, testDB.DB.Model(user).Update("dietary_preferences", `{"high_protein": true}`).Error)
+		cut, err := profileService.DeriveNutritionTargets(ctx, user.ID.String(), domain.MacroPresetCut)
+		require.NoError(t, err)
+		require.NotNil(t, cut.MacroPreset)
+		assert.Equal(t, domain.MacroPresetCut, cut.MacroPreset.GoalType)
+		assert.Equal(t, []string{domain.DietaryPreferenceHighProtein}, cut.MacroPreset.Adjustments)
+		assert.Equal(t, target.Calories, cut.Calories)
+		assert.Equal(t, cut.MacroPreset.Targets(cut.Calories), cut.Macros())
+
+		stored, err = summaryService.GetNutritionTargets(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, cut.Protein, stored.Protein)
+	})
+
+	t.Run("Summaries, the coach and the agent read the same target", func(t *testing.T) {
+		user := CreateTestUser(t, testDB.DB, "targets_consumers@example.com")
+		target, err := profileService.SetNutritionTargets(ctx, user.ID.String(), &domain.NutritionTarget{
+			Calories: 2400, Protein: 180, Carbohydrates: 240, Fat: 80,
+		})
+		require.NoError(t, err)
+
+		adherence, err := summaryService.GetAdherence(ctx, user.ID.String(), time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, target.Macros(), adherence.Targets)
+
+		coachService := services.NewCoachService(userRepo, summaryService, goalService, nil, "", nil)
+		digest, err := coachService.GetDigest(ctx, user.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, target.Macros(), digest.Targets)
+
+		// The agent calls the daily macros tool once, then answers
+		var requests []external.ChatRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req external.ChatRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			requests = append(requests, req)
+
+			message := map[string]interface{}{"role": "assistant", "content": "You have plenty left."}
+			if len(requests) == 1 {
+				message = map[string]interface{}{
+					"role":    "assistant",
+					"content": "",
+					"tool_calls": []map[string]interface{}{{
+						"id":       "call_macros",
+						"type":     "function",
+						"function": map[string]string{"name": "calculate_daily_macros", "arguments": `{"date": "` + time.Now().Format("2006-01-02") + `"}`},
+					}},
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":      "test-response-id",
+				"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+			})
+		}))
+		defer server.Close()
+
+		agent := services.NewAgentService(
+			nil, nil,
+			services.NewActivityService(postgres.NewActivityRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), summaryService),
+			nil, nil,
+			goalService,
+			summaryService,
+			nil, nil,
+			postgres.NewConversationRepository(testDB.DB),
+			userRepo,
+			external.NewOpenRouterClientWithBaseURL("test-key", server.URL),
+		)
+
+		_, err = agent.SendMessage(ctx, user.ID, "How am I doing today?")
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+
+		systemPrompt := requests[0].Messages[0]
+		assert.Equal(t, "system", systemPrompt.Role)
+		assert.Contains(t, systemPrompt.Content, "- Calories: 0 / 2400")
+		assert.Contains(t, systemPrompt.Content, "- Protein: 0.0g / 180.0g")
+
+		msgs := requests[1].Messages
+		toolMsg := msgs[len(msgs)-1]
+		assert.Equal(t, "tool", toolMsg.Role)
+		var macros struct {
+			Consumed domain.MacroTargets `json:"consumed"`
+			Targets  domain.MacroTargets `json:"targets"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(toolMsg.Content), &macros))
+		assert.Equal(t, 2400.0, macros.Targets.Calories)
+		assert.Equal(t, 80.0, macros.Targets.Fat)
+		assert.Zero(t, macros.Consumed.Calories)
+	})
+}
@@ -0,0 +1,417 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOpenRouter records the last chat request and replies with the given content
+func fakeOpenRouter(t *testing.T, content string, lastRequest *map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return fakeOpenRouterMessage(t, map[string]interface{}{"role": "assistant", "content": content}, lastRequest)
+}
+
+// fakeOpenRouterMessage records the last chat request and replies with the given message
+func fakeOpenRouterMessage(t *testing.T, message map[string]interface{}, lastRequest *map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chat/completions", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(lastRequest))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":    "test",
+			"model": "test-model",
+			"choices": []map[string]interface{}{
+				{"index": 0, "message": message, "finish_reason": "stop"},
+			},
+		})
+	}))
+}
+
+func TestOpenRouterStructuredOutput(t *testing.T) {
+	ctx := context.Background()
+	messages := []external.Message{{Role: "user", Content: "2 eggs"}}
+
+	type nutrition struct {
+		Calories float64 `json:"calories"`
+		Protein  float64 `json:"protein"`
+	}
+
+	t.Run("JSON mode request and pure JSON reply", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, `{"calories": 155, "protein": 13}`, &lastRequest)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		resp, err := client.ChatWithOptions(ctx, messages, "test-model", external.StructuredOutputOptions())
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.0, lastRequest["temperature"])
+		assert.Equal(t, map[string]interface{}{"type": "json_object"}, lastRequest["response_format"])
+
+		var result nutrition
+		require.NoError(t, external.DecodeJSONContent(resp.Choices[0].Message.Content, &result))
+		assert.Equal(t, 155.0, result.Calories)
+		assert.Equal(t, 13.0, result.Protein)
+	})
+
+	t.Run("JSON schema request", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, `{"calories": 155, "protein": 13}`, &lastRequest)
+		defer server.Close()
+
+		schema := map[string]interface{}{"type": "object"}
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		_, err := client.ChatWithOptions(ctx, messages, "test-model", external.JSONSchemaOptions("nutrition", schema))
+		require.NoError(t, err)
+
+		format, ok := lastRequest["response_format"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "json_schema", format["type"])
+		assert.Equal(t, "nutrition", format["json_schema"].(map[string]interface{})["name"])
+	})
+
+	t.Run("Forced tool call request and arguments", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouterMessage(t, map[string]interface{}{
+			"role":    "assistant",
+			"content": nil,
+			"tool_calls": []map[string]interface{}{{
+				"id":       "call_1",
+				"type":     "function",
+				"function": map[string]string{"name": "nutrition", "arguments": `{"calories": 155, "protein": 13}`},
+			}},
+		}, &lastRequest)
+		defer server.Close()
+
+		tool := external.Tool{Type: "function", Function: external.ToolFunction{Name: "nutrition", Parameters: map[string]interface{}{"type": "object"}}}
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		resp, err := client.ChatWithToolsAndOptions(ctx, messages, []external.Tool{tool}, "test-model", external.ToolCallOptions("nutrition"))
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.0, lastRequest["temperature"])
+		assert.Equal(t, map[string]interface{}{
+			"type":     "function",
+			"function": map[string]interface{}{"name": "nutrition"},
+		}, lastRequest["tool_choice"])
+
+		arguments, ok := resp.ToolArguments("nutrition")
+		require.True(t, ok)
+		var result nutrition
+		require.NoError(t, external.DecodeJSONContent(arguments, &result))
+		assert.Equal(t, 155.0, result.Calories)
+
+		_, ok = resp.ToolArguments("other")
+		assert.False(t, ok)
+	})
+
+	t.Run("Plain chat keeps defaults", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, "hello", &lastRequest)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		_, err := client.Chat(ctx, messages, "test-model")
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.7, lastRequest["temperature"])
+		assert.NotContains(t, lastRequest, "response_format")
+		assert.NotContains(t, lastRequest, "tool_choice")
+	})
+
+	t.Run("Fence stripping fallback", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, "Here is the estimate:\n```json\n{\"calories\": 155, \"protein\": 13}\n```", &lastRequest)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		resp, err := client.ChatWithOptions(ctx, messages, "test-model", external.StructuredOutputOptions())
+		require.NoError(t, err)
+
+		var result nutrition
+		require.NoError(t, external.DecodeJSONContent(resp.Choices[0].Message.Content, &result))
+		assert.Equal(t, 155.0, result.Calories)
+
+		var fenced nutrition
+		require.NoError(t, external.DecodeJSONContent("```json\n{\"calories\": 90}\n```", &fenced))
+		assert.Equal(t, 90.0, fenced.Calories)
+	})
+
+	t.Run("No JSON in reply", func(t *testing.T) {
+		var result nutrition
+		assert.Error(t, external.DecodeJSONContent("I can't estimate that food.", &result))
+	})
+}
+
+func TestOpenRouterMultimodalMessage(t *testing.T) {
+	image := "data:image/png;base64,iVBORw0KGgo="
+
+	t.Run("Parts serialize as the content array", func(t *testing.T) {
+		msg := external.NewMultimodalMessage("user", external.TextPart("What is this?"), external.ImagePart(image))
+
+		data, err := json.Marshal(msg)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"role": "user",
+			"content": [
+				{"type": "text", "text": "What is this?"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo="}}
+			]
+		}`, string(data))
+	})
+
+	t.Run("Text serializes as a string", func(t *testing.T) {
+		data, err := json.Marshal(external.NewTextMessage("user", `say "hi"`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"role": "user", "content": "say \"hi\""}`, string(data))
+	})
+
+	t.Run("Request matches the multimodal schema", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, "A banana.", &lastRequest)
+		defer server.Close()
+
+		messages := []external.Message{
+			external.NewTextMessage("system", "You identify foods."),
+			external.NewMultimodalMessage("user", external.TextPart("What is this?"), external.ImagePart(image)),
+		}
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		_, err := client.Chat(context.Background(), messages, "test-model")
+		require.NoError(t, err)
+
+		sent := lastRequest["messages"].([]interface{})
+		require.Len(t, sent, 2)
+		assert.Equal(t, "You identify foods.", sent[0].(map[string]interface{})["content"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"type": "text", "text": "What is this?"},
+			map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": image}},
+		}, sent[1].(map[string]interface{})["content"])
+	})
+
+	t.Run("Decodes string, array and null content", func(t *testing.T) {
+		var msgs []external.Message
+		require.NoError(t, json.Unmarshal([]byte(`[
+			{"role": "user", "content": "plain"},
+			{"role": "user", "content": [{"type": "text", "text": "look"}, {"type": "image_url", "image_url": {"url": "https://example.com/a.jpg"}}]},
+			{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "x", "arguments": "{}"}}]}
+		]`), &msgs))
+		require.Len(t, msgs, 3)
+
+		assert.Equal(t, "plain", msgs[0].Content)
+		assert.Empty(t, msgs[0].Parts)
+
+		require.Len(t, msgs[1].Parts, 2)
+		assert.Equal(t, "look", msgs[1].Content)
+		assert.Equal(t, "https://example.com/a.jpg", msgs[1].Parts[1].ImageURL.URL)
+
+		assert.Empty(t, msgs[2].Content)
+		require.Len(t, msgs[2].ToolCalls, 1)
+		assert.Equal(t, "call_1", msgs[2].ToolCalls[0].ID)
+	})
+
+	t.Run("Responses with array content decode", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouterMessage(t, map[string]interface{}{
+			"role": "assistant",
+			"content": []map[string]interface{}{
+				{"type": "text", "text": "A banana."},
+				{"type": "text", "text": "About 105 kcal."},
+			},
+		}, &lastRequest)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		resp, err := client.Chat(context.Background(), []external.Message{external.NewTextMessage("user", "What is this?")}, "test-model")
+		require.NoError(t, err)
+
+		require.Len(t, resp.Choices, 1)
+		message := resp.Choices[0].Message
+		assert.Equal(t, "assistant", message.Role)
+		assert.Equal(t, "A banana.\nAbout 105 kcal.", message.Content)
+		assert.Len(t, message.Parts, 2)
+	})
+}
+
+func TestOpenRouterRetryAfter(t *testing.T) {
+	ctx := context.Background()
+	messages := []external.Message{{Role: "user", Content: "2 eggs"}}
+
+	// rateLimitedServer answers 429 with the given Retry-After until limitedCalls requests were made
+	rateLimitedServer := func(retryAfter string, limitedCalls int32, calls *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(calls, 1) <= limitedCalls {
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error": {"message": "Rate limit exceeded"}}`))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": "test",
+				"choices": []map[string]interface{}{
+					{"index": 0, "message": map[string]string{"role": "assistant", "content": "ok"}, "finish_reason": "stop"},
+				},
+			})
+		}))
+	}
+
+	t.Run("Waits out Retry-After before retrying", func(t *testing.T) {
+		var calls int32
+		server := rateLimitedServer("3", 1, &calls)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		start := time.Now()
+		resp, err := client.Chat(ctx, messages, "test-model")
+		require.NoError(t, err)
+
+		assert.Equal(t, "ok", resp.Choices[0].Message.Content)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		assert.GreaterOrEqual(t, time.Since(start), 3*time.Second, "retried before Retry-After elapsed")
+	})
+
+	t.Run("Surfaces a long Retry-After without hammering the provider", func(t *testing.T) {
+		var calls int32
+		server := rateLimitedServer("120", 10, &calls)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		_, err := client.Chat(ctx, messages, "test-model")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrRateLimited)
+
+		var rateLimited *domain.RateLimitError
+		require.ErrorAs(t, err, &rateLimited)
+		assert.InDelta(t, 120, rateLimited.RetryAfter.Seconds(), 1)
+
+		// Calls during the throttle window fail fast without reaching the provider
+		_, err = client.Chat(ctx, messages, "test-model")
+		assert.ErrorIs(t, err, domain.ErrRateLimited)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Accepts an HTTP date", func(t *testing.T) {
+		var calls int32
+		retryAt := time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat)
+		server := rateLimitedServer(retryAt, 10, &calls)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL)
+		_, err := client.Chat(ctx, messages, "test-model")
+
+		var rateLimited *domain.RateLimitError
+		require.ErrorAs(t, err, &rateLimited)
+		assert.InDelta(t, 120, rateLimited.RetryAfter.Seconds(), 2)
+	})
+}
+
+func TestOpenRouterFallbackModels(t *testing.T) {
+	ctx := context.Background()
+	messages := []external.Message{{Role: "user", Content: "2 eggs"}}
+
+	// modelServer answers 429 for rate-limited models and 400 for rejected ones,
+	// and records the model of every request it gets
+	modelServer := func(status map[string]int, models *[]string) *httptest.Server {
+		var mu sync.Mutex
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			model, _ := req["model"].(string)
+
+			mu.Lock()
+			*models = append(*models, model)
+			mu.Unlock()
+
+			if code, ok := status[model]; ok {
+				if code == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "120")
+				}
+				w.WriteHeader(code)
+				_, _ = w.Write([]byte(`{"error": {"message": "unavailable"}}`))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":    "test",
+				"model": model,
+				"choices": []map[string]interface{}{
+					{"index": 0, "message": map[string]string{"role": "assistant", "content": "ok from " + model}, "finish_reason": "stop"},
+				},
+			})
+		}))
+	}
+
+	t.Run("Falls back when the primary model is rate limited", func(t *testing.T) {
+		var models []string
+		server := modelServer(map[string]int{"primary": http.StatusTooManyRequests}, &models)
+		defer server.Close()
+
+		auditor := &recordingAuditor{}
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL).
+			WithAuditor(auditor).
+			WithFallbackModels("fallback")
+
+		start := time.Now()
+		resp, err := client.Chat(ctx, messages, "primary")
+		require.NoError(t, err)
+
+		assert.Equal(t, "fallback", resp.Model)
+		assert.Equal(t, "ok from fallback", resp.Choices[0].Message.Content)
+		assert.Equal(t, []string{"primary", "fallback"}, models)
+		assert.Less(t, time.Since(start), 5*time.Second, "waited for the primary's Retry-After")
+
+		// Each attempt is audited under the model it was sent to
+		entries := auditor.entries
+		require.Len(t, entries, 2)
+		assert.Equal(t, "primary", entries[0].Model)
+		assert.Equal(t, "error", entries[0].Status)
+		assert.Equal(t, "fallback", entries[1].Model)
+		assert.Equal(t, "success", entries[1].Status)
+
+		// While the primary is throttled, requests go straight to the fallback
+		models = nil
+		resp, err = client.Chat(ctx, messages, "primary")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", resp.Model)
+		assert.Equal(t, []string{"fallback"}, models)
+	})
+
+	t.Run("Fails when every model is rate limited", func(t *testing.T) {
+		var models []string
+		server := modelServer(map[string]int{"primary": http.StatusTooManyRequests, "fallback": http.StatusTooManyRequests}, &models)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL).WithFallbackModels("fallback")
+		_, err := client.Chat(ctx, messages, "primary")
+		assert.ErrorIs(t, err, domain.ErrRateLimited)
+		assert.Equal(t, []string{"primary", "fallback"}, models)
+	})
+
+	t.Run("Does not fall back on a rejected request", func(t *testing.T) {
+		var models []string
+		server := modelServer(map[string]int{"primary": http.StatusBadRequest}, &models)
+		defer server.Close()
+
+		client := external.NewOpenRouterClientWithBaseURL("test-key", server.URL).WithFallbackModels("fallback")
+		_, err := client.Chat(ctx, messages, "primary")
+		require.Error(t, err)
+		assert.NotContains(t, models, "fallback")
+	})
+}
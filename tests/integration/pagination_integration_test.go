@@ -0,0 +1,221 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor(t *testing.T) {
+	t.Run("Round-trips through its encoding", func(t *testing.T) {
+		cursor := domain.NewPageCursor(time.Date(2025, 11, 19, 8, 30, 0, 123456000, time.UTC), uuid.New())
+
+		decoded, err := domain.DecodePageCursor(cursor.Encode())
+		require.NoError(t, err)
+		assert.True(t, decoded.CreatedAt.Equal(cursor.CreatedAt))
+		assert.Equal(t, cursor.ID, decoded.ID)
+	})
+
+	t.Run("Empty cursor is the first page", func(t *testing.T) {
+		decoded, err := domain.DecodePageCursor("")
+		require.NoError(t, err)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("Tampered cursors are rejected", func(t *testing.T) {
+		for _, cursor := range []string{"not base64!", "bm9waXBl", "MjAyNXxub3QtYS11dWlk"} {
+			_, err := domain.DecodePageCursor(cursor)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput, cursor)
+		}
+	})
+}
+
+func TestMealCursorPagination(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	mealService := services.NewMealService(mealRepo, postgres.NewFoodRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), nil, postgres.NewTransactor(testDB.DB), nil)
+	user := CreateTestUser(t, testDB.DB, "meal_cursor@example.com")
+	other := CreateTestUser(t, testDB.DB, "meal_cursor_other@example.com")
+
+	start := time.Date(2025, 11, 19, 8, 0, 0, 0, time.UTC)
+	createMeal := func(userID uuid.UUID, createdAt time.Time) *domain.Meal {
+		meal := &domain.Meal{UserID: userID, Name: "Oatmeal", MealType: "breakfast", ConsumedAt: createdAt, TotalCalories: 300, CreatedAt: createdAt}
+		require.NoError(t, mealRepo.Create(ctx, meal))
+		return meal
+	}
+
+	// Two meals share a creation time, so the ID has to break the tie between pages
+	for i := 0; i < 4; i++ {
+		createMeal(user.ID, start.Add(time.Duration(i)*time.Hour))
+	}
+	createMeal(user.ID, start.Add(3*time.Hour))
+	createMeal(other.ID, start.Add(time.Hour))
+
+	t.Run("Pages do not repeat or skip meals when new ones are logged", func(t *testing.T) {
+		seen := map[uuid.UUID]bool{}
+		var inserted []uuid.UUID
+		cursor := ""
+		pages := 0
+		for {
+			page, err := mealService.ListMealsPage(ctx, user.ID.String(), cursor, 2)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page.Meals), 2)
+			for _, meal := range page.Meals {
+				assert.False(t, seen[meal.ID], "meal %s returned twice", meal.ID)
+				assert.Equal(t, user.ID, meal.UserID)
+				seen[meal.ID] = true
+			}
+			pages++
+
+			// A meal logged mid-way lands ahead of the pages already read
+			inserted = append(inserted, createMeal(user.ID, time.Now()).ID)
+
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		assert.Equal(t, 3, pages)
+		assert.Len(t, seen, 5)
+		for _, id := range inserted {
+			assert.False(t, seen[id], "meal logged while paging showed up on a later page")
+		}
+	})
+
+	t.Run("Newest first", func(t *testing.T) {
+		page, err := mealService.ListMealsPage(ctx, user.ID.String(), "", 100)
+		require.NoError(t, err)
+		require.NotEmpty(t, page.Meals)
+		assert.Empty(t, page.NextCursor)
+		for i := 1; i < len(page.Meals); i++ {
+			assert.False(t, page.Meals[i].CreatedAt.After(page.Meals[i-1].CreatedAt))
+		}
+	})
+
+	t.Run("Invalid cursor or limit", func(t *testing.T) {
+		_, err := mealService.ListMealsPage(ctx, user.ID.String(), "garbage", 2)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = mealService.ListMealsPage(ctx, user.ID.String(), "", 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestActivityCursorPagination(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	activityService := services.NewActivityService(activityRepo, postgres.NewUserActionRepository(testDB.DB), newSummaryService(testDB.DB))
+	user := CreateTestUser(t, testDB.DB, "activity_cursor@example.com")
+
+	start := time.Date(2025, 11, 19, 8, 0, 0, 0, time.UTC)
+	createActivity := func(createdAt time.Time) *domain.Activity {
+		activity := &domain.Activity{UserID: user.ID, ActivityType: "running", StartTime: createdAt, CreatedAt: createdAt}
+		require.NoError(t, activityRepo.Create(ctx, activity))
+		return activity
+	}
+	oldest := createActivity(start)
+	middle := createActivity(start.Add(time.Hour))
+	newest := createActivity(start.Add(2 * time.Hour))
+
+	first, err := activityService.ListActivitiesPage(ctx, user.ID.String(), "", 2)
+	require.NoError(t, err)
+	require.Len(t, first.Activities, 2)
+	assert.Equal(t, newest.ID, first.Activities[0].ID)
+	assert.Equal(t, middle.ID, first.Activities[1].ID)
+	require.NotEmpty(t, first.NextCursor)
+
+	// Removing a row already read would pull the next one back a place with an offset
+	require.NoError(t, activityRepo.Delete(ctx, newest.ID))
+	createActivity(time.Now())
+
+	second, err := activityService.ListActivitiesPage(ctx, user.ID.String(), first.NextCursor, 2)
+	require.NoError(t, err)
+	require.Len(t, second.Activities, 1)
+	assert.Equal(t, oldest.ID, second.Activities[0].ID)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestConversationMessagePagination(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "message_cursor@example.com")
+	other := CreateTestUser(t, testDB.DB, "message_cursor_other@example.com")
+
+	conversationRepo := postgres.NewConversationRepository(testDB.DB)
+	conversationService := services.NewConversationService(conversationRepo)
+
+	start := time.Date(2025, 11, 19, 8, 0, 0, 0, time.UTC)
+	conversation := &domain.Conversation{UserID: user.ID, CreatedAt: start, UpdatedAt: start}
+	require.NoError(t, conversationRepo.Create(ctx, conversation))
+	addMessage := func(content string, at time.Time) *domain.Message {
+		message := &domain.Message{ConversationID: conversation.ID, Role: "user", Content: content, CreatedAt: at}
+		require.NoError(t, conversationRepo.AddMessage(ctx, message))
+		return message
+	}
+	var want []uuid.UUID
+	for i, content := range []string{"one", "two", "three"} {
+		want = append(want, addMessage(content, start.Add(time.Duration(i)*time.Minute)).ID)
+	}
+
+	t.Run("Oldest first, and replies added while paging come last", func(t *testing.T) {
+		first, err := conversationService.ListMessages(ctx, user.ID.String(), conversation.ID.String(), "", 2)
+		require.NoError(t, err)
+		require.Len(t, first.Messages, 2)
+		require.NotEmpty(t, first.NextCursor)
+
+		reply := addMessage("four", start.Add(time.Hour))
+
+		second, err := conversationService.ListMessages(ctx, user.ID.String(), conversation.ID.String(), first.NextCursor, 2)
+		require.NoError(t, err)
+		require.Len(t, second.Messages, 2)
+		assert.Empty(t, second.NextCursor)
+
+		got := []uuid.UUID{}
+		for _, message := range append(first.Messages, second.Messages...) {
+			got = append(got, message.ID)
+		}
+		assert.Equal(t, append(want, reply.ID), got)
+	})
+
+	t.Run("Another user's conversation is not found", func(t *testing.T) {
+		_, err := conversationService.ListMessages(ctx, other.ID.String(), conversation.ID.String(), "", 2)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Deleted conversation is not found", func(t *testing.T) {
+		deleted := &domain.Conversation{UserID: user.ID, CreatedAt: start, UpdatedAt: start}
+		require.NoError(t, conversationRepo.Create(ctx, deleted))
+		require.NoError(t, testDB.DB.Model(deleted).Update("deleted_at", time.Now()).Error)
+
+		_, err := conversationService.ListMessages(ctx, user.ID.String(), deleted.ID.String(), "", 2)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Invalid conversation ID or cursor", func(t *testing.T) {
+		_, err := conversationService.ListMessages(ctx, user.ID.String(), "not-a-uuid", "", 2)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = conversationService.ListMessages(ctx, user.ID.String(), conversation.ID.String(), "garbage", 2)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
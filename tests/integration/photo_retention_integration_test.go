@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPhotoStorage is an in-memory photo store keyed by user ID
+type memoryPhotoStorage struct {
+	photos  map[string][]domain.StoredPhoto
+	deleted []string
+}
+
+func (m *memoryPhotoStorage) ListImages(ctx context.Context, userID string) ([]domain.StoredPhoto, error) {
+	return m.photos[userID], nil
+}
+
+func (m *memoryPhotoStorage) DeleteImage(ctx context.Context, objectPath string) error {
+	m.deleted = append(m.deleted, objectPath)
+	return nil
+}
+
+func TestPhotoRetentionCleanup(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "photos@example.com")
+	prefix := user.ID.String() + "/"
+	old := time.Now().AddDate(0, 0, -60)
+
+	// Old photo still attached to a meal
+	attached := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Model(attached).Update("photo_path", prefix+"attached.jpg").Error)
+
+	// Old photo of a live meal that does not record its path
+	CreateTestMeal(t, testDB.DB, user.ID, "breakfast")
+
+	// Old photo shared by a live meal and a deleted one
+	sharedLive := CreateTestMeal(t, testDB.DB, user.ID, "snack")
+	require.NoError(t, testDB.DB.Model(sharedLive).Update("photo_path", prefix+"shared.jpg").Error)
+	sharedDeleted := CreateTestMeal(t, testDB.DB, user.ID, "snack")
+	require.NoError(t, testDB.DB.Model(sharedDeleted).Updates(map[string]interface{}{
+		"photo_path": prefix + "shared.jpg",
+		"deleted_at": time.Now(),
+	}).Error)
+
+	// Old photo whose meal was deleted
+	deletedMeal := CreateTestMeal(t, testDB.DB, user.ID, "dinner")
+	require.NoError(t, testDB.DB.Model(deletedMeal).Updates(map[string]interface{}{
+		"photo_path": prefix + "deleted_meal.jpg",
+		"deleted_at": time.Now(),
+	}).Error)
+
+	newStorage := func() *memoryPhotoStorage {
+		return &memoryPhotoStorage{photos: map[string][]domain.StoredPhoto{
+			user.ID.String(): {
+				{Path: prefix + "attached.jpg", CreatedAt: old},
+				{Path: prefix + "deleted_meal.jpg", CreatedAt: old},
+				{Path: prefix + "unrecorded.jpg", CreatedAt: old},
+				{Path: prefix + "shared.jpg", CreatedAt: old},
+				{Path: prefix + "recent.jpg", CreatedAt: time.Now()},
+			},
+		}}
+	}
+
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	retention := 30 * 24 * time.Hour
+
+	t.Run("Deletes only expired photos of deleted meals", func(t *testing.T) {
+		storage := newStorage()
+		svc := services.NewPhotoRetentionService(userRepo, mealRepo, storage, retention, false)
+
+		report, err := svc.Cleanup(ctx)
+		require.NoError(t, err)
+
+		sort.Strings(storage.deleted)
+		assert.Equal(t, []string{prefix + "deleted_meal.jpg"}, storage.deleted)
+		assert.ElementsMatch(t, storage.deleted, report.Deleted)
+		assert.Equal(t, 5, report.PhotosScanned)
+		assert.False(t, report.DryRun)
+	})
+
+	t.Run("Dry run reports without deleting", func(t *testing.T) {
+		storage := newStorage()
+		svc := services.NewPhotoRetentionService(userRepo, mealRepo, storage, retention, true)
+
+		report, err := svc.Cleanup(ctx)
+		require.NoError(t, err)
+
+		assert.Empty(t, storage.deleted)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, []string{prefix + "deleted_meal.jpg"}, report.Deleted)
+	})
+
+	t.Run("Rejects a non-positive retention", func(t *testing.T) {
+		svc := services.NewPhotoRetentionService(userRepo, mealRepo, newStorage(), 0, false)
+
+		_, err := svc.Cleanup(ctx)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestSupabaseListImages(t *testing.T) {
+	created := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+
+	var offsets []float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/storage/v1/object/list/meal-photos", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "user-1", body["prefix"])
+		offsets = append(offsets, body["offset"].(float64))
+
+		// First page is full, second page is short
+		objects := []map[string]interface{}{}
+		if body["offset"].(float64) == 0 {
+			objects = append(objects, map[string]interface{}{"id": nil, "name": "nested"})
+			for i := 0; i < 99; i++ {
+				objects = append(objects, map[string]interface{}{"id": "obj", "name": "a.jpg", "created_at": created})
+			}
+		} else {
+			objects = append(objects, map[string]interface{}{"id": "obj", "name": "last.jpg", "created_at": created})
+		}
+		json.NewEncoder(w).Encode(objects)
+	}))
+	defer server.Close()
+
+	client := external.NewSupabaseStorageClient(server.URL, "anon")
+	photos, err := client.ListImages(context.Background(), "user-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []float64{0, 100}, offsets)
+	require.Len(t, photos, 100) // folder entry skipped
+	assert.Equal(t, "user-1/a.jpg", photos[0].Path)
+	assert.Equal(t, "user-1/last.jpg", photos[99].Path)
+	assert.True(t, created.Equal(photos[99].CreatedAt))
+}
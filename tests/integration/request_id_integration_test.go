@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditor keeps the audit entries a client records
+type recordingAuditor struct {
+	entries []*domain.LLMAuditEntry
+}
+
+func (a *recordingAuditor) Record(ctx context.Context, entry *domain.LLMAuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	aiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "test-response-id",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]interface{}{"role": "assistant", "content": "unparseable"},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer aiServer.Close()
+
+	auditor := &recordingAuditor{}
+	client := external.NewOpenRouterClientWithBaseURL("test-key", aiServer.URL).WithAuditor(auditor)
+
+	// A handler whose AI call succeeds but whose result it rejects
+	var serviceRequestID string
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.POST("/parse", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		serviceRequestID = requestid.FromContext(ctx)
+
+		messages := []external.Message{external.NewTextMessage("user", "two eggs")}
+		if _, err := client.Chat(ctx, messages, "test-model"); err != nil {
+			c.JSON(http.StatusBadGateway, dto.ErrorResponse{Error: "AI call failed", Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{
+			Error:   "Failed to parse meal",
+			Message: "the AI response could not be parsed",
+			Code:    "PARSE_FAILED",
+		})
+	})
+	router.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	t.Run("Client request ID reaches services, the AI audit log and the error body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse", nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-123")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "req-123", w.Header().Get(middleware.RequestIDHeader))
+		assert.Equal(t, "req-123", serviceRequestID)
+
+		require.Len(t, auditor.entries, 1)
+		require.NotNil(t, auditor.entries[0].RequestID)
+		assert.Equal(t, "req-123", *auditor.entries[0].RequestID)
+
+		var body dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "req-123", body.RequestID)
+		assert.Equal(t, "PARSE_FAILED", body.Code)
+		assert.Equal(t, "Failed to parse meal", body.Error)
+	})
+
+	t.Run("Generated request ID when the client sends none", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/parse", nil))
+
+		generated := w.Header().Get(middleware.RequestIDHeader)
+		require.NotEmpty(t, generated)
+		assert.Equal(t, generated, serviceRequestID)
+
+		var body dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, generated, body.RequestID)
+	})
+
+	t.Run("Successful responses are not changed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status": "ok"}`, w.Body.String())
+	})
+}
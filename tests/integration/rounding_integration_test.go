@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"testing"
+
+	"fitness-tracker/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		places int
+		want   float64
+	}{
+		{"Half rounds up", 0.5, 0, 1},
+		{"Negative half rounds down", -0.5, 0, -1},
+		{"Below half rounds down", 0.49, 0, 0},
+		{"Half at one place", 0.05, 1, 0.1},
+		{"Half at two places", 1.005, 2, 1.01},
+		{"Decimal value, not its binary neighbour", 2.675, 2, 2.68},
+		{"Calories stay whole", 349.5, 0, 350},
+		{"Already rounded", 12.3, 1, 12.3},
+		{"Zero places", 165.44, 0, 165},
+		{"Negative places leave the value", 1.23456, -1, 1.23456},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.RoundHalfAwayFromZero(tt.value, tt.places))
+		})
+	}
+}
+
+func TestRoundNutritionJSON(t *testing.T) {
+	input := []byte(`{
+		"id": "123e4567-e89b-12d3-a456-426614174000",
+		"quantity": 1.255,
+		"total_calories": 412.45,
+		"total_protein": 30.04,
+		"tdee": 2450.55,
+		"food_items": [
+			{"calories": 205.25, "protein": 4.333, "serving_size": 158.125},
+			{"calories": 207.2, "fat": null}
+		],
+		"meal_groups": [{"group": "lunch", "meal_count": 2, "total_fat": 0.05}]
+	}`)
+
+	output, err := utils.RoundNutritionJSON(input, 1)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"id": "123e4567-e89b-12d3-a456-426614174000",
+		"quantity": 1.255,
+		"total_calories": 412.5,
+		"total_protein": 30,
+		"tdee": 2450.6,
+		"food_items": [
+			{"calories": 205.3, "protein": 4.3, "serving_size": 158.125},
+			{"calories": 207.2, "fat": null}
+		],
+		"meal_groups": [{"group": "lunch", "meal_count": 2, "total_fat": 0.1}]
+	}`, string(output))
+
+	_, err = utils.RoundNutritionJSON([]byte(`{"calories":`), 1)
+	assert.Error(t, err)
+}
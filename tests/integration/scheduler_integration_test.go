@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	jobRunRepo := postgres.NewJobRunRepository(testDB.DB)
+
+	runs := 0
+	job := services.Job{
+		Name:     "test_sweep",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			runs++
+			return nil
+		},
+	}
+
+	// Two schedulers stand in for two instances sharing the database
+	first := services.NewScheduler(jobRunRepo)
+	second := services.NewScheduler(jobRunRepo)
+	require.NoError(t, first.Register(job))
+	require.NoError(t, second.Register(job))
+
+	t.Run("Job runs once per interval across instances", func(t *testing.T) {
+		ran, err := first.RunIfDue(ctx, job.Name)
+		require.NoError(t, err)
+		assert.True(t, ran)
+
+		ran, err = second.RunIfDue(ctx, job.Name)
+		require.NoError(t, err)
+		assert.False(t, ran)
+		assert.Equal(t, 1, runs)
+
+		last, err := jobRunRepo.GetLastRun(ctx, job.Name)
+		require.NoError(t, err)
+		require.NotNil(t, last.FinishedAt)
+		assert.Nil(t, last.Error)
+	})
+
+	t.Run("Job runs again once the interval has passed", func(t *testing.T) {
+		require.NoError(t, jobRunRepo.SaveRun(ctx, &domain.JobRun{Name: job.Name, StartedAt: time.Now().Add(-2 * time.Hour)}))
+
+		ran, err := second.RunIfDue(ctx, job.Name)
+		require.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("Job is skipped while another instance holds its lock", func(t *testing.T) {
+		require.NoError(t, jobRunRepo.SaveRun(ctx, &domain.JobRun{Name: job.Name, StartedAt: time.Now().Add(-2 * time.Hour)}))
+
+		release, ok, err := jobRunRepo.TryLock(ctx, job.Name)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ran, err := first.RunIfDue(ctx, job.Name)
+		require.NoError(t, err)
+		assert.False(t, ran)
+
+		release()
+		ran, err = first.RunIfDue(ctx, job.Name)
+		require.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, 3, runs)
+	})
+
+	t.Run("Failures and panics are recorded", func(t *testing.T) {
+		scheduler := services.NewScheduler(jobRunRepo)
+		require.NoError(t, scheduler.Register(services.Job{
+			Name:     "test_failing",
+			Interval: time.Hour,
+			Run:      func(ctx context.Context) error { return errors.New("storage unavailable") },
+		}))
+		require.NoError(t, scheduler.Register(services.Job{
+			Name:     "test_panicking",
+			Interval: time.Hour,
+			Run:      func(ctx context.Context) error { panic("nil map") },
+		}))
+
+		ran, err := scheduler.RunIfDue(ctx, "test_failing")
+		assert.True(t, ran)
+		assert.EqualError(t, err, "storage unavailable")
+		last, err := jobRunRepo.GetLastRun(ctx, "test_failing")
+		require.NoError(t, err)
+		require.NotNil(t, last.Error)
+		assert.Equal(t, "storage unavailable", *last.Error)
+
+		ran, err = scheduler.RunIfDue(ctx, "test_panicking")
+		assert.True(t, ran)
+		assert.ErrorContains(t, err, "panicked")
+	})
+
+	t.Run("Invalid registrations are rejected", func(t *testing.T) {
+		scheduler := services.NewScheduler(jobRunRepo)
+		noop := func(ctx context.Context) error { return nil }
+
+		assert.ErrorIs(t, scheduler.Register(services.Job{Interval: time.Hour, Run: noop}), domain.ErrInvalidInput)
+		assert.ErrorIs(t, scheduler.Register(services.Job{Name: "test_noop", Run: noop}), domain.ErrInvalidInput)
+		assert.ErrorIs(t, scheduler.Register(services.Job{Name: "test_noop", Interval: time.Hour}), domain.ErrInvalidInput)
+		require.NoError(t, scheduler.Register(services.Job{Name: "test_noop", Interval: time.Hour, Run: noop}))
+		assert.ErrorIs(t, scheduler.Register(services.Job{Name: "test_noop", Interval: time.Hour, Run: noop}), domain.ErrConflict)
+
+		_, err := scheduler.RunIfDue(ctx, "unknown")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
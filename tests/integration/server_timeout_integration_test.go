@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/http/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTimeoutOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	slow := func(c *gin.Context) {
+		time.Sleep(300 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	}
+
+	router := gin.New()
+	router.GET("/default", slow)
+	router.GET("/stream", middleware.WriteTimeout(5*time.Second), slow)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	t.Run("Server write timeout cuts off a slow response", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/default")
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.Error(t, err)
+	})
+
+	t.Run("Override lets the slow response finish", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "done", string(body))
+	})
+}
@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/http/middleware"
+	"fitness-tracker/internal/config"
+	"fitness-tracker/internal/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestSlowRequestLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	router := gin.New()
+	router.Use(middleware.Logger(zap.New(core), 20*time.Millisecond))
+	router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/slow", func(c *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	get := func(path string) observer.LoggedEntry {
+		logs.TakeAll()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		return entries[0]
+	}
+
+	t.Run("Fast requests are logged at INFO", func(t *testing.T) {
+		entry := get("/fast")
+		assert.Equal(t, zapcore.InfoLevel, entry.Level)
+		assert.NotContains(t, entry.ContextMap(), "slow")
+	})
+
+	t.Run("Slow requests are tagged and logged at WARN", func(t *testing.T) {
+		entry := get("/slow")
+		assert.Equal(t, zapcore.WarnLevel, entry.Level)
+		assert.Equal(t, true, entry.ContextMap()["slow"])
+		assert.Equal(t, "/slow", entry.ContextMap()["path"])
+	})
+
+	t.Run("A zero threshold turns it off", func(t *testing.T) {
+		router := gin.New()
+		router.Use(middleware.Logger(zap.New(core), 0))
+		router.GET("/slow", func(c *gin.Context) {
+			time.Sleep(30 * time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+
+		logs.TakeAll()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	})
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	queryLog := config.NewQueryLogger(zap.New(core), gormlogger.Warn, 100*time.Millisecond)
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	query := func() (string, int64) { return `SELECT * FROM "foods" WHERE name ILIKE $1`, 3 }
+
+	t.Run("Slow queries are logged with their SQL and duration", func(t *testing.T) {
+		queryLog.Trace(ctx, time.Now().Add(-250*time.Millisecond), query, nil)
+
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, `SELECT * FROM "foods" WHERE name ILIKE $1`, fields["sql"])
+		assert.GreaterOrEqual(t, fields["duration"], 250*time.Millisecond)
+		assert.Equal(t, int64(3), fields["rows"])
+		assert.Equal(t, true, fields["slow"])
+		assert.Equal(t, "req-123", fields["request_id"])
+	})
+
+	t.Run("Fast queries are not logged at the Warn level", func(t *testing.T) {
+		queryLog.Trace(ctx, time.Now(), query, nil)
+		assert.Empty(t, logs.TakeAll())
+	})
+
+	t.Run("Failed queries are errors, missing records are not", func(t *testing.T) {
+		queryLog.Trace(ctx, time.Now(), query, errors.New("connection reset"))
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+
+		queryLog.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+		assert.Empty(t, logs.TakeAll())
+	})
+
+	t.Run("The Info level logs every query at DEBUG", func(t *testing.T) {
+		queryLog.LogMode(gormlogger.Info).Trace(ctx, time.Now(), query, nil)
+		entries := logs.TakeAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	})
+
+	t.Run("A zero threshold turns it off", func(t *testing.T) {
+		config.NewQueryLogger(zap.New(core), gormlogger.Warn, 0).
+			Trace(ctx, time.Now().Add(-time.Hour), query, nil)
+		assert.Empty(t, logs.TakeAll())
+	})
+}
@@ -0,0 +1,822 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/core/ports"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newSummaryService builds a summary service over the test database with the default
+// calorie source priority
+func newSummaryService(db *gorm.DB) ports.SummaryService {
+	return services.NewSummaryService(
+		postgres.NewMealRepository(db),
+		postgres.NewActivityRepository(db),
+		postgres.NewWorkoutRepository(db),
+		postgres.NewUserRepository(db),
+		postgres.NewGoalRepository(db),
+		postgres.NewNutritionTargetRepository(db),
+		postgres.NewMealDistributionRepository(db),
+		postgres.NewMetricRepository(db),
+		nil,
+	)
+}
+
+func TestSummaryAdherence(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	user := CreateTestUser(t, testDB.DB, "adherence@example.com")
+
+	// Calorie target of 500; test meals are 500 kcal each
+	require.NoError(t, testDB.DB.Create(&domain.Goal{
+		UserID:      user.ID,
+		GoalType:    "calories",
+		Description: "Daily calories",
+		TargetValue: 500,
+		Unit:        "kcal",
+		StartDate:   time.Now(),
+		Status:      "active",
+	}).Error)
+
+	logMealAt := func(consumedAt time.Time) {
+		meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+		require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", consumedAt).Error)
+	}
+
+	day1 := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	logMealAt(day1)
+	logMealAt(day2)
+	logMealAt(day2.Add(time.Hour)) // 1000 kcal on day 2
+
+	t.Run("Per-day targets and percentages", func(t *testing.T) {
+		summary, err := summaryService.GetAdherence(ctx, user.ID.String(), day1, day1.AddDate(0, 0, 2))
+		require.NoError(t, err)
+
+		require.Len(t, summary.Days, 3)
+		assert.Equal(t, "2025-11-10", summary.From)
+		assert.Equal(t, "2025-11-12", summary.To)
+		assert.Equal(t, 500.0, summary.Targets.Calories)
+		assert.Equal(t, domain.DefaultDailyTargets.Protein, summary.Targets.Protein)
+		assert.Equal(t, 2, summary.DaysLogged)
+
+		assert.True(t, summary.Days[0].CaloriesMet)
+		assert.False(t, summary.Days[0].ProteinMet)
+		assert.False(t, summary.Days[1].CaloriesMet)
+		assert.Equal(t, 1000.0, summary.Days[1].Totals.Calories)
+		assert.False(t, summary.Days[2].Logged)
+
+		assert.Equal(t, 33.3, summary.Adherence.Calories)
+		assert.Equal(t, 0.0, summary.Adherence.Overall)
+	})
+
+	t.Run("Days follow the user's timezone", func(t *testing.T) {
+		require.NoError(t, testDB.DB.Model(user).Update("timezone", "America/New_York").Error)
+		defer testDB.DB.Model(user).Update("timezone", "UTC")
+
+		// 02:00 UTC on Nov 14 is the evening of Nov 13 in New York
+		logMealAt(time.Date(2025, 11, 14, 2, 0, 0, 0, time.UTC))
+
+		nov13 := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+		summary, err := summaryService.GetAdherence(ctx, user.ID.String(), nov13, nov13.AddDate(0, 0, 1))
+		require.NoError(t, err)
+
+		require.Len(t, summary.Days, 2)
+		assert.Equal(t, "America/New_York", summary.Timezone)
+		assert.True(t, summary.Days[0].Logged)
+		assert.False(t, summary.Days[1].Logged)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		_, err := summaryService.GetAdherence(ctx, user.ID.String(), day2, day1)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = summaryService.GetAdherence(ctx, user.ID.String(), day1.AddDate(-2, 0, 0), day1)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestSummaryRange(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+
+	user := CreateTestUser(t, testDB.DB, "summary_range@example.com")
+
+	day1 := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	// Day 1 has a stored summary; day 2 only has a 500 kcal meal and a weigh-in
+	require.NoError(t, testDB.DB.Create(&domain.DailySummary{
+		UserID:              user.ID,
+		Date:                day1,
+		TotalCalories:       1800,
+		TotalCaloriesBurned: 300,
+	}).Error)
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day2.Add(12*time.Hour)).Error)
+	require.NoError(t, testDB.DB.Create(&domain.Metric{
+		UserID: user.ID, MetricType: "weight", Value: 80.5, Unit: "kg", MeasuredAt: day2.Add(7 * time.Hour),
+	}).Error)
+
+	t.Run("Stored days are reused and missing days computed", func(t *testing.T) {
+		summaries, err := summaryService.GetSummaryRange(ctx, user.ID.String(), day1, day1.AddDate(0, 0, 2))
+		require.NoError(t, err)
+
+		assert.Equal(t, "2025-11-10", summaries.From)
+		assert.Equal(t, "2025-11-12", summaries.To)
+		require.Len(t, summaries.Days, 3)
+
+		assert.Equal(t, 1800.0, summaries.Days[0].TotalCalories)
+		assert.Equal(t, 1500.0, summaries.Days[0].NetCalories)
+
+		assert.Equal(t, "2025-11-11", summaries.Days[1].Date.Format("2006-01-02"))
+		assert.Equal(t, 500.0, summaries.Days[1].TotalCalories)
+		require.NotNil(t, summaries.Days[1].Weight)
+		assert.Equal(t, 80.5, *summaries.Days[1].Weight)
+
+		assert.Equal(t, 0.0, summaries.Days[2].TotalCalories)
+
+		// The computed day is now cached
+		stored, err := metricRepo.GetDailySummary(ctx, user.ID, day2)
+		require.NoError(t, err)
+		assert.Equal(t, 500.0, stored.TotalCalories)
+	})
+
+	t.Run("Days after today are left out", func(t *testing.T) {
+		today := time.Now().UTC()
+		summaries, err := summaryService.GetSummaryRange(ctx, user.ID.String(), today.AddDate(0, 0, -1), today.AddDate(0, 0, 3))
+		require.NoError(t, err)
+
+		require.Len(t, summaries.Days, 2)
+		assert.Equal(t, today.Format("2006-01-02"), summaries.Days[1].Date.Format("2006-01-02"))
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		_, err := summaryService.GetSummaryRange(ctx, user.ID.String(), day2, day1)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = summaryService.GetSummaryRange(ctx, user.ID.String(), day1, day1.AddDate(0, 0, domain.MaxSummaryRangeDays))
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestDailySummaryUpsert(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+
+	user := CreateTestUser(t, testDB.DB, "summary_upsert@example.com")
+	day := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day.Add(12*time.Hour)).Error)
+
+	countRows := func(t *testing.T) int64 {
+		var count int64
+		require.NoError(t, testDB.DB.Model(&domain.DailySummary{}).Where("user_id = ?", user.ID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("Concurrent recomputes of a day store one row", func(t *testing.T) {
+		const workers = 8
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := summaryService.RefreshDailySummary(ctx, user.ID.String(), day.Add(12*time.Hour))
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int64(1), countRows(t))
+		stored, err := metricRepo.GetDailySummary(ctx, user.ID, day)
+		require.NoError(t, err)
+		assert.InDelta(t, 500.0, stored.TotalCalories, 0.01)
+	})
+
+	t.Run("Upserts at different times of the day update the same row", func(t *testing.T) {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+
+		for _, at := range []time.Time{day.Add(9 * time.Hour), time.Date(2025, 11, 10, 23, 30, 0, 0, tokyo)} {
+			require.NoError(t, metricRepo.CreateOrUpdateDailySummary(ctx, &domain.DailySummary{
+				UserID:        user.ID,
+				Date:          at,
+				TotalCalories: 1200,
+			}))
+		}
+
+		assert.Equal(t, int64(1), countRows(t))
+		stored, err := metricRepo.GetDailySummary(ctx, user.ID, day)
+		require.NoError(t, err)
+		assert.InDelta(t, 1200.0, stored.TotalCalories, 0.01)
+	})
+}
+
+func TestDailySummaryEnergyBalance(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	day := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	user := CreateTestUser(t, testDB.DB, "energy@example.com")
+	require.NoError(t, testDB.DB.Model(user).Updates(map[string]interface{}{
+		"weight_kg":      80.0,
+		"height_cm":      180.0,
+		"date_of_birth":  day.AddDate(-30, 0, -1),
+		"gender":         "male",
+		"activity_level": "moderately_active",
+	}).Error)
+
+	// 500 kcal consumed
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day.Add(12*time.Hour)).Error)
+
+	// 200 kcal logged on an activity
+	activity := CreateTestActivity(t, testDB.DB, user.ID, "running")
+	require.NoError(t, testDB.DB.Model(activity).Update("start_time", day.Add(7*time.Hour)).Error)
+
+	// 60 minute workout without a logged burn: 5 MET * 80 kg * 1 h = 400 kcal
+	duration := 60
+	require.NoError(t, testDB.DB.Create(&domain.Workout{
+		UserID:          user.ID,
+		Name:            "Upper body",
+		StartTime:       day.Add(18 * time.Hour),
+		DurationMinutes: &duration,
+	}).Error)
+
+	t.Run("Burned includes activities and workout estimates", func(t *testing.T) {
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 500.0, summary.TotalCalories, 0.01)
+		assert.InDelta(t, 600.0, summary.TotalCaloriesBurned, 0.01)
+		assert.Equal(t, 90, summary.TotalExerciseMinutes)
+		assert.InDelta(t, -100.0, summary.NetCalories, 0.01)
+	})
+
+	t.Run("Workout estimates count cardio sets", func(t *testing.T) {
+		cardioUser := CreateTestUser(t, testDB.DB, "energy_cardio@example.com")
+		require.NoError(t, testDB.DB.Model(cardioUser).Update("weight_kg", 80.0).Error)
+		rowing := CreateTestExercise(t, testDB.DB, "Summary Rowing", "cardio")
+
+		workout := &domain.Workout{UserID: cardioUser.ID, Name: "Rowing", StartTime: day.Add(18 * time.Hour), DurationMinutes: &duration}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+		workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: rowing.ID, OrderIndex: 1}
+		require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+		require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{WorkoutExerciseID: workoutExercise.ID, SetNumber: 1, DurationSeconds: intPtr(900)}).Error)
+
+		// Same estimate as finishing the workout: 8 MET * 80 kg * 0.25 h + 5 MET * 80 kg * 0.75 h = 460 kcal
+		summary, err := summaryService.GetDailySummary(ctx, cardioUser.ID.String(), day)
+		require.NoError(t, err)
+		assert.InDelta(t, 460.0, summary.TotalCaloriesBurned, 0.01)
+	})
+
+	t.Run("Energy balance uses TDEE from the profile", func(t *testing.T) {
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		// BMR = 10*80 + 6.25*180 - 5*30 + 5 = 1780; moderately active = 1.55
+		require.NotNil(t, summary.TDEE)
+		assert.InDelta(t, 2759.0, *summary.TDEE, 0.01)
+		require.NotNil(t, summary.EnergyBalance)
+		assert.InDelta(t, 500.0-2759.0, *summary.EnergyBalance, 0.01)
+	})
+
+	t.Run("Energy balance is omitted for an incomplete profile", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "energy_incomplete@example.com")
+
+		summary, err := summaryService.GetDailySummary(ctx, other.ID.String(), day)
+		require.NoError(t, err)
+		assert.Nil(t, summary.TDEE)
+		assert.Nil(t, summary.EnergyBalance)
+		assert.Zero(t, summary.NetCalories)
+	})
+}
+
+func TestDynamicCalorieTarget(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	targetRepo := postgres.NewNutritionTargetRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		postgres.NewGoalRepository(testDB.DB),
+		targetRepo,
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	day := time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC)
+	user := CreateTestUser(t, testDB.DB, "dynamic_target@example.com")
+	require.NoError(t, targetRepo.Upsert(ctx, &domain.NutritionTarget{
+		UserID: user.ID, Calories: 2000, Protein: 150, Carbohydrates: 200, Fat: 70,
+	}))
+
+	// 500 kcal consumed, 200 kcal burned on an activity and 400 kcal on a logged workout
+	meal := CreateTestMeal(t, testDB.DB, user.ID, "lunch")
+	require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day.Add(12*time.Hour)).Error)
+	activity := CreateTestActivity(t, testDB.DB, user.ID, "running")
+	require.NoError(t, testDB.DB.Model(activity).Update("start_time", day.Add(7*time.Hour)).Error)
+	burned := 400.0
+	require.NoError(t, testDB.DB.Create(&domain.Workout{
+		UserID:         user.ID,
+		Name:           "Intervals",
+		StartTime:      day.Add(18 * time.Hour),
+		CaloriesBurned: &burned,
+	}).Error)
+
+	setMode := func(t *testing.T, mode string, fraction *float64) {
+		require.NoError(t, testDB.DB.Model(user).Updates(map[string]interface{}{
+			"calorie_target_mode":       mode,
+			"exercise_calorie_fraction": fraction,
+		}).Error)
+	}
+
+	t.Run("Static target ignores exercise", func(t *testing.T) {
+		setMode(t, domain.CalorieTargetStatic, nil)
+
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		require.NotNil(t, summary.CalorieTarget)
+		assert.InDelta(t, 2000.0, *summary.CalorieTarget, 0.01)
+		assert.Zero(t, summary.ExerciseCaloriesAdded)
+		require.NotNil(t, summary.CaloriesRemaining)
+		assert.InDelta(t, 1500.0, *summary.CaloriesRemaining, 0.01)
+	})
+
+	t.Run("Dynamic target adds half of the burn by default", func(t *testing.T) {
+		setMode(t, domain.CalorieTargetDynamic, nil)
+
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 600.0, summary.TotalCaloriesBurned, 0.01)
+		assert.InDelta(t, 300.0, summary.ExerciseCaloriesAdded, 0.01)
+		require.NotNil(t, summary.CalorieTarget)
+		assert.InDelta(t, 2300.0, *summary.CalorieTarget, 0.01)
+		require.NotNil(t, summary.CaloriesRemaining)
+		assert.InDelta(t, 1800.0, *summary.CaloriesRemaining, 0.01)
+	})
+
+	t.Run("Dynamic target uses the chosen fraction", func(t *testing.T) {
+		full := 1.0
+		setMode(t, domain.CalorieTargetDynamic, &full)
+
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 600.0, summary.ExerciseCaloriesAdded, 0.01)
+		require.NotNil(t, summary.CalorieTarget)
+		assert.InDelta(t, 2600.0, *summary.CalorieTarget, 0.01)
+	})
+
+	t.Run("Remaining goes negative once over the target", func(t *testing.T) {
+		summary := &domain.DailySummary{TotalCalories: 2500, TotalCaloriesBurned: 200}
+		summary.ApplyCalorieTarget(2000, &domain.User{CalorieTargetMode: domain.CalorieTargetDynamic})
+
+		require.NotNil(t, summary.CaloriesRemaining)
+		assert.InDelta(t, -400.0, *summary.CaloriesRemaining, 0.01)
+	})
+}
+
+func TestDailySummaryOverlappingBurn(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	newSummaryService := func(priority []string) ports.SummaryService {
+		return services.NewSummaryService(
+			postgres.NewMealRepository(testDB.DB),
+			postgres.NewActivityRepository(testDB.DB),
+			postgres.NewWorkoutRepository(testDB.DB),
+			postgres.NewUserRepository(testDB.DB),
+			postgres.NewGoalRepository(testDB.DB),
+			postgres.NewNutritionTargetRepository(testDB.DB),
+			postgres.NewMealDistributionRepository(testDB.DB),
+			postgres.NewMetricRepository(testDB.DB),
+			priority,
+		)
+	}
+
+	day := time.Date(2025, 11, 14, 0, 0, 0, 0, time.UTC)
+	user := CreateTestUser(t, testDB.DB, "overlapping_burn@example.com")
+	require.NoError(t, testDB.DB.Model(user).Update("weight_kg", 80.0).Error)
+
+	// A watch recorded a 07:00-08:00 run at 600 kcal
+	runEnd := day.Add(8 * time.Hour)
+	runCalories := 600.0
+	device := domain.CalorieSourceDevice
+	run := &domain.Activity{
+		UserID:         user.ID,
+		ActivityType:   "running",
+		StartTime:      day.Add(7 * time.Hour),
+		EndTime:        &runEnd,
+		CaloriesBurned: &runCalories,
+		CalorieSource:  &device,
+	}
+	require.NoError(t, testDB.DB.Create(run).Error)
+
+	// A 07:30-08:30 workout without a logged burn: 5 MET * 80 kg * 1 h = 400 kcal, half overlapping the run
+	workoutEnd := day.Add(8*time.Hour + 30*time.Minute)
+	workout := &domain.Workout{
+		UserID:    user.ID,
+		Name:      "Intervals",
+		StartTime: day.Add(7*time.Hour + 30*time.Minute),
+		EndTime:   &workoutEnd,
+	}
+	require.NoError(t, testDB.DB.Create(workout).Error)
+
+	t.Run("Device burn wins the overlapping time", func(t *testing.T) {
+		summary, err := newSummaryService(nil).GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		// 600 from the run, plus the half of the estimate outside it
+		assert.InDelta(t, 800.0, summary.TotalCaloriesBurned, 0.01)
+		assert.InDelta(t, -800.0, summary.NetCalories, 0.01)
+	})
+
+	t.Run("Debug field explains the decisions", func(t *testing.T) {
+		summary, err := newSummaryService(nil).GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+		require.Len(t, summary.BurnDebug, 2)
+
+		decisions := make(map[uuid.UUID]domain.BurnDecision)
+		for _, decision := range summary.BurnDebug {
+			decisions[decision.ID] = decision
+		}
+
+		runDecision := decisions[run.ID]
+		assert.Equal(t, domain.CalorieSourceDevice, runDecision.Source)
+		assert.InDelta(t, 600.0, runDecision.CountedCalories, 0.01)
+		assert.Empty(t, runDecision.OverlapsWith)
+
+		workoutDecision := decisions[workout.ID]
+		assert.Equal(t, domain.BurnKindWorkout, workoutDecision.Kind)
+		assert.Equal(t, domain.CalorieSourceEstimate, workoutDecision.Source)
+		assert.InDelta(t, 400.0, workoutDecision.Calories, 0.01)
+		assert.InDelta(t, 200.0, workoutDecision.CountedCalories, 0.01)
+		assert.InDelta(t, 30.0, workoutDecision.OverlapMinutes, 0.01)
+		assert.Equal(t, []uuid.UUID{run.ID}, workoutDecision.OverlapsWith)
+	})
+
+	t.Run("Priority is configurable", func(t *testing.T) {
+		priority := []string{domain.CalorieSourceEstimate, domain.CalorieSourceDevice, domain.CalorieSourceManual}
+		summary, err := newSummaryService(priority).GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		// 400 from the estimate, plus the half of the run outside it
+		assert.InDelta(t, 700.0, summary.TotalCaloriesBurned, 0.01)
+	})
+
+	t.Run("Sessions covering the same time count once", func(t *testing.T) {
+		start := day.Add(12 * time.Hour)
+		manual := domain.BurnEntry{ID: uuid.New(), Start: start, End: start.Add(time.Hour), Calories: 500, Source: domain.CalorieSourceManual}
+		tracked := domain.BurnEntry{ID: uuid.New(), Start: start, End: start.Add(time.Hour), Calories: 450, Source: domain.CalorieSourceDevice}
+		// No duration, logged during the tracked hour
+		instant := domain.BurnEntry{ID: uuid.New(), Start: start.Add(10 * time.Minute), End: start.Add(10 * time.Minute), Calories: 100, Source: domain.CalorieSourceManual}
+
+		total, decisions := domain.DeduplicateBurn([]domain.BurnEntry{manual, tracked, instant}, domain.DefaultCalorieSourcePriority)
+		assert.InDelta(t, 450.0, total, 0.01)
+		require.Len(t, decisions, 3)
+		assert.Zero(t, decisions[0].CountedCalories)
+		assert.InDelta(t, 450.0, decisions[1].CountedCalories, 0.01)
+		assert.Zero(t, decisions[2].CountedCalories)
+	})
+}
+
+func TestDailySummaryCustomMealTypes(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	day := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	user := CreateTestUser(t, testDB.DB, "custom_meals@example.com")
+
+	// 500 kcal each
+	for i, mealType := range []string{"breakfast", "second breakfast", "pre-workout", "lunch", "midnight feast"} {
+		meal := CreateTestMeal(t, testDB.DB, user.ID, mealType)
+		require.NoError(t, testDB.DB.Model(meal).Update("consumed_at", day.Add(time.Duration(7+i*3)*time.Hour)).Error)
+	}
+
+	summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+	require.NoError(t, err)
+
+	t.Run("Custom types count towards the day's totals", func(t *testing.T) {
+		assert.InDelta(t, 2500.0, summary.TotalCalories, 0.01)
+		assert.InDelta(t, 150.0, summary.TotalProtein, 0.01)
+	})
+
+	t.Run("Custom types are grouped under canonical types", func(t *testing.T) {
+		require.Len(t, summary.MealGroups, 4)
+
+		assert.Equal(t, "breakfast", summary.MealGroups[0].Group)
+		assert.ElementsMatch(t, []string{"breakfast", "second breakfast"}, summary.MealGroups[0].MealTypes)
+		assert.Equal(t, 2, summary.MealGroups[0].MealCount)
+		assert.InDelta(t, 1000.0, summary.MealGroups[0].TotalCalories, 0.01)
+
+		assert.Equal(t, "lunch", summary.MealGroups[1].Group)
+		assert.Equal(t, "snack", summary.MealGroups[2].Group)
+		assert.Equal(t, []string{"pre-workout"}, summary.MealGroups[2].MealTypes)
+		assert.Equal(t, "other", summary.MealGroups[3].Group)
+		assert.Equal(t, []string{"midnight feast"}, summary.MealGroups[3].MealTypes)
+
+		var groupCalories float64
+		for _, group := range summary.MealGroups {
+			groupCalories += group.TotalCalories
+		}
+		assert.InDelta(t, summary.TotalCalories, groupCalories, 0.01)
+	})
+}
+
+func TestDailySummaryMicronutrients(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	mealRepo := postgres.NewMealRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		mealRepo,
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		postgres.NewMetricRepository(testDB.DB),
+		nil,
+	)
+
+	day := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	user := CreateTestUser(t, testDB.DB, "micronutrients@example.com")
+
+	// Per 100 g serving
+	soup := CreateTestFood(t, testDB.DB, "Tomato Soup", 80)
+	cheese := CreateTestFood(t, testDB.DB, "Cheddar", 400)
+	apple := CreateTestFood(t, testDB.DB, "Apple", 52) // lists neither
+	require.NoError(t, testDB.DB.Model(soup).Updates(map[string]interface{}{"sodium": 700.0, "saturated_fat": 0.5}).Error)
+	require.NoError(t, testDB.DB.Model(cheese).Updates(map[string]interface{}{"sodium": 650.0, "saturated_fat": 20.0}).Error)
+
+	logMeal := func(mealType string, hour int, items ...domain.MealFoodItem) *domain.Meal {
+		meal := &domain.Meal{
+			UserID:     user.ID,
+			Name:       mealType,
+			MealType:   mealType,
+			ConsumedAt: day.Add(time.Duration(hour) * time.Hour),
+			FoodItems:  items,
+		}
+		require.NoError(t, mealRepo.Create(ctx, meal))
+		return meal
+	}
+
+	// Soup: 300 g = 2100 mg sodium, 1.5 g saturated fat; cheese: 50 g = 325 mg, 10 g
+	lunch := logMeal("lunch", 12,
+		domain.MealFoodItem{FoodID: soup.ID, Quantity: 300, Unit: "g"},
+		domain.MealFoodItem{FoodID: cheese.ID, Quantity: 50, Unit: "g"},
+	)
+	// Cheese: 1 serving = 650 mg, 20 g; apple adds nothing
+	logMeal("snack", 16,
+		domain.MealFoodItem{FoodID: cheese.ID, Quantity: 1, Unit: "serving"},
+		domain.MealFoodItem{FoodID: apple.ID, Quantity: 150, Unit: "g"},
+	)
+	// The next day does not count
+	logMeal("breakfast", 32, domain.MealFoodItem{FoodID: soup.ID, Quantity: 100, Unit: "g"})
+
+	t.Run("Meals total their foods' micronutrients", func(t *testing.T) {
+		assert.InDelta(t, 2425.0, lunch.TotalSodium, 0.01)
+		assert.InDelta(t, 11.5, lunch.TotalSaturatedFat, 0.01)
+
+		items, err := mealRepo.GetFoodItems(ctx, lunch.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		for _, item := range items {
+			if item.FoodID == soup.ID {
+				assert.InDelta(t, 700.0, item.Snapshot.Sodium, 0.01)
+				assert.InDelta(t, 2100.0, item.Sodium, 0.01)
+			}
+		}
+	})
+
+	t.Run("Summary totals micronutrients across the day's meals", func(t *testing.T) {
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 3075.0, summary.TotalSodium, 0.01)
+		assert.InDelta(t, 31.5, summary.TotalSaturatedFat, 0.01)
+		assert.Empty(t, summary.NutrientLimits)
+	})
+
+	t.Run("Limit goals are compared with the day's intake", func(t *testing.T) {
+		for goalType, target := range map[string]float64{"sodium": 2300, "saturated_fat": 40} {
+			require.NoError(t, testDB.DB.Create(&domain.Goal{
+				UserID:      user.ID,
+				GoalType:    goalType,
+				Description: "Daily limit",
+				TargetValue: target,
+				Unit:        domain.NutrientLimitUnits[goalType],
+				StartDate:   day,
+				Status:      "active",
+			}).Error)
+		}
+
+		summary, err := summaryService.GetDailySummary(ctx, user.ID.String(), day)
+		require.NoError(t, err)
+		require.Len(t, summary.NutrientLimits, 2)
+
+		limits := make(map[string]domain.NutrientLimit)
+		for _, limit := range summary.NutrientLimits {
+			limits[limit.Nutrient] = limit
+		}
+		assert.Equal(t, "mg", limits["sodium"].Unit)
+		assert.InDelta(t, -775.0, limits["sodium"].Remaining, 0.01)
+		assert.True(t, limits["sodium"].Exceeded)
+		assert.Equal(t, "g", limits["saturated_fat"].Unit)
+		assert.InDelta(t, 8.5, limits["saturated_fat"].Remaining, 0.01)
+		assert.False(t, limits["saturated_fat"].Exceeded)
+	})
+}
+
+func TestMealTypeLabels(t *testing.T) {
+	t.Run("Normalizes custom labels", func(t *testing.T) {
+		mealType, err := domain.NormalizeMealType("  Second   Breakfast ")
+		require.NoError(t, err)
+		assert.Equal(t, "second breakfast", mealType)
+	})
+
+	t.Run("Rejects empty and overlong labels", func(t *testing.T) {
+		_, err := domain.NormalizeMealType("   ")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		_, err = domain.NormalizeMealType(strings.Repeat("a", domain.MaxMealTypeLength+1))
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Maps labels to canonical groups", func(t *testing.T) {
+		cases := map[string]string{
+			"breakfast":         "breakfast",
+			"Second Breakfast":  "breakfast",
+			"brunch":            "breakfast",
+			"lunch":             "lunch",
+			"supper":            "dinner",
+			"pre-workout":       "snack",
+			"post-dinner snack": "snack",
+			"midnight feast":    "other",
+		}
+		for label, group := range cases {
+			assert.Equal(t, group, domain.MealTypeGroup(label), label)
+		}
+	})
+}
+
+func TestDailySummaryRefreshOnBurnChanges(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	summaryService := newSummaryService(testDB.DB)
+	activityService := services.NewActivityService(postgres.NewActivityRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), summaryService)
+	workoutService := services.NewWorkoutService(postgres.NewWorkoutRepository(testDB.DB), postgres.NewUserRepository(testDB.DB), postgres.NewUserActionRepository(testDB.DB), summaryService)
+	user := CreateTestUser(t, testDB.DB, "summary_burn_refresh@example.com")
+
+	day := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	storedBurn := func(date time.Time) float64 {
+		summary, err := metricRepo.GetDailySummary(ctx, user.ID, date)
+		require.NoError(t, err)
+		return summary.TotalCaloriesBurned
+	}
+
+	t.Run("Backdated activity updates the days it moves between", func(t *testing.T) {
+		calories := 300.0
+		activity, err := activityService.CreateActivity(ctx, user.ID.String(), &domain.Activity{
+			ActivityType:   "running",
+			StartTime:      day.Add(7 * time.Hour),
+			CaloriesBurned: &calories,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 300.0, storedBurn(day), 0.01)
+
+		nextDay := day.AddDate(0, 0, 1)
+		_, err = activityService.UpdateActivity(ctx, activity.ID.String(), map[string]interface{}{
+			"start_time": nextDay.Add(7 * time.Hour).Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		assert.Zero(t, storedBurn(day))
+		assert.InDelta(t, 300.0, storedBurn(nextDay), 0.01)
+
+		require.NoError(t, activityService.DeleteActivity(ctx, activity.ID.String()))
+		assert.Zero(t, storedBurn(nextDay))
+	})
+
+	t.Run("Deleted workout leaves its day", func(t *testing.T) {
+		workoutDay := day.AddDate(0, 0, 3)
+		calories := 250.0
+		workout := &domain.Workout{UserID: user.ID, Name: "Legs", StartTime: workoutDay.Add(18 * time.Hour), CaloriesBurned: &calories}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+		_, err := summaryService.RefreshDailySummary(ctx, user.ID.String(), workout.StartTime)
+		require.NoError(t, err)
+		assert.InDelta(t, 250.0, storedBurn(workoutDay), 0.01)
+
+		require.NoError(t, workoutService.DeleteWorkout(ctx, user.ID, workout.ID))
+		assert.Zero(t, storedBurn(workoutDay))
+	})
+}
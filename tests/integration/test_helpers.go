@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"fitness-tracker/internal/config"
 	"fitness-tracker/internal/core/domain"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,7 +15,6 @@ import (
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"golang.org/x/crypto/bcrypt"
-	pgdriver "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -43,12 +43,26 @@ func SetupTestDB(t *testing.T) *TestDatabase {
 	)
 	require.NoError(t, err, "Failed to start PostgreSQL container")
 
-	// Get connection string
-	connStr, err := pgContainer.ConnectionString(ctx, "sslmode=disable")
-	require.NoError(t, err, "Failed to get connection string")
-
-	// Connect to database
-	db, err := gorm.Open(pgdriver.Open(connStr), &gorm.Config{})
+	// Get container address
+	host, err := pgContainer.Host(ctx)
+	require.NoError(t, err, "Failed to get container host")
+	port, err := pgContainer.MappedPort(ctx, "5432/tcp")
+	require.NoError(t, err, "Failed to get container port")
+
+	// Connect through the same path the application uses
+	db, err := config.OpenDB(&config.DatabaseConfig{
+		Host:            host,
+		Port:            port.Int(),
+		User:            "test_user",
+		Password:        "test_password",
+		DBName:          "test_fitness_tracker",
+		SSLMode:         "disable",
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 5 * time.Minute,
+		ConnMaxIdleTime: 2 * time.Minute,
+		PrepareStmt:     true,
+	}, nil)
 	require.NoError(t, err, "Failed to connect to test database")
 
 	// Run migrations
@@ -86,25 +100,42 @@ func TeardownTestDB(t *testing.T, testDB *TestDatabase) {
 
 // runMigrations applies all database migrations
 func runMigrations(db *gorm.DB) error {
+	// Exercise duplicate detection compares names by trigram similarity
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return err
+	}
+
 	// Auto-migrate all domain models
 	return db.AutoMigrate(
 		&domain.User{},
+		&domain.UserToken{},
 		&domain.Food{},
 		&domain.ServingUnit{},
 		&domain.FoodIngredient{},
 		&domain.FoodServingConversion{},
+		&domain.FoodRevision{},
+		&domain.FoodStar{},
 		&domain.Meal{},
 		&domain.MealFoodItem{},
 		&domain.Activity{},
+		&domain.RoutePoint{},
 		&domain.Exercise{},
 		&domain.Workout{},
 		&domain.WorkoutExercise{},
 		&domain.WorkoutSet{},
+		&domain.PlannedSet{},
+		&domain.WorkoutPause{},
 		&domain.Metric{},
 		&domain.DailySummary{},
 		&domain.Goal{},
+		&domain.NutritionTarget{},
+		&domain.MealDistribution{},
+		&domain.Achievement{},
 		&domain.Conversation{},
 		&domain.Message{},
+		&domain.LLMAuditEntry{},
+		&domain.UserAction{},
+		&domain.JobRun{},
 	)
 }
 
@@ -216,8 +247,10 @@ func CreateTestActivity(t *testing.T, db *gorm.DB, userID uuid.UUID, activityTyp
 // CleanupTestData removes all test data from the database
 func CleanupTestData(t *testing.T, db *gorm.DB) {
 	// Delete in reverse order of dependencies
+	db.Exec("TRUNCATE TABLE job_runs CASCADE")
 	db.Exec("TRUNCATE TABLE messages CASCADE")
 	db.Exec("TRUNCATE TABLE conversations CASCADE")
+	db.Exec("TRUNCATE TABLE nutrition_targets CASCADE")
 	db.Exec("TRUNCATE TABLE goals CASCADE")
 	db.Exec("TRUNCATE TABLE daily_summaries CASCADE")
 	db.Exec("TRUNCATE TABLE metrics CASCADE")
@@ -227,11 +260,13 @@ func CleanupTestData(t *testing.T, db *gorm.DB) {
 	db.Exec("TRUNCATE TABLE exercises CASCADE")
 	db.Exec("TRUNCATE TABLE activities CASCADE")
 	db.Exec("TRUNCATE TABLE meal_food_items CASCADE")
+	db.Exec("TRUNCATE TABLE food_stars CASCADE")
 	db.Exec("TRUNCATE TABLE meals CASCADE")
 	db.Exec("TRUNCATE TABLE food_serving_conversions CASCADE")
 	db.Exec("TRUNCATE TABLE food_ingredients CASCADE")
 	db.Exec("TRUNCATE TABLE serving_units CASCADE")
 	db.Exec("TRUNCATE TABLE foods CASCADE")
+	db.Exec("TRUNCATE TABLE user_tokens CASCADE")
 	db.Exec("TRUNCATE TABLE users CASCADE")
 }
 
@@ -0,0 +1,203 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoLastAction(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	actionRepo := postgres.NewUserActionRepository(testDB.DB)
+	summaryService := services.NewSummaryService(
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		postgres.NewGoalRepository(testDB.DB),
+		postgres.NewNutritionTargetRepository(testDB.DB),
+		postgres.NewMealDistributionRepository(testDB.DB),
+		metricRepo,
+		nil,
+	)
+	metricService := services.NewMetricService(metricRepo, userRepo, actionRepo, summaryService)
+	undoService := services.NewUndoService(
+		actionRepo,
+		postgres.NewMealRepository(testDB.DB),
+		postgres.NewActivityRepository(testDB.DB),
+		metricRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		summaryService,
+	)
+
+	user := CreateTestUser(t, testDB.DB, "undo@example.com")
+	userID := user.ID.String()
+
+	t.Run("Undoing a create removes the record", func(t *testing.T) {
+		metric, err := metricService.LogMetric(ctx, userID, "water", 500, "ml", time.Now())
+		require.NoError(t, err)
+
+		result, err := undoService.UndoLastAction(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ActionCreate, result.Action)
+		assert.Equal(t, domain.ActionEntityMetric, result.EntityType)
+		assert.Equal(t, metric.ID, result.EntityID)
+
+		_, err = metricRepo.GetByID(ctx, metric.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Only the latest action can be undone", func(t *testing.T) {
+		_, err := undoService.UndoLastAction(ctx, userID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Undoing a delete restores the record", func(t *testing.T) {
+		metric, err := metricService.LogMetric(ctx, userID, "water", 750, "ml", time.Now())
+		require.NoError(t, err)
+		require.NoError(t, metricService.DeleteMetric(ctx, userID, metric.ID.String()))
+
+		_, err = metricRepo.GetByID(ctx, metric.ID)
+		require.ErrorIs(t, err, domain.ErrNotFound)
+
+		result, err := undoService.UndoLastAction(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ActionDelete, result.Action)
+
+		restored, err := metricRepo.GetByID(ctx, metric.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 750.0, restored.Value)
+	})
+
+	t.Run("Actions outside the undo window are kept", func(t *testing.T) {
+		metric, err := metricService.LogMetric(ctx, userID, "water", 250, "ml", time.Now())
+		require.NoError(t, err)
+		require.NoError(t, testDB.DB.Model(&domain.UserAction{}).
+			Where("entity_id = ?", metric.ID).
+			Update("created_at", time.Now().Add(-domain.UndoWindow-time.Minute)).Error)
+
+		_, err = undoService.UndoLastAction(ctx, userID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = metricRepo.GetByID(ctx, metric.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Actions of other users are not undone", func(t *testing.T) {
+		other := CreateTestUser(t, testDB.DB, "undo_other@example.com")
+		_, err := metricService.LogMetric(ctx, userID, "water", 300, "ml", time.Now())
+		require.NoError(t, err)
+
+		_, err = undoService.UndoLastAction(ctx, other.ID.String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestUserActionUndoable(t *testing.T) {
+	now := time.Now()
+	undoID := uuid.New()
+
+	assert.True(t, (&domain.UserAction{Action: domain.ActionCreate, CreatedAt: now.Add(-time.Minute)}).Undoable(now))
+	assert.True(t, (&domain.UserAction{Action: domain.ActionDelete, CreatedAt: now.Add(-domain.UndoWindow)}).Undoable(now))
+	assert.False(t, (&domain.UserAction{Action: domain.ActionCreate, CreatedAt: now.Add(-domain.UndoWindow - time.Second)}).Undoable(now))
+	assert.False(t, (&domain.UserAction{Action: domain.ActionUndo, UndoesID: &undoID, CreatedAt: now}).Undoable(now))
+}
+
+func TestUndoRefreshesDerivedData(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB.DB)
+	metricRepo := postgres.NewMetricRepository(testDB.DB)
+	actionRepo := postgres.NewUserActionRepository(testDB.DB)
+	activityRepo := postgres.NewActivityRepository(testDB.DB)
+	summaryService := newSummaryService(testDB.DB)
+	metricService := services.NewMetricService(metricRepo, userRepo, actionRepo, summaryService)
+	activityService := services.NewActivityService(activityRepo, actionRepo, summaryService)
+	undoService := services.NewUndoService(
+		actionRepo,
+		postgres.NewMealRepository(testDB.DB),
+		activityRepo,
+		metricRepo,
+		postgres.NewWorkoutRepository(testDB.DB),
+		userRepo,
+		summaryService,
+	)
+
+	user := CreateTestUser(t, testDB.DB, "undo_derived@example.com")
+	userID := user.ID.String()
+	require.NoError(t, testDB.DB.Model(user).Update("height_cm", 180.0).Error)
+
+	day1 := time.Date(2025, 11, 10, 8, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	t.Run("Undoing a weight entry reverts the values derived from it", func(t *testing.T) {
+		_, err := metricService.LogMetric(ctx, userID, "weight", 80.0, "kg", day1)
+		require.NoError(t, err)
+		_, err = metricService.LogMetric(ctx, userID, "bmi", 27.8, "kg/m2", day2)
+		require.NoError(t, err)
+		_, err = metricService.LogMetric(ctx, userID, "weight", 90.0, "kg", day2)
+		require.NoError(t, err)
+
+		stored, err := metricRepo.GetDailySummary(ctx, user.ID, day2)
+		require.NoError(t, err)
+		require.NotNil(t, stored.Weight)
+		assert.InDelta(t, 90.0, *stored.Weight, 0.01)
+
+		_, err = undoService.UndoLastAction(ctx, userID)
+		require.NoError(t, err)
+
+		// BMI logged with the weight goes with it
+		bmis, err := metricService.GetMetricTrend(ctx, userID, "bmi", nil, nil)
+		require.NoError(t, err)
+		assert.Empty(t, bmis)
+
+		// Profile weight falls back to the remaining entry
+		refreshed, err := userRepo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, refreshed.WeightKg)
+		assert.Equal(t, 80.0, *refreshed.WeightKg)
+
+		stored, err = metricRepo.GetDailySummary(ctx, user.ID, day2)
+		require.NoError(t, err)
+		assert.Nil(t, stored.Weight)
+	})
+
+	t.Run("Undoing an activity clears its burn from the day", func(t *testing.T) {
+		calories := 300.0
+		_, err := activityService.CreateActivity(ctx, userID, &domain.Activity{
+			ActivityType:   "running",
+			StartTime:      day1.Add(-time.Hour),
+			CaloriesBurned: &calories,
+		})
+		require.NoError(t, err)
+
+		stored, err := metricRepo.GetDailySummary(ctx, user.ID, day1)
+		require.NoError(t, err)
+		assert.InDelta(t, 300.0, stored.TotalCaloriesBurned, 0.01)
+
+		_, err = undoService.UndoLastAction(ctx, userID)
+		require.NoError(t, err)
+
+		stored, err = metricRepo.GetDailySummary(ctx, user.ID, day1)
+		require.NoError(t, err)
+		assert.Zero(t, stored.TotalCaloriesBurned)
+	})
+}
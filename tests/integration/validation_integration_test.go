@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-tracker/internal/adapters/http/dto"
+	"fitness-tracker/internal/adapters/http/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationDetails(t *testing.T) {
+	validate := middleware.NewValidator()
+
+	t.Run("Keys fields by JSON name with readable messages", func(t *testing.T) {
+		err := validate.Struct(dto.RegisterRequest{Email: "not-an-email", Password: "short"})
+		require.Error(t, err)
+
+		assert.Equal(t, map[string]string{
+			"email":    "must be a valid email address",
+			"password": "must be at least 8 characters",
+			"name":     "is required",
+		}, middleware.ValidationDetails(err))
+	})
+
+	t.Run("Keys nested fields by path", func(t *testing.T) {
+		err := validate.Struct(dto.CreateMealRequest{
+			Name:       "Lunch",
+			MealType:   strings.Repeat("x", 51),
+			ConsumedAt: time.Now(),
+			Foods:      []dto.FoodItem{{FoodID: "f1", Quantity: -1, Unit: "g"}},
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, map[string]string{
+			"meal_type":         "must be at most 50 characters",
+			"foods[0].quantity": "must be greater than 0",
+		}, middleware.ValidationDetails(err))
+	})
+
+	t.Run("Returns nil for other errors", func(t *testing.T) {
+		assert.Nil(t, middleware.ValidationDetails(assert.AnError))
+	})
+}
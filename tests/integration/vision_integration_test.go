@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fitness-tracker/internal/adapters/external"
+	"fitness-tracker/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisionImageInput(t *testing.T) {
+	ctx := context.Background()
+	reply := `{"items": [{"name": "Banana", "quantity": 1, "unit": "pieces"}]}`
+
+	// imageContent returns the content parts of the first message of a recorded request
+	imageContent := func(t *testing.T, request map[string]interface{}) []interface{} {
+		messages := request["messages"].([]interface{})
+		require.Len(t, messages, 1)
+		parts, ok := messages[0].(map[string]interface{})["content"].([]interface{})
+		require.True(t, ok, "content must be an array of parts")
+		require.Len(t, parts, 2)
+		return parts
+	}
+
+	t.Run("URL with quotes is sent as an image part", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, reply, &lastRequest)
+		defer server.Close()
+
+		url := `https://example.com/meal "lunch".jpg`
+		client := external.NewVisionClientWithBaseURL("test-key", server.URL)
+		result, err := client.AnalyzeFoodPhoto(ctx, url)
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, "piece", result.Items[0].Unit)
+
+		parts := imageContent(t, lastRequest)
+		assert.Equal(t, map[string]interface{}{
+			"type":      "image_url",
+			"image_url": map[string]interface{}{"url": url},
+		}, parts[0])
+		assert.Equal(t, "text", parts[1].(map[string]interface{})["type"])
+	})
+
+	t.Run("Base64 data URL is sent inline", func(t *testing.T) {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, reply, &lastRequest)
+		defer server.Close()
+
+		image := external.ImageDataURL("image/jpeg", []byte{0xff, 0xd8, 0xff, 0xe0})
+		assert.Equal(t, "data:image/jpeg;base64,/9j/4A==", image)
+
+		client := external.NewVisionClientWithBaseURL("test-key", server.URL)
+		_, err := client.AnalyzeFoodPhoto(ctx, image)
+		require.NoError(t, err)
+
+		parts := imageContent(t, lastRequest)
+		assert.Equal(t, image, parts[0].(map[string]interface{})["image_url"].(map[string]interface{})["url"])
+	})
+
+	t.Run("Rejects invalid images before calling the model", func(t *testing.T) {
+		client := external.NewVisionClientWithBaseURL("test-key", "http://127.0.0.1:0")
+
+		for _, image := range []string{
+			"ftp://example.com/meal.jpg",
+			"data:image/png,rawbytes",
+			"data:text/plain;base64,aGVsbG8=",
+			"data:image/png;base64,not base64!",
+		} {
+			_, err := client.AnalyzeFoodPhoto(ctx, image)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput, image)
+		}
+	})
+}
+
+func TestVisionNoFoodDetected(t *testing.T) {
+	ctx := context.Background()
+	image := "https://example.com/meal.jpg"
+
+	analyze := func(t *testing.T, reply string) *external.FoodAnalysisResult {
+		var lastRequest map[string]interface{}
+		server := fakeOpenRouter(t, reply, &lastRequest)
+		defer server.Close()
+
+		result, err := external.NewVisionClientWithBaseURL("test-key", server.URL).AnalyzeFoodPhoto(ctx, image)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		return result
+	}
+
+	t.Run("An empty item list keeps the model's explanation", func(t *testing.T) {
+		result := analyze(t, `{"items": [], "image_quality": "Blurry", "notes": "The plate is out of focus"}`)
+		assert.Empty(t, result.Items)
+		assert.Equal(t, 0, result.TotalItems)
+		assert.Equal(t, external.ImageQualityBlurry, result.ImageQuality)
+		assert.Equal(t, "The plate is out of focus", result.Notes)
+	})
+
+	t.Run("A prose reply becomes the notes", func(t *testing.T) {
+		result := analyze(t, "I can't see any food in this picture, only a keyboard.")
+		assert.Empty(t, result.Items)
+		assert.Equal(t, external.ImageQualityUnknown, result.ImageQuality)
+		assert.Equal(t, "I can't see any food in this picture, only a keyboard.", result.Notes)
+
+		long := analyze(t, strings.Repeat("nothing edible here ", 40))
+		assert.LessOrEqual(t, len([]rune(long.Notes)), 301)
+	})
+
+	t.Run("Items without a name or quantity count as nothing detected", func(t *testing.T) {
+		result := analyze(t, `{"items": [{"name": "", "quantity": 1}, {"name": "Soup", "quantity": 0}]}`)
+		assert.Empty(t, result.Items)
+		assert.Equal(t, external.VisionNoteNoFood, result.Notes)
+	})
+
+	t.Run("Detected items carry the image quality", func(t *testing.T) {
+		result := analyze(t, `{"image_quality": "dark", "items": [{"name": "Banana", "quantity": 1, "unit": "piece"}]}`)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, external.ImageQualityDark, result.ImageQuality)
+		assert.Empty(t, result.Notes)
+	})
+
+	t.Run("API errors are still errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{"message": "model unavailable", "type": "server_error", "code": "503"},
+			})
+		}))
+		defer server.Close()
+
+		result, err := external.NewVisionClientWithBaseURL("test-key", server.URL).AnalyzeFoodPhoto(ctx, image)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "model unavailable")
+		assert.Nil(t, result)
+	})
+
+	t.Run("No choices is an error, not an empty result", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": "test", "choices": []interface{}{}})
+		}))
+		defer server.Close()
+
+		_, err := external.NewVisionClientWithBaseURL("test-key", server.URL).AnalyzeFoodPhoto(ctx, image)
+		assert.Error(t, err)
+	})
+}
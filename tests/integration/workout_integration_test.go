@@ -1,15 +1,28 @@
 package integration
 
 import (
+	"context"
+	"encoding/json"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	httpAdapter "fitness-tracker/internal/adapters/http"
+	"fitness-tracker/internal/adapters/http/handlers"
 	"fitness-tracker/internal/adapters/repositories/postgres"
+	"fitness-tracker/internal/config"
 	"fitness-tracker/internal/core/domain"
+	"fitness-tracker/internal/pkg/auth"
+	"fitness-tracker/internal/services"
 
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestWorkoutFlow(t *testing.T) {
@@ -341,3 +354,1121 @@ func TestEstimated1RMCalculation(t *testing.T) {
 		}
 	})
 }
+
+func TestEstimateWorkoutCalories(t *testing.T) {
+	start := time.Now().Add(-60 * time.Minute)
+	duration := 60
+
+	strength := domain.WorkoutExercise{
+		Exercise: domain.Exercise{Name: "Bench Press", Category: "strength"},
+		Sets: []domain.WorkoutSet{
+			{SetNumber: 1, Reps: intPtr(10), Weight: float64Ptr(60.0)},
+			{SetNumber: 2, Reps: intPtr(8), Weight: float64Ptr(65.0)},
+		},
+	}
+
+	t.Run("Mixed strength and cardio workout", func(t *testing.T) {
+		cardio := domain.WorkoutExercise{
+			Exercise: domain.Exercise{Name: "Rowing", Category: "cardio"},
+			Sets: []domain.WorkoutSet{
+				{SetNumber: 1, DurationSeconds: intPtr(900)}, // 15 minutes
+			},
+		}
+		workout := &domain.Workout{
+			StartTime:       start,
+			DurationMinutes: &duration,
+			Exercises:       []domain.WorkoutExercise{strength, cardio},
+		}
+
+		// Cardio: 8.0 MET x 80kg x 0.25h = 160 kcal
+		// Strength: 5.0 MET x 80kg x 0.75h = 300 kcal
+		calories := domain.EstimateWorkoutCalories(workout, 80.0)
+		assert.InDelta(t, 460.0, calories, 0.01)
+	})
+
+	t.Run("Cardio set with distance only", func(t *testing.T) {
+		cardio := domain.WorkoutExercise{
+			Exercise: domain.Exercise{Name: "Running", Category: "cardio"},
+			Sets: []domain.WorkoutSet{
+				{SetNumber: 1, Distance: float64Ptr(5000.0)}, // 5km in meters
+			},
+		}
+		workout := &domain.Workout{
+			StartTime:       start,
+			DurationMinutes: &duration,
+			Exercises:       []domain.WorkoutExercise{strength, cardio},
+		}
+
+		// Cardio: 1.0 kcal/kg/km x 80kg x 5km = 400 kcal
+		// Strength: 5.0 MET x 80kg x 1h = 400 kcal
+		calories := domain.EstimateWorkoutCalories(workout, 80.0)
+		assert.InDelta(t, 800.0, calories, 0.01)
+	})
+
+	t.Run("Falls back to default weight", func(t *testing.T) {
+		workout := &domain.Workout{
+			StartTime:       start,
+			DurationMinutes: &duration,
+			Exercises:       []domain.WorkoutExercise{strength},
+		}
+
+		// Strength: 5.0 MET x 70kg x 1h = 350 kcal
+		calories := domain.EstimateWorkoutCalories(workout, 0)
+		assert.InDelta(t, 350.0, calories, 0.01)
+	})
+}
+
+func TestExerciseHistory(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "exercise_history@example.com")
+	other := CreateTestUser(t, testDB.DB, "exercise_history_other@example.com")
+	squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+	bench := CreateTestExercise(t, testDB.DB, "Bench Press", "strength")
+
+	workoutService := services.NewWorkoutService(
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	logSets := func(userID uuid.UUID, exercise *domain.Exercise, startTime time.Time, weights ...float64) {
+		workout := &domain.Workout{UserID: userID, Name: "Leg Day", StartTime: startTime}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+
+		workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: 1}
+		require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+
+		for i, weight := range weights {
+			require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+				WorkoutExerciseID: workoutExercise.ID,
+				SetNumber:         i + 1,
+				Reps:              intPtr(5),
+				Weight:            float64Ptr(weight),
+			}).Error)
+		}
+	}
+
+	week1 := time.Date(2025, 11, 3, 18, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+	logSets(user.ID, squat, week2, 105, 110)
+	logSets(user.ID, squat, week1, 100)
+	logSets(user.ID, bench, week1, 80)
+	logSets(other.ID, squat, week1, 200)
+
+	t.Run("Chronological sets for one exercise", func(t *testing.T) {
+		history, err := workoutService.GetExerciseHistory(ctx, user.ID, squat.ID, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+
+		assert.Equal(t, 100.0, *history[0].Weight)
+		assert.Equal(t, 105.0, *history[1].Weight)
+		assert.Equal(t, 110.0, *history[2].Weight)
+		assert.Equal(t, 2, history[2].SetNumber)
+
+		// Brzycki: 100 x 36 / (37 - 5) = 112.5
+		require.NotNil(t, history[0].Estimated1RM)
+		assert.InDelta(t, 112.5, *history[0].Estimated1RM, 0.01)
+	})
+
+	t.Run("Date range is inclusive of the end day", func(t *testing.T) {
+		day := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+		history, err := workoutService.GetExerciseHistory(ctx, user.ID, squat.ID, &day, &day)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+
+	t.Run("Unknown exercise", func(t *testing.T) {
+		_, err := workoutService.GetExerciseHistory(ctx, user.ID, uuid.New(), nil, nil)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Performance summarizes the user's sets", func(t *testing.T) {
+		performance, err := workoutService.GetExercisePerformance(ctx, user.ID, squat.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, performance.TotalSets)
+		assert.Equal(t, 2, performance.WorkoutCount)
+		require.Len(t, performance.RecentSets, 3)
+		assert.Equal(t, 110.0, *performance.RecentSets[0].Weight)
+		require.NotNil(t, performance.PersonalRecords.HeaviestWeight)
+		assert.Equal(t, 110.0, *performance.PersonalRecords.HeaviestWeight.Weight)
+
+		// Brzycki: 110 x 36 / (37 - 5) = 123.75
+		require.NotNil(t, performance.CurrentEstimated1RM)
+		assert.InDelta(t, 123.75, *performance.CurrentEstimated1RM, 0.01)
+	})
+}
+
+func TestGetWorkoutsByExercise(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workouts_by_exercise@example.com")
+	squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+	backSquat := &domain.Exercise{Name: "Back Squat", Category: "strength", CanonicalID: &squat.ID}
+	require.NoError(t, testDB.DB.Create(backSquat).Error)
+	bench := CreateTestExercise(t, testDB.DB, "Bench Press", "strength")
+
+	workoutService := services.NewWorkoutService(
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	createWorkout := func(name string, startTime time.Time, exercises ...*domain.Exercise) *domain.Workout {
+		endTime := startTime.Add(time.Hour)
+		workout := &domain.Workout{UserID: user.ID, Name: name, StartTime: startTime, EndTime: &endTime}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+		for i, exercise := range exercises {
+			require.NoError(t, testDB.DB.Create(&domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: i + 1}).Error)
+		}
+		return workout
+	}
+
+	day := time.Date(2025, 11, 3, 18, 0, 0, 0, time.UTC)
+	legDay := createWorkout("Leg Day", day, squat)
+	aliasDay := createWorkout("Leg Day B", day.AddDate(0, 0, 2), backSquat, bench)
+	createWorkout("Push Day", day.AddDate(0, 0, 1), bench)
+	oldLegDay := createWorkout("Old Leg Day", day.AddDate(0, -2, 0), squat)
+
+	t.Run("Only workouts with the exercise or an alias", func(t *testing.T) {
+		workouts, err := workoutService.GetWorkouts(ctx, user.ID, nil, nil, domain.WorkoutFilter{ExerciseID: &squat.ID})
+		require.NoError(t, err)
+		require.Len(t, workouts, 3)
+		assert.Equal(t, aliasDay.ID, workouts[0].ID)
+		assert.Equal(t, legDay.ID, workouts[1].ID)
+		assert.Equal(t, oldLegDay.ID, workouts[2].ID)
+	})
+
+	t.Run("Combines with the date range", func(t *testing.T) {
+		start, end := day, day.AddDate(0, 0, 2)
+		workouts, err := workoutService.GetWorkouts(ctx, user.ID, &start, &end, domain.WorkoutFilter{ExerciseID: &squat.ID})
+		require.NoError(t, err)
+		require.Len(t, workouts, 2)
+		assert.Equal(t, aliasDay.ID, workouts[0].ID)
+		assert.Equal(t, legDay.ID, workouts[1].ID)
+	})
+
+	t.Run("Filtering by an alias matches its canonical exercise", func(t *testing.T) {
+		workouts, err := workoutService.GetWorkouts(ctx, user.ID, nil, nil, domain.WorkoutFilter{ExerciseID: &backSquat.ID})
+		require.NoError(t, err)
+		assert.Len(t, workouts, 3)
+	})
+
+	t.Run("Without a filter every workout is returned", func(t *testing.T) {
+		workouts, err := workoutService.GetWorkouts(ctx, user.ID, nil, nil, domain.WorkoutFilter{})
+		require.NoError(t, err)
+		assert.Len(t, workouts, 4)
+	})
+
+	t.Run("Unknown exercise or status", func(t *testing.T) {
+		unknown := uuid.New()
+		_, err := workoutService.GetWorkouts(ctx, user.ID, nil, nil, domain.WorkoutFilter{ExerciseID: &unknown})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = workoutService.GetWorkouts(ctx, user.ID, nil, nil, domain.WorkoutFilter{Status: "cancelled"})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("The filter is served by GET /workouts", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		keys, err := auth.NewKeySet(auth.NewHMACKey("test", "workout_routes_secret_key_for_tests_32+"))
+		require.NoError(t, err)
+		token, err := keys.Sign(jwt.MapClaims{
+			"user_id": user.ID.String(),
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+
+		// Only the workout handler is reached by these requests
+		cfg := &config.Config{}
+		cfg.Server.MaxBodyBytes = 1 << 20
+		router := httpAdapter.SetupRouter(
+			nil, nil, nil, nil, nil,
+			handlers.NewWorkoutHandler(workoutService, handlers.Display{NutritionPrecision: 1}),
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			keys, zap.NewNop(), cfg,
+		)
+
+		get := func(query string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/workouts"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		w := get("?exercise_id=" + squat.ID.String() + "&start_date=2025-11-03&end_date=2025-11-06")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var workouts []domain.Workout
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workouts))
+		require.Len(t, workouts, 2)
+		assert.Equal(t, aliasDay.ID, workouts[0].ID)
+		assert.Equal(t, legDay.ID, workouts[1].ID)
+
+		assert.Equal(t, http.StatusBadRequest, get("?exercise_id=not-a-uuid").Code)
+		assert.Equal(t, http.StatusNotFound, get("?exercise_id="+uuid.New().String()).Code)
+	})
+
+	t.Run("Exercise names resolve to the library exercise", func(t *testing.T) {
+		exercise, err := workoutService.FindExercise(ctx, "  squat ")
+		require.NoError(t, err)
+		assert.Equal(t, squat.ID, exercise.ID)
+
+		exercise, err = workoutService.FindExercise(ctx, "bench pres")
+		require.NoError(t, err)
+		assert.Equal(t, bench.ID, exercise.ID)
+	})
+}
+
+func TestSummarizeExercisePerformance(t *testing.T) {
+	week1 := time.Date(2025, 11, 3, 18, 0, 0, 0, time.UTC)
+	workout1, workout2, workout3 := uuid.New(), uuid.New(), uuid.New()
+	set := func(workoutID uuid.UUID, performedAt time.Time, number, reps int, weight *float64) *domain.ExerciseSetRecord {
+		return &domain.ExerciseSetRecord{
+			SetID:        uuid.New(),
+			WorkoutID:    workoutID,
+			PerformedAt:  performedAt,
+			SetNumber:    number,
+			Reps:         intPtr(reps),
+			Weight:       weight,
+			Estimated1RM: domain.EstimateOneRepMax(weight, intPtr(reps)),
+		}
+	}
+
+	t.Run("Records, current estimate and recent sets", func(t *testing.T) {
+		records := []*domain.ExerciseSetRecord{
+			set(workout1, week1, 1, 3, float64Ptr(120)),
+			set(workout1, week1, 2, 12, float64Ptr(80)),
+			set(workout2, week1.AddDate(0, 0, 7), 1, 5, float64Ptr(100)),
+			set(workout2, week1.AddDate(0, 0, 7), 2, 5, float64Ptr(105)),
+			set(workout3, week1.AddDate(0, 0, 14), 1, 20, nil),
+		}
+
+		performance := domain.SummarizeExercisePerformance(records)
+		assert.Equal(t, 5, performance.TotalSets)
+		assert.Equal(t, 3, performance.WorkoutCount)
+		assert.Equal(t, week1.AddDate(0, 0, 14), *performance.LastPerformedAt)
+		assert.Same(t, records[4], performance.RecentSets[0], "recent sets are newest first")
+
+		assert.Same(t, records[0], performance.PersonalRecords.HeaviestWeight)
+		assert.Same(t, records[4], performance.PersonalRecords.MostReps)
+		// 120 x 36 / 34 = 127.06 beats 80 x 36 / 25 = 115.2
+		assert.Same(t, records[0], performance.PersonalRecords.BestEstimated1RM)
+
+		// The bodyweight workout has no estimate, so the latest weighted workout counts:
+		// 105 x 36 / 32 = 118.13
+		require.NotNil(t, performance.CurrentEstimated1RM)
+		assert.InDelta(t, 118.125, *performance.CurrentEstimated1RM, 0.001)
+	})
+
+	t.Run("Recent sets are capped", func(t *testing.T) {
+		var records []*domain.ExerciseSetRecord
+		for i := 0; i < domain.ExercisePerformanceRecentSets+5; i++ {
+			records = append(records, set(workout1, week1, i+1, 5, float64Ptr(100)))
+		}
+		performance := domain.SummarizeExercisePerformance(records)
+		assert.Len(t, performance.RecentSets, domain.ExercisePerformanceRecentSets)
+		assert.Equal(t, 1, performance.WorkoutCount)
+	})
+
+	t.Run("No sets yet", func(t *testing.T) {
+		performance := domain.SummarizeExercisePerformance(nil)
+		assert.Zero(t, performance.TotalSets)
+		assert.Empty(t, performance.RecentSets)
+		assert.Nil(t, performance.LastPerformedAt)
+		assert.Nil(t, performance.CurrentEstimated1RM)
+		assert.Nil(t, performance.PersonalRecords.HeaviestWeight)
+	})
+}
+
+func TestWorkoutActiveDuration(t *testing.T) {
+	start := time.Date(2025, 11, 19, 17, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := start.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+
+	t.Run("Paused then resumed session excludes the break", func(t *testing.T) {
+		workout := &domain.Workout{
+			StartTime: start,
+			EndTime:   at(60),
+			Pauses: []domain.WorkoutPause{
+				{PausedAt: *at(20), ResumedAt: at(35)},
+				{PausedAt: *at(50), ResumedAt: at(55)},
+			},
+		}
+		assert.Equal(t, 40*time.Minute, workout.ActiveDuration(*at(90)))
+		assert.Nil(t, workout.OpenPause())
+	})
+
+	t.Run("Open pause counts until now while the workout is running", func(t *testing.T) {
+		workout := &domain.Workout{
+			StartTime: start,
+			Pauses:    []domain.WorkoutPause{{PausedAt: *at(30)}},
+		}
+		assert.Equal(t, 30*time.Minute, workout.ActiveDuration(*at(45)))
+		require.NotNil(t, workout.OpenPause())
+	})
+
+	t.Run("Without pauses it is wall-clock time", func(t *testing.T) {
+		workout := &domain.Workout{StartTime: start, EndTime: at(45)}
+		assert.Equal(t, 45*time.Minute, workout.ActiveDuration(*at(90)))
+	})
+}
+
+func TestWorkoutPauseResume(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workout_pause@example.com")
+	other := CreateTestUser(t, testDB.DB, "workout_pause_other@example.com")
+
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	workoutService := services.NewWorkoutService(
+		workoutRepo,
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	workout := &domain.Workout{UserID: user.ID, Name: "Push Day", StartTime: time.Now().Add(-time.Hour)}
+	require.NoError(t, testDB.DB.Create(workout).Error)
+	workoutID := workout.ID
+
+	t.Run("Pause and resume record the break", func(t *testing.T) {
+		paused, err := workoutService.PauseWorkout(ctx, user.ID, workoutID)
+		require.NoError(t, err)
+		require.NotNil(t, paused.OpenPause())
+
+		_, err = workoutService.PauseWorkout(ctx, user.ID, workoutID)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		resumed, err := workoutService.ResumeWorkout(ctx, user.ID, workoutID)
+		require.NoError(t, err)
+		assert.Nil(t, resumed.OpenPause())
+
+		_, err = workoutService.ResumeWorkout(ctx, user.ID, workoutID)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		stored, err := workoutRepo.GetByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.Len(t, stored.Pauses, 1)
+		require.NotNil(t, stored.Pauses[0].ResumedAt)
+		assert.Less(t, stored.ActiveDuration(time.Now()), time.Since(workout.StartTime))
+	})
+
+	t.Run("Another user's workout is not found", func(t *testing.T) {
+		_, err := workoutService.PauseWorkout(ctx, other.ID, workoutID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Finished workout cannot be paused", func(t *testing.T) {
+		endTime := time.Now()
+		finished := &domain.Workout{UserID: user.ID, Name: "Leg Day", StartTime: endTime.Add(-time.Hour), EndTime: &endTime}
+		require.NoError(t, testDB.DB.Create(finished).Error)
+
+		_, err := workoutService.PauseWorkout(ctx, user.ID, finished.ID)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestReorderWorkoutExercises(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workout_reorder@example.com")
+	other := CreateTestUser(t, testDB.DB, "workout_reorder_other@example.com")
+
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	workoutService := services.NewWorkoutService(
+		workoutRepo,
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	workout := &domain.Workout{UserID: user.ID, Name: "Full Body", StartTime: time.Now().Add(-time.Hour)}
+	require.NoError(t, testDB.DB.Create(workout).Error)
+	ids := make([]uuid.UUID, 4)
+	for i, name := range []string{"Squat", "Bench Press", "Row", "Plank"} {
+		exercise := CreateTestExercise(t, testDB.DB, name, "strength")
+		workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: i}
+		require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+		ids[i] = workoutExercise.ID
+	}
+
+	storedOrder := func() []uuid.UUID {
+		stored, err := workoutRepo.GetWorkoutExercises(ctx, workout.ID)
+		require.NoError(t, err)
+		order := make([]uuid.UUID, len(stored))
+		for i, we := range stored {
+			assert.Equal(t, i, we.OrderIndex)
+			order[i] = we.ID
+		}
+		return order
+	}
+
+	t.Run("Full list sets the order", func(t *testing.T) {
+		reordered, err := workoutService.ReorderExercises(ctx, user.ID, workout.ID,
+			[]uuid.UUID{ids[3], ids[2], ids[1], ids[0]})
+		require.NoError(t, err)
+		require.Len(t, reordered, 4)
+		assert.Equal(t, ids[3], reordered[0].ID)
+		assert.Equal(t, []uuid.UUID{ids[3], ids[2], ids[1], ids[0]}, storedOrder())
+	})
+
+	t.Run("Partial list keeps the others in place", func(t *testing.T) {
+		// Swap the first and last; the two in the middle stay put
+		_, err := workoutService.ReorderExercises(ctx, user.ID, workout.ID,
+			[]uuid.UUID{ids[0], ids[3]})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[0], ids[2], ids[1], ids[3]}, storedOrder())
+	})
+
+	t.Run("Invalid lists are rejected without changes", func(t *testing.T) {
+		before := storedOrder()
+
+		otherWorkout := &domain.Workout{UserID: user.ID, Name: "Cardio", StartTime: time.Now()}
+		require.NoError(t, testDB.DB.Create(otherWorkout).Error)
+		foreign := &domain.WorkoutExercise{WorkoutID: otherWorkout.ID, ExerciseID: CreateTestExercise(t, testDB.DB, "Rowing", "cardio").ID}
+		require.NoError(t, testDB.DB.Create(foreign).Error)
+
+		for _, list := range [][]uuid.UUID{
+			{},
+			{ids[0], ids[0]},
+			{ids[0], uuid.New()},
+			{ids[0], foreign.ID},
+		} {
+			_, err := workoutService.ReorderExercises(ctx, user.ID, workout.ID, list)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput, "list %v", list)
+		}
+		assert.Equal(t, before, storedOrder())
+	})
+
+	t.Run("Another user's workout is not found", func(t *testing.T) {
+		_, err := workoutService.ReorderExercises(ctx, other.ID, workout.ID, []uuid.UUID{ids[0]})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestLogSetsInBulk(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workout_bulk_sets@example.com")
+	other := CreateTestUser(t, testDB.DB, "workout_bulk_sets_other@example.com")
+	squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	workoutService := services.NewWorkoutService(
+		workoutRepo,
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	workout := &domain.Workout{UserID: user.ID, Name: "Legs", StartTime: time.Now().Add(-time.Hour)}
+	require.NoError(t, testDB.DB.Create(workout).Error)
+	workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: squat.ID}
+	require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+	require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+		WorkoutExerciseID: workoutExercise.ID,
+		SetNumber:         1,
+		Reps:              intPtr(10),
+		SetType:           domain.SetTypeWarmup,
+	}).Error)
+
+	t.Run("Sets are numbered after the last one", func(t *testing.T) {
+		result, err := workoutService.LogSets(ctx, user.ID, workout.ID, workoutExercise.ID, []*domain.WorkoutSet{
+			{Reps: intPtr(5), Weight: float64Ptr(100)},
+			{Reps: intPtr(5), Weight: float64Ptr(100), RPE: float64Ptr(8)},
+			{Reps: intPtr(5), Weight: float64Ptr(100), SetType: domain.SetTypeDrop},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Received)
+		assert.Equal(t, 3, result.Created)
+		assert.Empty(t, result.Errors)
+		require.Len(t, result.Sets, 3)
+		for i, set := range result.Sets {
+			assert.Equal(t, i+2, set.SetNumber)
+		}
+		assert.Equal(t, domain.SetTypeNormal, result.Sets[0].SetType)
+
+		stored, err := workoutRepo.GetSets(ctx, workoutExercise.ID)
+		require.NoError(t, err)
+		assert.Len(t, stored, 4)
+	})
+
+	t.Run("Invalid sets are reported without failing the rest", func(t *testing.T) {
+		result, err := workoutService.LogSets(ctx, user.ID, workout.ID, workoutExercise.ID, []*domain.WorkoutSet{
+			{Reps: intPtr(-1)},
+			{Reps: intPtr(8), Weight: float64Ptr(80)},
+			{Reps: intPtr(8), RPE: float64Ptr(11)},
+			{Reps: intPtr(8), SetType: "giant"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 3, result.Failed)
+		require.Len(t, result.Errors, 3)
+		assert.Equal(t, []int{0, 2, 3}, []int{result.Errors[0].Index, result.Errors[1].Index, result.Errors[2].Index})
+		assert.Contains(t, result.Errors[1].Message, "rpe")
+		require.Len(t, result.Sets, 1)
+		assert.Equal(t, 5, result.Sets[0].SetNumber, "rejected sets take no number")
+	})
+
+	t.Run("Batches must have 1 to 50 sets", func(t *testing.T) {
+		_, err := workoutService.LogSets(ctx, user.ID, workout.ID, workoutExercise.ID, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		tooMany := make([]*domain.WorkoutSet, domain.MaxSetBatchSize+1)
+		for i := range tooMany {
+			tooMany[i] = &domain.WorkoutSet{Reps: intPtr(1)}
+		}
+		_, err = workoutService.LogSets(ctx, user.ID, workout.ID, workoutExercise.ID, tooMany)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Unknown exercises and other users' workouts are not found", func(t *testing.T) {
+		sets := []*domain.WorkoutSet{{Reps: intPtr(5)}}
+		_, err := workoutService.LogSets(ctx, user.ID, workout.ID, uuid.New(), sets)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		_, err = workoutService.LogSets(ctx, other.ID, workout.ID, workoutExercise.ID, sets)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Finished workouts take no more sets", func(t *testing.T) {
+		_, err := workoutService.FinishWorkout(ctx, user.ID, workout.ID)
+		require.NoError(t, err)
+
+		_, err = workoutService.LogSets(ctx, user.ID, workout.ID, workoutExercise.ID, []*domain.WorkoutSet{{Reps: intPtr(5)}})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestFindPersonalRecords(t *testing.T) {
+	bench := domain.Exercise{ID: uuid.New(), Name: "Bench Press"}
+	previous := []*domain.ExerciseSetRecord{
+		{Reps: intPtr(5), Weight: float64Ptr(80)},  // 1RM 90
+		{Reps: intPtr(10), Weight: float64Ptr(70)}, // 1RM 93.33
+	}
+	set := func(reps int, weight float64) domain.WorkoutSet {
+		return domain.WorkoutSet{ID: uuid.New(), Reps: intPtr(reps), Weight: float64Ptr(weight)}
+	}
+
+	t.Run("Heavier set and better estimate are records", func(t *testing.T) {
+		sets := []domain.WorkoutSet{set(3, 82.5), set(8, 80), set(2, 85)}
+
+		records := domain.FindPersonalRecords(bench, sets, previous)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, domain.PersonalRecordWeight, records[0].Kind)
+		assert.Equal(t, sets[2].ID, records[0].SetID, "the heaviest set is the record")
+		assert.Equal(t, 85.0, records[0].Value)
+		assert.Equal(t, 80.0, records[0].PreviousBest)
+		assert.Equal(t, "Bench Press", records[0].ExerciseName)
+
+		// 80 x 36 / 29 = 99.31 beats 70 x 36 / 27 = 93.33
+		assert.Equal(t, domain.PersonalRecordEstimated1RM, records[1].Kind)
+		assert.Equal(t, sets[1].ID, records[1].SetID)
+		assert.InDelta(t, 99.31, records[1].Value, 0.01)
+		assert.InDelta(t, 93.33, records[1].PreviousBest, 0.01)
+	})
+
+	t.Run("Matching the best is not a record", func(t *testing.T) {
+		assert.Empty(t, domain.FindPersonalRecords(bench, []domain.WorkoutSet{set(5, 80)}, previous))
+	})
+
+	t.Run("First session sets the baseline", func(t *testing.T) {
+		assert.Empty(t, domain.FindPersonalRecords(bench, []domain.WorkoutSet{set(5, 100)}, nil))
+	})
+}
+
+func TestWorkoutTotalWork(t *testing.T) {
+	workout := &domain.Workout{
+		Exercises: []domain.WorkoutExercise{
+			{Sets: []domain.WorkoutSet{
+				{Reps: intPtr(10), Weight: float64Ptr(50)},
+				{Reps: intPtr(8), Weight: float64Ptr(55)},
+			}},
+			{Sets: []domain.WorkoutSet{
+				{Reps: intPtr(15)},             // bodyweight: reps but no volume
+				{DurationSeconds: intPtr(600)}, // timed: a set without reps
+			}},
+		},
+	}
+
+	sets, reps, volume := workout.TotalWork()
+	assert.Equal(t, 4, sets)
+	assert.Equal(t, 33, reps)
+	assert.InDelta(t, 940, volume, 0.001)
+}
+
+func TestWorkoutSetRPE(t *testing.T) {
+	t.Run("RPE must be on the 0-10 scale", func(t *testing.T) {
+		assert.NoError(t, domain.ValidateRPE(nil))
+		assert.NoError(t, domain.ValidateRPE(float64Ptr(0)))
+		assert.NoError(t, domain.ValidateRPE(float64Ptr(8.5)))
+		assert.NoError(t, domain.ValidateRPE(float64Ptr(10)))
+		assert.ErrorIs(t, domain.ValidateRPE(float64Ptr(-1)), domain.ErrInvalidInput)
+		assert.ErrorIs(t, domain.ValidateRPE(float64Ptr(10.5)), domain.ErrInvalidInput)
+	})
+
+	t.Run("Average covers rated working sets only", func(t *testing.T) {
+		workout := &domain.Workout{
+			Exercises: []domain.WorkoutExercise{
+				{Sets: []domain.WorkoutSet{
+					{SetType: domain.SetTypeWarmup, RPE: float64Ptr(4)},
+					{SetType: domain.SetTypeNormal, RPE: float64Ptr(7.5)},
+					{SetType: domain.SetTypeNormal},
+				}},
+				{Sets: []domain.WorkoutSet{
+					{SetType: domain.SetTypeNormal, RPE: float64Ptr(9)},
+					{SetType: domain.SetTypeDrop, RPE: float64Ptr(10)},
+				}},
+			},
+		}
+		average := workout.AverageRPE()
+		require.NotNil(t, average)
+		assert.InDelta(t, 8.8, *average, 0.001)
+
+		assert.Nil(t, (&domain.Workout{}).AverageRPE())
+	})
+
+	t.Run("Finished summary and history carry RPE", func(t *testing.T) {
+		testDB := SetupTestDB(t)
+		defer TeardownTestDB(t, testDB)
+
+		ctx := context.Background()
+		user := CreateTestUser(t, testDB.DB, "workout_rpe@example.com")
+		squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+		workoutService := services.NewWorkoutService(
+			postgres.NewWorkoutRepository(testDB.DB),
+			postgres.NewUserRepository(testDB.DB),
+			postgres.NewUserActionRepository(testDB.DB),
+			newSummaryService(testDB.DB),
+		)
+
+		workout := &domain.Workout{UserID: user.ID, Name: "Legs", StartTime: time.Now().Add(-time.Hour)}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+		workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: squat.ID, OrderIndex: 1}
+		require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+		for i, rpe := range []float64{7, 8.5} {
+			require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+				WorkoutExerciseID: workoutExercise.ID,
+				SetNumber:         i + 1,
+				Reps:              intPtr(5),
+				Weight:            float64Ptr(100),
+				SetType:           domain.SetTypeNormal,
+				RPE:               float64Ptr(rpe),
+			}).Error)
+		}
+
+		finished, err := workoutService.FinishWorkout(ctx, user.ID, workout.ID)
+		require.NoError(t, err)
+		require.NotNil(t, finished.Summary.AverageRPE)
+		assert.InDelta(t, 7.8, *finished.Summary.AverageRPE, 0.001)
+
+		history, err := workoutService.GetExerciseHistory(ctx, user.ID, squat.ID, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.NotNil(t, history[1].RPE)
+		assert.Equal(t, 8.5, *history[1].RPE)
+	})
+}
+
+func TestFinishWorkoutSummary(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workout_finish@example.com")
+	other := CreateTestUser(t, testDB.DB, "workout_finish_other@example.com")
+	squat := CreateTestExercise(t, testDB.DB, "Squat", "strength")
+	bench := CreateTestExercise(t, testDB.DB, "Bench Press", "strength")
+
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	workoutService := services.NewWorkoutService(
+		workoutRepo,
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	logWorkout := func(startTime time.Time, endTime *time.Time, sets map[*domain.Exercise][][2]float64) *domain.Workout {
+		workout := &domain.Workout{UserID: user.ID, Name: "Strength", StartTime: startTime, EndTime: endTime}
+		require.NoError(t, testDB.DB.Create(workout).Error)
+
+		order := 0
+		for exercise, exerciseSets := range sets {
+			order++
+			workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: order}
+			require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+			for i, set := range exerciseSets {
+				require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+					WorkoutExerciseID: workoutExercise.ID,
+					SetNumber:         i + 1,
+					Reps:              intPtr(int(set[0])),
+					Weight:            float64Ptr(set[1]),
+				}).Error)
+			}
+		}
+		return workout
+	}
+
+	// Last week's session sets the baseline: squat 100 kg, bench 80 kg
+	lastWeekEnd := time.Now().AddDate(0, 0, -7)
+	logWorkout(lastWeekEnd.Add(-time.Hour), &lastWeekEnd, map[*domain.Exercise][][2]float64{
+		squat: {{5, 100}},
+		bench: {{5, 80}},
+	})
+
+	workout := logWorkout(time.Now().Add(-time.Hour), nil, map[*domain.Exercise][][2]float64{
+		squat: {{5, 100}, {3, 110}},
+		bench: {{5, 75}, {5, 75}},
+	})
+
+	t.Run("Another user's workout is not found", func(t *testing.T) {
+		_, err := workoutService.FinishWorkout(ctx, other.ID, workout.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Summary of the logged sets", func(t *testing.T) {
+		finished, err := workoutService.FinishWorkout(ctx, user.ID, workout.ID)
+		require.NoError(t, err)
+		require.NotNil(t, finished.Summary)
+
+		summary := finished.Summary
+		assert.Equal(t, 4, summary.TotalSets)
+		assert.Equal(t, 18, summary.TotalReps)
+		// 5 x 100 + 3 x 110 + 2 x 5 x 75 = 1580
+		assert.InDelta(t, 1580, summary.TotalVolume, 0.01)
+		assert.InDelta(t, 60, summary.DurationMinutes, 1)
+		assert.Greater(t, summary.CaloriesBurned, 0.0)
+
+		// Squat 110 kg beats 100 kg and its 1RM estimate 110 x 36 / 34 = 116.47 beats 112.5;
+		// bench stayed below last week
+		require.Len(t, summary.PersonalRecords, 2)
+		for _, record := range summary.PersonalRecords {
+			assert.Equal(t, squat.ID, record.ExerciseID)
+		}
+	})
+
+	t.Run("Totals are stored on the workout", func(t *testing.T) {
+		stored, err := workoutRepo.GetByID(ctx, workout.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.EndTime)
+		require.NotNil(t, stored.TotalSets)
+		assert.Equal(t, 4, *stored.TotalSets)
+		assert.Equal(t, 18, *stored.TotalReps)
+		assert.InDelta(t, 1580, *stored.TotalVolume, 0.01)
+		assert.Equal(t, 2, *stored.PersonalRecordCount)
+		assert.Len(t, stored.Exercises, 2, "finishing leaves the exercises in place")
+	})
+
+	t.Run("Finished workout cannot be finished again", func(t *testing.T) {
+		_, err := workoutService.FinishWorkout(ctx, user.ID, workout.ID)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
+
+func TestSupersetWorkoutAnalytics(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workout_superset@example.com")
+	bench := CreateTestExercise(t, testDB.DB, "Bench Press", "strength")
+	row := CreateTestExercise(t, testDB.DB, "Barbell Row", "strength")
+
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	workoutService := services.NewWorkoutService(
+		workoutRepo,
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	addExercise := func(workout *domain.Workout, exercise *domain.Exercise, order int) *domain.WorkoutExercise {
+		workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: order}
+		require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+		return workoutExercise
+	}
+	addSet := func(workoutExercise *domain.WorkoutExercise, number, reps int, weight float64, setType string, groupID *uuid.UUID) {
+		require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+			WorkoutExerciseID: workoutExercise.ID,
+			SetNumber:         number,
+			Reps:              intPtr(reps),
+			Weight:            float64Ptr(weight),
+			SetType:           setType,
+			GroupID:           groupID,
+		}).Error)
+	}
+
+	// Baseline: bench 80 kg, row 70 kg
+	lastWeekEnd := time.Now().AddDate(0, 0, -7)
+	lastWeek := &domain.Workout{UserID: user.ID, Name: "Upper", StartTime: lastWeekEnd.Add(-time.Hour), EndTime: &lastWeekEnd}
+	require.NoError(t, testDB.DB.Create(lastWeek).Error)
+	addSet(addExercise(lastWeek, bench, 1), 1, 5, 80, domain.SetTypeNormal, nil)
+	addSet(addExercise(lastWeek, row, 2), 1, 5, 70, domain.SetTypeNormal, nil)
+
+	// Today: a heavy bench warmup, then bench and row alternated as a superset,
+	// finished with a bench drop set
+	workout := &domain.Workout{UserID: user.ID, Name: "Upper", StartTime: time.Now().Add(-time.Hour)}
+	require.NoError(t, testDB.DB.Create(workout).Error)
+	benchBlock := addExercise(workout, bench, 1)
+	rowBlock := addExercise(workout, row, 2)
+
+	superset, dropSet := uuid.New(), uuid.New()
+	addSet(benchBlock, 1, 1, 100, domain.SetTypeWarmup, nil)
+	addSet(benchBlock, 2, 5, 75, domain.SetTypeSuperset, &superset)
+	addSet(rowBlock, 1, 5, 72.5, domain.SetTypeSuperset, &superset)
+	addSet(benchBlock, 3, 5, 75, domain.SetTypeSuperset, &superset)
+	addSet(rowBlock, 2, 5, 72.5, domain.SetTypeSuperset, &superset)
+	addSet(benchBlock, 4, 8, 60, domain.SetTypeDrop, &dropSet)
+	addSet(benchBlock, 5, 8, 50, domain.SetTypeDrop, &dropSet)
+
+	t.Run("Warmups are left out of totals and records", func(t *testing.T) {
+		finished, err := workoutService.FinishWorkout(ctx, user.ID, workout.ID)
+		require.NoError(t, err)
+
+		summary := finished.Summary
+		assert.Equal(t, 6, summary.TotalSets)
+		assert.Equal(t, 36, summary.TotalReps)
+		// 2 x 5 x 75 + 2 x 5 x 72.5 + 8 x 60 + 8 x 50 = 2355
+		assert.InDelta(t, 2355, summary.TotalVolume, 0.01)
+
+		// The 100 kg bench warmup is no record; the 72.5 kg rows beat 70 kg
+		require.Len(t, summary.PersonalRecords, 2)
+		for _, record := range summary.PersonalRecords {
+			assert.Equal(t, row.ID, record.ExerciseID)
+		}
+	})
+
+	t.Run("Grouping is kept on the sets", func(t *testing.T) {
+		stored, err := workoutRepo.GetByID(ctx, workout.ID)
+		require.NoError(t, err)
+
+		grouped := map[uuid.UUID]int{}
+		for _, exercise := range stored.Exercises {
+			for _, set := range exercise.Sets {
+				if set.GroupID != nil {
+					grouped[*set.GroupID]++
+				}
+			}
+		}
+		assert.Equal(t, 4, grouped[superset])
+		assert.Equal(t, 2, grouped[dropSet])
+	})
+
+	t.Run("Exercise performance ignores warmups", func(t *testing.T) {
+		performance, err := workoutService.GetExercisePerformance(ctx, user.ID, bench.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 6, performance.TotalSets, "history still lists the warmup")
+		require.NotNil(t, performance.PersonalRecords.HeaviestWeight)
+		assert.Equal(t, 80.0, *performance.PersonalRecords.HeaviestWeight.Weight)
+		// Newest first: today's five bench sets end with the warmup, then last week's set
+		require.Len(t, performance.RecentSets, 6)
+		assert.Equal(t, domain.SetTypeWarmup, performance.RecentSets[4].SetType)
+	})
+}
+
+func TestNormalizeExerciseName(t *testing.T) {
+	assert.Equal(t, "Bench Press", domain.NormalizeExerciseName("  Bench \t  Press\n"))
+	assert.Equal(t, "", domain.NormalizeExerciseName("   "))
+
+	assert.True(t, domain.SameExerciseName("bench press", " Bench  PRESS "))
+	assert.False(t, domain.SameExerciseName("Bench Press", "Barbell Bench Press"))
+}
+
+func TestCreateExerciseDedupe(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "exercise_dedupe@example.com")
+	bench := CreateTestExercise(t, testDB.DB, "Bench Press", "strength")
+
+	workoutService := services.NewWorkoutService(
+		postgres.NewWorkoutRepository(testDB.DB),
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	t.Run("Same name in other casing returns the existing exercise", func(t *testing.T) {
+		exercise, created, err := workoutService.CreateExercise(ctx, &domain.Exercise{Name: "  bench   press ", Category: "strength"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, bench.ID, exercise.ID)
+	})
+
+	var barbell *domain.Exercise
+	t.Run("Close match is created with a warning", func(t *testing.T) {
+		var created bool
+		var err error
+		barbell, created, err = workoutService.CreateExercise(ctx, &domain.Exercise{Name: "Barbell  Bench Press", Category: "strength"})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "Barbell Bench Press", barbell.Name)
+		assert.Nil(t, barbell.CanonicalID)
+		require.NotEmpty(t, barbell.Warnings)
+		assert.Contains(t, barbell.Warnings[0], `"Bench Press"`)
+	})
+
+	t.Run("Alias of an alias points at the canonical exercise", func(t *testing.T) {
+		require.NotNil(t, barbell)
+		require.NoError(t, testDB.DB.Model(barbell).Update("canonical_id", bench.ID).Error)
+
+		flat, created, err := workoutService.CreateExercise(ctx, &domain.Exercise{Name: "Flat Bench", Category: "strength", CanonicalID: &barbell.ID})
+		require.NoError(t, err)
+		assert.True(t, created)
+		require.NotNil(t, flat.CanonicalID)
+		assert.Equal(t, bench.ID, *flat.CanonicalID)
+		assert.Empty(t, flat.Warnings)
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		_, _, err := workoutService.CreateExercise(ctx, &domain.Exercise{Name: "   ", Category: "strength"})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+		missing := uuid.New()
+		_, _, err = workoutService.CreateExercise(ctx, &domain.Exercise{Name: "Incline Press", Category: "strength", CanonicalID: &missing})
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+
+	t.Run("Alias sets count toward the canonical history", func(t *testing.T) {
+		require.NotNil(t, barbell)
+		for i, exercise := range []*domain.Exercise{bench, barbell} {
+			workout := &domain.Workout{UserID: user.ID, Name: "Push", StartTime: time.Date(2025, 11, 3+i, 18, 0, 0, 0, time.UTC)}
+			require.NoError(t, testDB.DB.Create(workout).Error)
+			workoutExercise := &domain.WorkoutExercise{WorkoutID: workout.ID, ExerciseID: exercise.ID, OrderIndex: 1}
+			require.NoError(t, testDB.DB.Create(workoutExercise).Error)
+			require.NoError(t, testDB.DB.Create(&domain.WorkoutSet{
+				WorkoutExerciseID: workoutExercise.ID,
+				SetNumber:         1,
+				Reps:              intPtr(5),
+				Weight:            float64Ptr(80 + float64(i)*5),
+			}).Error)
+		}
+
+		for _, exercise := range []*domain.Exercise{bench, barbell} {
+			history, err := workoutService.GetExerciseHistory(ctx, user.ID, exercise.ID, nil, nil)
+			require.NoError(t, err)
+			require.Len(t, history, 2)
+			assert.Equal(t, 85.0, *history[1].Weight)
+		}
+	})
+}
+
+func TestCloneWorkout(t *testing.T) {
+	// Setup
+	testDB := SetupTestDB(t)
+	defer TeardownTestDB(t, testDB)
+
+	ctx := context.Background()
+	user := CreateTestUser(t, testDB.DB, "workout_clone@example.com")
+	other := CreateTestUser(t, testDB.DB, "workout_clone_other@example.com")
+	bench := CreateTestExercise(t, testDB.DB, "Bench Press", "strength")
+	row := CreateTestExercise(t, testDB.DB, "Barbell Row", "strength")
+
+	workoutRepo := postgres.NewWorkoutRepository(testDB.DB)
+	workoutService := services.NewWorkoutService(
+		workoutRepo,
+		postgres.NewUserRepository(testDB.DB),
+		postgres.NewUserActionRepository(testDB.DB),
+		newSummaryService(testDB.DB),
+	)
+
+	end := time.Now().Add(-23 * time.Hour)
+	source := &domain.Workout{UserID: user.ID, Name: "Upper", StartTime: end.Add(-time.Hour), EndTime: &end}
+	require.NoError(t, testDB.DB.Create(source).Error)
+	// Added in the opposite order to their position in the workout
+	rowExercise := &domain.WorkoutExercise{WorkoutID: source.ID, ExerciseID: row.ID, OrderIndex: 1}
+	require.NoError(t, testDB.DB.Create(rowExercise).Error)
+	benchExercise := &domain.WorkoutExercise{WorkoutID: source.ID, ExerciseID: bench.ID, OrderIndex: 0}
+	require.NoError(t, testDB.DB.Create(benchExercise).Error)
+	for _, set := range []*domain.WorkoutSet{
+		{WorkoutExerciseID: benchExercise.ID, SetNumber: 2, Reps: intPtr(8), Weight: float64Ptr(80), RestSeconds: intPtr(120)},
+		{WorkoutExerciseID: benchExercise.ID, SetNumber: 1, Reps: intPtr(10), Weight: float64Ptr(40), SetType: domain.SetTypeWarmup},
+		{WorkoutExerciseID: rowExercise.ID, SetNumber: 1, Reps: intPtr(10), Weight: float64Ptr(60)},
+	} {
+		require.NoError(t, testDB.DB.Create(set).Error)
+	}
+
+	t.Run("The clone has the same exercises in order and no logged sets", func(t *testing.T) {
+		clone, err := workoutService.CloneWorkout(ctx, user.ID, source.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, source.ID, clone.ID)
+		assert.Equal(t, "Upper", clone.Name)
+		assert.Nil(t, clone.EndTime)
+
+		stored, err := workoutService.GetWorkout(ctx, user.ID, clone.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.EndTime)
+
+		exercises, err := workoutRepo.GetWorkoutExercises(ctx, clone.ID)
+		require.NoError(t, err)
+		require.Len(t, exercises, 2)
+		assert.Equal(t, bench.ID, exercises[0].ExerciseID)
+		assert.Equal(t, row.ID, exercises[1].ExerciseID)
+		for _, exercise := range exercises {
+			assert.Empty(t, exercise.Sets)
+		}
+
+		planned := exercises[0].PlannedSets
+		require.Len(t, planned, 2)
+		assert.Equal(t, 1, planned[0].SetNumber)
+		assert.Equal(t, domain.SetTypeWarmup, planned[0].SetType)
+		assert.Equal(t, 40.0, *planned[0].Weight)
+		assert.Equal(t, 80.0, *planned[1].Weight)
+		assert.Equal(t, 120, *planned[1].RestSeconds)
+		require.Len(t, exercises[1].PlannedSets, 1)
+
+		// The source is untouched
+		sourceExercises, err := workoutRepo.GetWorkoutExercises(ctx, source.ID)
+		require.NoError(t, err)
+		assert.Len(t, sourceExercises[0].Sets, 2)
+		assert.Empty(t, sourceExercises[0].PlannedSets)
+	})
+
+	t.Run("Sets logged on the clone are new sets", func(t *testing.T) {
+		clone, err := workoutService.CloneWorkout(ctx, user.ID, source.ID)
+		require.NoError(t, err)
+
+		_, err = workoutService.LogSet(ctx, clone.Exercises[0].ID, &domain.WorkoutSet{SetNumber: 1, Reps: intPtr(8), Weight: float64Ptr(82.5)})
+		require.NoError(t, err)
+
+		sets, err := workoutRepo.GetSets(ctx, clone.Exercises[0].ID)
+		require.NoError(t, err)
+		require.Len(t, sets, 1)
+		assert.Equal(t, 82.5, *sets[0].Weight)
+
+		sourceSets, err := workoutRepo.GetSets(ctx, benchExercise.ID)
+		require.NoError(t, err)
+		assert.Len(t, sourceSets, 2)
+	})
+
+	t.Run("Another user's workout cannot be cloned", func(t *testing.T) {
+		_, err := workoutService.CloneWorkout(ctx, other.ID, source.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}